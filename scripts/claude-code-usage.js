@@ -0,0 +1,196 @@
+#!/usr/bin/env node
+/**
+ * Claude Code usage reporter for AI Limits Monitor Mattermost plugin.
+ *
+ * Reads the session logs Claude Code keeps on this machine
+ * (~/.claude/projects/**\/*.jsonl), sums the token usage per day and model,
+ * the same way ccusage does, and pushes it to the plugin so the team sees
+ * everyone's Claude Code consumption in one card.
+ *
+ * Each report replaces this machine's previous one, so it is safe to run as
+ * often as you like. Only token counts, model names and dates are sent —
+ * never prompts, code or file paths.
+ *
+ * Usage:
+ *   MM_URL=https://mattermost.example.com MM_TOKEN=<personal access token> \
+ *   node claude-code-usage.js [--watch[=minutes]] [--host=name] [--dry-run]
+ *
+ *   --watch      keep running and report every 15 minutes (or the given interval)
+ *   --host       machine name shown in the dashboard (default: the hostname)
+ *   --dry-run    print the report instead of sending it
+ *
+ * Environment variables:
+ *   MM_URL             - Mattermost URL
+ *   MM_TOKEN           - your personal access token; usage is reported as you
+ *   CLAUDE_CONFIG_DIR  - Claude Code config directories, comma-separated
+ *                        (default: ~/.claude and ~/.config/claude)
+ *
+ * Or run it from crontab: *\/30 * * * * node /path/to/claude-code-usage.js
+ */
+
+const fs = require('fs');
+const os = require('os');
+const path = require('path');
+
+const PLUGIN_PATH = '/plugins/com.fambear.ai-limits-monitor/api/v1/claudecode/usage';
+
+function parseArgs(argv) {
+  const args = { watch: 0, host: os.hostname(), dryRun: false };
+  for (const arg of argv) {
+    if (arg === '--watch') {
+      args.watch = 15;
+    } else if (arg.startsWith('--watch=')) {
+      args.watch = Number(arg.slice('--watch='.length));
+    } else if (arg.startsWith('--host=')) {
+      args.host = arg.slice('--host='.length);
+    } else if (arg === '--dry-run') {
+      args.dryRun = true;
+    } else {
+      throw new Error(`Unknown option ${arg}`);
+    }
+  }
+  if (!(args.watch >= 0)) {
+    throw new Error('--watch needs a number of minutes');
+  }
+  return args;
+}
+
+function projectDirs() {
+  const configured = (process.env.CLAUDE_CONFIG_DIR || '').split(',').map(d => d.trim()).filter(Boolean);
+  const roots = configured.length > 0 ? configured : [
+    path.join(os.homedir(), '.claude'),
+    path.join(os.homedir(), '.config', 'claude'),
+  ];
+  return roots.map(root => path.join(root, 'projects')).filter(dir => fs.existsSync(dir));
+}
+
+function* jsonlFiles(dir) {
+  for (const entry of fs.readdirSync(dir, { withFileTypes: true })) {
+    const full = path.join(dir, entry.name);
+    if (entry.isDirectory()) {
+      yield* jsonlFiles(full);
+    } else if (entry.isFile() && entry.name.endsWith('.jsonl')) {
+      yield full;
+    }
+  }
+}
+
+/**
+ * Sums usage per UTC day and model since the start of the previous month.
+ * A response can be logged more than once (e.g. when a session is resumed),
+ * so entries are counted once per message and request ID.
+ */
+function collectUsage(now) {
+  const since = new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth() - 1, 1)).toISOString().slice(0, 10);
+  const seen = new Set();
+  const totals = new Map();
+
+  for (const dir of projectDirs()) {
+    for (const file of jsonlFiles(dir)) {
+      if (fs.statSync(file).mtime.toISOString().slice(0, 10) < since) {
+        continue;
+      }
+      for (const line of fs.readFileSync(file, 'utf8').split('\n')) {
+        if (!line.includes('"usage"')) {
+          continue;
+        }
+        let entry;
+        try {
+          entry = JSON.parse(line);
+        } catch (e) {
+          continue; // a session still being written can end in a partial line
+        }
+        const message = entry.message || {};
+        const usage = message.usage;
+        if (!usage || !message.model || message.model === '<synthetic>' || !entry.timestamp) {
+          continue;
+        }
+        const date = new Date(entry.timestamp).toISOString().slice(0, 10);
+        if (date < since) {
+          continue;
+        }
+        if (message.id && entry.requestId) {
+          const id = `${message.id}:${entry.requestId}`;
+          if (seen.has(id)) {
+            continue;
+          }
+          seen.add(id);
+        }
+
+        const key = `${date}|${message.model}`;
+        if (!totals.has(key)) {
+          totals.set(key, {
+            date, model: message.model,
+            inputTokens: 0, outputTokens: 0, cacheCreationTokens: 0, cacheReadTokens: 0,
+            sessionIds: new Set(),
+          });
+        }
+        const t = totals.get(key);
+        t.inputTokens += usage.input_tokens || 0;
+        t.outputTokens += usage.output_tokens || 0;
+        t.cacheCreationTokens += usage.cache_creation_input_tokens || 0;
+        t.cacheReadTokens += usage.cache_read_input_tokens || 0;
+        if (entry.sessionId) {
+          t.sessionIds.add(entry.sessionId);
+        }
+      }
+    }
+  }
+
+  return [...totals.values()]
+    .sort((a, b) => a.date.localeCompare(b.date) || a.model.localeCompare(b.model))
+    .map(({ sessionIds, ...t }) => ({ ...t, sessions: sessionIds.size }));
+}
+
+async function report(args) {
+  const body = { host: args.host, usage: collectUsage(new Date()) };
+  if (args.dryRun) {
+    console.log(JSON.stringify(body, null, 2));
+    return;
+  }
+
+  const resp = await fetch(process.env.MM_URL.replace(/\/+$/, '') + PLUGIN_PATH, {
+    method: 'POST',
+    headers: {
+      'Authorization': `Bearer ${process.env.MM_TOKEN}`,
+      'Content-Type': 'application/json',
+      'X-Requested-With': 'XMLHttpRequest',
+    },
+    body: JSON.stringify(body),
+  });
+  if (!resp.ok) {
+    throw new Error(`HTTP ${resp.status}: ${(await resp.text()).slice(0, 200)}`);
+  }
+  console.log(`OK: ${body.usage.length} day and model totals from ${args.host} pushed to ${process.env.MM_URL}`);
+}
+
+async function main() {
+  const args = parseArgs(process.argv.slice(2));
+  if (!args.dryRun && (!process.env.MM_URL || !process.env.MM_TOKEN)) {
+    console.error('Error: MM_URL and MM_TOKEN must be set');
+    process.exit(1);
+  }
+  if (projectDirs().length === 0) {
+    console.error('Error: no Claude Code session logs found; set CLAUDE_CONFIG_DIR');
+    process.exit(1);
+  }
+
+  if (!args.watch) {
+    await report(args);
+    return;
+  }
+  // Agent mode: keep reporting, and keep going when the server is briefly unreachable
+  for (;;) {
+    try {
+      await report(args);
+    } catch (e) {
+      console.error(`Error: ${e.message}`);
+    }
+    await new Promise(resolve => setTimeout(resolve, args.watch * 60 * 1000));
+  }
+}
+
+main().catch(e => {
+  console.error(`Error: ${e.message}`);
+  process.exit(1);
+});
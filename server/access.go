@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Access control =====
+
+// accessCacheTTL is how long an access decision is reused, so the membership lookups
+// aren't repeated on every request. Configuration changes drop every decision.
+const accessCacheTTL = time.Minute
+
+// accessDecision is whether a user may see the dashboard, as of at.
+type accessDecision struct {
+	allowed bool
+	at      time.Time
+}
+
+// accessRestricted reports whether any of the access settings limits who may use the plugin.
+func (c *Configuration) accessRestricted() bool {
+	return c.AllowedUserIds != "" || c.AllowedTeamIds != "" || c.AllowedRoles != "" || c.AllowedChannelIds != ""
+}
+
+// checkAccess returns true if user is allowed to access this plugin: when nothing is
+// restricted, or when the user is listed, has an allowed role, or is a member of an
+// allowed team or channel.
+func (p *Plugin) checkAccess(userID string) bool {
+	config := p.getConfiguration()
+	if !config.accessRestricted() {
+		return true
+	}
+
+	now := time.Now()
+	p.accessLock.Lock()
+	decision, ok := p.access[userID]
+	p.accessLock.Unlock()
+	if ok && now.Sub(decision.at) < accessCacheTTL {
+		return decision.allowed
+	}
+
+	allowed := p.lookupAccess(config, userID)
+	p.accessLock.Lock()
+	if p.access == nil {
+		p.access = map[string]accessDecision{}
+	}
+	p.access[userID] = accessDecision{allowed: allowed, at: now}
+	p.accessLock.Unlock()
+	return allowed
+}
+
+func (p *Plugin) lookupAccess(config *Configuration, userID string) bool {
+	for _, id := range splitList(config.AllowedUserIds) {
+		if id == userID {
+			return true
+		}
+	}
+
+	roles := map[string]bool{}
+	for _, role := range splitList(config.AllowedRoles) {
+		roles[role] = true
+	}
+	if len(roles) > 0 {
+		if user, appErr := p.API.GetUser(userID); appErr == nil {
+			for _, role := range strings.Fields(user.Roles) {
+				if roles[role] {
+					return true
+				}
+			}
+		}
+	}
+
+	// One lookup of the user's teams answers both the team and the team admin checks
+	teams := splitList(config.AllowedTeamIds)
+	if len(teams) > 0 || roles[model.TeamAdminRoleId] {
+		members, appErr := p.API.GetTeamMembersForUser(userID, 0, 200)
+		if appErr == nil {
+			allowedTeams := map[string]bool{}
+			for _, id := range teams {
+				allowedTeams[id] = true
+			}
+			for _, m := range members {
+				if m.DeleteAt != 0 {
+					continue
+				}
+				if allowedTeams[m.TeamId] {
+					return true
+				}
+				if roles[model.TeamAdminRoleId] && (m.SchemeAdmin || strings.Contains(" "+m.Roles+" ", " "+model.TeamAdminRoleId+" ")) {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, channelID := range splitList(config.AllowedChannelIds) {
+		if _, appErr := p.API.GetChannelMember(channelID, userID); appErr == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// clearAccess drops the cached access decisions, e.g. once the configuration changed.
+func (p *Plugin) clearAccess() {
+	p.accessLock.Lock()
+	p.access = nil
+	p.accessLock.Unlock()
+}
+
+// allowedUsers returns the users recently allowed access, for pushes that can't be
+// addressed to a role.
+func (p *Plugin) allowedUsers() []string {
+	p.accessLock.Lock()
+	defer p.accessLock.Unlock()
+	var users []string
+	for id, decision := range p.access {
+		if decision.allowed {
+			users = append(users, id)
+		}
+	}
+	return users
+}
+
+// canChange reports whether the user may refresh providers and manage credentials and
+// budgets: anyone with access, or with Restrict Changes to Managers only system admins
+// and Tuning Managers.
+func (p *Plugin) canChange(userID string) bool {
+	if !p.getConfiguration().RestrictChanges {
+		return true
+	}
+	return p.canTune(userID)
+}
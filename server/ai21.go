@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== AI21 Labs (API key) =====
+
+// AI21Info is the key's health and the account's plan. AI21 Studio has no usage or billing
+// API, so the plan and the end of the trial credit come from the configuration.
+type AI21Info struct {
+	Plan      string `json:"plan,omitempty"`
+	TrialEnds string `json:"trialEnds,omitempty"`
+	DaysLeft  int    `json:"daysLeft"`
+}
+
+func (a AI21Info) summary(locale string) string {
+	var parts []string
+	if a.Plan != "" {
+		parts = append(parts, translate(locale, "summary.plan", a.Plan))
+	}
+	switch {
+	case a.TrialEnds != "" && a.DaysLeft < 0:
+		parts = append(parts, translate(locale, "summary.ai21_trial_expired"))
+	case a.TrialEnds != "":
+		parts = append(parts, translate(locale, "summary.ai21_trial", a.DaysLeft))
+	}
+	if len(parts) == 0 {
+		return translate(locale, "summary.connected")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (a AI21Info) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "trial_end", At: parseTime(a.TrialEnds)}}
+}
+
+func (p *Plugin) getAI21Status(config *Configuration) ServiceStatus {
+	const id, name = "ai21", "AI21 Labs"
+	if config.Ai21ApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newAI21Request(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	// The library listing only confirms the key is accepted; its contents aren't used
+	var files []json.RawMessage
+	if err := json.Unmarshal(body, &files); err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	info := AI21Info{Plan: config.Ai21Plan}
+	now := time.Now().UTC()
+	if info.Plan == "Trial" {
+		if ends, err := time.Parse("2006-01-02", strings.TrimSpace(config.Ai21TrialEnds)); err == nil {
+			info.TrialEnds = ends.Format(time.RFC3339)
+			info.DaysLeft = int(ends.Sub(now).Hours() / 24)
+		}
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: ai21Status(info, now),
+		Data: info, CachedAt: now.Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// ai21Status is an error once the trial credit has expired, after which requests are
+// refused until a payment method is added, and a warning in its last week.
+func ai21Status(info AI21Info, now time.Time) string {
+	ends := parseTime(info.TrialEnds)
+	switch {
+	case ends.IsZero():
+		return "ok"
+	case !now.Before(ends):
+		return "error"
+	case ends.Sub(now) < 7*24*time.Hour:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newAI21Request(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.ai21.com/studio/v1/library/files?limit=1", nil)
+	req.Header.Set("Authorization", "Bearer "+config.Ai21ApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
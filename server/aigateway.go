@@ -0,0 +1,545 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===== Self-hosted AI gateway (Kong AI Gateway, Envoy AI Gateway) via Prometheus metrics =====
+
+const defaultAIGatewayName = "AI Gateway"
+
+// aiGatewayModelsShown is how many models a card lists.
+const aiGatewayModelsShown = 5
+
+// AIGatewayInfo is the month's consumption of one upstream provider routed through the
+// gateway. The metrics are counters since the gateway started, so the month's usage is
+// measured from the first refresh of the month and survives gateway restarts.
+type AIGatewayInfo struct {
+	Gateway        string                `json:"gateway"` // "kong" or "envoy"
+	Provider       string                `json:"provider"`
+	Requests       float64               `json:"requests"`
+	InputTokens    float64               `json:"inputTokens"`
+	OutputTokens   float64               `json:"outputTokens"`
+	Tokens         float64               `json:"tokens"`
+	Cost           float64               `json:"cost"`
+	HasCost        bool                  `json:"hasCost"` // Envoy AI Gateway exports no cost
+	Currency       string                `json:"currency"`
+	Budget         float64               `json:"budget,omitempty"`
+	TokenLimit     float64               `json:"tokenLimit,omitempty"`
+	Models         []AIGatewayModelUsage `json:"models,omitempty"`
+	ModelCount     int                   `json:"modelCount"`
+	TrackedSince   string                `json:"trackedSince"`
+	Period         string                `json:"period"`
+	CycleEnd       string                `json:"cycleEnd"`
+	DaysUntilReset int                   `json:"daysUntilReset"`
+}
+
+// AIGatewayModelUsage is the month's consumption of one model.
+type AIGatewayModelUsage struct {
+	Model    string  `json:"model"`
+	Requests float64 `json:"requests"`
+	Tokens   float64 `json:"tokens"`
+	Cost     float64 `json:"cost,omitempty"`
+}
+
+// usagePercent is the share of the token limit consumed, or of the budget.
+func (a AIGatewayInfo) usagePercent() (float64, bool) {
+	switch {
+	case a.TokenLimit > 0:
+		return a.Tokens / a.TokenLimit * 100, true
+	case a.Budget > 0:
+		return a.Cost / a.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (a AIGatewayInfo) summary(locale string) string {
+	text := translate(locale, "summary.aigateway", formatCount(a.Tokens), formatCount(a.Requests), a.Period)
+	switch {
+	case a.TokenLimit > 0:
+		text += ", " + translate(locale, "summary.aigateway_token_limit", formatCount(a.TokenLimit))
+	case a.HasCost && a.Budget > 0:
+		text += ", " + translate(locale, "summary.cost_of", formatMoney(a.Cost, a.Currency, 2), formatMoney(a.Budget, a.Currency, 0))
+	case a.HasCost:
+		text += ", " + translate(locale, "summary.aigateway_cost", formatMoney(a.Cost, a.Currency, 2))
+	}
+	return text
+}
+
+func (a AIGatewayInfo) compact(name, locale string) string {
+	switch {
+	case a.TokenLimit > 0:
+		return fmt.Sprintf("%s %s/%s tok", name, formatCount(a.Tokens), formatCount(a.TokenLimit))
+	case a.HasCost && a.Budget > 0:
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(a.Cost, a.Currency, 0), formatMoney(a.Budget, a.Currency, 0))
+	case a.HasCost:
+		return fmt.Sprintf("%s %s", name, formatMoney(a.Cost, a.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s tok", name, formatCount(a.Tokens))
+}
+
+func (a AIGatewayInfo) resets(time.Time) []ResetInfo {
+	if a.TokenLimit > 0 || a.Budget > 0 {
+		return []ResetInfo{{Kind: "monthly_billing", At: parseTime(a.CycleEnd)}}
+	}
+	return nil
+}
+
+// aiGatewayStatus applies the configured limit of the provider; without one the card
+// only reports consumption.
+func aiGatewayStatus(info AIGatewayInfo, config *Configuration) string {
+	if info.TokenLimit > 0 {
+		return budgetStatus(info.Tokens, info.TokenLimit, config)
+	}
+	return budgetStatus(info.Cost, info.Budget, config)
+}
+
+// aiGatewayName is the configured display name of the gateway.
+func (c *Configuration) aiGatewayName() string {
+	if name := strings.TrimSpace(c.AigatewayName); name != "" {
+		return name
+	}
+	return defaultAIGatewayName
+}
+
+func (c *Configuration) aiGatewayURL() string {
+	return strings.TrimSpace(c.AigatewayMetricsUrl)
+}
+
+// aiGatewayLimit is the monthly limit set for an upstream provider: a budget, or a number
+// of tokens.
+type aiGatewayLimit struct {
+	Budget float64
+	Tokens float64
+}
+
+// aiGatewayLimits parses the configured limits, e.g. "openai=500, anthropic=20M tokens".
+func (p *Plugin) aiGatewayLimits(config *Configuration) map[string]aiGatewayLimit {
+	limits := map[string]aiGatewayLimit{}
+	for _, item := range splitList(config.AigatewayLimits) {
+		provider, value, ok := strings.Cut(item, "=")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if !ok || provider == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if count, isTokens := strings.CutSuffix(strings.ToLower(value), "tokens"); isTokens {
+			tokens, err := parseTokenCount(count)
+			if err != nil {
+				p.API.LogWarn("Invalid AI gateway token limit", "provider", provider, "error", err.Error())
+				continue
+			}
+			limits[provider] = aiGatewayLimit{Tokens: tokens}
+			continue
+		}
+		limits[provider] = aiGatewayLimit{Budget: p.budgetIn(config, "aigateway", value, baseCurrency)}
+	}
+	return limits
+}
+
+// parseTokenCount parses a count with an optional K, M or B suffix, e.g. "20M".
+func parseTokenCount(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	multiplier := 1.0
+	if value != "" {
+		switch value[len(value)-1] {
+		case 'k', 'K':
+			multiplier = 1e3
+		case 'm', 'M':
+			multiplier = 1e6
+		case 'b', 'B':
+			multiplier = 1e9
+		}
+		if multiplier > 1 {
+			value = strings.TrimSpace(value[:len(value)-1])
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid token count %q", value)
+	}
+	return n * multiplier, nil
+}
+
+// promSample is a sample of the Prometheus text exposition format.
+type promSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// series identifies the sample's time series: its name and sorted labels.
+func (s promSample) series() string {
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(s.Name)
+	for _, k := range keys {
+		b.WriteString("|" + k + "=" + s.Labels[k])
+	}
+	return b.String()
+}
+
+// parsePromText reads the samples of a Prometheus text exposition, skipping comments and
+// lines it can't parse.
+func parsePromText(body []byte) []promSample {
+	var samples []promSample
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if s, ok := parsePromLine(line); ok {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// parsePromLine parses `name{label="value",...} value [timestamp]`.
+func parsePromLine(line string) (promSample, bool) {
+	s := promSample{Labels: map[string]string{}}
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return s, false
+	}
+	s.Name, line = line[:end], line[end:]
+
+	if line[0] == '{' {
+		line = line[1:]
+		for {
+			line = strings.TrimLeft(line, " \t,")
+			if line == "" {
+				return s, false
+			}
+			if line[0] == '}' {
+				line = line[1:]
+				break
+			}
+			eq := strings.IndexByte(line, '=')
+			if eq <= 0 || len(line) < eq+2 || line[eq+1] != '"' {
+				return s, false
+			}
+			name := strings.TrimSpace(line[:eq])
+			var value strings.Builder
+			i := eq + 2
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+					switch line[i] {
+					case 'n':
+						value.WriteByte('\n')
+					default:
+						value.WriteByte(line[i])
+					}
+					continue
+				}
+				value.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return s, false
+			}
+			s.Labels[name] = value.String()
+			line = line[i+1:]
+		}
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return s, false
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return s, false
+	}
+	s.Value = v
+	return s, true
+}
+
+// aiGatewayCounts is the month's consumption of a model.
+type aiGatewayCounts struct {
+	Requests     float64 `json:"requests,omitempty"`
+	Cost         float64 `json:"cost,omitempty"`
+	InputTokens  float64 `json:"inputTokens,omitempty"`
+	OutputTokens float64 `json:"outputTokens,omitempty"`
+	TotalTokens  float64 `json:"totalTokens,omitempty"`
+}
+
+// tokens prefers the gateway's own total, which counts tokens like cached or reasoning
+// ones that are neither input nor output.
+func (c aiGatewayCounts) tokens() float64 {
+	if c.TotalTokens > 0 {
+		return c.TotalTokens
+	}
+	return c.InputTokens + c.OutputTokens
+}
+
+// aiGatewayMetric is a sample attributed to an upstream provider and model.
+type aiGatewayMetric struct {
+	Series   string
+	Gateway  string
+	Provider string
+	Model    string
+	Field    string // "requests", "cost", "input", "output" or "total"
+	Value    float64
+}
+
+// aiGatewayMetrics keeps the samples of Kong's AI metrics and of the OpenTelemetry GenAI
+// metrics Envoy AI Gateway exports, and drops everything else the endpoint serves.
+func aiGatewayMetrics(samples []promSample) []aiGatewayMetric {
+	var metrics []aiGatewayMetric
+	for _, s := range samples {
+		m := aiGatewayMetric{Series: s.series(), Value: s.Value}
+		switch strings.TrimPrefix(s.Name, "kong_") {
+		case "ai_llm_requests_total":
+			m.Gateway, m.Field = "kong", "requests"
+		case "ai_llm_cost_total":
+			m.Gateway, m.Field = "kong", "cost"
+		case "ai_llm_tokens_total":
+			m.Gateway, m.Field = "kong", tokenField(s.Labels["token_type"])
+		case "gen_ai_client_token_usage_sum":
+			m.Gateway, m.Field = "envoy", tokenField(s.Labels["gen_ai_token_type"])
+		case "gen_ai_server_request_duration_count", "gen_ai_server_request_duration_seconds_count":
+			m.Gateway, m.Field = "envoy", "requests"
+		}
+		if m.Field == "" {
+			continue
+		}
+		if m.Gateway == "kong" {
+			m.Provider, m.Model = s.Labels["ai_provider"], s.Labels["ai_model"]
+		} else {
+			m.Provider, m.Model = s.Labels["gen_ai_provider_name"], s.Labels["gen_ai_request_model"]
+			if m.Provider == "" {
+				m.Provider = s.Labels["gen_ai_system"]
+			}
+		}
+		m.Provider = strings.ToLower(strings.TrimSpace(m.Provider))
+		if m.Provider == "" {
+			m.Provider = "unknown"
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// tokenField maps Kong's and OpenTelemetry's token types to a field, or "" for types
+// already included in the others.
+func tokenField(tokenType string) string {
+	switch tokenType {
+	case "prompt_tokens", "input":
+		return "input"
+	case "completion_tokens", "output":
+		return "output"
+	case "total_tokens":
+		return "total"
+	}
+	return ""
+}
+
+// aiGatewayState is the month's consumption per provider and model, and the counters at
+// the last refresh, from which the next refresh adds what changed.
+type aiGatewayState struct {
+	Month     string                                `json:"month"`
+	FirstSeen string                                `json:"firstSeen"`
+	Counters  map[string]float64                    `json:"counters"`
+	Usage     map[string]map[string]aiGatewayCounts `json:"usage"`
+}
+
+// aiGatewayStateLock serializes updates of the stored state, which provider instances
+// scraping the same gateway share.
+var aiGatewayStateLock sync.Mutex
+
+// aiGatewayStateKey is scoped by the metrics URL, so instances scraping different gateways
+// keep separate counts.
+func aiGatewayStateKey(url string) string {
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	return fmt.Sprintf("aigateway_%08x", h.Sum32())
+}
+
+// advance adds the counters' growth since the last refresh to the month's usage. A
+// counter that dropped was reset by a gateway restart, so all of its value is new. On the
+// first refresh ever the counters only become the baseline.
+func (s *aiGatewayState) advance(metrics []aiGatewayMetric, month string, now time.Time) {
+	baseline := s.Counters == nil
+	if baseline {
+		s.Counters = map[string]float64{}
+	}
+	if s.Month != month || s.Usage == nil {
+		s.Month = month
+		s.FirstSeen = now.Format(time.RFC3339)
+		s.Usage = map[string]map[string]aiGatewayCounts{}
+	}
+
+	counters := make(map[string]float64, len(metrics))
+	for _, m := range metrics {
+		counters[m.Series] = m.Value
+		models := s.Usage[m.Provider]
+		if models == nil {
+			models = map[string]aiGatewayCounts{}
+			s.Usage[m.Provider] = models
+		}
+		counts := models[m.Model]
+		last, seen := s.Counters[m.Series]
+		delta := m.Value
+		switch {
+		case seen && m.Value >= last:
+			delta = m.Value - last
+		case !seen && baseline:
+			delta = 0
+		}
+		switch m.Field {
+		case "requests":
+			counts.Requests += delta
+		case "cost":
+			counts.Cost += delta
+		case "input":
+			counts.InputTokens += delta
+		case "output":
+			counts.OutputTokens += delta
+		case "total":
+			counts.TotalTokens += delta
+		}
+		models[m.Model] = counts
+	}
+	// Series the gateway no longer serves are dropped, so a series that returns after a
+	// restart counts from zero
+	s.Counters = counters
+}
+
+// updateAIGatewayState applies the scraped metrics to the stored state and returns it.
+func (p *Plugin) updateAIGatewayState(config *Configuration, metrics []aiGatewayMetric, now time.Time) aiGatewayState {
+	aiGatewayStateLock.Lock()
+	defer aiGatewayStateLock.Unlock()
+
+	key := aiGatewayStateKey(config.aiGatewayURL())
+	var state aiGatewayState
+	if data, appErr := p.API.KVGet(key); appErr == nil && data != nil {
+		json.Unmarshal(data, &state)
+	}
+	state.advance(metrics, now.Format("2006-01"), now)
+	data, _ := json.Marshal(state)
+	if appErr := p.API.KVSet(key, data); appErr != nil {
+		p.API.LogWarn("Failed to store AI gateway usage", "key", key, "error", appErr.Error())
+	}
+	return state
+}
+
+// getAIGatewayStatuses scrapes the gateway's metrics and returns a card per upstream
+// provider, including providers that only have a limit configured.
+func (p *Plugin) getAIGatewayStatuses(config *Configuration) []ServiceStatus {
+	const id = "aigateway"
+	gatewayName := config.aiGatewayName()
+	if config.aiGatewayURL() == "" {
+		return []ServiceStatus{errorStatus(id, gatewayName, "error.aigateway_url_missing")}
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.([]ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newAIGatewayMetricsRequest(config))
+	if err != nil {
+		return []ServiceStatus{errorStatus(id, gatewayName, "error.api", err.Error())}
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return []ServiceStatus{errorStatus(id, gatewayName, "error.api", err.Error())}
+	}
+	if resp.StatusCode != 200 {
+		return []ServiceStatus{errorStatus(id, gatewayName, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))}
+	}
+	metrics := aiGatewayMetrics(parsePromText(body))
+	if len(metrics) == 0 {
+		return []ServiceStatus{errorStatus(id, gatewayName, "error.aigateway_no_metrics")}
+	}
+
+	now := time.Now().UTC()
+	state := p.updateAIGatewayState(config, metrics, now)
+	monthStart, monthEnd := billingCycle(now, 1)
+	gateway := metrics[0].Gateway
+	limits := p.aiGatewayLimits(config)
+
+	providers := map[string]bool{}
+	for provider := range state.Usage {
+		providers[provider] = true
+	}
+	for provider := range limits {
+		providers[provider] = true
+	}
+	names := make([]string, 0, len(providers))
+	for provider := range providers {
+		names = append(names, provider)
+	}
+	sort.Strings(names)
+
+	var statuses []ServiceStatus
+	for _, provider := range names {
+		info := AIGatewayInfo{
+			Gateway: gateway, Provider: provider, HasCost: gateway == "kong", Currency: baseCurrency,
+			Budget: limits[provider].Budget, TokenLimit: limits[provider].Tokens,
+			TrackedSince:   state.FirstSeen,
+			Period:         cyclePeriod(monthStart, monthEnd),
+			CycleEnd:       monthEnd.Format(time.RFC3339),
+			DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+		}
+		for model, counts := range state.Usage[provider] {
+			info.Requests += counts.Requests
+			info.Cost += counts.Cost
+			info.InputTokens += counts.InputTokens
+			info.OutputTokens += counts.OutputTokens
+			info.Tokens += counts.tokens()
+			if counts.Requests > 0 || counts.tokens() > 0 {
+				info.Models = append(info.Models, AIGatewayModelUsage{
+					Model: model, Requests: counts.Requests, Tokens: counts.tokens(), Cost: math.Round(counts.Cost*100) / 100,
+				})
+			}
+		}
+		info.Cost = math.Round(info.Cost*100) / 100
+		sort.Slice(info.Models, func(i, j int) bool {
+			if info.Models[i].Tokens != info.Models[j].Tokens {
+				return info.Models[i].Tokens > info.Models[j].Tokens
+			}
+			return info.Models[i].Model < info.Models[j].Model
+		})
+		info.ModelCount = len(info.Models)
+		if len(info.Models) > aiGatewayModelsShown {
+			info.Models = info.Models[:aiGatewayModelsShown]
+		}
+
+		statuses = append(statuses, ServiceStatus{
+			ID: id + ":" + provider, Name: gatewayName + " · " + provider, Enabled: true,
+			Status: aiGatewayStatus(info, config), Data: info, CachedAt: now.Unix(),
+		})
+	}
+	p.setCache(id, statuses)
+	return statuses
+}
+
+func newAIGatewayMetricsRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", config.aiGatewayURL(), nil)
+	if token := strings.TrimSpace(config.AigatewayToken); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "text/plain")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
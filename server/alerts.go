@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Channel alerts =====
+
+// alertAckProp records who acknowledged a grouped alert post, and when.
+const alertAckProp = "ailimits_ack"
+
+// alertGroupProp marks a grouped alert post, the only posts that can be acknowledged.
+const alertGroupProp = "ailimits_alert_group"
+
+// alertGroupingWindow is how long status changes are collected into one alert post,
+// or zero to post each change on its own.
+func (c *Configuration) alertGroupingWindow() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.AlertGroupingWindow))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return min(d, 30*time.Minute)
+}
+
+// alertBatch is the status changes collected for one alert post.
+type alertBatch struct {
+	channelID string
+	events    []StatusEvent
+}
+
+// postChannelAlerts posts status changes to the configured alert channel as the bot.
+// Changes within the grouping window, like several providers running low at the end of
+// the month, are posted together once the window closes.
+func (p *Plugin) postChannelAlerts(channelID string, events []StatusEvent) {
+	var changes []StatusEvent
+	for _, ev := range events {
+		if ev.Type == EventStatusChange {
+			changes = append(changes, ev)
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+	window := p.getConfiguration().alertGroupingWindow()
+	if window == 0 {
+		for _, ev := range changes {
+			p.postAlert(channelID, []StatusEvent{ev})
+		}
+		return
+	}
+
+	p.alertLock.Lock()
+	if p.alertBatch != nil && p.alertBatch.channelID == channelID {
+		p.alertBatch.events = append(p.alertBatch.events, changes...)
+		p.alertLock.Unlock()
+		return
+	}
+	batch := &alertBatch{channelID: channelID, events: changes}
+	p.alertBatch = batch
+	p.alertLock.Unlock()
+
+	// Post early rather than lose the alerts when the plugin stops
+	select {
+	case <-time.After(window):
+	case <-p.bg.done():
+	}
+	p.alertLock.Lock()
+	if p.alertBatch == batch {
+		p.alertBatch = nil
+	}
+	changes = batch.events
+	p.alertLock.Unlock()
+	p.postAlert(channelID, coalesceAlertEvents(changes))
+}
+
+// coalesceAlertEvents merges changes of the same provider into one, from its first
+// status to its last, and drops providers that ended where they started.
+func coalesceAlertEvents(events []StatusEvent) []StatusEvent {
+	var merged []StatusEvent
+	index := map[string]int{}
+	for _, ev := range events {
+		if i, ok := index[ev.Provider]; ok {
+			from := merged[i].From
+			merged[i] = ev
+			merged[i].From = from
+			continue
+		}
+		index[ev.Provider] = len(merged)
+		merged = append(merged, ev)
+	}
+	var changed []StatusEvent
+	for _, ev := range merged {
+		if ev.From != ev.To {
+			changed = append(changed, ev)
+		}
+	}
+	return changed
+}
+
+// alertSection describes one status change: what changed, the usage and any error.
+func alertSection(ev StatusEvent, locale string) string {
+	s := localizeStatuses([]ServiceStatus{ev.Service}, locale)[0]
+	message := translate(locale, "alert.status_changed", ev.Name, ev.From, ev.To, time.Unix(ev.Timestamp, 0).UTC().Format(time.RFC1123))
+	message += "\n" + summarizeService(s, locale)
+	if text := forecastText(s.Forecast, locale); text != "" {
+		message += "\n" + text
+	}
+	if s.Error != "" {
+		message += "\n" + translate(locale, "alert.error", s.Error)
+	}
+	return message
+}
+
+// postAlert posts one status change as it always was, or several as one post with a
+// section per provider and a single acknowledge button.
+func (p *Plugin) postAlert(channelID string, events []StatusEvent) {
+	if len(events) == 0 {
+		return
+	}
+	locale := p.serverLocale()
+	post := &model.Post{ChannelId: channelID, UserId: p.botUserID}
+	if len(events) == 1 {
+		post.Message = alertSection(events[0], locale)
+	} else {
+		sections := []string{translate(locale, "alert.grouped", len(events))}
+		for _, ev := range events {
+			sections = append(sections, "---\n"+alertSection(ev, locale))
+		}
+		post.Message = strings.Join(sections, "\n")
+		post.AddProp(alertGroupProp, true)
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{{Actions: []*model.PostAction{{
+			Id:   "ack",
+			Name: translate(locale, "alert.ack_button"),
+			Type: model.PostActionTypeButton,
+			Integration: &model.PostActionIntegration{
+				URL: pluginURL + "/api/v1/alerts/ack",
+			},
+		}}}})
+	}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		p.API.LogWarn("Failed to post alert", "channel", channelID, "error", appErr.Error())
+	}
+}
+
+// handleAlertAck acknowledges a grouped alert for everyone in the channel: the button is
+// replaced with who acknowledged it and when. Only members who can read the channel can
+// acknowledge, and only grouped alerts.
+func (p *Plugin) handleAlertAck(w http.ResponseWriter, r *http.Request, userID string) {
+	var req model.PostActionIntegrationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	post, appErr := p.API.GetPost(req.PostId)
+	if appErr != nil || post.UserId != p.botUserID || post.GetProp(alertGroupProp) == nil ||
+		!p.API.HasPermissionToChannel(userID, post.ChannelId, model.PermissionReadChannel) {
+		http.Error(w, `{"error": "not_found", "message": "Alert not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if post.GetProp(alertAckProp) != nil {
+		json.NewEncoder(w).Encode(model.PostActionIntegrationResponse{})
+		return
+	}
+	name := userID
+	if user, appErr := p.API.GetUser(userID); appErr == nil {
+		name = user.Username
+	}
+	now := time.Now()
+	post.Message += "\n\n" + translate(p.serverLocale(), "alert.acked", name, now.UTC().Format(time.RFC1123))
+	post.DelProp("attachments")
+	post.AddProp(alertAckProp, map[string]any{"userId": userID, "at": now.Unix()})
+	if _, appErr := p.API.UpdatePost(post); appErr != nil {
+		p.API.LogWarn("Failed to acknowledge an alert", "post_id", post.Id, "error", appErr.Error())
+	}
+	json.NewEncoder(w).Encode(model.PostActionIntegrationResponse{})
+}
+
+// alertRecipients resolves the Alert Recipients setting, usernames with or without "@",
+// to user IDs. Unknown users are logged and skipped.
+func (p *Plugin) alertRecipients(config *Configuration) []string {
+	var ids []string
+	for _, name := range splitList(config.AlertRecipients) {
+		user, appErr := p.API.GetUserByUsername(strings.TrimPrefix(name, "@"))
+		if appErr != nil {
+			p.API.LogWarn("Unknown alert recipient", "username", name, "error", appErr.Error())
+			continue
+		}
+		ids = append(ids, user.Id)
+	}
+	return ids
+}
+
+// notifyAlertRecipients sends the configured recipients a DM for each provider that
+// crossed into warning or error, in each recipient's language. Changes back to ok only go
+// to the alert channel.
+func (p *Plugin) notifyAlertRecipients(config *Configuration, events []StatusEvent) {
+	var crossed []StatusEvent
+	for _, ev := range events {
+		if ev.Type == EventThreshold {
+			crossed = append(crossed, ev)
+		}
+	}
+	if len(crossed) == 0 {
+		return
+	}
+	for _, userID := range p.alertRecipients(config) {
+		locale := p.getUserContext(userID).Locale
+		var sections []string
+		if len(crossed) > 1 {
+			sections = append(sections, translate(locale, "alert.grouped", len(crossed)))
+		}
+		for i, ev := range crossed {
+			if i > 0 || len(crossed) > 1 {
+				sections = append(sections, "---")
+			}
+			sections = append(sections, alertSection(ev, locale))
+		}
+		p.sendDirectMessage(userID, strings.Join(sections, "\n"))
+	}
+}
+
+// notifyAdminsReauth sends every system admin a DM explaining how to reauthorize a provider.
+func (p *Plugin) notifyAdminsReauth(ev StatusEvent) {
+	locale := p.serverLocale()
+	s := localizeStatuses([]ServiceStatus{ev.Service}, locale)[0]
+	p.notifyAdmins(translate(locale, "alert.reauth", ev.Name) + "\n\n" + s.Error)
+}
+
+// notifyAdmins sends a DM from the bot to every system admin.
+func (p *Plugin) notifyAdmins(message string) {
+	for page := 0; ; page++ {
+		admins, appErr := p.API.GetUsers(&model.UserGetOptions{Role: model.SystemAdminRoleId, Active: true, Page: page, PerPage: 100})
+		if appErr != nil {
+			p.API.LogWarn("Failed to list system admins", "error", appErr.Error())
+			return
+		}
+		for _, admin := range admins {
+			p.sendDirectMessage(admin.Id, message)
+		}
+		if len(admins) < 100 {
+			return
+		}
+	}
+}
+
+// sendDirectMessage posts a message from the bot in its DM channel with the user.
+func (p *Plugin) sendDirectMessage(userID, message string) {
+	channel, appErr := p.API.GetDirectChannel(p.botUserID, userID)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get direct channel", "user_id", userID, "error", appErr.Error())
+		return
+	}
+	post := &model.Post{ChannelId: channel.Id, UserId: p.botUserID, Message: message}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		p.API.LogWarn("Failed to send direct message", "user_id", userID, "error", appErr.Error())
+	}
+}
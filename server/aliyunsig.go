@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Alibaba Cloud RPC signature (version 1.0) =====
+
+// aliyunCredentials is a RAM AccessKey pair.
+type aliyunCredentials struct {
+	AccessKeyID     string
+	AccessKeySecret string
+}
+
+// newAliyunRPCRequest builds a signed GET request for an RPC-style OpenAPI such as BSS,
+// e.g. action "QueryBillOverview" with version "2017-12-14".
+func newAliyunRPCRequest(creds aliyunCredentials, host, action, version string, params map[string]string, now time.Time) *http.Request {
+	query := map[string]string{
+		"Action":           action,
+		"Version":          version,
+		"Format":           "JSON",
+		"AccessKeyId":      creds.AccessKeyID,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   strconv.FormatInt(now.UnixNano(), 36),
+		"Timestamp":        now.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	for k, v := range params {
+		query[k] = v
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, aliyunEscape(k)+"="+aliyunEscape(query[k]))
+	}
+	canonical := strings.Join(pairs, "&")
+
+	mac := hmac.New(sha1.New, []byte(creds.AccessKeySecret+"&"))
+	mac.Write([]byte("GET&" + aliyunEscape("/") + "&" + aliyunEscape(canonical)))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	req, _ := http.NewRequest("GET", "https://"+host+"/?"+canonical+"&Signature="+aliyunEscape(signature), nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// aliyunEscape is RFC 3986 percent-encoding, which the signature is computed over.
+func aliyunEscape(s string) string {
+	s = neturl.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	return strings.ReplaceAll(s, "%7E", "~")
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Weekly cost allocation =====
+
+const (
+	allocationSnapshotKey = "allocation_snapshot"
+	allocationLastSentKey = "allocation_last_sent"
+)
+
+// teamShare is the part of a card's spend charged to a team.
+type teamShare struct {
+	Team    string
+	Percent float64
+}
+
+// parseCostAllocation parses the Cost Allocation setting: one "<card, instance or
+// provider ID>=<teams>" per line, where teams is a team or a split like "ML:70, Search:30".
+// Teams without a percentage share what the others leave equally; otherwise what's left
+// is unallocated.
+func parseCostAllocation(raw string) (map[string][]teamShare, error) {
+	rules := map[string][]teamShare{}
+	for id, value := range parseProviderNames(raw) {
+		var shares []teamShare
+		var unsized []int
+		left := 100.0
+		for _, part := range strings.Split(value, ",") {
+			team, pct, hasPct := strings.Cut(strings.TrimSpace(part), ":")
+			team = strings.TrimSpace(team)
+			if team == "" {
+				continue
+			}
+			if !hasPct {
+				unsized = append(unsized, len(shares))
+				shares = append(shares, teamShare{Team: team})
+				continue
+			}
+			p, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(pct), "%"), 64)
+			if err != nil || p <= 0 || p > 100 {
+				return nil, fmt.Errorf("%s: %q is not a percentage", id, pct)
+			}
+			shares = append(shares, teamShare{Team: team, Percent: p})
+			left -= p
+		}
+		if left < -0.01 {
+			return nil, fmt.Errorf("%s: shares add up to more than 100%%", id)
+		}
+		for _, i := range unsized {
+			shares[i].Percent = left / float64(len(unsized))
+		}
+		if len(unsized) == 0 && left > 0.01 {
+			shares = append(shares, teamShare{Percent: left})
+		}
+		rules[id] = shares
+	}
+	return rules, nil
+}
+
+// costSnapshot is every card's spend this billing cycle when the last report was posted,
+// so the next report can charge only the week's spend.
+type costSnapshot struct {
+	At    int64                   `json:"at"`
+	Costs map[string]snapshotCost `json:"costs"`
+}
+
+type snapshotCost struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// CostAllocation is the spend of a period charged to teams, in the reporting currency.
+type CostAllocation struct {
+	Since       int64      `json:"since"` // start of the period, 0 for the billing cycles so far
+	Until       int64      `json:"until"`
+	Currency    string     `json:"currency"`
+	Total       float64    `json:"total"`
+	Teams       []TeamCost `json:"teams"`
+	Unconverted []string   `json:"unconverted,omitempty"` // providers whose currency has no exchange rate
+}
+
+// TeamCost is one team's part of the spend. Spend no rule charges to a team has an
+// empty team.
+type TeamCost struct {
+	Team      string         `json:"team"`
+	Amount    float64        `json:"amount"`
+	Percent   float64        `json:"percent"`
+	Providers []ProviderCost `json:"providers"`
+}
+
+// ProviderCost is a card's spend charged to a team.
+type ProviderCost struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// teamSharesFor finds the rule for a card: its own ID, or the closest instance or
+// provider ID it's scoped under, e.g. "openai:ml" for "openai:ml:org-abc". Cards of
+// provider instances without a rule go to the instance's label.
+func teamSharesFor(cardID string, rules map[string][]teamShare, instanceTeams map[string]string) []teamShare {
+	for id := cardID; id != ""; {
+		if shares, ok := rules[id]; ok {
+			return shares
+		}
+		if team, ok := instanceTeams[id]; ok {
+			return []teamShare{{Team: team, Percent: 100}}
+		}
+		i := strings.LastIndex(id, ":")
+		if i < 0 {
+			break
+		}
+		id = id[:i]
+	}
+	return []teamShare{{Percent: 100}}
+}
+
+// allocateCosts charges the spend since the previous snapshot to teams and returns the
+// new snapshot. Without a previous snapshot the billing cycles so far are charged. A
+// card whose spend dropped since the snapshot started a new cycle, so all of its spend
+// counts.
+func allocateCosts(services []ServiceStatus, prev *costSnapshot, rules map[string][]teamShare, instanceTeams map[string]string, config *Configuration, now time.Time) (*CostAllocation, costSnapshot) {
+	snapshot := costSnapshot{At: now.Unix(), Costs: map[string]snapshotCost{}}
+	alloc := &CostAllocation{Until: now.Unix(), Currency: config.reportingCurrency(), Teams: []TeamCost{}}
+	if prev != nil {
+		alloc.Since = prev.At
+	}
+
+	teams := map[string]*TeamCost{}
+	for _, s := range services {
+		usage := computeUsage(s)
+		if !s.Enabled || usage == nil || usage.Cost == nil {
+			continue
+		}
+		current := snapshotCost{Amount: *usage.Cost, Currency: strings.ToUpper(usage.Currency)}
+		snapshot.Costs[s.ID] = current
+		spend := current.Amount
+		if prev != nil {
+			if last, ok := prev.Costs[s.ID]; ok && last.Currency == current.Currency && last.Amount <= current.Amount {
+				spend -= last.Amount
+			}
+		}
+		amount, ok := config.convertMoney(spend, current.Currency, alloc.Currency)
+		if !ok {
+			alloc.Unconverted = append(alloc.Unconverted, s.Name)
+			continue
+		}
+		if amount <= 0 {
+			continue
+		}
+		for _, share := range teamSharesFor(s.ID, rules, instanceTeams) {
+			team := teams[share.Team]
+			if team == nil {
+				team = &TeamCost{Team: share.Team}
+				teams[share.Team] = team
+			}
+			part := amount * share.Percent / 100
+			team.Amount += part
+			team.Providers = append(team.Providers, ProviderCost{ID: s.ID, Name: s.Name, Amount: math.Round(part*100) / 100})
+			alloc.Total += part
+		}
+	}
+
+	for _, team := range teams {
+		if alloc.Total > 0 {
+			team.Percent = math.Round(team.Amount/alloc.Total*1000) / 10
+		}
+		team.Amount = math.Round(team.Amount*100) / 100
+		sort.Slice(team.Providers, func(i, j int) bool { return team.Providers[i].Amount > team.Providers[j].Amount })
+		alloc.Teams = append(alloc.Teams, *team)
+	}
+	// Largest first, with the unallocated spend last
+	sort.Slice(alloc.Teams, func(i, j int) bool {
+		a, b := alloc.Teams[i], alloc.Teams[j]
+		if (a.Team == "") != (b.Team == "") {
+			return b.Team == ""
+		}
+		return a.Amount > b.Amount
+	})
+	alloc.Total = math.Round(alloc.Total*100) / 100
+	return alloc, snapshot
+}
+
+// instanceTeams maps each provider instance to its label, as the team its spend belongs to.
+func (p *Plugin) instanceTeams() map[string]string {
+	teams := map[string]string{}
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+	for _, inst := range p.instances {
+		team := inst.Label
+		if team == "" {
+			team = inst.ID
+		}
+		teams[inst.Type+":"+inst.ID] = team
+	}
+	return teams
+}
+
+// currentCostAllocation allocates the spend since the last report, and returns the
+// snapshot to store once it's posted.
+func (p *Plugin) currentCostAllocation(services []ServiceStatus, now time.Time) (*CostAllocation, costSnapshot, error) {
+	config := p.getConfiguration()
+	rules, err := parseCostAllocation(config.CostAllocation)
+	if err != nil {
+		return nil, costSnapshot{}, err
+	}
+	var prev *costSnapshot
+	if data, appErr := p.API.KVGet(allocationSnapshotKey); appErr == nil && data != nil {
+		var s costSnapshot
+		if json.Unmarshal(data, &s) == nil {
+			prev = &s
+		}
+	}
+	alloc, snapshot := allocateCosts(services, prev, rules, p.instanceTeams(), config, now)
+	return alloc, snapshot, nil
+}
+
+// runCostReportJob posts the weekly cost allocation to the cost report channel on
+// Mondays at the digest hour.
+func (p *Plugin) runCostReportJob() {
+	config := p.getConfiguration()
+	if config.CostReportChannelId == "" {
+		return
+	}
+	hour, minute := config.digestTime()
+	now := time.Now()
+	if now.Weekday() != time.Monday || now.Hour() != hour || now.Minute() < minute {
+		return
+	}
+	if !p.claimPeriod(allocationLastSentKey, now.Format("2006-01-02")) {
+		return
+	}
+
+	services := p.collectStatuses()
+	p.trackStatusChanges(services)
+	locale := p.serverLocale()
+	alloc, snapshot, err := p.currentCostAllocation(services, now)
+	if err != nil {
+		p.API.LogWarn("Cost allocation is invalid", "error", err.Error())
+		p.notifyAdmins(translate(locale, "allocation.invalid", err.Error()))
+		return
+	}
+
+	message := costAllocationText(alloc, locale) + p.openAITopSpendersText(config, locale, now)
+	post := &model.Post{ChannelId: config.CostReportChannelId, UserId: p.botUserID, Message: message}
+	if _, appErr := p.API.CreatePost(post); appErr != nil {
+		p.API.LogWarn("Failed to post the cost report", "channel", config.CostReportChannelId, "error", appErr.Error())
+		return
+	}
+	data, _ := json.Marshal(snapshot)
+	if appErr := p.API.KVSet(allocationSnapshotKey, data); appErr != nil {
+		p.API.LogWarn("Failed to store the cost snapshot", "error", appErr.Error())
+	}
+}
+
+// costAllocationText renders the allocation as a table per team.
+func costAllocationText(alloc *CostAllocation, locale string) string {
+	var b strings.Builder
+	until := time.Unix(alloc.Until, 0).UTC().Format("Jan 2")
+	if alloc.Since > 0 {
+		b.WriteString(translate(locale, "allocation.title", time.Unix(alloc.Since, 0).UTC().Format("Jan 2"), until))
+	} else {
+		b.WriteString(translate(locale, "allocation.title_cycle", until))
+	}
+	b.WriteString("\n\n")
+	if len(alloc.Teams) == 0 {
+		b.WriteString(translate(locale, "allocation.none"))
+	} else {
+		b.WriteString(translate(locale, "allocation.table_header") + "\n|:--|--:|--:|:--|\n")
+		for _, team := range alloc.Teams {
+			name := team.Team
+			if name == "" {
+				name = translate(locale, "allocation.unallocated")
+			}
+			var providers []string
+			for _, pc := range team.Providers {
+				providers = append(providers, pc.Name+" "+formatMoney(pc.Amount, alloc.Currency, 2))
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %.1f%% | %s |\n", name, formatMoney(team.Amount, alloc.Currency, 2), team.Percent, strings.Join(providers, ", ")))
+		}
+		b.WriteString(fmt.Sprintf("| **%s** | **%s** | | |\n", translate(locale, "allocation.total"), formatMoney(alloc.Total, alloc.Currency, 2)))
+	}
+	if len(alloc.Unconverted) > 0 {
+		b.WriteString("\n" + translate(locale, "allocation.unconverted", strings.Join(alloc.Unconverted, ", ")))
+	}
+	return b.String()
+}
+
+// handleCostAllocation serves GET /api/v1/admin/allocation: the spend since the last
+// weekly report, allocated as the next report will.
+func (p *Plugin) handleCostAllocation(w http.ResponseWriter, r *http.Request) {
+	alloc, _, err := p.currentCostAllocation(p.collectStatuses(), time.Now())
+	if err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "invalid_allocation", "message": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alloc)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Amazon Q Developer (IAM credentials) =====
+
+// amazonQMaxMemberPages bounds the subscription group listing, 100 members per page.
+const amazonQMaxMemberPages = 20
+
+// AmazonQInfo is the month-to-date Amazon Q spend from Cost Explorer, the subscribed users
+// from the IAM Identity Center group the subscription is assigned to, and request counts
+// from CloudWatch. Agentic requests are limited per user each month; AWS only publishes
+// account-wide counts, so they are compared against the limit of every seat combined.
+type AmazonQInfo struct {
+	TotalCost       float64 `json:"totalCost"`
+	Currency        string  `json:"currency"`
+	Period          string  `json:"period"`
+	CycleEnd        string  `json:"cycleEnd"`
+	DaysUntilReset  int     `json:"daysUntilReset"`
+	Region          string  `json:"region"`
+	HasSeats        bool    `json:"hasSeats"`
+	Seats           float64 `json:"seats"`
+	HasMetrics      bool    `json:"hasMetrics"`
+	Requests        float64 `json:"requests"`
+	AgenticRequests float64 `json:"agenticRequests"`
+	AgenticLimit    float64 `json:"agenticLimit"` // per user per month
+}
+
+// agenticPercent is the share of the seats' combined agentic request limit used this month.
+func (q AmazonQInfo) agenticPercent() (float64, bool) {
+	if !q.HasMetrics || !q.HasSeats || q.Seats <= 0 || q.AgenticLimit <= 0 {
+		return 0, false
+	}
+	return q.AgenticRequests / (q.Seats * q.AgenticLimit) * 100, true
+}
+
+func (q AmazonQInfo) summary(locale string) string {
+	text := translate(locale, "summary.spent", formatMoney(q.TotalCost, q.Currency, 2), q.Period)
+	if q.HasSeats {
+		text += ", " + translate(locale, "summary.amazonq_seats", formatCount(q.Seats))
+	}
+	if q.HasMetrics && q.HasSeats {
+		text += ", " + translate(locale, "summary.amazonq_agentic", formatCount(q.AgenticRequests), formatCount(q.Seats*q.AgenticLimit))
+	} else if q.HasMetrics {
+		text += ", " + translate(locale, "summary.amazonq_requests", formatCount(q.Requests), formatCount(q.AgenticRequests))
+	}
+	return text
+}
+
+func (q AmazonQInfo) compact(name, locale string) string {
+	if pct, ok := q.agenticPercent(); ok {
+		return fmt.Sprintf("%s %.0f%%", name, pct)
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(q.TotalCost, q.Currency, 0))
+}
+
+func (q AmazonQInfo) usagePercent() (float64, bool) {
+	return q.agenticPercent()
+}
+
+func (q AmazonQInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(q.CycleEnd)}}
+}
+
+// amazonQMembershipsResponse is Identity Store's ListGroupMemberships.
+type amazonQMembershipsResponse struct {
+	GroupMemberships []struct {
+		MembershipID string `json:"MembershipId" schema:"required"`
+		MemberID     struct {
+			UserID string `json:"UserId"`
+		} `json:"MemberId"`
+		GroupID         string `json:"GroupId"`
+		IdentityStoreID string `json:"IdentityStoreId"`
+	} `json:"GroupMemberships" schema:"required"`
+	NextToken string `json:"NextToken"`
+}
+
+func (c *Configuration) amazonQCredentials() awsCredentials {
+	return awsCredentials{
+		AccessKeyID:     strings.TrimSpace(c.AmazonqAccessKeyId),
+		SecretAccessKey: strings.TrimSpace(c.AmazonqSecretAccessKey),
+		SessionToken:    strings.TrimSpace(c.AmazonqSessionToken),
+	}
+}
+
+// amazonQRegion is the region of the Amazon Q Developer profile and Identity Center
+// instance, us-east-1 by default.
+func (c *Configuration) amazonQRegion() string {
+	if region := strings.TrimSpace(c.AmazonqRegion); region != "" {
+		return region
+	}
+	return "us-east-1"
+}
+
+// amazonQAgenticLimit is the monthly agentic request limit per user, 1000 on Pro by default.
+func (c *Configuration) amazonQAgenticLimit() float64 {
+	if limit, err := strconv.ParseFloat(strings.TrimSpace(c.AmazonqAgenticLimit), 64); err == nil && limit > 0 {
+		return limit
+	}
+	return 1000
+}
+
+func (p *Plugin) getAmazonQStatus(config *Configuration) ServiceStatus {
+	const id, name = "amazonq", "Amazon Q Developer"
+	creds := config.amazonQCredentials()
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return errorStatus(id, name, "error.aws_credentials_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := AmazonQInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+		Region:         config.amazonQRegion(),
+		AgenticLimit:   config.amazonQAgenticLimit(),
+	}
+
+	// The same per-service cost query as Bedrock; subscriptions are billed as "Amazon Q"
+	body, err := p.awsCall(client, newBedrockCostRequest(creds, monthStart, now))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	var costs bedrockCostResponse
+	drift, err := decodeResponse(body, &costs)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	for _, result := range costs.ResultsByTime {
+		for _, group := range result.Groups {
+			if len(group.Keys) == 0 || !strings.HasPrefix(group.Keys[0], "Amazon Q") {
+				continue
+			}
+			info.TotalCost += float64(group.Metrics.UnblendedCost.Amount)
+			if unit := group.Metrics.UnblendedCost.Unit; unit != "" {
+				info.Currency = strings.ToUpper(unit)
+			}
+		}
+	}
+
+	// Seats and metrics are optional: the card still shows spend without them
+	if strings.TrimSpace(config.AmazonqIdentityStoreId) != "" && strings.TrimSpace(config.AmazonqGroupId) != "" {
+		seats, d, err := p.amazonQSeats(client, config, creds, now)
+		if err != nil {
+			p.API.LogWarn("Failed to list Amazon Q Developer subscribers", "error", err.Error())
+		} else {
+			drift.merge(d)
+			info.HasSeats = true
+			info.Seats = seats
+		}
+	}
+
+	if body, err := p.awsCall(client, newAmazonQMetricsRequest(creds, info.Region, monthStart, now)); err != nil {
+		p.API.LogWarn("Failed to fetch Amazon Q Developer metrics", "error", err.Error())
+	} else {
+		var metrics bedrockMetricsResponse
+		d, err := decodeResponse(body, &metrics)
+		if err != nil {
+			p.API.LogWarn("Failed to parse Amazon Q Developer metrics", "error", err.Error())
+		} else {
+			drift.merge(d)
+			info.HasMetrics = true
+			for _, result := range metrics.MetricDataResults {
+				total := 0.0
+				for _, v := range result.Values {
+					total += float64(v)
+				}
+				switch result.ID {
+				case "requests":
+					info.Requests = total
+				case "agentic":
+					info.AgenticRequests = total
+				}
+			}
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: amazonQStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// amazonQStatus is an error once the seats' combined agentic limit is used up, by which
+// point the average user is out of requests, and a warning close to it.
+func amazonQStatus(info AmazonQInfo, config *Configuration) string {
+	pct, ok := info.agenticPercent()
+	switch {
+	case !ok:
+		return "ok"
+	case pct >= 100:
+		return "error"
+	case pct > config.warningPercent(80):
+		return "warning"
+	}
+	return "ok"
+}
+
+// amazonQSeats counts the members of the Identity Center group the subscription is assigned to.
+func (p *Plugin) amazonQSeats(client *http.Client, config *Configuration, creds awsCredentials, now time.Time) (float64, schemaDrift, error) {
+	var drift schemaDrift
+	seats := 0.0
+	token := ""
+	for page := 0; page < amazonQMaxMemberPages; page++ {
+		body, err := p.awsCall(client, newAmazonQMembershipsRequest(config, creds, token, now))
+		if err != nil {
+			return 0, drift, err
+		}
+		var members amazonQMembershipsResponse
+		d, err := decodeResponse(body, &members)
+		if err != nil {
+			return 0, drift, err
+		}
+		drift.merge(d)
+		seats += float64(len(members.GroupMemberships))
+		if token = members.NextToken; token == "" {
+			break
+		}
+	}
+	return seats, drift, nil
+}
+
+// newAmazonQMembershipsRequest lists a page of the subscription group's members.
+func newAmazonQMembershipsRequest(config *Configuration, creds awsCredentials, token string, now time.Time) *http.Request {
+	params := map[string]any{
+		"IdentityStoreId": strings.TrimSpace(config.AmazonqIdentityStoreId),
+		"GroupId":         strings.TrimSpace(config.AmazonqGroupId),
+		"MaxResults":      100,
+	}
+	if token != "" {
+		params["NextToken"] = token
+	}
+	payload, _ := json.Marshal(params)
+	region := config.amazonQRegion()
+	return newAWSJSONRequest(creds, "identitystore", region, "identitystore."+region+".amazonaws.com",
+		"AWSIdentityStore.ListGroupMemberships", "application/x-amz-json-1.1", payload, now)
+}
+
+// newAmazonQMetricsRequest sums the month's requests and agentic requests across all users.
+func newAmazonQMetricsRequest(creds awsCredentials, region string, start, now time.Time) *http.Request {
+	query := func(id, metric string) map[string]any {
+		return map[string]any{
+			"Id":         id,
+			"Expression": fmt.Sprintf(`SUM(SEARCH('{AWS/Q} MetricName="%s"', 'Sum', 86400))`, metric),
+			"Period":     86400,
+		}
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"MetricDataQueries": []map[string]any{
+			query("requests", "Invocations"),
+			query("agentic", "AgenticRequests"),
+		},
+		"StartTime": start.Unix(),
+		"EndTime":   now.Unix(),
+	})
+	return newAWSJSONRequest(creds, "monitoring", region, "monitoring."+region+".amazonaws.com",
+		"GraniteServiceVersion20100801.GetMetricData", "application/x-amz-json-1.0", payload, now)
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Anthropic API (console.anthropic.com, Admin API key) =====
+
+// anthropicTierSpendLimits is the monthly API spend (USD) each usage tier is capped at;
+// Anthropic rejects requests past it until the month ends or the tier rises.
+var anthropicTierSpendLimits = []float64{0, 100, 500, 1000, 5000}
+
+// AnthropicUsageInfo is the organization's API spend and token usage for the current
+// calendar month. Unlike ClaudeUsageInfo it covers pay-as-you-go API keys, not claude.ai plans.
+type AnthropicUsageInfo struct {
+	TotalCost       float64 `json:"totalCost"`
+	Budget          float64 `json:"budget,omitempty"`
+	Currency        string  `json:"currency"`
+	Period          string  `json:"period"`
+	CycleEnd        string  `json:"cycleEnd"`
+	DaysUntilReset  int     `json:"daysUntilReset"`
+	InputTokens     float64 `json:"inputTokens"`
+	OutputTokens    float64 `json:"outputTokens"`
+	CacheReadTokens float64 `json:"cacheReadTokens"`
+	// The configured usage tier and its monthly spend limit; the API doesn't report them
+	Tier       int                       `json:"tier,omitempty"`
+	HasTier    bool                      `json:"hasTier"`
+	TierLimit  float64                   `json:"tierLimit,omitempty"`
+	Workspaces []AnthropicWorkspaceSpend `json:"workspaces,omitempty"` // highest spend first
+}
+
+// AnthropicWorkspaceSpend is one workspace's spend this month. ID is empty for the
+// organization's default workspace.
+type AnthropicWorkspaceSpend struct {
+	ID   string  `json:"id,omitempty"`
+	Name string  `json:"name"`
+	Cost float64 `json:"cost"`
+}
+
+// anthropicTier is the configured usage tier (1–4), or false when unset.
+func (c *Configuration) anthropicTier() (int, bool) {
+	tier, err := strconv.Atoi(strings.TrimSpace(c.AnthropicUsageTier))
+	if err != nil || tier < 1 || tier >= len(anthropicTierSpendLimits) {
+		return 0, false
+	}
+	return tier, true
+}
+
+func (d AnthropicUsageInfo) totalTokens() float64 {
+	return d.InputTokens + d.OutputTokens + d.CacheReadTokens
+}
+
+// spendLimit is the lower of the budget and the tier's limit, whichever are set.
+func (d AnthropicUsageInfo) spendLimit() float64 {
+	if d.TierLimit > 0 && (d.Budget <= 0 || d.TierLimit < d.Budget) {
+		return d.TierLimit
+	}
+	return d.Budget
+}
+
+func (d AnthropicUsageInfo) summary(locale string) string {
+	text := translate(locale, "summary.anthropic_spent", formatMoney(d.TotalCost, d.Currency, 2), formatCount(d.totalTokens()))
+	if limit := d.spendLimit(); limit > 0 {
+		text = translate(locale, "summary.anthropic_budget", formatMoney(d.TotalCost, d.Currency, 2), formatMoney(limit, d.Currency, 0), formatCount(d.totalTokens()))
+	}
+	if d.HasTier {
+		text += " " + translate(locale, "summary.anthropic_tier", d.Tier, formatMoney(d.TierLimit, d.Currency, 0))
+	}
+	if len(d.Workspaces) > 1 {
+		top := d.Workspaces[0]
+		text += " · " + translate(locale, "summary.anthropic_top_workspace", top.Name, formatMoney(top.Cost, d.Currency, 2))
+	}
+	return text
+}
+
+func (d AnthropicUsageInfo) compact(name, locale string) string {
+	if limit := d.spendLimit(); limit > 0 {
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(d.TotalCost, d.Currency, 0), formatMoney(limit, d.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(d.TotalCost, d.Currency, 0))
+}
+
+func (d AnthropicUsageInfo) usagePercent() (float64, bool) {
+	if limit := d.spendLimit(); limit > 0 {
+		return d.TotalCost / limit * 100, true
+	}
+	return 0, false
+}
+
+func (d AnthropicUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(d.CycleEnd)}}
+}
+
+// anthropicCostResponse is a page of /v1/organizations/cost_report. Amounts are decimal
+// strings in the currency's smallest unit (cents).
+type anthropicCostResponse struct {
+	Data []struct {
+		StartingAt string `json:"starting_at"`
+		EndingAt   string `json:"ending_at"`
+		Results    []struct {
+			Currency    string    `json:"currency"`
+			Amount      flexFloat `json:"amount" schema:"required"`
+			WorkspaceID *string   `json:"workspace_id"`
+			Description *string   `json:"description"`
+			CostType    *string   `json:"cost_type"`
+			ContextWin  *string   `json:"context_window"`
+			Model       *string   `json:"model"`
+			ServiceTier *string   `json:"service_tier"`
+			TokenType   *string   `json:"token_type"`
+		} `json:"results"`
+	} `json:"data" schema:"required"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+}
+
+// anthropicWorkspacesResponse is a page of /v1/organizations/workspaces.
+type anthropicWorkspacesResponse struct {
+	Data []struct {
+		ID   string `json:"id" schema:"required"`
+		Name string `json:"name" schema:"required"`
+	} `json:"data" schema:"required"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+// anthropicUsageResponse is a page of /v1/organizations/usage_report/messages.
+type anthropicUsageResponse struct {
+	Data []struct {
+		StartingAt string `json:"starting_at"`
+		EndingAt   string `json:"ending_at"`
+		Results    []struct {
+			UncachedInputTokens flexFloat `json:"uncached_input_tokens" schema:"required"`
+			CacheCreation       struct {
+				Ephemeral1hInputTokens flexFloat `json:"ephemeral_1h_input_tokens"`
+				Ephemeral5mInputTokens flexFloat `json:"ephemeral_5m_input_tokens"`
+			} `json:"cache_creation"`
+			CacheReadInputTokens flexFloat `json:"cache_read_input_tokens"`
+			OutputTokens         flexFloat `json:"output_tokens" schema:"required"`
+			ServerToolUse        *struct {
+				WebSearchRequests flexFloat `json:"web_search_requests"`
+			} `json:"server_tool_use"`
+			APIKeyID    *string `json:"api_key_id"`
+			WorkspaceID *string `json:"workspace_id"`
+			Model       *string `json:"model"`
+			ServiceTier *string `json:"service_tier"`
+			ContextWin  *string `json:"context_window"`
+		} `json:"results"`
+	} `json:"data" schema:"required"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+}
+
+// anthropicMaxPages bounds pagination of the monthly reports (daily buckets, up to 31 per page).
+const anthropicMaxPages = 5
+
+func (p *Plugin) getAnthropicStatus(config *Configuration) ServiceStatus {
+	const id, name = "anthropic", "Anthropic API"
+	if config.AnthropicAdminKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+	// Usage and cost reports are only open to Admin API keys
+	if !strings.HasPrefix(config.AnthropicAdminKey, "sk-ant-admin") {
+		return errorStatus(id, name, "error.anthropic_admin_key_required")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	now := time.Now().UTC()
+	cycleStart, cycleEnd := billingCycle(now, 1)
+	info := AnthropicUsageInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(cycleStart, cycleEnd),
+		CycleEnd:       cycleEnd.Format(time.RFC3339),
+		DaysUntilReset: int(cycleEnd.Sub(now).Hours() / 24),
+	}
+
+	var drift schemaDrift
+	workspaceCost := map[string]float64{}
+	costParams := neturl.Values{"group_by[]": {"workspace_id"}}
+	err := p.anthropicReport(client, config, "cost_report", costParams, cycleStart, func(body []byte) (bool, string, error) {
+		var page anthropicCostResponse
+		d, err := decodeResponse(body, &page)
+		if err != nil {
+			return false, "", err
+		}
+		drift.merge(d)
+		for _, bucket := range page.Data {
+			for _, r := range bucket.Results {
+				if r.Currency != "" {
+					info.Currency = strings.ToUpper(r.Currency)
+				}
+				info.TotalCost += float64(r.Amount) / 100
+				workspace := ""
+				if r.WorkspaceID != nil {
+					workspace = *r.WorkspaceID
+				}
+				workspaceCost[workspace] += float64(r.Amount) / 100
+			}
+		}
+		return page.HasMore, page.NextPage, nil
+	})
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+
+	err = p.anthropicReport(client, config, "usage_report/messages", nil, cycleStart, func(body []byte) (bool, string, error) {
+		var page anthropicUsageResponse
+		d, err := decodeResponse(body, &page)
+		if err != nil {
+			return false, "", err
+		}
+		drift.merge(d)
+		for _, bucket := range page.Data {
+			for _, r := range bucket.Results {
+				info.InputTokens += float64(r.UncachedInputTokens + r.CacheCreation.Ephemeral1hInputTokens + r.CacheCreation.Ephemeral5mInputTokens)
+				info.OutputTokens += float64(r.OutputTokens)
+				info.CacheReadTokens += float64(r.CacheReadInputTokens)
+			}
+		}
+		return page.HasMore, page.NextPage, nil
+	})
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	// Names are only decoration, so a failed listing leaves the IDs
+	names, err := p.anthropicWorkspaceNames(client, config, &drift)
+	if err != nil {
+		p.API.LogDebug("Failed to list Anthropic workspaces", "error", err.Error())
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+	info.Workspaces = anthropicWorkspaceSpend(workspaceCost, names)
+
+	info.Budget = p.budgetIn(config, id, config.AnthropicMonthlyBudget, info.Currency)
+	if tier, ok := config.anthropicTier(); ok {
+		info.Tier, info.HasTier = tier, true
+		if limit, ok := config.convertMoney(anthropicTierSpendLimits[tier], "USD", info.Currency); ok {
+			info.TierLimit = limit
+		}
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: anthropicStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// anthropicStatus is the worse of the spend against the budget and against the tier's
+// monthly limit.
+func anthropicStatus(info AnthropicUsageInfo, config *Configuration) string {
+	status := budgetStatus(info.TotalCost, info.Budget, config)
+	if tierStatus := budgetStatus(info.TotalCost, info.TierLimit, config); statusSeverity(tierStatus) > statusSeverity(status) {
+		status = tierStatus
+	}
+	return status
+}
+
+// anthropicWorkspaceSpend lists the workspaces that spent anything, highest first. The
+// default workspace has no ID.
+func anthropicWorkspaceSpend(cost map[string]float64, names map[string]string) []AnthropicWorkspaceSpend {
+	var workspaces []AnthropicWorkspaceSpend
+	for id, amount := range cost {
+		if amount <= 0 {
+			continue
+		}
+		name := names[id]
+		switch {
+		case id == "":
+			name = "Default"
+		case name == "":
+			name = id
+		}
+		workspaces = append(workspaces, AnthropicWorkspaceSpend{ID: id, Name: name, Cost: amount})
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		if workspaces[i].Cost != workspaces[j].Cost {
+			return workspaces[i].Cost > workspaces[j].Cost
+		}
+		return workspaces[i].Name < workspaces[j].Name
+	})
+	return workspaces
+}
+
+// anthropicWorkspaceNames maps the organization's workspace IDs to their names.
+func (p *Plugin) anthropicWorkspaceNames(client *http.Client, config *Configuration, drift *schemaDrift) (map[string]string, error) {
+	names := map[string]string{}
+	after := ""
+	for i := 0; i < anthropicMaxPages; i++ {
+		url := "https://api.anthropic.com/v1/organizations/workspaces?limit=100&include_archived=true"
+		if after != "" {
+			url += "&after_id=" + neturl.QueryEscape(after)
+		}
+		resp, err := client.Do(newAnthropicAdminRequest(config, url))
+		if err != nil {
+			return names, err
+		}
+		body, err := p.readResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return names, err
+		}
+		if resp.StatusCode != 200 {
+			return names, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+		var page anthropicWorkspacesResponse
+		d, err := decodeResponse(body, &page)
+		if err != nil {
+			return names, err
+		}
+		drift.merge(d)
+		for _, w := range page.Data {
+			names[w.ID] = w.Name
+		}
+		if !page.HasMore || page.LastID == "" {
+			break
+		}
+		after = page.LastID
+	}
+	return names, nil
+}
+
+// anthropicReport walks the pages of an organization report with daily buckets since
+// start, handing each page body to handle, which returns whether there are more pages.
+// params are added to the query, e.g. a group_by.
+func (p *Plugin) anthropicReport(client *http.Client, config *Configuration, report string, params neturl.Values, start time.Time,
+	handle func(body []byte) (hasMore bool, nextPage string, err error)) error {
+	page := ""
+	for i := 0; i < anthropicMaxPages; i++ {
+		url := fmt.Sprintf("https://api.anthropic.com/v1/organizations/%s?starting_at=%s&bucket_width=1d&limit=31",
+			report, neturl.QueryEscape(start.Format(time.RFC3339)))
+		if len(params) > 0 {
+			url += "&" + params.Encode()
+		}
+		if page != "" {
+			url += "&page=" + neturl.QueryEscape(page)
+		}
+		resp, err := client.Do(newAnthropicAdminRequest(config, url))
+		if err != nil {
+			return err
+		}
+		body, err := p.readResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != 200 {
+			var errResp struct {
+				Error *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+				return fmt.Errorf("HTTP %d: %s", resp.StatusCode, errResp.Error.Message)
+			}
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+
+		hasMore, nextPage, err := handle(body)
+		if err != nil {
+			return err
+		}
+		if !hasMore || nextPage == "" {
+			return nil
+		}
+		page = nextPage
+	}
+	return nil
+}
+
+func newAnthropicAdminRequest(config *Configuration, url string) *http.Request {
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("x-api-key", config.AnthropicAdminKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== AssemblyAI (API key) =====
+
+// Listing and duration lookups are bounded per refresh; durations already looked up are
+// kept, so a busy month catches up over a few refreshes.
+const (
+	assemblyAIMaxPages   = 20
+	assemblyAIMaxLookups = 50
+)
+
+// AssemblyAIUsageInfo is the month's transcription volume and its estimated cost. AssemblyAI
+// has no billing API: the cost is the transcribed audio hours times the configured rate,
+// and the balance comes from the configuration.
+type AssemblyAIUsageInfo struct {
+	Hours          float64 `json:"hours"`
+	Transcripts    int     `json:"transcripts"`
+	Partial        bool    `json:"partial"` // some durations are still to be looked up
+	TotalCost      float64 `json:"totalCost"`
+	HourlyRate     float64 `json:"hourlyRate"`
+	Currency       string  `json:"currency"`
+	Period         string  `json:"period"`
+	CycleEnd       string  `json:"cycleEnd"`
+	DaysUntilReset int     `json:"daysUntilReset"`
+	CreditBalance  float64 `json:"creditBalance"`
+	HasBalance     bool    `json:"hasBalance"`
+	LowBalance     float64 `json:"lowBalance,omitempty"`
+}
+
+func (a AssemblyAIUsageInfo) summary(locale string) string {
+	text := translate(locale, "summary.spent", formatMoney(a.TotalCost, a.Currency, 2), a.Period)
+	text += " " + translate(locale, "summary.assemblyai", fmt.Sprintf("%.1f", a.Hours), a.Transcripts)
+	if a.HasBalance {
+		text += ", " + translate(locale, "summary.credit_balance", formatMoney(a.CreditBalance, a.Currency, 2))
+	}
+	return text
+}
+
+func (a AssemblyAIUsageInfo) compact(name, locale string) string {
+	if a.HasBalance {
+		return translate(locale, "compact.balance_left", name, formatMoney(a.CreditBalance, a.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(a.TotalCost, a.Currency, 0))
+}
+
+func (a AssemblyAIUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(a.CycleEnd)}}
+}
+
+// assemblyAIListResponse is GET /v2/transcript. Items don't carry the audio duration.
+type assemblyAIListResponse struct {
+	PageDetails struct {
+		Limit       flexFloat `json:"limit"`
+		ResultCount flexFloat `json:"result_count"`
+		PrevURL     *string   `json:"prev_url"`
+	} `json:"page_details" schema:"required"`
+	Transcripts []struct {
+		ID      string `json:"id" schema:"required"`
+		Status  string `json:"status"`
+		Created string `json:"created" schema:"required"`
+	} `json:"transcripts" schema:"required"`
+}
+
+// assemblyAITranscriptResponse is GET /v2/transcript/{id}.
+type assemblyAITranscriptResponse struct {
+	ID            string    `json:"id"`
+	Status        string    `json:"status"`
+	AudioDuration flexFloat `json:"audio_duration" schema:"required"` // seconds
+}
+
+// assemblyAIMonth is the audio duration of each transcript created in a calendar month (UTC).
+type assemblyAIMonth struct {
+	Month   string             `json:"month"`   // "2006-01"
+	Seconds map[string]float64 `json:"seconds"` // transcript ID → audio seconds
+}
+
+func assemblyAIMonthKey(month string) string {
+	return "assemblyai_" + month
+}
+
+func (p *Plugin) getAssemblyAIStatus(config *Configuration) ServiceStatus {
+	const id, name = "assemblyai", "AssemblyAI"
+	if config.AssemblyaiApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := AssemblyAIUsageInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+
+	month := assemblyAIMonth{Month: monthStart.Format("2006-01"), Seconds: map[string]float64{}}
+	if data, appErr := p.API.KVGet(p.scopedKey(assemblyAIMonthKey(month.Month))); appErr == nil && data != nil {
+		json.Unmarshal(data, &month)
+	}
+	if month.Seconds == nil {
+		month.Seconds = map[string]float64{}
+	}
+
+	// Completed transcripts of the month, newest first
+	var pending []string
+	var drift schemaDrift
+	url := newAssemblyAIListURL()
+	for page := 0; page < assemblyAIMaxPages && url != ""; page++ {
+		body, err := p.assemblyAIGet(client, config, url)
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		var list assemblyAIListResponse
+		d, err := decodeResponse(body, &list)
+		if err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		drift.merge(d)
+
+		url = ""
+		if list.PageDetails.PrevURL != nil {
+			url = *list.PageDetails.PrevURL
+		}
+		for _, t := range list.Transcripts {
+			if created := assemblyAITime(t.Created); !created.IsZero() && created.Before(monthStart) {
+				url = ""
+				break
+			}
+			info.Transcripts++
+			if _, ok := month.Seconds[t.ID]; !ok {
+				pending = append(pending, t.ID)
+			}
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	// Durations only need looking up once per transcript
+	if len(pending) > assemblyAIMaxLookups {
+		pending, info.Partial = pending[:assemblyAIMaxLookups], true
+	}
+	for _, transcriptID := range pending {
+		body, err := p.assemblyAIGet(client, config, "https://api.assemblyai.com/v2/transcript/"+neturl.PathEscape(transcriptID))
+		if err != nil {
+			p.API.LogWarn("Failed to fetch AssemblyAI transcript", "transcript", transcriptID, "error", err.Error())
+			info.Partial = true
+			continue
+		}
+		var transcript assemblyAITranscriptResponse
+		if err := json.Unmarshal(body, &transcript); err != nil {
+			info.Partial = true
+			continue
+		}
+		month.Seconds[transcriptID] = float64(transcript.AudioDuration)
+	}
+	if len(pending) > 0 {
+		data, _ := json.Marshal(month)
+		if appErr := p.API.KVSet(p.scopedKey(assemblyAIMonthKey(month.Month)), data); appErr != nil {
+			p.API.LogWarn("Failed to store AssemblyAI durations", "month", month.Month, "error", appErr.Error())
+		}
+	}
+
+	seconds := 0.0
+	for _, s := range month.Seconds {
+		seconds += s
+	}
+	info.Hours = seconds / 3600
+	info.HourlyRate = p.budgetIn(config, id, config.AssemblyaiHourlyRate, info.Currency)
+	info.TotalCost = info.Hours * info.HourlyRate
+	if strings.TrimSpace(config.AssemblyaiCreditBalance) != "" {
+		info.CreditBalance = p.budgetIn(config, id, config.AssemblyaiCreditBalance, info.Currency)
+		info.HasBalance = true
+		info.LowBalance = p.budgetIn(config, id, config.AssemblyaiLowBalance, info.Currency)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: assemblyAIStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// assemblyAIStatus warns when the configured balance drops below the alert amount.
+func assemblyAIStatus(info AssemblyAIUsageInfo) string {
+	if info.HasBalance && info.LowBalance > 0 && info.CreditBalance < info.LowBalance {
+		return "warning"
+	}
+	return "ok"
+}
+
+// assemblyAITime parses a transcript's creation time, which has no zone and is UTC.
+func assemblyAITime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// assemblyAIGet sends a request and returns the body of a successful response.
+func (p *Plugin) assemblyAIGet(client *http.Client, config *Configuration, url string) ([]byte, error) {
+	resp, err := client.Do(newAssemblyAIRequest(config, url))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+// newAssemblyAIListURL is the first page of completed transcripts.
+func newAssemblyAIListURL() string {
+	return "https://api.assemblyai.com/v2/transcript?" + neturl.Values{"limit": {"200"}, "status": {"completed"}}.Encode()
+}
+
+func newAssemblyAIRequest(config *Configuration, url string) *http.Request {
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Authorization", config.AssemblyaiApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
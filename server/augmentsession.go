@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// ===== Augment sessions and token renewal =====
+
+// augmentDefaultTenant is the API of accounts whose tenant isn't known.
+const augmentDefaultTenant = "https://d2.api.augmentcode.com/"
+
+// augmentSession is the session the Augment CLI keeps in ~/.augment/session.json. Given as
+// the access token, usually as a file: reference, it also tells the account's tenant.
+type augmentSession struct {
+	AccessToken string `json:"accessToken"`
+	TenantURL   string `json:"tenantURL"`
+}
+
+// augmentCredentials returns the access token and API base URL of the Augment Access
+// Token setting: a token, or a session. Tenants outside augmentcode.com are ignored, so a
+// session file can't send the token elsewhere.
+func augmentCredentials(value string) (token, baseURL string) {
+	value = strings.TrimSpace(value)
+	var session augmentSession
+	if !strings.HasPrefix(value, "{") || json.Unmarshal([]byte(value), &session) != nil || session.AccessToken == "" {
+		return value, augmentDefaultTenant
+	}
+	baseURL = augmentDefaultTenant
+	if u, err := neturl.Parse(session.TenantURL); err == nil && u.Scheme == "https" && strings.HasSuffix(u.Hostname(), ".augmentcode.com") {
+		baseURL = strings.TrimRight(session.TenantURL, "/") + "/"
+	}
+	return session.AccessToken, baseURL
+}
+
+// newAugmentCreditRequest builds the get-credit-info request for the setting's token and
+// tenant. The provider client sets the configured User-Agent.
+func newAugmentCreditRequest(setting string) *http.Request {
+	token, baseURL := augmentCredentials(setting)
+	req, _ := http.NewRequest("POST", baseURL+"get-credit-info", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// renewedAugmentToken re-reads the Augment Access Token when it's a file: or env:
+// reference, for a token the Augment CLI or a secrets agent renewed since the
+// configuration was loaded. It returns "" when the reference holds the same token.
+// The periodic secret refresh makes the renewed token current everywhere else.
+func renewedAugmentToken(config *Configuration) string {
+	ref, ok := config.secretRefs["augmentaccesstoken"]
+	if !ok {
+		return ""
+	}
+	value, err := resolveSecret(ref.Ref)
+	if err != nil || value == "" {
+		return ""
+	}
+	if token, _ := augmentCredentials(value); token == "" {
+		return ""
+	} else if current, _ := augmentCredentials(config.AugmentAccessToken); token == current {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ===== Provider availability =====
+
+// availabilityWindows are the periods uptime is reported over.
+var availabilityWindows = []struct {
+	name   string
+	period time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// ProviderAvailability is the share of requests to a provider's status API that succeeded
+// over each window, in percent. A window without requests is left out.
+type ProviderAvailability struct {
+	Provider string             `json:"provider"`
+	Uptime   map[string]float64 `json:"uptime"`   // window → percent
+	Requests map[string]int     `json:"requests"` // window → requests it's based on
+}
+
+// availabilityReport computes each provider's uptime from the stored request history.
+// Days recorded before hourly figures were kept only count towards the 7 and 30 day windows.
+func (p *Plugin) availabilityReport() []ProviderAvailability {
+	now := time.Now().UTC()
+	report := []ProviderAvailability{}
+	for provider, byDate := range p.latencyHistory(31) {
+		requests := map[string]int{}
+		failures := map[string]int{}
+		count := func(at time.Time, hourly bool, r, f int) {
+			for _, w := range availabilityWindows {
+				start := now.Add(-w.period)
+				if hourly && at.Add(time.Hour).After(start) || !hourly && w.period >= 7*24*time.Hour && !at.Before(start.Truncate(24*time.Hour)) {
+					requests[w.name] += r
+					failures[w.name] += f
+				}
+			}
+		}
+		for date, d := range byDate {
+			day, err := time.Parse("2006-01-02", date)
+			if err != nil {
+				continue
+			}
+			if len(d.Hours) == 0 {
+				count(day, false, d.Requests, d.Failures)
+				continue
+			}
+			for h, hour := range d.Hours {
+				count(day.Add(time.Duration(h)*time.Hour), true, hour.Requests, hour.Failures)
+			}
+		}
+
+		a := ProviderAvailability{Provider: provider, Uptime: map[string]float64{}, Requests: map[string]int{}}
+		for window, n := range requests {
+			if n > 0 {
+				a.Uptime[window] = math.Round(10000*float64(n-failures[window])/float64(n)) / 100
+				a.Requests[window] = n
+			}
+		}
+		if len(a.Uptime) > 0 {
+			report = append(report, a)
+		}
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Provider < report[j].Provider })
+	return report
+}
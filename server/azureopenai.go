@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Azure OpenAI (service principal, Azure Resource Manager) =====
+
+// azureOpenAIMaxAccounts bounds the resources queried, two requests each.
+const azureOpenAIMaxAccounts = 10
+
+// azureOpenAIWindow is how far back the busiest minute is looked for.
+const azureOpenAIWindow = 15 * time.Minute
+
+// AzureOpenAIInfo is the Azure OpenAI deployments of a subscription: each deployment's
+// tokens and requests per minute quota and its busiest minute of the last quarter hour,
+// from Azure Monitor. Azure meters spend through Cost Management, not per deployment.
+type AzureOpenAIInfo struct {
+	SubscriptionID string                  `json:"subscriptionId"`
+	Accounts       int                     `json:"accounts"`
+	Deployments    []AzureOpenAIDeployment `json:"deployments"`       // most utilized first
+	Partial        bool                    `json:"partial,omitempty"` // more resources than are queried
+}
+
+// AzureOpenAIDeployment is one model deployment's per-minute quota and usage. Provisioned
+// deployments are sized in PTUs and have no per-minute quota.
+type AzureOpenAIDeployment struct {
+	Account           string  `json:"account"`
+	Location          string  `json:"location"`
+	Name              string  `json:"name"`
+	Model             string  `json:"model"`
+	ModelVersion      string  `json:"modelVersion,omitempty"`
+	SKU               string  `json:"sku"`
+	Capacity          float64 `json:"capacity"`
+	TokensPerMinute   float64 `json:"tokensPerMinute,omitempty"`
+	RequestsPerMinute float64 `json:"requestsPerMinute,omitempty"`
+	TokensUsed        float64 `json:"tokensUsed"`
+	RequestsUsed      float64 `json:"requestsUsed"`
+	Percent           float64 `json:"percent"`
+}
+
+// binding is the quota closest to its limit, tokens or requests per minute.
+func (d AzureOpenAIDeployment) binding() (unit string, used, limit float64) {
+	if d.RequestsPerMinute > 0 && (d.TokensPerMinute <= 0 || d.RequestsUsed/d.RequestsPerMinute > d.TokensUsed/d.TokensPerMinute) {
+		return "requests", d.RequestsUsed, d.RequestsPerMinute
+	}
+	return "tokens", d.TokensUsed, d.TokensPerMinute
+}
+
+// peak is the deployment closest to its quota, when any has one.
+func (a AzureOpenAIInfo) peak() (AzureOpenAIDeployment, bool) {
+	if len(a.Deployments) == 0 {
+		return AzureOpenAIDeployment{}, false
+	}
+	if _, _, limit := a.Deployments[0].binding(); limit <= 0 {
+		return AzureOpenAIDeployment{}, false
+	}
+	return a.Deployments[0], true
+}
+
+func (a AzureOpenAIInfo) summary(locale string) string {
+	if peak, ok := a.peak(); ok {
+		unit, used, limit := peak.binding()
+		return translate(locale, "summary.azure_openai_"+unit, peak.Name, formatCount(used), formatCount(limit))
+	}
+	return translate(locale, "summary.azure_openai_deployments", len(a.Deployments), a.Accounts)
+}
+
+func (a AzureOpenAIInfo) compact(name, locale string) string {
+	if peak, ok := a.peak(); ok {
+		return fmt.Sprintf("%s %s %.0f%%", name, peak.Name, peak.Percent)
+	}
+	return name
+}
+
+func (a AzureOpenAIInfo) usagePercent() (float64, bool) {
+	if peak, ok := a.peak(); ok {
+		return peak.Percent, true
+	}
+	return 0, false
+}
+
+// azureAccountsResponse is a page of the subscription's Cognitive Services accounts.
+type azureAccountsResponse struct {
+	Value []struct {
+		ID       string `json:"id" schema:"required"`
+		Name     string `json:"name" schema:"required"`
+		Location string `json:"location"`
+		Kind     string `json:"kind"` // "OpenAI", or "AIServices" for Azure AI Foundry
+	} `json:"value" schema:"required"`
+	NextLink string `json:"nextLink"`
+}
+
+// azureDeploymentsResponse is an account's deployments.
+type azureDeploymentsResponse struct {
+	Value []struct {
+		Name string `json:"name" schema:"required"`
+		SKU  struct {
+			Name     string    `json:"name"`
+			Capacity flexFloat `json:"capacity"`
+		} `json:"sku"`
+		Properties struct {
+			Model struct {
+				Format  string `json:"format"`
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"model"`
+			RateLimits []struct {
+				Key           string    `json:"key"` // "request" or "token"
+				RenewalPeriod flexFloat `json:"renewalPeriod"`
+				Count         flexFloat `json:"count"`
+			} `json:"rateLimits"`
+			ProvisioningState string `json:"provisioningState"`
+		} `json:"properties"`
+	} `json:"value" schema:"required"`
+}
+
+// azureMetricsResponse is Azure Monitor's metrics of an account, split by deployment.
+type azureMetricsResponse struct {
+	Value []struct {
+		Name struct {
+			Value string `json:"value"`
+		} `json:"name"`
+		Timeseries []struct {
+			MetadataValues []struct {
+				Name struct {
+					Value string `json:"value"`
+				} `json:"name"`
+				Value string `json:"value"`
+			} `json:"metadatavalues"`
+			Data []struct {
+				TimeStamp string     `json:"timeStamp"`
+				Total     *flexFloat `json:"total"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"value" schema:"required"`
+}
+
+// azureOpenAIProvider reads the deployments through Azure Resource Manager with a
+// service principal's client secret.
+type azureOpenAIProvider struct{}
+
+func (azureOpenAIProvider) ID() string   { return "azureopenai" }
+func (azureOpenAIProvider) Name() string { return "Azure OpenAI" }
+
+func (azureOpenAIProvider) Enabled(config *Configuration) bool {
+	return config.AzureOpenaiEnabled
+}
+
+func (azureOpenAIProvider) EvaluateStatus(data interface{}, config *Configuration) string {
+	return azureOpenAIStatus(data.(AzureOpenAIInfo), config)
+}
+
+func (a azureOpenAIProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	subscription := strings.TrimSpace(config.AzureOpenaiSubscriptionId)
+	if strings.TrimSpace(config.AzureOpenaiTenantId) == "" || strings.TrimSpace(config.AzureOpenaiClientId) == "" ||
+		config.AzureOpenaiClientSecret == "" || subscription == "" {
+		return nil, errFetch("error.azure_credentials_missing")
+	}
+
+	client := p.providerClient(a.ID(), 30*time.Second)
+	token, err := p.azureAccessToken(client, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the configured resources, or every OpenAI and AI Services resource
+	wanted := map[string]bool{}
+	for _, name := range splitList(config.AzureOpenaiAccounts) {
+		wanted[strings.ToLower(name)] = true
+	}
+	type account struct{ id, name, location string }
+	var accounts []account
+	url := "https://management.azure.com/subscriptions/" + neturl.PathEscape(subscription) +
+		"/providers/Microsoft.CognitiveServices/accounts?api-version=2023-05-01"
+	for page := 0; url != "" && page < 5; page++ {
+		var resp azureAccountsResponse
+		if err := p.getJSON(a.ID(), client, newAzureRequest(token, url), &resp); err != nil {
+			return nil, err
+		}
+		for _, acc := range resp.Value {
+			if acc.Kind != "OpenAI" && acc.Kind != "AIServices" {
+				continue
+			}
+			if len(wanted) > 0 && !wanted[strings.ToLower(acc.Name)] {
+				continue
+			}
+			accounts = append(accounts, account{acc.ID, acc.Name, acc.Location})
+		}
+		url = resp.NextLink
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].name < accounts[j].name })
+
+	info := AzureOpenAIInfo{SubscriptionID: subscription, Accounts: len(accounts), Deployments: []AzureOpenAIDeployment{}}
+	if len(accounts) > azureOpenAIMaxAccounts {
+		accounts, info.Partial = accounts[:azureOpenAIMaxAccounts], true
+	}
+	now := time.Now().UTC()
+	for _, acc := range accounts {
+		var deployments azureDeploymentsResponse
+		url := "https://management.azure.com" + acc.id + "/deployments?api-version=2023-05-01"
+		if err := p.getJSON(a.ID(), client, newAzureRequest(token, url), &deployments); err != nil {
+			return nil, err
+		}
+		if len(deployments.Value) == 0 {
+			continue
+		}
+		var metrics azureMetricsResponse
+		if err := p.getJSON(a.ID(), client, newAzureMetricsRequest(token, acc.id, now), &metrics); err != nil {
+			return nil, err
+		}
+		tokens, requests := azureBusiestMinute(metrics)
+
+		for _, dep := range deployments.Value {
+			props := dep.Properties
+			d := AzureOpenAIDeployment{
+				Account: acc.name, Location: acc.location, Name: dep.Name,
+				Model: props.Model.Name, ModelVersion: props.Model.Version,
+				SKU: dep.SKU.Name, Capacity: float64(dep.SKU.Capacity),
+				TokensUsed: tokens[strings.ToLower(dep.Name)], RequestsUsed: requests[strings.ToLower(dep.Name)],
+			}
+			// Limits are per renewal period, e.g. requests per 10 seconds
+			for _, limit := range props.RateLimits {
+				if limit.RenewalPeriod <= 0 {
+					continue
+				}
+				perMinute := float64(limit.Count) * 60 / float64(limit.RenewalPeriod)
+				switch limit.Key {
+				case "token":
+					d.TokensPerMinute = perMinute
+				case "request":
+					d.RequestsPerMinute = perMinute
+				}
+			}
+			if _, used, limit := d.binding(); limit > 0 {
+				d.Percent = used / limit * 100
+			}
+			info.Deployments = append(info.Deployments, d)
+		}
+	}
+	sort.SliceStable(info.Deployments, func(i, j int) bool { return info.Deployments[i].Percent > info.Deployments[j].Percent })
+	return info, nil
+}
+
+// azureBusiestMinute returns each deployment's highest tokens and requests in a minute,
+// keyed by the lowercased deployment name.
+func azureBusiestMinute(metrics azureMetricsResponse) (tokens, requests map[string]float64) {
+	tokens, requests = map[string]float64{}, map[string]float64{}
+	for _, metric := range metrics.Value {
+		target := tokens
+		if metric.Name.Value == "AzureOpenAIRequests" {
+			target = requests
+		}
+		for _, ts := range metric.Timeseries {
+			deployment := ""
+			for _, meta := range ts.MetadataValues {
+				if strings.EqualFold(meta.Name.Value, "ModelDeploymentName") {
+					deployment = strings.ToLower(meta.Value)
+				}
+			}
+			for _, point := range ts.Data {
+				if point.Total != nil {
+					target[deployment] = max(target[deployment], float64(*point.Total))
+				}
+			}
+		}
+	}
+	return tokens, requests
+}
+
+// azureOpenAIStatus is an error while a deployment's busiest minute reached its quota,
+// which Azure answers with 429s, and a warning above the warning threshold.
+func azureOpenAIStatus(info AzureOpenAIInfo, config *Configuration) string {
+	peak, ok := info.peak()
+	switch {
+	case !ok:
+		return "ok"
+	case peak.Percent >= 100:
+		return "error"
+	case peak.Percent > config.warningPercent(80):
+		return "warning"
+	}
+	return "ok"
+}
+
+// azureAccessToken gets a Resource Manager token with the client credentials grant.
+func (p *Plugin) azureAccessToken(client *http.Client, config *Configuration) (string, error) {
+	resp, err := client.Do(newAzureTokenRequest(config))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	if resp.StatusCode != 200 || token.AccessToken == "" {
+		// The description starts with an AADSTS code and ends with trace IDs
+		description, _, _ := strings.Cut(token.ErrorDescription, "\r\n")
+		return "", fmt.Errorf("HTTP %d: %s %s", resp.StatusCode, token.Error, description)
+	}
+	return token.AccessToken, nil
+}
+
+func newAzureTokenRequest(config *Configuration) *http.Request {
+	form := neturl.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {strings.TrimSpace(config.AzureOpenaiClientId)},
+		"client_secret": {config.AzureOpenaiClientSecret},
+		"scope":         {"https://management.azure.com/.default"},
+	}
+	url := "https://login.microsoftonline.com/" + neturl.PathEscape(strings.TrimSpace(config.AzureOpenaiTenantId)) + "/oauth2/v2.0/token"
+	req, _ := http.NewRequest("POST", url, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+func newAzureRequest(token, url string) *http.Request {
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// newAzureMetricsRequest asks Azure Monitor for the account's tokens and requests per
+// minute over the window, split by deployment.
+func newAzureMetricsRequest(token, accountID string, now time.Time) *http.Request {
+	query := neturl.Values{
+		"api-version": {"2023-10-01"},
+		"metricnames": {"TokenTransaction,AzureOpenAIRequests"},
+		"aggregation": {"Total"},
+		"interval":    {"PT1M"},
+		"timespan":    {now.Add(-azureOpenAIWindow).Format(time.RFC3339) + "/" + now.Format(time.RFC3339)},
+		"$filter":     {"ModelDeploymentName eq '*'"},
+		"top":         {"100"},
+	}
+	return newAzureRequest(token, "https://management.azure.com"+accountID+"/providers/Microsoft.Insights/metrics?"+query.Encode())
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Error backoff =====
+
+// errorBackoffBase is how long a failed fetch is reused at first. It doubles with each
+// consecutive failure, up to the cache TTL, so a provider that's down isn't called on every
+// dashboard load but is picked up again soon after it recovers. A provider that rate
+// limited the fetch is left alone for as long as its Retry-After asked.
+const errorBackoffBase = 30 * time.Second
+
+const (
+	// defaultBreakerFailures is how many fetches in a row may fail before the provider's
+	// circuit breaker opens, and defaultBreakerCooldown how long it then stays open.
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 15 * time.Minute
+)
+
+// providerBackoff is the last failed result of a provider and when to try it again.
+type providerBackoff struct {
+	failures int
+	retryAt  time.Time
+	statuses []ServiceStatus
+	// When the circuit breaker opened: the provider is only tried again after the
+	// cool-down, and a manual refresh doesn't skip it
+	openedAt time.Time
+}
+
+// BreakerState tells why a provider isn't being called: its circuit breaker opened after
+// too many failed fetches in a row.
+type BreakerState struct {
+	State    string `json:"state"` // "open" until RetryAt, then "half-open" until the trial fetch
+	Failures int    `json:"failures"`
+	OpenedAt int64  `json:"openedAt"` // Unix time
+	RetryAt  int64  `json:"retryAt"`  // Unix time of the next trial fetch
+}
+
+// circuitBreaker returns how many failures in a row open a provider's circuit breaker, zero
+// when it's off, and how long it stays open.
+func (c *Configuration) circuitBreaker() (int, time.Duration) {
+	failures := defaultBreakerFailures
+	if n, err := strconv.Atoi(strings.TrimSpace(c.CircuitBreakerFailures)); err == nil && n >= 0 {
+		failures = n
+	}
+	cooldown := defaultBreakerCooldown
+	if minutes, err := strconv.Atoi(strings.TrimSpace(c.CircuitBreakerCooldownMinutes)); err == nil && minutes >= 1 {
+		cooldown = time.Duration(min(minutes, 24*60)) * time.Minute
+	}
+	return failures, cooldown
+}
+
+// fetchProvider returns the provider's statuses, reusing the last result while it's backing
+// off after a failure. Failed cards show their last known good status meanwhile.
+func (p *Plugin) fetchProvider(prov provider, config *Configuration) []ServiceStatus {
+	start := time.Now()
+	p.backoffLock.Lock()
+	b, ok := p.backoff[prov.ID]
+	if ok && start.Before(b.retryAt) {
+		statuses := append([]ServiceStatus(nil), b.statuses...)
+		p.backoffLock.Unlock()
+		p.logFetch(prov.ID, "backoff", start, statuses)
+		return p.withBreaker(prov.ID, p.withLastGood(statuses, time.Now()), time.Now())
+	}
+	p.backoffLock.Unlock()
+
+	statuses := p.safeFetch(prov, config)
+	p.applyDetectedExpiry(prov.ID, statuses)
+	p.recordFetch(prov.ID, statuses)
+	p.logFetch(prov.ID, fetchCacheDecision(statuses, start), start, statuses)
+	p.rememberGood(statuses)
+	return p.withBreaker(prov.ID, p.withLastGood(statuses, time.Now()), time.Now())
+}
+
+// withBreaker marks the failed cards of a provider whose circuit breaker is open as
+// unreachable, with the breaker's state. Cards with a last known good status keep showing
+// it. Credentials that need renewing stay reauth, which says what to do about it.
+func (p *Plugin) withBreaker(id string, statuses []ServiceStatus, now time.Time) []ServiceStatus {
+	p.backoffLock.Lock()
+	b, ok := p.backoff[id]
+	if !ok || b.openedAt.IsZero() {
+		p.backoffLock.Unlock()
+		return statuses
+	}
+	state := BreakerState{State: "open", Failures: b.failures, OpenedAt: b.openedAt.Unix(), RetryAt: b.retryAt.Unix()}
+	if !now.Before(b.retryAt) {
+		state.State = "half-open"
+	}
+	p.backoffLock.Unlock()
+
+	for i, s := range statuses {
+		if s.Error == "" && !s.Stale {
+			continue
+		}
+		breaker := state
+		statuses[i].Breaker = &breaker
+		if s.Status != "reauth" {
+			statuses[i].Status = "unreachable"
+		}
+	}
+	return statuses
+}
+
+// safeFetch calls the provider, turning a panic, e.g. on a malformed upstream response,
+// into an error status so it can't take down the request or the background poller.
+func (p *Plugin) safeFetch(prov provider, config *Configuration) (statuses []ServiceStatus) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.API.LogError("Provider fetch panicked", "provider", prov.ID, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+			statuses = []ServiceStatus{errorStatus(prov.ID, prov.Name, "error.internal", fmt.Sprint(r))}
+		}
+	}()
+	return prov.Fetch(p, config)
+}
+
+// recordFetch starts or extends the provider's backoff when any of its statuses failed,
+// and clears it once they all succeed.
+func (p *Plugin) recordFetch(id string, statuses []ServiceStatus) {
+	failed := false
+	for _, s := range statuses {
+		if s.Error != "" {
+			failed = true
+			break
+		}
+	}
+
+	if failed {
+		// Tag the failure so a card shown in a bug report can be matched to the log
+		requestID := model.NewId()
+		for i, s := range statuses {
+			if s.Error != "" {
+				statuses[i].RequestID = requestID
+				p.API.LogWarn("Provider fetch failed", "request_id", requestID, "provider", s.ID, "error", s.Error)
+			}
+		}
+	}
+
+	p.backoffLock.Lock()
+	defer p.backoffLock.Unlock()
+	if !failed {
+		if b, ok := p.backoff[id]; ok && !b.openedAt.IsZero() {
+			p.API.LogInfo("Provider recovered, circuit breaker closed", "provider", id)
+		}
+		delete(p.backoff, id)
+		return
+	}
+	if p.backoff == nil {
+		p.backoff = map[string]*providerBackoff{}
+	}
+	b, ok := p.backoff[id]
+	if !ok {
+		b = &providerBackoff{}
+		p.backoff[id] = b
+	}
+	ids := []string{id}
+	for _, s := range statuses {
+		ids = append(ids, s.ID)
+	}
+	delay := min(errorBackoffBase<<min(b.failures, 10), p.getCacheTTL())
+	b.failures++
+	// After too many failures in a row, e.g. with wrong credentials, stop calling the
+	// provider for the cool-down, so it doesn't ban the server's IP for hammering it
+	if threshold, cooldown := p.getConfiguration().circuitBreaker(); threshold > 0 && b.failures >= threshold {
+		if b.openedAt.IsZero() {
+			p.API.LogWarn("Circuit breaker opened, pausing requests to the provider", "provider", id,
+				"failures", b.failures, "cooldown", cooldown.String())
+			b.openedAt = time.Now()
+		}
+		delay = cooldown
+	}
+	delay = max(delay, p.rateLimitedFor(time.Now(), ids...))
+	b.retryAt = time.Now().Add(delay)
+	b.statuses = append([]ServiceStatus(nil), statuses...)
+	for i := range b.statuses {
+		if b.statuses[i].CachedAt == 0 {
+			b.statuses[i].CachedAt = time.Now().Unix()
+		}
+	}
+}
+
+// clearBackoff retries failed providers on the next fetch. Providers whose circuit breaker
+// is open wait out the cool-down, so refreshing from several dashboards can't hammer an API
+// that keeps failing.
+func (p *Plugin) clearBackoff() {
+	p.backoffLock.Lock()
+	for key, b := range p.backoff {
+		if b.openedAt.IsZero() {
+			delete(p.backoff, key)
+		}
+	}
+	p.backoffLock.Unlock()
+}
+
+// resetBreakers closes the circuit breakers of the given providers, or of all of them when
+// ids is nil, once their settings changed: new credentials are worth trying at once.
+func (p *Plugin) resetBreakers(ids map[string]bool) {
+	p.backoffLock.Lock()
+	for key := range p.backoff {
+		if ids == nil || ids[key] {
+			delete(p.backoff, key)
+		}
+	}
+	p.backoffLock.Unlock()
+}
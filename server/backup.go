@@ -0,0 +1,279 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ===== Configuration backup and restore =====
+
+const backupVersion = 1
+
+// backupSecretSettings hold credentials inside JSON or a URL, such as a proxy's password or
+// a webhook's token, so they're treated as secrets as a whole.
+var backupSecretSettings = map[string]bool{
+	"customproviders": true, "providerinstances": true, "federationpeers": true, "providerheaders": true,
+	"outboundproxyurl": true, "webhookurl": true,
+}
+
+// isSecretSetting reports whether a configuration key holds a credential or other secret.
+func isSecretSetting(key string) bool {
+	return isCredentialSetting(key) || backupSecretSettings[key]
+}
+
+// Backup is the plugin's configuration and per-user settings, as exported by
+// POST /api/v1/admin/backup/export.
+type Backup struct {
+	Version     int                    `json:"version"`
+	ExportedAt  string                 `json:"exportedAt"`
+	Settings    map[string]interface{} `json:"settings"`          // everything but the secrets
+	Secrets     *encryptedSecrets      `json:"secrets,omitempty"` // left out unless a passphrase was given
+	Preferences []userPreferencesEntry `json:"preferences"`
+}
+
+// userPreferencesEntry is one user's dashboard preferences. Users are matched by username
+// on import, since user IDs differ between servers.
+type userPreferencesEntry struct {
+	UserID      string          `json:"userId"`
+	Username    string          `json:"username"`
+	Preferences UserPreferences `json:"preferences"`
+}
+
+// encryptedSecrets are the secret settings as JSON, sealed with AES-256-GCM under a key
+// derived from the passphrase with scrypt.
+type encryptedSecrets struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func backupKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptSecrets(secrets map[string]interface{}, passphrase string) (*encryptedSecrets, error) {
+	plaintext, _ := json.Marshal(secrets)
+	enc := &encryptedSecrets{Salt: make([]byte, 16)}
+	if _, err := io.ReadFull(rand.Reader, enc.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := backupKey(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+	enc.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, enc.Nonce); err != nil {
+		return nil, err
+	}
+	enc.Data = aead.Seal(nil, enc.Nonce, plaintext, nil)
+	return enc, nil
+}
+
+func (enc *encryptedSecrets) decrypt(passphrase string) (map[string]interface{}, error) {
+	aead, err := backupKey(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("malformed secrets")
+	}
+	plaintext, err := aead.Open(nil, enc.Nonce, enc.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase")
+	}
+	var secrets map[string]interface{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("malformed secrets")
+	}
+	return secrets, nil
+}
+
+// configurationMap returns the configuration by setting key.
+func configurationMap(config *Configuration) map[string]interface{} {
+	settings := map[string]interface{}{}
+	data, _ := json.Marshal(config)
+	json.Unmarshal(data, &settings)
+	return settings
+}
+
+// allUserPreferences returns the preferences of every user who saved any.
+func (p *Plugin) allUserPreferences() ([]userPreferencesEntry, error) {
+	entries := []userPreferencesEntry{}
+	for page := 0; ; page++ {
+		keys, appErr := p.API.KVList(page, 200)
+		if appErr != nil {
+			return nil, appErr
+		}
+		for _, key := range keys {
+			userID, ok := strings.CutPrefix(key, preferencesKey(""))
+			if !ok {
+				continue
+			}
+			entry := userPreferencesEntry{UserID: userID, Preferences: p.getUserPreferences(userID)}
+			if user, appErr := p.API.GetUser(userID); appErr == nil {
+				entry.Username = user.Username
+			}
+			entries = append(entries, entry)
+		}
+		if len(keys) < 200 {
+			break
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].UserID < entries[j].UserID })
+	return entries, nil
+}
+
+// handleBackupExport serves POST /api/v1/admin/backup/export. Secrets are left out unless
+// the body gives a passphrase to encrypt them with.
+func (p *Plugin) handleBackupExport(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Passphrase string `json:"passphrase"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&body); err != nil && err != io.EOF {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+
+	backup := Backup{Version: backupVersion, ExportedAt: time.Now().UTC().Format(time.RFC3339), Settings: map[string]interface{}{}}
+	secrets := map[string]interface{}{}
+	for key, value := range p.getConfiguration().withSecretReferences() {
+		if isSecretSetting(key) {
+			secrets[key] = value
+		} else {
+			backup.Settings[key] = value
+		}
+	}
+	if body.Passphrase != "" {
+		enc, err := encryptSecrets(secrets, body.Passphrase)
+		if err != nil {
+			http.Error(w, `{"error": "encryption_failed", "message": "Failed to encrypt the secrets"}`, http.StatusInternalServerError)
+			return
+		}
+		backup.Secrets = enc
+	}
+	prefs, err := p.allUserPreferences()
+	if err != nil {
+		http.Error(w, `{"error": "kv_error", "message": "Failed to read user preferences"}`, http.StatusInternalServerError)
+		return
+	}
+	backup.Preferences = prefs
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="ai-limits-monitor-backup.json"`)
+	json.NewEncoder(w).Encode(backup)
+}
+
+// BackupImportResult reports what POST /api/v1/admin/backup/import restored.
+type BackupImportResult struct {
+	Settings    int      `json:"settings"`
+	Secrets     int      `json:"secrets"`
+	Preferences int      `json:"preferences"`
+	Skipped     []string `json:"skipped"` // unknown settings and users not found on this server
+}
+
+// handleBackupImport serves POST /api/v1/admin/backup/import: it applies the backup's
+// settings over the current ones, and its secrets when the passphrase is given. Secrets
+// left out of the backup keep their current values.
+func (p *Plugin) handleBackupImport(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Backup      Backup `json:"backup"`
+		Passphrase  string `json:"passphrase"`
+		Preferences *bool  `json:"preferences"` // restore user preferences; true by default
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4*1024*1024)).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	backupError := func(msg string) {
+		data, _ := json.Marshal(map[string]string{"error": "invalid_backup", "message": msg})
+		http.Error(w, string(data), http.StatusBadRequest)
+	}
+	if body.Backup.Version != backupVersion {
+		backupError(fmt.Sprintf("unsupported backup version %d", body.Backup.Version))
+		return
+	}
+
+	result := BackupImportResult{Skipped: []string{}}
+	known := configurationKeys()
+	settings := p.getConfiguration().withSecretReferences()
+	for key, value := range body.Backup.Settings {
+		if !known[key] || isSecretSetting(key) {
+			result.Skipped = append(result.Skipped, key)
+			continue
+		}
+		settings[key] = value
+		result.Settings++
+	}
+	if body.Backup.Secrets != nil {
+		if body.Passphrase == "" {
+			backupError("the backup has encrypted secrets: give the passphrase")
+			return
+		}
+		secrets, err := body.Backup.Secrets.decrypt(body.Passphrase)
+		if err != nil {
+			backupError(err.Error())
+			return
+		}
+		for key, value := range secrets {
+			if !known[key] {
+				result.Skipped = append(result.Skipped, key)
+				continue
+			}
+			settings[key] = value
+			result.Secrets++
+		}
+	}
+	// Check the settings decode before saving them
+	data, _ := json.Marshal(settings)
+	if err := json.Unmarshal(data, &Configuration{}); err != nil {
+		backupError("invalid settings: " + err.Error())
+		return
+	}
+	if appErr := p.API.SavePluginConfig(settings); appErr != nil {
+		http.Error(w, `{"error": "save_failed", "message": "Failed to save the configuration"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if body.Preferences == nil || *body.Preferences {
+		for _, entry := range body.Backup.Preferences {
+			user, appErr := p.API.GetUserByUsername(entry.Username)
+			if appErr != nil {
+				user, appErr = p.API.GetUser(entry.UserID)
+			}
+			prefs := entry.Preferences
+			if appErr != nil || prefs.validate() != nil {
+				result.Skipped = append(result.Skipped, "@"+entry.Username)
+				continue
+			}
+			data, _ := json.Marshal(prefs)
+			if appErr := p.API.KVSet(preferencesKey(user.Id), data); appErr != nil {
+				p.API.LogWarn("Failed to restore user preferences", "user_id", user.Id, "error", appErr.Error())
+				result.Skipped = append(result.Skipped, "@"+entry.Username)
+				continue
+			}
+			result.Preferences++
+		}
+	}
+	sort.Strings(result.Skipped)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== AWS Bedrock (IAM credentials) =====
+
+// BedrockUsageInfo is the account's month-to-date Bedrock spend from Cost Explorer and
+// its invocation and token counts from CloudWatch in the configured region.
+type BedrockUsageInfo struct {
+	TotalCost      float64 `json:"totalCost"`
+	Budget         float64 `json:"budget,omitempty"`
+	Currency       string  `json:"currency"`
+	Period         string  `json:"period"`
+	CycleEnd       string  `json:"cycleEnd"`
+	DaysUntilReset int     `json:"daysUntilReset"`
+	Region         string  `json:"region"`
+	HasMetrics     bool    `json:"hasMetrics"`
+	Invocations    float64 `json:"invocations"`
+	InputTokens    float64 `json:"inputTokens"`
+	OutputTokens   float64 `json:"outputTokens"`
+}
+
+func (b BedrockUsageInfo) summary(locale string) string {
+	text := translate(locale, "summary.spent", formatMoney(b.TotalCost, b.Currency, 2), b.Period)
+	if b.Budget > 0 {
+		text = translate(locale, "summary.spent_budget", formatMoney(b.TotalCost, b.Currency, 2), formatMoney(b.Budget, b.Currency, 0), b.Period)
+	}
+	if b.HasMetrics {
+		text += " " + translate(locale, "summary.bedrock_metrics", formatCount(b.Invocations), formatCount(b.InputTokens+b.OutputTokens))
+	}
+	return text
+}
+
+func (b BedrockUsageInfo) compact(name, locale string) string {
+	if b.Budget > 0 {
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(b.TotalCost, b.Currency, 0), formatMoney(b.Budget, b.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(b.TotalCost, b.Currency, 0))
+}
+
+func (b BedrockUsageInfo) usagePercent() (float64, bool) {
+	if b.Budget > 0 {
+		return b.TotalCost / b.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (b BedrockUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(b.CycleEnd)}}
+}
+
+// bedrockCostResponse is Cost Explorer's GetCostAndUsage grouped by service.
+type bedrockCostResponse struct {
+	ResultsByTime []struct {
+		Groups []struct {
+			Keys    []string `json:"Keys" schema:"required"`
+			Metrics struct {
+				UnblendedCost struct {
+					Amount flexFloat `json:"Amount" schema:"required"`
+					Unit   string    `json:"Unit"`
+				} `json:"UnblendedCost" schema:"required"`
+			} `json:"Metrics" schema:"required"`
+		} `json:"Groups"`
+		TimePeriod struct {
+			Start string `json:"Start"`
+			End   string `json:"End"`
+		} `json:"TimePeriod"`
+		Total     map[string]any `json:"Total"`
+		Estimated bool           `json:"Estimated"`
+	} `json:"ResultsByTime" schema:"required"`
+	DimensionValueAttributes []any  `json:"DimensionValueAttributes"`
+	GroupDefinitions         []any  `json:"GroupDefinitions"`
+	NextPageToken            string `json:"NextPageToken"`
+}
+
+// bedrockMetricsResponse is CloudWatch's GetMetricData.
+type bedrockMetricsResponse struct {
+	MetricDataResults []struct {
+		ID         string      `json:"Id" schema:"required"`
+		Label      string      `json:"Label"`
+		StatusCode string      `json:"StatusCode"`
+		Timestamps []flexFloat `json:"Timestamps"`
+		Values     []flexFloat `json:"Values" schema:"required"`
+	} `json:"MetricDataResults" schema:"required"`
+	Messages  []any  `json:"Messages"`
+	NextToken string `json:"NextToken"`
+}
+
+func (c *Configuration) bedrockCredentials() awsCredentials {
+	return awsCredentials{
+		AccessKeyID:     strings.TrimSpace(c.BedrockAccessKeyId),
+		SecretAccessKey: strings.TrimSpace(c.BedrockSecretAccessKey),
+		SessionToken:    strings.TrimSpace(c.BedrockSessionToken),
+	}
+}
+
+// bedrockRegion is the region whose CloudWatch metrics are read, us-east-1 by default.
+func (c *Configuration) bedrockRegion() string {
+	if region := strings.TrimSpace(c.BedrockRegion); region != "" {
+		return region
+	}
+	return "us-east-1"
+}
+
+func (p *Plugin) getBedrockStatus(config *Configuration) ServiceStatus {
+	const id, name = "bedrock", "AWS Bedrock"
+	creds := config.bedrockCredentials()
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return errorStatus(id, name, "error.aws_credentials_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := BedrockUsageInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+		Region:         config.bedrockRegion(),
+	}
+
+	body, err := p.awsCall(client, newBedrockCostRequest(creds, monthStart, now))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	var costs bedrockCostResponse
+	drift, err := decodeResponse(body, &costs)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	// Bedrock spend is split across services: "Amazon Bedrock" and one per marketplace
+	// model, e.g. "Claude 3.5 Sonnet (Amazon Bedrock Edition)"
+	for _, result := range costs.ResultsByTime {
+		for _, group := range result.Groups {
+			if len(group.Keys) == 0 || !strings.Contains(group.Keys[0], "Bedrock") {
+				continue
+			}
+			info.TotalCost += float64(group.Metrics.UnblendedCost.Amount)
+			if unit := group.Metrics.UnblendedCost.Unit; unit != "" {
+				info.Currency = strings.ToUpper(unit)
+			}
+		}
+	}
+
+	// Metrics are optional: the card still shows spend if CloudWatch access is missing
+	if body, err := p.awsCall(client, newBedrockMetricsRequest(creds, info.Region, monthStart, now)); err != nil {
+		p.API.LogWarn("Failed to fetch Bedrock metrics", "error", err.Error())
+	} else {
+		var metrics bedrockMetricsResponse
+		d, err := decodeResponse(body, &metrics)
+		if err != nil {
+			p.API.LogWarn("Failed to parse Bedrock metrics", "error", err.Error())
+		} else {
+			drift.merge(d)
+			info.HasMetrics = true
+			for _, result := range metrics.MetricDataResults {
+				total := 0.0
+				for _, v := range result.Values {
+					total += float64(v)
+				}
+				switch result.ID {
+				case "invocations":
+					info.Invocations = total
+				case "input":
+					info.InputTokens = total
+				case "output":
+					info.OutputTokens = total
+				}
+			}
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info.Budget = p.budgetIn(config, id, config.BedrockMonthlyBudget, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: budgetStatus(info.TotalCost, info.Budget, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// awsCall sends a signed request and returns the body of a successful response.
+func (p *Plugin) awsCall(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		// AWS JSON errors carry the message as "message" or "Message"
+		var errResp struct {
+			Type     string `json:"__type"`
+			Message  string `json:"message"`
+			Message2 string `json:"Message"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && (errResp.Message != "" || errResp.Message2 != "") {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, errResp.Message+errResp.Message2)
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+// newBedrockCostRequest asks Cost Explorer for this month's cost by service. Cost Explorer
+// is global and served from us-east-1; its end date is exclusive.
+func newBedrockCostRequest(creds awsCredentials, start, now time.Time) *http.Request {
+	end := now.AddDate(0, 0, 1)
+	payload, _ := json.Marshal(map[string]any{
+		"TimePeriod":  map[string]string{"Start": start.Format("2006-01-02"), "End": end.Format("2006-01-02")},
+		"Granularity": "MONTHLY",
+		"Metrics":     []string{"UnblendedCost"},
+		"GroupBy":     []map[string]string{{"Type": "DIMENSION", "Key": "SERVICE"}},
+	})
+	return newAWSJSONRequest(creds, "ce", "us-east-1", "ce.us-east-1.amazonaws.com",
+		"AWSInsightsIndexService.GetCostAndUsage", "application/x-amz-json-1.1", payload, now)
+}
+
+// newBedrockMetricsRequest sums the month's invocations and tokens across all models.
+func newBedrockMetricsRequest(creds awsCredentials, region string, start, now time.Time) *http.Request {
+	query := func(id, metric string) map[string]any {
+		return map[string]any{
+			"Id":         id,
+			"Expression": fmt.Sprintf(`SUM(SEARCH('{AWS/Bedrock,ModelId} MetricName="%s"', 'Sum', 86400))`, metric),
+			"Period":     86400,
+		}
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"MetricDataQueries": []map[string]any{
+			query("invocations", "Invocations"),
+			query("input", "InputTokenCount"),
+			query("output", "OutputTokenCount"),
+		},
+		"StartTime": start.Unix(),
+		"EndTime":   now.Unix(),
+	})
+	return newAWSJSONRequest(creds, "monitoring", region, "monitoring."+region+".amazonaws.com",
+		"GraniteServiceVersion20100801.GetMetricData", "application/x-amz-json-1.0", payload, now)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Boards (Focalboard) incident cards =====
+
+// boardsBlock is the subset of a Boards block needed to create cards through the v2 API.
+type boardsBlock struct {
+	ID       string                 `json:"id"`
+	BoardID  string                 `json:"boardId"`
+	ParentID string                 `json:"parentId"`
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Fields   map[string]interface{} `json:"fields"`
+	Schema   int                    `json:"schema"`
+	CreateAt int64                  `json:"createAt"`
+	UpdateAt int64                  `json:"updateAt"`
+}
+
+// createBoardsIncidentCard files a card on the configured board describing a provider incident.
+// The bot user must be a member of the board for the request to be accepted.
+func (p *Plugin) createBoardsIncidentCard(boardID string, s ServiceStatus, prevStatus, locale string) {
+	now := time.Now()
+	cardID := "c" + model.NewId()
+	textID := "t" + model.NewId()
+
+	s = localizeStatuses([]ServiceStatus{s}, locale)[0]
+	details := translate(locale, "alert.status_changed", s.Name, prevStatus, s.Status, now.UTC().Format(time.RFC1123)) + "\n\n"
+	if s.Error != "" {
+		details += translate(locale, "alert.error", s.Error) + "\n\n"
+	}
+	if s.Data != nil {
+		if raw, err := json.MarshalIndent(s.Data, "", "  "); err == nil {
+			details += "```json\n" + string(raw) + "\n```\n"
+		}
+	}
+
+	blocks := []boardsBlock{
+		{
+			ID: cardID, BoardID: boardID, ParentID: boardID, Type: "card",
+			Title: fmt.Sprintf("%s: %s", s.Name, s.Status),
+			Fields: map[string]interface{}{
+				"icon":         "🚨",
+				"properties":   map[string]interface{}{},
+				"contentOrder": []string{textID},
+				"isTemplate":   false,
+			},
+			Schema: 1, CreateAt: now.UnixMilli(), UpdateAt: now.UnixMilli(),
+		},
+		{
+			ID: textID, BoardID: boardID, ParentID: cardID, Type: "text",
+			Title:  details,
+			Fields: map[string]interface{}{},
+			Schema: 1, CreateAt: now.UnixMilli(), UpdateAt: now.UnixMilli(),
+		},
+	}
+
+	payload, _ := json.Marshal(blocks)
+	req, _ := http.NewRequest("POST", "/focalboard/api/v2/boards/"+boardID+"/blocks", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Mattermost-User-Id", p.botUserID)
+
+	resp := p.API.PluginHTTP(req)
+	if resp == nil {
+		p.API.LogWarn("Failed to create Boards card: Boards plugin unreachable", "provider", s.ID)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		p.API.LogWarn("Failed to create Boards card", "provider", s.ID,
+			"status", resp.StatusCode, "body", string(body[:min(len(body), 200)]))
+	}
+}
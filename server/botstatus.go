@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Bot custom status =====
+
+// updateBotStatus sets the bot's custom status to the overall status, so everyone can
+// see at a glance from the user list whether AI limits are fine.
+func (p *Plugin) updateBotStatus(services []ServiceStatus) {
+	if p.botUserID == "" {
+		return
+	}
+
+	locale := p.serverLocale()
+	config := p.getConfiguration()
+	overall := overallStatus(services, config)
+	causes := map[string]bool{}
+	for _, id := range overall.Causes {
+		causes[id] = true
+	}
+	var problems []string
+	for _, s := range services {
+		if causes[s.ID] {
+			problems = append(problems, compactSummary(s, locale))
+		}
+	}
+
+	status := &model.CustomStatus{}
+	switch overall.Status {
+	case "none":
+		status.Emoji = "white_circle"
+		status.Text = translate(locale, "botstatus.none")
+	case "ok":
+		status.Emoji = "large_green_circle"
+		status.Text = translate(locale, "botstatus.all_ok")
+	case "warning":
+		status.Emoji = "large_yellow_circle"
+		status.Text = strings.Join(problems, " | ")
+	default:
+		status.Emoji = "red_circle"
+		status.Text = strings.Join(problems, " | ")
+	}
+	if runway := runwayText(computeRunway(services, config, time.Now()), locale); runway != "" && overall.Status != "none" {
+		status.Text += " · " + runway
+	}
+	if runes := []rune(status.Text); len(runes) > model.CustomStatusTextMaxRunes {
+		status.Text = string(runes[:model.CustomStatusTextMaxRunes-1]) + "…"
+	}
+
+	// Only save changes; every update is broadcast to all clients
+	key := status.Emoji + " " + status.Text
+	p.stateLock.Lock()
+	unchanged := key == p.botStatus
+	p.botStatus = key
+	p.stateLock.Unlock()
+	if unchanged {
+		return
+	}
+
+	if appErr := p.API.UpdateUserCustomStatus(p.botUserID, status); appErr != nil {
+		p.API.LogWarn("Failed to update bot custom status", "error", appErr.Error())
+		p.stateLock.Lock()
+		p.botStatus = ""
+		p.stateLock.Unlock()
+	}
+}
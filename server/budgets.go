@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ===== Named budgets =====
+
+// budgetStatusPrefix starts the IDs budgets are tracked under, like cards, so their
+// status changes are alerted, sent to webhooks and recorded in the timeline.
+const budgetStatusPrefix = "budget:"
+
+var budgetSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// budgetDefinition is one entry of the Budgets setting. Sources are card, instance or
+// provider IDs such as "anthropic" or "openai:eu", or an OpenAI project under one, like
+// "openai/proj_abc".
+type budgetDefinition struct {
+	Name    string   `json:"name"`
+	Amount  string   `json:"amount"`            // e.g. "2000" or "1800 EUR", in the reporting currency by default
+	Period  string   `json:"period,omitempty"`  // "month": the billing cycles the cards report
+	Warning float64  `json:"warning,omitempty"` // percent, overrides the Warning Threshold
+	Sources []string `json:"sources"`
+}
+
+// BudgetStatus is a budget's spend this period, in the budget's currency.
+type BudgetStatus struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Period      string         `json:"period"`
+	Amount      float64        `json:"amount"`
+	Currency    string         `json:"currency"`
+	Spent       float64        `json:"spent"`
+	Percent     float64        `json:"percent"`
+	Status      string         `json:"status"`
+	Sources     []ProviderCost `json:"sources"`
+	Unconverted []string       `json:"unconverted,omitempty"` // cards whose currency has no exchange rate
+}
+
+func (b BudgetStatus) summary(locale string) string {
+	return translate(locale, "summary.budget", formatMoney(b.Spent, b.Currency, 2), formatMoney(b.Amount, b.Currency, 0), b.Percent)
+}
+
+func (b BudgetStatus) usagePercent() (float64, bool) {
+	if b.Amount > 0 {
+		return b.Spent / b.Amount * 100, true
+	}
+	return 0, false
+}
+
+// parseBudgets parses the Budgets setting, a JSON array of budget definitions. An invalid
+// entry fails the whole list, like the provider instances.
+func parseBudgets(raw string) ([]budgetDefinition, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var defs []budgetDefinition
+	if err := json.Unmarshal([]byte(raw), &defs); err != nil {
+		return nil, fmt.Errorf("not a JSON array of budgets: %v", err)
+	}
+	seen := map[string]bool{}
+	for i, def := range defs {
+		id := budgetID(def.Name)
+		switch {
+		case id == budgetStatusPrefix:
+			return nil, fmt.Errorf("budget %d: name is required", i+1)
+		case seen[id]:
+			return nil, fmt.Errorf("budget %q: duplicate name", def.Name)
+		case def.Period != "" && def.Period != "month":
+			return nil, fmt.Errorf("budget %q: period must be month", def.Name)
+		case def.Warning < 0 || def.Warning > 100:
+			return nil, fmt.Errorf("budget %q: warning must be a percentage", def.Name)
+		case len(def.Sources) == 0:
+			return nil, fmt.Errorf("budget %q: sources are required", def.Name)
+		}
+		seen[id] = true
+		if amount, _, err := parseMoney(def.Amount); err != nil || amount <= 0 {
+			return nil, fmt.Errorf("budget %q: %q is not an amount", def.Name, def.Amount)
+		}
+		defs[i].Period = "month"
+	}
+	return defs, nil
+}
+
+// budgetID is the status ID of a budget, e.g. "budget:ml-platform" for "ML Platform".
+func budgetID(name string) string {
+	return budgetStatusPrefix + strings.Trim(budgetSlugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// budgetSourceMatches reports whether a card is the source or scoped under it, e.g.
+// "openai:ml:org-abc" under "openai:ml".
+func budgetSourceMatches(cardID, source string) bool {
+	return cardID == source || strings.HasPrefix(cardID, source+":")
+}
+
+// computeBudget sums the spend of the budget's sources. A card is counted once, even
+// when several sources match it; OpenAI projects add their cost on cards not counted
+// whole.
+func computeBudget(def budgetDefinition, services []ServiceStatus, config *Configuration) BudgetStatus {
+	amount, currency, _ := parseMoney(def.Amount)
+	if currency == "" {
+		currency = config.reportingCurrency()
+	}
+	b := BudgetStatus{ID: budgetID(def.Name), Name: def.Name, Period: def.Period, Amount: amount, Currency: currency, Sources: []ProviderCost{}}
+
+	add := func(id, name string, cost float64, from string) {
+		converted, ok := config.convertMoney(cost, from, currency)
+		if !ok {
+			b.Unconverted = append(b.Unconverted, name)
+			return
+		}
+		b.Spent += converted
+		b.Sources = append(b.Sources, ProviderCost{ID: id, Name: name, Amount: converted})
+	}
+	counted := map[string]bool{}
+	for _, source := range def.Sources {
+		scope, project, _ := strings.Cut(strings.TrimSpace(source), "/")
+		for _, s := range services {
+			if !s.Enabled || (s.Error != "" && !s.Stale) || counted[s.ID] || !budgetSourceMatches(s.ID, scope) {
+				continue
+			}
+			if project == "" {
+				if m := computeUsage(s); m != nil && m.Cost != nil {
+					counted[s.ID] = true
+					add(s.ID, s.Name, *m.Cost, m.Currency)
+				}
+				continue
+			}
+			info, ok := s.Data.(OpenAIUsageInfo)
+			if !ok {
+				continue
+			}
+			for _, item := range info.ProjectCosts {
+				if item.ID == project {
+					name := item.Name
+					if name == "" {
+						name = item.ID
+					}
+					add(s.ID+"/"+item.ID, s.Name+" · "+name, item.Cost, info.currency())
+				}
+			}
+		}
+	}
+
+	b.Percent = math.Round(b.Spent/b.Amount*1000) / 10
+	warn := config.warningPercent(80)
+	if def.Warning > 0 {
+		warn = def.Warning
+	}
+	switch {
+	case b.Spent >= b.Amount:
+		b.Status = "error"
+	case b.Percent > warn:
+		b.Status = "warning"
+	default:
+		b.Status = "ok"
+	}
+	return b
+}
+
+// budgetStatuses computes every configured budget from the cards. An invalid Budgets
+// setting is logged and shows no budgets.
+func (p *Plugin) budgetStatuses(services []ServiceStatus, config *Configuration) []BudgetStatus {
+	defs, err := parseBudgets(config.Budgets)
+	if err != nil {
+		p.API.LogWarn("Invalid budgets", "error", err.Error())
+		return nil
+	}
+	var budgets []BudgetStatus
+	for _, def := range defs {
+		budgets = append(budgets, computeBudget(def, services, config))
+	}
+	return budgets
+}
+
+// trackBudgets tracks the budgets' statuses like cards', so a budget that turns yellow
+// or red is alerted on its own rather than as part of a provider. Budgets no longer
+// configured are forgotten.
+func (p *Plugin) trackBudgets(budgets []BudgetStatus) {
+	locale := p.serverLocale()
+	services := make([]ServiceStatus, 0, len(budgets))
+	current := map[string]bool{}
+	for _, b := range budgets {
+		services = append(services, ServiceStatus{
+			ID: b.ID, Name: translate(locale, "budget.name", b.Name), Enabled: true, Status: b.Status, Data: b,
+		})
+		current[b.ID] = true
+	}
+
+	p.stateLock.Lock()
+	for id := range p.states {
+		if strings.HasPrefix(id, budgetStatusPrefix) && !current[id] {
+			delete(p.states, id)
+		}
+	}
+	p.stateLock.Unlock()
+	p.trackStatusChanges(services)
+}
+
+// handleBudgets serves the admin budgets API: GET returns the definitions and their
+// spend, PUT replaces the definitions with a JSON array and saves them to the
+// configuration.
+func (p *Plugin) handleBudgets(w http.ResponseWriter, r *http.Request) {
+	config := p.getConfiguration()
+	defs, err := parseBudgets(config.Budgets)
+	if r.Method == http.MethodPut {
+		var body []budgetDefinition
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256*1024)).Decode(&body); err != nil {
+			http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON array of budgets"}`, http.StatusBadRequest)
+			return
+		}
+		data, _ := json.MarshalIndent(body, "", "  ")
+		if len(body) == 0 {
+			data = nil
+		}
+		if defs, err = parseBudgets(string(data)); err != nil {
+			msg, _ := json.Marshal(map[string]string{"error": "invalid_budgets", "message": err.Error()})
+			http.Error(w, string(msg), http.StatusBadRequest)
+			return
+		}
+		updated := *config
+		updated.Budgets = string(data)
+		if err := p.saveConfiguration(&updated); err != nil {
+			msg, _ := json.Marshal(map[string]string{"error": "save_failed", "message": err.Error()})
+			http.Error(w, string(msg), http.StatusInternalServerError)
+			return
+		}
+		config = &updated
+	} else if err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "invalid_budgets", "message": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+
+	services := p.collectStatuses()
+	budgets := []BudgetStatus{}
+	for _, def := range defs {
+		budgets = append(budgets, computeBudget(def, services, config))
+	}
+	if defs == nil {
+		defs = []budgetDefinition{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"definitions": defs, "budgets": budgets})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== iCalendar feed of resets =====
+
+// handleCalendar serves upcoming resets as an iCalendar feed. Calendar clients
+// can't send a Mattermost session, so the feed is protected by a shared token.
+func (p *Plugin) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	config := p.getConfiguration()
+	token := r.URL.Query().Get("token")
+	if config.CalendarToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(config.CalendarToken)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now()
+	locale := p.serverLocale()
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//AI Limits Monitor//Mattermost Plugin//EN\r\n")
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	b.WriteString("X-WR-CALNAME:AI Limit Resets\r\n")
+
+	for _, s := range p.collectStatuses() {
+		if !s.Enabled {
+			continue
+		}
+		for _, reset := range serviceResets(s, now) {
+			at := reset.At.UTC()
+			b.WriteString("BEGIN:VEVENT\r\n")
+			b.WriteString(fmt.Sprintf("UID:%s-%d@ai-limits-monitor\r\n", s.ID, at.Unix()))
+			b.WriteString("DTSTAMP:" + now.UTC().Format("20060102T150405Z") + "\r\n")
+			b.WriteString("DTSTART:" + at.Format("20060102T150405Z") + "\r\n")
+			b.WriteString("DTEND:" + at.Add(15*time.Minute).Format("20060102T150405Z") + "\r\n")
+			b.WriteString("SUMMARY:" + icsEscape(translate(locale, "calendar.event_summary", s.Name, reset.label(locale))) + "\r\n")
+			b.WriteString("DESCRIPTION:" + icsEscape(translate(locale, "calendar.event_description", summarizeService(s, locale))) + "\r\n")
+			b.WriteString("TRANSP:TRANSPARENT\r\n")
+			b.WriteString("END:VEVENT\r\n")
+		}
+	}
+
+	b.WriteString("END:VCALENDAR\r\n")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="ai-limits.ics"`)
+	w.Write([]byte(b.String()))
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func icsEscape(s string) string {
+	return icsEscaper.Replace(s)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Cerebras (Cerebras Cloud API key) =====
+
+const defaultCerebrasModels = "llama-3.3-70b"
+
+// CerebrasUsageInfo is the daily quota headroom of the monitored models. Like Groq, Cerebras
+// only reports limits in the headers of inference responses, so each model costs one minimal request.
+type CerebrasUsageInfo struct {
+	Models []CerebrasModelLimits `json:"models"`
+}
+
+// CerebrasModelLimits is one model's daily request and token quotas and per-minute token limit.
+// The daily token quota is only reported on tiers that have one.
+type CerebrasModelLimits struct {
+	Model                 string  `json:"model"`
+	RequestsLimit         float64 `json:"requestsLimit"` // per day
+	RequestsRemaining     float64 `json:"requestsRemaining"`
+	HasDailyTokens        bool    `json:"hasDailyTokens"`
+	TokensDayLimit        float64 `json:"tokensDayLimit"`
+	TokensDayRemaining    float64 `json:"tokensDayRemaining"`
+	DayReset              string  `json:"dayReset,omitempty"`
+	TokensMinuteLimit     float64 `json:"tokensMinuteLimit"`
+	TokensMinuteRemaining float64 `json:"tokensMinuteRemaining"`
+	Error                 string  `json:"error,omitempty"`
+}
+
+// dailyPercent is the larger share of the daily request and token quotas consumed.
+func (m CerebrasModelLimits) dailyPercent() float64 {
+	pct := 0.0
+	if m.RequestsLimit > 0 {
+		pct = (m.RequestsLimit - m.RequestsRemaining) / m.RequestsLimit * 100
+	}
+	if m.HasDailyTokens && m.TokensDayLimit > 0 {
+		pct = max(pct, (m.TokensDayLimit-m.TokensDayRemaining)/m.TokensDayLimit*100)
+	}
+	return pct
+}
+
+// exhausted reports whether a daily quota is used up, blocking the model until the reset.
+func (m CerebrasModelLimits) exhausted() bool {
+	return (m.RequestsLimit > 0 && m.RequestsRemaining <= 0) ||
+		(m.HasDailyTokens && m.TokensDayLimit > 0 && m.TokensDayRemaining <= 0)
+}
+
+// mostConstrained returns the reachable model closest to a daily quota, or false if none was reachable.
+func (d CerebrasUsageInfo) mostConstrained() (CerebrasModelLimits, bool) {
+	var worst CerebrasModelLimits
+	found := false
+	for _, m := range d.Models {
+		if m.Error != "" {
+			continue
+		}
+		if !found || m.dailyPercent() > worst.dailyPercent() {
+			worst, found = m, true
+		}
+	}
+	return worst, found
+}
+
+func (d CerebrasUsageInfo) summary(locale string) string {
+	m, ok := d.mostConstrained()
+	if !ok {
+		return ""
+	}
+	if m.HasDailyTokens {
+		return translate(locale, "summary.cerebras_tokens", m.Model, formatCount(m.TokensDayRemaining), formatCount(m.TokensDayLimit), formatCount(m.RequestsRemaining), formatCount(m.RequestsLimit))
+	}
+	return translate(locale, "summary.cerebras", m.Model, formatCount(m.RequestsRemaining), formatCount(m.RequestsLimit), formatCount(m.TokensMinuteLimit))
+}
+
+func (d CerebrasUsageInfo) usagePercent() (float64, bool) {
+	if m, ok := d.mostConstrained(); ok {
+		return m.dailyPercent(), true
+	}
+	return 0, false
+}
+
+func (d CerebrasUsageInfo) resets(time.Time) []ResetInfo {
+	if m, ok := d.mostConstrained(); ok {
+		return []ResetInfo{{Kind: "daily_quota", At: parseTime(m.DayReset)}}
+	}
+	return nil
+}
+
+// cerebrasModels is the configured list of models to monitor.
+func (c *Configuration) cerebrasModels() []string {
+	models := splitList(c.CerebrasModels)
+	if len(models) == 0 {
+		return []string{defaultCerebrasModels}
+	}
+	return models
+}
+
+func (p *Plugin) getCerebrasStatus(config *Configuration) ServiceStatus {
+	const id, name = "cerebras", "Cerebras"
+	if config.CerebrasApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	now := time.Now().UTC()
+	var info CerebrasUsageInfo
+	failed := 0
+	for _, model := range config.cerebrasModels() {
+		limits, err := p.fetchCerebrasLimits(client, config, model, now)
+		if err != nil {
+			// An invalid key fails every model the same way
+			if errAuth, ok := err.(authError); ok {
+				return errorStatus(id, name, "error.http", errAuth.status, errAuth.message)
+			}
+			limits = CerebrasModelLimits{Model: model, Error: err.Error()}
+			failed++
+		}
+		info.Models = append(info.Models, limits)
+	}
+	if failed == len(info.Models) {
+		return errorStatus(id, name, "error.api", info.Models[0].Error)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: cerebrasStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// cerebrasStatus is an error once any model's daily quota is used up.
+func cerebrasStatus(info CerebrasUsageInfo, config *Configuration) string {
+	status := "ok"
+	for _, m := range info.Models {
+		if m.Error != "" {
+			continue
+		}
+		if m.exhausted() {
+			return "error"
+		}
+		if m.dailyPercent() > config.warningPercent(90) {
+			status = "warning"
+		}
+	}
+	return status
+}
+
+// fetchCerebrasLimits sends a one-token completion and reads the rate limit headers.
+// Rate limited responses carry the same headers, so they count as a reading too.
+func (p *Plugin) fetchCerebrasLimits(client *http.Client, config *Configuration, model string, now time.Time) (CerebrasModelLimits, error) {
+	resp, err := client.Do(newCerebrasRequest(config, model))
+	if err != nil {
+		return CerebrasModelLimits{}, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return CerebrasModelLimits{}, err
+	}
+
+	if resp.StatusCode != 200 && resp.StatusCode != 429 {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		message := string(body[:min(len(body), 200)])
+		if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+			message = errResp.Message
+		}
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			return CerebrasModelLimits{}, authError{status: resp.StatusCode, message: message}
+		}
+		return CerebrasModelLimits{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, message)
+	}
+
+	limits := CerebrasModelLimits{Model: model}
+	limits.RequestsLimit, _ = headerFloat(resp.Header, "x-ratelimit-limit-requests-day")
+	limits.RequestsRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-requests-day")
+	limits.TokensMinuteLimit, _ = headerFloat(resp.Header, "x-ratelimit-limit-tokens-minute")
+	limits.TokensMinuteRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-tokens-minute")
+	if limit, ok := headerFloat(resp.Header, "x-ratelimit-limit-tokens-day"); ok {
+		limits.HasDailyTokens = true
+		limits.TokensDayLimit = limit
+		limits.TokensDayRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-tokens-day")
+	}
+	limits.DayReset = cerebrasResetTime(resp.Header.Get("x-ratelimit-reset-requests-day"), now)
+	return limits, nil
+}
+
+// cerebrasResetTime converts a reset delay in seconds, e.g. "33011.382867", to an RFC 3339 timestamp.
+func cerebrasResetTime(value string, now time.Time) string {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds <= 0 {
+		return ""
+	}
+	return now.Add(time.Duration(seconds * float64(time.Second))).Format(time.RFC3339)
+}
+
+func newCerebrasRequest(config *Configuration, model string) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"model":                 model,
+		"messages":              []map[string]string{{"role": "user", "content": "."}},
+		"max_completion_tokens": 1,
+	})
+	req, _ := http.NewRequest("POST", "https://api.cerebras.ai/v1/chat/completions", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+config.CerebrasApiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
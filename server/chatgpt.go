@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== ChatGPT (plan limits via the web app's session) =====
+
+// chatGPTSessionCookie is the cookie that keeps a chatgpt.com login signed in.
+const chatGPTSessionCookie = "__Secure-next-auth.session-token"
+
+// ChatGPTUsageInfo is the state of a ChatGPT plan's limits as the web app sees them: the
+// models whose message cap was reached, and the allowances of metered features such as
+// deep research. ChatGPT doesn't report how many messages are left before a cap, only that
+// one was reached and when it lifts.
+type ChatGPTUsageInfo struct {
+	Plan            string                `json:"plan,omitempty"` // "plus", "pro", "team", ...
+	RenewsAt        string                `json:"renewsAt,omitempty"`
+	CappedModels    []ChatGPTModelCap     `json:"cappedModels,omitempty"`
+	Features        []ChatGPTFeatureLimit `json:"features,omitempty"`
+	BlockedFeatures []string              `json:"blockedFeatures,omitempty"`
+	HasData         bool                  `json:"hasData"`
+}
+
+// ChatGPTModelCap is a model whose message cap was reached.
+type ChatGPTModelCap struct {
+	Model       string `json:"model"`
+	ResetsAfter string `json:"resetsAfter,omitempty"`
+}
+
+// ChatGPTFeatureLimit is the allowance left of a metered feature, e.g. "deep_research".
+type ChatGPTFeatureLimit struct {
+	Feature    string `json:"feature"`
+	Remaining  int    `json:"remaining"`
+	ResetAfter string `json:"resetAfter,omitempty"`
+}
+
+// nextReset is when the earliest cap lifts, or the zero time without a cap.
+func (c ChatGPTUsageInfo) nextReset() time.Time {
+	var next time.Time
+	for _, m := range c.CappedModels {
+		if t := parseTime(m.ResetsAfter); !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
+
+func (c ChatGPTUsageInfo) summary(locale string) string {
+	if !c.HasData {
+		return translate(locale, "summary.no_data")
+	}
+	var parts []string
+	if c.Plan != "" {
+		parts = append(parts, translate(locale, "summary.chatgpt_plan", c.Plan))
+	}
+	if len(c.CappedModels) > 0 {
+		var models []string
+		for _, m := range c.CappedModels {
+			models = append(models, m.Model)
+		}
+		parts = append(parts, translate(locale, "summary.chatgpt_capped", strings.Join(models, ", ")))
+	}
+	if len(c.BlockedFeatures) > 0 {
+		parts = append(parts, translate(locale, "summary.chatgpt_blocked", strings.Join(c.BlockedFeatures, ", ")))
+	}
+	for _, f := range c.Features {
+		parts = append(parts, translate(locale, "summary.chatgpt_feature", strings.ReplaceAll(f.Feature, "_", " "), f.Remaining))
+	}
+	if len(c.CappedModels) == 0 && len(c.BlockedFeatures) == 0 {
+		parts = append(parts, translate(locale, "summary.chatgpt_no_caps"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c ChatGPTUsageInfo) compact(name, locale string) string {
+	switch {
+	case len(c.CappedModels) > 0:
+		return translate(locale, "compact.models_capped", name, len(c.CappedModels))
+	case c.Plan != "":
+		return fmt.Sprintf("%s %s", name, c.Plan)
+	}
+	return name
+}
+
+func (c ChatGPTUsageInfo) resets(time.Time) []ResetInfo {
+	resets := []ResetInfo{{Kind: "chatgpt_cap", At: c.nextReset()}}
+	for _, f := range c.Features {
+		if f.Remaining <= 0 {
+			resets = append(resets, ResetInfo{Kind: "chatgpt_feature", At: parseTime(f.ResetAfter)})
+		}
+	}
+	return resets
+}
+
+// chatGPTStatus is an error while a model's cap blocks the account, and a warning while a
+// feature is used up or blocked.
+func chatGPTStatus(info ChatGPTUsageInfo) string {
+	if len(info.CappedModels) > 0 {
+		return "error"
+	}
+	if len(info.BlockedFeatures) > 0 {
+		return "warning"
+	}
+	for _, f := range info.Features {
+		if f.Remaining <= 0 {
+			return "warning"
+		}
+	}
+	return "ok"
+}
+
+// chatGPTSessionResponse is GET /api/auth/session. An expired session answers with an
+// empty object.
+type chatGPTSessionResponse struct {
+	AccessToken string `json:"accessToken"`
+	Expires     string `json:"expires"`
+}
+
+// chatGPTAccountsResponse is GET /backend-api/accounts/check, keyed by account ID.
+type chatGPTAccountsResponse struct {
+	Accounts map[string]struct {
+		Account struct {
+			AccountID string `json:"account_id"`
+			PlanType  string `json:"plan_type"`
+		} `json:"account"`
+		Entitlement struct {
+			SubscriptionPlan      string `json:"subscription_plan"`
+			ExpiresAt             string `json:"expires_at"`
+			HasActiveSubscription bool   `json:"has_active_subscription"`
+		} `json:"entitlement"`
+	} `json:"accounts" schema:"required"`
+	AccountOrdering []string `json:"account_ordering"`
+}
+
+// chatGPTInitResponse is POST /backend-api/conversation/init, which the web app calls
+// before a new conversation to learn which models and features it may use.
+type chatGPTInitResponse struct {
+	Type            string   `json:"type"`
+	BlockedFeatures []string `json:"blocked_features"`
+	ModelLimits     []struct {
+		ModelSlug   string `json:"model_slug"`
+		ResetsAfter string `json:"resets_after"`
+	} `json:"model_limits" schema:"required"`
+	LimitsProgress []struct {
+		FeatureName string `json:"feature_name"`
+		Remaining   int    `json:"remaining"`
+		ResetAfter  string `json:"reset_after"`
+	} `json:"limits_progress"`
+	DefaultModelSlug string `json:"default_model_slug"`
+}
+
+// chatGPTCredentials returns the access token and account to use: the session token's,
+// or the Codex card's OAuth login when no session token is set. expired is true when the
+// session has to be renewed.
+func (p *Plugin) chatGPTCredentials(client *http.Client, config *Configuration) (token, account string, expired bool, err error) {
+	if config.ChatgptSessionToken == "" {
+		account = config.ChatgptAccountId
+		if account == "" {
+			account = config.CodexAccountId
+		}
+		return config.CodexAccessToken, account, false, nil
+	}
+
+	resp, err := client.Do(newChatGPTSessionRequest(config))
+	if err != nil {
+		return "", "", false, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return "", "", false, err
+	}
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return "", "", true, nil
+	}
+	var session chatGPTSessionResponse
+	if resp.StatusCode != 200 || json.Unmarshal(body, &session) != nil {
+		return "", "", false, fmt.Errorf("session HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	if session.AccessToken == "" {
+		return "", "", true, nil
+	}
+	return session.AccessToken, config.ChatgptAccountId, false, nil
+}
+
+func (p *Plugin) getChatGPTStatus(config *Configuration) ServiceStatus {
+	const id, name = "chatgpt", "ChatGPT"
+	if config.ChatgptSessionToken == "" && config.CodexAccessToken == "" {
+		return errorStatus(id, name, "error.chatgpt_token_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	token, account, expired, err := p.chatGPTCredentials(client, config)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if expired {
+		return reauthStatus(id, name, "error.chatgpt_reauth")
+	}
+	var drift schemaDrift
+
+	// The plan is only decoration, so the card works without it
+	info := ChatGPTUsageInfo{}
+	if body, status, err := p.chatGPTCall(client, newChatGPTAccountsRequest(token, account)); err == nil && status == 200 {
+		var accounts chatGPTAccountsResponse
+		if d, err := decodeResponse(body, &accounts); err == nil {
+			drift.merge(d)
+			info.Plan, info.RenewsAt = accounts.plan(account)
+		}
+	}
+
+	body, status, err := p.chatGPTCall(client, newChatGPTInitRequest(token, account))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if status == 401 {
+		return reauthStatus(id, name, "error.chatgpt_reauth")
+	}
+	if status != 200 {
+		return errorStatus(id, name, "error.http", status, string(body[:min(len(body), 200)]))
+	}
+	var raw chatGPTInitResponse
+	d, err := decodeResponse(body, &raw)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	drift.merge(d)
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info.HasData = true
+	info.BlockedFeatures = raw.BlockedFeatures
+	for _, m := range raw.ModelLimits {
+		info.CappedModels = append(info.CappedModels, ChatGPTModelCap{Model: m.ModelSlug, ResetsAfter: m.ResetsAfter})
+	}
+	for _, f := range raw.LimitsProgress {
+		info.Features = append(info.Features, ChatGPTFeatureLimit{Feature: f.FeatureName, Remaining: f.Remaining, ResetAfter: f.ResetAfter})
+	}
+	sort.Slice(info.Features, func(i, j int) bool { return info.Features[i].Feature < info.Features[j].Feature })
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: chatGPTStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// plan returns the plan and renewal date of the account, or of the first one.
+func (r chatGPTAccountsResponse) plan(account string) (string, string) {
+	key := account
+	if _, ok := r.Accounts[key]; !ok && len(r.AccountOrdering) > 0 {
+		key = r.AccountOrdering[0]
+	}
+	a, ok := r.Accounts[key]
+	if !ok {
+		return "", ""
+	}
+	renews := ""
+	if a.Entitlement.HasActiveSubscription {
+		renews = a.Entitlement.ExpiresAt
+	}
+	return strings.ToLower(a.Account.PlanType), renews
+}
+
+// chatGPTCall sends a request and returns the body and status code.
+func (p *Plugin) chatGPTCall(client *http.Client, req *http.Request) ([]byte, int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+func newChatGPTSessionRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://chatgpt.com/api/auth/session", nil)
+	req.AddCookie(&http.Cookie{Name: chatGPTSessionCookie, Value: config.ChatgptSessionToken})
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+func newChatGPTAccountsRequest(token, account string) *http.Request {
+	req, _ := http.NewRequest("GET", "https://chatgpt.com/backend-api/accounts/check/v4-2023-04-27", nil)
+	setChatGPTHeaders(req, token, account)
+	return req
+}
+
+func newChatGPTInitRequest(token, account string) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"gizmo_id":                nil,
+		"requested_default_model": nil,
+		"conversation_id":         nil,
+		"timezone_offset_min":     0,
+	})
+	req, _ := http.NewRequest("POST", "https://chatgpt.com/backend-api/conversation/init", bytes.NewReader(payload))
+	setChatGPTHeaders(req, token, account)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func setChatGPTHeaders(req *http.Request, token, account string) {
+	req.Header.Set("Authorization", "Bearer "+token)
+	if account != "" {
+		req.Header.Set("ChatGPT-Account-Id", account)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Claude Code (usage pushed from developers' machines) =====
+
+const (
+	claudeCodeIndexKey      = "claudecode_index"
+	claudeCodeMaxHosts      = 10
+	claudeCodeMaxEntries    = 500 // day and model pairs per report
+	claudeCodeRetentionDays = 62
+	claudeCodeStaleAfter    = 48 * time.Hour
+)
+
+// claudeCodePrices are the API list prices per million input and output tokens by model
+// family, checked in order. Cache writes cost 1.25x input and cache reads 0.1x input.
+var claudeCodePrices = []struct {
+	match         string
+	input, output float64
+}{
+	{"opus-4-5", 5, 25},
+	{"opus", 15, 75},
+	{"sonnet", 3, 15},
+	{"haiku-4-5", 1, 5},
+	{"haiku", 0.8, 4},
+}
+
+// ClaudeCodeInfo is the month's Claude Code usage per developer, as reported by the companion
+// agent from each machine's local session logs. Cost is what the tokens would cost at API list
+// prices, which is how heavy subscription use compares with paying per token.
+type ClaudeCodeInfo struct {
+	Developers      []ClaudeCodeDeveloper `json:"developers"`
+	TotalTokens     float64               `json:"totalTokens"`
+	TotalCost       float64               `json:"totalCost"`
+	Sessions        int                   `json:"sessions"`
+	DeveloperBudget float64               `json:"developerBudget,omitempty"`
+	Currency        string                `json:"currency"`
+	Period          string                `json:"period"`
+	CycleEnd        string                `json:"cycleEnd"`
+	DaysUntilReset  int                   `json:"daysUntilReset"`
+}
+
+// ClaudeCodeDeveloper is one developer's usage this month, summed over their machines.
+type ClaudeCodeDeveloper struct {
+	UserID       string   `json:"userId"`
+	Username     string   `json:"username"`
+	Hosts        []string `json:"hosts"`
+	InputTokens  float64  `json:"inputTokens"`
+	OutputTokens float64  `json:"outputTokens"`
+	CacheTokens  float64  `json:"cacheTokens"` // cache writes and reads
+	Cost         float64  `json:"cost"`
+	Sessions     int      `json:"sessions"`
+	TopModel     string   `json:"topModel,omitempty"`
+	LastReport   string   `json:"lastReport"`
+	Stale        bool     `json:"stale,omitempty"` // no report for two days
+}
+
+// claudeCodeUsage is the usage of one model on one day, as sent by the agent.
+type claudeCodeUsage struct {
+	Date                string  `json:"date"` // UTC, YYYY-MM-DD
+	Model               string  `json:"model"`
+	InputTokens         float64 `json:"inputTokens"`
+	OutputTokens        float64 `json:"outputTokens"`
+	CacheCreationTokens float64 `json:"cacheCreationTokens"`
+	CacheReadTokens     float64 `json:"cacheReadTokens"`
+	Sessions            int     `json:"sessions"`
+}
+
+// claudeCodeHostReport is the latest report from one machine. Each report replaces the
+// previous one, so the agent can resend the same days without counting them twice.
+type claudeCodeHostReport struct {
+	ReceivedAt string            `json:"receivedAt"`
+	Usage      []claudeCodeUsage `json:"usage"`
+}
+
+// claudeCodeReport is everything a developer's machines have reported, keyed by host name.
+type claudeCodeReport struct {
+	Hosts map[string]claudeCodeHostReport `json:"hosts"`
+}
+
+func claudeCodeReportKey(userID string) string {
+	return "claudecode_" + userID
+}
+
+// cost prices the tokens at the model's API list price.
+func (u claudeCodeUsage) cost() float64 {
+	model := strings.ToLower(strings.ReplaceAll(u.Model, ".", "-"))
+	for _, price := range claudeCodePrices {
+		if strings.Contains(model, price.match) {
+			return (u.InputTokens*price.input + u.CacheCreationTokens*price.input*1.25 +
+				u.CacheReadTokens*price.input*0.1 + u.OutputTokens*price.output) / 1e6
+		}
+	}
+	return 0
+}
+
+// handleClaudeCodeUsage stores a report from the companion agent. The agent authenticates
+// with the developer's own access token, so any user may report their usage, but only
+// their own.
+func (p *Plugin) handleClaudeCodeUsage(w http.ResponseWriter, r *http.Request, userID string) {
+	if !p.getConfiguration().ClaudeCodeEnabled {
+		http.Error(w, `{"error": "disabled", "message": "Claude Code monitoring is not enabled"}`, http.StatusForbidden)
+		return
+	}
+	var body struct {
+		Host  string            `json:"host"`
+		Usage []claudeCodeUsage `json:"usage"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 256*1024)).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validateClaudeCodeReport(&body.Host, body.Usage); err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "invalid_report", "message": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	report := p.getClaudeCodeReport(userID)
+	if _, known := report.Hosts[body.Host]; !known && len(report.Hosts) >= claudeCodeMaxHosts {
+		http.Error(w, `{"error": "too_many_hosts", "message": "Too many machines reporting for this user"}`, http.StatusBadRequest)
+		return
+	}
+	cutoff := now.AddDate(0, 0, -claudeCodeRetentionDays).Format("2006-01-02")
+	var kept []claudeCodeUsage
+	for _, u := range body.Usage {
+		if u.Date >= cutoff {
+			kept = append(kept, u)
+		}
+	}
+	report.Hosts[body.Host] = claudeCodeHostReport{ReceivedAt: now.Format(time.RFC3339), Usage: kept}
+
+	data, _ := json.Marshal(report)
+	if appErr := p.API.KVSet(claudeCodeReportKey(userID), data); appErr != nil {
+		http.Error(w, `{"error": "kv_error", "message": "Failed to save the report"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := p.addClaudeCodeReporter(userID); err != nil {
+		p.API.LogWarn("Failed to update the Claude Code reporters", "error", err.Error())
+	}
+	// Show the new report on the next refresh rather than after the cache expires
+	p.cacheLock.Lock()
+	delete(p.cache, "claudecode")
+	p.cacheLock.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"accepted": len(kept)})
+}
+
+func validateClaudeCodeReport(host *string, usage []claudeCodeUsage) error {
+	*host = strings.TrimSpace(*host)
+	if *host == "" || len(*host) > 100 {
+		return fmt.Errorf("host must be between 1 and 100 characters")
+	}
+	if len(usage) > claudeCodeMaxEntries {
+		return fmt.Errorf("at most %d usage entries per report", claudeCodeMaxEntries)
+	}
+	for _, u := range usage {
+		if _, err := time.Parse("2006-01-02", u.Date); err != nil {
+			return fmt.Errorf("date must be YYYY-MM-DD, got %q", u.Date)
+		}
+		if u.Model == "" || len(u.Model) > 100 {
+			return fmt.Errorf("model must be between 1 and 100 characters")
+		}
+		if u.InputTokens < 0 || u.OutputTokens < 0 || u.CacheCreationTokens < 0 || u.CacheReadTokens < 0 || u.Sessions < 0 {
+			return fmt.Errorf("token and session counts can't be negative")
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) getClaudeCodeReport(userID string) claudeCodeReport {
+	report := claudeCodeReport{}
+	if data, appErr := p.API.KVGet(claudeCodeReportKey(userID)); appErr == nil && data != nil {
+		json.Unmarshal(data, &report)
+	}
+	if report.Hosts == nil {
+		report.Hosts = map[string]claudeCodeHostReport{}
+	}
+	return report
+}
+
+// claudeCodeReporters lists the users who have reported usage.
+func (p *Plugin) claudeCodeReporters() []string {
+	var users []string
+	if data, appErr := p.API.KVGet(claudeCodeIndexKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &users)
+	}
+	return users
+}
+
+func (p *Plugin) addClaudeCodeReporter(userID string) error {
+	users := p.claudeCodeReporters()
+	for _, u := range users {
+		if u == userID {
+			return nil
+		}
+	}
+	data, _ := json.Marshal(append(users, userID))
+	if appErr := p.API.KVSet(claudeCodeIndexKey, data); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+func (p *Plugin) getClaudeCodeStatus(config *Configuration) ServiceStatus {
+	const id, name = "claudecode", "Claude Code"
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := ClaudeCodeInfo{
+		Developers:      []ClaudeCodeDeveloper{},
+		DeveloperBudget: p.budgetIn(config, id, config.ClaudeCodeDeveloperBudget, "USD"),
+		Currency:        "USD",
+		Period:          cyclePeriod(monthStart, monthEnd),
+		CycleEnd:        monthEnd.Format(time.RFC3339),
+		DaysUntilReset:  int(monthEnd.Sub(now).Hours() / 24),
+	}
+	from := monthStart.Format("2006-01-02")
+	for _, userID := range p.claudeCodeReporters() {
+		if dev, ok := claudeCodeDeveloperUsage(userID, p.getClaudeCodeReport(userID), from, now); ok {
+			if user, appErr := p.API.GetUser(userID); appErr == nil {
+				dev.Username = user.Username
+			}
+			info.Developers = append(info.Developers, dev)
+			info.TotalTokens += dev.InputTokens + dev.OutputTokens + dev.CacheTokens
+			info.TotalCost += dev.Cost
+			info.Sessions += dev.Sessions
+		}
+	}
+	sort.SliceStable(info.Developers, func(i, j int) bool { return info.Developers[i].Cost > info.Developers[j].Cost })
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: claudeCodeStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// claudeCodeDeveloperUsage sums a developer's usage since from over all their machines, or
+// returns false if there's none.
+func claudeCodeDeveloperUsage(userID string, report claudeCodeReport, from string, now time.Time) (ClaudeCodeDeveloper, bool) {
+	dev := ClaudeCodeDeveloper{UserID: userID, Username: userID, Hosts: []string{}}
+	modelCost := map[string]float64{}
+	var last time.Time
+	for host, hr := range report.Hosts {
+		dev.Hosts = append(dev.Hosts, host)
+		if at := parseTime(hr.ReceivedAt); at.After(last) {
+			last = at
+		}
+		for _, u := range hr.Usage {
+			if u.Date < from {
+				continue
+			}
+			dev.InputTokens += u.InputTokens
+			dev.OutputTokens += u.OutputTokens
+			dev.CacheTokens += u.CacheCreationTokens + u.CacheReadTokens
+			dev.Sessions += u.Sessions
+			dev.Cost += u.cost()
+			modelCost[u.Model] += u.cost()
+		}
+	}
+	if len(modelCost) == 0 {
+		return dev, false
+	}
+	sort.Strings(dev.Hosts)
+	for model, cost := range modelCost {
+		if dev.TopModel == "" || cost > modelCost[dev.TopModel] {
+			dev.TopModel = model
+		}
+	}
+	dev.LastReport = last.Format(time.RFC3339)
+	dev.Stale = now.Sub(last) > claudeCodeStaleAfter
+	return dev, true
+}
+
+// claudeCodeStatus compares each developer with the per-developer budget, if one is set.
+func claudeCodeStatus(info ClaudeCodeInfo, config *Configuration) string {
+	status := "ok"
+	for _, d := range info.Developers {
+		switch budgetStatus(d.Cost, info.DeveloperBudget, config) {
+		case "error":
+			return "error"
+		case "warning":
+			status = "warning"
+		}
+	}
+	return status
+}
+
+// mostConstrained returns the developer closest to the budget, if one is set.
+func (i ClaudeCodeInfo) mostConstrained() (ClaudeCodeDeveloper, bool) {
+	if i.DeveloperBudget <= 0 || len(i.Developers) == 0 {
+		return ClaudeCodeDeveloper{}, false
+	}
+	// Developers are sorted by cost
+	return i.Developers[0], true
+}
+
+func (i ClaudeCodeInfo) summary(locale string) string {
+	if len(i.Developers) == 0 {
+		return translate(locale, "summary.claudecode_no_reports")
+	}
+	text := translate(locale, "summary.claudecode", len(i.Developers), formatCount(i.TotalTokens), formatMoney(i.TotalCost, i.Currency, 2), i.Period)
+	if worst, ok := i.mostConstrained(); ok {
+		text += ", " + translate(locale, "summary.worst_spender", worst.Username, formatMoney(worst.Cost, i.Currency, 2), formatMoney(i.DeveloperBudget, i.Currency, 0))
+	}
+	return text
+}
+
+func (i ClaudeCodeInfo) compact(name, locale string) string {
+	if worst, ok := i.mostConstrained(); ok {
+		return fmt.Sprintf("%s @%s %s/%s", name, worst.Username, formatMoney(worst.Cost, i.Currency, 0), formatMoney(i.DeveloperBudget, i.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s tok", name, formatCount(i.TotalTokens))
+}
+
+func (i ClaudeCodeInfo) usagePercent() (float64, bool) {
+	if worst, ok := i.mostConstrained(); ok {
+		return worst.Cost / i.DeveloperBudget * 100, true
+	}
+	return 0, false
+}
+
+func (i ClaudeCodeInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(i.CycleEnd)}}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== claude.ai sign-in (OAuth authorization code with PKCE) and token renewal =====
+
+const (
+	claudeOAuthClientID     = "9d1c250a-e61b-44d9-88ed-5944d1962f5e"
+	claudeOAuthAuthorizeURL = "https://claude.ai/oauth/authorize"
+	claudeOAuthTokenURL     = "https://platform.claude.com/v1/oauth/token"
+	// The client only redirects to Anthropic's own page, which shows the code to paste back
+	claudeOAuthRedirectURI = "https://platform.claude.com/oauth/code/callback"
+	claudeOAuthScopes      = "user:profile user:inference"
+
+	claudeOAuthTokensKey     = "claude_oauth_tokens"
+	claudeOAuthPendingPrefix = "claude_oauth_pending_"
+	// claudeOAuthPendingTTL is how long a started sign-in can be completed.
+	claudeOAuthPendingTTL = 15 * time.Minute
+	// claudeTokenRefreshMargin is how long before expiry the background job renews the
+	// access token. Fetches renew it themselves only once it has expired.
+	claudeTokenRefreshMargin = 10 * time.Minute
+)
+
+// claudeOAuthTokens are the current claude.ai tokens: those of the account an admin signed
+// in with, or those renewed from the configured ones. They are kept in the KV store, so a
+// renewal doesn't write the whole configuration back.
+type claudeOAuthTokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresAt    int64  `json:"expiresAt,omitempty"` // Unix time; zero when unknown
+	ConnectedBy  string `json:"connectedBy,omitempty"`
+	ConnectedAt  int64  `json:"connectedAt,omitempty"`
+	// Hash of the configured refresh token these were renewed from; empty after sign-in
+	Source string `json:"source,omitempty"`
+}
+
+// signedIn reports whether the tokens come from an admin signing in.
+func (t claudeOAuthTokens) signedIn() bool {
+	return t.Source == ""
+}
+
+// claudeOAuthPending is a started sign-in, stored under its state until it's completed.
+type claudeOAuthPending struct {
+	Verifier string `json:"verifier"`
+	UserID   string `json:"userId"`
+}
+
+// claudeTokenResponse is the token endpoint's answer to a code exchange or renewal.
+type claudeTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// expiresAt is the Unix time the access token expires, or zero when not given.
+func (t claudeTokenResponse) expiresAt(now time.Time) int64 {
+	if t.ExpiresIn <= 0 {
+		return 0
+	}
+	return now.Add(time.Duration(t.ExpiresIn) * time.Second).Unix()
+}
+
+// claudeTokensKey is where the plugin's tokens are stored: the organization's, a user's
+// personal ones, or a provider instance's.
+func (p *Plugin) claudeTokensKey() string {
+	return p.scopedKey(claudeOAuthTokensKey)
+}
+
+func (p *Plugin) getClaudeOAuthTokens() (claudeOAuthTokens, bool) {
+	var tokens claudeOAuthTokens
+	data, appErr := p.API.KVGet(p.claudeTokensKey())
+	if appErr != nil || data == nil || json.Unmarshal(data, &tokens) != nil || tokens.AccessToken == "" {
+		return claudeOAuthTokens{}, false
+	}
+	return tokens, true
+}
+
+func (p *Plugin) saveClaudeOAuthTokens(tokens claudeOAuthTokens) error {
+	data, _ := json.Marshal(tokens)
+	if appErr := p.API.KVSet(p.claudeTokensKey(), data); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// currentClaudeTokens returns the stored tokens that apply to the configuration: those of
+// a signed-in account, or those renewed from the configured tokens as long as an admin
+// hasn't replaced them since.
+func (p *Plugin) currentClaudeTokens(config *Configuration) (claudeOAuthTokens, bool) {
+	tokens, ok := p.getClaudeOAuthTokens()
+	if !ok || !tokens.signedIn() && tokens.Source != credentialHash(config.ClaudeRefreshToken) {
+		return claudeOAuthTokens{}, false
+	}
+	return tokens, true
+}
+
+// claudeConfig returns a copy of the configuration with the current claude.ai tokens,
+// renewed first if they have expired.
+func (p *Plugin) claudeConfig(config *Configuration) *Configuration {
+	return p.claudeConfigRenewing(config, 0)
+}
+
+// claudeConfigRenewing is claudeConfig renewing the tokens when they expire within margin.
+func (p *Plugin) claudeConfigRenewing(config *Configuration, margin time.Duration) *Configuration {
+	c := *config
+	tokens, ok := p.currentClaudeTokens(config)
+	if !ok {
+		return &c
+	}
+	c.ClaudeAccessToken, c.ClaudeRefreshToken = tokens.AccessToken, tokens.RefreshToken
+	if tokens.ExpiresAt > 0 && time.Until(time.Unix(tokens.ExpiresAt, 0)) < margin && c.ClaudeRefreshToken != "" {
+		if _, err := p.refreshClaudeToken(&c); err != nil {
+			p.API.LogWarn("Renewing the claude.ai token failed", "error", err.Error())
+		}
+	}
+	return &c
+}
+
+// renewClaudeTokens renews the claude.ai tokens of the organization and of every claude.ai
+// instance ahead of their expiry.
+func (p *Plugin) renewClaudeTokens() {
+	p.renewClaudeToken()
+	p.instancesLock.Lock()
+	var instances []*Plugin
+	for _, inst := range p.instances {
+		if inst.Type == "claude" && !inst.Disabled {
+			instances = append(instances, inst.plugin)
+		}
+	}
+	p.instancesLock.Unlock()
+	for _, ip := range instances {
+		ip.renewClaudeToken()
+	}
+}
+
+// renewClaudeToken renews the claude.ai access token in the background shortly before it
+// expires, so status requests neither wait for the renewal nor hit a 401 first. Tokens
+// whose expiry isn't known yet, configured ones never renewed, are renewed on a 401.
+func (p *Plugin) renewClaudeToken() {
+	config := p.getConfiguration()
+	if !config.ClaudeEnabled || config.DemoMode {
+		return
+	}
+	p.claudeConfigRenewing(config, claudeTokenRefreshMargin)
+}
+
+// refreshClaudeToken renews the access token with the refresh token, updates config with
+// both and stores them. Renewals are serialized, since each one spends the refresh token:
+// a fetch that waited for another one's renewal takes its tokens instead.
+func (p *Plugin) refreshClaudeToken(config *Configuration) (string, error) {
+	p.claudeTokenLock.Lock()
+	defer p.claudeTokenLock.Unlock()
+	stored, ok := p.currentClaudeTokens(config)
+	if ok && stored.RefreshToken != config.ClaudeRefreshToken {
+		config.ClaudeAccessToken, config.ClaudeRefreshToken = stored.AccessToken, stored.RefreshToken
+		return stored.AccessToken, nil
+	}
+	if !ok {
+		stored = claudeOAuthTokens{Source: credentialHash(config.ClaudeRefreshToken)}
+	}
+
+	form := neturl.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("client_id", claudeOAuthClientID)
+	form.Set("refresh_token", config.ClaudeRefreshToken)
+	req, _ := http.NewRequest("POST", claudeOAuthTokenURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+
+	resp, err := p.providerClient("claude", 15*time.Second).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("refresh HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var tokenResp claudeTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", err
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("empty access_token")
+	}
+
+	config.ClaudeAccessToken = tokenResp.AccessToken
+	if tokenResp.RefreshToken != "" {
+		config.ClaudeRefreshToken = tokenResp.RefreshToken
+	}
+	stored.AccessToken, stored.RefreshToken = config.ClaudeAccessToken, config.ClaudeRefreshToken
+	stored.ExpiresAt = tokenResp.expiresAt(time.Now())
+	return tokenResp.AccessToken, p.saveClaudeOAuthTokens(stored)
+}
+
+// pkceVerifier returns a random code verifier and its S256 challenge.
+func pkceVerifier() (verifier, challenge string) {
+	verifier = randomToken(32)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomToken(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// claudeAuthorizeURL is the claude.ai page the admin signs in and approves access on.
+func claudeAuthorizeURL(state, challenge string) string {
+	q := neturl.Values{}
+	q.Set("code", "true")
+	q.Set("client_id", claudeOAuthClientID)
+	q.Set("response_type", "code")
+	q.Set("redirect_uri", claudeOAuthRedirectURI)
+	q.Set("scope", claudeOAuthScopes)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+	q.Set("state", state)
+	return claudeOAuthAuthorizeURL + "?" + q.Encode()
+}
+
+// handleClaudeOAuthStart starts a sign-in and returns the URL to open.
+func (p *Plugin) handleClaudeOAuthStart(w http.ResponseWriter, r *http.Request, userID string) {
+	verifier, challenge := pkceVerifier()
+	state := randomToken(24)
+	data, _ := json.Marshal(claudeOAuthPending{Verifier: verifier, UserID: userID})
+	if appErr := p.API.KVSetWithExpiry(claudeOAuthPendingPrefix+state, data, int64(claudeOAuthPendingTTL/time.Second)); appErr != nil {
+		http.Error(w, `{"error": "store_failed", "message": "Could not start the sign-in"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"url": claudeAuthorizeURL(state, challenge)})
+}
+
+// handleClaudeOAuthCallback exchanges the authorization code for tokens and stores them.
+// The code is the one claude.ai shows after approval, "<code>#<state>", or the code and
+// state given separately.
+func (p *Plugin) handleClaudeOAuthCallback(w http.ResponseWriter, r *http.Request, userID string) {
+	var body struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16*1024)).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	code, state := strings.TrimSpace(body.Code), strings.TrimSpace(body.State)
+	if c, s, ok := strings.Cut(code, "#"); ok {
+		code, state = c, s
+	}
+	if code == "" || state == "" {
+		http.Error(w, `{"error": "invalid_code", "message": "Paste the whole code shown by claude.ai"}`, http.StatusBadRequest)
+		return
+	}
+
+	var pending claudeOAuthPending
+	key := claudeOAuthPendingPrefix + state
+	data, appErr := p.API.KVGet(key)
+	if appErr != nil || data == nil || json.Unmarshal(data, &pending) != nil || pending.UserID != userID {
+		http.Error(w, `{"error": "expired", "message": "The sign-in expired or was started by someone else. Start it again."}`, http.StatusBadRequest)
+		return
+	}
+	p.API.KVDelete(key)
+
+	tokens, err := p.exchangeClaudeCode(code, state, pending.Verifier)
+	if err != nil {
+		p.API.LogWarn("claude.ai sign-in failed", "error", err.Error())
+		resp, _ := json.Marshal(map[string]string{"error": "exchange_failed", "message": err.Error()})
+		http.Error(w, string(resp), http.StatusBadGateway)
+		return
+	}
+	tokens.ConnectedBy, tokens.ConnectedAt = userID, time.Now().Unix()
+	if err := p.saveClaudeOAuthTokens(tokens); err != nil {
+		http.Error(w, `{"error": "store_failed", "message": "Could not save the tokens"}`, http.StatusInternalServerError)
+		return
+	}
+	p.API.LogInfo("claude.ai account connected", "user_id", userID)
+	p.resetClaude()
+	p.handleClaudeOAuthStatus(w, r)
+}
+
+// exchangeClaudeCode trades the authorization code for the account's tokens.
+func (p *Plugin) exchangeClaudeCode(code, state, verifier string) (claudeOAuthTokens, error) {
+	payload, _ := json.Marshal(map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"state":         state,
+		"client_id":     claudeOAuthClientID,
+		"redirect_uri":  claudeOAuthRedirectURI,
+		"code_verifier": verifier,
+	})
+	req, _ := http.NewRequest("POST", claudeOAuthTokenURL, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+
+	resp, err := p.providerClient("claude", 15*time.Second).Do(req)
+	if err != nil {
+		return claudeOAuthTokens{}, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return claudeOAuthTokens{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return claudeOAuthTokens{}, fmt.Errorf("token HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var tokenResp claudeTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return claudeOAuthTokens{}, err
+	}
+	if tokenResp.AccessToken == "" {
+		return claudeOAuthTokens{}, fmt.Errorf("empty access_token")
+	}
+	return claudeOAuthTokens{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		ExpiresAt:    tokenResp.expiresAt(time.Now()),
+	}, nil
+}
+
+// handleClaudeOAuthStatus tells whether an account is connected, by whom and when.
+func (p *Plugin) handleClaudeOAuthStatus(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]any{"connected": false}
+	if tokens, ok := p.getClaudeOAuthTokens(); ok && tokens.signedIn() {
+		resp["connected"] = true
+		resp["connectedAt"] = tokens.ConnectedAt
+		if tokens.ExpiresAt > 0 {
+			resp["expiresAt"] = tokens.ExpiresAt
+		}
+		if user, appErr := p.API.GetUser(tokens.ConnectedBy); appErr == nil {
+			resp["connectedBy"] = user.Username
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleClaudeOAuthDisconnect forgets the signed-in account, so the configured tokens, if
+// any, are used again.
+func (p *Plugin) handleClaudeOAuthDisconnect(w http.ResponseWriter, r *http.Request, userID string) {
+	if appErr := p.API.KVDelete(claudeOAuthTokensKey); appErr != nil {
+		http.Error(w, `{"error": "store_failed", "message": "Could not remove the tokens"}`, http.StatusInternalServerError)
+		return
+	}
+	p.API.LogInfo("claude.ai account disconnected", "user_id", userID)
+	p.resetClaude()
+	p.handleClaudeOAuthStatus(w, r)
+}
+
+// resetClaude drops claude.ai's cached result, backoff, circuit breaker and last known good
+// status, so the card shows the newly connected account at once.
+func (p *Plugin) resetClaude() {
+	ids := map[string]bool{"claude": true}
+	p.invalidateProviders(ids, nil)
+	p.resetBreakers(ids)
+	p.forgetGood(ids)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== OpenAI Codex (ChatGPT plan usage via OAuth) =====
+
+// codexClientID is the OAuth client of the Codex CLI, whose tokens the plugin reuses.
+const codexClientID = "app_EMoamEEZ73f0CkXaXp7hrann"
+
+// CodexUsageInfo is the ChatGPT plan's usage of its Codex rate limit windows, which Codex CLI,
+// the IDE extension and Codex cloud tasks share. Like claude.ai, the plan has a short window
+// (5 hours) and a weekly one, each reported as a percentage.
+type CodexUsageInfo struct {
+	Plan                 string   `json:"plan,omitempty"` // "plus", "pro", "team", ...
+	PrimaryUsed          float64  `json:"primaryUsed"`
+	PrimaryWindowMinutes int      `json:"primaryWindowMinutes,omitempty"`
+	PrimaryReset         string   `json:"primaryReset,omitempty"`
+	WeeklyUsed           float64  `json:"weeklyUsed"`
+	WeeklyWindowMinutes  int      `json:"weeklyWindowMinutes,omitempty"`
+	WeeklyReset          string   `json:"weeklyReset,omitempty"`
+	LimitReached         bool     `json:"limitReached,omitempty"`
+	Credits              *float64 `json:"credits,omitempty"` // purchased credits that run on after a window is used up
+	HasData              bool     `json:"hasData"`
+	Constrained          []string `json:"constrained,omitempty"` // windows driving the status: "primary", "weekly"
+}
+
+func (c CodexUsageInfo) summary(locale string) string {
+	if !c.HasData {
+		return translate(locale, "summary.no_data")
+	}
+	text := translate(locale, "summary.codex", c.PrimaryUsed, c.WeeklyUsed)
+	if len(c.Constrained) > 0 {
+		var windows []string
+		for _, w := range c.Constrained {
+			windows = append(windows, translate(locale, "window.codex_"+w))
+		}
+		text = translate(locale, "summary.constrained", text, strings.Join(windows, ", "))
+	}
+	return text
+}
+
+func (c CodexUsageInfo) usagePercent() (float64, bool) {
+	if c.HasData {
+		return max(c.PrimaryUsed, c.WeeklyUsed), true
+	}
+	return 0, false
+}
+
+func (c CodexUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "codex_primary", At: parseTime(c.PrimaryReset)}, {Kind: "codex_weekly", At: parseTime(c.WeeklyReset)}}
+}
+
+// codexStatus derives the status from both windows and records which are responsible for it.
+// A reached limit is an error even when credits keep Codex usable, since they cost extra.
+func codexStatus(info *CodexUsageInfo, config *Configuration) string {
+	warn := config.warningPercent(80)
+	status := "ok"
+	info.Constrained = nil
+	for _, w := range []struct {
+		id   string
+		used float64
+	}{{"primary", info.PrimaryUsed}, {"weekly", info.WeeklyUsed}} {
+		windowStatus := "ok"
+		if w.used >= 100 {
+			windowStatus = "error"
+		} else if w.used > warn {
+			windowStatus = "warning"
+		}
+		switch {
+		case windowStatus == "ok":
+		case statusSeverity(windowStatus) > statusSeverity(status):
+			status = windowStatus
+			info.Constrained = []string{w.id}
+		case windowStatus == status:
+			info.Constrained = append(info.Constrained, w.id)
+		}
+	}
+	if info.LimitReached && status != "error" {
+		status = "error"
+	}
+	return status
+}
+
+// codexUsageResponse is GET /backend-api/wham/usage. Windows are null when the plan doesn't
+// have them.
+type codexUsageResponse struct {
+	PlanType  string `json:"plan_type"`
+	RateLimit *struct {
+		Allowed         bool              `json:"allowed"`
+		LimitReached    bool              `json:"limit_reached"`
+		PrimaryWindow   *codexUsageWindow `json:"primary_window"`
+		SecondaryWindow *codexUsageWindow `json:"secondary_window"`
+	} `json:"rate_limit" schema:"required"`
+	Credits *struct {
+		HasCredits bool       `json:"has_credits"`
+		Unlimited  bool       `json:"unlimited"`
+		Balance    *flexFloat `json:"balance"`
+	} `json:"credits"`
+}
+
+type codexUsageWindow struct {
+	UsedPercent        flexFloat `json:"used_percent"`
+	LimitWindowSeconds int       `json:"limit_window_seconds"`
+	ResetAt            int64     `json:"reset_at"`
+}
+
+func (w codexUsageWindow) resetTime() string {
+	if w.ResetAt <= 0 {
+		return ""
+	}
+	return time.Unix(w.ResetAt, 0).UTC().Format(time.RFC3339)
+}
+
+func (p *Plugin) getCodexStatus(config *Configuration) ServiceStatus {
+	const id, name = "codex", "OpenAI Codex"
+	if config.CodexAccessToken == "" {
+		return errorStatus(id, name, "error.codex_token_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newCodexUsageRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+
+	// Access tokens expire after a few days; the refresh token renews them
+	if resp.StatusCode == 401 && config.CodexRefreshToken != "" {
+		if refreshErr := p.refreshCodexToken(config); refreshErr == nil {
+			if resp, err = client.Do(newCodexUsageRequest(config)); err != nil {
+				return errorStatus(id, name, "error.api", err.Error())
+			}
+			body, err = p.readResponse(resp)
+			resp.Body.Close()
+			if err != nil {
+				return errorStatus(id, name, "error.api", err.Error())
+			}
+		}
+	}
+
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var raw codexUsageResponse
+	drift, err := decodeResponse(body, &raw)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info := CodexUsageInfo{Plan: raw.PlanType}
+	if rl := raw.RateLimit; rl != nil {
+		info.LimitReached = rl.LimitReached || !rl.Allowed
+		if w := rl.PrimaryWindow; w != nil {
+			info.PrimaryUsed = float64(w.UsedPercent)
+			info.PrimaryWindowMinutes = w.LimitWindowSeconds / 60
+			info.PrimaryReset = w.resetTime()
+			info.HasData = true
+		}
+		if w := rl.SecondaryWindow; w != nil {
+			info.WeeklyUsed = float64(w.UsedPercent)
+			info.WeeklyWindowMinutes = w.LimitWindowSeconds / 60
+			info.WeeklyReset = w.resetTime()
+			info.HasData = true
+		}
+	}
+	if c := raw.Credits; c != nil && c.HasCredits && !c.Unlimited && c.Balance != nil {
+		info.Credits = floatPtr(float64(*c.Balance))
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: codexStatus(&info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+func newCodexUsageRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://chatgpt.com/backend-api/wham/usage", nil)
+	req.Header.Set("Authorization", "Bearer "+config.CodexAccessToken)
+	if config.CodexAccountId != "" {
+		req.Header.Set("ChatGPT-Account-Id", config.CodexAccountId)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// refreshCodexToken renews the access token with the refresh token and saves both to the
+// config, since OpenAI rotates refresh tokens on use.
+func (p *Plugin) refreshCodexToken(config *Configuration) error {
+	payload, _ := json.Marshal(map[string]string{
+		"client_id":     codexClientID,
+		"grant_type":    "refresh_token",
+		"refresh_token": config.CodexRefreshToken,
+		"scope":         "openid profile email",
+	})
+	req, _ := http.NewRequest("POST", "https://auth.openai.com/oauth/token", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+
+	resp, err := p.providerClient("codex", 15*time.Second).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("refresh HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return err
+	}
+	if tokenResp.AccessToken == "" {
+		return fmt.Errorf("empty access_token")
+	}
+
+	config.CodexAccessToken = tokenResp.AccessToken
+	if tokenResp.RefreshToken != "" {
+		config.CodexRefreshToken = tokenResp.RefreshToken
+	}
+	return p.saveConfiguration(config)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Remote collectors =====
+
+const (
+	collectorReportsKey = "collector_reports"
+	// collectorTokenHeader carries the shared collector token; collectors have no
+	// Mattermost session
+	collectorTokenHeader = "X-AI-Limits-Collector-Token"
+	// collectorRetention is how long a collector that stopped reporting stays on the dashboard
+	collectorRetention   = 7 * 24 * time.Hour
+	collectorMaxStatuses = 200
+)
+
+// collectorReport is the last report a collector pushed.
+type collectorReport struct {
+	Label      string         `json:"label"`
+	ReceivedAt int64          `json:"receivedAt"`
+	Interval   int            `json:"interval"` // seconds between the collector's reports
+	Services   []remoteStatus `json:"services"`
+}
+
+func (r collectorReport) label(id string) string {
+	if label := strings.TrimSpace(r.Label); label != "" {
+		return label
+	}
+	return id
+}
+
+// staleAfter is how long after a report the collector is considered gone: three missed
+// reports, and at least 15 minutes.
+func (r collectorReport) staleAfter() time.Duration {
+	return max(3*time.Duration(r.Interval)*time.Second, 15*time.Minute)
+}
+
+func (p *Plugin) loadCollectorReports() map[string]collectorReport {
+	reports := map[string]collectorReport{}
+	if data, appErr := p.API.KVGet(collectorReportsKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &reports)
+	}
+	return reports
+}
+
+// handleCollectorReport serves POST /api/v1/collector/report: the normalized statuses a
+// collector fetched inside a network the server can't reach the providers from.
+func (p *Plugin) handleCollectorReport(w http.ResponseWriter, r *http.Request) {
+	config := p.getConfiguration()
+	token := r.Header.Get(collectorTokenHeader)
+	if !config.CollectorsEnabled || config.CollectorToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(config.CollectorToken)) != 1 {
+		http.Error(w, `{"error": "unauthorized", "message": "Unknown collector token"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		ID string `json:"id"`
+		collectorReport
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024*1024)).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	var err error
+	switch {
+	case !customIDPattern.MatchString(body.ID):
+		err = fmt.Errorf("id must be lowercase letters, digits, - or _")
+	case len(body.Label) > 100:
+		err = fmt.Errorf("label must be at most 100 characters")
+	case len(body.Services) > collectorMaxStatuses:
+		err = fmt.Errorf("at most %d statuses per report", collectorMaxStatuses)
+	}
+	if err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "invalid_report", "message": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+
+	report := body.collectorReport
+	report.ReceivedAt = time.Now().Unix()
+	p.historyLock.Lock()
+	reports := p.loadCollectorReports()
+	reports[body.ID] = report
+	data, _ := json.Marshal(reports)
+	appErr := p.API.KVSet(collectorReportsKey, data)
+	p.historyLock.Unlock()
+	if appErr != nil {
+		http.Error(w, `{"error": "kv_error", "message": "Failed to save the report"}`, http.StatusInternalServerError)
+		return
+	}
+	// Show the new report on the next refresh rather than after the cache expires
+	p.cacheLock.Lock()
+	delete(p.cache, "collector")
+	p.cacheLock.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"accepted": len(report.Services)})
+}
+
+// getCollectorStatuses returns the statuses last reported by each collector, or an error
+// card for a collector that stopped reporting.
+func (p *Plugin) getCollectorStatuses(config *Configuration) []ServiceStatus {
+	if cached, ok := p.getCached("collector"); ok {
+		return append([]ServiceStatus(nil), cached.([]ServiceStatus)...)
+	}
+
+	reports := p.loadCollectorReports()
+	ids := make([]string, 0, len(reports))
+	for id := range reports {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	now := time.Now()
+	services := []ServiceStatus{}
+	for _, id := range ids {
+		report := reports[id]
+		receivedAt := time.Unix(report.ReceivedAt, 0)
+		age := now.Sub(receivedAt)
+		switch {
+		case age > collectorRetention:
+			continue
+		case age > report.staleAfter():
+			services = append(services, errorStatus("collector:@"+id, report.label(id), "error.collector_stale", receivedAt.UTC().Format(time.RFC3339)))
+		default:
+			services = append(services, importStatuses(id, report.label(id), report.Services)...)
+		}
+	}
+	p.setCache("collector", services)
+	return append([]ServiceStatus(nil), services...)
+}
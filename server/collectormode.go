@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// ===== Standalone collector mode =====
+
+// collectorAPI stands in for the Mattermost API when the plugin binary runs as a collector:
+// logs go to stderr and the KV store lives in memory. A provider that needs anything else
+// fails on its own card without affecting the others.
+type collectorAPI struct {
+	plugin.API
+
+	kvLock sync.Mutex
+	kv     map[string][]byte
+}
+
+func (a *collectorAPI) log(level, msg string, keyValuePairs ...any) {
+	var b strings.Builder
+	b.WriteString(level + " " + msg)
+	for i := 0; i+1 < len(keyValuePairs); i += 2 {
+		fmt.Fprintf(&b, " %v=%q", keyValuePairs[i], fmt.Sprint(keyValuePairs[i+1]))
+	}
+	log.Println(b.String())
+}
+
+func (a *collectorAPI) LogDebug(msg string, keyValuePairs ...any) {}
+func (a *collectorAPI) LogInfo(msg string, keyValuePairs ...any) {
+	a.log("info", msg, keyValuePairs...)
+}
+func (a *collectorAPI) LogWarn(msg string, keyValuePairs ...any) {
+	a.log("warn", msg, keyValuePairs...)
+}
+func (a *collectorAPI) LogError(msg string, keyValuePairs ...any) {
+	a.log("error", msg, keyValuePairs...)
+}
+
+func (a *collectorAPI) KVGet(key string) ([]byte, *model.AppError) {
+	a.kvLock.Lock()
+	defer a.kvLock.Unlock()
+	return a.kv[key], nil
+}
+
+func (a *collectorAPI) KVSet(key string, value []byte) *model.AppError {
+	a.kvLock.Lock()
+	defer a.kvLock.Unlock()
+	a.kv[key] = value
+	return nil
+}
+
+func (a *collectorAPI) GetConfig() *model.Config {
+	config := &model.Config{}
+	config.SetDefaults()
+	return config
+}
+
+// GetUsers finds no admins, so there's no one to notify.
+func (a *collectorAPI) GetUsers(options *model.UserGetOptions) ([]*model.User, *model.AppError) {
+	return nil, nil
+}
+
+// collectorOptions are the command line options of the collector.
+type collectorOptions struct {
+	config   string
+	url      string
+	token    string
+	id       string
+	label    string
+	interval time.Duration
+	once     bool
+	dryRun   bool
+}
+
+// runCollector runs the binary as a standalone collector: it fetches the providers
+// configured in a settings file with the plugin's own code and pushes the statuses to the
+// plugin's collector endpoint, for networks where only the collector may reach the
+// providers. It returns the exit code.
+func runCollector(args []string) int {
+	var opts collectorOptions
+	flags := flag.NewFlagSet("collect", flag.ContinueOnError)
+	flags.StringVar(&opts.config, "config", "collector.json", "plugin settings file, with the keys of the plugin's config.json entry")
+	flags.StringVar(&opts.url, "url", os.Getenv("MM_URL"), "Mattermost URL (default $MM_URL)")
+	flags.StringVar(&opts.token, "token", os.Getenv("AILIMITS_COLLECTOR_TOKEN"), "collector token set in the plugin settings (default $AILIMITS_COLLECTOR_TOKEN)")
+	flags.StringVar(&opts.id, "id", "", "collector ID: lowercase letters, digits, - or _")
+	flags.StringVar(&opts.label, "label", "", "name shown on the collector's cards (default: the ID)")
+	flags.DurationVar(&opts.interval, "interval", 5*time.Minute, "time between reports")
+	flags.BoolVar(&opts.once, "once", false, "report once and exit")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "print the report instead of sending it")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	switch {
+	case !customIDPattern.MatchString(opts.id):
+		log.Println("-id must be lowercase letters, digits, - or _")
+		return 2
+	case opts.interval < time.Minute:
+		log.Println("-interval must be at least 1m")
+		return 2
+	case !opts.dryRun && (opts.url == "" || opts.token == ""):
+		log.Println("the Mattermost URL and the collector token are required")
+		return 2
+	}
+
+	p, err := newCollectorPlugin(opts.config)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		p.bg.stop(shutdownTimeout)
+	}()
+
+	for {
+		if err := p.pushCollectorReport(opts); err != nil {
+			log.Println("error", err)
+			if opts.once {
+				return 1
+			}
+		}
+		if opts.once {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-time.After(opts.interval):
+		}
+	}
+}
+
+// newCollectorPlugin sets up the plugin to fetch the providers configured in the file.
+func newCollectorPlugin(path string) (*Plugin, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the settings: %w", err)
+	}
+	config := &Configuration{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid settings in %s: %w", path, err)
+	}
+	config, errs := resolveSecrets(config)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid settings in %s: %w", path, errs[0])
+	}
+	// A collector reports its own providers only
+	config.FederationEnabled, config.CollectorsEnabled = false, false
+
+	p := &Plugin{
+		configuration: config,
+		cache:         make(map[string]*CacheEntry),
+		states:        make(map[string]*providerState),
+		bg:            newBackground(),
+		defaultUA:     "Mattermost-AI-Limits-Monitor-Collector",
+	}
+	p.API = &collectorAPI{kv: map[string][]byte{}}
+	instances, err := p.parseProviderInstances(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider instances: %w", err)
+	}
+	p.instances = instances
+	return p, nil
+}
+
+// pushCollectorReport fetches every provider afresh and sends the statuses to the plugin.
+func (p *Plugin) pushCollectorReport(opts collectorOptions) error {
+	p.cacheLock.Lock()
+	p.cache = make(map[string]*CacheEntry)
+	p.cacheLock.Unlock()
+	p.clearInstanceCaches()
+
+	config := p.getConfiguration()
+	services := []ServiceStatus{}
+	for _, s := range p.gatherStatuses(false) {
+		if s.Enabled {
+			services = append(services, s)
+		}
+	}
+	uc := userContext{Locale: "en", Location: time.UTC, Units: config.defaultUnits()}
+	localizeStatuses(services, uc.Locale)
+	addResetViews(services, uc, time.Now())
+	addUsageMetrics(services)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":       opts.id,
+		"label":    opts.label,
+		"interval": int(opts.interval.Seconds()),
+		"services": services,
+	})
+	if opts.dryRun {
+		fmt.Println(string(body))
+		return nil
+	}
+
+	req, _ := http.NewRequest("POST", strings.TrimRight(opts.url, "/")+"/plugins/"+pluginID+"/api/v1/collector/report", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(collectorTokenHeader, opts.token)
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, msg)
+	}
+	log.Printf("info Reported %d statuses", len(services))
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// ===== Slash command =====
+
+const commandTrigger = "ailimits"
+
+func (p *Plugin) registerCommands() error {
+	autocomplete := model.NewAutocompleteData(commandTrigger, "[command]", "Show AI service limits")
+	autocomplete.AddCommand(model.NewAutocompleteData("status", "", "Show the current status of all AI services"))
+	setup := model.NewAutocompleteData("setup", "[provider]", "Configure providers and alerts (system admins only)")
+	setup.RoleID = model.SystemAdminRoleId
+	autocomplete.AddCommand(setup)
+	backfill := model.NewAutocompleteData("backfill", "[months]", "Import previous months of OpenAI cost history (system admins only)")
+	backfill.RoleID = model.SystemAdminRoleId
+	autocomplete.AddCommand(backfill)
+	addProvider := model.NewAutocompleteData("add-provider", "", "Add a custom provider with a test fetch (system admins only)")
+	addProvider.RoleID = model.SystemAdminRoleId
+	autocomplete.AddCommand(addProvider)
+	config := model.NewAutocompleteData("config", "[set|unset]", "Show or change thresholds, cache TTLs and budgets")
+	config.AddCommand(model.NewAutocompleteData("set", "<name> <value>", "Change a setting, e.g. claude.warn 70"))
+	config.AddCommand(model.NewAutocompleteData("unset", "<name>", "Reset a setting to its default"))
+	autocomplete.AddCommand(config)
+
+	return p.API.RegisterCommand(&model.Command{
+		Trigger:          commandTrigger,
+		DisplayName:      "AI Limits",
+		Description:      "Show AI service usage and limits",
+		AutoComplete:     true,
+		AutoCompleteDesc: "Show AI service limits",
+		AutoCompleteHint: "[command]",
+		AutocompleteData: autocomplete,
+	})
+}
+
+func (p *Plugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+	uc := p.getUserContext(args.UserId)
+	fields := strings.Fields(args.Command)
+	subcommand := "status"
+	if len(fields) > 1 {
+		subcommand = fields[1]
+	}
+
+	// Setup, backfill and add-provider check for the system admin permission instead of the plugin allowlists
+	if subcommand == "setup" {
+		stepID := ""
+		if len(fields) > 2 {
+			stepID = strings.ToLower(fields[2])
+		}
+		return p.executeSetupCommand(args, uc, stepID), nil
+	}
+	if subcommand == "backfill" {
+		monthsArg := ""
+		if len(fields) > 2 {
+			monthsArg = fields[2]
+		}
+		return p.executeBackfillCommand(args, uc, monthsArg), nil
+	}
+	if subcommand == "add-provider" {
+		return p.executeAddProviderCommand(args, uc), nil
+	}
+	// Config checks for system admins and the tuning managers
+	if subcommand == "config" {
+		return ephemeralResponse(p.executeConfigCommand(args.UserId, uc, fields[2:])), nil
+	}
+
+	if !p.checkAccess(args.UserId) {
+		return ephemeralResponse(translate(uc.Locale, "command.access_denied")), nil
+	}
+	p.markActive()
+
+	switch subcommand {
+	case "status":
+		return ephemeralResponse(p.statusCommandText(uc)), nil
+	default:
+		return ephemeralResponse(translate(uc.Locale, "command.unknown")), nil
+	}
+}
+
+// statusCommandText renders all enabled services with resets in the user's timezone.
+func (p *Plugin) statusCommandText(uc userContext) string {
+	if text := p.statusText(uc, nil); text != "" {
+		return text
+	}
+	return translate(uc.Locale, "command.no_services")
+}
+
+// statusText renders the enabled services that match, or all of them when match is nil. It
+// is empty when none match.
+func (p *Plugin) statusText(uc userContext, match func(ServiceStatus) bool) string {
+	services := p.collectStatuses()
+	p.trackStatusChanges(services)
+	localizeStatuses(services, uc.Locale)
+
+	now := time.Now()
+	var b strings.Builder
+	b.WriteString(translate(uc.Locale, "command.status_header") + "\n")
+	count := 0
+	for _, s := range services {
+		if !s.Enabled || match != nil && !match(s) {
+			continue
+		}
+		count++
+		b.WriteString(fmt.Sprintf("- %s **%s**: %s\n", statusEmoji(s.Status), s.Name, summarizeInUnits(s, uc.Units, uc.Locale)))
+		for _, r := range serviceResets(s, now) {
+			view := newResetView(r, uc, now)
+			b.WriteString("  - " + translate(uc.Locale, "command.resets_in", view.Label, view.Relative, view.Formatted) + "\n")
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	return b.String()
+}
+
+func statusEmoji(status string) string {
+	switch status {
+	case "ok":
+		return "🟢"
+	case "warning":
+		return "🟡"
+	case "error":
+		return "🔴"
+	case "reauth":
+		return "🔑"
+	case "unreachable":
+		return "⛔"
+	}
+	return "⚪"
+}
+
+func ephemeralResponse(text string) *model.CommandResponse {
+	return &model.CommandResponse{
+		ResponseType: model.CommandResponseTypeEphemeral,
+		Text:         text,
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ===== Compact status =====
+
+// CompactStatus is one provider's entry in GET /api/v1/status/compact.
+type CompactStatus struct {
+	Status   string   `json:"status"`
+	Percent  *float64 `json:"percent,omitempty"`
+	ResetsAt string   `json:"resetsAt,omitempty"` // next reset, RFC 3339 UTC
+}
+
+// compactMaxAge is how long clients may reuse a compact response. Provider data
+// is cached for several minutes on the server anyway.
+const compactMaxAge = 60
+
+// handleGetCompactStatus returns a tiny status map for badges and frequent polling,
+// with an ETag so unchanged responses cost a 304.
+func (p *Plugin) handleGetCompactStatus(w http.ResponseWriter, r *http.Request) {
+	services := p.collectStatuses()
+	p.trackStatusChanges(services)
+
+	now := time.Now()
+	result := map[string]CompactStatus{}
+	for _, s := range services {
+		if !s.Enabled {
+			continue
+		}
+		entry := CompactStatus{Status: s.Status}
+		if pct, ok := usagePercent(s); ok {
+			entry.Percent = floatPtr(math.Round(pct*10) / 10)
+		}
+		if resets := serviceResets(s, now); len(resets) > 0 {
+			entry.ResetsAt = resets[0].At.UTC().Format(time.RFC3339)
+		}
+		result[s.ID] = entry
+	}
+
+	body, _ := json.Marshal(result)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(compactMaxAge))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
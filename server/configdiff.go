@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// ===== Selective cache invalidation =====
+
+// fetchIndependentKeys are settings that don't change what providers return: access,
+// display and integration settings applied after the fetch. Provider instances are
+// compared separately.
+var fetchIndependentKeys = map[string]bool{
+	"alloweduserids": true, "allowedteamids": true, "allowedroles": true, "allowedchannelids": true, "restrictchanges": true, "alertchannelid": true, "alertgroupingwindow": true, "costreportchannelid": true, "costallocation": true, "budgets": true, "calendartoken": true, "metricstoken": true,
+	"providernames": true, "providerorder": true, "providerinstances": true, "displayunits": true, "idleafterhours": true,
+	"credentialmaxagedays": true, "credentialexpirations": true, "credentialexpirywarningdays": true,
+	"overallstatusweights": true, "overallerrorpercent": true, "overallwarningpercent": true,
+	"mentionsenabled": true, "refreshemoji": true, "configmanagerids": true, "cachettl": true, "providercachettls": true, "circuitbreakerfailures": true, "circuitbreakercooldownminutes": true, "personalcredentialsenabled": true,
+}
+
+// fetchIndependentPrefixes are integrations whose settings don't affect provider data.
+var fetchIndependentPrefixes = []string{"boards", "digest", "jira", "webhook", "grafana", "header", "statuspost"}
+
+// changedKeys returns the configuration keys whose values differ.
+func changedKeys(old, updated *Configuration) []string {
+	var before, after map[string]any
+	data, _ := json.Marshal(old)
+	json.Unmarshal(data, &before)
+	data, _ = json.Marshal(updated)
+	json.Unmarshal(data, &after)
+
+	var keys []string
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// affectedProviders maps changed settings to the providers whose cached results they make
+// stale. all is true when a setting every provider uses changed, such as the warning
+// threshold or the exchange rates.
+func affectedProviders(keys []string) (ids map[string]bool, all bool) {
+	ids = map[string]bool{}
+	for _, key := range keys {
+		if fetchIndependent(key) {
+			continue
+		}
+		id := providerForKey(key)
+		if id == "" {
+			return nil, true
+		}
+		ids[id] = true
+	}
+	return ids, false
+}
+
+func fetchIndependent(key string) bool {
+	if fetchIndependentKeys[key] {
+		return true
+	}
+	for _, prefix := range fetchIndependentPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// providerForKey returns the provider a setting belongs to, by its longest matching prefix,
+// so "claudecodeenabled" belongs to claudecode rather than claude.
+func providerForKey(key string) string {
+	match := ""
+	for _, prov := range providers {
+		if strings.HasPrefix(key, prov.ID) && len(prov.ID) > len(match) {
+			match = prov.ID
+		}
+	}
+	return match
+}
+
+// cacheKeyProvider returns the provider a cache or backoff key belongs to: "openai",
+// "openai:org-abc" and "openai_orgs" all belong to openai.
+func cacheKeyProvider(key string) string {
+	if i := strings.IndexAny(key, ":_"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// invalidateProviders drops the cached results and error backoff of the given providers,
+// except open circuit breakers, and forgets the last status of those that were switched off, so switching one back on
+// later doesn't report a transition from a stale status.
+func (p *Plugin) invalidateProviders(ids map[string]bool, disabled map[string]bool) {
+	p.cacheLock.Lock()
+	for key := range p.cache {
+		if ids[cacheKeyProvider(key)] {
+			delete(p.cache, key)
+		}
+	}
+	p.cacheLock.Unlock()
+
+	p.backoffLock.Lock()
+	for key, b := range p.backoff {
+		if ids[key] && b.openedAt.IsZero() {
+			delete(p.backoff, key)
+		}
+	}
+	p.backoffLock.Unlock()
+
+	p.stateLock.Lock()
+	for key := range p.states {
+		if disabled[cacheKeyProvider(key)] {
+			delete(p.states, key)
+		}
+	}
+	p.stateLock.Unlock()
+}
+
+// applyConfigurationChange invalidates what the change between two configurations affects.
+func (p *Plugin) applyConfigurationChange(old, updated *Configuration) {
+	ids, all := affectedProviders(changedKeys(old, updated))
+	if all {
+		p.cacheLock.Lock()
+		p.cache = make(map[string]*CacheEntry)
+		p.cacheLock.Unlock()
+		p.resetBreakers(nil)
+		p.forgetGood(nil)
+		return
+	}
+	p.forgetGood(ids)
+	disabled := map[string]bool{}
+	for _, prov := range providers {
+		if prov.Enabled(old) && !prov.Enabled(updated) {
+			disabled[prov.ID] = true
+		}
+	}
+	p.invalidateProviders(ids, disabled)
+	p.resetBreakers(ids)
+}
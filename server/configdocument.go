@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ===== Configuration document =====
+
+// configDocumentKey is where the uploaded configuration document is kept.
+const configDocumentKey = "config_document"
+
+// maxConfigDocumentSize bounds an uploaded configuration document.
+const maxConfigDocumentSize = 1024 * 1024
+
+// ConfigDocument is a declarative configuration, uploaded as YAML or JSON and applied over
+// the System Console settings. Provider settings use the provider's System Console keys
+// with or without the provider prefix, like provider instances; a provider's "enabled"
+// (true by default), "warning" and "ttl" set its switch, warning threshold and cache TTL.
+// Once providers are listed, the providers left out are disabled. settings sets any other
+// configuration key. Credentials must be env: or file: references, so the document never
+// holds a secret.
+type ConfigDocument struct {
+	Providers  map[string]map[string]any `json:"providers"`
+	Thresholds struct {
+		Warning        *float64 `json:"warning"`
+		OverallError   *float64 `json:"overallError"`
+		OverallWarning *float64 `json:"overallWarning"`
+	} `json:"thresholds"`
+	Alerts struct {
+		Channel        *string  `json:"channel"`
+		Recipients     []string `json:"recipients"`
+		GroupingWindow *string  `json:"groupingWindow"`
+	} `json:"alerts"`
+	Budgets  []budgetDefinition `json:"budgets"`
+	Settings map[string]any     `json:"settings"`
+}
+
+// storedConfigDocument is the document as uploaded, so comments and layout survive a
+// download.
+type storedConfigDocument struct {
+	Source    string `json:"source"`
+	UpdatedAt int64  `json:"updatedAt"`
+	UpdatedBy string `json:"updatedBy"`
+}
+
+// appliedDocument is the document the configuration was built with.
+type appliedDocument struct {
+	hash      string
+	overrides map[string]documentOverride
+}
+
+// documentOverride is a setting the document changed, and its System Console value.
+type documentOverride struct {
+	value interface{}
+	base  interface{}
+}
+
+// parseConfigDocument parses a JSON or YAML document. Unknown fields are rejected, so a
+// misspelt section isn't silently ignored.
+func parseConfigDocument(source []byte) (*ConfigDocument, error) {
+	data := bytes.TrimSpace(source)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		var parsed interface{}
+		if err := yaml.Unmarshal(source, &parsed); err != nil {
+			return nil, fmt.Errorf("not a YAML or JSON document: %v", err)
+		}
+		converted, err := json.Marshal(jsonCompatible(parsed))
+		if err != nil {
+			return nil, fmt.Errorf("not a YAML or JSON document: %v", err)
+		}
+		data = converted
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var doc ConfigDocument
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid document: %v", err)
+	}
+	return &doc, nil
+}
+
+// jsonCompatible converts the maps yaml.v2 decodes, keyed by interface{}, to maps JSON
+// can encode.
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonCompatible(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonCompatible(value)
+		}
+	}
+	return v
+}
+
+// documentValue checks a setting of the document and converts numbers to the text
+// System Console stores them as.
+func documentValue(key string, value any, known map[string]bool) (any, error) {
+	if !known[key] {
+		return nil, fmt.Errorf("unknown setting %q", key)
+	}
+	switch v := value.(type) {
+	case string:
+		if v != "" && isCredentialSetting(key) && !strings.HasPrefix(v, "env:") && !strings.HasPrefix(v, "file:") {
+			return nil, fmt.Errorf("setting %q: credentials must be env: or file: references", key)
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return value, nil
+}
+
+// settings returns the configuration settings the document sets, given the System
+// Console ones.
+func (doc *ConfigDocument) settings(base map[string]interface{}) (map[string]interface{}, error) {
+	known := configurationKeys()
+	settings := map[string]interface{}{}
+	stringSetting := func(key string) string {
+		if v, ok := settings[key].(string); ok {
+			return v
+		}
+		s, _ := base[key].(string)
+		return s
+	}
+
+	for key, value := range doc.Settings {
+		key = strings.ToLower(key)
+		v, err := documentValue(key, value, known)
+		if err != nil {
+			return nil, err
+		}
+		settings[key] = v
+	}
+
+	if doc.Providers != nil {
+		for _, step := range setupSteps {
+			if step.EnabledKey != "" {
+				settings[step.EnabledKey] = false
+			}
+		}
+	}
+	ids := make([]string, 0, len(doc.Providers))
+	for id := range doc.Providers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		step, ok := findSetupStep(id)
+		if !ok || step.EnabledKey == "" {
+			return nil, fmt.Errorf("provider %q: unknown provider", id)
+		}
+		prefix := strings.TrimSuffix(step.EnabledKey, "enabled")
+		settings[step.EnabledKey] = true
+		for key, value := range doc.Providers[id] {
+			key = strings.ToLower(key)
+			switch key {
+			case "enabled":
+				enabled, ok := value.(bool)
+				if !ok {
+					return nil, fmt.Errorf("provider %q: enabled must be true or false", id)
+				}
+				settings[step.EnabledKey] = enabled
+				continue
+			case "warning":
+				w, ok := value.(float64)
+				if !ok || w <= 0 || w > 100 {
+					return nil, fmt.Errorf("provider %q: warning must be a percentage", id)
+				}
+				settings["providerwarningthresholds"] = setProviderSetting(stringSetting("providerwarningthresholds"), id, strconv.FormatFloat(w, 'f', -1, 64))
+				continue
+			case "ttl":
+				ttl, _ := value.(string)
+				if _, ok := parseCacheTTL(ttl); !ok {
+					return nil, fmt.Errorf("provider %q: ttl must be a duration of at least 1m", id)
+				}
+				settings["providercachettls"] = setProviderSetting(stringSetting("providercachettls"), id, ttl)
+				continue
+			}
+			if !strings.HasPrefix(key, prefix) {
+				key = prefix + key
+			}
+			v, err := documentValue(key, value, known)
+			if err != nil {
+				return nil, fmt.Errorf("provider %q: %v", id, err)
+			}
+			settings[key] = v
+		}
+	}
+
+	percent := func(key string, value *float64) error {
+		if value == nil {
+			return nil
+		}
+		if *value <= 0 || *value > 100 {
+			return fmt.Errorf("thresholds: %s must be a percentage", key)
+		}
+		settings[key] = strconv.FormatFloat(*value, 'f', -1, 64)
+		return nil
+	}
+	if err := percent("warningthreshold", doc.Thresholds.Warning); err != nil {
+		return nil, err
+	}
+	if err := percent("overallerrorpercent", doc.Thresholds.OverallError); err != nil {
+		return nil, err
+	}
+	if err := percent("overallwarningpercent", doc.Thresholds.OverallWarning); err != nil {
+		return nil, err
+	}
+
+	if doc.Alerts.Channel != nil {
+		settings["alertchannelid"] = *doc.Alerts.Channel
+	}
+	if doc.Alerts.Recipients != nil {
+		settings["alertrecipients"] = strings.Join(doc.Alerts.Recipients, ",")
+	}
+	if window := doc.Alerts.GroupingWindow; window != nil {
+		if _, err := time.ParseDuration(*window); *window != "" && err != nil {
+			return nil, fmt.Errorf("alerts: groupingWindow must be a duration like 10m")
+		}
+		settings["alertgroupingwindow"] = *window
+	}
+
+	if doc.Budgets != nil {
+		data, _ := json.MarshalIndent(doc.Budgets, "", "  ")
+		if len(doc.Budgets) == 0 {
+			data = nil
+		}
+		if _, err := parseBudgets(string(data)); err != nil {
+			return nil, fmt.Errorf("budgets: %v", err)
+		}
+		settings["budgets"] = string(data)
+	}
+	return settings, nil
+}
+
+// overlay applies the document to the System Console configuration.
+func (doc *ConfigDocument) overlay(raw *Configuration) (*Configuration, map[string]documentOverride, error) {
+	base := configurationMap(raw)
+	settings, err := doc.settings(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged := configurationMap(raw)
+	for key, value := range settings {
+		merged[key] = value
+	}
+	config := &Configuration{}
+	data, _ := json.Marshal(merged)
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, nil, fmt.Errorf("invalid settings: %v", err)
+	}
+
+	applied := configurationMap(config)
+	overrides := make(map[string]documentOverride, len(settings))
+	for key := range settings {
+		overrides[key] = documentOverride{value: applied[key], base: base[key]}
+	}
+	return config, overrides, nil
+}
+
+// loadConfigDocument returns the stored document, or nil when there's none.
+func (p *Plugin) loadConfigDocument() (*storedConfigDocument, error) {
+	data, appErr := p.API.KVGet(configDocumentKey)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if data == nil {
+		return nil, nil
+	}
+	var stored storedConfigDocument
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (s *storedConfigDocument) hash() string {
+	if s == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(s.Source))
+	return hex.EncodeToString(sum[:])
+}
+
+// applyConfigDocument overlays the stored document on the System Console configuration. A
+// document that no longer applies, e.g. after a plugin upgrade dropped a setting, is
+// logged and ignored rather than half applied.
+func (p *Plugin) applyConfigDocument(raw *Configuration) (*Configuration, *appliedDocument) {
+	stored, err := p.loadConfigDocument()
+	if err != nil {
+		p.API.LogError("Failed to load the configuration document", "error", err.Error())
+		return raw, nil
+	}
+	if stored == nil {
+		return raw, nil
+	}
+	applied := &appliedDocument{hash: stored.hash()}
+	doc, err := parseConfigDocument([]byte(stored.Source))
+	if err == nil {
+		var config *Configuration
+		if config, applied.overrides, err = doc.overlay(raw); err == nil {
+			return config, applied
+		}
+	}
+	p.API.LogError("Ignoring the invalid configuration document", "error", err.Error())
+	return raw, applied
+}
+
+// checkConfigDocument reloads the configuration when the stored document changed, e.g.
+// when it was uploaded on another server of the cluster.
+func (p *Plugin) checkConfigDocument() {
+	stored, err := p.loadConfigDocument()
+	if err != nil {
+		return
+	}
+	current := ""
+	if applied := p.getConfiguration().document; applied != nil {
+		current = applied.hash
+	}
+	if stored.hash() == current {
+		return
+	}
+	p.API.LogInfo("Configuration document changed, reloading the configuration")
+	if err := p.OnConfigurationChange(); err != nil {
+		p.API.LogError("Failed to reload the configuration", "error", err.Error())
+	}
+}
+
+// ConfigDocumentResponse describes the stored configuration document.
+type ConfigDocumentResponse struct {
+	Source    string   `json:"source"`
+	UpdatedAt int64    `json:"updatedAt,omitempty"`
+	UpdatedBy string   `json:"updatedBy,omitempty"`
+	Settings  []string `json:"settings"` // configuration keys the document sets
+}
+
+func documentResponse(stored *storedConfigDocument, overrides map[string]documentOverride) ConfigDocumentResponse {
+	resp := ConfigDocumentResponse{Settings: []string{}}
+	if stored != nil {
+		resp.Source, resp.UpdatedAt, resp.UpdatedBy = stored.Source, stored.UpdatedAt, stored.UpdatedBy
+	}
+	for key := range overrides {
+		resp.Settings = append(resp.Settings, key)
+	}
+	sort.Strings(resp.Settings)
+	return resp
+}
+
+// handleConfigDocument serves /api/v1/admin/config/document. GET returns the stored
+// document, PUT validates and stores a YAML or JSON document and applies it at once, and
+// DELETE removes it, going back to the System Console settings. Other servers of the
+// cluster pick up a change within a minute.
+func (p *Plugin) handleConfigDocument(w http.ResponseWriter, r *http.Request, userID string) {
+	documentError := func(status int, code, msg string) {
+		data, _ := json.Marshal(map[string]string{"error": code, "message": msg})
+		http.Error(w, string(data), status)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		stored, err := p.loadConfigDocument()
+		if err != nil {
+			documentError(http.StatusInternalServerError, "load_failed", err.Error())
+			return
+		}
+		if stored == nil {
+			http.Error(w, `{"error": "not_found", "message": "No configuration document is stored"}`, http.StatusNotFound)
+			return
+		}
+		var overrides map[string]documentOverride
+		if applied := p.getConfiguration().document; applied != nil && applied.hash == stored.hash() {
+			overrides = applied.overrides
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(documentResponse(stored, overrides))
+		return
+
+	case http.MethodDelete:
+		if appErr := p.API.KVDelete(configDocumentKey); appErr != nil {
+			documentError(http.StatusInternalServerError, "delete_failed", appErr.Error())
+			return
+		}
+		if err := p.OnConfigurationChange(); err != nil {
+			documentError(http.StatusInternalServerError, "reload_failed", err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	source, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigDocumentSize))
+	if err != nil {
+		documentError(http.StatusBadRequest, "invalid_document", fmt.Sprintf("the document must be at most %d KB", maxConfigDocumentSize/1024))
+		return
+	}
+	doc, err := parseConfigDocument(source)
+	if err != nil {
+		documentError(http.StatusBadRequest, "invalid_document", err.Error())
+		return
+	}
+	// Check it against the System Console settings it will be applied over
+	var base Configuration
+	if err := p.API.LoadPluginConfiguration(&base); err != nil {
+		documentError(http.StatusInternalServerError, "load_failed", err.Error())
+		return
+	}
+	_, overrides, err := doc.overlay(&base)
+	if err != nil {
+		documentError(http.StatusBadRequest, "invalid_document", err.Error())
+		return
+	}
+
+	stored := &storedConfigDocument{Source: string(source), UpdatedAt: time.Now().UnixMilli(), UpdatedBy: userID}
+	data, _ := json.Marshal(stored)
+	if appErr := p.API.KVSet(configDocumentKey, data); appErr != nil {
+		documentError(http.StatusInternalServerError, "save_failed", appErr.Error())
+		return
+	}
+	p.useConfiguration(&base)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(documentResponse(stored, overrides))
+}
@@ -0,0 +1,807 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Admin: test connection =====
+
+// ConnectionTestResult is the response for POST /api/v1/admin/test/{provider}.
+type ConnectionTestResult struct {
+	Provider  string   `json:"provider"`
+	Success   bool     `json:"success"`
+	LatencyMs int64    `json:"latencyMs"`
+	Message   string   `json:"message"`
+	Scopes    []string `json:"scopes"` // endpoints the credentials were verified against
+	// Each request sent, up to the first that failed
+	Probes []ProbeResult `json:"probes"`
+}
+
+// ProbeResult is what one request of a connection test got back.
+type ProbeResult struct {
+	Scope     string   `json:"scope"`
+	Method    string   `json:"method"`
+	URL       string   `json:"url"`              // without the query, which may hold a key
+	Status    int      `json:"status,omitempty"` // 0 when no response came back
+	LatencyMs int64    `json:"latencyMs"`
+	RequestID string   `json:"requestId,omitempty"` // the provider's, for its support
+	Fields    []string `json:"fields,omitempty"`    // top-level fields of a JSON response
+	Error     string   `json:"error,omitempty"`     // the provider's error message, or the network error
+	Body      string   `json:"body,omitempty"`      // the start of a failed response
+}
+
+// probeRequestIDHeaders are the headers providers return their request IDs in.
+var probeRequestIDHeaders = []string{"X-Request-Id", "Request-Id", "Cf-Ray", "X-Amzn-Requestid", "Apim-Request-Id"}
+
+// probeErrorMessage finds the message in the error shapes providers use, such as
+// {"error": {"message": ...}}, {"error": ...}, {"message": ...} or {"detail": ...}.
+func probeErrorMessage(body []byte) string {
+	var parsed map[string]any
+	if json.Unmarshal(body, &parsed) != nil {
+		return ""
+	}
+	if e, ok := parsed["error"].(map[string]any); ok {
+		if msg, ok := e["message"].(string); ok {
+			return msg
+		}
+	}
+	for _, key := range []string{"error", "message", "detail", "error_description"} {
+		if msg, ok := parsed[key].(string); ok && msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// probeFields lists the top-level fields of a JSON object response, to show what the
+// credentials can read without echoing values that may be sensitive.
+func probeFields(body []byte) []string {
+	var parsed map[string]json.RawMessage
+	if json.Unmarshal(body, &parsed) != nil {
+		return nil
+	}
+	fields := make([]string, 0, len(parsed))
+	for key := range parsed {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// serveAdminAPI handles System Console endpoints. Only system admins may call them,
+// regardless of the plugin's user and team allowlists.
+func (p *Plugin) serveAdminAPI(w http.ResponseWriter, r *http.Request, userID string) {
+	if !p.isSystemAdmin(userID) {
+		http.Error(w, `{"error": "forbidden", "message": "System admin permission required"}`, http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/v1/admin/test/") && r.Method == http.MethodPost:
+		p.handleTestConnection(w, r, userID, strings.TrimPrefix(r.URL.Path, "/api/v1/admin/test/"))
+	case r.URL.Path == "/api/v1/admin/diagnostics" && r.Method == http.MethodGet:
+		p.handleDiagnostics(w, r)
+	case r.URL.Path == "/api/v1/admin/log" && r.Method == http.MethodGet:
+		p.handleFetchLog(w, r)
+	case r.URL.Path == "/api/v1/admin/metrics" && r.Method == http.MethodGet:
+		p.handleMetrics(w, r)
+	case r.URL.Path == "/api/v1/admin/allocation" && r.Method == http.MethodGet:
+		p.handleCostAllocation(w, r)
+	case r.URL.Path == "/api/v1/admin/budgets" && (r.Method == http.MethodGet || r.Method == http.MethodPut):
+		p.handleBudgets(w, r)
+	case r.URL.Path == "/api/v1/admin/config/document" && (r.Method == http.MethodGet || r.Method == http.MethodPut || r.Method == http.MethodDelete):
+		p.handleConfigDocument(w, r, userID)
+	case r.URL.Path == "/api/v1/admin/backup/export" && r.Method == http.MethodPost:
+		p.handleBackupExport(w, r)
+	case r.URL.Path == "/api/v1/admin/backup/import" && r.Method == http.MethodPost:
+		p.handleBackupImport(w, r)
+	case r.URL.Path == "/api/v1/admin/setup/open" && r.Method == http.MethodPost:
+		p.handleSetupOpen(w, r, userID)
+	case r.URL.Path == "/api/v1/admin/setup/submit" && r.Method == http.MethodPost:
+		p.handleSetupSubmit(w, r, userID)
+	case r.URL.Path == "/api/v1/admin/customprovider/submit" && r.Method == http.MethodPost:
+		p.handleCustomProviderSubmit(w, r, userID)
+	case r.URL.Path == "/api/v1/admin/customprovider/confirm" && r.Method == http.MethodPost:
+		p.handleCustomProviderConfirm(w, r, userID)
+	case r.URL.Path == "/api/v1/admin/claude/oauth" && r.Method == http.MethodGet:
+		p.handleClaudeOAuthStatus(w, r)
+	case r.URL.Path == "/api/v1/admin/claude/oauth" && r.Method == http.MethodDelete:
+		p.handleClaudeOAuthDisconnect(w, r, userID)
+	case r.URL.Path == "/api/v1/admin/claude/oauth/start" && r.Method == http.MethodPost:
+		p.handleClaudeOAuthStart(w, r, userID)
+	case r.URL.Path == "/api/v1/admin/claude/oauth/callback" && r.Method == http.MethodPost:
+		p.handleClaudeOAuthCallback(w, r, userID)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *Plugin) handleTestConnection(w http.ResponseWriter, r *http.Request, userID, provider string) {
+	// Test the saved configuration, optionally overridden by unsaved values from the console
+	config := *p.getConfiguration()
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&config); err != nil && err != io.EOF {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+
+	if provider == "claude" {
+		config = *p.claudeConfig(&config)
+	}
+	probes, ok := connectionProbes(provider, &config)
+	if !ok {
+		http.Error(w, `{"error": "unknown_provider", "message": "Unknown provider"}`, http.StatusNotFound)
+		return
+	}
+
+	result := p.runConnectionTest(provider, probes, p.getUserContext(userID).Locale)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// connectionProbe is a single authenticated request whose success proves access to Scope.
+// A nil Request means the credential needed to build it is missing.
+type connectionProbe struct {
+	Scope   string
+	Request *http.Request
+	Missing string // message ID used when Request is nil
+}
+
+// connectionProbes returns the credential checks for a provider, or false if it's unknown.
+func connectionProbes(provider string, config *Configuration) ([]connectionProbe, bool) {
+	switch provider {
+	case "augment":
+		return augmentProbes(config), true
+	case "zai":
+		return zaiProbes(config), true
+	case "openai":
+		return openAIProbes(config), true
+	case "claude":
+		return claudeProbes(config), true
+	case "anthropic":
+		return anthropicProbes(config), true
+	case "copilot":
+		return copilotProbes(config), true
+	case "cursor":
+		return cursorProbes(config), true
+	case "mistral":
+		return mistralProbes(config), true
+	case "groq":
+		return groqProbes(config), true
+	case "deepseek":
+		return deepSeekProbes(config), true
+	case "perplexity":
+		return perplexityProbes(config), true
+	case "together":
+		return togetherProbes(config), true
+	case "bedrock":
+		return bedrockProbes(config), true
+	case "vertex":
+		return vertexProbes(config), true
+	case "gemini":
+		return geminiProbes(config), true
+	case "azureopenai":
+		return azureOpenAIProbes(config), true
+	case "xai":
+		return xaiProbes(config), true
+	case "windsurf":
+		return windsurfProbes(config), true
+	case "jetbrains":
+		return jetBrainsProbes(config), true
+	case "cerebras":
+		return cerebrasProbes(config), true
+	case "sambanova":
+		return sambaNovaProbes(config), true
+	case "selfhosted":
+		return selfHostedProbes(config), true
+	case "litellm":
+		return liteLLMProbes(config), true
+	case "helicone":
+		return heliconeProbes(config), true
+	case "gateway":
+		return gatewayProbes(config), true
+	case "elevenlabs":
+		return elevenLabsProbes(config), true
+	case "assemblyai":
+		return assemblyAIProbes(config), true
+	case "amazonq":
+		return amazonQProbes(config), true
+	case "moonshot":
+		return moonshotProbes(config), true
+	case "dashscope":
+		return dashScopeProbes(config), true
+	case "ai21":
+		return ai21Probes(config), true
+	case "fal":
+		return falProbes(config), true
+	case "runpod":
+		return runPodProbes(config), true
+	case "yandex":
+		return yandexProbes(config), true
+	case "gigachat":
+		return gigaChatProbes(config), true
+	case "reka":
+		return rekaProbes(config), true
+	case "watsonx":
+		return watsonxProbes(config), true
+	case "databricks":
+		return databricksProbes(config), true
+	case "nvidia":
+		return nvidiaProbes(config), true
+	case "vercel":
+		return vercelProbes(config), true
+	case "openrouter":
+		return openRouterProbes(config), true
+	case "portkey":
+		return portkeyProbes(config), true
+	case "githubmodels":
+		return githubModelsProbes(config), true
+	case "codex":
+		return codexProbes(config), true
+	case "chatgpt":
+		return chatGPTProbes(config), true
+	case "upstage":
+		return upstageProbes(config), true
+	case "aigateway":
+		return aiGatewayProbes(config), true
+	case "custom":
+		return customProbes(config), true
+	}
+	return nil, false
+}
+
+// runConnectionTest sends the probes one after the other, without touching the cache or
+// the provider's backoff, and stops at the first that fails.
+func (p *Plugin) runConnectionTest(provider string, probes []connectionProbe, locale string) ConnectionTestResult {
+	result := ConnectionTestResult{Provider: provider, Scopes: []string{}, Probes: []ProbeResult{}}
+	client := p.outboundClient(10 * time.Second)
+
+	start := time.Now()
+	for _, probe := range probes {
+		if probe.Request == nil {
+			result.Message = translate(locale, probe.Missing)
+			return result
+		}
+
+		u := *probe.Request.URL
+		u.RawQuery, u.User = "", nil
+		pr := ProbeResult{Scope: probe.Scope, Method: probe.Request.Method, URL: u.String()}
+		sent := time.Now()
+		resp, err := client.Do(p.prepareProviderRequest(probe.Request, provider))
+		pr.LatencyMs = time.Since(sent).Milliseconds()
+		if err != nil {
+			pr.Error = err.Error()
+			result.Probes = append(result.Probes, pr)
+			result.LatencyMs = time.Since(start).Milliseconds()
+			result.Message = translate(locale, "error.api", err.Error())
+			return result
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+		pr.Status = resp.StatusCode
+		for _, header := range probeRequestIDHeaders {
+			if id := resp.Header.Get(header); id != "" {
+				pr.RequestID = id
+				break
+			}
+		}
+		pr.Fields = probeFields(body)
+
+		if resp.StatusCode != http.StatusOK {
+			pr.Error = probeErrorMessage(body)
+			pr.Body = string(body[:min(len(body), 2000)])
+			result.Probes = append(result.Probes, pr)
+			result.LatencyMs = time.Since(start).Milliseconds()
+			detail := pr.Error
+			if detail == "" {
+				detail = string(body[:min(len(body), 200)])
+			}
+			result.Message = translate(locale, "error.http", resp.StatusCode, detail)
+			return result
+		}
+		result.Probes = append(result.Probes, pr)
+		result.Scopes = append(result.Scopes, probe.Scope)
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+	result.Success = true
+	result.Message = translate(locale, "connection.ok", strings.Join(result.Scopes, ", "))
+	return result
+}
+
+func augmentProbes(config *Configuration) []connectionProbe {
+	if config.AugmentAccessToken == "" {
+		return []connectionProbe{{Missing: "error.access_token_missing"}}
+	}
+	return []connectionProbe{{Scope: "credit-info", Request: newAugmentCreditRequest(config.AugmentAccessToken)}}
+}
+
+func zaiProbes(config *Configuration) []connectionProbe {
+	keys := parseZaiKeys(config.ZaiApiKey)
+	if len(keys) == 0 {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	// Every key of a pool is tested, with its name in the scope
+	var probes []connectionProbe
+	for _, key := range keys {
+		suffix := ""
+		if len(keys) > 1 {
+			suffix = " (" + key.name + ")"
+		}
+		req, _ := http.NewRequest("GET", "https://api.z.ai/api/biz/subscription/list", nil)
+		req.Header.Set("Authorization", "Bearer "+key.key)
+		req2, _ := http.NewRequest("GET", "https://api.z.ai/api/monitor/usage/quota/limit", nil)
+		req2.Header.Set("Authorization", "Bearer "+key.key)
+		probes = append(probes, connectionProbe{Scope: "subscription" + suffix, Request: req}, connectionProbe{Scope: "quota" + suffix, Request: req2})
+	}
+	return probes
+}
+
+func openAIProbes(config *Configuration) []connectionProbe {
+	if config.OpenaiApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	now := time.Now().UTC()
+	url := fmt.Sprintf("https://api.openai.com/v1/organization/costs?start_time=%d&limit=1", now.Add(-24*time.Hour).Unix())
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Authorization", "Bearer "+config.OpenaiApiKey)
+	return []connectionProbe{{Scope: "organization.costs", Request: req}}
+}
+
+func claudeProbes(config *Configuration) []connectionProbe {
+	if config.ClaudeAccessToken == "" {
+		return []connectionProbe{{Missing: "error.claude_token_missing"}}
+	}
+	req, _ := http.NewRequest("GET", "https://api.anthropic.com/api/oauth/usage", nil)
+	req.Header.Set("Authorization", "Bearer "+config.ClaudeAccessToken)
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("anthropic-beta", "oauth-2025-04-20")
+	return []connectionProbe{{Scope: "oauth.usage", Request: req}}
+}
+
+func anthropicProbes(config *Configuration) []connectionProbe {
+	if config.AnthropicAdminKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	start := time.Now().UTC().Add(-24 * time.Hour).Truncate(24 * time.Hour)
+	url := "https://api.anthropic.com/v1/organizations/cost_report?starting_at=" + neturl.QueryEscape(start.Format(time.RFC3339))
+	return []connectionProbe{{Scope: "organizations.cost_report", Request: newAnthropicAdminRequest(config, url)}}
+}
+
+func copilotProbes(config *Configuration) []connectionProbe {
+	if config.CopilotToken == "" {
+		return []connectionProbe{{Missing: "error.access_token_missing"}}
+	}
+	if strings.TrimSpace(config.CopilotOrganization) == "" {
+		return []connectionProbe{{Missing: "error.copilot_org_missing"}}
+	}
+	org := neturl.PathEscape(strings.TrimSpace(config.CopilotOrganization))
+	return []connectionProbe{{Scope: "copilot.billing", Request: newCopilotRequest(config, "/orgs/"+org+"/copilot/billing")}}
+}
+
+func cursorProbes(config *Configuration) []connectionProbe {
+	if config.CursorApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "teams.spend", Request: newCursorRequest(config, "/teams/spend", map[string]any{"page": 1, "pageSize": 1})}}
+}
+
+func mistralProbes(config *Configuration) []connectionProbe {
+	if config.MistralApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "models", Request: newMistralRequest(config)}}
+}
+
+func groqProbes(config *Configuration) []connectionProbe {
+	if config.GroqApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	req, _ := http.NewRequest("GET", "https://api.groq.com/openai/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.GroqApiKey)
+	return []connectionProbe{{Scope: "models", Request: req}}
+}
+
+func deepSeekProbes(config *Configuration) []connectionProbe {
+	if config.DeepseekApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "user.balance", Request: newDeepSeekRequest(config)}}
+}
+
+func perplexityProbes(config *Configuration) []connectionProbe {
+	if config.PerplexityApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "async.chat.completions", Request: newPerplexityRequest(config)}}
+}
+
+func togetherProbes(config *Configuration) []connectionProbe {
+	if config.TogetherApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "models", Request: newTogetherRequest(config)}}
+}
+
+func bedrockProbes(config *Configuration) []connectionProbe {
+	creds := config.bedrockCredentials()
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return []connectionProbe{{Missing: "error.aws_credentials_missing"}}
+	}
+	now := time.Now().UTC()
+	start, _ := billingCycle(now, 1)
+	return []connectionProbe{
+		{Scope: "ce:GetCostAndUsage", Request: newBedrockCostRequest(creds, start, now)},
+		{Scope: "cloudwatch:GetMetricData", Request: newBedrockMetricsRequest(creds, config.bedrockRegion(), start, now)},
+	}
+}
+
+// vertexProbes only checks the service account key: the BigQuery and Monitoring requests
+// need the access token the exchange returns.
+func vertexProbes(config *Configuration) []connectionProbe {
+	if strings.TrimSpace(config.VertexServiceAccountKey) == "" {
+		return []connectionProbe{{Missing: "error.gcp_key_missing"}}
+	}
+	sa, err := parseServiceAccount(config.VertexServiceAccountKey)
+	if err != nil {
+		return []connectionProbe{{Missing: "error.gcp_key_invalid"}}
+	}
+	req, err := newGCPTokenRequest(sa, vertexScopes, time.Now())
+	if err != nil {
+		return []connectionProbe{{Missing: "error.gcp_key_invalid"}}
+	}
+	return []connectionProbe{{Scope: "oauth2:" + sa.ClientEmail, Request: req}}
+}
+
+// geminiProbes lists the models with the API key and exchanges the service account key
+// for a token, whichever are configured.
+func geminiProbes(config *Configuration) []connectionProbe {
+	var probes []connectionProbe
+	if strings.TrimSpace(config.GeminiApiKey) != "" {
+		probes = append(probes, connectionProbe{Scope: "models", Request: newGeminiModelsRequest(config)})
+	}
+	if strings.TrimSpace(config.GeminiServiceAccountKey) != "" {
+		sa, err := parseServiceAccount(config.GeminiServiceAccountKey)
+		if err != nil {
+			return []connectionProbe{{Missing: "error.gcp_key_invalid"}}
+		}
+		req, err := newGCPTokenRequest(sa, geminiScopes, time.Now())
+		if err != nil {
+			return []connectionProbe{{Missing: "error.gcp_key_invalid"}}
+		}
+		probes = append(probes, connectionProbe{Scope: "oauth2:" + sa.ClientEmail, Request: req})
+	}
+	if len(probes) == 0 {
+		return []connectionProbe{{Missing: "error.gemini_credentials_missing"}}
+	}
+	return probes
+}
+
+// azureOpenAIProbes exchanges the client secret for a Resource Manager token; reading the
+// deployments also needs a role on the subscription, which only a refresh shows.
+func azureOpenAIProbes(config *Configuration) []connectionProbe {
+	if strings.TrimSpace(config.AzureOpenaiTenantId) == "" || strings.TrimSpace(config.AzureOpenaiClientId) == "" ||
+		config.AzureOpenaiClientSecret == "" || strings.TrimSpace(config.AzureOpenaiSubscriptionId) == "" {
+		return []connectionProbe{{Missing: "error.azure_credentials_missing"}}
+	}
+	return []connectionProbe{{Scope: "oauth2:" + strings.TrimSpace(config.AzureOpenaiClientId), Request: newAzureTokenRequest(config)}}
+}
+
+// xaiProbes checks the API key only: billing requests need the team ID the key check returns.
+func xaiProbes(config *Configuration) []connectionProbe {
+	if config.XaiApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "api-key", Request: newXaiKeyRequest(config)}}
+}
+
+func windsurfProbes(config *Configuration) []connectionProbe {
+	if config.WindsurfServiceKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "GetTeamCreditBalance", Request: newWindsurfRequest(config, "GetTeamCreditBalance", nil)}}
+}
+
+func jetBrainsProbes(config *Configuration) []connectionProbe {
+	if config.JetbrainsApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	if strings.TrimSpace(config.JetbrainsCustomerCode) == "" {
+		return []connectionProbe{{Missing: "error.jetbrains_customer_missing"}}
+	}
+	return []connectionProbe{{Scope: "customer/licenses", Request: newJetBrainsRequest(config)}}
+}
+
+func cerebrasProbes(config *Configuration) []connectionProbe {
+	if config.CerebrasApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	req, _ := http.NewRequest("GET", "https://api.cerebras.ai/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.CerebrasApiKey)
+	return []connectionProbe{{Scope: "models", Request: req}}
+}
+
+func sambaNovaProbes(config *Configuration) []connectionProbe {
+	if config.SambanovaApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	req, _ := http.NewRequest("GET", "https://api.sambanova.ai/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.SambanovaApiKey)
+	return []connectionProbe{{Scope: "models", Request: req}}
+}
+
+func selfHostedProbes(config *Configuration) []connectionProbe {
+	if config.selfHostedType() == "openai" {
+		return []connectionProbe{{Scope: "v1/models", Request: newSelfHostedRequest(config, "GET", "/v1/models", nil)}}
+	}
+	return []connectionProbe{{Scope: "api/tags", Request: newSelfHostedRequest(config, "GET", "/api/tags", nil)}}
+}
+
+func liteLLMProbes(config *Configuration) []connectionProbe {
+	if config.litellmURL() == "" {
+		return []connectionProbe{{Missing: "error.litellm_url_missing"}}
+	}
+	if config.LitellmMasterKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "team/list", Request: newLiteLLMRequest(config, "/team/list", nil)}}
+}
+
+func heliconeProbes(config *Configuration) []connectionProbe {
+	if config.HeliconeApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	now := time.Now().UTC()
+	return []connectionProbe{{Scope: "user/metrics/query", Request: newHeliconeMetricsRequest(config, now.Add(-time.Hour), now, 0)}}
+}
+
+func gatewayProbes(config *Configuration) []connectionProbe {
+	if strings.TrimSpace(config.GatewayUrl) == "" {
+		return []connectionProbe{{Missing: "error.gateway_url_missing"}}
+	}
+	probes := []connectionProbe{{Scope: "models", Request: newGatewayModelsRequest(config)}}
+	if strings.TrimSpace(config.GatewayUsagePath) != "" {
+		if req, err := newGatewayUsageRequest(config); err == nil {
+			probes = append(probes, connectionProbe{Scope: "usage", Request: req})
+		}
+	}
+	return probes
+}
+
+func elevenLabsProbes(config *Configuration) []connectionProbe {
+	if config.ElevenlabsApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "user/subscription", Request: newElevenLabsRequest(config)}}
+}
+
+func assemblyAIProbes(config *Configuration) []connectionProbe {
+	if config.AssemblyaiApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "transcripts", Request: newAssemblyAIRequest(config, "https://api.assemblyai.com/v2/transcript?limit=1")}}
+}
+
+func amazonQProbes(config *Configuration) []connectionProbe {
+	creds := config.amazonQCredentials()
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return []connectionProbe{{Missing: "error.aws_credentials_missing"}}
+	}
+	now := time.Now().UTC()
+	start, _ := billingCycle(now, 1)
+	probes := []connectionProbe{
+		{Scope: "ce:GetCostAndUsage", Request: newBedrockCostRequest(creds, start, now)},
+		{Scope: "cloudwatch:GetMetricData", Request: newAmazonQMetricsRequest(creds, config.amazonQRegion(), start, now)},
+	}
+	if strings.TrimSpace(config.AmazonqIdentityStoreId) != "" && strings.TrimSpace(config.AmazonqGroupId) != "" {
+		probes = append(probes, connectionProbe{Scope: "identitystore:ListGroupMemberships", Request: newAmazonQMembershipsRequest(config, creds, "", now)})
+	}
+	return probes
+}
+
+func moonshotProbes(config *Configuration) []connectionProbe {
+	if config.MoonshotApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "users/me/balance", Request: newMoonshotRequest(config)}}
+}
+
+func dashScopeProbes(config *Configuration) []connectionProbe {
+	if config.DashscopeApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	probes := []connectionProbe{{Scope: "models", Request: newDashScopeModelsRequest(config)}}
+	if creds := config.dashScopeCredentials(); creds.AccessKeyID != "" && creds.AccessKeySecret != "" {
+		now := time.Now().UTC()
+		_, host := config.dashScopeHosts()
+		probes = append(probes, connectionProbe{Scope: "bss:QueryBillOverview", Request: newAliyunRPCRequest(creds, host,
+			"QueryBillOverview", "2017-12-14", map[string]string{"BillingCycle": now.Format("2006-01")}, now)})
+	}
+	return probes
+}
+
+func ai21Probes(config *Configuration) []connectionProbe {
+	if config.Ai21ApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "library/files", Request: newAI21Request(config)}}
+}
+
+func falProbes(config *Configuration) []connectionProbe {
+	if config.FalApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "account/billing", Request: newFalRequest(config, "/v1/account/billing", neturl.Values{"expand": {"credits"}})}}
+}
+
+func runPodProbes(config *Configuration) []connectionProbe {
+	if config.RunpodApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "graphql myself", Request: newRunPodRequest(config)}}
+}
+
+// yandexProbes only checks the authorized key: the billing request needs the IAM token the
+// exchange returns.
+func yandexProbes(config *Configuration) []connectionProbe {
+	if strings.TrimSpace(config.YandexServiceAccountKey) == "" {
+		return []connectionProbe{{Missing: "error.yandex_key_missing"}}
+	}
+	key, err := parseYandexAuthorizedKey(config.YandexServiceAccountKey)
+	if err != nil {
+		return []connectionProbe{{Missing: "error.yandex_key_invalid"}}
+	}
+	req, err := newYandexTokenRequest(key, time.Now())
+	if err != nil {
+		return []connectionProbe{{Missing: "error.yandex_key_invalid"}}
+	}
+	return []connectionProbe{{Scope: "iam:" + key.ServiceAccountID, Request: req}}
+}
+
+// gigaChatProbes only checks the authorization key: the balance request needs the access
+// token the exchange returns.
+func gigaChatProbes(config *Configuration) []connectionProbe {
+	if config.GigachatAuthKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "oauth:" + config.gigaChatScope(), Request: newGigaChatTokenRequest(config)}}
+}
+
+func rekaProbes(config *Configuration) []connectionProbe {
+	if config.RekaApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "models", Request: newRekaRequest(config)}}
+}
+
+// watsonxProbes only checks the API key: the usage report needs the IAM token the exchange
+// returns.
+func watsonxProbes(config *Configuration) []connectionProbe {
+	if config.WatsonxApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	if strings.TrimSpace(config.WatsonxAccountId) == "" {
+		return []connectionProbe{{Missing: "error.ibm_account_missing"}}
+	}
+	return []connectionProbe{{Scope: "iam:apikey", Request: newIBMTokenRequest(config)}}
+}
+
+// databricksProbes checks the token and the warehouse without running the billing query,
+// which can wait for a stopped warehouse to start.
+func databricksProbes(config *Configuration) []connectionProbe {
+	if config.DatabricksToken == "" {
+		return []connectionProbe{{Missing: "error.access_token_missing"}}
+	}
+	warehouse := strings.TrimSpace(config.DatabricksWarehouseId)
+	if config.databricksHost() == "" || warehouse == "" {
+		return []connectionProbe{{Missing: "error.databricks_warehouse_missing"}}
+	}
+	req, _ := http.NewRequest("GET", config.databricksHost()+"/api/2.0/sql/warehouses/"+neturl.PathEscape(warehouse), nil)
+	req.Header.Set("Authorization", "Bearer "+config.DatabricksToken)
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return []connectionProbe{{Scope: "sql/warehouses", Request: req}}
+}
+
+func nvidiaProbes(config *Configuration) []connectionProbe {
+	if config.NvidiaApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "models", Request: newNvidiaRequest(config)}}
+}
+
+func vercelProbes(config *Configuration) []connectionProbe {
+	if config.VercelApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "credits", Request: newVercelCreditsRequest(config)}}
+}
+
+// openRouterProbes checks the API key only: keys that can't read the account's credits
+// still work for the card.
+func openRouterProbes(config *Configuration) []connectionProbe {
+	if config.OpenrouterApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "auth/key", Request: newOpenRouterKeyRequest(config)}}
+}
+
+// portkeyProbes checks the key against the analytics API, which workspace-scoped keys can
+// read too; listing workspaces needs an admin key and is optional.
+func portkeyProbes(config *Configuration) []connectionProbe {
+	if config.PortkeyApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	now := time.Now().UTC()
+	query := neturl.Values{
+		"time_of_generation_min": {now.Add(-time.Hour).Format(time.RFC3339)},
+		"time_of_generation_max": {now.Format(time.RFC3339)},
+	}
+	return []connectionProbe{{Scope: "analytics", Request: newPortkeyRequest(config, "/v1/analytics/graphs/requests", query)}}
+}
+
+// githubModelsProbes only reads the catalog, since an inference request would use up part of
+// the daily allowance.
+func githubModelsProbes(config *Configuration) []connectionProbe {
+	if config.GithubModelsToken == "" {
+		return []connectionProbe{{Missing: "error.access_token_missing"}}
+	}
+	return []connectionProbe{{Scope: "models:read", Request: newGithubModelsCatalogRequest(config)}}
+}
+
+func codexProbes(config *Configuration) []connectionProbe {
+	if config.CodexAccessToken == "" {
+		return []connectionProbe{{Missing: "error.codex_token_missing"}}
+	}
+	return []connectionProbe{{Scope: "wham.usage", Request: newCodexUsageRequest(config)}}
+}
+
+func upstageProbes(config *Configuration) []connectionProbe {
+	if config.UpstageApiKey == "" {
+		return []connectionProbe{{Missing: "error.api_key_missing"}}
+	}
+	return []connectionProbe{{Scope: "models", Request: newUpstageRequest(config)}}
+}
+
+func aiGatewayProbes(config *Configuration) []connectionProbe {
+	if config.aiGatewayURL() == "" {
+		return []connectionProbe{{Missing: "error.aigateway_url_missing"}}
+	}
+	return []connectionProbe{{Scope: "metrics", Request: newAIGatewayMetricsRequest(config)}}
+}
+
+func chatGPTProbes(config *Configuration) []connectionProbe {
+	switch {
+	case config.ChatgptSessionToken != "":
+		return []connectionProbe{{Scope: "session", Request: newChatGPTSessionRequest(config)}}
+	case config.CodexAccessToken != "":
+		account := config.ChatgptAccountId
+		if account == "" {
+			account = config.CodexAccountId
+		}
+		return []connectionProbe{{Scope: "accounts.check", Request: newChatGPTAccountsRequest(config.CodexAccessToken, account)}}
+	}
+	return []connectionProbe{{Missing: "error.chatgpt_token_missing"}}
+}
+
+// customProbes calls each custom provider's endpoint, reporting its ID as the scope.
+func customProbes(config *Configuration) []connectionProbe {
+	defs, err := parseCustomProviders(config.CustomProviders)
+	switch {
+	case err != nil:
+		return []connectionProbe{{Missing: "error.custom_providers_invalid"}}
+	case len(defs) == 0:
+		return []connectionProbe{{Missing: "error.custom_providers_missing"}}
+	}
+	var probes []connectionProbe
+	for _, def := range defs {
+		probes = append(probes, connectionProbe{Scope: def.ID, Request: newCustomRequest(def)})
+	}
+	return probes
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== GitHub Copilot (organization, personal access token) =====
+
+// copilotPremiumPerSeat is the monthly premium request allowance included with each seat, by plan.
+var copilotPremiumPerSeat = map[string]float64{
+	"business":   300,
+	"enterprise": 1000,
+}
+
+// copilotSeatPrice is the monthly list price of a seat in USD, by plan.
+var copilotSeatPrice = map[string]float64{
+	"business":   19,
+	"enterprise": 39,
+}
+
+// CopilotUsageInfo is an organization's Copilot seats and this month's premium requests.
+// Premium requests reset on the 1st of each month (UTC); usage past the allowance is billed.
+type CopilotUsageInfo struct {
+	Organization string  `json:"organization"`
+	PlanType     string  `json:"planType"`
+	SeatsTotal   float64 `json:"seatsTotal"`
+	SeatsActive  float64 `json:"seatsActive"`
+	SeatsPending float64 `json:"seatsPending"`
+	// Seats nobody used this cycle, and seats that end with it
+	SeatsInactive            float64 `json:"seatsInactive"`
+	SeatsPendingCancellation float64 `json:"seatsPendingCancellation,omitempty"`
+	// The seats' monthly cost at list price; 0 for an unknown plan
+	SeatCost         float64 `json:"seatCost,omitempty"`
+	HasPremiumData   bool    `json:"hasPremiumData"`
+	PremiumUsed      float64 `json:"premiumUsed"`
+	PremiumAllowance float64 `json:"premiumAllowance"`
+	OverageCost      float64 `json:"overageCost"`
+	Currency         string  `json:"currency"`
+	CycleEnd         string  `json:"cycleEnd"`
+}
+
+func (c CopilotUsageInfo) summary(locale string) string {
+	idle := ""
+	if c.SeatsInactive > 0 {
+		idle = " " + translate(locale, "summary.copilot_idle_seats", formatCount(c.SeatsInactive))
+	}
+	if !c.HasPremiumData {
+		return translate(locale, "summary.copilot_seats", formatCount(c.SeatsActive), formatCount(c.SeatsTotal)) + idle
+	}
+	text := translate(locale, "summary.copilot", formatCount(c.PremiumUsed), formatCount(c.PremiumAllowance), formatCount(c.SeatsActive), formatCount(c.SeatsTotal)) + idle
+	if c.OverageCost > 0 {
+		text += " " + translate(locale, "summary.copilot_overage", formatMoney(c.OverageCost, c.Currency, 2))
+	}
+	return text
+}
+
+func (c CopilotUsageInfo) usagePercent() (float64, bool) {
+	if c.HasPremiumData && c.PremiumAllowance > 0 {
+		return c.PremiumUsed / c.PremiumAllowance * 100, true
+	}
+	return 0, false
+}
+
+func (c CopilotUsageInfo) resets(time.Time) []ResetInfo {
+	if c.HasPremiumData {
+		return []ResetInfo{{Kind: "copilot_premium", At: parseTime(c.CycleEnd)}}
+	}
+	return nil
+}
+
+// copilotBillingResponse is /orgs/{org}/copilot/billing.
+type copilotBillingResponse struct {
+	SeatBreakdown struct {
+		Total               flexFloat `json:"total" schema:"required"`
+		AddedThisCycle      flexFloat `json:"added_this_cycle"`
+		PendingInvitation   flexFloat `json:"pending_invitation"`
+		PendingCancellation flexFloat `json:"pending_cancellation"`
+		ActiveThisCycle     flexFloat `json:"active_this_cycle"`
+		InactiveThisCycle   flexFloat `json:"inactive_this_cycle"`
+	} `json:"seat_breakdown" schema:"required"`
+	SeatManagementSetting string `json:"seat_management_setting"`
+	IDEChat               string `json:"ide_chat"`
+	PlatformChat          string `json:"platform_chat"`
+	CLI                   string `json:"cli"`
+	PublicCodeSuggestions string `json:"public_code_suggestions"`
+	PlanType              string `json:"plan_type"`
+}
+
+// copilotPremiumResponse is /organizations/{org}/settings/billing/premium_request/usage.
+// Discounted quantities are covered by the included allowance; net amounts are billed.
+type copilotPremiumResponse struct {
+	TimePeriod struct {
+		Year  int `json:"year"`
+		Month int `json:"month"`
+		Day   int `json:"day"`
+	} `json:"timePeriod"`
+	Organization string `json:"organization"`
+	UsageItems   []struct {
+		Product          string    `json:"product"`
+		SKU              string    `json:"sku"`
+		Model            string    `json:"model"`
+		UnitType         string    `json:"unitType"`
+		PricePerUnit     flexFloat `json:"pricePerUnit"`
+		GrossQuantity    flexFloat `json:"grossQuantity" schema:"required"`
+		GrossAmount      flexFloat `json:"grossAmount"`
+		DiscountQuantity flexFloat `json:"discountQuantity"`
+		DiscountAmount   flexFloat `json:"discountAmount"`
+		NetQuantity      flexFloat `json:"netQuantity"`
+		NetAmount        flexFloat `json:"netAmount" schema:"required"`
+	} `json:"usageItems" schema:"required"`
+}
+
+func (p *Plugin) getCopilotStatus(config *Configuration) ServiceStatus {
+	const id, name = "copilot", "GitHub Copilot"
+	org := strings.TrimSpace(config.CopilotOrganization)
+	if config.CopilotToken == "" {
+		return errorStatus(id, name, "error.access_token_missing")
+	}
+	if org == "" {
+		return errorStatus(id, name, "error.copilot_org_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	now := time.Now().UTC()
+	_, cycleEnd := billingCycle(now, 1)
+
+	body, err := p.copilotGet(client, config, "/orgs/"+neturl.PathEscape(org)+"/copilot/billing")
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	var billing copilotBillingResponse
+	drift, err := decodeResponse(body, &billing)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	seats := billing.SeatBreakdown
+	info := CopilotUsageInfo{
+		Organization: org,
+		PlanType:     billing.PlanType,
+		SeatsTotal:   float64(seats.Total),
+		SeatsActive:  float64(seats.ActiveThisCycle),
+		SeatsPending: float64(seats.PendingInvitation),
+		Currency:     baseCurrency,
+		CycleEnd:     cycleEnd.Format(time.RFC3339),
+	}
+	info.SeatsInactive = float64(seats.InactiveThisCycle)
+	info.SeatsPendingCancellation = float64(seats.PendingCancellation)
+	info.SeatCost = info.SeatsTotal * copilotSeatPrice[strings.ToLower(billing.PlanType)]
+	perSeat, ok := copilotPremiumPerSeat[strings.ToLower(billing.PlanType)]
+	if !ok {
+		perSeat = copilotPremiumPerSeat["business"]
+	}
+	info.PremiumAllowance = info.SeatsTotal * perSeat
+
+	// Premium request usage needs organization billing access, which tokens scoped to
+	// Copilot seat management don't have. Seats are still worth showing without it.
+	path := fmt.Sprintf("/organizations/%s/settings/billing/premium_request/usage?year=%d&month=%d",
+		neturl.PathEscape(org), now.Year(), int(now.Month()))
+	if body, err := p.copilotGet(client, config, path); err != nil {
+		p.API.LogWarn("Failed to fetch Copilot premium request usage", "error", err.Error())
+	} else {
+		var usage copilotPremiumResponse
+		d, err := decodeResponse(body, &usage)
+		if err != nil {
+			p.API.LogWarn("Failed to parse Copilot premium request usage", "error", err.Error())
+		} else {
+			drift.merge(d)
+			info.HasPremiumData = true
+			for _, item := range usage.UsageItems {
+				info.PremiumUsed += float64(item.GrossQuantity)
+				info.OverageCost += float64(item.NetAmount)
+			}
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	status := "ok"
+	if info.OverageCost > 0 ||
+		(info.PremiumAllowance > 0 && info.PremiumUsed/info.PremiumAllowance*100 > config.warningPercent(80)) {
+		status = "warning"
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: status,
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// copilotGet calls the GitHub REST API and returns the body of a successful response.
+func (p *Plugin) copilotGet(client *http.Client, config *Configuration, path string) ([]byte, error) {
+	resp, err := client.Do(newCopilotRequest(config, path))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+func newCopilotRequest(config *Configuration, path string) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.github.com"+path, nil)
+	req.Header.Set("Authorization", "Bearer "+config.CopilotToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
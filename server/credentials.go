@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Credential rotation reminders =====
+
+const credentialAgesKey = "credential_ages"
+
+// credentialReminderInterval is how often an admin is reminded again about a credential
+// that is still past its maximum age.
+const credentialReminderInterval = 7 * 24 * time.Hour
+
+// credentialKeyPattern matches the configuration keys that hold provider or integration
+// credentials.
+var credentialKeyPattern = regexp.MustCompile(`(apikey|adminkey|accesstoken|token|accesskeyid|secretaccesskey|accesssecret|serviceaccountkey|managementkey|servicekey|masterkey|authkey|consolecookie)$`)
+
+// credentialKeysExcluded are renewed by the plugin itself (claude.ai and Codex) or
+// generated for the plugin's own use, so rotating them is not the admin's concern.
+var credentialKeysExcluded = map[string]bool{
+	"claudeaccesstoken": true, "clauderefreshtoken": true,
+	"codexaccesstoken": true, "codexrefreshtoken": true,
+	"calendartoken": true, "metricstoken": true,
+}
+
+// credentialAge records when a credential was last changed. Only a hash of the value is
+// kept, to notice a change across restarts without storing the secret again.
+type credentialAge struct {
+	Hash       string `json:"hash"`
+	SetAt      int64  `json:"setAt"`
+	RemindedAt int64  `json:"remindedAt,omitempty"`
+}
+
+// credentialMaxAge is the configured age after which admins are reminded to rotate a
+// credential; zero disables reminders.
+func (c *Configuration) credentialMaxAge() time.Duration {
+	days, err := strconv.Atoi(strings.TrimSpace(c.CredentialMaxAgeDays))
+	if err != nil || days <= 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// configuredCredentials returns the credentials that are set, by configuration key.
+func configuredCredentials(config *Configuration) map[string]string {
+	var values map[string]any
+	data, _ := json.Marshal(config)
+	json.Unmarshal(data, &values)
+	credentials := map[string]string{}
+	for key, value := range values {
+		if s, ok := value.(string); ok && strings.TrimSpace(s) != "" && credentialKeyPattern.MatchString(key) && !credentialKeysExcluded[key] {
+			credentials[key] = s
+		}
+	}
+	return credentials
+}
+
+func credentialHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// trackCredentialAges notes when each configured credential was set. A credential seen for
+// the first time counts as set now.
+func (p *Plugin) trackCredentialAges(config *Configuration) {
+	ages := p.getCredentialAges()
+	credentials := configuredCredentials(config)
+	changed := false
+	for key, value := range credentials {
+		hash := credentialHash(value)
+		if age, ok := ages[key]; ok && age.Hash == hash {
+			continue
+		}
+		ages[key] = credentialAge{Hash: hash, SetAt: time.Now().Unix()}
+		changed = true
+	}
+	for key := range ages {
+		if _, ok := credentials[key]; !ok {
+			delete(ages, key)
+			changed = true
+		}
+	}
+	if changed {
+		p.saveCredentialAges(ages)
+	}
+}
+
+// checkCredentialAges reminds system admins of credentials older than the maximum age.
+func (p *Plugin) checkCredentialAges() {
+	config := p.getConfiguration()
+	maxAge := config.credentialMaxAge()
+	if maxAge == 0 {
+		return
+	}
+
+	locale := p.serverLocale()
+	ages := p.getCredentialAges()
+	now := time.Now()
+	var lines []string
+	for key, age := range ages {
+		setAt := time.Unix(age.SetAt, 0)
+		if now.Sub(setAt) < maxAge || now.Sub(time.Unix(age.RemindedAt, 0)) < credentialReminderInterval {
+			continue
+		}
+		days := int(now.Sub(setAt).Hours() / 24)
+		lines = append(lines, translate(locale, "alert.credential_age_item", credentialLabel(key), days, setAt.UTC().Format("2006-01-02")))
+		age.RemindedAt = now.Unix()
+		ages[key] = age
+	}
+	if len(lines) == 0 {
+		return
+	}
+	p.saveCredentialAges(ages)
+	sort.Strings(lines)
+	p.notifyAdmins(translate(locale, "alert.credential_age", int(maxAge.Hours()/24)) + "\n" + strings.Join(lines, "\n"))
+}
+
+// credentialLabel names a credential by its provider and its System Console setting, e.g.
+// "Z.AI `ZaiApiKey`".
+func credentialLabel(key string) string {
+	field := key
+	t := reflect.TypeOf(Configuration{})
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == key {
+			field = t.Field(i).Name
+			break
+		}
+	}
+	if prov, ok := findProvider(providerForKey(key)); ok {
+		return prov.Name + " `" + field + "`"
+	}
+	return "`" + field + "`"
+}
+
+func (p *Plugin) getCredentialAges() map[string]credentialAge {
+	ages := map[string]credentialAge{}
+	if data, appErr := p.API.KVGet(credentialAgesKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &ages)
+	}
+	return ages
+}
+
+func (p *Plugin) saveCredentialAges(ages map[string]credentialAge) {
+	data, _ := json.Marshal(ages)
+	if appErr := p.API.KVSet(credentialAgesKey, data); appErr != nil {
+		p.API.LogWarn("Failed to save credential ages", "error", appErr.Error())
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ===== Currencies =====
+
+// baseCurrency is what providers bill in unless they say otherwise, and what
+// exchange rates are quoted against.
+const baseCurrency = "USD"
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"CNY": "¥",
+	"JPY": "¥",
+	"INR": "₹",
+	"RUB": "₽",
+}
+
+// parseMoney parses an amount with an optional ISO currency code or symbol, e.g.
+// "500", "500 EUR", "EUR 500" or "€500". The currency is empty when none was given.
+// Symbols shared by several currencies (¥) must be written as a code.
+func parseMoney(value string) (amount float64, currency string, err error) {
+	value = strings.TrimSpace(value)
+	for code, symbol := range currencySymbols {
+		if strings.HasPrefix(value, symbol) && symbol != "¥" {
+			value, currency = strings.TrimSpace(strings.TrimPrefix(value, symbol)), code
+			break
+		}
+	}
+	if fields := strings.Fields(value); currency == "" && len(fields) == 2 {
+		if isCurrencyCode(fields[0]) {
+			currency, value = strings.ToUpper(fields[0]), fields[1]
+		} else if isCurrencyCode(fields[1]) {
+			value, currency = fields[0], strings.ToUpper(fields[1])
+		}
+	}
+
+	amount, err = strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		return 0, "", fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, currency, nil
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// reportingCurrency is the currency totals across providers are shown in.
+func (c *Configuration) reportingCurrency() string {
+	if code := strings.TrimSpace(c.ReportingCurrency); isCurrencyCode(code) {
+		return strings.ToUpper(code)
+	}
+	return baseCurrency
+}
+
+// exchangeRates parses "EUR=1.08, CNY=0.14" (US dollars per unit) into a rate table
+// that always includes the base currency.
+func (c *Configuration) exchangeRates() map[string]float64 {
+	rates := map[string]float64{baseCurrency: 1}
+	for _, item := range splitList(c.ExchangeRates) {
+		code, rate, ok := strings.Cut(item, "=")
+		code = strings.ToUpper(strings.TrimSpace(code))
+		value, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if ok && isCurrencyCode(code) && err == nil && value > 0 {
+			rates[code] = value
+		}
+	}
+	return rates
+}
+
+// convertMoney converts between two currencies through the base currency. It fails
+// when either currency has no configured rate.
+func (c *Configuration) convertMoney(amount float64, from, to string) (float64, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return amount, true
+	}
+	rates := c.exchangeRates()
+	fromRate, okFrom := rates[from]
+	toRate, okTo := rates[to]
+	if !okFrom || !okTo {
+		return 0, false
+	}
+	return amount * fromRate / toRate, true
+}
+
+// budgetIn parses a configured budget and converts it to the provider's billing currency,
+// so it can be compared with spend. Budgets without a currency are in the billing
+// currency already. Invalid or unconvertible budgets are logged and treated as unset.
+func (p *Plugin) budgetIn(config *Configuration, provider, value, billingCurrency string) float64 {
+	if strings.TrimSpace(value) == "" {
+		return 0
+	}
+	amount, currency, err := parseMoney(value)
+	if err != nil {
+		p.API.LogWarn("Invalid budget", "provider", provider, "error", err.Error())
+		return 0
+	}
+	if currency == "" {
+		currency = billingCurrency
+	}
+	budget, ok := config.convertMoney(amount, currency, billingCurrency)
+	if !ok {
+		p.API.LogWarn("No exchange rate for the budget", "provider", provider, "from", currency, "to", billingCurrency)
+		return 0
+	}
+	return budget
+}
+
+// formatMoney renders an amount with the currency's symbol, or its code when it has none.
+func formatMoney(amount float64, currency string, decimals int) string {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = baseCurrency
+	}
+	number := strconv.FormatFloat(amount, 'f', decimals, 64)
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol + number
+	}
+	return number + " " + currency
+}
+
+// currency is the billing currency, defaulting to the base currency for data
+// cached before it was recorded.
+func (d OpenAIUsageInfo) currency() string {
+	if d.Currency == "" {
+		return baseCurrency
+	}
+	return d.Currency
+}
+
+// CostTotal is the spend of all providers that report costs, in the reporting currency.
+type CostTotal struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	// Providers whose currency has no exchange rate and were left out
+	Unconverted []string `json:"unconverted,omitempty"`
+}
+
+// totalCost adds up the cost of every service that reports one, or returns nil when none do.
+func totalCost(services []ServiceStatus, config *Configuration) *CostTotal {
+	total := &CostTotal{Currency: config.reportingCurrency()}
+	found := false
+	for _, s := range services {
+		if s.Usage == nil || s.Usage.Cost == nil {
+			continue
+		}
+		found = true
+		amount, ok := config.convertMoney(*s.Usage.Cost, s.Usage.Currency, total.Currency)
+		if !ok {
+			total.Unconverted = append(total.Unconverted, s.Name)
+			continue
+		}
+		total.Amount += amount
+	}
+	if !found {
+		return nil
+	}
+	return total
+}
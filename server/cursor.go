@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Cursor (team, Admin API key) =====
+
+// defaultCursorRequestsPerSeat is the monthly fast premium request allowance of a Cursor Teams seat.
+const defaultCursorRequestsPerSeat = 500
+
+// cursorMaxPages bounds pagination of team members' spend.
+const cursorMaxPages = 20
+
+// CursorUsageInfo is a Cursor team's fast premium requests and usage-based spend in the
+// current subscription cycle.
+type CursorUsageInfo struct {
+	SeatsTotal     float64             `json:"seatsTotal"`
+	SeatsActive    float64             `json:"seatsActive"`
+	RequestsUsed   float64             `json:"requestsUsed"`
+	RequestsTotal  float64             `json:"requestsTotal"`
+	RequestsRemain float64             `json:"requestsRemain"`
+	SpendCost      float64             `json:"spendCost"`
+	Currency       string              `json:"currency"`
+	CycleStart     string              `json:"cycleStart"`
+	CycleEnd       string              `json:"cycleEnd"`
+	TopMembers     []CursorMemberUsage `json:"topMembers,omitempty"`
+}
+
+func (c CursorUsageInfo) summary(locale string) string {
+	text := translate(locale, "summary.cursor", formatCount(c.RequestsRemain), formatCount(c.RequestsTotal), formatCount(c.SeatsActive), formatCount(c.SeatsTotal))
+	if c.SpendCost > 0 {
+		text += " " + translate(locale, "summary.cursor_spend", formatMoney(c.SpendCost, c.Currency, 2))
+	}
+	return text
+}
+
+func (c CursorUsageInfo) compact(name, locale string) string {
+	return translate(locale, "compact.cursor_left", name, formatCount(c.RequestsRemain))
+}
+
+func (c CursorUsageInfo) usagePercent() (float64, bool) {
+	if c.RequestsTotal > 0 {
+		return c.RequestsUsed / c.RequestsTotal * 100, true
+	}
+	return 0, false
+}
+
+func (c CursorUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "billing_cycle", At: parseTime(c.CycleEnd)}}
+}
+
+// CursorMemberUsage is one team member's consumption, for the heaviest users on the card.
+type CursorMemberUsage struct {
+	Name     string  `json:"name"`
+	Requests float64 `json:"requests"`
+	Spend    float64 `json:"spend"`
+}
+
+// cursorSpendResponse is a page of POST /teams/spend.
+type cursorSpendResponse struct {
+	TeamMemberSpend []struct {
+		Name                     string    `json:"name"`
+		Email                    string    `json:"email"`
+		Role                     string    `json:"role"`
+		SpendCents               flexFloat `json:"spendCents" schema:"required"`
+		FastPremiumRequests      flexFloat `json:"fastPremiumRequests" schema:"required"`
+		HardLimitOverrideDollars flexFloat `json:"hardLimitOverrideDollars"`
+	} `json:"teamMemberSpend" schema:"required"`
+	SubscriptionCycleStart int64 `json:"subscriptionCycleStart"` // Unix ms
+	TotalMembers           int   `json:"totalMembers"`
+	TotalPages             int   `json:"totalPages"`
+}
+
+// cursorRequestsPerSeat is the configured monthly request allowance of a seat.
+func (c *Configuration) cursorRequestsPerSeat() float64 {
+	n, err := strconv.ParseFloat(strings.TrimSpace(c.CursorRequestsPerSeat), 64)
+	if err != nil || n <= 0 {
+		return defaultCursorRequestsPerSeat
+	}
+	return n
+}
+
+func (p *Plugin) getCursorStatus(config *Configuration) ServiceStatus {
+	const id, name = "cursor", "Cursor"
+	if config.CursorApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	info := CursorUsageInfo{Currency: baseCurrency}
+	var members []CursorMemberUsage
+	var drift schemaDrift
+	var cycleStart int64
+	for page := 1; page <= cursorMaxPages; page++ {
+		resp, err := client.Do(newCursorRequest(config, "/teams/spend", map[string]any{"page": page, "pageSize": 100}))
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		body, err := p.readResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		if resp.StatusCode != 200 {
+			return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+
+		var spend cursorSpendResponse
+		d, err := decodeResponse(body, &spend)
+		if err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		drift.merge(d)
+		cycleStart = spend.SubscriptionCycleStart
+		info.SeatsTotal = float64(spend.TotalMembers)
+		for _, m := range spend.TeamMemberSpend {
+			requests, cost := float64(m.FastPremiumRequests), float64(m.SpendCents)/100
+			info.RequestsUsed += requests
+			info.SpendCost += cost
+			if requests > 0 || cost > 0 {
+				info.SeatsActive++
+			}
+			memberName := m.Name
+			if memberName == "" {
+				memberName = m.Email
+			}
+			members = append(members, CursorMemberUsage{Name: memberName, Requests: requests, Spend: cost})
+		}
+		if page >= spend.TotalPages {
+			break
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	if info.SeatsTotal == 0 {
+		info.SeatsTotal = float64(len(members))
+	}
+	info.RequestsTotal = info.SeatsTotal * config.cursorRequestsPerSeat()
+	info.RequestsRemain = max(info.RequestsTotal-info.RequestsUsed, 0)
+	if cycleStart > 0 {
+		start := time.UnixMilli(cycleStart).UTC()
+		info.CycleStart = start.Format(time.RFC3339)
+		info.CycleEnd = start.AddDate(0, 1, 0).Format(time.RFC3339)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Requests != members[j].Requests {
+			return members[i].Requests > members[j].Requests
+		}
+		return members[i].Spend > members[j].Spend
+	})
+	info.TopMembers = members[:min(len(members), 5)]
+
+	status := "ok"
+	if info.RequestsTotal > 0 && info.RequestsUsed/info.RequestsTotal*100 > config.warningPercent(90) {
+		status = "warning"
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: status,
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// newCursorRequest builds an Admin API request. Cursor uses the API key as the
+// basic auth username with an empty password.
+func newCursorRequest(config *Configuration, path string, payload any) *http.Request {
+	data, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", "https://api.cursor.com"+path, bytes.NewReader(data))
+	req.SetBasicAuth(config.CursorApiKey, "")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Custom providers (defined in the configuration) =====
+
+// customMaxProviders bounds the definitions fetched on each refresh.
+const customMaxProviders = 20
+
+var customIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// customProvider is one admin-defined quota API: how to call it and where the numbers are
+// in its JSON response. Paths are JSONPath-style, e.g. "$.quota.remaining" or
+// "data[0].usage.total"; a plain dot path such as "quota.remaining" works too. A path
+// of "header:<name>" reads a response header instead, as LLM proxies report rate limits.
+type customProvider struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"` // GET by default
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"` // sent as JSON with POST and PUT
+	Auth    struct {
+		Type     string `json:"type,omitempty"`   // "bearer", "basic" or "header"
+		Token    string `json:"token,omitempty"`  // bearer token, or the header's value
+		Header   string `json:"header,omitempty"` // header name for "header" auth, e.g. X-Api-Key
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+	} `json:"auth"`
+	Used      string `json:"used,omitempty"`
+	Remaining string `json:"remaining,omitempty"`
+	Total     string `json:"total,omitempty"`
+	ResetAt   string `json:"resetAt,omitempty"`
+	Unit      string `json:"unit,omitempty"`     // "requests", "tokens", "credits" or "cost"
+	Currency  string `json:"currency,omitempty"` // of cost, USD by default
+	// Threshold rules: a percentage of the total used, or an absolute amount left
+	WarnPercent    float64  `json:"warnPercent,omitempty"`
+	ErrorPercent   float64  `json:"errorPercent,omitempty"`
+	WarnRemaining  *float64 `json:"warnRemaining,omitempty"`
+	ErrorRemaining *float64 `json:"errorRemaining,omitempty"`
+}
+
+// CustomProviderInfo is what a custom provider's mappings read from its response.
+type CustomProviderInfo struct {
+	Used         float64 `json:"used"`
+	HasUsed      bool    `json:"hasUsed"`
+	Remaining    float64 `json:"remaining"`
+	HasRemaining bool    `json:"hasRemaining"`
+	Total        float64 `json:"total,omitempty"`
+	ResetAt      string  `json:"resetAt,omitempty"`
+	Unit         string  `json:"unit"`
+	Currency     string  `json:"currency,omitempty"`
+	LatencyMs    int64   `json:"latencyMs"`
+}
+
+// usagePercent is the share of the total used, if the total is known.
+func (i CustomProviderInfo) usagePercent() (float64, bool) {
+	if i.Total <= 0 || (!i.HasUsed && !i.HasRemaining) {
+		return 0, false
+	}
+	return i.Used / i.Total * 100, true
+}
+
+func (i CustomProviderInfo) summary(locale string) string {
+	switch {
+	case i.Unit == "cost" && i.Total > 0:
+		return translate(locale, "summary.budget_used", formatMoney(i.Used, i.Currency, 2), formatMoney(i.Total, i.Currency, 0))
+	case i.Unit == "cost" && i.HasRemaining:
+		return translate(locale, "summary.credit_balance", formatMoney(i.Remaining, i.Currency, 2))
+	case i.Unit == "cost":
+		return translate(locale, "summary.spent_total", formatMoney(i.Used, i.Currency, 2))
+	case i.Total > 0:
+		return translate(locale, "summary.custom", formatCount(i.Remaining), formatCount(i.Total), i.Unit)
+	case i.HasRemaining:
+		return translate(locale, "summary.custom_remaining", formatCount(i.Remaining), i.Unit)
+	}
+	return translate(locale, "summary.custom_used", formatCount(i.Used), i.Unit)
+}
+
+func (i CustomProviderInfo) compact(name, locale string) string {
+	switch pct, ok := i.usagePercent(); {
+	case ok:
+		return fmt.Sprintf("%s %.0f%%", name, pct)
+	case i.HasRemaining && i.Unit == "cost":
+		return translate(locale, "compact.balance_left", name, formatMoney(i.Remaining, i.Currency, 0))
+	case i.HasRemaining:
+		return fmt.Sprintf("%s %s %s", name, formatCount(i.Remaining), i.Unit)
+	default:
+		return name
+	}
+}
+
+func (i CustomProviderInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "quota_reset", At: parseTime(i.ResetAt)}}
+}
+
+func (c customProvider) statusID() string {
+	return "custom:" + c.ID
+}
+
+func (c customProvider) statusName() string {
+	if name := strings.TrimSpace(c.Name); name != "" {
+		return name
+	}
+	return c.ID
+}
+
+// customHeaderPath returns the header a "header:<name>" path reads.
+func customHeaderPath(path string) (string, bool) {
+	prefix, name, found := strings.Cut(strings.TrimSpace(path), ":")
+	if !found || !strings.EqualFold(prefix, "header") || strings.TrimSpace(name) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(name), true
+}
+
+// readsBody reports whether any mapping reads the response body rather than a header.
+func (c customProvider) readsBody() bool {
+	for _, path := range []string{c.Used, c.Remaining, c.Total, c.ResetAt} {
+		if _, header := customHeaderPath(path); path != "" && !header {
+			return true
+		}
+	}
+	return false
+}
+
+// customValueAt reads a mapping's value from the response header or body.
+func customValueAt(doc any, header http.Header, path string) (any, bool) {
+	if name, ok := customHeaderPath(path); ok {
+		v := strings.TrimSpace(header.Get(name))
+		return v, v != ""
+	}
+	return jsonValueAt(doc, path)
+}
+
+// customNumberAt is customValueAt for a number, which may also be a numeric string.
+func customNumberAt(doc any, header http.Header, path string) (float64, bool) {
+	switch v, _ := customValueAt(doc, header, path); v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (c customProvider) unit() string {
+	switch c.Unit {
+	case "requests", "tokens", "credits", "cost":
+		return c.Unit
+	}
+	return "credits"
+}
+
+// parseCustomProviders parses the configured definitions. An invalid definition is an
+// error rather than being skipped, so a typo doesn't silently drop a card.
+func parseCustomProviders(raw string) ([]customProvider, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var defs []customProvider
+	if err := json.Unmarshal([]byte(raw), &defs); err != nil {
+		return nil, fmt.Errorf("not a JSON array of providers: %v", err)
+	}
+	if len(defs) > customMaxProviders {
+		return nil, fmt.Errorf("at most %d custom providers", customMaxProviders)
+	}
+	seen := map[string]bool{}
+	for i, d := range defs {
+		switch {
+		case !customIDPattern.MatchString(d.ID):
+			return nil, fmt.Errorf("provider %d: id must be lowercase letters, digits, - or _", i+1)
+		case seen[d.ID]:
+			return nil, fmt.Errorf("provider %q: duplicate id", d.ID)
+		case d.Used == "" && d.Remaining == "":
+			return nil, fmt.Errorf("provider %q: map at least one of used and remaining", d.ID)
+		}
+		if u, err := neturl.Parse(d.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("provider %q: url must be an http or https URL", d.ID)
+		}
+		switch strings.ToUpper(d.Method) {
+		case "", "GET", "POST", "PUT":
+		default:
+			return nil, fmt.Errorf("provider %q: method must be GET, POST or PUT", d.ID)
+		}
+		switch d.Auth.Type {
+		case "", "bearer", "basic":
+		case "header":
+			if d.Auth.Header == "" {
+				return nil, fmt.Errorf("provider %q: header auth needs a header name", d.ID)
+			}
+		default:
+			return nil, fmt.Errorf("provider %q: auth type must be bearer, basic or header", d.ID)
+		}
+		seen[d.ID] = true
+	}
+	return defs, nil
+}
+
+// getCustomStatuses returns one card per custom provider, or a single error card when the
+// definitions can't be parsed.
+func (p *Plugin) getCustomStatuses(config *Configuration) []ServiceStatus {
+	defs, err := parseCustomProviders(config.CustomProviders)
+	if err != nil {
+		return []ServiceStatus{errorStatus("custom", "Custom providers", "error.custom_invalid", err.Error())}
+	}
+	var statuses []ServiceStatus
+	for _, def := range defs {
+		statuses = append(statuses, p.getCustomStatus(config, def))
+	}
+	return statuses
+}
+
+func (p *Plugin) getCustomStatus(config *Configuration, def customProvider) ServiceStatus {
+	id := def.statusID()
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+	result := p.fetchCustomStatus(config, def)
+	if result.Error == "" {
+		p.setCache(id, result)
+	}
+	return result
+}
+
+// fetchCustomStatus calls the provider's API and applies the definition, bypassing the cache.
+func (p *Plugin) fetchCustomStatus(config *Configuration, def customProvider) ServiceStatus {
+	id, name := def.statusID(), def.statusName()
+	client := p.providerClient(id, 15*time.Second)
+	start := time.Now()
+	resp, err := client.Do(newCustomRequest(def))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	// A definition that only reads headers may call an endpoint that doesn't answer JSON
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil && def.readsBody() {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	info, err := readCustomInfo(def, doc, resp.Header, time.Now())
+	if err != nil {
+		return errorStatus(id, name, "error.custom_mapping", err.Error())
+	}
+	info.LatencyMs = time.Since(start).Milliseconds()
+
+	return ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: customStatus(info, def, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+}
+
+// readCustomInfo applies the definition's mappings to the response. Used and remaining
+// are derived from each other when only one is mapped and the total is known.
+func readCustomInfo(def customProvider, doc any, header http.Header, now time.Time) (CustomProviderInfo, error) {
+	info := CustomProviderInfo{Unit: def.unit()}
+	if info.Unit == "cost" {
+		info.Currency = strings.ToUpper(strings.TrimSpace(def.Currency))
+		if info.Currency == "" {
+			info.Currency = baseCurrency
+		}
+	}
+	for _, m := range []struct {
+		path  string
+		value *float64
+		found *bool
+	}{
+		{def.Used, &info.Used, &info.HasUsed},
+		{def.Remaining, &info.Remaining, &info.HasRemaining},
+		{def.Total, &info.Total, nil},
+	} {
+		if m.path == "" {
+			continue
+		}
+		v, ok := customNumberAt(doc, header, m.path)
+		if !ok {
+			return info, fmt.Errorf("no number at %q", m.path)
+		}
+		*m.value = v
+		if m.found != nil {
+			*m.found = true
+		}
+	}
+	if info.Total > 0 {
+		switch {
+		case info.HasUsed && !info.HasRemaining:
+			info.Remaining = info.Total - info.Used
+		case info.HasRemaining && !info.HasUsed:
+			info.Used = info.Total - info.Remaining
+		}
+	}
+	if def.ResetAt != "" {
+		if v, ok := customValueAt(doc, header, def.ResetAt); ok {
+			info.ResetAt = customResetTime(v, now)
+		}
+	}
+	return info, nil
+}
+
+// customResetTime accepts an RFC 3339 time, a Unix timestamp in seconds or milliseconds,
+// or the time left until the reset: a duration such as "6m0s", as in OpenAI-style
+// x-ratelimit-reset headers, or a number of seconds too small to be a timestamp.
+func customResetTime(v any, now time.Time) string {
+	var seconds float64
+	switch t := v.(type) {
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed.UTC().Format(time.RFC3339)
+		}
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			d, err := time.ParseDuration(t)
+			if err != nil || d <= 0 {
+				return ""
+			}
+			return now.Add(d).UTC().Truncate(time.Second).Format(time.RFC3339)
+		}
+		seconds = f
+	case float64:
+		seconds = t
+	default:
+		return ""
+	}
+	switch {
+	case seconds <= 0:
+		return ""
+	case seconds < 1e8:
+		// Under three years, so not a timestamp
+		return now.Add(time.Duration(seconds * float64(time.Second))).UTC().Truncate(time.Second).Format(time.RFC3339)
+	case seconds > 1e11:
+		seconds /= 1000
+	}
+	return time.Unix(int64(math.Round(seconds)), 0).UTC().Format(time.RFC3339)
+}
+
+// customStatus applies the definition's threshold rules, falling back to the warning
+// threshold and an error once nothing is left.
+func customStatus(info CustomProviderInfo, def customProvider, config *Configuration) string {
+	errorPct := def.ErrorPercent
+	if errorPct <= 0 {
+		errorPct = 100
+	}
+	warnPct := def.WarnPercent
+	if warnPct <= 0 {
+		warnPct = config.warningPercent(80)
+	}
+	pct, hasPct := info.usagePercent()
+	switch {
+	case hasPct && pct >= errorPct:
+		return "error"
+	case info.HasRemaining && def.ErrorRemaining != nil && info.Remaining <= *def.ErrorRemaining:
+		return "error"
+	case hasPct && pct > warnPct:
+		return "warning"
+	case info.HasRemaining && def.WarnRemaining != nil && info.Remaining <= *def.WarnRemaining:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newCustomRequest(def customProvider) *http.Request {
+	method := strings.ToUpper(def.Method)
+	if method == "" {
+		method = "GET"
+	}
+	var body *strings.Reader
+	if def.Body != "" && method != "GET" {
+		body = strings.NewReader(def.Body)
+	} else {
+		body = strings.NewReader("")
+	}
+	req, _ := http.NewRequest(method, def.URL, body)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	if def.Body != "" && method != "GET" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range def.Headers {
+		req.Header.Set(k, v)
+	}
+	switch def.Auth.Type {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+def.Auth.Token)
+	case "basic":
+		req.SetBasicAuth(def.Auth.Username, def.Auth.Password)
+	case "header":
+		req.Header.Set(def.Auth.Header, def.Auth.Token)
+	}
+	return req
+}
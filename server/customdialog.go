@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Adding a custom provider from a dialog =====
+
+const (
+	customDialogCallback = "add-provider"
+	// customDraftTTL is how long a tested definition waits for the admin to confirm it.
+	customDraftTTL = 15 * time.Minute
+)
+
+// customDraftKey stores a tested definition until the admin saves or discards it. It holds
+// the credential, so it expires rather than lingering in the KV store.
+func customDraftKey(userID string) string {
+	return "custom_draft_" + userID
+}
+
+// executeAddProviderCommand handles `/ailimits add-provider`.
+func (p *Plugin) executeAddProviderCommand(args *model.CommandArgs, uc userContext) *model.CommandResponse {
+	if !p.isSystemAdmin(args.UserId) {
+		return ephemeralResponse(translate(uc.Locale, "setup.admin_only"))
+	}
+	if err := p.openCustomProviderDialog(args.TriggerId, customProvider{Unit: "credits"}, uc.Locale); err != nil {
+		p.API.LogWarn("Failed to open the custom provider dialog", "error", err.Error())
+	}
+	return &model.CommandResponse{}
+}
+
+// openCustomProviderDialog asks for the definition, prefilled from def. The credential
+// isn't prefilled.
+func (p *Plugin) openCustomProviderDialog(triggerID string, def customProvider, locale string) *model.AppError {
+	text := func(name, labelID, current string, optional bool) model.DialogElement {
+		return model.DialogElement{
+			DisplayName: translate(locale, labelID),
+			Name:        name,
+			Type:        "text",
+			Default:     current,
+			Optional:    optional,
+		}
+	}
+	path := func(name, labelID, current string) model.DialogElement {
+		el := text(name, labelID, current, true)
+		el.HelpText = translate(locale, "customdialog.help_path")
+		return el
+	}
+	authType := def.Auth.Type
+	if authType == "" {
+		authType = "none"
+	}
+
+	id := text("id", "customdialog.field_id", def.ID, false)
+	id.HelpText = translate(locale, "customdialog.help_id")
+	url := text("url", "customdialog.field_url", def.URL, false)
+	url.SubType = "url"
+	elements := []model.DialogElement{
+		id,
+		text("name", "customdialog.field_name", def.Name, true),
+		url,
+		{
+			DisplayName: translate(locale, "customdialog.field_method"),
+			Name:        "method",
+			Type:        "select",
+			Default:     strings.ToUpper(def.Method),
+			Optional:    true,
+			Options: []*model.PostActionOptions{
+				{Text: "GET", Value: "GET"},
+				{Text: "POST", Value: "POST"},
+			},
+		},
+		{
+			DisplayName: translate(locale, "customdialog.field_auth"),
+			Name:        "auth",
+			Type:        "select",
+			Default:     authType,
+			Options: []*model.PostActionOptions{
+				{Text: translate(locale, "customdialog.auth_none"), Value: "none"},
+				{Text: translate(locale, "customdialog.auth_bearer"), Value: "bearer"},
+				{Text: translate(locale, "customdialog.auth_basic"), Value: "basic"},
+				{Text: translate(locale, "customdialog.auth_header"), Value: "header"},
+			},
+		},
+		text("authheader", "customdialog.field_auth_header", def.Auth.Header, true),
+		text("username", "customdialog.field_username", def.Auth.Username, true),
+		{
+			DisplayName: translate(locale, "customdialog.field_token"),
+			Name:        "token",
+			Type:        "text",
+			SubType:     "password",
+			Optional:    true,
+			HelpText:    translate(locale, "customdialog.help_token"),
+		},
+		path("used", "customdialog.field_used", def.Used),
+		path("remaining", "customdialog.field_remaining", def.Remaining),
+		path("total", "customdialog.field_total", def.Total),
+		path("resetat", "customdialog.field_reset", def.ResetAt),
+		{
+			DisplayName: translate(locale, "customdialog.field_unit"),
+			Name:        "unit",
+			Type:        "select",
+			Default:     def.unit(),
+			Options: []*model.PostActionOptions{
+				{Text: translate(locale, "customdialog.unit_credits"), Value: "credits"},
+				{Text: translate(locale, "customdialog.unit_requests"), Value: "requests"},
+				{Text: translate(locale, "customdialog.unit_tokens"), Value: "tokens"},
+				{Text: translate(locale, "customdialog.unit_cost"), Value: "cost"},
+			},
+		},
+	}
+
+	return p.API.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       pluginURL + "/api/v1/admin/customprovider/submit",
+		Dialog: model.Dialog{
+			CallbackId:       customDialogCallback,
+			Title:            translate(locale, "customdialog.title"),
+			IntroductionText: translate(locale, "customdialog.intro"),
+			Elements:         elements,
+			SubmitLabel:      translate(locale, "customdialog.submit"),
+		},
+	})
+}
+
+// customProviderFromSubmission builds a definition from the dialog's fields.
+func customProviderFromSubmission(submission map[string]any) customProvider {
+	field := func(name string) string {
+		return strings.TrimSpace(fmtSubmission(submission[name]))
+	}
+	def := customProvider{
+		ID:        strings.ToLower(field("id")),
+		Name:      field("name"),
+		URL:       field("url"),
+		Used:      field("used"),
+		Remaining: field("remaining"),
+		Total:     field("total"),
+		ResetAt:   field("resetat"),
+		Unit:      field("unit"),
+	}
+	if method := field("method"); method != "GET" {
+		def.Method = method
+	}
+	switch field("auth") {
+	case "bearer":
+		def.Auth.Type, def.Auth.Token = "bearer", field("token")
+	case "basic":
+		def.Auth.Type, def.Auth.Username, def.Auth.Password = "basic", field("username"), field("token")
+	case "header":
+		def.Auth.Type, def.Auth.Header, def.Auth.Token = "header", field("authheader"), field("token")
+	}
+	return def
+}
+
+// handleCustomProviderSubmit validates the definition and runs a test fetch with it. On
+// success the admin gets the values read from the response to confirm before it's saved;
+// otherwise the dialog stays open with the problem.
+func (p *Plugin) handleCustomProviderSubmit(w http.ResponseWriter, r *http.Request, userID string) {
+	var req model.SubmitDialogRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Cancelled {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	locale := p.getUserContext(userID).Locale
+	config := p.getConfiguration()
+	def := customProviderFromSubmission(req.Submission)
+
+	w.Header().Set("Content-Type", "application/json")
+	respond := func(resp model.SubmitDialogResponse) {
+		json.NewEncoder(w).Encode(resp)
+	}
+	existing, err := parseCustomProviders(config.CustomProviders)
+	if err != nil {
+		respond(model.SubmitDialogResponse{Error: translate(locale, "customdialog.existing_invalid", err.Error())})
+		return
+	}
+	for _, d := range existing {
+		if d.ID == def.ID {
+			respond(model.SubmitDialogResponse{Errors: map[string]string{"id": translate(locale, "customdialog.duplicate_id")}})
+			return
+		}
+	}
+	if def.Auth.Type == "header" && def.Auth.Header == "" {
+		respond(model.SubmitDialogResponse{Errors: map[string]string{"authheader": translate(locale, "customdialog.header_required")}})
+		return
+	}
+	raw, _ := json.Marshal([]customProvider{def})
+	if _, err := parseCustomProviders(string(raw)); err != nil {
+		respond(model.SubmitDialogResponse{Error: err.Error()})
+		return
+	}
+
+	result := p.fetchCustomStatus(config, def)
+	if result.Error != "" {
+		respond(model.SubmitDialogResponse{Error: translate(locale, "customdialog.test_failed", result.Error)})
+		return
+	}
+	info, _ := result.Data.(CustomProviderInfo)
+
+	data, _ := json.Marshal(def)
+	if appErr := p.API.KVSetWithExpiry(customDraftKey(userID), data, int64(customDraftTTL/time.Second)); appErr != nil {
+		respond(model.SubmitDialogResponse{Error: appErr.Error()})
+		return
+	}
+
+	action := func(id, labelID string) *model.PostAction {
+		return &model.PostAction{
+			Id:   id,
+			Name: translate(locale, labelID),
+			Type: model.PostActionTypeButton,
+			Integration: &model.PostActionIntegration{
+				URL:     pluginURL + "/api/v1/admin/customprovider/confirm",
+				Context: map[string]any{"action": id},
+			},
+		}
+	}
+	post := &model.Post{
+		ChannelId: req.ChannelId,
+		UserId:    p.botUserID,
+		Message:   customPreviewText(def, info, result.Status, locale),
+	}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{{Actions: []*model.PostAction{
+		action("save", "customdialog.save_button"),
+		action("cancel", "customdialog.cancel_button"),
+	}}})
+	p.API.SendEphemeralPost(userID, post)
+	w.Write([]byte("{}"))
+}
+
+// customPreviewText lists what the test fetch read, for the admin to check the mappings.
+func customPreviewText(def customProvider, info CustomProviderInfo, status, locale string) string {
+	number := func(v float64) string {
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if info.Currency != "" {
+			s += " " + info.Currency
+		}
+		return s
+	}
+	var b strings.Builder
+	b.WriteString(translate(locale, "customdialog.preview", def.statusName(), info.LatencyMs) + "\n")
+	if info.HasUsed || info.Total > 0 && info.HasRemaining {
+		b.WriteString(fmt.Sprintf("- %s: `%s`\n", translate(locale, "customdialog.field_used"), number(info.Used)))
+	}
+	if info.HasRemaining || info.Total > 0 && info.HasUsed {
+		b.WriteString(fmt.Sprintf("- %s: `%s`\n", translate(locale, "customdialog.field_remaining"), number(info.Remaining)))
+	}
+	if info.Total > 0 {
+		b.WriteString(fmt.Sprintf("- %s: `%s`\n", translate(locale, "customdialog.field_total"), number(info.Total)))
+	}
+	if def.ResetAt != "" {
+		reset := info.ResetAt
+		if reset == "" {
+			reset = translate(locale, "customdialog.not_found")
+		}
+		b.WriteString(fmt.Sprintf("- %s: `%s`\n", translate(locale, "customdialog.field_reset"), reset))
+	}
+	b.WriteString(fmt.Sprintf("- %s: `%s`\n", translate(locale, "customdialog.status"), status))
+	b.WriteString(translate(locale, "customdialog.confirm"))
+	return b.String()
+}
+
+// handleCustomProviderConfirm saves or discards the tested definition, from the buttons
+// under the preview.
+func (p *Plugin) handleCustomProviderConfirm(w http.ResponseWriter, r *http.Request, userID string) {
+	var req model.PostActionIntegrationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	locale := p.getUserContext(userID).Locale
+	w.Header().Set("Content-Type", "application/json")
+	reply := func(msgID string, args ...any) {
+		json.NewEncoder(w).Encode(model.PostActionIntegrationResponse{
+			Update: &model.Post{Message: translate(locale, msgID, args...), Props: model.StringInterface{}},
+		})
+	}
+
+	var def customProvider
+	data, appErr := p.API.KVGet(customDraftKey(userID))
+	if appErr != nil || data == nil || json.Unmarshal(data, &def) != nil {
+		reply("customdialog.expired")
+		return
+	}
+	p.API.KVDelete(customDraftKey(userID))
+	if action, _ := req.Context["action"].(string); action != "save" {
+		reply("customdialog.cancelled", def.statusName())
+		return
+	}
+
+	if err := p.addCustomProvider(def); err != nil {
+		reply("customdialog.save_failed", err.Error())
+		return
+	}
+	p.API.LogInfo("Custom provider added", "user_id", userID, "provider", def.ID)
+	reply("customdialog.saved", def.statusName())
+}
+
+// addCustomProvider appends the definition to the custom providers and turns them on. The
+// existing definitions are kept as they were written.
+func (p *Plugin) addCustomProvider(def customProvider) error {
+	current := p.getConfiguration()
+	existing, err := parseCustomProviders(current.CustomProviders)
+	if err != nil {
+		return err
+	}
+	for _, d := range existing {
+		if d.ID == def.ID {
+			return fmt.Errorf("a custom provider with the id %q already exists", def.ID)
+		}
+	}
+	var defs []json.RawMessage
+	if strings.TrimSpace(current.CustomProviders) != "" {
+		if err := json.Unmarshal([]byte(current.CustomProviders), &defs); err != nil {
+			return err
+		}
+	}
+	raw, _ := json.Marshal(def)
+	defs = append(defs, raw)
+	if len(defs) > customMaxProviders {
+		return fmt.Errorf("at most %d custom providers", customMaxProviders)
+	}
+	updated, _ := json.MarshalIndent(defs, "", "  ")
+
+	config := *current
+	config.CustomProviders = string(updated)
+	config.CustomProvidersEnabled = true
+	return p.saveConfiguration(&config)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Billing cycles =====
+
+// openAICycleAnchor is the configured day of the month OpenAI invoices start on, 1 by default.
+func (c *Configuration) openAICycleAnchor() int {
+	day, err := strconv.Atoi(strings.TrimSpace(c.OpenaiCycleAnchorDay))
+	if err != nil || day < 1 || day > 31 {
+		return 1
+	}
+	return day
+}
+
+// billingCycle returns the monthly cycle containing now, for cycles starting on
+// anchorDay at midnight UTC. In months shorter than anchorDay the cycle starts on
+// the last day of the month.
+func billingCycle(now time.Time, anchorDay int) (start, end time.Time) {
+	now = now.UTC()
+	start = anchorDate(now.Year(), now.Month(), anchorDay)
+	if now.Before(start) {
+		start = anchorDate(now.Year(), now.Month()-1, anchorDay)
+	}
+	return start, anchorDate(start.Year(), start.Month()+1, anchorDay)
+}
+
+func anchorDate(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// cyclePeriod labels a cycle: the month for calendar-month cycles ("Oct 2026"),
+// otherwise its first and last day ("Oct 15 – Nov 14").
+func cyclePeriod(start, end time.Time) string {
+	if start.Day() == 1 {
+		return start.Format("Jan 2006")
+	}
+	return start.Format("Jan 2") + " – " + end.AddDate(0, 0, -1).Format("Jan 2")
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Alibaba Cloud DashScope / Model Studio (API key, optional AccessKey) =====
+
+// dashScopeMaxPackagePages bounds the resource package listing, 100 packages per page.
+const dashScopeMaxPackagePages = 5
+
+// DashScopeInfo is the state of a DashScope key and, with an AccessKey for the billing API,
+// the month's postpaid Model Studio spend and the free quotas and resource packages it
+// draws down first.
+type DashScopeInfo struct {
+	Region         string           `json:"region"` // "intl" or "china"
+	Models         int              `json:"models"`
+	HasBilling     bool             `json:"hasBilling"`
+	TotalCost      float64          `json:"totalCost"`
+	Budget         float64          `json:"budget,omitempty"`
+	Currency       string           `json:"currency"`
+	Period         string           `json:"period"`
+	CycleEnd       string           `json:"cycleEnd"`
+	DaysUntilReset int              `json:"daysUntilReset"`
+	Quotas         []DashScopeQuota `json:"quotas,omitempty"`
+	BillingError   string           `json:"billingError,omitempty"`
+}
+
+func (d DashScopeInfo) summary(locale string) string {
+	if !d.HasBilling {
+		return translate(locale, "summary.models_available", d.Models)
+	}
+	text := translate(locale, "summary.spent", formatMoney(d.TotalCost, d.Currency, 2), d.Period)
+	if d.Budget > 0 {
+		text = translate(locale, "summary.spent_budget", formatMoney(d.TotalCost, d.Currency, 2), formatMoney(d.Budget, d.Currency, 0), d.Period)
+	}
+	if len(d.Quotas) > 0 {
+		text += ", " + translate(locale, "summary.dashscope_quota", d.Quotas[0].Name, d.Quotas[0].percent())
+	}
+	return text
+}
+
+func (d DashScopeInfo) compact(name, locale string) string {
+	switch {
+	case d.HasBilling && d.Budget > 0:
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(d.TotalCost, d.Currency, 0), formatMoney(d.Budget, d.Currency, 0))
+	case d.HasBilling:
+		return fmt.Sprintf("%s %s", name, formatMoney(d.TotalCost, d.Currency, 0))
+	default:
+		return name
+	}
+}
+
+func (d DashScopeInfo) usagePercent() (float64, bool) {
+	if d.Budget > 0 {
+		return d.TotalCost / d.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (d DashScopeInfo) resets(time.Time) []ResetInfo {
+	if d.HasBilling {
+		return []ResetInfo{{Kind: "monthly_billing", At: parseTime(d.CycleEnd)}}
+	}
+	return nil
+}
+
+// DashScopeQuota is a free quota or resource package, usually for one model.
+type DashScopeQuota struct {
+	Name      string  `json:"name"`
+	Total     float64 `json:"total"`
+	Remaining float64 `json:"remaining"`
+	Unit      string  `json:"unit"`
+	ExpiresAt string  `json:"expiresAt,omitempty"`
+}
+
+// percent is the share of the quota consumed.
+func (q DashScopeQuota) percent() float64 {
+	if q.Total <= 0 {
+		return 0
+	}
+	return (q.Total - q.Remaining) / q.Total * 100
+}
+
+// dashScopeModelsResponse is GET /compatible-mode/v1/models.
+type dashScopeModelsResponse struct {
+	Data []struct {
+		ID string `json:"id" schema:"required"`
+	} `json:"data" schema:"required"`
+}
+
+// dashScopeBillResponse is BSS QueryBillOverview.
+type dashScopeBillResponse struct {
+	Data struct {
+		BillingCycle string `json:"BillingCycle"`
+		Items        struct {
+			Item []struct {
+				ProductCode  string    `json:"ProductCode" schema:"required"`
+				ProductName  string    `json:"ProductName"`
+				PretaxAmount flexFloat `json:"PretaxAmount" schema:"required"`
+				Currency     string    `json:"Currency"`
+			} `json:"Item" schema:"required"`
+		} `json:"Items" schema:"required"`
+	} `json:"Data" schema:"required"`
+}
+
+// dashScopePackagesResponse is BSS QueryResourcePackageInstances.
+type dashScopePackagesResponse struct {
+	Data struct {
+		TotalCount flexFloat `json:"TotalCount"`
+		Instances  struct {
+			Instance []struct {
+				InstanceID          string    `json:"InstanceId" schema:"required"`
+				Remark              string    `json:"Remark"`
+				PackageType         string    `json:"PackageType"`
+				Status              string    `json:"Status"`
+				TotalAmount         flexFloat `json:"TotalAmount" schema:"required"`
+				TotalAmountUnit     string    `json:"TotalAmountUnit"`
+				RemainingAmount     flexFloat `json:"RemainingAmount" schema:"required"`
+				RemainingAmountUnit string    `json:"RemainingAmountUnit"`
+				ExpiryTime          string    `json:"ExpiryTime"`
+			} `json:"Instance" schema:"required"`
+		} `json:"Instances" schema:"required"`
+	} `json:"Data" schema:"required"`
+}
+
+// dashScopeHosts returns the DashScope API and billing API hosts of the configured site.
+func (c *Configuration) dashScopeHosts() (api, billing string) {
+	if c.DashscopeRegion == "china" {
+		return "dashscope.aliyuncs.com", "business.aliyuncs.com"
+	}
+	return "dashscope-intl.aliyuncs.com", "business.ap-southeast-1.aliyuncs.com"
+}
+
+func (c *Configuration) dashScopeCredentials() aliyunCredentials {
+	return aliyunCredentials{
+		AccessKeyID:     strings.TrimSpace(c.DashscopeAccessKeyId),
+		AccessKeySecret: strings.TrimSpace(c.DashscopeAccessSecret),
+	}
+}
+
+// dashScopeProductCodes are the billing product codes counted as Model Studio spend.
+func (c *Configuration) dashScopeProductCodes() []string {
+	if codes := splitList(c.DashscopeProductCode); len(codes) > 0 {
+		return codes
+	}
+	return []string{"sfm"}
+}
+
+func (p *Plugin) getDashScopeStatus(config *Configuration) ServiceStatus {
+	const id, name = "dashscope", "Alibaba DashScope"
+	if config.DashscopeApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	resp, err := client.Do(newDashScopeModelsRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var models dashScopeModelsResponse
+	drift, err := decodeResponse(body, &models)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := DashScopeInfo{
+		Region:         "intl",
+		Models:         len(models.Data),
+		Currency:       "USD",
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+	if config.DashscopeRegion == "china" {
+		info.Region, info.Currency = "china", "CNY"
+	}
+
+	creds := config.dashScopeCredentials()
+	if creds.AccessKeyID != "" && creds.AccessKeySecret != "" {
+		// The key works, so billing failures only degrade the card
+		if d, err := p.fetchDashScopeBilling(client, config, creds, monthStart, now, &info); err != nil {
+			info.BillingError = err.Error()
+		} else {
+			drift.merge(d)
+			info.HasBilling = true
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+	info.Budget = p.budgetIn(config, id, config.DashscopeMonthlyBudget, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: dashScopeStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// dashScopeStatus follows the budget, and warns when the billing API fails or a free quota
+// is nearly used up, after which the model is billed.
+func dashScopeStatus(info DashScopeInfo, config *Configuration) string {
+	status := budgetStatus(info.TotalCost, info.Budget, config)
+	if status != "ok" {
+		return status
+	}
+	if info.BillingError != "" {
+		return "warning"
+	}
+	for _, q := range info.Quotas {
+		if q.percent() > config.warningPercent(90) {
+			return "warning"
+		}
+	}
+	return "ok"
+}
+
+// fetchDashScopeBilling reads the month's Model Studio spend and the active resource packages.
+func (p *Plugin) fetchDashScopeBilling(client *http.Client, config *Configuration, creds aliyunCredentials, monthStart, now time.Time, info *DashScopeInfo) (schemaDrift, error) {
+	_, host := config.dashScopeHosts()
+	body, err := p.aliyunCall(client, newAliyunRPCRequest(creds, host, "QueryBillOverview", "2017-12-14",
+		map[string]string{"BillingCycle": monthStart.Format("2006-01")}, now))
+	if err != nil {
+		return schemaDrift{}, err
+	}
+	var bill dashScopeBillResponse
+	drift, err := decodeResponse(body, &bill)
+	if err != nil {
+		return drift, err
+	}
+	codes := config.dashScopeProductCodes()
+	for _, item := range bill.Data.Items.Item {
+		for _, code := range codes {
+			if strings.EqualFold(item.ProductCode, code) {
+				info.TotalCost += float64(item.PretaxAmount)
+				if item.Currency != "" {
+					info.Currency = strings.ToUpper(item.Currency)
+				}
+			}
+		}
+	}
+
+	for page := 1; page <= dashScopeMaxPackagePages; page++ {
+		params := map[string]string{"ProductCode": codes[0], "PageNum": strconv.Itoa(page), "PageSize": "100"}
+		body, err := p.aliyunCall(client, newAliyunRPCRequest(creds, host, "QueryResourcePackageInstances", "2017-12-14", params, now))
+		if err != nil {
+			return drift, err
+		}
+		var packages dashScopePackagesResponse
+		d, err := decodeResponse(body, &packages)
+		if err != nil {
+			return drift, err
+		}
+		drift.merge(d)
+		for _, pkg := range packages.Data.Instances.Instance {
+			if pkg.Status != "" && !strings.EqualFold(pkg.Status, "Available") {
+				continue
+			}
+			quota := DashScopeQuota{
+				Name: pkg.Remark, Total: float64(pkg.TotalAmount), Remaining: float64(pkg.RemainingAmount),
+				Unit: pkg.TotalAmountUnit, ExpiresAt: aliyunTime(pkg.ExpiryTime),
+			}
+			if quota.Name == "" {
+				quota.Name = pkg.PackageType
+			}
+			info.Quotas = append(info.Quotas, quota)
+		}
+		if page*100 >= int(packages.Data.TotalCount) {
+			break
+		}
+	}
+	sort.Slice(info.Quotas, func(i, j int) bool { return info.Quotas[i].percent() > info.Quotas[j].percent() })
+	return drift, nil
+}
+
+// aliyunCall sends a signed request and returns the body of a successful response. RPC
+// APIs may report errors with HTTP 200, so the Success flag is checked too.
+func (p *Plugin) aliyunCall(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	var status struct {
+		Code    string `json:"Code"`
+		Message string `json:"Message"`
+		Success *bool  `json:"Success"`
+	}
+	json.Unmarshal(body, &status)
+	if resp.StatusCode != 200 || (status.Success != nil && !*status.Success) {
+		if status.Message != "" {
+			return nil, fmt.Errorf("HTTP %d: %s: %s", resp.StatusCode, status.Code, status.Message)
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+// aliyunTime converts BSS timestamps such as "2025-06-30T16:00:00Z" or
+// "2025-06-30 16:00:00" (UTC) to RFC 3339.
+func aliyunTime(value string) string {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+func newDashScopeModelsRequest(config *Configuration) *http.Request {
+	host, _ := config.dashScopeHosts()
+	req, _ := http.NewRequest("GET", "https://"+host+"/compatible-mode/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.DashscopeApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
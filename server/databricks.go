@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Databricks Model Serving (personal access token, SQL warehouse) =====
+
+// databricksUsageQuery prices the month's model serving usage from the billing system tables
+// at list price, by endpoint and serving type. Foundation Model APIs are the pay-per-token
+// endpoints; provisioned throughput and custom models are other serving types.
+const databricksUsageQuery = `SELECT
+  COALESCE(u.usage_metadata.endpoint_name, '') AS endpoint,
+  COALESCE(u.product_features.serving_type, '') AS serving_type,
+  SUM(u.usage_quantity) AS dbus,
+  SUM(u.usage_quantity * CAST(p.pricing.effective_list.default AS DOUBLE)) AS cost,
+  FIRST(p.currency_code) AS currency
+FROM system.billing.usage u
+JOIN system.billing.list_prices p
+  ON u.sku_name = p.sku_name AND u.cloud = p.cloud AND u.usage_unit = p.usage_unit
+  AND u.usage_start_time >= p.price_start_time
+  AND (p.price_end_time IS NULL OR u.usage_start_time < p.price_end_time)
+WHERE u.billing_origin_product = 'MODEL_SERVING' AND u.usage_date >= :month_start
+GROUP BY 1, 2
+ORDER BY cost DESC`
+
+// DatabricksInfo is the month's model serving spend at list price, with the Foundation Model
+// APIs share and the most expensive endpoints. Billing system tables lag by a few hours.
+type DatabricksInfo struct {
+	TotalCost      float64              `json:"totalCost"`
+	FoundationCost float64              `json:"foundationCost"`
+	DBUs           float64              `json:"dbus"`
+	Budget         float64              `json:"budget,omitempty"`
+	Currency       string               `json:"currency"`
+	Period         string               `json:"period"`
+	CycleEnd       string               `json:"cycleEnd"`
+	DaysUntilReset int                  `json:"daysUntilReset"`
+	TopEndpoints   []DatabricksEndpoint `json:"topEndpoints,omitempty"`
+}
+
+func (d DatabricksInfo) summary(locale string) string {
+	text := translate(locale, "summary.spent", formatMoney(d.TotalCost, d.Currency, 2), d.Period)
+	if d.Budget > 0 {
+		text = translate(locale, "summary.spent_budget", formatMoney(d.TotalCost, d.Currency, 2), formatMoney(d.Budget, d.Currency, 0), d.Period)
+	}
+	if d.FoundationCost > 0 {
+		text += ", " + translate(locale, "summary.databricks_foundation", formatMoney(d.FoundationCost, d.Currency, 2))
+	}
+	return text
+}
+
+func (d DatabricksInfo) compact(name, locale string) string {
+	if d.Budget > 0 {
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(d.TotalCost, d.Currency, 0), formatMoney(d.Budget, d.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(d.TotalCost, d.Currency, 0))
+}
+
+func (d DatabricksInfo) usagePercent() (float64, bool) {
+	if d.Budget > 0 {
+		return d.TotalCost / d.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (d DatabricksInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(d.CycleEnd)}}
+}
+
+// DatabricksEndpoint is one serving endpoint's spend this month.
+type DatabricksEndpoint struct {
+	Name        string  `json:"name"`
+	ServingType string  `json:"servingType,omitempty"` // e.g. FOUNDATION_MODEL, MODEL, GPU_MODEL
+	DBUs        float64 `json:"dbus"`
+	Cost        float64 `json:"cost"`
+}
+
+// databricksStatementResponse is POST /api/2.0/sql/statements with a synchronous wait.
+type databricksStatementResponse struct {
+	StatementID string `json:"statement_id" schema:"required"`
+	Status      struct {
+		State string `json:"state" schema:"required"`
+		Error *struct {
+			ErrorCode string `json:"error_code"`
+			Message   string `json:"message"`
+		} `json:"error"`
+	} `json:"status" schema:"required"`
+	Result struct {
+		DataArray [][]*string `json:"data_array"`
+	} `json:"result"`
+}
+
+func (c *Configuration) databricksHost() string {
+	host := strings.TrimRight(strings.TrimSpace(c.DatabricksHost), "/")
+	if host != "" && !strings.HasPrefix(host, "https://") && !strings.HasPrefix(host, "http://") {
+		host = "https://" + host
+	}
+	return host
+}
+
+func (p *Plugin) getDatabricksStatus(config *Configuration) ServiceStatus {
+	const id, name = "databricks", "Databricks Model Serving"
+	if config.DatabricksToken == "" {
+		return errorStatus(id, name, "error.access_token_missing")
+	}
+	if config.databricksHost() == "" || strings.TrimSpace(config.DatabricksWarehouseId) == "" {
+		return errorStatus(id, name, "error.databricks_warehouse_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 60*time.Second)
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	resp, err := client.Do(newDatabricksStatementRequest(config, monthStart))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var statement databricksStatementResponse
+	drift, err := decodeResponse(body, &statement)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+	switch statement.Status.State {
+	case "SUCCEEDED":
+	case "FAILED":
+		message := "statement failed"
+		if statement.Status.Error != nil {
+			message = statement.Status.Error.Message
+		}
+		return errorStatus(id, name, "error.api", message)
+	default:
+		// A cold warehouse can take longer to start than the wait allows; the next refresh retries
+		return errorStatus(id, name, "error.api", fmt.Sprintf("statement %s is %s", statement.StatementID, strings.ToLower(statement.Status.State)))
+	}
+
+	info := DatabricksInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+	for _, row := range statement.Result.DataArray {
+		if len(row) < 5 {
+			continue
+		}
+		cell := func(i int) string {
+			if row[i] == nil {
+				return ""
+			}
+			return *row[i]
+		}
+		dbus, _ := strconv.ParseFloat(cell(2), 64)
+		cost, _ := strconv.ParseFloat(cell(3), 64)
+		endpoint := DatabricksEndpoint{Name: cell(0), ServingType: cell(1), DBUs: dbus, Cost: cost}
+		if endpoint.Name == "" {
+			endpoint.Name = "(unnamed)"
+		}
+		if currency := cell(4); currency != "" {
+			info.Currency = strings.ToUpper(currency)
+		}
+		info.TotalCost += cost
+		info.DBUs += dbus
+		if endpoint.ServingType == "FOUNDATION_MODEL" {
+			info.FoundationCost += cost
+		}
+		info.TopEndpoints = append(info.TopEndpoints, endpoint)
+	}
+	sort.Slice(info.TopEndpoints, func(i, j int) bool { return info.TopEndpoints[i].Cost > info.TopEndpoints[j].Cost })
+	info.TopEndpoints = info.TopEndpoints[:min(len(info.TopEndpoints), 5)]
+	info.Budget = p.budgetIn(config, id, config.DatabricksMonthlyBudget, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: budgetStatus(info.TotalCost, info.Budget, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+func newDatabricksStatementRequest(config *Configuration, monthStart time.Time) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"statement":       databricksUsageQuery,
+		"warehouse_id":    strings.TrimSpace(config.DatabricksWarehouseId),
+		"wait_timeout":    "50s",
+		"on_wait_timeout": "CANCEL",
+		"disposition":     "INLINE",
+		"format":          "JSON_ARRAY",
+		"parameters": []map[string]string{
+			{"name": "month_start", "value": monthStart.Format("2006-01-02"), "type": "DATE"},
+		},
+	})
+	req, _ := http.NewRequest("POST", config.databricksHost()+"/api/2.0/sql/statements", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+config.DatabricksToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
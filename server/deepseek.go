@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== DeepSeek (API key) =====
+
+// DeepSeekBalanceInfo is the account's prepaid balance, split into topped-up and granted funds.
+type DeepSeekBalanceInfo struct {
+	Available       bool    `json:"available"`
+	Currency        string  `json:"currency"`
+	TotalBalance    float64 `json:"totalBalance"`
+	ToppedUpBalance float64 `json:"toppedUpBalance"`
+	GrantedBalance  float64 `json:"grantedBalance"`
+	LowBalance      float64 `json:"lowBalance,omitempty"`
+}
+
+func (d DeepSeekBalanceInfo) summary(locale string) string {
+	return translate(locale, "summary.deepseek", formatMoney(d.TotalBalance, d.Currency, 2),
+		formatMoney(d.ToppedUpBalance, d.Currency, 2), formatMoney(d.GrantedBalance, d.Currency, 2))
+}
+
+func (d DeepSeekBalanceInfo) compact(name, locale string) string {
+	return translate(locale, "compact.balance_left", name, formatMoney(d.TotalBalance, d.Currency, 0))
+}
+
+// deepSeekBalanceResponse is /user/balance. Balances are decimal strings, one entry per currency.
+type deepSeekBalanceResponse struct {
+	IsAvailable  bool `json:"is_available" schema:"required"`
+	BalanceInfos []struct {
+		Currency        string    `json:"currency" schema:"required"`
+		TotalBalance    flexFloat `json:"total_balance" schema:"required"`
+		GrantedBalance  flexFloat `json:"granted_balance"`
+		ToppedUpBalance flexFloat `json:"topped_up_balance"`
+	} `json:"balance_infos" schema:"required"`
+}
+
+func (p *Plugin) getDeepSeekStatus(config *Configuration) ServiceStatus {
+	const id, name = "deepseek", "DeepSeek"
+	if config.DeepseekApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 10*time.Second)
+	resp, err := client.Do(newDeepSeekRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var raw deepSeekBalanceResponse
+	drift, err := decodeResponse(body, &raw)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info := DeepSeekBalanceInfo{Available: raw.IsAvailable, Currency: baseCurrency}
+	// Accounts normally hold one currency; the first non-empty balance wins otherwise
+	for _, b := range raw.BalanceInfos {
+		info.Currency = strings.ToUpper(b.Currency)
+		info.TotalBalance = float64(b.TotalBalance)
+		info.GrantedBalance = float64(b.GrantedBalance)
+		info.ToppedUpBalance = float64(b.ToppedUpBalance)
+		if info.TotalBalance > 0 {
+			break
+		}
+	}
+	info.LowBalance = p.budgetIn(config, id, config.DeepseekLowBalance, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: deepSeekStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// deepSeekStatus is an error once API calls are refused for lack of funds, and a
+// warning below the configured low balance.
+func deepSeekStatus(info DeepSeekBalanceInfo) string {
+	switch {
+	case !info.Available || info.TotalBalance <= 0:
+		return "error"
+	case info.LowBalance > 0 && info.TotalBalance < info.LowBalance:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newDeepSeekRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.deepseek.com/user/balance", nil)
+	req.Header.Set("Authorization", "Bearer "+config.DeepseekApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
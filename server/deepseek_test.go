@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestDeepSeekBalance(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{DeepseekEnabled: true, DeepseekApiKey: "sk-test"}, map[string]fixture{
+		"https://api.deepseek.com/user/balance": {200, "deepseek/balance.json"},
+	})
+
+	s := p.getDeepSeekStatus(p.getConfiguration())
+	if s.Status != "ok" || s.Error != "" {
+		t.Fatalf("status = %q, error %q; want ok", s.Status, s.Error)
+	}
+	// The empty CNY balance is passed over for the funded one
+	want := DeepSeekBalanceInfo{Available: true, Currency: "USD", TotalBalance: 42.17, ToppedUpBalance: 40, GrantedBalance: 2.17}
+	if info := s.Data.(DeepSeekBalanceInfo); info != want {
+		t.Errorf("balance = %+v\nwant %+v", info, want)
+	}
+}
+
+func TestDeepSeekRejectedKey(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{DeepseekEnabled: true, DeepseekApiKey: "sk-test"}, map[string]fixture{
+		"https://api.deepseek.com/user/balance": {401, "deepseek/balance.json"},
+	})
+
+	if s := p.getDeepSeekStatus(p.getConfiguration()); s.Status != "error" || s.errorID != "error.http" {
+		t.Errorf("status = %q, error %q; want an HTTP error", s.Status, s.Error)
+	}
+}
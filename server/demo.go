@@ -0,0 +1,885 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// ===== Demo mode =====
+
+// demoStatuses returns realistic synthetic data for all providers without calling any API.
+// Usage follows each provider's real window, so values drift, cross thresholds and
+// reset over time, exercising alerts and integrations too.
+func demoStatuses(config *Configuration, now time.Time) []ServiceStatus {
+	utc := now.UTC()
+	monthStart := time.Date(utc.Year(), utc.Month(), 1, 0, 0, 0, 0, time.UTC)
+	nextMonth := monthStart.AddDate(0, 1, 0)
+	monthFraction := utc.Sub(monthStart).Seconds() / nextMonth.Sub(monthStart).Seconds()
+
+	// Fraction of the current fixed-length window that has elapsed, and when it ends
+	window := func(length time.Duration) (float64, time.Time) {
+		start := utc.Truncate(length)
+		return utc.Sub(start).Seconds() / length.Seconds(), start.Add(length)
+	}
+	// Small deterministic wobble so refreshes don't look perfectly linear
+	wobble := func(period float64) float64 {
+		return math.Sin(float64(utc.Unix()) / period)
+	}
+
+	// Augment: credits consumed steadily over the billing cycle
+	augmentTotal := 600000.0
+	augmentUsed := math.Round(augmentTotal * math.Min(monthFraction*0.95+0.02*wobble(3600), 1))
+	augment := AugmentCreditInfo{
+		PlanName:       "Developer",
+		UsageTotal:     augmentTotal,
+		UsageUsed:      augmentUsed,
+		UsageRemaining: augmentTotal - augmentUsed,
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+	}
+	augment.IsLow = augment.UsageRemaining/augmentTotal < 0.1
+
+	// Z.AI: 5-hour token window that fills up and resets
+	zaiFraction, zaiReset := window(5 * time.Hour)
+	zaiTotal := 40000000.0
+	zaiUsed := math.Round(zaiTotal * math.Min(zaiFraction*1.05, 1))
+	zai := ZaiQuotaInfo{
+		PlanName: "GLM Coding Pro", PlanStatus: "VALID",
+		TokensTotal: zaiTotal, TokensUsed: zaiUsed, TokensRemain: zaiTotal - zaiUsed,
+		NextReset: zaiReset.UnixMilli(),
+		McpTotal:  1000, McpUsed: math.Round(1000 * zaiFraction * 0.4),
+	}
+	zai.McpRemain = zai.McpTotal - zai.McpUsed
+
+	// OpenAI: month-to-date spend running slightly ahead of a $500 budget
+	openai := OpenAIUsageInfo{
+		TotalCost:      math.Round(500*monthFraction*1.1*100) / 100,
+		Budget:         500,
+		Currency:       "USD",
+		CreditBalance:  120,
+		CreditSource:   "api",
+		RemainingFunds: floatPtr(120),
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		BucketCount:    utc.Day(),
+	}
+
+	// Anthropic API: month-to-date spend well within a $300 budget on Tier 2, across three workspaces
+	anthropicCost := math.Round(300*monthFraction*0.7*100) / 100
+	anthropic := AnthropicUsageInfo{
+		TotalCost:       anthropicCost,
+		Budget:          300,
+		Currency:        "USD",
+		Period:          monthStart.Format("Jan 2006"),
+		CycleEnd:        nextMonth.Format(time.RFC3339),
+		DaysUntilReset:  int(nextMonth.Sub(utc).Hours() / 24),
+		InputTokens:     math.Round(anthropicCost * 180000),
+		OutputTokens:    math.Round(anthropicCost * 25000),
+		CacheReadTokens: math.Round(anthropicCost * 400000),
+		Tier:            2,
+		HasTier:         true,
+		TierLimit:       anthropicTierSpendLimits[2],
+		Workspaces: []AnthropicWorkspaceSpend{
+			{ID: "wrkspc_01Prod", Name: "Production", Cost: math.Round(anthropicCost*0.62*100) / 100},
+			{ID: "wrkspc_01Eval", Name: "Evals", Cost: math.Round(anthropicCost*0.27*100) / 100},
+			{Name: "Default", Cost: math.Round(anthropicCost*0.11*100) / 100},
+		},
+	}
+
+	// GitHub Copilot: premium requests of a 25-seat Business plan, spilling into overage late in the month
+	copilotUsed := math.Round(25 * 300 * monthFraction * 1.15)
+	copilot := CopilotUsageInfo{
+		Organization: "acme", PlanType: "business",
+		SeatsTotal: 25, SeatsActive: 21, SeatsPending: 1, SeatsInactive: 3, SeatCost: 25 * 19,
+		HasPremiumData: true, PremiumUsed: copilotUsed, PremiumAllowance: 25 * 300,
+		OverageCost: math.Round(math.Max(copilotUsed-25*300, 0)*0.04*100) / 100,
+		Currency:    "USD",
+		CycleEnd:    nextMonth.Format(time.RFC3339),
+	}
+
+	// Cursor: 12-seat team working through its fast requests over the subscription cycle
+	cursorUsed := math.Round(12 * 500 * math.Min(monthFraction*0.9+0.03*wobble(5400), 1))
+	cursor := CursorUsageInfo{
+		SeatsTotal: 12, SeatsActive: 10,
+		RequestsUsed: cursorUsed, RequestsTotal: 12 * 500, RequestsRemain: 12*500 - cursorUsed,
+		SpendCost:  math.Round(40*monthFraction*100) / 100,
+		Currency:   "USD",
+		CycleStart: monthStart.Format(time.RFC3339),
+		CycleEnd:   nextMonth.Format(time.RFC3339),
+		TopMembers: []CursorMemberUsage{
+			{Name: "Alice", Requests: math.Round(cursorUsed * 0.22), Spend: 12.5},
+			{Name: "Bob", Requests: math.Round(cursorUsed * 0.15)},
+		},
+	}
+
+	// Mistral: monthly token limit of a Scale workspace, billed in euros
+	mistralLarge := math.Round(600000000 * monthFraction * 0.5)
+	mistralSmall := math.Round(600000000 * monthFraction * 0.25)
+	mistral := MistralUsageInfo{
+		HasLimits: true, TokensLimit: 1000000000, TokensPerMinute: 2000000,
+		HasUsage: true, TokensUsed: mistralLarge + mistralSmall,
+		TotalCost: math.Round((mistralLarge*2.5/1e6+mistralSmall*0.2/1e6)*100) / 100,
+		Currency:  "EUR",
+		Models: []MistralModelUsage{
+			{Model: "mistral-large-latest", InputTokens: math.Round(mistralLarge * 0.8), OutputTokens: math.Round(mistralLarge * 0.2), Cost: math.Round(mistralLarge*2.5/1e6*100) / 100},
+			{Model: "mistral-small-latest", InputTokens: math.Round(mistralSmall * 0.8), OutputTokens: math.Round(mistralSmall * 0.2), Cost: math.Round(mistralSmall*0.2/1e6*100) / 100},
+		},
+		Period:   monthStart.Format("Jan 2006"),
+		CycleEnd: nextMonth.Format(time.RFC3339),
+	}
+	mistral.TokensRemaining = mistral.TokensLimit - mistral.TokensUsed
+
+	// Groq: free tier daily requests and per-minute tokens
+	dayFraction, dayReset := window(24 * time.Hour)
+	minuteFraction, minuteReset := window(time.Minute)
+	groq := GroqUsageInfo{Models: []GroqModelLimits{
+		{
+			Model:         "llama-3.3-70b-versatile",
+			RequestsLimit: 1000, RequestsRemaining: 1000 - math.Round(1000*dayFraction*0.8),
+			RequestsReset: dayReset.Format(time.RFC3339),
+			TokensLimit:   12000, TokensRemaining: 12000 - math.Round(12000*minuteFraction*0.5),
+			TokensReset: minuteReset.Format(time.RFC3339),
+		},
+		{
+			Model:         "llama-3.1-8b-instant",
+			RequestsLimit: 14400, RequestsRemaining: 14400 - math.Round(14400*dayFraction*0.3),
+			RequestsReset: dayReset.Format(time.RFC3339),
+			TokensLimit:   6000, TokensRemaining: 6000 - math.Round(6000*minuteFraction*0.2),
+			TokensReset: minuteReset.Format(time.RFC3339),
+		},
+	}}
+
+	// DeepSeek: prepaid balance drawn down over the month, dipping below the low balance near the end
+	deepseek := DeepSeekBalanceInfo{
+		Available:      true,
+		Currency:       "CNY",
+		GrantedBalance: 10,
+		LowBalance:     50,
+	}
+	deepseek.ToppedUpBalance = math.Round((500-500*monthFraction*0.95)*100) / 100
+	deepseek.TotalBalance = deepseek.ToppedUpBalance + deepseek.GrantedBalance
+
+	// Perplexity: configured tier and balance
+	perplexity := PerplexityInfo{
+		Tier: 2, HasTier: true, NextTierSpend: perplexityTierThresholds[3],
+		CreditBalance: 84.2, HasBalance: true, LowBalance: 20,
+		Currency: "USD",
+	}
+
+	// Together AI: configured balance
+	together := TogetherInfo{CreditBalance: 36.5, HasBalance: true, LowBalance: 25, Currency: "USD", Models: 142}
+
+	// AWS Bedrock: month-to-date spend against a $1000 budget
+	bedrockCost := math.Round(1000*monthFraction*0.85*100) / 100
+	bedrock := BedrockUsageInfo{
+		TotalCost: bedrockCost, Budget: 1000, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		Region:         "us-east-1",
+		HasMetrics:     true,
+		Invocations:    math.Round(bedrockCost * 40),
+		InputTokens:    math.Round(bedrockCost * 220000),
+		OutputTokens:   math.Round(bedrockCost * 30000),
+	}
+
+	// Google Vertex AI: billing export spend and quota peaks, per model in one of two projects
+	vertexCost := math.Round(600*monthFraction*0.7*100) / 100
+	vertex := VertexUsageInfo{
+		TotalCost: vertexCost, HasCost: true, Budget: 600, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		Projects: []VertexProjectUsage{
+			{
+				ProjectID: "ml-prod", Cost: math.Round(vertexCost*0.8*100) / 100, HasQuota: true,
+				QuotaMetric: "aiplatform.googleapis.com/generate_content_requests_per_minute_per_project_per_base_model",
+				Location:    "us-central1", QuotaUsage: 152, QuotaLimit: 200, QuotaPercent: 76,
+				Models: []VertexModelQuota{
+					{Metric: "aiplatform.googleapis.com/generate_content_requests_per_minute_per_project_per_base_model", Model: "gemini-2.5-pro", Location: "us-central1", Window: "minute", Usage: 152, Limit: 200, Percent: 76},
+					{Metric: "aiplatform.googleapis.com/generate_content_requests_per_minute_per_project_per_base_model", Model: "gemini-2.5-flash", Location: "us-central1", Window: "minute", Usage: 410, Limit: 1000, Percent: 41},
+				},
+			},
+			{
+				ProjectID: "ml-staging", Cost: math.Round(vertexCost*0.2*100) / 100, HasQuota: true,
+				QuotaMetric: "aiplatform.googleapis.com/online_prediction_requests_per_base_model",
+				Location:    "europe-west4", QuotaUsage: 12, QuotaLimit: 300, QuotaPercent: 4,
+			},
+		},
+	}
+
+	// Google Gemini API: an AI Studio key, with the daily quota of the key's project
+	gemini := GeminiInfo{
+		HasKey: true, Models: 38, Project: "ai-studio-demo", HasQuota: true,
+		DayReset: vertexQuotaDayStart(now).AddDate(0, 0, 1).UTC().Format(time.RFC3339),
+		Quotas: []VertexModelQuota{
+			{Metric: "generativelanguage.googleapis.com/generate_requests_per_model_per_day", Model: "gemini-2.5-pro", Window: "day", Usage: 612, Limit: 1000, Percent: 61.2},
+			{Metric: "generativelanguage.googleapis.com/generate_requests_per_model", Model: "gemini-2.5-flash", Window: "minute", Usage: 420, Limit: 1000, Percent: 42},
+		},
+	}
+
+	// Azure OpenAI: two resources, the production GPT-4o deployment busy against its tokens per minute
+	azureOpenAI := AzureOpenAIInfo{
+		SubscriptionID: "00000000-0000-0000-0000-000000000000", Accounts: 2,
+		Deployments: []AzureOpenAIDeployment{
+			{Account: "acme-openai-eastus", Location: "eastus", Name: "gpt-4o-prod", Model: "gpt-4o", ModelVersion: "2024-11-20", SKU: "GlobalStandard", Capacity: 450,
+				TokensPerMinute: 450000, RequestsPerMinute: 4500, TokensUsed: 338000, RequestsUsed: 1210, Percent: 338000.0 / 450000 * 100},
+			{Account: "acme-openai-eastus", Location: "eastus", Name: "text-embedding", Model: "text-embedding-3-large", ModelVersion: "1", SKU: "Standard", Capacity: 350,
+				TokensPerMinute: 350000, RequestsPerMinute: 2100, TokensUsed: 96000, RequestsUsed: 640, Percent: 640.0 / 2100 * 100},
+			{Account: "acme-openai-swedencentral", Location: "swedencentral", Name: "o4-mini", Model: "o4-mini", ModelVersion: "2025-04-16", SKU: "DataZoneStandard", Capacity: 100,
+				TokensPerMinute: 100000, RequestsPerMinute: 100, TokensUsed: 8200, RequestsUsed: 9, Percent: 9},
+		},
+	}
+
+	// xAI: prepaid credit with postpaid spend under the team's spending limit
+	xai := XaiUsageInfo{
+		TeamID: "demo-team", KeyName: "mattermost", HasBilling: true,
+		CreditBalance: 42.75, MonthlySpend: math.Round(150*monthFraction*0.6*100) / 100, SpendingLimit: 150, LowBalance: 20,
+		Currency:       "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		Tier:           "Tier 2",
+	}
+
+	// Windsurf: 15 seats of 500 prompt credits with an add-on pack in reserve
+	windsurfUsed := math.Round(15 * 500 * monthFraction * 0.9)
+	windsurf := WindsurfUsageInfo{
+		Seats: 15, CreditsPerSeat: 500, CreditsTotal: 15 * 500,
+		CreditsUsed: windsurfUsed, CreditsRemain: 15*500 - windsurfUsed,
+		AddOnAvailable: 1000,
+		CycleStart:     monthStart.Format(time.RFC3339),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		TopMembers: []WindsurfMemberUsage{
+			{Name: "Dana", Credits: math.Round(windsurfUsed * 0.18)},
+			{Name: "Eve", Credits: math.Round(windsurfUsed * 0.12)},
+			{Name: "Frank", Credits: math.Round(windsurfUsed * 0.09)},
+		},
+	}
+
+	// Tabnine: configured seats with an annual renewal in two months
+	tabnineRenewal := nextMonth.AddDate(0, 1, 0)
+	tabnine := TabnineInfo{
+		Plan: "Enterprise", SeatsPurchased: 40, SeatsAssigned: 37,
+		RenewalDate:      tabnineRenewal.Format(time.RFC3339),
+		DaysUntilRenewal: int(tabnineRenewal.Sub(utc).Hours() / 24),
+	}
+
+	// JetBrains AI: Pro and Ultimate seats with an annual renewal
+	jetbrainsRenewal := monthStart.AddDate(1, 0, 0)
+	jetbrains := JetBrainsAIInfo{
+		SeatsTotal: 30, SeatsAssigned: 26, CreditsAllowance: 22*10 + 4*35,
+		Products: []JetBrainsAIProduct{
+			{Name: "JetBrains AI Pro", Total: 25, Assigned: 22, CreditsPerSeat: 10},
+			{Name: "JetBrains AI Ultimate", Total: 5, Assigned: 4, CreditsPerSeat: 35},
+		},
+		RenewalDate: jetbrainsRenewal.Format(time.RFC3339),
+	}
+
+	// Cerebras: free tier daily quotas, one model close to its token cap
+	cerebras := CerebrasUsageInfo{Models: []CerebrasModelLimits{
+		{
+			Model:         "llama-3.3-70b",
+			RequestsLimit: 14400, RequestsRemaining: 14400 - math.Round(14400*dayFraction*0.3),
+			HasDailyTokens: true, TokensDayLimit: 1000000, TokensDayRemaining: 1000000 - math.Round(1000000*dayFraction*0.95),
+			DayReset:          dayReset.Format(time.RFC3339),
+			TokensMinuteLimit: 60000, TokensMinuteRemaining: 52000,
+		},
+		{
+			Model:         "qwen-3-32b",
+			RequestsLimit: 14400, RequestsRemaining: 14400 - math.Round(14400*dayFraction*0.1),
+			HasDailyTokens: true, TokensDayLimit: 1000000, TokensDayRemaining: 1000000 - math.Round(1000000*dayFraction*0.2),
+			DayReset:          dayReset.Format(time.RFC3339),
+			TokensMinuteLimit: 60000, TokensMinuteRemaining: 60000,
+		},
+	}}
+
+	// SambaNova: free tier with a daily request cap on the large model
+	sambanova := SambaNovaUsageInfo{Tier: "Free", Models: []SambaNovaModelLimits{
+		{
+			Model:       "Meta-Llama-3.3-70B-Instruct",
+			MinuteLimit: 20, MinuteRemaining: 18,
+			HasDaily: true, DayLimit: 400, DayRemaining: 400 - math.Round(400*dayFraction*0.85),
+			DayReset: dayReset.Format(time.RFC3339),
+		},
+		{
+			Model:       "DeepSeek-V3-0324",
+			MinuteLimit: 20, MinuteRemaining: 20,
+			HasDaily: true, DayLimit: 400, DayRemaining: 400 - math.Round(400*dayFraction*0.2),
+			DayReset: dayReset.Format(time.RFC3339),
+		},
+	}}
+
+	// Self-hosted: an Ollama box with one of its models warm
+	selfhosted := SelfHostedInfo{
+		ServerType: "ollama", Version: "0.6.5", LatencyMs: 12, Loaded: 1,
+		Models: []SelfHostedModel{
+			{Name: "qwen2.5-coder:14b", Size: 9.0e9, Loaded: true, VRAM: 10.2e9, ExpiresAt: utc.Add(4 * time.Minute).Format(time.RFC3339)},
+			{Name: "llama3.1:8b", Size: 4.9e9},
+			{Name: "nomic-embed-text:latest", Size: 0.27e9},
+		},
+		Throughput: &SelfHostedThroughput{Model: "qwen2.5-coder:14b", TokensPerSecond: 38.6, PromptTokensPerSecond: 412},
+	}
+
+	// LiteLLM: team budgets on the proxy, one team nearly at its monthly cap
+	litellm := LiteLLMBudgetInfo{
+		TotalSpend: math.Round(2140*monthFraction*100) / 100, Currency: "USD", KeysTotal: 38,
+		Teams: []LiteLLMBudget{
+			{ID: "team-platform", Name: "platform", Spend: math.Round(930*monthFraction*100) / 100, MaxBudget: 1000, ResetAt: nextMonth.Format(time.RFC3339)},
+			{ID: "team-data", Name: "data-science", Spend: math.Round(820*monthFraction*100) / 100, MaxBudget: 1500, ResetAt: nextMonth.Format(time.RFC3339)},
+			{ID: "team-support", Name: "support-bots", Spend: math.Round(390*monthFraction*100) / 100},
+		},
+		Keys: []LiteLLMBudget{
+			{ID: "demo-key-1", Name: "ci-code-review", Spend: math.Round(176*monthFraction*100) / 100, MaxBudget: 200, ResetAt: nextMonth.Format(time.RFC3339)},
+			{ID: "demo-key-2", Name: "sk-...x7Qa", Spend: math.Round(41*monthFraction*100) / 100, MaxBudget: 100, ResetAt: nextMonth.Format(time.RFC3339)},
+		},
+	}
+
+	// Helicone: gateway-wide month-to-date spend with a budget and top users
+	heliconeCost := math.Round(1500*monthFraction*0.6*100) / 100
+	helicone := HeliconeUsageInfo{
+		TotalCost: heliconeCost, Budget: 1500, Currency: "USD",
+		Period:           monthStart.Format("Jan 2006"),
+		CycleEnd:         nextMonth.Format(time.RFC3339),
+		DaysUntilReset:   int(nextMonth.Sub(utc).Hours() / 24),
+		Requests:         math.Round(heliconeCost * 55),
+		PromptTokens:     math.Round(heliconeCost * 180000),
+		CompletionTokens: math.Round(heliconeCost * 25000),
+		TopUsers: []HeliconeUserUsage{
+			{User: "support-bot", Requests: math.Round(heliconeCost * 30), Cost: math.Round(heliconeCost*0.45*100) / 100},
+			{User: "alice@example.com", Requests: math.Round(heliconeCost * 8), Cost: math.Round(heliconeCost*0.2*100) / 100},
+			{User: "code-review-ci", Requests: math.Round(heliconeCost * 6), Cost: math.Round(heliconeCost*0.15*100) / 100},
+		},
+	}
+
+	// Gateway: a corporate proxy reporting the team's spend against its cap
+	gateway := GatewayInfo{
+		Models: 14, LatencyMs: 48,
+		HasRateLimits: true, RequestsLimit: 600, RequestsRemaining: 571,
+		HasUsage: true, Used: math.Round(500*monthFraction*0.5*100) / 100, Limit: 500, Unit: "cost", Currency: "USD",
+	}
+
+	// ElevenLabs: Creator plan character quota, resetting with the subscription month
+	elevenlabs := ElevenLabsInfo{
+		Tier: "creator", Status: "active",
+		CharactersUsed: math.Round(100000 * monthFraction * 0.9), CharactersLimit: 100000,
+		NextReset:  nextMonth.Format(time.RFC3339),
+		VoicesUsed: 7, VoicesLimit: 30,
+	}
+
+	// AssemblyAI: meeting transcription spend against a prepaid balance
+	assemblyaiHours := math.Round(420*monthFraction*10) / 10
+	assemblyai := AssemblyAIUsageInfo{
+		Hours: assemblyaiHours, Transcripts: int(assemblyaiHours * 1.6),
+		TotalCost: math.Round(assemblyaiHours*0.15*100) / 100, HourlyRate: 0.15, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		CreditBalance:  math.Round((150-assemblyaiHours*0.15)*100) / 100, HasBalance: true, LowBalance: 50,
+	}
+
+	// Supermaven: a small team plan renewing monthly
+	supermavenRenewal := utc.AddDate(0, 0, 12)
+	supermaven := SupermavenInfo{
+		Plan: "Team", PlanStatus: "active", SeatsPurchased: 10, SeatsAssigned: 8,
+		RenewalDate:      supermavenRenewal.Format(time.RFC3339),
+		DaysUntilRenewal: 12,
+	}
+
+	// Amazon Q Developer: Pro seats with agentic requests pooled against the per-user limit
+	amazonq := AmazonQInfo{
+		TotalCost: math.Round(24*19*monthFraction*100) / 100, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		Region:         "us-east-1",
+		HasSeats:       true, Seats: 24,
+		HasMetrics: true, Requests: math.Round(24 * 1400 * monthFraction),
+		AgenticRequests: math.Round(24 * 1000 * monthFraction * 0.7), AgenticLimit: 1000,
+	}
+
+	// Moonshot AI: mainland platform balance with vouchers used first
+	moonshot := MoonshotBalanceInfo{
+		Currency: "CNY", CashBalance: 300, LowBalance: 100, Tier: "Tier2",
+		Concurrency: 100, RequestsPerMinute: 500, TokensPerMinute: 3000000,
+	}
+	moonshot.VoucherBalance = math.Round(math.Max(50-400*monthFraction, 0)*100) / 100
+	moonshot.AvailableBalance = moonshot.CashBalance + moonshot.VoucherBalance
+
+	// DashScope: international site spend after the new-user free quotas run low
+	dashscope := DashScopeInfo{
+		Region: "intl", Models: 86, HasBilling: true,
+		TotalCost: math.Round(200*monthFraction*0.55*100) / 100, Budget: 200, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		Quotas: []DashScopeQuota{
+			{Name: "qwen-max", Total: 1000000, Remaining: math.Round(1000000 * (1 - math.Min(0.6+monthFraction*0.4, 1))), Unit: "tokens", ExpiresAt: monthStart.AddDate(0, 4, 0).Format(time.RFC3339)},
+			{Name: "qwen-plus", Total: 1000000, Remaining: math.Round(1000000 * (1 - monthFraction*0.5)), Unit: "tokens", ExpiresAt: monthStart.AddDate(0, 4, 0).Format(time.RFC3339)},
+		},
+	}
+
+	// AI21 Labs: trial credit running out in a few days
+	ai21Ends := utc.AddDate(0, 0, 5)
+	ai21 := AI21Info{Plan: "Trial", TrialEnds: ai21Ends.Format(time.RFC3339), DaysLeft: 5}
+
+	// fal.ai: image generation drawing down prepaid credits
+	falSpend := math.Round(320*monthFraction*100) / 100
+	fal := FalInfo{
+		CreditBalance: math.Round((400-falSpend)*100) / 100, LowBalance: 100, Currency: "USD",
+		HasUsage: true, MonthlySpend: falSpend,
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		TopEndpoints: []FalEndpointUse{
+			{Endpoint: "fal-ai/flux-pro/v1.1", Quantity: math.Round(falSpend * 0.6 / 0.04), Unit: "image", Cost: math.Round(falSpend*0.6*100) / 100},
+			{Endpoint: "fal-ai/kling-video/v2/master", Quantity: math.Round(falSpend * 0.3 / 1.4), Unit: "video", Cost: math.Round(falSpend*0.3*100) / 100},
+			{Endpoint: "fal-ai/flux/dev", Quantity: math.Round(falSpend * 0.1 / 0.025), Unit: "image", Cost: math.Round(falSpend*0.1*100) / 100},
+		},
+	}
+
+	// RunPod: two GPU pods and a serverless endpoint with a warm worker
+	runpodPods := []RunPodPod{
+		{Name: "llama-finetune", GPU: "H100 80GB HBM3", GPUCount: 2, CostPerHr: 5.38},
+		{Name: "comfyui", GPU: "RTX 4090", GPUCount: 1, CostPerHr: 0.69},
+	}
+	runpodBurn := 5.38 + 0.69 + 0.79
+	runpodBalance := math.Round((900-runpodBurn*24*30*monthFraction*0.6)*100) / 100
+	runpod := RunPodInfo{
+		CreditBalance: runpodBalance, LowBalance: 200, SpendPerHour: runpodBurn, PodSpendPerHour: 5.38 + 0.69,
+		RunningPods: len(runpodPods), Endpoints: 2, MinWorkers: 1, Pods: runpodPods,
+	}
+	if runpodBalance > 0 {
+		runpod.RunwayHours = runpodBalance / runpodBurn
+	}
+
+	// Yandex Cloud: a prepaid billing account in roubles
+	yandexSpend := math.Round(42000*monthFraction*100) / 100
+	yandex := YandexInfo{
+		AccountName: "ai-team", Active: true, Balance: math.Round((60000-yandexSpend)*100) / 100,
+		LowBalance: 10000, Currency: "RUB", MonthlySpend: yandexSpend,
+		TrackedSince:   monthStart.Format(time.RFC3339),
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+	}
+
+	// Custom provider: an in-house model platform's daily request quota
+	internalQuota := CustomProviderInfo{
+		Used: math.Round(5000 * dayFraction * 0.7), HasUsed: true, Total: 5000, Unit: "requests",
+		ResetAt: dayReset.Format(time.RFC3339), LatencyMs: 35,
+	}
+	internalQuota.Remaining, internalQuota.HasRemaining = internalQuota.Total-internalQuota.Used, true
+
+	// GigaChat: prepaid token packages
+	gigachat := GigaChatInfo{
+		Scope: "GIGACHAT_API_B2B", LowTokens: 500000,
+		Packages: []GigaChatPackage{
+			{Usage: "GigaChat", Remaining: math.Round(20000000 * (1 - monthFraction*0.8))},
+			{Usage: "GigaChat-Max", Remaining: math.Round(3000000 * (1 - math.Min(monthFraction*1.1, 0.97)))},
+			{Usage: "GigaChat-Pro", Remaining: math.Round(5000000 * (1 - monthFraction*0.6))},
+			{Usage: "embeddings", Remaining: math.Round(50000000 * (1 - monthFraction*0.2))},
+		},
+	}
+
+	// Reka: balance entered by hand
+	reka := RekaInfo{CreditBalance: 18.4, HasBalance: true, LowBalance: 20, Currency: "USD", Models: 4}
+
+	// Upstage: balance entered by hand, the month's usage taken from how it fell
+	upstageUsed := math.Round(64*monthFraction*100) / 100
+	upstage := UpstageInfo{
+		Models: 9, HasBalance: true, CreditBalance: 150 - upstageUsed, LowBalance: 25,
+		BalanceUpdated: utc.Add(-6 * time.Hour).Format(time.RFC3339),
+		MonthlyUsage:   upstageUsed, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+	}
+
+	// watsonx.ai: pay-as-you-go Essentials plan with a budget
+	watsonxUnits := math.Round(18500 * monthFraction)
+	watsonxHours := math.Round(42*monthFraction*10) / 10
+	watsonxUnitCost := math.Round(watsonxUnits*0.01*100) / 100
+	watsonxHourCost := math.Round(watsonxHours*1.02*100) / 100
+	watsonx := WatsonxInfo{
+		Plan: "Essentials", ResourceUnits: watsonxUnits, CapacityHours: watsonxHours,
+		TotalCost: watsonxUnitCost + watsonxHourCost, Budget: 250, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		Metrics: []WatsonxMetric{
+			{Metric: "RESOURCE_UNITS", Name: "Resource Units", Quantity: watsonxUnits, Unit: "RESOURCE_UNITS", Cost: watsonxUnitCost},
+			{Metric: "CAPACITY_UNIT_HOURS", Name: "Capacity Unit-Hours", Quantity: watsonxHours, Unit: "CAPACITY_UNIT_HOURS", Cost: watsonxHourCost},
+		},
+	}
+
+	// Databricks: pay-per-token Foundation Model APIs plus a custom GPU endpoint
+	databricksFM := math.Round(640*monthFraction*100) / 100
+	databricksGPU := math.Round(410*monthFraction*100) / 100
+	databricks := DatabricksInfo{
+		TotalCost: databricksFM + databricksGPU, FoundationCost: databricksFM,
+		DBUs: math.Round((databricksFM+databricksGPU)/0.07), Budget: 1500, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+		TopEndpoints: []DatabricksEndpoint{
+			{Name: "databricks-meta-llama-3-3-70b-instruct", ServingType: "FOUNDATION_MODEL", DBUs: math.Round(databricksFM * 0.7 / 0.07), Cost: math.Round(databricksFM*0.7*100) / 100},
+			{Name: "support-classifier", ServingType: "GPU_MODEL", DBUs: math.Round(databricksGPU / 0.07), Cost: databricksGPU},
+			{Name: "databricks-gte-large-en", ServingType: "FOUNDATION_MODEL", DBUs: math.Round(databricksFM * 0.3 / 0.07), Cost: math.Round(databricksFM*0.3*100) / 100},
+		},
+	}
+
+	// NVIDIA NIM: trial credits entered by hand
+	nvidia := NvidiaInfo{
+		Models: 187, HasCredits: true, CreditsLeft: math.Round(5000 * (1 - math.Min(monthFraction*0.9, 0.98))), CreditsTotal: 5000,
+		CreditsUpdated: utc.Add(-26 * time.Hour).Format(time.RFC3339), CreditsPerDay: 150,
+	}
+	nvidia.DaysLeft = nvidia.CreditsLeft / nvidia.CreditsPerDay
+
+	// Vercel AI Gateway: prepaid credits on top of the free monthly credit
+	vercelSpend := math.Round(85*monthFraction*100) / 100
+	vercel := VercelGatewayInfo{
+		Balance: math.Round((120-vercelSpend)*100) / 100, LowBalance: 25, TotalUsed: 412.8 + vercelSpend, MonthlySpend: vercelSpend,
+		TrackedSince: monthStart.Format(time.RFC3339), Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+	}
+
+	// OpenRouter: prepaid credits, with a monthly limit on the monitored key
+	openRouterSpend := math.Round(140*monthFraction*100) / 100
+	openRouter := OpenRouterInfo{
+		Label: "sk-or-v1-a3f…91c", KeyUsage: 1284.5 + openRouterSpend,
+		UsageDaily: math.Round(openRouterSpend/math.Max(float64(utc.Day()), 1)*100) / 100, UsageWeekly: math.Round(min(openRouterSpend, 32.4)*100) / 100,
+		UsageMonthly: openRouterSpend, KeyLimit: floatPtr(200), KeyLimitRemaining: floatPtr(200 - openRouterSpend), LimitReset: "monthly",
+		HasCredits: true, TotalCredits: 2000, TotalUsage: 1590.2 + openRouterSpend, Balance: math.Round((409.8-openRouterSpend)*100) / 100,
+		LowBalance: 50, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+	}
+
+	// Portkey: three workspaces, production close to its budget
+	portkey := PortkeyInfo{
+		Workspaces: []PortkeyWorkspace{
+			{Slug: "production", Name: "Production", Requests: math.Round(1840000 * monthFraction), Cost: math.Round(1720*monthFraction*100) / 100, Budget: 1800},
+			{Slug: "research", Name: "Research", Requests: math.Round(264000 * monthFraction), Cost: math.Round(610*monthFraction*100) / 100, Budget: 1000},
+			{Slug: "internal-tools", Name: "Internal tools", Requests: math.Round(95000 * monthFraction), Cost: math.Round(84*monthFraction*100) / 100},
+		},
+		Currency:       "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+	}
+	for _, w := range portkey.Workspaces {
+		portkey.TotalRequests += w.Requests
+		portkey.TotalCost += w.Cost
+	}
+
+	// GitHub Models: Copilot Business allowances, the high tier running short
+	githubModels := GithubModelsInfo{Plan: "business", Organization: "acme-corp", Tiers: []GithubModelsTier{
+		{
+			Tier: "high", Model: "openai/gpt-4.1", CatalogModels: 9,
+			RequestsPerMinute: 10, RequestsPerDay: 100, TokensPerRequest: 8000, Concurrent: 2,
+			RequestsLimit: 100, RequestsRemaining: 100 - math.Round(100*math.Min(dayFraction*1.1, 0.97)),
+			RequestsReset: dayReset.Format(time.RFC3339),
+		},
+		{
+			Tier: "low", Model: "openai/gpt-4.1-mini", CatalogModels: 21,
+			RequestsPerMinute: 15, RequestsPerDay: 300, TokensPerRequest: 8000, Concurrent: 5,
+			RequestsLimit: 300, RequestsRemaining: 300 - math.Round(300*dayFraction*0.45),
+			RequestsReset: dayReset.Format(time.RFC3339),
+		},
+	}}
+
+	// Claude: 5-hour and 7-day windows
+	fiveHour, reset5h := window(5 * time.Hour)
+	weekStart := time.Date(utc.Year(), utc.Month(), utc.Day()-int(utc.Weekday()), 0, 0, 0, 0, time.UTC)
+	sevenDay := utc.Sub(weekStart).Hours() / (7 * 24)
+	claude := ClaudeUsageInfo{
+		Utilization5h: math.Round(math.Min(fiveHour*110+5*wobble(900), 100)),
+		Reset5h:       reset5h.Format(time.RFC3339),
+		Utilization7d: math.Round(math.Min(sevenDay*95, 100)),
+		Reset7d:       weekStart.AddDate(0, 0, 7).Format(time.RFC3339),
+		SonnetUtil:    math.Round(sevenDay * 60),
+		OpusUtil:      math.Round(math.Min(sevenDay*130, 100)),
+		HasData:       true,
+	}
+
+	// Codex: a ChatGPT Plus plan, used more lightly than claude.ai
+	codexShort, codexReset := window(5 * time.Hour)
+	codex := CodexUsageInfo{
+		Plan:                 "plus",
+		PrimaryUsed:          math.Round(codexShort*70 + 5 + 5*wobble(1300)),
+		PrimaryWindowMinutes: 300,
+		PrimaryReset:         codexReset.Format(time.RFC3339),
+		WeeklyUsed:           math.Round(math.Min(sevenDay*75, 100)),
+		WeeklyWindowMinutes:  7 * 24 * 60,
+		WeeklyReset:          weekStart.AddDate(0, 0, 7).Format(time.RFC3339),
+		HasData:              true,
+	}
+
+	// ChatGPT: a shared Team account whose reasoning model hit its message cap
+	chatgpt := ChatGPTUsageInfo{
+		Plan:         "team",
+		RenewsAt:     nextMonth.Format(time.RFC3339),
+		CappedModels: []ChatGPTModelCap{{Model: "o3", ResetsAfter: utc.Add(2*time.Hour + 40*time.Minute).Format(time.RFC3339)}},
+		Features: []ChatGPTFeatureLimit{
+			{Feature: "deep_research", Remaining: 14, ResetAfter: nextMonth.Format(time.RFC3339)},
+			{Feature: "image_gen", Remaining: 32, ResetAfter: utc.Add(5 * time.Hour).Format(time.RFC3339)},
+		},
+		HasData: true,
+	}
+
+	// Kong AI Gateway: two upstream providers, OpenAI with a budget and Anthropic with a token limit
+	var gatewayProviders []AIGatewayInfo
+	for _, g := range []struct {
+		provider      string
+		budget, limit float64
+		models        []AIGatewayModelUsage
+	}{
+		{"openai", 500, 0, []AIGatewayModelUsage{
+			{Model: "gpt-4.1", Requests: 41200, Tokens: 96e6, Cost: 312.4},
+			{Model: "gpt-4.1-mini", Requests: 88300, Tokens: 51e6, Cost: 28.6},
+		}},
+		{"anthropic", 0, 40e6, []AIGatewayModelUsage{
+			{Model: "claude-sonnet-4", Requests: 9100, Tokens: 38e6, Cost: 171.2},
+		}},
+	} {
+		info := AIGatewayInfo{
+			Gateway: "kong", Provider: g.provider, HasCost: true, Currency: "USD", Budget: g.budget, TokenLimit: g.limit,
+			TrackedSince:   monthStart.Format(time.RFC3339),
+			Period:         monthStart.Format("Jan 2006"),
+			CycleEnd:       nextMonth.Format(time.RFC3339),
+			DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+			ModelCount:     len(g.models),
+		}
+		for _, m := range g.models {
+			m.Requests = math.Round(m.Requests * monthFraction)
+			m.Tokens = math.Round(m.Tokens * monthFraction)
+			m.Cost = math.Round(m.Cost*monthFraction*100) / 100
+			info.Models = append(info.Models, m)
+			info.Requests += m.Requests
+			info.Tokens += m.Tokens
+			info.InputTokens += math.Round(m.Tokens * 0.8)
+			info.OutputTokens += m.Tokens - math.Round(m.Tokens*0.8)
+			info.Cost += m.Cost
+		}
+		info.Cost = math.Round(info.Cost*100) / 100
+		gatewayProviders = append(gatewayProviders, info)
+	}
+
+	// Claude Code: three developers reporting from their machines, one heavy user near the budget
+	claudeCode := ClaudeCodeInfo{
+		DeveloperBudget: 400, Currency: "USD",
+		Period:         monthStart.Format("Jan 2006"),
+		CycleEnd:       nextMonth.Format(time.RFC3339),
+		DaysUntilReset: int(nextMonth.Sub(utc).Hours() / 24),
+	}
+	for _, d := range []struct {
+		user, host, model string
+		share             float64
+		stale             bool
+	}{
+		{"alice", "alice-mbp", "claude-opus-4-5-20251101", 1, false},
+		{"bob", "bob-workstation", "claude-sonnet-4-5-20250929", 0.45, false},
+		{"carol", "carol-laptop", "claude-sonnet-4-5-20250929", 0.2, true},
+	} {
+		dev := ClaudeCodeDeveloper{
+			UserID: d.user, Username: d.user, Hosts: []string{d.host}, TopModel: d.model,
+			InputTokens:  math.Round(2.1e6 * monthFraction * d.share),
+			OutputTokens: math.Round(6.4e6 * monthFraction * d.share),
+			CacheTokens:  math.Round(3.9e8 * monthFraction * d.share),
+			Sessions:     int(140 * monthFraction * d.share),
+			LastReport:   utc.Add(-20 * time.Minute).Format(time.RFC3339),
+			Stale:        d.stale,
+		}
+		if d.stale {
+			dev.LastReport = utc.AddDate(0, 0, -3).Format(time.RFC3339)
+		}
+		dev.Cost = math.Round(360*monthFraction*d.share*100) / 100
+		claudeCode.Developers = append(claudeCode.Developers, dev)
+		claudeCode.TotalTokens += dev.InputTokens + dev.OutputTokens + dev.CacheTokens
+		claudeCode.TotalCost += dev.Cost
+		claudeCode.Sessions += dev.Sessions
+	}
+
+	services := []ServiceStatus{
+		{ID: "augment", Name: "Augment Code", Enabled: true, Data: augment},
+		{ID: "zai", Name: "Z.AI", Enabled: true, Data: zai},
+		{ID: "openai", Name: "OpenAI", Enabled: true, Data: openai},
+	}
+	// Claude's status also records which windows are constrained, so it's computed before storing the data
+	claudeState := claudeStatus(&claude, config)
+	services = append(services, ServiceStatus{ID: "claude", Name: "claude.ai", Enabled: true, Status: claudeState, Data: claude})
+	services = append(services, ServiceStatus{ID: "anthropic", Name: "Anthropic API", Enabled: true, Data: anthropic})
+	services = append(services, ServiceStatus{ID: "copilot", Name: "GitHub Copilot", Enabled: true, Data: copilot})
+	services = append(services, ServiceStatus{ID: "cursor", Name: "Cursor", Enabled: true, Data: cursor})
+	services = append(services, ServiceStatus{ID: "mistral", Name: "Mistral", Enabled: true, Data: mistral})
+	services = append(services, ServiceStatus{ID: "groq", Name: "Groq", Enabled: true, Data: groq})
+	services = append(services, ServiceStatus{ID: "deepseek", Name: "DeepSeek", Enabled: true, Data: deepseek})
+	services = append(services, ServiceStatus{ID: "perplexity", Name: "Perplexity", Enabled: true, Data: perplexity})
+	services = append(services, ServiceStatus{ID: "together", Name: "Together AI", Enabled: true, Data: together})
+	services = append(services, ServiceStatus{ID: "bedrock", Name: "AWS Bedrock", Enabled: true, Data: bedrock})
+	services = append(services, ServiceStatus{ID: "vertex", Name: "Google Vertex AI", Enabled: true, Data: vertex})
+	services = append(services, ServiceStatus{ID: "gemini", Name: "Google Gemini API", Enabled: true, Data: gemini})
+	services = append(services, ServiceStatus{ID: "azureopenai", Name: "Azure OpenAI", Enabled: true, Data: azureOpenAI})
+	services = append(services, ServiceStatus{ID: "xai", Name: "xAI", Enabled: true, Data: xai})
+	services = append(services, ServiceStatus{ID: "windsurf", Name: "Windsurf", Enabled: true, Data: windsurf})
+	services = append(services, ServiceStatus{ID: "tabnine", Name: "Tabnine", Enabled: true, Data: tabnine})
+	services = append(services, ServiceStatus{ID: "jetbrains", Name: "JetBrains AI", Enabled: true, Data: jetbrains})
+	services = append(services, ServiceStatus{ID: "cerebras", Name: "Cerebras", Enabled: true, Data: cerebras})
+	services = append(services, ServiceStatus{ID: "sambanova", Name: "SambaNova", Enabled: true, Data: sambanova})
+	services = append(services, ServiceStatus{ID: "selfhosted", Name: "Self-hosted LLM", Enabled: true, Data: selfhosted})
+	services = append(services, ServiceStatus{ID: "litellm", Name: "LiteLLM", Enabled: true, Data: litellm})
+	services = append(services, ServiceStatus{ID: "helicone", Name: "Helicone", Enabled: true, Data: helicone})
+	services = append(services, ServiceStatus{ID: "gateway", Name: defaultGatewayName, Enabled: true, Data: gateway})
+	services = append(services, ServiceStatus{ID: "elevenlabs", Name: "ElevenLabs", Enabled: true, Data: elevenlabs})
+	services = append(services, ServiceStatus{ID: "assemblyai", Name: "AssemblyAI", Enabled: true, Data: assemblyai})
+	services = append(services, ServiceStatus{ID: "supermaven", Name: "Supermaven", Enabled: true, Data: supermaven})
+	services = append(services, ServiceStatus{ID: "amazonq", Name: "Amazon Q Developer", Enabled: true, Data: amazonq})
+	services = append(services, ServiceStatus{ID: "moonshot", Name: "Moonshot AI", Enabled: true, Data: moonshot})
+	services = append(services, ServiceStatus{ID: "dashscope", Name: "Alibaba DashScope", Enabled: true, Data: dashscope})
+	services = append(services, ServiceStatus{ID: "ai21", Name: "AI21 Labs", Enabled: true, Data: ai21})
+	services = append(services, ServiceStatus{ID: "fal", Name: "fal.ai", Enabled: true, Data: fal})
+	services = append(services, ServiceStatus{ID: "runpod", Name: "RunPod", Enabled: true, Data: runpod})
+	services = append(services, ServiceStatus{ID: "yandex", Name: "Yandex Foundation Models", Enabled: true, Data: yandex})
+	services = append(services, ServiceStatus{ID: "gigachat", Name: "GigaChat", Enabled: true, Data: gigachat})
+	services = append(services, ServiceStatus{ID: "reka", Name: "Reka AI", Enabled: true, Data: reka})
+	services = append(services, ServiceStatus{ID: "watsonx", Name: "IBM watsonx.ai", Enabled: true, Data: watsonx})
+	services = append(services, ServiceStatus{ID: "databricks", Name: "Databricks Model Serving", Enabled: true, Data: databricks})
+	services = append(services, ServiceStatus{ID: "nvidia", Name: "NVIDIA NIM", Enabled: true, Data: nvidia})
+	services = append(services, ServiceStatus{ID: "vercel", Name: "Vercel AI Gateway", Enabled: true, Data: vercel})
+	services = append(services, ServiceStatus{ID: "openrouter", Name: "OpenRouter", Enabled: true, Data: openRouter})
+	services = append(services, ServiceStatus{ID: "portkey", Name: "Portkey", Enabled: true, Data: portkey})
+	services = append(services, ServiceStatus{ID: "githubmodels", Name: "GitHub Models", Enabled: true, Data: githubModels})
+	codexState := codexStatus(&codex, config)
+	services = append(services, ServiceStatus{ID: "codex", Name: "OpenAI Codex", Enabled: true, Status: codexState, Data: codex})
+	services = append(services, ServiceStatus{ID: "chatgpt", Name: "ChatGPT", Enabled: true, Data: chatgpt})
+	services = append(services, ServiceStatus{ID: "claudecode", Name: "Claude Code", Enabled: true, Data: claudeCode})
+	services = append(services, ServiceStatus{ID: "upstage", Name: "Upstage Solar", Enabled: true, Data: upstage})
+	for _, g := range gatewayProviders {
+		services = append(services, ServiceStatus{ID: "aigateway:" + g.Provider, Name: "Kong · " + g.Provider, Enabled: true, Data: g})
+	}
+	services = append(services, ServiceStatus{ID: "custom:platform", Name: "ML Platform", Enabled: true, Data: internalQuota})
+
+	for i, s := range services {
+		if s.Status == "" {
+			services[i].Status = demoStatus(config, s)
+		}
+		services[i].CachedAt = now.Unix()
+	}
+	return services
+}
+
+// demoStatus applies the same thresholds the real providers use.
+func demoStatus(config *Configuration, s ServiceStatus) string {
+	pct, _ := usagePercent(s)
+	switch d := s.Data.(type) {
+	case AugmentCreditInfo:
+		if d.IsLow || pct > config.warningPercent(90) {
+			return "warning"
+		}
+	case ZaiQuotaInfo:
+		if pct > config.warningPercent(90) {
+			return "warning"
+		}
+	case OpenAIUsageInfo:
+		return budgetStatus(d.TotalCost, d.Budget, config)
+	case AnthropicUsageInfo:
+		return anthropicStatus(d, config)
+	case BedrockUsageInfo:
+		return budgetStatus(d.TotalCost, d.Budget, config)
+	case HeliconeUsageInfo:
+		return budgetStatus(d.TotalCost, d.Budget, config)
+	case GatewayInfo:
+		return gatewayStatus(d, config)
+	case ElevenLabsInfo:
+		return elevenLabsStatus(d, config)
+	case AssemblyAIUsageInfo:
+		return assemblyAIStatus(d)
+	case SupermavenInfo:
+		return supermavenStatus(d, config)
+	case AmazonQInfo:
+		return amazonQStatus(d, config)
+	case MoonshotBalanceInfo:
+		return moonshotStatus(d)
+	case DashScopeInfo:
+		return dashScopeStatus(d, config)
+	case AI21Info:
+		return ai21Status(d, time.Now().UTC())
+	case FalInfo:
+		return falStatus(d)
+	case RunPodInfo:
+		return runPodStatus(d)
+	case YandexInfo:
+		return yandexStatus(d)
+	case GigaChatInfo:
+		return gigaChatStatus(d)
+	case RekaInfo:
+		return rekaStatus(d)
+	case UpstageInfo:
+		return upstageStatus(d)
+	case ChatGPTUsageInfo:
+		return chatGPTStatus(d)
+	case AIGatewayInfo:
+		return aiGatewayStatus(d, config)
+	case CustomProviderInfo:
+		return customStatus(d, customProvider{}, config)
+	case WatsonxInfo:
+		return watsonxStatus(d, config)
+	case DatabricksInfo:
+		return budgetStatus(d.TotalCost, d.Budget, config)
+	case NvidiaInfo:
+		return nvidiaStatus(d, config)
+	case VercelGatewayInfo:
+		return vercelGatewayStatus(d)
+	case PortkeyInfo:
+		return portkeyStatus(d, config)
+	case ClaudeCodeInfo:
+		return claudeCodeStatus(d, config)
+	case GithubModelsInfo:
+		return githubModelsStatus(d, config)
+	case VertexUsageInfo:
+		return vertexStatus(d, config)
+	case GeminiInfo:
+		return geminiStatus(d, config)
+	case AzureOpenAIInfo:
+		return azureOpenAIStatus(d, config)
+	case OpenRouterInfo:
+		return openRouterStatus(d, config)
+	case XaiUsageInfo:
+		return xaiStatus(d, config)
+	case WindsurfUsageInfo:
+		return windsurfStatus(d, config)
+	case TabnineInfo:
+		return tabnineStatus(d, config)
+	case CerebrasUsageInfo:
+		return cerebrasStatus(d, config)
+	case SambaNovaUsageInfo:
+		return sambaNovaStatus(d, config)
+	case SelfHostedInfo:
+		return selfHostedStatus(d)
+	case LiteLLMBudgetInfo:
+		return litellmStatus(d, config)
+	case CursorUsageInfo, MistralUsageInfo, GroqUsageInfo, JetBrainsAIInfo:
+		if pct > config.warningPercent(90) {
+			return "warning"
+		}
+	case DeepSeekBalanceInfo:
+		return deepSeekStatus(d)
+	case PerplexityInfo:
+		if d.HasBalance && d.LowBalance > 0 && d.CreditBalance < d.LowBalance {
+			return "warning"
+		}
+	case TogetherInfo:
+		if d.HasBalance && d.LowBalance > 0 && d.CreditBalance < d.LowBalance {
+			return "warning"
+		}
+	case CopilotUsageInfo:
+		if d.OverageCost > 0 || pct > config.warningPercent(80) {
+			return "warning"
+		}
+	}
+	return "ok"
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Digest =====
+
+const (
+	digestLastSentKey = "digest_last_sent"
+	digestSnapshotKey = "digest_snapshot"
+)
+
+// digestPoint is a card's usage as of a digest, to tell how it moved by the next one.
+type digestPoint struct {
+	Percent  *float64 `json:"percent,omitempty"`
+	Cost     *float64 `json:"cost,omitempty"`
+	Currency string   `json:"currency,omitempty"`
+}
+
+// digestTime returns the time of day the digest is sent, given as "9" or "09:30"; 9:00
+// unless set.
+func (c *Configuration) digestTime() (hour, minute int) {
+	h, m, hasMinute := strings.Cut(strings.TrimSpace(c.DigestHour), ":")
+	hour, err := strconv.Atoi(h)
+	if err != nil || hour < 0 || hour > 23 {
+		return 9, 0
+	}
+	if hasMinute {
+		if minute, err = strconv.Atoi(m); err != nil || minute < 0 || minute > 59 {
+			return 9, 0
+		}
+	}
+	return hour, minute
+}
+
+// digestWeekday is the day weekly digests are sent, Monday unless set.
+func (c *Configuration) digestWeekday() time.Weekday {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(strings.TrimSpace(c.DigestWeekday), d.String()) {
+			return d
+		}
+	}
+	return time.Monday
+}
+
+// claimPeriod records under key that a scheduled job ran for period, such as a day, and
+// reports whether this call was the one to claim it. Every node of a cluster runs the
+// jobs, so the claim is a compare-and-set and only one of them wins.
+func (p *Plugin) claimPeriod(key, period string) bool {
+	last, appErr := p.API.KVGet(key)
+	if appErr != nil {
+		p.API.LogWarn("Failed to read when a scheduled job last ran", "key", key, "error", appErr.Error())
+		return false
+	}
+	if string(last) == period {
+		return false
+	}
+	claimed, appErr := p.API.KVCompareAndSet(key, last, []byte(period))
+	if appErr != nil {
+		p.API.LogWarn("Failed to store when a scheduled job ran", "key", key, "error", appErr.Error())
+		return false
+	}
+	return claimed
+}
+
+// runDigestJob sends the scheduled digest once per period when the configured time arrives,
+// by email and to the digest channel.
+func (p *Plugin) runDigestJob() {
+	config := p.getConfiguration()
+	if config.DigestSchedule == "" || config.DigestSchedule == "off" || config.DigestEmails == "" && config.DigestChannelId == "" {
+		return
+	}
+
+	hour, minute := config.digestTime()
+	now := time.Now()
+	if now.Hour() != hour || now.Minute() < minute {
+		return
+	}
+	if config.DigestSchedule == "weekly" && now.Weekday() != config.digestWeekday() {
+		return
+	}
+
+	// Only send once per day, even across restarts and cluster nodes
+	if !p.claimPeriod(digestLastSentKey, now.Format("2006-01-02")) {
+		return
+	}
+
+	services := p.collectStatuses()
+	p.trackStatusChanges(services)
+	locale := p.serverLocale()
+	runway := computeRunway(services, config, now)
+	listed := config.digestView().apply(services)
+	deltas := digestDeltas(listed, p.getDigestSnapshot(), locale)
+	subject, body := buildDigestEmail(config.DigestSchedule, listed, deltas, runway, now, config.defaultUnits(), locale)
+
+	for _, to := range strings.Split(config.DigestEmails, ",") {
+		to = strings.TrimSpace(to)
+		if to == "" {
+			continue
+		}
+		if appErr := p.API.SendMail(to, subject, body); appErr != nil {
+			p.API.LogWarn("Failed to send digest email", "to", to, "error", appErr.Error())
+		}
+	}
+	if config.DigestChannelId != "" {
+		post := &model.Post{
+			ChannelId: config.DigestChannelId,
+			UserId:    p.botUserID,
+			Message:   buildDigestPost(subject, listed, deltas, runway, config.defaultUnits(), locale),
+		}
+		if _, appErr := p.API.CreatePost(post); appErr != nil {
+			p.API.LogWarn("Failed to post the digest", "channel", config.DigestChannelId, "error", appErr.Error())
+		}
+	}
+	p.saveDigestSnapshot(services)
+}
+
+func (p *Plugin) getDigestSnapshot() map[string]digestPoint {
+	points := map[string]digestPoint{}
+	if data, appErr := p.API.KVGet(digestSnapshotKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &points)
+	}
+	return points
+}
+
+// saveDigestSnapshot keeps every card's usage for the next digest's deltas. Failed cards
+// keep their previous point, so the delta spans the outage.
+func (p *Plugin) saveDigestSnapshot(services []ServiceStatus) {
+	points := p.getDigestSnapshot()
+	for _, s := range services {
+		if !s.Enabled || s.Error != "" || s.Stale {
+			continue
+		}
+		if m := computeUsage(s); m != nil {
+			points[s.ID] = digestPoint{Percent: m.Percent, Cost: m.Cost, Currency: m.Currency}
+		}
+	}
+	data, _ := json.Marshal(points)
+	if appErr := p.API.KVSet(digestSnapshotKey, data); appErr != nil {
+		p.API.LogWarn("Failed to store the digest snapshot", "error", appErr.Error())
+	}
+}
+
+// digestDeltas describes how each card's usage moved since the last digest, e.g.
+// "+12 pts, +$45.20 since the last digest", by card ID. Cards that didn't move are left out.
+func digestDeltas(services []ServiceStatus, previous map[string]digestPoint, locale string) map[string]string {
+	deltas := map[string]string{}
+	for _, s := range services {
+		prev, ok := previous[s.ID]
+		m := computeUsage(s)
+		if !ok || m == nil || s.Error != "" {
+			continue
+		}
+		var parts []string
+		if m.Percent != nil && prev.Percent != nil {
+			if d := *m.Percent - *prev.Percent; math.Abs(d) >= 0.5 {
+				parts = append(parts, translate(locale, "digest.delta_points", d))
+			}
+		}
+		if m.Cost != nil && prev.Cost != nil && m.Currency == prev.Currency {
+			if d := *m.Cost - *prev.Cost; math.Abs(d) >= 0.005 {
+				sign := "+"
+				if d < 0 {
+					sign = "-"
+				}
+				parts = append(parts, sign+formatMoney(math.Abs(d), m.Currency, 2))
+			}
+		}
+		if len(parts) > 0 {
+			deltas[s.ID] = translate(locale, "digest.delta", strings.Join(parts, ", "))
+		}
+	}
+	return deltas
+}
+
+// buildDigestPost renders the digest as a channel post.
+func buildDigestPost(subject string, services []ServiceStatus, deltas map[string]string, runway *Runway, units, locale string) string {
+	var b strings.Builder
+	b.WriteString("#### " + subject + "\n")
+	if text := runwayText(runway, locale); text != "" {
+		b.WriteString("**" + text + "**\n")
+	}
+	if len(services) == 0 {
+		b.WriteString(translate(locale, "digest.all_ok"))
+		return b.String()
+	}
+	for _, s := range services {
+		line := fmt.Sprintf("- %s **%s**: %s", statusEmoji(s.Status), s.Name, summarizeInUnits(s, units, locale))
+		if delta := deltas[s.ID]; delta != "" {
+			line += " _(" + delta + ")_"
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// buildDigestEmail renders the digest as an HTML email.
+func buildDigestEmail(schedule string, services []ServiceStatus, deltas map[string]string, runway *Runway, now time.Time, units, locale string) (string, string) {
+	subjectID := "digest.subject_daily"
+	if schedule == "weekly" {
+		subjectID = "digest.subject_weekly"
+	}
+	subject := translate(locale, subjectID, now.Format("2006-01-02"))
+
+	var b strings.Builder
+	b.WriteString(`<h2 style="font-family: sans-serif;">` + html.EscapeString(subject) + `</h2>`)
+	if text := runwayText(runway, locale); text != "" {
+		b.WriteString(`<p style="font-family: sans-serif;"><b>` + html.EscapeString(text) + `</b><br>` +
+			html.EscapeString(translate(locale, "runway.wallet", formatMoney(runway.Available, runway.Currency, 2),
+				formatMoney(runway.Credits, runway.Currency, 2), formatMoney(runway.BurnPerDay, runway.Currency, 2))) + `</p>`)
+	}
+	if len(services) == 0 {
+		b.WriteString(`<p style="font-family: sans-serif;">` + html.EscapeString(translate(locale, "digest.all_ok")) + `</p>`)
+		return subject, b.String()
+	}
+	b.WriteString(`<table style="font-family: sans-serif; border-collapse: collapse;" cellpadding="6">`)
+	for _, s := range services {
+		b.WriteString("<tr>")
+		b.WriteString(fmt.Sprintf(`<td style="color: %s;">&#9679;</td>`, statusColor(s.Status)))
+		b.WriteString("<td><b>" + html.EscapeString(s.Name) + "</b></td>")
+		b.WriteString("<td>" + html.EscapeString(summarizeInUnits(s, units, locale)) + "</td>")
+		b.WriteString(`<td style="color: #8b8fa7;">` + html.EscapeString(deltas[s.ID]) + "</td>")
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</table>")
+
+	return subject, b.String()
+}
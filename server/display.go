@@ -0,0 +1,55 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ===== Display names and ordering =====
+
+// parseProviderNames parses one "id=Display name" pair per line.
+func parseProviderNames(raw string) map[string]string {
+	names := map[string]string{}
+	for _, line := range strings.Split(raw, "\n") {
+		id, name, ok := strings.Cut(line, "=")
+		id, name = strings.TrimSpace(id), strings.TrimSpace(name)
+		if ok && id != "" && name != "" {
+			names[id] = name
+		}
+	}
+	return names
+}
+
+// applyDisplaySettings renames services and sorts them by the configured order.
+// Services missing from the order keep their default position after the listed ones.
+func applyDisplaySettings(services []ServiceStatus, config *Configuration) []ServiceStatus {
+	names := parseProviderNames(config.ProviderNames)
+	for i, s := range services {
+		if name, ok := names[s.ID]; ok {
+			services[i].Name = name
+		}
+	}
+
+	rank := map[string]int{}
+	for _, id := range strings.Split(config.ProviderOrder, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			if _, seen := rank[id]; !seen {
+				rank[id] = len(rank)
+			}
+		}
+	}
+	if len(rank) == 0 {
+		return services
+	}
+
+	position := func(id string) int {
+		if r, ok := rank[id]; ok {
+			return r
+		}
+		return len(rank)
+	}
+	sort.SliceStable(services, func(i, j int) bool {
+		return position(services[i].ID) < position(services[j].ID)
+	})
+	return services
+}
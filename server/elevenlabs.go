@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// ===== ElevenLabs (API key) =====
+
+// ElevenLabsInfo is the subscription's character quota for the current period. Usage-based
+// plans can extend past the quota, billed as overage; the others stop at it.
+type ElevenLabsInfo struct {
+	Tier            string  `json:"tier"`
+	Status          string  `json:"status"`
+	CharactersUsed  float64 `json:"charactersUsed"`
+	CharactersLimit float64 `json:"charactersLimit"`
+	CanExtend       bool    `json:"canExtend"`
+	NextReset       string  `json:"nextReset,omitempty"`
+	VoicesUsed      float64 `json:"voicesUsed"`
+	VoicesLimit     float64 `json:"voicesLimit"`
+}
+
+func (e ElevenLabsInfo) summary(locale string) string {
+	if e.CanExtend && e.CharactersUsed > e.CharactersLimit {
+		return translate(locale, "summary.elevenlabs_overage", formatCount(e.CharactersUsed-e.CharactersLimit), e.Tier)
+	}
+	return translate(locale, "summary.elevenlabs", formatCount(max(e.CharactersLimit-e.CharactersUsed, 0)), formatCount(e.CharactersLimit), e.Tier)
+}
+
+func (e ElevenLabsInfo) compact(name, locale string) string {
+	return translate(locale, "compact.characters_left", name, formatCount(max(e.CharactersLimit-e.CharactersUsed, 0)))
+}
+
+func (e ElevenLabsInfo) usagePercent() (float64, bool) {
+	if e.CharactersLimit > 0 {
+		return e.CharactersUsed / e.CharactersLimit * 100, true
+	}
+	return 0, false
+}
+
+func (e ElevenLabsInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "character_quota", At: parseTime(e.NextReset)}}
+}
+
+// elevenLabsSubscriptionResponse is GET /v1/user/subscription.
+type elevenLabsSubscriptionResponse struct {
+	Tier                          string    `json:"tier" schema:"required"`
+	CharacterCount                flexFloat `json:"character_count" schema:"required"`
+	CharacterLimit                flexFloat `json:"character_limit" schema:"required"`
+	CanExtendCharacterLimit       bool      `json:"can_extend_character_limit"`
+	AllowedToExtendCharacterLimit bool      `json:"allowed_to_extend_character_limit"`
+	NextCharacterCountResetUnix   flexFloat `json:"next_character_count_reset_unix"`
+	VoiceLimit                    flexFloat `json:"voice_limit"`
+	VoiceSlotsUsed                flexFloat `json:"voice_slots_used"`
+	Status                        string    `json:"status"`
+	BillingPeriod                 string    `json:"billing_period"`
+	CharacterRefreshPeriod        string    `json:"character_refresh_period"`
+	Currency                      string    `json:"currency"`
+}
+
+func (p *Plugin) getElevenLabsStatus(config *Configuration) ServiceStatus {
+	const id, name = "elevenlabs", "ElevenLabs"
+	if config.ElevenlabsApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newElevenLabsRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var sub elevenLabsSubscriptionResponse
+	drift, err := decodeResponse(body, &sub)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info := ElevenLabsInfo{
+		Tier:            sub.Tier,
+		Status:          sub.Status,
+		CharactersUsed:  float64(sub.CharacterCount),
+		CharactersLimit: float64(sub.CharacterLimit),
+		CanExtend:       sub.CanExtendCharacterLimit && sub.AllowedToExtendCharacterLimit,
+		VoicesUsed:      float64(sub.VoiceSlotsUsed),
+		VoicesLimit:     float64(sub.VoiceLimit),
+	}
+	if sub.NextCharacterCountResetUnix > 0 {
+		info.NextReset = time.Unix(int64(sub.NextCharacterCountResetUnix), 0).UTC().Format(time.RFC3339)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: elevenLabsStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// elevenLabsStatus is an error once the quota is used up on a plan that can't extend it,
+// and a warning while extending it into overage.
+func elevenLabsStatus(info ElevenLabsInfo, config *Configuration) string {
+	if info.CharactersLimit <= 0 {
+		return "ok"
+	}
+	switch {
+	case info.CharactersUsed >= info.CharactersLimit && !info.CanExtend:
+		return "error"
+	case info.CharactersUsed/info.CharactersLimit*100 > config.warningPercent(90):
+		return "warning"
+	}
+	return "ok"
+}
+
+func newElevenLabsRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.elevenlabs.io/v1/user/subscription", nil)
+	req.Header.Set("xi-api-key", config.ElevenlabsApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
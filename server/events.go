@@ -0,0 +1,69 @@
+package main
+
+import "time"
+
+// Event types emitted when provider status changes.
+const (
+	EventStatusChange = "status_change"
+	EventThreshold    = "threshold"
+	EventReset        = "reset"
+)
+
+// StatusEvent describes a notable change in a provider's status.
+type StatusEvent struct {
+	Type      string        `json:"type"`
+	Provider  string        `json:"provider"`
+	Name      string        `json:"name"`
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Percent   float64       `json:"percent"`
+	Summary   string        `json:"summary"`
+	Timestamp int64         `json:"timestamp"`
+	Service   ServiceStatus `json:"service"`
+}
+
+func newStatusEvent(eventType string, s ServiceStatus, from string, percent float64, at time.Time, locale string) StatusEvent {
+	return StatusEvent{
+		Type:      eventType,
+		Provider:  s.ID,
+		Name:      s.Name,
+		From:      from,
+		To:        s.Status,
+		Percent:   percent,
+		Summary:   summarizeService(s, locale),
+		Timestamp: at.Unix(),
+		Service:   s,
+	}
+}
+
+// dispatchEvents fans status events out to the configured integrations.
+func (p *Plugin) dispatchEvents(events []StatusEvent) {
+	if len(events) == 0 {
+		return
+	}
+	config := p.getConfiguration()
+
+	for _, ev := range events {
+		if ev.Type == EventStatusChange && ev.To == "error" && config.BoardsEnabled && config.BoardsBoardId != "" {
+			p.bg.Go(p, func() { p.createBoardsIncidentCard(config.BoardsBoardId, ev.Service, ev.From, p.serverLocale()) })
+		}
+	}
+
+	for _, ev := range events {
+		if ev.Type == EventStatusChange && ev.To == "reauth" {
+			p.bg.Go(p, func() { p.notifyAdminsReauth(ev) })
+		}
+	}
+	if config.AlertChannelId != "" {
+		p.bg.Go(p, func() { p.postChannelAlerts(config.AlertChannelId, events) })
+	}
+	if config.AlertRecipients != "" {
+		p.bg.Go(p, func() { p.notifyAlertRecipients(config, events) })
+	}
+	if config.WebhookUrl != "" {
+		p.bg.Go(p, func() { p.sendWebhooks(config, events) })
+	}
+	if config.GrafanaUrl != "" && config.GrafanaToken != "" {
+		p.bg.Go(p, func() { p.sendGrafanaAnnotations(config, events) })
+	}
+}
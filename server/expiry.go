@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Credential expiration =====
+
+const credentialExpiryRemindersKey = "credential_expiry_reminders"
+
+// credentialExpiryHeaders are response headers in which providers announce when the
+// credential used for the request expires, with their time layout. GitHub sends one for
+// fine-grained and expiring classic personal access tokens.
+var credentialExpiryHeaders = map[string]string{
+	"GitHub-Authentication-Token-Expiration": "2006-01-02 15:04:05 MST",
+}
+
+// parseCredentialExpirations parses the Credential Expiration Dates setting: one
+// "<provider or card ID>=<YYYY-MM-DD>" per line.
+func parseCredentialExpirations(raw string) map[string]time.Time {
+	expirations := map[string]time.Time{}
+	for _, line := range strings.Split(raw, "\n") {
+		id, date, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		at, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+		if id = strings.TrimSpace(id); err == nil && id != "" {
+			expirations[id] = at
+		}
+	}
+	return expirations
+}
+
+// credentialExpiryWarning is how long before a credential expires admins are reminded.
+func (c *Configuration) credentialExpiryWarning() time.Duration {
+	days, err := strconv.Atoi(strings.TrimSpace(c.CredentialExpiryWarningDays))
+	if err != nil || days <= 0 {
+		days = 14
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// recordCredentialExpiry remembers the credential expiry a provider announced in a response.
+func (p *Plugin) recordCredentialExpiry(provider string, header http.Header) {
+	for name, layout := range credentialExpiryHeaders {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		at, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		p.expiryLock.Lock()
+		if p.expiry == nil {
+			p.expiry = map[string]time.Time{}
+		}
+		p.expiry[provider] = at
+		p.expiryLock.Unlock()
+	}
+}
+
+// applyDetectedExpiry sets the credential expiry the provider announced on its statuses.
+func (p *Plugin) applyDetectedExpiry(provider string, statuses []ServiceStatus) {
+	p.expiryLock.Lock()
+	defer p.expiryLock.Unlock()
+	for i, s := range statuses {
+		at, ok := p.expiry[s.ID]
+		if !ok {
+			at, ok = p.expiry[provider]
+		}
+		if ok {
+			statuses[i].CredentialExpiresAt = at.Unix()
+		}
+	}
+}
+
+// applyCredentialExpirations sets the expiry dates entered by admins, which take precedence
+// over those providers announce. A provider ID covers all of its cards.
+func applyCredentialExpirations(services []ServiceStatus, config *Configuration) {
+	expirations := parseCredentialExpirations(config.CredentialExpirations)
+	if len(expirations) == 0 {
+		return
+	}
+	for i, s := range services {
+		at, ok := expirations[s.ID]
+		if !ok {
+			at, ok = expirations[strings.Split(s.ID, ":")[0]]
+		}
+		if ok {
+			services[i].CredentialExpiresAt = at.Unix()
+		}
+	}
+}
+
+// credentialExpiryReminder is the last reminder sent for a card's credential.
+type credentialExpiryReminder struct {
+	ExpiresAt int64 `json:"expiresAt"`
+	DaysLeft  int   `json:"daysLeft"`
+}
+
+// checkCredentialExpiry reminds system admins when a credential is about to expire: once
+// when it enters the warning window, again a week and a day before, and when it expires.
+func (p *Plugin) checkCredentialExpiry() {
+	config := p.getConfiguration()
+	if config.DemoMode {
+		return
+	}
+	warning := config.credentialExpiryWarning()
+	stages := []int{int(warning.Hours() / 24), 7, 1, 0}
+
+	type expiring struct {
+		id, name  string
+		expiresAt time.Time
+	}
+	var candidates []expiring
+	p.stateLock.Lock()
+	for id, state := range p.states {
+		if state.Last.CredentialExpiresAt > 0 {
+			candidates = append(candidates, expiring{id, state.Last.Name, time.Unix(state.Last.CredentialExpiresAt, 0)})
+		}
+	}
+	p.stateLock.Unlock()
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].id < candidates[j].id })
+
+	reminders := map[string]credentialExpiryReminder{}
+	if data, appErr := p.API.KVGet(credentialExpiryRemindersKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &reminders)
+	}
+
+	locale := p.serverLocale()
+	var lines []string
+	for _, c := range candidates {
+		left := time.Until(c.expiresAt)
+		if left > warning {
+			continue
+		}
+		daysLeft := max(int(left.Hours()/24), 0)
+		// The closest reminder reached; -1 once expired
+		stage := stages[0]
+		for _, s := range stages {
+			if daysLeft <= s {
+				stage = min(stage, s)
+			}
+		}
+		if left <= 0 {
+			stage = -1
+		}
+		if prev, ok := reminders[c.id]; ok && prev.ExpiresAt == c.expiresAt.Unix() && prev.DaysLeft <= stage {
+			continue
+		}
+		reminders[c.id] = credentialExpiryReminder{ExpiresAt: c.expiresAt.Unix(), DaysLeft: stage}
+
+		date := c.expiresAt.UTC().Format("2006-01-02")
+		if left <= 0 {
+			lines = append(lines, translate(locale, "alert.credential_expired", c.name, date))
+		} else {
+			lines = append(lines, translate(locale, "alert.credential_expiring", c.name, daysLeft, date))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	data, _ := json.Marshal(reminders)
+	if appErr := p.API.KVSet(credentialExpiryRemindersKey, data); appErr != nil {
+		p.API.LogWarn("Failed to save credential expiry reminders", "error", appErr.Error())
+	}
+	p.notifyAdmins(strings.Join(lines, "\n"))
+}
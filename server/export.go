@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ===== Usage export =====
+
+// exportRow is one figure of a recorded usage point.
+type exportRow struct {
+	Provider  string  `json:"provider"`
+	Name      string  `json:"name"`
+	Timestamp string  `json:"timestamp"` // start of the bucket, RFC 3339 in UTC
+	Metric    string  `json:"metric"`    // used, limit, cost, percent or peak
+	Value     float64 `json:"value"`
+	Unit      string  `json:"unit"` // the card's unit, its currency, or "percent"
+}
+
+// exportRows flattens a point into a row per recorded figure.
+func exportRows(series UsageSeries, point UsagePoint) []exportRow {
+	at := time.Unix(point.Time, 0).UTC().Format(time.RFC3339)
+	var rows []exportRow
+	add := func(metric string, value *float64, unit string) {
+		if value != nil {
+			rows = append(rows, exportRow{Provider: series.Provider, Name: series.Name, Timestamp: at, Metric: metric, Value: *value, Unit: unit})
+		}
+	}
+	add("used", point.Used, series.Unit)
+	add("limit", point.Limit, series.Unit)
+	add("cost", point.Cost, series.Currency)
+	add("percent", point.Percent, "percent")
+	add("peak", point.Peak, "percent")
+	return rows
+}
+
+// parseExportTime parses a from or to parameter, a date like "2025-01-31" or an RFC 3339
+// time. A date as to includes the whole day.
+func parseExportTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return day, nil
+}
+
+// handleExport serves GET /api/v1/export: the recorded usage and spend between from and to
+// (the last 30 days by default), one row per provider, time and metric, as CSV or, with
+// format=jsonl, as JSON lines. resolution and provider work as for GET /api/v1/history.
+func (p *Plugin) handleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	switch format {
+	case "":
+		format = "csv"
+	case "csv", "jsonl":
+	case "json":
+		format = "jsonl"
+	default:
+		http.Error(w, `{"error": "invalid_format", "message": "format must be csv or jsonl"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	to := now
+	if v := query.Get("to"); v != "" {
+		t, err := parseExportTime(v, true)
+		if err != nil {
+			http.Error(w, `{"error": "invalid_to", "message": "to must be a date (2006-01-02) or an RFC 3339 time"}`, http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -30).Truncate(24 * time.Hour)
+	if v := query.Get("from"); v != "" {
+		t, err := parseExportTime(v, false)
+		if err != nil {
+			http.Error(w, `{"error": "invalid_from", "message": "from must be a date (2006-01-02) or an RFC 3339 time"}`, http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if to.After(now) {
+		to = now
+	}
+	if !from.Before(to) {
+		http.Error(w, `{"error": "invalid_range", "message": "from must be before to"}`, http.StatusBadRequest)
+		return
+	}
+	if from.Before(now.AddDate(0, 0, -dailyHistoryDays)) {
+		http.Error(w, fmt.Sprintf(`{"error": "invalid_range", "message": "history covers at most %d days"}`, dailyHistoryDays), http.StatusBadRequest)
+		return
+	}
+
+	resolution := query.Get("resolution")
+	switch resolution {
+	case "":
+		resolution = "daily"
+	case "daily":
+	case "hourly":
+		if from.Before(now.AddDate(0, 0, -hourlyHistoryDays)) {
+			http.Error(w, fmt.Sprintf(`{"error": "invalid_resolution", "message": "hourly history covers at most %d days"}`, hourlyHistoryDays), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, `{"error": "invalid_resolution", "message": "resolution must be daily or hourly"}`, http.StatusBadRequest)
+		return
+	}
+
+	series := p.usageHistory(query.Get("provider"), resolution, from, now)
+	filename := fmt.Sprintf("ai-usage-%s-%s.%s", from.Format("20060102"), to.Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == "jsonl" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, s := range series {
+			for _, point := range s.Points {
+				if point.Time > to.Unix() {
+					break
+				}
+				for _, row := range exportRows(s, point) {
+					enc.Encode(row)
+				}
+			}
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	out := csv.NewWriter(w)
+	out.Write([]string{"provider", "name", "timestamp", "metric", "value", "unit"})
+	for _, s := range series {
+		for _, point := range s.Points {
+			if point.Time > to.Unix() {
+				break
+			}
+			for _, row := range exportRows(s, point) {
+				out.Write([]string{row.Provider, row.Name, row.Timestamp, row.Metric, strconv.FormatFloat(row.Value, 'f', -1, 64), row.Unit})
+			}
+		}
+		// Flushing per series streams large exports instead of buffering them
+		out.Flush()
+	}
+	out.Flush()
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== fal.ai (admin API key) =====
+
+// FalInfo is the account's remaining credits and the month-to-date spend by endpoint.
+type FalInfo struct {
+	CreditBalance  float64          `json:"creditBalance"`
+	LowBalance     float64          `json:"lowBalance,omitempty"`
+	Currency       string           `json:"currency"`
+	HasUsage       bool             `json:"hasUsage"`
+	MonthlySpend   float64          `json:"monthlySpend"`
+	Period         string           `json:"period"`
+	CycleEnd       string           `json:"cycleEnd"`
+	DaysUntilReset int              `json:"daysUntilReset"`
+	TopEndpoints   []FalEndpointUse `json:"topEndpoints,omitempty"`
+}
+
+func (f FalInfo) summary(locale string) string {
+	text := translate(locale, "summary.credit_balance", formatMoney(f.CreditBalance, f.Currency, 2))
+	if f.HasUsage {
+		text += ", " + translate(locale, "summary.spent", formatMoney(f.MonthlySpend, f.Currency, 2), f.Period)
+	}
+	return text
+}
+
+func (f FalInfo) compact(name, locale string) string {
+	return translate(locale, "compact.balance_left", name, formatMoney(f.CreditBalance, f.Currency, 0))
+}
+
+func (f FalInfo) resets(time.Time) []ResetInfo {
+	if f.HasUsage {
+		return []ResetInfo{{Kind: "monthly_billing", At: parseTime(f.CycleEnd)}}
+	}
+	return nil
+}
+
+// FalEndpointUse is one model endpoint's spend this month, e.g. "fal-ai/flux/dev".
+type FalEndpointUse struct {
+	Endpoint string  `json:"endpoint"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Cost     float64 `json:"cost"`
+}
+
+// falBillingResponse is GET /v1/account/billing?expand=credits.
+type falBillingResponse struct {
+	Username string `json:"username"`
+	Credits  struct {
+		CurrentBalance flexFloat `json:"current_balance" schema:"required"`
+		Currency       string    `json:"currency"`
+	} `json:"credits" schema:"required"`
+}
+
+// falUsageResponse is GET /v1/models/usage?expand=summary.
+type falUsageResponse struct {
+	Summary []struct {
+		EndpointID string    `json:"endpoint_id" schema:"required"`
+		Unit       string    `json:"unit"`
+		Quantity   flexFloat `json:"quantity"`
+		UnitPrice  flexFloat `json:"unit_price"`
+		Cost       flexFloat `json:"cost" schema:"required"`
+		Currency   string    `json:"currency"`
+	} `json:"summary" schema:"required"`
+	NextCursor *string `json:"next_cursor"`
+	HasMore    bool    `json:"has_more"`
+}
+
+func (p *Plugin) getFalStatus(config *Configuration) ServiceStatus {
+	const id, name = "fal", "fal.ai"
+	if config.FalApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newFalRequest(config, "/v1/account/billing", neturl.Values{"expand": {"credits"}}))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var billing falBillingResponse
+	drift, err := decodeResponse(body, &billing)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := FalInfo{
+		CreditBalance:  float64(billing.Credits.CurrentBalance),
+		Currency:       "USD",
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+	if billing.Credits.Currency != "" {
+		info.Currency = strings.ToUpper(billing.Credits.Currency)
+	}
+
+	// Usage is optional: the card still shows the balance if it fails
+	query := neturl.Values{"expand": {"summary"}, "start": {monthStart.Format(time.RFC3339)}, "end": {now.Format(time.RFC3339)}}
+	if body, err := p.falUsage(client, config, query); err != nil {
+		p.API.LogWarn("Failed to fetch fal.ai usage", "error", err.Error())
+	} else {
+		var usage falUsageResponse
+		d, err := decodeResponse(body, &usage)
+		if err != nil {
+			p.API.LogWarn("Failed to parse fal.ai usage", "error", err.Error())
+		} else {
+			drift.merge(d)
+			info.HasUsage = true
+			byEndpoint := map[string]*FalEndpointUse{}
+			for _, s := range usage.Summary {
+				info.MonthlySpend += float64(s.Cost)
+				use, ok := byEndpoint[s.EndpointID]
+				if !ok {
+					use = &FalEndpointUse{Endpoint: s.EndpointID, Unit: s.Unit}
+					byEndpoint[s.EndpointID] = use
+				}
+				use.Quantity += float64(s.Quantity)
+				use.Cost += float64(s.Cost)
+			}
+			for _, use := range byEndpoint {
+				info.TopEndpoints = append(info.TopEndpoints, *use)
+			}
+			sort.Slice(info.TopEndpoints, func(i, j int) bool { return info.TopEndpoints[i].Cost > info.TopEndpoints[j].Cost })
+			info.TopEndpoints = info.TopEndpoints[:min(len(info.TopEndpoints), 5)]
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+	info.LowBalance = p.budgetIn(config, id, config.FalLowBalance, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: falStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// falStatus is an error once the credits are used up, as fal then rejects requests, and a
+// warning below the configured low balance.
+func falStatus(info FalInfo) string {
+	switch {
+	case info.CreditBalance <= 0:
+		return "error"
+	case info.LowBalance > 0 && info.CreditBalance < info.LowBalance:
+		return "warning"
+	}
+	return "ok"
+}
+
+// falUsage returns the body of a successful usage response.
+func (p *Plugin) falUsage(client *http.Client, config *Configuration, query neturl.Values) ([]byte, error) {
+	resp, err := client.Do(newFalRequest(config, "/v1/models/usage", query))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+func newFalRequest(config *Configuration, path string, query neturl.Values) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.fal.ai"+path+"?"+query.Encode(), nil)
+	req.Header.Set("Authorization", "Key "+config.FalApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== Federation with other Mattermost servers =====
+
+// federationHeader marks status requests from a peer, which are answered with the local
+// providers only so two servers that federate with each other don't recurse.
+const federationHeader = "X-AI-Limits-Federation"
+
+// federationPeer is another Mattermost server running this plugin whose providers are
+// shown on this dashboard.
+type federationPeer struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	URL   string `json:"url"`
+	// Personal access token of a user allowed to use the plugin on the peer; "env:NAME"
+	// or "file:/path" reads it from the server's environment or a file
+	Token    string `json:"token"`
+	Disabled bool   `json:"disabled"`
+}
+
+func (peer federationPeer) label() string {
+	if label := strings.TrimSpace(peer.Label); label != "" {
+		return label
+	}
+	return peer.ID
+}
+
+// scopedStatusID scopes a status fetched elsewhere to its peer or collector: "zai" becomes
+// "zai:@<scope>" and "openai:org-abc" becomes "openai:@<scope>:org-abc", so the card keeps
+// its type.
+func scopedStatusID(scope, id string) string {
+	typ, rest, ok := strings.Cut(id, ":")
+	if ok {
+		return typ + ":@" + scope + ":" + rest
+	}
+	return typ + ":@" + scope
+}
+
+// remoteStatus is a status as another instance of the plugin serves it, with its data
+// kept as sent.
+type remoteStatus struct {
+	ServiceStatus
+	Data json.RawMessage `json:"data"`
+}
+
+// importStatuses turns the enabled statuses of a peer or collector into cards of this
+// server, labelled and scoped to their source.
+func importStatuses(scope, label string, remote []remoteStatus) []ServiceStatus {
+	var services []ServiceStatus
+	for _, r := range remote {
+		s := r.ServiceStatus
+		if !s.Enabled {
+			continue
+		}
+		s.Data = PeerStatusInfo{Peer: scope, raw: r.Data, usage: s.Usage, resetViews: s.Resets}
+		s.ID = scopedStatusID(scope, s.ID)
+		s.Name = label + " · " + s.Name
+		s.Usage, s.Resets = nil, nil
+		services = append(services, s)
+	}
+	return services
+}
+
+func parseFederationPeers(raw string) ([]federationPeer, error) {
+	var peers []federationPeer
+	if err := json.Unmarshal([]byte(raw), &peers); err != nil {
+		return nil, fmt.Errorf("not a JSON array of peers: %v", err)
+	}
+	seen := map[string]bool{}
+	for i, peer := range peers {
+		u, err := neturl.Parse(strings.TrimSpace(peer.URL))
+		switch {
+		case !customIDPattern.MatchString(peer.ID):
+			return nil, fmt.Errorf("peer %d: id must be lowercase letters, digits, - or _", i+1)
+		case seen[peer.ID]:
+			return nil, fmt.Errorf("peer %q: duplicate id", peer.ID)
+		case err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "":
+			return nil, fmt.Errorf("peer %q: url must be the peer's http(s) site URL", peer.ID)
+		case strings.TrimSpace(peer.Token) == "":
+			return nil, fmt.Errorf("peer %q: token is required", peer.ID)
+		}
+		seen[peer.ID] = true
+		peers[i].URL = strings.TrimRight(u.String(), "/")
+		token, err := resolveSecret(peer.Token)
+		if err != nil {
+			return nil, fmt.Errorf("peer %q: token: %v", peer.ID, err)
+		}
+		peers[i].Token = token
+	}
+	return peers, nil
+}
+
+// getPeerStatuses fetches the providers of every enabled peer.
+func (p *Plugin) getPeerStatuses(config *Configuration) []ServiceStatus {
+	peers, err := parseFederationPeers(config.FederationPeers)
+	if err != nil {
+		return []ServiceStatus{errorStatus("federation", "Federated servers", "error.federation_invalid", err.Error())}
+	}
+	var services []ServiceStatus
+	for _, peer := range peers {
+		if !peer.Disabled {
+			services = append(services, p.getPeerStatus(peer)...)
+		}
+	}
+	return services
+}
+
+func (p *Plugin) getPeerStatus(peer federationPeer) []ServiceStatus {
+	cacheKey := "federation_" + peer.ID
+	if cached, ok := p.getCached(cacheKey); ok {
+		return append([]ServiceStatus(nil), cached.([]ServiceStatus)...)
+	}
+
+	id, name := "federation:@"+peer.ID, peer.label()
+	client := p.providerClient(id, 20*time.Second)
+	req, _ := http.NewRequest("GET", peer.URL+"/plugins/"+pluginID+"/api/v1/status", nil)
+	req.Header.Set("Authorization", "Bearer "+peer.Token)
+	req.Header.Set(federationHeader, "1")
+	resp, err := client.Do(req)
+	if err != nil {
+		return []ServiceStatus{errorStatus(id, name, "error.federation_peer", err.Error())}
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return []ServiceStatus{errorStatus(id, name, "error.federation_peer", err.Error())}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return []ServiceStatus{errorStatus(id, name, "error.federation_peer", fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)])))}
+	}
+
+	var result struct {
+		Services []remoteStatus `json:"services"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return []ServiceStatus{errorStatus(id, name, "error.federation_peer", err.Error())}
+	}
+
+	services := importStatuses(peer.ID, name, result.Services)
+	p.setCache(cacheKey, services)
+	return append([]ServiceStatus(nil), services...)
+}
+
+// PeerStatusInfo is the data of a status fetched from a peer or pushed by a collector; Peer
+// is its ID. It is passed on to the webapp unchanged, so the card renders as on the peer; the usage and resets the peer
+// worked out stand in for the typed data the summaries use.
+type PeerStatusInfo struct {
+	Peer       string
+	raw        json.RawMessage
+	usage      *UsageMetrics
+	resetViews []ResetView
+}
+
+func (d PeerStatusInfo) MarshalJSON() ([]byte, error) {
+	if len(d.raw) == 0 {
+		return []byte("null"), nil
+	}
+	return d.raw, nil
+}
+
+func (d PeerStatusInfo) usagePercent() (float64, bool) {
+	if d.usage != nil && d.usage.Percent != nil {
+		return *d.usage.Percent, true
+	}
+	return 0, false
+}
+
+func (d PeerStatusInfo) resets(time.Time) []ResetInfo {
+	var resets []ResetInfo
+	for _, r := range d.resetViews {
+		if at, err := time.Parse(time.RFC3339, r.At); err == nil {
+			resets = append(resets, ResetInfo{Kind: r.Kind, At: at})
+		}
+	}
+	return resets
+}
+
+// summary describes the peer's usage figures.
+func (d PeerStatusInfo) summary(locale string) string {
+	u := d.usage
+	switch {
+	case u == nil:
+		return translate(locale, "summary.peer_connected")
+	case u.Cost != nil && u.CostLimit != nil:
+		return translate(locale, "summary.cost_of", formatMoney(*u.Cost, u.Currency, 2), formatMoney(*u.CostLimit, u.Currency, 0))
+	case u.Cost != nil:
+		return translate(locale, "summary.cost", formatMoney(*u.Cost, u.Currency, 2))
+	case u.Percent != nil:
+		return translate(locale, "summary.percent", *u.Percent)
+	case u.Used != nil:
+		return translate(locale, "summary.custom_used", formatCount(*u.Used), u.Unit)
+	}
+	return translate(locale, "summary.peer_connected")
+}
+
+func (d PeerStatusInfo) compact(name, locale string) string {
+	if pct, ok := d.usagePercent(); ok {
+		return fmt.Sprintf("%s %.0f%%", name, pct)
+	}
+	return name
+}
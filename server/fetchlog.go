@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ===== Fetch audit log =====
+
+const (
+	// fetchLogSize is how many fetches and requests the log keeps, and fetchErrorLogSize
+	// how many failures, so a burst of successes doesn't push out the failure being looked for.
+	fetchLogSize      = 500
+	fetchErrorLogSize = 200
+)
+
+// FetchLogEntry is a provider fetch, or an HTTP request a fetch sent.
+type FetchLogEntry struct {
+	Time       int64  `json:"time"` // Unix milliseconds
+	Kind       string `json:"kind"` // "fetch" or "request"
+	Provider   string `json:"provider"`
+	Scope      string `json:"scope,omitempty"` // "instance:<type>:<id>", or the user of personal credentials
+	Cache      string `json:"cache,omitempty"` // fetches: "fetched", "cached" or "backoff"
+	Cards      int    `json:"cards,omitempty"`
+	Method     string `json:"method,omitempty"`
+	URL        string `json:"url,omitempty"` // without the query, which may hold credentials
+	Status     int    `json:"status,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+	RequestID  string `json:"requestId,omitempty"` // shown on failed cards
+}
+
+// fetchLog keeps the latest entries in memory, shared by the plugin, its instances and
+// personal credentials. It is lost on restart; the server log has the same events.
+type fetchLog struct {
+	lock    sync.Mutex
+	entries []FetchLogEntry
+	next    int
+	errors  []FetchLogEntry
+	nextErr int
+}
+
+func appendRing(ring []FetchLogEntry, next *int, size int, e FetchLogEntry) []FetchLogEntry {
+	if len(ring) < size {
+		return append(ring, e)
+	}
+	ring[*next] = e
+	*next = (*next + 1) % size
+	return ring
+}
+
+// add records an entry; failures are kept in their own ring as well.
+func (l *fetchLog) add(e FetchLogEntry) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = appendRing(l.entries, &l.next, fetchLogSize, e)
+	if e.Error != "" {
+		l.errors = appendRing(l.errors, &l.nextErr, fetchErrorLogSize, e)
+	}
+}
+
+// recentEntries returns up to limit entries of a ring, newest first, matching provider unless
+// it's empty.
+func recentEntries(ring []FetchLogEntry, next int, provider string, limit int) []FetchLogEntry {
+	result := []FetchLogEntry{}
+	for i := 0; i < len(ring) && len(result) < limit; i++ {
+		e := ring[(next-1-i+2*len(ring))%len(ring)]
+		if provider == "" || cacheKeyProvider(e.Provider) == provider {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// logProviderRequest records an HTTP request of a provider fetch.
+func (p *Plugin) logProviderRequest(provider string, req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	u := *req.URL
+	u.RawQuery, u.User = "", nil
+	e := FetchLogEntry{
+		Time: time.Now().UnixMilli(), Kind: "request", Provider: provider, Scope: p.tokenScope,
+		Method: req.Method, URL: u.String(), DurationMs: duration.Milliseconds(),
+	}
+	switch {
+	case err != nil:
+		e.Error = err.Error()
+	case resp.StatusCode >= 400:
+		e.Status = resp.StatusCode
+		e.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	default:
+		e.Status = resp.StatusCode
+	}
+	p.fetchLog.add(e)
+	p.API.LogDebug("Provider request", "provider", provider, "method", e.Method, "url", e.URL,
+		"status", e.Status, "duration_ms", e.DurationMs, "error", e.Error)
+}
+
+// logFetch records a provider fetch and where its result came from. Fetches answered
+// from the cache are only written to the server log, since the poller makes one a
+// minute per provider.
+func (p *Plugin) logFetch(provider, cache string, start time.Time, statuses []ServiceStatus) {
+	e := FetchLogEntry{
+		Time: start.UnixMilli(), Kind: "fetch", Provider: provider, Scope: p.tokenScope,
+		Cache: cache, Cards: len(statuses), DurationMs: time.Since(start).Milliseconds(),
+	}
+	for _, s := range statuses {
+		if s.Error != "" {
+			e.Error, e.RequestID = s.ID+": "+s.Error, s.RequestID
+			break
+		}
+	}
+	if cache == "fetched" {
+		p.fetchLog.add(e)
+	}
+	p.API.LogDebug("Provider fetch", "provider", provider, "cache", cache, "cards", e.Cards,
+		"duration_ms", e.DurationMs, "error", e.Error, "request_id", e.RequestID)
+}
+
+// fetchCacheDecision tells a fresh fetch from one answered from the cache: cached cards
+// keep the time they were fetched.
+func fetchCacheDecision(statuses []ServiceStatus, start time.Time) string {
+	if len(statuses) == 0 {
+		return "fetched"
+	}
+	for _, s := range statuses {
+		if s.CachedAt == 0 || s.CachedAt >= start.Unix() {
+			return "fetched"
+		}
+	}
+	return "cached"
+}
+
+// handleFetchLog serves GET /api/v1/admin/log: the latest fetches and HTTP requests
+// (limit, 100 by default), newest first, and the latest failures. provider filters by
+// provider ID.
+func (p *Plugin) handleFetchLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 100
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > fetchLogSize {
+			http.Error(w, fmt.Sprintf(`{"error": "invalid_limit", "message": "limit must be between 1 and %d"}`, fetchLogSize), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	provider := query.Get("provider")
+
+	entries, errors := []FetchLogEntry{}, []FetchLogEntry{}
+	if l := p.fetchLog; l != nil {
+		l.lock.Lock()
+		entries = recentEntries(l.entries, l.next, provider, limit)
+		errors = recentEntries(l.errors, l.nextErr, provider, min(limit, fetchErrorLogSize))
+		l.lock.Unlock()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+		"errors":  errors,
+	})
+}
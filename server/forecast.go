@@ -0,0 +1,201 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// ===== Forecast =====
+
+// forecastWindowDays is how many recent days the burn rate is averaged over when the
+// history covers them, so a busy week shows up before the cycle average catches up.
+const forecastWindowDays = 7
+
+// Forecast projects a service's usage to the end of its billing cycle at the current
+// burn rate. Amounts are in the usage's unit, e.g. the currency for spend.
+type Forecast struct {
+	Unit             string   `json:"unit"`
+	Currency         string   `json:"currency,omitempty"`
+	PerDay           float64  `json:"perDay"`
+	Projected        float64  `json:"projected"` // at the end of the cycle
+	Limit            *float64 `json:"limit,omitempty"`
+	ProjectedPercent *float64 `json:"projectedPercent,omitempty"`
+	// Days until the limit is used up at this rate; nil without a limit or a burn
+	DaysUntilExhausted *float64 `json:"daysUntilExhausted,omitempty"`
+	ExhaustsAt         string   `json:"exhaustsAt,omitempty"` // when that's before the cycle ends
+	CycleEnd           string   `json:"cycleEnd"`
+	Basis              string   `json:"basis"`   // "cost_history", "usage_history" or "cycle_average"
+	Overrun            bool     `json:"overrun"` // the projection exceeds the limit
+}
+
+// forecastUnits are the units that add up over a billing cycle; percentages and seats
+// don't, so they aren't projected.
+var forecastUnits = map[string]bool{"cost": true, "credits": true, "tokens": true, "requests": true, "characters": true}
+
+// forecastCycle returns the service's monthly cycle, when it has one. Daily and hourly
+// quotas reset before a projection would mean anything.
+func forecastCycle(s ServiceStatus, now time.Time) (start, end time.Time, ok bool) {
+	for _, r := range serviceResets(s, now) {
+		switch r.Kind {
+		case "monthly_billing", "billing_cycle", "copilot_premium", "character_quota":
+			return r.At.AddDate(0, -1, 0), r.At, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// applyForecasts projects each service's usage to the end of its cycle, and turns an ok
+// status into a warning when the projection exceeds the limit. The burn rate comes from
+// the OpenAI cost history or the recorded usage history, or else the cycle's average.
+func (p *Plugin) applyForecasts(services []ServiceStatus, config *Configuration, now time.Time) {
+	var history map[string][]UsagePoint
+	if !config.DemoMode {
+		history = p.recentUsageHistory(now)
+	}
+	for i, s := range services {
+		// Peers forecast their own cards
+		if _, peer := s.Data.(PeerStatusInfo); peer || !s.Enabled || s.Error != "" {
+			continue
+		}
+		usage := computeUsage(s)
+		if usage == nil || usage.Used == nil || !forecastUnits[usage.Unit] {
+			continue
+		}
+		start, end, ok := forecastCycle(s, now)
+		if !ok {
+			continue
+		}
+
+		var perDay float64
+		basis := ""
+		if _, isOpenAI := s.Data.(OpenAIUsageInfo); isOpenAI && !config.DemoMode {
+			perDay, ok = p.costHistoryRate(s.ID, start, now)
+			basis = "cost_history"
+		} else {
+			perDay, ok = usageHistoryRate(history[s.ID], *usage.Used, start, now)
+			basis = "usage_history"
+		}
+		if !ok {
+			perDay, ok = cycleAverageRate(*usage.Used, start, now)
+			basis = "cycle_average"
+		}
+		if !ok {
+			continue
+		}
+
+		f := newForecast(usage, perDay, basis, end, now)
+		services[i].Forecast = f
+		if f.Overrun && s.Status == "ok" {
+			services[i].Status = "warning"
+		}
+	}
+}
+
+// newForecast projects the usage at perDay until the cycle ends. Values are rounded, so
+// the response's ETag doesn't change with every fetch.
+func newForecast(usage *UsageMetrics, perDay float64, basis string, end, now time.Time) *Forecast {
+	used := *usage.Used
+	projected := used + perDay*end.Sub(now).Hours()/24
+	f := &Forecast{
+		Unit: usage.Unit, Currency: usage.Currency,
+		PerDay: math.Round(perDay*100) / 100, Projected: math.Round(projected*100) / 100,
+		CycleEnd: end.UTC().Format(time.RFC3339), Basis: basis,
+	}
+	if usage.Limit == nil || *usage.Limit <= 0 {
+		return f
+	}
+	limit := *usage.Limit
+	f.Limit = floatPtr(limit)
+	f.ProjectedPercent = floatPtr(math.Round(projected/limit*1000) / 10)
+	f.Overrun = projected > limit
+	if perDay > 0 && used < limit {
+		days := (limit - used) / perDay
+		f.DaysUntilExhausted = floatPtr(math.Round(days*10) / 10)
+		if at := now.Add(time.Duration(days * float64(24*time.Hour))); at.Before(end) {
+			f.ExhaustsAt = at.UTC().Format(time.RFC3339)
+		}
+	}
+	return f
+}
+
+// costHistoryRate averages an OpenAI organization's stored daily costs over the last
+// full days of the cycle, leaving out today's partial bucket.
+func (p *Plugin) costHistoryRate(statusID string, cycleStart, now time.Time) (float64, bool) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -forecastWindowDays)
+	if start := cycleStart.UTC().Truncate(24 * time.Hour); from.Before(start) {
+		from = start
+	}
+	days := today.Sub(from).Hours() / 24
+	if days < 1 {
+		return 0, false
+	}
+	return p.costBetween(statusID, from, today) / days, true
+}
+
+// usageHistoryRate is the growth per day since the oldest recorded day of the window
+// within the cycle. A drop means the counter was reset, so there's no rate to tell.
+func usageHistoryRate(points []UsagePoint, used float64, cycleStart, now time.Time) (float64, bool) {
+	windowStart := now.AddDate(0, 0, -forecastWindowDays)
+	if windowStart.Before(cycleStart) {
+		windowStart = cycleStart
+	}
+	for _, point := range points {
+		// A daily point holds the day's last sample, so it's as of the day's end
+		at := time.Unix(point.Time, 0).AddDate(0, 0, 1)
+		if point.Used == nil || time.Unix(point.Time, 0).Before(windowStart) {
+			continue
+		}
+		days := now.Sub(at).Hours() / 24
+		if days < 1 || used < *point.Used {
+			return 0, false
+		}
+		return (used - *point.Used) / days, true
+	}
+	return 0, false
+}
+
+// cycleAverageRate spreads the usage so far over the days the cycle has run. It waits for
+// a full day, since a morning's usage says little about the month.
+func cycleAverageRate(used float64, cycleStart, now time.Time) (float64, bool) {
+	days := now.Sub(cycleStart).Hours() / 24
+	if days < 1 {
+		return 0, false
+	}
+	return used / days, true
+}
+
+// recentUsageHistory returns each service's daily points of this month and the last,
+// oldest first.
+func (p *Plugin) recentUsageHistory(now time.Time) map[string][]UsagePoint {
+	now = now.UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	history := map[string][]UsagePoint{}
+	p.historyLock.Lock()
+	defer p.historyLock.Unlock()
+	for _, month := range []time.Time{thisMonth.AddDate(0, -1, 0), thisMonth} {
+		for id, series := range p.loadUsageBucket(dailyUsageKey(month.Format("2006-01"))) {
+			history[id] = append(history[id], series.Points...)
+		}
+	}
+	return history
+}
+
+// forecastText renders a projected overrun as "On track for X of Y by <date>", or "" when
+// the service isn't heading over its limit.
+func forecastText(f *Forecast, locale string) string {
+	if f == nil || !f.Overrun || f.Limit == nil {
+		return ""
+	}
+	amount := func(v float64) string {
+		if f.Unit == "cost" {
+			return formatMoney(v, f.Currency, 0)
+		}
+		return formatCount(v)
+	}
+	end := parseTime(f.CycleEnd).Format("2006-01-02")
+	if f.ExhaustsAt != "" {
+		return translate(locale, "forecast.exhausts", amount(f.Projected), amount(*f.Limit), end, parseTime(f.ExhaustsAt).Format("2006-01-02"))
+	}
+	return translate(locale, "forecast.overrun", amount(f.Projected), amount(*f.Limit), end)
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Generic OpenAI-compatible gateway (base URL, optional key and usage endpoint) =====
+
+const defaultGatewayName = "OpenAI-compatible Gateway"
+
+// GatewayInfo is the state of an OpenAI-compatible endpoint: whether it answers and accepts
+// the key, the rate limits it advertises in headers, and usage read from an optional
+// endpoint. Gateways differ widely, so every part but reachability is best effort.
+type GatewayInfo struct {
+	Models            int     `json:"models"`
+	LatencyMs         int64   `json:"latencyMs"`
+	HasRateLimits     bool    `json:"hasRateLimits"`
+	RequestsLimit     float64 `json:"requestsLimit,omitempty"`
+	RequestsRemaining float64 `json:"requestsRemaining,omitempty"`
+	TokensLimit       float64 `json:"tokensLimit,omitempty"`
+	TokensRemaining   float64 `json:"tokensRemaining,omitempty"`
+	HasUsage          bool    `json:"hasUsage"`
+	Used              float64 `json:"used,omitempty"`
+	Limit             float64 `json:"limit,omitempty"`
+	Unit              string  `json:"unit,omitempty"` // "cost", "requests", "tokens" or "credits"
+	Currency          string  `json:"currency,omitempty"`
+	UsageError        string  `json:"usageError,omitempty"`
+}
+
+// usagePercent is the share of the usage limit consumed, or of the advertised rate
+// limits when the gateway has no usage endpoint.
+func (g GatewayInfo) usagePercent() (float64, bool) {
+	if g.HasUsage && g.Limit > 0 {
+		return g.Used / g.Limit * 100, true
+	}
+	if !g.HasRateLimits {
+		return 0, false
+	}
+	pct := 0.0
+	if g.RequestsLimit > 0 {
+		pct = (g.RequestsLimit - g.RequestsRemaining) / g.RequestsLimit * 100
+	}
+	if g.TokensLimit > 0 {
+		pct = max(pct, (g.TokensLimit-g.TokensRemaining)/g.TokensLimit*100)
+	}
+	return pct, true
+}
+
+func (g GatewayInfo) summary(locale string) string {
+	parts := []string{translate(locale, "summary.gateway", g.Models, g.LatencyMs)}
+	switch {
+	case g.HasUsage && g.Unit == "cost" && g.Limit > 0:
+		parts = append(parts, translate(locale, "summary.gateway_budget", formatMoney(g.Used, g.Currency, 2), formatMoney(g.Limit, g.Currency, 0)))
+	case g.HasUsage && g.Unit == "cost":
+		parts = append(parts, translate(locale, "summary.gateway_spent", formatMoney(g.Used, g.Currency, 2)))
+	case g.HasUsage && g.Limit > 0:
+		parts = append(parts, translate(locale, "summary.gateway_usage", formatCount(g.Used), formatCount(g.Limit), g.Unit))
+	case g.HasRateLimits && g.RequestsLimit > 0:
+		parts = append(parts, translate(locale, "summary.gateway_rate_limit", formatCount(g.RequestsRemaining), formatCount(g.RequestsLimit)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// gatewayModelsResponse is GET /v1/models.
+type gatewayModelsResponse struct {
+	Data []struct {
+		ID string `json:"id" schema:"required"`
+	} `json:"data" schema:"required"`
+}
+
+// gatewayName is the configured display name of the gateway.
+func (c *Configuration) gatewayName() string {
+	if name := strings.TrimSpace(c.GatewayName); name != "" {
+		return name
+	}
+	return defaultGatewayName
+}
+
+// gatewayUsageUnit is the configured unit of the usage endpoint's numbers.
+func (c *Configuration) gatewayUsageUnit() string {
+	switch c.GatewayUsageUnit {
+	case "requests", "tokens", "credits":
+		return c.GatewayUsageUnit
+	}
+	return "cost"
+}
+
+func (p *Plugin) getGatewayStatus(config *Configuration) ServiceStatus {
+	id, name := "gateway", config.gatewayName()
+	if strings.TrimSpace(config.GatewayUrl) == "" {
+		return errorStatus(id, name, "error.gateway_url_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	start := time.Now()
+	resp, err := client.Do(newGatewayModelsRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	info := GatewayInfo{LatencyMs: time.Since(start).Milliseconds()}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var models gatewayModelsResponse
+	drift, err := decodeResponse(body, &models)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+	info.Models = len(models.Data)
+
+	// OpenAI-style rate limit headers, which gateways such as LiteLLM and most corporate
+	// proxies pass through or set themselves
+	if limit, ok := headerFloat(resp.Header, "x-ratelimit-limit-requests"); ok {
+		info.HasRateLimits = true
+		info.RequestsLimit = limit
+		info.RequestsRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-requests")
+	}
+	if limit, ok := headerFloat(resp.Header, "x-ratelimit-limit-tokens"); ok {
+		info.HasRateLimits = true
+		info.TokensLimit = limit
+		info.TokensRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-tokens")
+	}
+
+	if strings.TrimSpace(config.GatewayUsagePath) != "" {
+		if err := p.fetchGatewayUsage(client, config, &info); err != nil {
+			// The endpoint answered, so the card still shows it as up
+			info.UsageError = err.Error()
+		}
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: gatewayStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// gatewayStatus is an error once the usage limit is reached, and warns when the usage
+// endpoint fails or a rate limit is close.
+func gatewayStatus(info GatewayInfo, config *Configuration) string {
+	if info.HasUsage && info.Limit > 0 && info.Used >= info.Limit {
+		return "error"
+	}
+	if info.UsageError != "" {
+		return "warning"
+	}
+	if pct, ok := info.usagePercent(); ok && pct > config.warningPercent(90) {
+		return "warning"
+	}
+	return "ok"
+}
+
+// fetchGatewayUsage reads the used and limit values from the configured usage endpoint.
+func (p *Plugin) fetchGatewayUsage(client *http.Client, config *Configuration, info *GatewayInfo) error {
+	req, err := newGatewayUsageRequest(config)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return err
+	}
+	used, ok := jsonNumberAt(doc, config.GatewayUsedField)
+	if !ok {
+		return fmt.Errorf("no number at %q", config.GatewayUsedField)
+	}
+	info.HasUsage = true
+	info.Used = used
+	info.Unit = config.gatewayUsageUnit()
+	if info.Unit == "cost" {
+		info.Currency = baseCurrency
+	}
+	if field := strings.TrimSpace(config.GatewayLimitField); field != "" {
+		info.Limit, _ = jsonNumberAt(doc, field)
+	}
+	return nil
+}
+
+// jsonNumberAt returns the number at a path such as "info.spend", "data.0.usage" or
+// "$.data[0].usage". Numeric strings count as numbers.
+func jsonNumberAt(doc any, path string) (float64, bool) {
+	switch v, _ := jsonValueAt(doc, path); v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonValueAt returns the value at a dot-separated path, which may also be written
+// JSONPath-style with a leading "$" and bracketed array indexes.
+func jsonValueAt(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, false
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := doc.(type) {
+		case map[string]any:
+			doc = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			doc = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, doc != nil
+}
+
+// gatewayModelsURL is the models endpoint, whether or not the base URL includes /v1.
+func (c *Configuration) gatewayModelsURL() string {
+	base := strings.TrimRight(strings.TrimSpace(c.GatewayUrl), "/")
+	if strings.HasSuffix(base, "/v1") {
+		return base + "/models"
+	}
+	return base + "/v1/models"
+}
+
+func newGatewayModelsRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", config.gatewayModelsURL(), nil)
+	setGatewayHeaders(req, config)
+	return req
+}
+
+// newGatewayUsageRequest builds the usage request. The path is resolved against the base
+// URL, so "/key/info" is on the server root and a full URL is used as is.
+func newGatewayUsageRequest(config *Configuration) (*http.Request, error) {
+	base, err := neturl.Parse(strings.TrimRight(strings.TrimSpace(config.GatewayUrl), "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	ref, err := neturl.Parse(strings.TrimSpace(config.GatewayUsagePath))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", base.ResolveReference(ref).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	setGatewayHeaders(req, config)
+	return req, nil
+}
+
+func setGatewayHeaders(req *http.Request, config *Configuration) {
+	if config.GatewayApiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+config.GatewayApiKey)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+}
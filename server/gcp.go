@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== Google Cloud service account authentication =====
+
+// gcpServiceAccount is the part of a service account key file needed to get access tokens.
+type gcpServiceAccount struct {
+	Type        string `json:"type"`
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+func parseServiceAccount(data string) (gcpServiceAccount, error) {
+	var sa gcpServiceAccount
+	if err := json.Unmarshal([]byte(data), &sa); err != nil {
+		return sa, fmt.Errorf("invalid service account key: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return sa, errors.New("service account key is missing client_email or private_key")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return sa, nil
+}
+
+// gcpAccessToken exchanges a signed JWT for an OAuth access token with the given scopes.
+func (p *Plugin) gcpAccessToken(client *http.Client, sa gcpServiceAccount, scopes ...string) (string, error) {
+	req, err := newGCPTokenRequest(sa, scopes, time.Now())
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	if resp.StatusCode != 200 || token.AccessToken == "" {
+		return "", fmt.Errorf("HTTP %d: %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	return token.AccessToken, nil
+}
+
+// newGCPTokenRequest builds the JWT bearer grant for the service account.
+func newGCPTokenRequest(sa gcpServiceAccount, scopes []string, now time.Time) (*http.Request, error) {
+	assertion, err := sa.signedJWT(strings.Join(scopes, " "), now)
+	if err != nil {
+		return nil, err
+	}
+	form := neturl.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, _ := http.NewRequest("POST", sa.TokenURI, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req, nil
+}
+
+// signedJWT returns an RS256-signed assertion valid for an hour.
+func (sa gcpServiceAccount) signedJWT(scope string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return "", errors.New("service account private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("service account private key is not an RSA key")
+	}
+
+	encode := func(v any) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := encode(map[string]string{"alg": "RS256", "typ": "JWT"}) + "." + encode(map[string]any{
+		"iss":   sa.ClientEmail,
+		"scope": scope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
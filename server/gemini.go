@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== Google Gemini API (API key and/or service account) =====
+
+// geminiQuotaMetrics are the Gemini API's per-model request quotas, per minute and per day.
+var geminiQuotaMetrics = []modelQuotaMetric{
+	{"generativelanguage.googleapis.com/quota/generate_requests_per_model", "model"},
+	{"generativelanguage.googleapis.com/quota/generate_requests_per_model_per_day", "model"},
+}
+
+// geminiScopes are the OAuth scopes requested for the service account: Service Usage to
+// check the API is enabled, and Monitoring for the quotas.
+var geminiScopes = []string{
+	"https://www.googleapis.com/auth/cloud-platform.read-only",
+	"https://www.googleapis.com/auth/monitoring.read",
+}
+
+// GeminiInfo is the Gemini API as Google AI Studio keys use it: the models the API key can
+// generate with, and, with a service account, the per-model quotas of the key's project.
+// The API key alone shows no usage, since the Gemini API doesn't report any to it. Spend
+// is on the Vertex AI card, which reads it from the billing export.
+type GeminiInfo struct {
+	HasKey   bool               `json:"hasKey"`
+	Models   int                `json:"models,omitempty"`
+	Project  string             `json:"project,omitempty"`
+	HasQuota bool               `json:"hasQuota"`
+	Quotas   []VertexModelQuota `json:"quotas,omitempty"` // most utilized first
+	DayReset string             `json:"dayReset,omitempty"`
+}
+
+// peak is the quota closest to its limit.
+func (g GeminiInfo) peak() (VertexModelQuota, bool) {
+	if len(g.Quotas) == 0 {
+		return VertexModelQuota{}, false
+	}
+	return g.Quotas[0], true
+}
+
+func (g GeminiInfo) summary(locale string) string {
+	if peak, ok := g.peak(); ok {
+		return translate(locale, "summary.gemini_quota_"+peak.Window, peak.Model, formatCount(peak.Usage), formatCount(peak.Limit))
+	}
+	if g.HasKey {
+		return translate(locale, "summary.models_available", g.Models)
+	}
+	return translate(locale, "summary.gemini_no_data")
+}
+
+func (g GeminiInfo) compact(name, locale string) string {
+	if peak, ok := g.peak(); ok {
+		return fmt.Sprintf("%s %s %.0f%%", name, peak.Model, peak.Percent)
+	}
+	return name
+}
+
+func (g GeminiInfo) usagePercent() (float64, bool) {
+	if peak, ok := g.peak(); ok {
+		return peak.Percent, true
+	}
+	return 0, false
+}
+
+func (g GeminiInfo) resets(time.Time) []ResetInfo {
+	if peak, ok := g.peak(); ok && peak.Window == "day" {
+		return []ResetInfo{{Kind: "daily_quota", At: parseTime(g.DayReset)}}
+	}
+	return nil
+}
+
+// geminiModelsResponse is GET /v1beta/models.
+type geminiModelsResponse struct {
+	Models []struct {
+		Name                       string   `json:"name"`
+		DisplayName                string   `json:"displayName"`
+		InputTokenLimit            int      `json:"inputTokenLimit"`
+		OutputTokenLimit           int      `json:"outputTokenLimit"`
+		SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+	} `json:"models" schema:"required"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// geminiServiceResponse is Service Usage's services.get.
+type geminiServiceResponse struct {
+	Name  string `json:"name"`
+	State string `json:"state" schema:"required"` // "ENABLED" or "DISABLED"
+}
+
+// geminiProvider reads the Gemini API with an API key, a service account, or both.
+type geminiProvider struct{}
+
+func (geminiProvider) ID() string   { return "gemini" }
+func (geminiProvider) Name() string { return "Google Gemini API" }
+
+func (geminiProvider) Enabled(config *Configuration) bool {
+	return config.GeminiEnabled
+}
+
+func (geminiProvider) EvaluateStatus(data interface{}, config *Configuration) string {
+	return geminiStatus(data.(GeminiInfo), config)
+}
+
+func (g geminiProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	hasKey := strings.TrimSpace(config.GeminiApiKey) != ""
+	hasAccount := strings.TrimSpace(config.GeminiServiceAccountKey) != ""
+	if !hasKey && !hasAccount {
+		return nil, errFetch("error.gemini_credentials_missing")
+	}
+
+	client := p.providerClient(g.ID(), 30*time.Second)
+	info := GeminiInfo{HasKey: hasKey}
+	if hasKey {
+		var models geminiModelsResponse
+		if err := p.getJSON(g.ID(), client, newGeminiModelsRequest(config), &models); err != nil {
+			return nil, err
+		}
+		for _, m := range models.Models {
+			for _, method := range m.SupportedGenerationMethods {
+				if method == "generateContent" {
+					info.Models++
+					break
+				}
+			}
+		}
+	}
+	if !hasAccount {
+		return info, nil
+	}
+
+	sa, err := parseServiceAccount(config.GeminiServiceAccountKey)
+	if err != nil {
+		return nil, err
+	}
+	project := strings.TrimSpace(config.GeminiProject)
+	if project == "" {
+		project = sa.ProjectID
+	}
+	if project == "" {
+		return nil, errFetch("error.gcp_projects_missing")
+	}
+	token, err := p.gcpAccessToken(client, sa, geminiScopes...)
+	if err != nil {
+		return nil, err
+	}
+	var service geminiServiceResponse
+	if err := p.getJSON(g.ID(), client, newGeminiServiceRequest(token, project), &service); err != nil {
+		return nil, err
+	}
+	if service.State != "ENABLED" {
+		return nil, errFetch("error.gemini_api_disabled", project)
+	}
+
+	now := time.Now().UTC()
+	usage := VertexProjectUsage{ProjectID: project}
+	drift, err := p.vertexModelQuotas(client, token, &usage, geminiQuotaMetrics, now)
+	if err := p.checkSchema(g.ID(), drift); err != nil {
+		return nil, errFetch("error.response_format", err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	info.Project, info.HasQuota, info.Quotas = project, usage.HasQuota, usage.Models
+	info.DayReset = vertexQuotaDayStart(now).AddDate(0, 0, 1).UTC().Format(time.RFC3339)
+	return info, nil
+}
+
+// geminiStatus is an error while a model's quota is used up, and a warning above the
+// warning threshold.
+func geminiStatus(info GeminiInfo, config *Configuration) string {
+	peak, ok := info.peak()
+	switch {
+	case !ok:
+		return "ok"
+	case peak.Percent >= 100:
+		return "error"
+	case peak.Percent > config.warningPercent(80):
+		return "warning"
+	}
+	return "ok"
+}
+
+func newGeminiModelsRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://generativelanguage.googleapis.com/v1beta/models?pageSize=1000", nil)
+	req.Header.Set("x-goog-api-key", config.GeminiApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+func newGeminiServiceRequest(token, project string) *http.Request {
+	req, _ := http.NewRequest("GET", "https://serviceusage.googleapis.com/v1/projects/"+neturl.PathEscape(project)+"/services/generativelanguage.googleapis.com", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== GigaChat (authorization key) =====
+
+// GigaChatInfo is the tokens left in each prepaid package. Only prepaid accounts have a
+// balance; postpaid corporate accounts get an empty list.
+type GigaChatInfo struct {
+	Scope     string            `json:"scope"`
+	Packages  []GigaChatPackage `json:"packages"`
+	LowTokens float64           `json:"lowTokens,omitempty"`
+}
+
+func (g GigaChatInfo) summary(locale string) string {
+	if len(g.Packages) == 0 {
+		return translate(locale, "summary.gigachat_postpaid")
+	}
+	var parts []string
+	for _, pkg := range g.Packages {
+		parts = append(parts, pkg.Usage+": "+formatCount(pkg.Remaining))
+	}
+	return translate(locale, "summary.gigachat", strings.Join(parts, ", "))
+}
+
+func (g GigaChatInfo) compact(name, locale string) string {
+	if len(g.Packages) > 0 {
+		lowest := g.Packages[0].Remaining
+		for _, pkg := range g.Packages[1:] {
+			lowest = min(lowest, pkg.Remaining)
+		}
+		return translate(locale, "compact.tokens_left", name, formatCount(lowest))
+	}
+	return name
+}
+
+// GigaChatPackage is the balance of one package, e.g. "GigaChat-Pro" or "embeddings".
+type GigaChatPackage struct {
+	Usage     string  `json:"usage"`
+	Remaining float64 `json:"remaining"`
+}
+
+// gigaChatBalanceResponse is GET /api/v1/balance.
+type gigaChatBalanceResponse struct {
+	Balance []struct {
+		Usage string    `json:"usage" schema:"required"`
+		Value flexFloat `json:"value" schema:"required"`
+	} `json:"balance" schema:"required"`
+}
+
+// gigaChatScope is the API scope the authorization key was issued for.
+func (c *Configuration) gigaChatScope() string {
+	switch c.GigachatScope {
+	case "GIGACHAT_API_B2B", "GIGACHAT_API_CORP":
+		return c.GigachatScope
+	}
+	return "GIGACHAT_API_PERS"
+}
+
+func (p *Plugin) getGigaChatStatus(config *Configuration) ServiceStatus {
+	const id, name = "gigachat", "GigaChat"
+	if config.GigachatAuthKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	token, err := p.gigaChatToken(client, config)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	resp, err := client.Do(newGigaChatBalanceRequest(token))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var balance gigaChatBalanceResponse
+	drift, err := decodeResponse(body, &balance)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info := GigaChatInfo{Scope: config.gigaChatScope(), Packages: []GigaChatPackage{}}
+	for _, b := range balance.Balance {
+		info.Packages = append(info.Packages, GigaChatPackage{Usage: b.Usage, Remaining: float64(b.Value)})
+	}
+	sort.Slice(info.Packages, func(i, j int) bool { return info.Packages[i].Usage < info.Packages[j].Usage })
+	if low, err := strconv.ParseFloat(strings.TrimSpace(config.GigachatLowTokens), 64); err == nil && low > 0 {
+		info.LowTokens = low
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: gigaChatStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// gigaChatStatus is an error once every package is used up, and a warning when any package
+// is below the configured number of tokens.
+func gigaChatStatus(info GigaChatInfo) string {
+	if len(info.Packages) == 0 {
+		return "ok"
+	}
+	empty, low := true, false
+	for _, pkg := range info.Packages {
+		if pkg.Remaining > 0 {
+			empty = false
+		}
+		if info.LowTokens > 0 && pkg.Remaining < info.LowTokens {
+			low = true
+		}
+	}
+	switch {
+	case empty:
+		return "error"
+	case low:
+		return "warning"
+	}
+	return "ok"
+}
+
+// gigaChatToken exchanges the authorization key for an access token, which is valid for
+// 30 minutes.
+func (p *Plugin) gigaChatToken(client *http.Client, config *Configuration) (string, error) {
+	resp, err := client.Do(newGigaChatTokenRequest(config))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Message     string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	if resp.StatusCode != 200 || token.AccessToken == "" {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, token.Message)
+	}
+	return token.AccessToken, nil
+}
+
+func newGigaChatTokenRequest(config *Configuration) *http.Request {
+	form := neturl.Values{"scope": {config.gigaChatScope()}}
+	req, _ := http.NewRequest("POST", "https://ngw.devices.sberbank.ru:9443/api/v2/oauth", strings.NewReader(form.Encode()))
+	req.Header.Set("Authorization", "Basic "+strings.TrimSpace(config.GigachatAuthKey))
+	req.Header.Set("RqUID", newRequestUUID())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+func newGigaChatBalanceRequest(token string) *http.Request {
+	req, _ := http.NewRequest("GET", "https://gigachat.devices.sberbank.ru/api/v1/balance", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// newRequestUUID returns a random version 4 UUID, which the token endpoint requires as RqUID.
+func newRequestUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
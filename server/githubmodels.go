@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== GitHub Models (GitHub token) =====
+
+const defaultGithubModels = "openai/gpt-4.1-mini, openai/gpt-4.1"
+
+// githubModelsTierLimits are the free-use allowances of each model tier by Copilot plan:
+// requests per minute, requests per day, input tokens per request and concurrent requests.
+// Copilot Free and Pro share the same allowances. Custom tiers have per-model limits.
+var githubModelsTierLimits = map[string]map[string][4]float64{
+	"free": {
+		"low":        {15, 150, 8000, 5},
+		"high":       {10, 50, 8000, 2},
+		"embeddings": {15, 150, 64000, 5},
+	},
+	"business": {
+		"low":        {15, 300, 8000, 5},
+		"high":       {10, 100, 8000, 2},
+		"embeddings": {15, 300, 64000, 5},
+	},
+	"enterprise": {
+		"low":        {20, 450, 8000, 8},
+		"high":       {15, 150, 16000, 4},
+		"embeddings": {20, 450, 64000, 8},
+	},
+}
+
+// GithubModelsInfo is the free-use rate limit headroom of GitHub Models for the monitored
+// models, grouped by their rate limit tier. Like Groq, the remaining requests are only
+// reported on inference responses, so each model costs one minimal request.
+type GithubModelsInfo struct {
+	Plan         string             `json:"plan"`
+	Organization string             `json:"organization,omitempty"` // requests are attributed to the org
+	Tiers        []GithubModelsTier `json:"tiers"`
+}
+
+// GithubModelsTier is one rate limit tier's allowance for the plan and the headroom of the
+// monitored model in it.
+type GithubModelsTier struct {
+	Tier              string  `json:"tier"`
+	Model             string  `json:"model"`
+	CatalogModels     int     `json:"catalogModels"` // models in the catalog sharing the tier
+	RequestsPerMinute float64 `json:"requestsPerMinute,omitempty"`
+	RequestsPerDay    float64 `json:"requestsPerDay,omitempty"`
+	TokensPerRequest  float64 `json:"tokensPerRequest,omitempty"`
+	Concurrent        float64 `json:"concurrent,omitempty"`
+	RequestsLimit     float64 `json:"requestsLimit"`
+	RequestsRemaining float64 `json:"requestsRemaining"`
+	RequestsReset     string  `json:"requestsReset,omitempty"`
+	Throttled         bool    `json:"throttled,omitempty"`
+	Error             string  `json:"error,omitempty"`
+}
+
+// percent is the share of the reported request limit consumed.
+func (t GithubModelsTier) percent() float64 {
+	switch {
+	case t.Throttled:
+		return 100
+	case t.RequestsLimit <= 0:
+		return 0
+	}
+	return (t.RequestsLimit - t.RequestsRemaining) / t.RequestsLimit * 100
+}
+
+// mostConstrained returns the reachable tier with the least headroom, or false if none was reachable.
+func (d GithubModelsInfo) mostConstrained() (GithubModelsTier, bool) {
+	var worst GithubModelsTier
+	found := false
+	for _, t := range d.Tiers {
+		if t.Error != "" {
+			continue
+		}
+		if !found || t.percent() > worst.percent() {
+			worst, found = t, true
+		}
+	}
+	return worst, found
+}
+
+func (d GithubModelsInfo) summary(locale string) string {
+	t, ok := d.mostConstrained()
+	switch {
+	case !ok:
+		return ""
+	case t.Throttled:
+		return translate(locale, "summary.githubmodels_throttled", t.Model, t.Tier)
+	case t.RequestsLimit > 0:
+		return translate(locale, "summary.githubmodels", t.Model, t.Tier, formatCount(t.RequestsRemaining), formatCount(t.RequestsLimit))
+	}
+	return translate(locale, "summary.githubmodels_allowance", t.Tier, formatCount(t.RequestsPerDay), formatCount(t.RequestsPerMinute))
+}
+
+func (d GithubModelsInfo) usagePercent() (float64, bool) {
+	if t, ok := d.mostConstrained(); ok && (t.Throttled || t.RequestsLimit > 0) {
+		return t.percent(), true
+	}
+	return 0, false
+}
+
+func (d GithubModelsInfo) resets(time.Time) []ResetInfo {
+	if t, ok := d.mostConstrained(); ok {
+		return []ResetInfo{{Kind: "daily_quota", At: parseTime(t.RequestsReset)}}
+	}
+	return nil
+}
+
+// githubModelsCatalogResponse is GET /catalog/models.
+type githubModelsCatalogResponse []struct {
+	ID            string `json:"id" schema:"required"`
+	Name          string `json:"name"`
+	Publisher     string `json:"publisher"`
+	RateLimitTier string `json:"rate_limit_tier" schema:"required"`
+}
+
+// githubModelsPlan is the configured Copilot plan, which sets the free-use allowances.
+func (c *Configuration) githubModelsPlan() string {
+	switch plan := strings.ToLower(strings.TrimSpace(c.GithubModelsPlan)); plan {
+	case "business", "enterprise":
+		return plan
+	}
+	return "free"
+}
+
+// githubModels is the configured list of models to monitor.
+func (c *Configuration) githubModels() []string {
+	models := splitList(c.GithubModelsModels)
+	if len(models) == 0 {
+		return splitList(defaultGithubModels)
+	}
+	return models
+}
+
+func (p *Plugin) getGithubModelsStatus(config *Configuration) ServiceStatus {
+	const id, name = "githubmodels", "GitHub Models"
+	if config.GithubModelsToken == "" {
+		return errorStatus(id, name, "error.access_token_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newGithubModelsCatalogRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var catalog githubModelsCatalogResponse
+	drift, err := decodeResponse(body, &catalog)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	tierOf := map[string]string{}
+	tierSize := map[string]int{}
+	for _, m := range catalog {
+		tier := strings.ToLower(m.RateLimitTier)
+		tierOf[strings.ToLower(m.ID)] = tier
+		tierSize[tier]++
+	}
+
+	info := GithubModelsInfo{Plan: config.githubModelsPlan(), Organization: strings.TrimSpace(config.GithubModelsOrg)}
+	now := time.Now().UTC()
+	failed := 0
+	for _, model := range config.githubModels() {
+		tier := GithubModelsTier{Tier: tierOf[strings.ToLower(model)], Model: model}
+		if tier.Tier == "" {
+			tier.Tier = "custom"
+		}
+		tier.CatalogModels = tierSize[tier.Tier]
+		if limits, ok := githubModelsTierLimits[info.Plan][tier.Tier]; ok {
+			tier.RequestsPerMinute, tier.RequestsPerDay, tier.TokensPerRequest, tier.Concurrent = limits[0], limits[1], limits[2], limits[3]
+		}
+		if err := p.fetchGithubModelsLimits(client, config, &tier, now); err != nil {
+			// An invalid token fails every model the same way
+			if errAuth, ok := err.(authError); ok {
+				return errorStatus(id, name, "error.http", errAuth.status, errAuth.message)
+			}
+			tier.Error = err.Error()
+			failed++
+		}
+		info.Tiers = append(info.Tiers, tier)
+	}
+	if failed == len(info.Tiers) {
+		return errorStatus(id, name, "error.api", info.Tiers[0].Error)
+	}
+	sort.SliceStable(info.Tiers, func(i, j int) bool { return info.Tiers[i].percent() > info.Tiers[j].percent() })
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: githubModelsStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// githubModelsStatus is an error while any monitored tier is throttled, which lasts until its
+// window resets, and a warning past the warning threshold of a tier's requests.
+func githubModelsStatus(info GithubModelsInfo, config *Configuration) string {
+	status := "ok"
+	for _, t := range info.Tiers {
+		if t.Error != "" {
+			continue
+		}
+		if t.Throttled || (t.RequestsLimit > 0 && t.RequestsRemaining <= 0) {
+			return "error"
+		}
+		if t.percent() > config.warningPercent(80) {
+			status = "warning"
+		}
+	}
+	return status
+}
+
+// fetchGithubModelsLimits sends a one-token completion and reads the rate limit headers.
+// A throttled model answers 429 with a Retry-After instead, which is a reading too.
+func (p *Plugin) fetchGithubModelsLimits(client *http.Client, config *Configuration, tier *GithubModelsTier, now time.Time) error {
+	resp, err := client.Do(newGithubModelsInferenceRequest(config, tier.Model))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 200 && resp.StatusCode != 429 {
+		var errResp struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		message := string(body[:min(len(body), 200)])
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			message = errResp.Error.Message
+		}
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			return authError{status: resp.StatusCode, message: message}
+		}
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, message)
+	}
+
+	tier.RequestsLimit, _ = headerFloat(resp.Header, "x-ratelimit-limit-requests")
+	tier.RequestsRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-requests")
+	tier.RequestsReset = groqResetTime(resp.Header.Get("x-ratelimit-reset-requests"), now)
+	if resp.StatusCode == 429 {
+		tier.Throttled = true
+		if seconds, ok := headerFloat(resp.Header, "retry-after"); ok && seconds > 0 {
+			tier.RequestsReset = now.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339)
+		}
+	}
+	return nil
+}
+
+func newGithubModelsCatalogRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://models.github.ai/catalog/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.GithubModelsToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// newGithubModelsInferenceRequest attributes the request to the organization when one is
+// configured, so it counts against the organization's allowance rather than the user's.
+func newGithubModelsInferenceRequest(config *Configuration, model string) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "."}},
+		"max_tokens": 1,
+	})
+	url := "https://models.github.ai/inference/chat/completions"
+	if org := strings.TrimSpace(config.GithubModelsOrg); org != "" {
+		url = "https://models.github.ai/orgs/" + neturl.PathEscape(org) + "/inference/chat/completions"
+	}
+	req, _ := http.NewRequest("POST", url, bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+config.GithubModelsToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
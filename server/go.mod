@@ -2,7 +2,11 @@ module github.com/alexander-voronkov/mattermost-ai-limits-monitor/server
 
 go 1.22
 
-require github.com/mattermost/mattermost/server/public v0.1.9
+require (
+	github.com/mattermost/mattermost/server/public v0.1.9
+	golang.org/x/crypto v0.25.0
+	gopkg.in/yaml.v2 v2.4.0
+)
 
 require (
 	filippo.io/edwards25519 v1.1.0 // indirect
@@ -37,7 +41,6 @@ require (
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 	github.com/wiggin77/merror v1.0.5 // indirect
 	github.com/wiggin77/srslog v1.0.1 // indirect
-	golang.org/x/crypto v0.25.0 // indirect
 	golang.org/x/net v0.27.0 // indirect
 	golang.org/x/sys v0.22.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
@@ -45,5 +48,4 @@ require (
 	google.golang.org/grpc v1.65.0 // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
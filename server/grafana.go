@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== Grafana annotations =====
+
+// sendGrafanaAnnotations writes an annotation for each threshold crossing or reset.
+func (p *Plugin) sendGrafanaAnnotations(config *Configuration, events []StatusEvent) {
+	locale := p.serverLocale()
+	client := p.outboundClient(10 * time.Second)
+	for _, ev := range events {
+		if ev.Type != EventThreshold && ev.Type != EventReset {
+			continue
+		}
+		if err := postGrafanaAnnotation(client, config, ev, locale); err != nil {
+			p.API.LogWarn("Failed to create Grafana annotation", "provider", ev.Provider, "error", err.Error())
+		}
+	}
+}
+
+func postGrafanaAnnotation(client *http.Client, config *Configuration, ev StatusEvent, locale string) error {
+	text := translate(locale, "alert.transition", ev.Name, ev.From, ev.To, ev.Summary)
+	if ev.Type == EventReset {
+		text = translate(locale, "alert.reset", ev.Name, ev.Summary)
+	}
+
+	annotation := map[string]interface{}{
+		"time": ev.Timestamp * 1000,
+		"tags": []string{"ai-limits", ev.Provider, ev.Type, ev.To},
+		"text": text,
+	}
+	if config.GrafanaDashboardUid != "" {
+		annotation["dashboardUID"] = config.GrafanaDashboardUid
+	}
+	payload, _ := json.Marshal(annotation)
+
+	req, _ := http.NewRequest("POST", strings.TrimRight(config.GrafanaUrl, "/")+"/api/annotations", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+config.GrafanaToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return nil
+}
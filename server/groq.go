@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== Groq (GroqCloud API key) =====
+
+const defaultGroqModels = "llama-3.3-70b-versatile"
+
+// GroqUsageInfo is the rate limit headroom of the monitored models. Groq only reports
+// limits in the headers of inference responses, so each model costs one minimal request.
+type GroqUsageInfo struct {
+	Models []GroqModelLimits `json:"models"`
+}
+
+// GroqModelLimits is one model's daily request and per-minute token limits.
+type GroqModelLimits struct {
+	Model             string  `json:"model"`
+	RequestsLimit     float64 `json:"requestsLimit"` // per day
+	RequestsRemaining float64 `json:"requestsRemaining"`
+	RequestsReset     string  `json:"requestsReset,omitempty"`
+	TokensLimit       float64 `json:"tokensLimit"` // per minute
+	TokensRemaining   float64 `json:"tokensRemaining"`
+	TokensReset       string  `json:"tokensReset,omitempty"`
+	Error             string  `json:"error,omitempty"`
+}
+
+// requestsPercent is the share of the daily request limit consumed.
+func (m GroqModelLimits) requestsPercent() float64 {
+	if m.RequestsLimit <= 0 {
+		return 0
+	}
+	return (m.RequestsLimit - m.RequestsRemaining) / m.RequestsLimit * 100
+}
+
+// tokensPercent is the share of the per-minute token limit consumed.
+func (m GroqModelLimits) tokensPercent() float64 {
+	if m.TokensLimit <= 0 {
+		return 0
+	}
+	return (m.TokensLimit - m.TokensRemaining) / m.TokensLimit * 100
+}
+
+// mostConstrained returns the reachable model with the least headroom, or false if none was reachable.
+func (d GroqUsageInfo) mostConstrained() (GroqModelLimits, bool) {
+	var worst GroqModelLimits
+	found := false
+	for _, m := range d.Models {
+		if m.Error != "" {
+			continue
+		}
+		if !found || max(m.requestsPercent(), m.tokensPercent()) > max(worst.requestsPercent(), worst.tokensPercent()) {
+			worst, found = m, true
+		}
+	}
+	return worst, found
+}
+
+func (d GroqUsageInfo) summary(locale string) string {
+	m, ok := d.mostConstrained()
+	if !ok {
+		return ""
+	}
+	return translate(locale, "summary.groq", m.Model, formatCount(m.RequestsRemaining), formatCount(m.RequestsLimit), formatCount(m.TokensRemaining), formatCount(m.TokensLimit))
+}
+
+func (d GroqUsageInfo) usagePercent() (float64, bool) {
+	if m, ok := d.mostConstrained(); ok {
+		return max(m.requestsPercent(), m.tokensPercent()), true
+	}
+	return 0, false
+}
+
+func (d GroqUsageInfo) resets(time.Time) []ResetInfo {
+	// Per-minute token windows reset too often to be worth listing
+	if m, ok := d.mostConstrained(); ok {
+		return []ResetInfo{{Kind: "groq_requests", At: parseTime(m.RequestsReset)}}
+	}
+	return nil
+}
+
+// groqModels is the configured list of models to monitor.
+func (c *Configuration) groqModels() []string {
+	models := splitList(c.GroqModels)
+	if len(models) == 0 {
+		return []string{defaultGroqModels}
+	}
+	return models
+}
+
+func (p *Plugin) getGroqStatus(config *Configuration) ServiceStatus {
+	const id, name = "groq", "Groq"
+	if config.GroqApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	now := time.Now().UTC()
+	var info GroqUsageInfo
+	failed := 0
+	for _, model := range config.groqModels() {
+		limits, err := p.fetchGroqLimits(client, config, model, now)
+		if err != nil {
+			// An invalid key fails every model the same way
+			if errAuth, ok := err.(authError); ok {
+				return errorStatus(id, name, "error.http", errAuth.status, errAuth.message)
+			}
+			limits = GroqModelLimits{Model: model, Error: err.Error()}
+			failed++
+		}
+		info.Models = append(info.Models, limits)
+	}
+	if failed == len(info.Models) {
+		return errorStatus(id, name, "error.api", info.Models[0].Error)
+	}
+
+	status := "ok"
+	for _, m := range info.Models {
+		if m.Error != "" {
+			continue
+		}
+		if m.RequestsLimit > 0 && m.RequestsRemaining <= 0 {
+			status = "error"
+			break
+		}
+		if max(m.requestsPercent(), m.tokensPercent()) > config.warningPercent(90) {
+			status = "warning"
+		}
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: status,
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// authError is a rejected API key, as opposed to a problem with one model.
+type authError struct {
+	status  int
+	message string
+}
+
+func (e authError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.status, e.message)
+}
+
+// fetchGroqLimits sends a one-token completion and reads the rate limit headers.
+// Rate limited responses carry the same headers, so they count as a reading too.
+func (p *Plugin) fetchGroqLimits(client *http.Client, config *Configuration, model string, now time.Time) (GroqModelLimits, error) {
+	resp, err := client.Do(newGroqRequest(config, model))
+	if err != nil {
+		return GroqModelLimits{}, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return GroqModelLimits{}, err
+	}
+
+	if resp.StatusCode != 200 && resp.StatusCode != 429 {
+		var errResp struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		message := string(body[:min(len(body), 200)])
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			message = errResp.Error.Message
+		}
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			return GroqModelLimits{}, authError{status: resp.StatusCode, message: message}
+		}
+		return GroqModelLimits{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, message)
+	}
+
+	limits := GroqModelLimits{Model: model}
+	limits.RequestsLimit, _ = headerFloat(resp.Header, "x-ratelimit-limit-requests")
+	limits.RequestsRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-requests")
+	limits.TokensLimit, _ = headerFloat(resp.Header, "x-ratelimit-limit-tokens")
+	limits.TokensRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-tokens")
+	limits.RequestsReset = groqResetTime(resp.Header.Get("x-ratelimit-reset-requests"), now)
+	limits.TokensReset = groqResetTime(resp.Header.Get("x-ratelimit-reset-tokens"), now)
+	return limits, nil
+}
+
+// groqResetTime converts a reset delay such as "2m59.56s" to an RFC 3339 timestamp.
+func groqResetTime(value string, now time.Time) string {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d <= 0 {
+		return ""
+	}
+	return now.Add(d).Format(time.RFC3339)
+}
+
+func newGroqRequest(config *Configuration, model string) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "."}},
+		"max_tokens": 1,
+	})
+	req, _ := http.NewRequest("POST", "https://api.groq.com/openai/v1/chat/completions", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+config.GroqApiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
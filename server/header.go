@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// ===== Channel header status line =====
+
+const maxChannelHeaderLength = 1024
+
+// pollProviders refreshes providers in the background every Background Refresh Interval
+// (the cache decides what is actually re-fetched), keeps the snapshot requests are served
+// from, and updates passive status surfaces.
+func (p *Plugin) pollProviders() {
+	if !p.pollDue(time.Now()) || p.skipIdlePoll() {
+		return
+	}
+	services := p.gatherStatuses(true)
+	p.snapshot.store(services, time.Now())
+	p.trackStatusChanges(services)
+	p.trackBudgets(p.budgetStatuses(services, p.getConfiguration()))
+	p.publishStatusChanges(services)
+	p.recordUsageHistory(services, time.Now())
+	p.updateChannelHeader(services)
+	p.updateBotStatus(services)
+	p.updateStatusPost(services)
+}
+
+// updateChannelHeader writes a compact status line into the configured channel's
+// header or purpose. It only saves when the text changed, since every update
+// produces a system message in the channel.
+func (p *Plugin) updateChannelHeader(services []ServiceStatus) {
+	config := p.getConfiguration()
+	if config.HeaderChannelId == "" {
+		return
+	}
+
+	locale := p.serverLocale()
+	var parts []string
+	for _, s := range services {
+		if s.Enabled {
+			parts = append(parts, compactSummary(s, locale))
+		}
+	}
+	if len(parts) == 0 {
+		return
+	}
+	line := "AI: " + strings.Join(parts, " | ")
+	if len(line) > maxChannelHeaderLength {
+		line = line[:maxChannelHeaderLength]
+	}
+
+	channel, appErr := p.API.GetChannel(config.HeaderChannelId)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get status header channel", "channel_id", config.HeaderChannelId, "error", appErr.Error())
+		return
+	}
+
+	if config.HeaderField == "purpose" {
+		if channel.Purpose == line {
+			return
+		}
+		channel.Purpose = line
+	} else {
+		if channel.Header == line {
+			return
+		}
+		channel.Header = line
+	}
+
+	if _, appErr := p.API.UpdateChannel(channel); appErr != nil {
+		p.API.LogWarn("Failed to update channel header", "channel_id", config.HeaderChannelId, "error", appErr.Error())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ===== Per-provider request headers =====
+
+// parseProviderHeaders parses the Provider Headers setting: a JSON object from provider ID
+// to the headers added to its requests. A provider ID like "openai" covers all its cards,
+// "openai:org-abc" a single one. A value "env:NAME" or "file:/path" is
+// read from the server's environment or a file.
+func parseProviderHeaders(raw string) (map[string]map[string]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var headers map[string]map[string]string
+	if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+		return nil, fmt.Errorf("not a JSON object of provider headers: %v", err)
+	}
+	for id, set := range headers {
+		for name, value := range set {
+			if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+				return nil, fmt.Errorf("%s: invalid header name %q", id, name)
+			}
+			if strings.ContainsAny(value, "\r\n") {
+				return nil, fmt.Errorf("%s: header %s has a line break", id, name)
+			}
+			if strings.HasPrefix(value, "env:") || strings.HasPrefix(value, "file:") {
+				resolved, err := resolveSecret(value)
+				if err != nil {
+					return nil, fmt.Errorf("%s: header %s: %v", id, name, err)
+				}
+				set[name] = resolved
+			}
+		}
+	}
+	return headers, nil
+}
+
+// providerHeaders returns the headers configured for a provider's requests; those of a
+// single card override those of the provider.
+func (c *Configuration) providerHeaders(id string) map[string]string {
+	all, err := parseProviderHeaders(c.ProviderHeaders)
+	if err != nil || len(all) == 0 {
+		return nil
+	}
+	headers := map[string]string{}
+	if typ, _, ok := strings.Cut(id, ":"); ok {
+		for name, value := range all[typ] {
+			headers[name] = value
+		}
+	}
+	for name, value := range all[id] {
+		headers[name] = value
+	}
+	return headers
+}
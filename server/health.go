@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ===== Provider API health =====
+
+// healthWindow is the number of recent requests per provider the health score is computed from.
+const healthWindow = 50
+
+type healthSample struct {
+	Latency time.Duration
+	Failed  bool
+}
+
+// providerHealth is a ring of the latest requests made to one provider's API.
+type providerHealth struct {
+	samples       []healthSample
+	next          int
+	lastError     string
+	lastErrorAt   time.Time
+	lastSuccessAt time.Time
+	retries       int
+	// Set by a 429 or 503 whose Retry-After was too long to wait for
+	rateLimitedUntil time.Time
+}
+
+// ProviderHealth describes how reliably a provider's status API answers, independent
+// of the quota status it reports.
+type ProviderHealth struct {
+	Provider      string  `json:"provider"`
+	Score         int     `json:"score"` // 0–100, see healthScore
+	Requests      int     `json:"requests"`
+	ErrorRate     float64 `json:"errorRate"`
+	P95LatencyMs  int64   `json:"p95LatencyMs"`
+	LastError     string  `json:"lastError,omitempty"`
+	LastErrorAt   int64   `json:"lastErrorAt,omitempty"`
+	LastSuccessAt int64   `json:"lastSuccessAt,omitempty"`
+	Retries       int     `json:"retries"` // since activation
+	// Until when the provider asked not to be called again
+	RateLimitedUntil int64 `json:"rateLimitedUntil,omitempty"`
+}
+
+// providerTransport sends the requests of every provider client, or nil for the outbound
+// transport of the configured proxy and TLS settings. Replacing it, e.g. with one that
+// answers from recorded responses, lets provider parsing run without the network.
+var providerTransport http.RoundTripper
+
+// providerClient returns an HTTP client whose requests are recorded in the provider's health.
+func (p *Plugin) providerClient(provider string, timeout time.Duration) *http.Client {
+	base := providerTransport
+	if base == nil {
+		base = p.getConfiguration().outboundTransport()
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &healthTransport{plugin: p, provider: provider, base: base},
+	}
+}
+
+type healthTransport struct {
+	plugin   *Plugin
+	provider string
+	base     http.RoundTripper
+}
+
+// RoundTrip sends the request, retrying transient failures, and times each attempt.
+func (t *healthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, release, err := t.plugin.bg.bind(req)
+	if err != nil {
+		return nil, err
+	}
+	req = t.plugin.prepareProviderRequest(req, t.provider)
+	resp, err := t.sendWithRetries(req)
+	if err != nil {
+		release()
+	} else {
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+		t.plugin.recordCredentialExpiry(t.provider, resp.Header)
+	}
+	return resp, err
+}
+
+// attempt sends the request once and records it. Network errors, rate limiting and server
+// errors count as failures; client errors such as a bad key are the admin's problem, not
+// the provider's.
+func (t *healthTransport) attempt(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+	t.plugin.logProviderRequest(t.provider, req, resp, err, latency)
+
+	switch {
+	case t.plugin.bg.stopping():
+		// Cancelled by deactivation, not the provider's fault
+	case err != nil:
+		t.plugin.recordHealth(t.provider, latency, err.Error())
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		t.plugin.recordHealth(t.provider, latency, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	default:
+		t.plugin.recordHealth(t.provider, latency, "")
+	}
+	return resp, err
+}
+
+// recordHealth adds a request to the provider's window; errMsg is empty for successes.
+func (p *Plugin) recordHealth(provider string, latency time.Duration, errMsg string) {
+	p.latency.record(provider, latency, errMsg != "")
+
+	p.healthLock.Lock()
+	defer p.healthLock.Unlock()
+
+	h := p.healthOf(provider)
+	sample := healthSample{Latency: latency, Failed: errMsg != ""}
+	if len(h.samples) < healthWindow {
+		h.samples = append(h.samples, sample)
+	} else {
+		h.samples[h.next] = sample
+	}
+	h.next = (h.next + 1) % healthWindow
+
+	if errMsg != "" {
+		h.lastError = errMsg
+		h.lastErrorAt = time.Now()
+	} else {
+		h.lastSuccessAt = time.Now()
+	}
+}
+
+// healthOf returns the provider's health entry, creating it. Callers hold healthLock.
+func (p *Plugin) healthOf(provider string) *providerHealth {
+	if p.health == nil {
+		p.health = map[string]*providerHealth{}
+	}
+	h, ok := p.health[provider]
+	if !ok {
+		h = &providerHealth{}
+		p.health[provider] = h
+	}
+	return h
+}
+
+// providerHealthReport summarizes every provider that has been contacted since activation.
+func (p *Plugin) providerHealthReport() []ProviderHealth {
+	p.healthLock.Lock()
+	defer p.healthLock.Unlock()
+
+	report := []ProviderHealth{}
+	for provider, h := range p.health {
+		report = append(report, h.summarize(provider))
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Provider < report[j].Provider })
+	return report
+}
+
+func (h *providerHealth) summarize(provider string) ProviderHealth {
+	failed := 0
+	latencies := make([]time.Duration, 0, len(h.samples))
+	for _, s := range h.samples {
+		if s.Failed {
+			failed++
+		}
+		latencies = append(latencies, s.Latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	result := ProviderHealth{Provider: provider, Requests: len(h.samples)}
+	if len(h.samples) > 0 {
+		result.ErrorRate = float64(failed) / float64(len(h.samples))
+		p95 := latencies[int(math.Ceil(float64(len(latencies))*0.95))-1]
+		result.P95LatencyMs = p95.Milliseconds()
+	}
+	result.Score = healthScore(result.ErrorRate, time.Duration(result.P95LatencyMs)*time.Millisecond)
+	if h.lastError != "" {
+		result.LastError = h.lastError
+		result.LastErrorAt = h.lastErrorAt.Unix()
+	}
+	if !h.lastSuccessAt.IsZero() {
+		result.LastSuccessAt = h.lastSuccessAt.Unix()
+	}
+	result.Retries = h.retries
+	if time.Now().Before(h.rateLimitedUntil) {
+		result.RateLimitedUntil = h.rateLimitedUntil.Unix()
+	}
+	return result
+}
+
+// healthScore is 100 for an API that always answers within 2s. Each failed request
+// costs its share, and a p95 latency above 2s scales the score down to half at 10s.
+func healthScore(errorRate float64, p95 time.Duration) int {
+	latencyFactor := 1.0
+	if p95 > 2*time.Second {
+		latencyFactor = math.Max(0.5, 1-0.5*(p95-2*time.Second).Seconds()/8)
+	}
+	return int(math.Round(100 * (1 - errorRate) * latencyFactor))
+}
+
+// handleDiagnostics serves the provider health report as JSON.
+func (p *Plugin) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"providers":    p.providerHealthReport(),
+		"availability": p.availabilityReport(),
+	})
+}
+
+// handleMetrics serves the provider health report in the Prometheus text format.
+func (p *Plugin) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	report := p.providerHealthReport()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	metrics := []struct {
+		name, help string
+		value      func(h ProviderHealth) float64
+	}{
+		{"ailimits_provider_health_score", "Health of the provider's status API from 0 to 100.",
+			func(h ProviderHealth) float64 { return float64(h.Score) }},
+		{"ailimits_provider_error_rate", "Share of recent requests to the provider that failed.",
+			func(h ProviderHealth) float64 { return h.ErrorRate }},
+		{"ailimits_provider_latency_p95_seconds", "95th percentile latency of recent requests to the provider.",
+			func(h ProviderHealth) float64 { return float64(h.P95LatencyMs) / 1000 }},
+		{"ailimits_provider_requests", "Number of recent requests the health metrics are based on.",
+			func(h ProviderHealth) float64 { return float64(h.Requests) }},
+		{"ailimits_provider_retries", "Requests to the provider retried since the plugin started.",
+			func(h ProviderHealth) float64 { return float64(h.Retries) }},
+		{"ailimits_provider_last_success_timestamp_seconds", "Unix time of the last request to the provider that succeeded, 0 if none.",
+			func(h ProviderHealth) float64 { return float64(h.LastSuccessAt) }},
+		{"ailimits_provider_last_failure_timestamp_seconds", "Unix time of the last request to the provider that failed, 0 if none.",
+			func(h ProviderHealth) float64 { return float64(h.LastErrorAt) }},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, h := range report {
+			fmt.Fprintf(w, "%s{provider=%q} %g\n", m.name, h.Provider, m.value(h))
+		}
+	}
+
+	// Today's figures come from the stored history, so they survive restarts
+	today := time.Now().UTC().Format("2006-01-02")
+	history := p.latencyHistory(1)
+	providers := make([]string, 0, len(history))
+	for provider := range history {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	const name = "ailimits_provider_latency_avg_seconds"
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, "Average latency of today's requests to the provider (UTC).", name)
+	for _, provider := range providers {
+		if d := history[provider][today]; d.Requests > 0 {
+			fmt.Fprintf(w, "%s{provider=%q} %g\n", name, provider, float64(d.TotalMs)/float64(d.Requests)/1000)
+		}
+	}
+
+	const uptime = "ailimits_provider_uptime_percent"
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", uptime, "Share of requests to the provider that succeeded over the window.", uptime)
+	for _, a := range p.availabilityReport() {
+		for _, window := range availabilityWindows {
+			if v, ok := a.Uptime[window.name]; ok {
+				fmt.Fprintf(w, "%s{provider=%q,window=%q} %g\n", uptime, a.Provider, window.name, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Helicone (API key, cloud or self-hosted) =====
+
+const defaultHeliconeURL = "https://api.helicone.ai"
+
+// heliconeMaxPages bounds the per-user metrics listing, heliconePageSize users per page.
+const (
+	heliconeMaxPages = 10
+	heliconePageSize = 500
+)
+
+// HeliconeUsageInfo is the month-to-date traffic logged by Helicone across every upstream
+// provider the org routes through it. Helicone prices requests in USD.
+type HeliconeUsageInfo struct {
+	TotalCost        float64             `json:"totalCost"`
+	Budget           float64             `json:"budget,omitempty"`
+	Currency         string              `json:"currency"`
+	Period           string              `json:"period"`
+	CycleEnd         string              `json:"cycleEnd"`
+	DaysUntilReset   int                 `json:"daysUntilReset"`
+	Requests         float64             `json:"requests"`
+	PromptTokens     float64             `json:"promptTokens"`
+	CompletionTokens float64             `json:"completionTokens"`
+	TopUsers         []HeliconeUserUsage `json:"topUsers,omitempty"`
+}
+
+func (h HeliconeUsageInfo) summary(locale string) string {
+	text := translate(locale, "summary.spent", formatMoney(h.TotalCost, h.Currency, 2), h.Period)
+	if h.Budget > 0 {
+		text = translate(locale, "summary.spent_budget", formatMoney(h.TotalCost, h.Currency, 2), formatMoney(h.Budget, h.Currency, 0), h.Period)
+	}
+	return text + " " + translate(locale, "summary.helicone_requests", formatCount(h.Requests), formatCount(h.PromptTokens+h.CompletionTokens))
+}
+
+func (h HeliconeUsageInfo) compact(name, locale string) string {
+	if h.Budget > 0 {
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(h.TotalCost, h.Currency, 0), formatMoney(h.Budget, h.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(h.TotalCost, h.Currency, 0))
+}
+
+func (h HeliconeUsageInfo) usagePercent() (float64, bool) {
+	if h.Budget > 0 {
+		return h.TotalCost / h.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (h HeliconeUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(h.CycleEnd)}}
+}
+
+// HeliconeUserUsage is one Helicone-User-Id's requests and cost this month.
+type HeliconeUserUsage struct {
+	User     string  `json:"user"`
+	Requests float64 `json:"requests"`
+	Cost     float64 `json:"cost"`
+}
+
+// heliconeUserMetricsResponse is POST /v1/user/metrics/query.
+type heliconeUserMetricsResponse struct {
+	Data *struct {
+		Users []struct {
+			UserID                string    `json:"user_id"`
+			TotalRequests         flexFloat `json:"total_requests" schema:"required"`
+			Cost                  flexFloat `json:"cost" schema:"required"`
+			TotalPromptTokens     flexFloat `json:"total_prompt_tokens"`
+			TotalCompletionTokens flexFloat `json:"total_completion_tokens"`
+			FirstActive           string    `json:"first_active"`
+			LastActive            string    `json:"last_active"`
+		} `json:"users" schema:"required"`
+		Count flexFloat `json:"count"`
+	} `json:"data" schema:"required"`
+	Error *string `json:"error"`
+}
+
+// heliconeURL is the configured API base URL, the US cloud by default.
+func (c *Configuration) heliconeURL() string {
+	url := strings.TrimRight(strings.TrimSpace(c.HeliconeUrl), "/")
+	if url == "" {
+		return defaultHeliconeURL
+	}
+	return url
+}
+
+func (p *Plugin) getHeliconeStatus(config *Configuration) ServiceStatus {
+	const id, name = "helicone", "Helicone"
+	if config.HeliconeApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := HeliconeUsageInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+
+	var drift schemaDrift
+	var users []HeliconeUserUsage
+	for page := 0; page < heliconeMaxPages; page++ {
+		resp, err := client.Do(newHeliconeMetricsRequest(config, monthStart, now, page*heliconePageSize))
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		body, err := p.readResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		if resp.StatusCode != 200 {
+			return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+
+		var metrics heliconeUserMetricsResponse
+		d, err := decodeResponse(body, &metrics)
+		if err == nil && metrics.Data == nil && metrics.Error != nil {
+			err = fmt.Errorf("%s", *metrics.Error)
+		}
+		if err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		drift.merge(d)
+
+		for _, u := range metrics.Data.Users {
+			info.TotalCost += float64(u.Cost)
+			info.Requests += float64(u.TotalRequests)
+			info.PromptTokens += float64(u.TotalPromptTokens)
+			info.CompletionTokens += float64(u.TotalCompletionTokens)
+			// Requests sent without a Helicone-User-Id header share an empty user
+			if u.UserID != "" {
+				users = append(users, HeliconeUserUsage{User: u.UserID, Requests: float64(u.TotalRequests), Cost: float64(u.Cost)})
+			}
+		}
+		if len(metrics.Data.Users) < heliconePageSize {
+			break
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Cost > users[j].Cost })
+	info.TopUsers = users[:min(len(users), 5)]
+	info.Budget = p.budgetIn(config, id, config.HeliconeMonthlyBudget, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: budgetStatus(info.TotalCost, info.Budget, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// newHeliconeMetricsRequest queries per-user totals for requests logged between start and end.
+func newHeliconeMetricsRequest(config *Configuration, start, end time.Time, offset int) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"filter": "all",
+		"offset": offset,
+		"limit":  heliconePageSize,
+		"timeFilter": map[string]int64{
+			"startTimeUnixSeconds": start.Unix(),
+			"endTimeUnixSeconds":   end.Unix(),
+		},
+		"timeZoneDifferenceMinutes": 0,
+	})
+	req, _ := http.NewRequest("POST", config.heliconeURL()+"/v1/user/metrics/query", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+config.HeliconeApiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== OpenAI cost history =====
+
+// maxBackfillMonths bounds how far back a backfill reaches.
+const maxBackfillMonths = 12
+
+// costMonth is one OpenAI organization's daily spend in a calendar month (UTC).
+type costMonth struct {
+	Month string             `json:"month"` // "2006-01"
+	Days  map[string]float64 `json:"days"`  // "2006-01-02" → USD
+}
+
+func costMonthKey(statusID, month string) string {
+	return "cost_" + statusID + "_" + month
+}
+
+func (p *Plugin) loadCostMonth(statusID, month string) costMonth {
+	m := costMonth{Month: month, Days: map[string]float64{}}
+	if data, appErr := p.API.KVGet(p.scopedKey(costMonthKey(statusID, month))); appErr == nil && data != nil {
+		json.Unmarshal(data, &m)
+	}
+	if m.Days == nil {
+		m.Days = map[string]float64{}
+	}
+	return m
+}
+
+// storeCostDays merges daily costs into the stored months. Later values for a day
+// replace earlier ones, since OpenAI keeps adding to the current day's bucket.
+func (p *Plugin) storeCostDays(statusID string, days map[string]float64) {
+	p.historyLock.Lock()
+	defer p.historyLock.Unlock()
+
+	months := map[string]costMonth{}
+	for date, cost := range days {
+		month := date[:7]
+		m, ok := months[month]
+		if !ok {
+			m = p.loadCostMonth(statusID, month)
+			months[month] = m
+		}
+		m.Days[date] = cost
+	}
+
+	for month, m := range months {
+		data, _ := json.Marshal(m)
+		if appErr := p.API.KVSet(p.scopedKey(costMonthKey(statusID, month)), data); appErr != nil {
+			p.API.LogWarn("Failed to store cost history", "provider", statusID, "month", month, "error", appErr.Error())
+		}
+	}
+}
+
+// previousCycleCosts returns the previous billing cycle's total spend and its spend up to
+// the same point in the cycle as now, for comparison with the current cycle. Both are 0
+// without history.
+func (p *Plugin) previousCycleCosts(statusID string, anchorDay int, cycleStart, now time.Time) (total, toDate float64) {
+	prevStart, _ := billingCycle(cycleStart.AddDate(0, 0, -1), anchorDay)
+	// Whole days, including the one corresponding to today
+	sameDay := prevStart.Add(now.Sub(cycleStart)).Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if sameDay.After(cycleStart) {
+		sameDay = cycleStart
+	}
+	return p.costBetween(statusID, prevStart, cycleStart), p.costBetween(statusID, prevStart, sameDay)
+}
+
+// costBetween sums the stored daily costs of the days in [from, to).
+func (p *Plugin) costBetween(statusID string, from, to time.Time) float64 {
+	p.historyLock.Lock()
+	defer p.historyLock.Unlock()
+
+	total := 0.0
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(to); month = month.AddDate(0, 1, 0) {
+		for date, cost := range p.loadCostMonth(statusID, month.Format("2006-01")).Days {
+			day, err := time.Parse("2006-01-02", date)
+			if err == nil && !day.Before(from) && day.Before(to) {
+				total += cost
+			}
+		}
+	}
+	return total
+}
+
+// backfillMonths is the configured number of months to import, within 1–maxBackfillMonths.
+func (c *Configuration) backfillMonths() int {
+	months, err := strconv.Atoi(strings.TrimSpace(c.OpenaiBackfillMonths))
+	if err != nil || months <= 0 {
+		return 3
+	}
+	return min(months, maxBackfillMonths)
+}
+
+// backfillOnActivate imports cost history for organizations that haven't been backfilled yet.
+func (p *Plugin) backfillOnActivate() {
+	config := p.getConfiguration()
+	if !config.OpenaiEnabled || config.OpenaiApiKey == "" || config.DemoMode {
+		return
+	}
+
+	for _, org := range p.openAIOrgs(config) {
+		if p.bg.stopping() {
+			return
+		}
+		flagKey := "cost_backfilled_" + org.statusID()
+		if data, appErr := p.API.KVGet(flagKey); appErr != nil || data != nil {
+			continue
+		}
+		if _, err := p.backfillOpenAICosts(config, org, config.backfillMonths()); err != nil {
+			p.API.LogWarn("Failed to backfill OpenAI cost history", "provider", org.statusID(), "error", err.Error())
+			continue
+		}
+		p.API.KVSet(flagKey, []byte(time.Now().UTC().Format(time.RFC3339)))
+	}
+}
+
+// backfillOpenAICosts fetches the daily costs of the previous months and the current
+// one so far, and returns how many days were stored.
+func (p *Plugin) backfillOpenAICosts(config *Configuration, org openAIOrg, months int) (int, error) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month()-time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	client := p.providerClient(org.statusID(), 30*time.Second)
+
+	days := map[string]float64{}
+	page := ""
+	// Each page holds at most a month of daily buckets
+	for i := 0; i <= maxBackfillMonths+1; i++ {
+		resp, err := client.Do(newOpenAICostsRequest(config, org, start, now, page))
+		if err != nil {
+			return 0, err
+		}
+		body, err := p.readResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return 0, err
+		}
+		if resp.StatusCode != 200 {
+			return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+
+		var raw openAICostsResponse
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return 0, err
+		}
+		for day, cost := range raw.dailyCosts() {
+			days[day] += cost
+		}
+		if !raw.HasMore || raw.NextPage == "" {
+			break
+		}
+		page = raw.NextPage
+	}
+
+	p.storeCostDays(org.statusID(), days)
+	return len(days), nil
+}
+
+// executeBackfillCommand re-imports cost history on demand. It runs in the background
+// and reports back with an ephemeral post, since it can take several requests per organization.
+func (p *Plugin) executeBackfillCommand(args *model.CommandArgs, uc userContext, monthsArg string) *model.CommandResponse {
+	if !p.isSystemAdmin(args.UserId) {
+		return ephemeralResponse(translate(uc.Locale, "backfill.admin_only"))
+	}
+	config := p.getConfiguration()
+	if !config.OpenaiEnabled || config.OpenaiApiKey == "" {
+		return ephemeralResponse(translate(uc.Locale, "backfill.not_configured"))
+	}
+
+	months := config.backfillMonths()
+	if monthsArg != "" {
+		n, err := strconv.Atoi(monthsArg)
+		if err != nil || n <= 0 || n > maxBackfillMonths {
+			return ephemeralResponse(translate(uc.Locale, "backfill.invalid_months", maxBackfillMonths))
+		}
+		months = n
+	}
+
+	p.bg.Go(p, func() {
+		var lines []string
+		for _, org := range p.openAIOrgs(config) {
+			stored, err := p.backfillOpenAICosts(config, org, months)
+			if err != nil {
+				lines = append(lines, translate(uc.Locale, "backfill.failed", org.statusName(), err.Error()))
+				continue
+			}
+			lines = append(lines, translate(uc.Locale, "backfill.done", org.statusName(), stored))
+		}
+		p.API.SendEphemeralPost(args.UserId, &model.Post{
+			ChannelId: args.ChannelId,
+			UserId:    p.botUserID,
+			Message:   strings.Join(lines, "\n"),
+		})
+	})
+
+	return ephemeralResponse(translate(uc.Locale, "backfill.started", months))
+}
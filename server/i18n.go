@@ -0,0 +1,102 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ===== Localization =====
+
+//go:embed i18n/*.json
+var i18nFiles embed.FS
+
+// translations maps locale → message ID → format string. English is the fallback.
+var translations = loadTranslations()
+
+func loadTranslations() map[string]map[string]string {
+	result := map[string]map[string]string{}
+	files, _ := i18nFiles.ReadDir("i18n")
+	for _, f := range files {
+		data, err := i18nFiles.ReadFile("i18n/" + f.Name())
+		if err != nil {
+			continue
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			continue
+		}
+		result[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = messages
+	}
+	return result
+}
+
+// translate formats the message for the given locale, falling back to the base
+// language (e.g. "pt" for "pt-BR") and then to English.
+func translate(locale, msgID string, args ...interface{}) string {
+	msg, ok := translations[locale][msgID]
+	if !ok {
+		if i := strings.IndexAny(locale, "-_"); i > 0 {
+			msg, ok = translations[locale[:i]][msgID]
+		}
+	}
+	if !ok {
+		msg, ok = translations["en"][msgID]
+	}
+	if !ok {
+		return msgID
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// serverLocale is used for messages that aren't addressed to a single user
+// (digests, channel header, alerts).
+func (p *Plugin) serverLocale() string {
+	config := p.API.GetConfig()
+	if config != nil && config.LocalizationSettings.DefaultServerLocale != nil {
+		return *config.LocalizationSettings.DefaultServerLocale
+	}
+	return "en"
+}
+
+// userContext holds the per-user settings used to render responses.
+type userContext struct {
+	Locale   string
+	Location *time.Location
+	Units    string
+}
+
+// getUserContext resolves the user's Mattermost locale and timezone and their
+// display units, falling back to the server defaults and UTC.
+func (p *Plugin) getUserContext(userID string) userContext {
+	uc := userContext{Locale: p.serverLocale(), Location: time.UTC, Units: p.getConfiguration().defaultUnits()}
+	if prefs := p.getUserPreferences(userID); prefs.Units != "" {
+		uc.Units = prefs.Units
+	}
+	if user, appErr := p.API.GetUser(userID); appErr == nil {
+		if user.Locale != "" {
+			uc.Locale = user.Locale
+		}
+		uc.Location = user.GetTimezoneLocation()
+	}
+	return uc
+}
+
+// localizeStatuses rewrites localizable error messages for the given locale.
+func localizeStatuses(services []ServiceStatus, locale string) []ServiceStatus {
+	for i, s := range services {
+		if s.errorID != "" {
+			services[i].Error = translate(locale, s.errorID, s.errorArgs...)
+		}
+		if s.staleErrorID != "" {
+			services[i].StaleError = translate(locale, s.staleErrorID, s.staleErrorArgs...)
+		}
+	}
+	return services
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Idle-aware polling =====
+
+// idlePollInterval is how often providers are still polled while nobody is using the plugin,
+// so alerts for a provider that runs out over a quiet weekend are only delayed, not lost.
+const idlePollInterval = time.Hour
+
+// idleAfter is how long without a dashboard view or command before background polling slows
+// down; zero polls at the normal rate all the time.
+func (c *Configuration) idleAfter() time.Duration {
+	hours, err := strconv.ParseFloat(strings.TrimSpace(c.IdleAfterHours), 64)
+	if err != nil || hours <= 0 {
+		return 0
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// markActive records that an allowed user viewed the dashboard or asked for data. The next
+// background poll runs at the normal rate again.
+func (p *Plugin) markActive() {
+	p.lastActivity.Store(time.Now().Unix())
+}
+
+// skipIdlePoll reports whether the background poll should be skipped because nobody has
+// used the plugin within the idle window and the last poll was recent enough.
+func (p *Plugin) skipIdlePoll() bool {
+	window := p.getConfiguration().idleAfter()
+	idle := window > 0 && time.Since(time.Unix(p.lastActivity.Load(), 0)) > window
+	if idle != p.pollingIdle {
+		p.pollingIdle = idle
+		if idle {
+			p.API.LogInfo("No recent activity, polling providers hourly", "idle_after", window.String())
+		} else {
+			p.API.LogInfo("Activity resumed, polling providers at the normal rate")
+		}
+	}
+	if !idle {
+		return false
+	}
+	if time.Since(p.lastIdlePoll) < idlePollInterval {
+		return true
+	}
+	p.lastIdlePoll = time.Now()
+	return false
+}
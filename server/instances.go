@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Provider instances (defined as a JSON list) =====
+
+// instanceTypesExcluded can't run as instances: Codex saves renewed tokens back to the
+// System Console fields, and Claude Code usage is pushed to a single store. claude.ai
+// instances store their renewed tokens under their own key.
+var instanceTypesExcluded = map[string]bool{"codex": true, "claudecode": true}
+
+// providerInstance is an instance of a built-in provider defined in the Provider Instances
+// setting, so a provider can be monitored more than once (e.g. two Z.AI accounts) without a
+// new set of System Console fields. Settings use the provider's System Console keys with or
+// without the provider prefix ("apikey" or "zaiapikey"); anything not set is inherited from
+// System Console. A string value "env:NAME" or "file:/path" is read from the server's
+// environment or a file, so credentials don't have to be stored in the plugin configuration.
+type providerInstance struct {
+	Type       string         `json:"type"`
+	ID         string         `json:"id"`
+	Label      string         `json:"label"`
+	Disabled   bool           `json:"disabled"`
+	Settings   map[string]any `json:"settings"`
+	Thresholds struct {
+		Warning float64 `json:"warning"` // percent, overrides the Warning Threshold
+	} `json:"thresholds"`
+	TTL string `json:"ttl"` // cache lifetime, e.g. "10m"
+
+	// plugin fetches the instance with its own configuration and cache
+	plugin *Plugin
+}
+
+// statusID scopes a status the provider returned to the instance: "zai" becomes
+// "zai:<instance>" and "openai:org-abc" becomes "openai:<instance>:org-abc".
+func (inst *providerInstance) statusID(id string) string {
+	if _, rest, ok := strings.Cut(id, ":"); ok {
+		return inst.Type + ":" + inst.ID + ":" + rest
+	}
+	return inst.Type + ":" + inst.ID
+}
+
+// parseProviderInstances parses the instance list against the current configuration. An
+// invalid entry fails the whole list, like the custom provider definitions.
+func (p *Plugin) parseProviderInstances(base *Configuration) ([]*providerInstance, error) {
+	if strings.TrimSpace(base.ProviderInstances) == "" {
+		return nil, nil
+	}
+	var instances []*providerInstance
+	if err := json.Unmarshal([]byte(base.ProviderInstances), &instances); err != nil {
+		return nil, fmt.Errorf("not a JSON array of instances: %v", err)
+	}
+	known := configurationKeys()
+	seen := map[string]bool{}
+	for i, inst := range instances {
+		step, ok := findSetupStep(inst.Type)
+		switch {
+		case !ok || step.EnabledKey == "" || instanceTypesExcluded[inst.Type]:
+			return nil, fmt.Errorf("instance %d: unsupported type %q", i+1, inst.Type)
+		case !customIDPattern.MatchString(inst.ID):
+			return nil, fmt.Errorf("instance %d: id must be lowercase letters, digits, - or _", i+1)
+		case seen[inst.Type+":"+inst.ID]:
+			return nil, fmt.Errorf("instance %q: duplicate id for %s", inst.ID, inst.Type)
+		}
+		seen[inst.Type+":"+inst.ID] = true
+
+		var ttl time.Duration
+		if inst.TTL != "" {
+			d, err := time.ParseDuration(inst.TTL)
+			if err != nil || d < time.Minute {
+				return nil, fmt.Errorf("instance %q: ttl must be a duration of at least 1m", inst.ID)
+			}
+			ttl = d
+		}
+		config, err := inst.configuration(base, strings.TrimSuffix(step.EnabledKey, "enabled"), known)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %v", inst.ID, err)
+		}
+		inst.plugin = &Plugin{
+			MattermostPlugin: p.MattermostPlugin,
+			configuration:    config,
+			cache:            make(map[string]*CacheEntry),
+			cacheTTL:         ttl,
+			botUserID:        p.botUserID,
+			bg:               p.bg,
+			defaultUA:        p.defaultUA,
+			latency:          p.latency,
+			fetchLog:         p.fetchLog,
+			tokenScope:       "instance:" + inst.Type + ":" + inst.ID,
+		}
+	}
+	return instances, nil
+}
+
+// configuration overlays the instance's settings on the System Console configuration.
+func (inst *providerInstance) configuration(base *Configuration, prefix string, known map[string]bool) (*Configuration, error) {
+	settings := map[string]any{prefix + "enabled": true}
+	for key, value := range inst.Settings {
+		key = strings.ToLower(key)
+		if !strings.HasPrefix(key, prefix) {
+			key = prefix + key
+		}
+		if !known[key] {
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+		switch v := value.(type) {
+		case string:
+			resolved, err := resolveSecret(v)
+			if err != nil {
+				return nil, fmt.Errorf("setting %q: %v", key, err)
+			}
+			value = resolved
+		case float64:
+			// Numeric settings are text fields in System Console
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		settings[key] = value
+	}
+
+	config := *base
+	config.ProviderInstances = ""
+	data, _ := json.Marshal(settings)
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid settings: %v", err)
+	}
+	if w := inst.Thresholds.Warning; w > 0 {
+		config.WarningThreshold = strconv.FormatFloat(w, 'f', -1, 64)
+	}
+	return &config, nil
+}
+
+// configurationKeys lists the JSON keys of the configuration.
+func configurationKeys() map[string]bool {
+	var fields map[string]any
+	data, _ := json.Marshal(Configuration{})
+	json.Unmarshal(data, &fields)
+	keys := map[string]bool{}
+	for key := range fields {
+		keys[key] = true
+	}
+	return keys
+}
+
+// getInstanceStatuses fetches every enabled provider instance.
+func (p *Plugin) getInstanceStatuses() []ServiceStatus {
+	p.instancesLock.Lock()
+	instances, err := p.instances, p.instancesErr
+	p.instancesLock.Unlock()
+	if err != nil {
+		return []ServiceStatus{errorStatus("instances", "Provider instances", "error.instances_invalid", err.Error())}
+	}
+
+	var services []ServiceStatus
+	for _, inst := range instances {
+		if inst.Disabled {
+			continue
+		}
+		prov, ok := findProvider(inst.Type)
+		if !ok {
+			continue
+		}
+		statuses := inst.plugin.fetchProvider(prov, inst.plugin.getConfiguration())
+		for i, s := range statuses {
+			statuses[i].ID = inst.statusID(s.ID)
+			if label := strings.TrimSpace(inst.Label); label != "" {
+				statuses[i].Name = label
+				if len(statuses) > 1 {
+					statuses[i].Name = label + " · " + s.Name
+				}
+			}
+		}
+		services = append(services, statuses...)
+	}
+	return services
+}
+
+// instanceTypes returns the provider types that have instances.
+func (p *Plugin) instanceTypes() map[string]bool {
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+	types := map[string]bool{}
+	for _, inst := range p.instances {
+		if !inst.Disabled {
+			types[inst.Type] = true
+		}
+	}
+	return types
+}
+
+// clearInstanceCaches drops the cached results of every instance.
+func (p *Plugin) clearInstanceCaches() {
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+	for _, inst := range p.instances {
+		inst.plugin.cacheLock.Lock()
+		inst.plugin.cache = make(map[string]*CacheEntry)
+		inst.plugin.cacheLock.Unlock()
+		inst.plugin.clearBackoff()
+	}
+}
+
+// reuseInstancePlugins keeps the cache of instances whose settings didn't change in a way
+// that affects their results.
+func reuseInstancePlugins(old, updated []*providerInstance) {
+	for _, inst := range updated {
+		for _, prev := range old {
+			if prev.Type != inst.Type || prev.ID != inst.ID || prev.plugin.cacheTTL != inst.plugin.cacheTTL {
+				continue
+			}
+			prevConfig, config := prev.plugin.getConfiguration(), inst.plugin.getConfiguration()
+			if ids, all := affectedProviders(changedKeys(prevConfig, config)); all || ids[inst.Type] {
+				break
+			}
+			prev.plugin.configurationLock.Lock()
+			prev.plugin.configuration = config
+			prev.plugin.configurationLock.Unlock()
+			inst.plugin = prev.plugin
+			break
+		}
+	}
+}
+
+// scopedKey is the KV key the plugin keeps its provider history under: the organization's
+// key, or one of its own for a provider instance or a user's personal credentials.
+func (p *Plugin) scopedKey(key string) string {
+	if p.tokenScope != "" {
+		return key + "_" + p.tokenScope
+	}
+	return key
+}
+
+func findProvider(id string) (provider, bool) {
+	for _, prov := range providers {
+		if prov.ID == id {
+			return prov, true
+		}
+	}
+	return provider{}, false
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== JetBrains AI (organization, Account API key) =====
+
+// jetbrainsAICredits is the monthly AI credit quota of a seat by subscription tier, matched
+// against the product name. Quotas renew every 30 days per user.
+var jetbrainsAICredits = []struct {
+	Tier    string
+	Credits float64
+}{
+	{"Ultimate", 35},
+	{"Pro", 10},
+}
+
+// JetBrainsAIInfo is the organization's JetBrains AI licenses and the AI credit quota of the
+// assigned seats. Per-user consumption isn't available through the Account API.
+type JetBrainsAIInfo struct {
+	SeatsTotal       float64              `json:"seatsTotal"`
+	SeatsAssigned    float64              `json:"seatsAssigned"`
+	CreditsAllowance float64              `json:"creditsAllowance"` // per 30 days across assigned seats
+	Products         []JetBrainsAIProduct `json:"products"`
+	RenewalDate      string               `json:"renewalDate,omitempty"`
+}
+
+func (j JetBrainsAIInfo) summary(locale string) string {
+	text := translate(locale, "summary.seats", formatCount(j.SeatsAssigned), formatCount(j.SeatsTotal))
+	if j.CreditsAllowance > 0 {
+		text += " " + translate(locale, "summary.jetbrains_credits", formatCount(j.CreditsAllowance))
+	}
+	return text
+}
+
+func (j JetBrainsAIInfo) compact(name, locale string) string {
+	return fmt.Sprintf("%s %s/%s", name, formatCount(j.SeatsAssigned), formatCount(j.SeatsTotal))
+}
+
+func (j JetBrainsAIInfo) usagePercent() (float64, bool) {
+	if j.SeatsTotal > 0 {
+		return j.SeatsAssigned / j.SeatsTotal * 100, true
+	}
+	return 0, false
+}
+
+func (j JetBrainsAIInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "plan_renewal", At: parseTime(j.RenewalDate)}}
+}
+
+// JetBrainsAIProduct is the seat count of one AI subscription, e.g. "JetBrains AI Pro".
+type JetBrainsAIProduct struct {
+	Name           string  `json:"name"`
+	Total          float64 `json:"total"`
+	Assigned       float64 `json:"assigned"`
+	CreditsPerSeat float64 `json:"creditsPerSeat"`
+}
+
+// jetbrainsLicensesResponse is GET /customer/licenses.
+type jetbrainsLicensesResponse []struct {
+	LicenseID string `json:"licenseId" schema:"required"`
+	Product   struct {
+		Code string `json:"code"`
+		Name string `json:"name" schema:"required"`
+	} `json:"product" schema:"required"`
+	Assignee            json.RawMessage `json:"assignee"` // null while unassigned
+	IsTrial             bool            `json:"isTrial"`
+	IsSuspended         bool            `json:"isSuspended"`
+	IsAvailableToAssign bool            `json:"isAvailableToAssign"`
+	Subscription        struct {
+		SubscriptionPackRef    string `json:"subscriptionPackRef"`
+		ValidUntilDate         string `json:"validUntilDate"`
+		IsAutomaticallyRenewed bool   `json:"isAutomaticallyRenewed"`
+		IsOutdated             bool   `json:"isOutdated"`
+	} `json:"subscription"`
+	Team struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"team"`
+}
+
+// jetbrainsCreditsPerSeat is the monthly credit quota of an AI product, or 0 when unknown.
+func jetbrainsCreditsPerSeat(product string) float64 {
+	for _, t := range jetbrainsAICredits {
+		if strings.Contains(product, t.Tier) {
+			return t.Credits
+		}
+	}
+	return 0
+}
+
+func (p *Plugin) getJetBrainsStatus(config *Configuration) ServiceStatus {
+	const id, name = "jetbrains", "JetBrains AI"
+	if config.JetbrainsApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+	if strings.TrimSpace(config.JetbrainsCustomerCode) == "" {
+		return errorStatus(id, name, "error.jetbrains_customer_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newJetBrainsRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var licenses jetbrainsLicensesResponse
+	drift, err := decodeResponse(body, &licenses)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info := JetBrainsAIInfo{}
+	products := map[string]*JetBrainsAIProduct{}
+	var renewal time.Time
+	for _, l := range licenses {
+		// AI subscriptions are products of their own, e.g. "JetBrains AI Ultimate"
+		if !strings.Contains(l.Product.Name, "AI") || l.IsSuspended {
+			continue
+		}
+		product, ok := products[l.Product.Name]
+		if !ok {
+			product = &JetBrainsAIProduct{Name: l.Product.Name, CreditsPerSeat: jetbrainsCreditsPerSeat(l.Product.Name)}
+			products[l.Product.Name] = product
+		}
+		product.Total++
+		if len(l.Assignee) > 0 && string(l.Assignee) != "null" {
+			product.Assigned++
+		}
+		if until, err := time.Parse("2006-01-02", l.Subscription.ValidUntilDate); err == nil && (renewal.IsZero() || until.Before(renewal)) {
+			renewal = until
+		}
+	}
+	if len(products) == 0 {
+		return errorStatus(id, name, "error.jetbrains_no_ai_licenses")
+	}
+	for _, product := range products {
+		info.SeatsTotal += product.Total
+		info.SeatsAssigned += product.Assigned
+		info.CreditsAllowance += product.Assigned * product.CreditsPerSeat
+		info.Products = append(info.Products, *product)
+	}
+	sort.Slice(info.Products, func(i, j int) bool { return info.Products[i].Name < info.Products[j].Name })
+	if !renewal.IsZero() {
+		info.RenewalDate = renewal.Format(time.RFC3339)
+	}
+
+	status := "ok"
+	if info.SeatsAssigned/info.SeatsTotal*100 > config.warningPercent(90) {
+		status = "warning"
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: status,
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+func newJetBrainsRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://account.jetbrains.com/api/v1/customer/licenses", nil)
+	req.Header.Set("X-Customer-Code", strings.TrimSpace(config.JetbrainsCustomerCode))
+	req.Header.Set("X-Api-Key", config.JetbrainsApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Jira escalation =====
+
+// checkEscalations opens Jira issues for providers that breached their budget
+// or have stayed in error longer than the configured threshold.
+func (p *Plugin) checkEscalations() {
+	config := p.getConfiguration()
+	if !config.JiraEnabled || config.JiraUrl == "" || config.JiraToken == "" || config.JiraProjectKey == "" {
+		return
+	}
+
+	criticalMins, err := strconv.Atoi(strings.TrimSpace(config.JiraCriticalMins))
+	if err != nil || criticalMins <= 0 {
+		criticalMins = 60
+	}
+
+	locale := p.serverLocale()
+
+	p.stateLock.Lock()
+	var toFile []ServiceStatus
+	var reasons []string
+	var report []ServiceStatus
+	for _, state := range p.states {
+		report = append(report, state.Last)
+		if state.Status != "error" && state.Status != "unreachable" || state.JiraFiled {
+			continue
+		}
+
+		reason := ""
+		if info, ok := state.Last.Data.(OpenAIUsageInfo); ok && info.Budget > 0 && info.TotalCost >= info.Budget {
+			reason = translate(locale, "alert.budget_exceeded", formatMoney(info.TotalCost, info.currency(), 2), formatMoney(info.Budget, info.currency(), 2))
+		} else if b, ok := state.Last.Data.(BudgetStatus); ok && b.Spent >= b.Amount {
+			reason = translate(locale, "alert.budget_exceeded", formatMoney(b.Spent, b.Currency, 2), formatMoney(b.Amount, b.Currency, 2))
+		} else if time.Since(state.Since) >= time.Duration(criticalMins)*time.Minute {
+			reason = translate(locale, "alert.critical_since", state.Since.UTC().Format(time.RFC1123), criticalMins)
+		}
+		if reason == "" {
+			continue
+		}
+
+		state.JiraFiled = true
+		toFile = append(toFile, state.Last)
+		reasons = append(reasons, reason)
+	}
+	p.stateLock.Unlock()
+
+	for i, s := range toFile {
+		if err := p.createJiraIssue(config, s, reasons[i], report, locale); err != nil {
+			p.API.LogWarn("Failed to create Jira issue", "provider", s.ID, "error", err.Error())
+		}
+	}
+}
+
+// createJiraIssue opens an issue for the incident and attaches the full usage report.
+func (p *Plugin) createJiraIssue(config *Configuration, s ServiceStatus, reason string, report []ServiceStatus, locale string) error {
+	issueType := config.JiraIssueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+
+	s = localizeStatuses([]ServiceStatus{s}, locale)[0]
+	localizeStatuses(report, locale)
+
+	description := reason + "\n\n"
+	if s.Error != "" {
+		description += translate(locale, "alert.error", s.Error) + "\n\n"
+	}
+	description += translate(locale, "alert.current_usage") + "\n"
+	for _, r := range report {
+		description += fmt.Sprintf("* %s (%s): %s\n", r.Name, r.Status, summarizeService(r, locale))
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": config.JiraProjectKey},
+			"summary":     translate(locale, "alert.issue_summary", s.Name, s.Status),
+			"description": description,
+			"issuetype":   map[string]string{"name": issueType},
+		},
+	})
+
+	baseURL := strings.TrimRight(config.JiraUrl, "/")
+	req, _ := http.NewRequest("POST", baseURL+"/rest/api/2/issue", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	setJiraAuth(req, config)
+
+	client := p.outboundClient(15 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 201 && resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var created map[string]interface{}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return err
+	}
+	key := getString(created, "key")
+	if key == "" {
+		return fmt.Errorf("no issue key in response")
+	}
+
+	// Attach the usage report as JSON
+	reportJSON, _ := json.MarshalIndent(report, "", "  ")
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, _ := mw.CreateFormFile("file", "usage-report.json")
+	fw.Write(reportJSON)
+	mw.Close()
+
+	req2, _ := http.NewRequest("POST", baseURL+"/rest/api/2/issue/"+key+"/attachments", &buf)
+	req2.Header.Set("Content-Type", mw.FormDataContentType())
+	req2.Header.Set("X-Atlassian-Token", "no-check")
+	setJiraAuth(req2, config)
+
+	resp2, err := client.Do(req2)
+	if err != nil {
+		return fmt.Errorf("issue %s created, attachment failed: %w", key, err)
+	}
+	defer resp2.Body.Close()
+	if resp2.StatusCode != 200 {
+		return fmt.Errorf("issue %s created, attachment failed: HTTP %d", key, resp2.StatusCode)
+	}
+
+	return nil
+}
+
+// setJiraAuth uses basic auth (Jira Cloud, email + API token) when a username is
+// configured, and a bearer personal access token (Jira Server/DC) otherwise.
+func setJiraAuth(req *http.Request, config *Configuration) {
+	if config.JiraUsername != "" {
+		req.SetBasicAuth(config.JiraUsername, config.JiraToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+config.JiraToken)
+	}
+}
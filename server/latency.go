@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ===== Provider latency history =====
+
+// maxTrendDays bounds how far back /api/v1/trends reaches.
+const maxTrendDays = 90
+
+// latencyDay sums one provider's requests on one day (UTC).
+type latencyDay struct {
+	Requests int   `json:"requests"`
+	Failures int   `json:"failures"`
+	TotalMs  int64 `json:"totalMs"`
+	MaxMs    int64 `json:"maxMs"`
+	// Requests and failures per hour of the day, for availability over the last 24 hours
+	Hours []latencyHour `json:"hours,omitempty"`
+}
+
+type latencyHour struct {
+	Requests int `json:"requests"`
+	Failures int `json:"failures"`
+}
+
+func (d *latencyDay) add(o latencyDay) {
+	d.Requests += o.Requests
+	d.Failures += o.Failures
+	d.TotalMs += o.TotalMs
+	d.MaxMs = max(d.MaxMs, o.MaxMs)
+	if len(o.Hours) > 0 && len(d.Hours) == 0 {
+		d.Hours = make([]latencyHour, 24)
+	}
+	for i, h := range o.Hours {
+		d.Hours[i].Requests += h.Requests
+		d.Hours[i].Failures += h.Failures
+	}
+}
+
+// latencyMonth is every provider's daily latency in a calendar month (UTC).
+type latencyMonth struct {
+	Month     string                           `json:"month"`     // "2006-01"
+	Providers map[string]map[string]latencyDay `json:"providers"` // provider → "2006-01-02" → day
+}
+
+func latencyMonthKey(month string) string {
+	return "latency_" + month
+}
+
+// latencyRecorder collects request latencies in memory until they're flushed to the KV
+// store, so a request doesn't cost a KV write. Provider instances share their parent's.
+type latencyRecorder struct {
+	lock    sync.Mutex
+	pending map[string]map[string]latencyDay
+}
+
+func (l *latencyRecorder) record(provider string, latency time.Duration, failed bool) {
+	if l == nil {
+		return
+	}
+	now := time.Now().UTC()
+	day := latencyDay{Requests: 1, TotalMs: latency.Milliseconds(), MaxMs: latency.Milliseconds(), Hours: make([]latencyHour, 24)}
+	day.Hours[now.Hour()].Requests = 1
+	if failed {
+		day.Failures = 1
+		day.Hours[now.Hour()].Failures = 1
+	}
+	date := now.Format("2006-01-02")
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.pending == nil {
+		l.pending = map[string]map[string]latencyDay{}
+	}
+	if l.pending[provider] == nil {
+		l.pending[provider] = map[string]latencyDay{}
+	}
+	d := l.pending[provider][date]
+	d.add(day)
+	l.pending[provider][date] = d
+}
+
+// take returns and clears the latencies not flushed yet.
+func (l *latencyRecorder) take() map[string]map[string]latencyDay {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	pending := l.pending
+	l.pending = nil
+	return pending
+}
+
+// peek returns a copy of the latencies not flushed yet.
+func (l *latencyRecorder) peek() map[string]map[string]latencyDay {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	pending := map[string]map[string]latencyDay{}
+	for provider, days := range l.pending {
+		pending[provider] = map[string]latencyDay{}
+		for date, d := range days {
+			pending[provider][date] = d
+		}
+	}
+	return pending
+}
+
+func (p *Plugin) loadLatencyMonth(month string) latencyMonth {
+	m := latencyMonth{Month: month}
+	if data, appErr := p.API.KVGet(latencyMonthKey(month)); appErr == nil && data != nil {
+		json.Unmarshal(data, &m)
+	}
+	if m.Providers == nil {
+		m.Providers = map[string]map[string]latencyDay{}
+	}
+	return m
+}
+
+// flushLatencies adds the latencies recorded since the last flush to the stored months.
+func (p *Plugin) flushLatencies() {
+	pending := p.latency.take()
+	if len(pending) == 0 {
+		return
+	}
+
+	p.historyLock.Lock()
+	defer p.historyLock.Unlock()
+
+	months := map[string]latencyMonth{}
+	for provider, days := range pending {
+		for date, d := range days {
+			month := date[:7]
+			m, ok := months[month]
+			if !ok {
+				m = p.loadLatencyMonth(month)
+				months[month] = m
+			}
+			if m.Providers[provider] == nil {
+				m.Providers[provider] = map[string]latencyDay{}
+			}
+			stored := m.Providers[provider][date]
+			stored.add(d)
+			m.Providers[provider][date] = stored
+		}
+	}
+
+	for month, m := range months {
+		data, _ := json.Marshal(m)
+		if appErr := p.API.KVSet(latencyMonthKey(month), data); appErr != nil {
+			p.API.LogWarn("Failed to store latency history", "month", month, "error", appErr.Error())
+		}
+	}
+}
+
+// latencyHistory returns the daily latencies of the last days, including those not
+// flushed yet, by provider and date.
+func (p *Plugin) latencyHistory(days int) map[string]map[string]latencyDay {
+	now := time.Now().UTC()
+	from := now.AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+
+	history := map[string]map[string]latencyDay{}
+	merge := func(provider, date string, d latencyDay) {
+		if date < from {
+			return
+		}
+		if history[provider] == nil {
+			history[provider] = map[string]latencyDay{}
+		}
+		stored := history[provider][date]
+		stored.add(d)
+		history[provider][date] = stored
+	}
+
+	p.historyLock.Lock()
+	for month := from[:7]; month <= now.Format("2006-01"); {
+		for provider, byDate := range p.loadLatencyMonth(month).Providers {
+			for date, d := range byDate {
+				merge(provider, date, d)
+			}
+		}
+		t, _ := time.Parse("2006-01", month)
+		month = t.AddDate(0, 1, 0).Format("2006-01")
+	}
+	p.historyLock.Unlock()
+
+	for provider, byDate := range p.latency.peek() {
+		for date, d := range byDate {
+			merge(provider, date, d)
+		}
+	}
+	return history
+}
+
+// LatencyPoint is one provider's status API latency on one day.
+type LatencyPoint struct {
+	Date     string `json:"date"`
+	Requests int    `json:"requests"`
+	Failures int    `json:"failures"`
+	AvgMs    int64  `json:"avgMs"`
+	MaxMs    int64  `json:"maxMs"`
+}
+
+// LatencyTrend is the daily latency of one provider's status API.
+type LatencyTrend struct {
+	Provider string         `json:"provider"`
+	Days     []LatencyPoint `json:"days"`
+}
+
+// handleTrends serves GET /api/v1/trends?days=N: the daily latency of each provider's
+// status API, to tell a degrading provider API from a problem in the plugin.
+func (p *Plugin) handleTrends(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxTrendDays {
+			http.Error(w, fmt.Sprintf(`{"error": "invalid_days", "message": "days must be between 1 and %d"}`, maxTrendDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	trends := []LatencyTrend{}
+	for provider, byDate := range p.latencyHistory(days) {
+		trend := LatencyTrend{Provider: provider, Days: []LatencyPoint{}}
+		for date, d := range byDate {
+			point := LatencyPoint{Date: date, Requests: d.Requests, Failures: d.Failures, MaxMs: d.MaxMs}
+			if d.Requests > 0 {
+				point.AvgMs = d.TotalMs / int64(d.Requests)
+			}
+			trend.Days = append(trend.Days, point)
+		}
+		sort.Slice(trend.Days, func(i, j int) bool { return trend.Days[i].Date < trend.Days[j].Date })
+		trends = append(trends, trend)
+	}
+	sort.Slice(trends, func(i, j int) bool { return trends[i].Provider < trends[j].Provider })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"days":         days,
+		"latency":      trends,
+		"availability": p.availabilityReport(),
+	})
+}
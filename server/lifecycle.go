@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ===== Background work and shutdown =====
+
+// shutdownTimeout is how long OnDeactivate waits for background work to finish.
+const shutdownTimeout = 10 * time.Second
+
+// errShuttingDown fails provider requests started after deactivation.
+var errShuttingDown = errors.New("plugin is shutting down")
+
+// background tracks the goroutines the plugin starts, so deactivation can cancel them and
+// wait for them to finish instead of leaving them to call the API of a stopped plugin.
+// Provider instances share their parent's.
+type background struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// lock orders Go's check of ctx before stop's wait, as the WaitGroup requires
+	lock sync.Mutex
+	wg   sync.WaitGroup
+}
+
+func newBackground() *background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &background{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a tracked goroutine, surviving a panic. It returns false without running fn
+// once the plugin is shutting down, or before it's activated.
+func (b *background) Go(p *Plugin, fn func()) bool {
+	if b == nil {
+		return false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.ctx.Err() != nil {
+		return false
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer p.recoverJob()
+		fn()
+	}()
+	return true
+}
+
+// done is closed when the plugin starts shutting down.
+func (b *background) done() <-chan struct{} {
+	if b == nil {
+		return nil
+	}
+	return b.ctx.Done()
+}
+
+// stopping reports whether the plugin is shutting down.
+func (b *background) stopping() bool {
+	return b != nil && b.ctx.Err() != nil
+}
+
+// stop cancels the background work and waits up to timeout for it to finish. It returns
+// false if some of it is still running.
+func (b *background) stop(timeout time.Duration) bool {
+	b.lock.Lock()
+	b.cancel()
+	b.lock.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// bind cancels the request when the plugin shuts down, so a slow provider doesn't hold up
+// deactivation. release must be called once the response is done with.
+func (b *background) bind(req *http.Request) (_ *http.Request, release func(), err error) {
+	if b == nil {
+		return req, func() {}, nil
+	}
+	if b.ctx.Err() != nil {
+		return nil, nil, errShuttingDown
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(b.ctx, cancel)
+	return req.WithContext(ctx), func() { stop(); cancel() }, nil
+}
+
+// releaseBody calls release when the response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
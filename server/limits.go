@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ===== Response size limits =====
+
+// defaultMaxResponseKB bounds responses read from external APIs when no limit is configured.
+const defaultMaxResponseKB = 1024
+
+// maxStaticFileSize bounds the files served from the plugin bundle.
+const maxStaticFileSize = 32 << 20
+
+// maxResponseBytes is the configured limit for responses read from external APIs.
+func (c *Configuration) maxResponseBytes() int64 {
+	kb, err := strconv.ParseInt(strings.TrimSpace(c.MaxResponseSizeKb), 10, 64)
+	if err != nil || kb <= 0 {
+		kb = defaultMaxResponseKB
+	}
+	return kb * 1024
+}
+
+// errResponseTooLarge is returned instead of a truncated body, which would only fail
+// later with a confusing parse error.
+type errResponseTooLarge struct {
+	limit int64
+}
+
+func (e errResponseTooLarge) Error() string {
+	return fmt.Sprintf("response exceeds the %d KB size limit", e.limit/1024)
+}
+
+// readBody reads at most limit bytes, without allocating more than that for larger bodies.
+func readBody(body io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errResponseTooLarge{limit: limit}
+	}
+	return data, nil
+}
+
+// readResponse reads an external API response within the configured size limit.
+func (p *Plugin) readResponse(resp *http.Response) ([]byte, error) {
+	return readBody(resp.Body, p.getConfiguration().maxResponseBytes())
+}
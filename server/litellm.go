@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== LiteLLM proxy (master key) =====
+
+// litellmMaxKeyPages bounds the virtual key listing, 100 keys per page.
+const litellmMaxKeyPages = 10
+
+// LiteLLMBudgetInfo is the spend and budgets tracked by a LiteLLM proxy. Orgs that route
+// every provider through the proxy enforce their internal budgets there, so it is the
+// authoritative view of them. LiteLLM records spend in USD.
+type LiteLLMBudgetInfo struct {
+	TotalSpend  float64         `json:"totalSpend"`
+	TotalBudget float64         `json:"totalBudget,omitempty"` // proxy-wide max_budget
+	Currency    string          `json:"currency"`
+	Teams       []LiteLLMBudget `json:"teams"`
+	Keys        []LiteLLMBudget `json:"keys"` // keys with a budget, most utilized first
+	KeysTotal   int             `json:"keysTotal"`
+	OverBudget  int             `json:"overBudget"` // teams and keys that reached their budget
+}
+
+// LiteLLMBudget is the spend of a team or virtual key against its max_budget. Budgets with
+// a duration reset at ResetAt; the others are lifetime caps.
+type LiteLLMBudget struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Spend     float64 `json:"spend"`
+	MaxBudget float64 `json:"maxBudget,omitempty"`
+	ResetAt   string  `json:"resetAt,omitempty"`
+	Blocked   bool    `json:"blocked,omitempty"`
+}
+
+// percent is the share of the budget spent, or 0 without a budget.
+func (b LiteLLMBudget) percent() float64 {
+	if b.MaxBudget <= 0 {
+		return 0
+	}
+	return b.Spend / b.MaxBudget * 100
+}
+
+// mostUtilized returns the team or key closest to its budget, or false if none has one.
+func (d LiteLLMBudgetInfo) mostUtilized() (LiteLLMBudget, bool) {
+	var worst LiteLLMBudget
+	found := false
+	for _, list := range [][]LiteLLMBudget{d.Teams, d.Keys} {
+		for _, b := range list {
+			if b.MaxBudget > 0 && (!found || b.percent() > worst.percent()) {
+				worst, found = b, true
+			}
+		}
+	}
+	return worst, found
+}
+
+func (d LiteLLMBudgetInfo) summary(locale string) string {
+	var text string
+	if d.TotalBudget > 0 {
+		text = translate(locale, "summary.litellm_budget", formatMoney(d.TotalSpend, d.Currency, 2), formatMoney(d.TotalBudget, d.Currency, 0))
+	} else {
+		text = translate(locale, "summary.litellm_spend", formatMoney(d.TotalSpend, d.Currency, 2))
+	}
+	if worst, ok := d.mostUtilized(); ok {
+		text += ", " + translate(locale, "summary.litellm_worst", worst.Name, formatMoney(worst.Spend, d.Currency, 2), formatMoney(worst.MaxBudget, d.Currency, 0))
+	}
+	if d.OverBudget > 0 {
+		text += ", " + translate(locale, "summary.litellm_over_budget", d.OverBudget)
+	}
+	return text
+}
+
+func (d LiteLLMBudgetInfo) usagePercent() (float64, bool) {
+	pct, ok := 0.0, false
+	if d.TotalBudget > 0 {
+		pct, ok = d.TotalSpend/d.TotalBudget*100, true
+	}
+	if worst, found := d.mostUtilized(); found {
+		pct, ok = max(pct, worst.percent()), true
+	}
+	return pct, ok
+}
+
+func (d LiteLLMBudgetInfo) resets(time.Time) []ResetInfo {
+	if worst, ok := d.mostUtilized(); ok {
+		return []ResetInfo{{Kind: "budget_reset", At: parseTime(worst.ResetAt)}}
+	}
+	return nil
+}
+
+// litellmTeamsResponse is GET /team/list.
+type litellmTeamsResponse []struct {
+	TeamID         string    `json:"team_id" schema:"required"`
+	TeamAlias      string    `json:"team_alias"`
+	Spend          flexFloat `json:"spend" schema:"required"`
+	MaxBudget      flexFloat `json:"max_budget"`
+	BudgetReset    string    `json:"budget_reset_at"`
+	BudgetPeriod   string    `json:"budget_duration"`
+	Blocked        bool      `json:"blocked"`
+	OrganizationID string    `json:"organization_id"`
+}
+
+// litellmKeysResponse is GET /key/list with full key objects.
+type litellmKeysResponse struct {
+	Keys []struct {
+		Token       string    `json:"token" schema:"required"`
+		KeyAlias    string    `json:"key_alias"`
+		KeyName     string    `json:"key_name"`
+		Spend       flexFloat `json:"spend" schema:"required"`
+		MaxBudget   flexFloat `json:"max_budget"`
+		BudgetReset string    `json:"budget_reset_at"`
+		TeamID      string    `json:"team_id"`
+		UserID      string    `json:"user_id"`
+		Blocked     bool      `json:"blocked"`
+	} `json:"keys" schema:"required"`
+	TotalCount flexFloat `json:"total_count"`
+	TotalPages flexFloat `json:"total_pages"`
+}
+
+// litellmSpendResponse is GET /global/spend.
+type litellmSpendResponse struct {
+	Spend     flexFloat `json:"spend" schema:"required"`
+	MaxBudget flexFloat `json:"max_budget"`
+}
+
+// litellmURL is the configured proxy base URL without a trailing slash.
+func (c *Configuration) litellmURL() string {
+	return strings.TrimRight(strings.TrimSpace(c.LitellmUrl), "/")
+}
+
+func (p *Plugin) getLiteLLMStatus(config *Configuration) ServiceStatus {
+	const id, name = "litellm", "LiteLLM"
+	if config.litellmURL() == "" {
+		return errorStatus(id, name, "error.litellm_url_missing")
+	}
+	if config.LitellmMasterKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	body, err := p.litellmGet(client, config, "/team/list", nil)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	var teams litellmTeamsResponse
+	drift, err := decodeResponse(body, &teams)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	info := LiteLLMBudgetInfo{Currency: "USD"}
+	for _, t := range teams {
+		team := LiteLLMBudget{
+			ID: t.TeamID, Name: t.TeamAlias, Spend: float64(t.Spend), MaxBudget: float64(t.MaxBudget),
+			ResetAt: litellmTime(t.BudgetReset), Blocked: t.Blocked,
+		}
+		if team.Name == "" {
+			team.Name = t.TeamID
+		}
+		info.Teams = append(info.Teams, team)
+	}
+
+	keysSpend := 0.0
+	for page := 1; page <= litellmMaxKeyPages; page++ {
+		query := neturl.Values{"return_full_object": {"true"}, "page": {strconv.Itoa(page)}, "size": {"100"}}
+		body, err := p.litellmGet(client, config, "/key/list", query)
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		var keys litellmKeysResponse
+		d, err := decodeResponse(body, &keys)
+		if err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		drift.merge(d)
+
+		for _, k := range keys.Keys {
+			info.KeysTotal++
+			keysSpend += float64(k.Spend)
+			if k.MaxBudget <= 0 {
+				continue
+			}
+			key := LiteLLMBudget{
+				ID: k.Token, Name: k.KeyAlias, Spend: float64(k.Spend), MaxBudget: float64(k.MaxBudget),
+				ResetAt: litellmTime(k.BudgetReset), Blocked: k.Blocked,
+			}
+			if key.Name == "" {
+				// key_name is the redacted key, e.g. "sk-...Ab3d"
+				key.Name = k.KeyName
+			}
+			info.Keys = append(info.Keys, key)
+		}
+		if page >= int(keys.TotalPages) {
+			break
+		}
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	// Deleted keys keep counting towards the proxy total, so prefer it when available
+	info.TotalSpend = keysSpend
+	if body, err := p.litellmGet(client, config, "/global/spend", nil); err == nil {
+		var spend litellmSpendResponse
+		if _, err := decodeResponse(body, &spend); err == nil {
+			info.TotalSpend = float64(spend.Spend)
+			info.TotalBudget = float64(spend.MaxBudget)
+		}
+	}
+
+	for _, list := range [][]LiteLLMBudget{info.Teams, info.Keys} {
+		for _, b := range list {
+			if b.MaxBudget > 0 && b.Spend >= b.MaxBudget {
+				info.OverBudget++
+			}
+		}
+	}
+	sort.Slice(info.Teams, func(i, j int) bool { return info.Teams[i].percent() > info.Teams[j].percent() })
+	sort.Slice(info.Keys, func(i, j int) bool { return info.Keys[i].percent() > info.Keys[j].percent() })
+	info.Keys = info.Keys[:min(len(info.Keys), 10)]
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: litellmStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// litellmStatus is an error when the proxy-wide budget or a team budget is used up, which
+// blocks every key behind it. A single exhausted key only warns.
+func litellmStatus(info LiteLLMBudgetInfo, config *Configuration) string {
+	status := budgetStatus(info.TotalSpend, info.TotalBudget, config)
+	if status == "error" {
+		return status
+	}
+	for _, t := range info.Teams {
+		switch budgetStatus(t.Spend, t.MaxBudget, config) {
+		case "error":
+			return "error"
+		case "warning":
+			status = "warning"
+		}
+	}
+	for _, k := range info.Keys {
+		if k.MaxBudget > 0 && k.percent() > config.warningPercent(80) {
+			status = "warning"
+		}
+	}
+	return status
+}
+
+// litellmTime converts LiteLLM's timestamps, which may lack a zone, to RFC 3339.
+func litellmTime(value string) string {
+	if value == "" {
+		return ""
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// litellmGet sends a request and returns the body of a successful response.
+func (p *Plugin) litellmGet(client *http.Client, config *Configuration, path string, query neturl.Values) ([]byte, error) {
+	resp, err := client.Do(newLiteLLMRequest(config, path, query))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+func newLiteLLMRequest(config *Configuration, path string, query neturl.Values) *http.Request {
+	url := config.litellmURL() + path
+	if len(query) > 0 {
+		url += "?" + query.Encode()
+	}
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Authorization", "Bearer "+config.LitellmMasterKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
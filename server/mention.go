@@ -0,0 +1,91 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// ===== Bot mentions =====
+
+// botMentionPattern matches an @-mention of the bot.
+var botMentionPattern = regexp.MustCompile(`(?i)(^|[^\w.-])@ailimits\b`)
+
+// MessageHasBeenPosted answers messages that mention the bot, like "@ailimits how is
+// claude?", with the status of the providers they name, or of all providers.
+func (p *Plugin) MessageHasBeenPosted(c *plugin.Context, post *model.Post) {
+	if !p.getConfiguration().MentionsEnabled || p.botUserID == "" || post.UserId == p.botUserID {
+		return
+	}
+	if post.IsSystemMessage() || post.GetProp("from_bot") == "true" || post.GetProp("from_webhook") == "true" {
+		return
+	}
+	if !botMentionPattern.MatchString(post.Message) {
+		return
+	}
+
+	uc := p.getUserContext(post.UserId)
+	if !p.checkAccess(post.UserId) {
+		p.API.SendEphemeralPost(post.UserId, &model.Post{ChannelId: post.ChannelId, RootId: post.RootId, Message: translate(uc.Locale, "command.access_denied")})
+		return
+	}
+	p.markActive()
+
+	words := mentionWords(botMentionPattern.ReplaceAllString(post.Message, " "))
+	var shown []string
+	text := p.statusText(uc, func(s ServiceStatus) bool {
+		if mentionsService(words, s) {
+			shown = append(shown, s.ID)
+			return true
+		}
+		return false
+	})
+	if text == "" {
+		text = p.statusText(uc, nil)
+		shown = []string{"all"}
+	}
+
+	rootID := post.RootId
+	if rootID == "" {
+		rootID = post.Id
+	}
+	reply := &model.Post{ChannelId: post.ChannelId, UserId: p.botUserID, RootId: rootID, Message: text}
+	reply.AddProp(statusPostProp, strings.Join(shown, ","))
+	// With an allowlist, others in the channel may not be allowed to see the status
+	config := p.getConfiguration()
+	if config.accessRestricted() {
+		p.API.SendEphemeralPost(post.UserId, reply)
+		return
+	}
+	if _, appErr := p.API.CreatePost(reply); appErr != nil {
+		p.API.LogWarn("Failed to answer a mention", "channel_id", post.ChannelId, "error", appErr.Error())
+	}
+}
+
+// mentionWords splits a message into lowercase words.
+func mentionWords(message string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != ':'
+	}) {
+		words[strings.Trim(w, "-_:")] = true
+	}
+	return words
+}
+
+// mentionsService reports whether the words name the service: its card ID, its provider
+// ID, or a word of its display name of at least three letters.
+func mentionsService(words map[string]bool, s ServiceStatus) bool {
+	if words[strings.ToLower(s.ID)] || words[strings.Split(s.ID, ":")[0]] {
+		return true
+	}
+	for w := range mentionWords(s.Name) {
+		if len(w) >= 3 && words[w] {
+			return true
+		}
+	}
+	return false
+}
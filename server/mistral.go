@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Mistral La Plateforme (API key, optional console session) =====
+
+// MistralUsageInfo is a workspace's monthly token limit and this month's consumption per model.
+// Limits come from rate limit headers on API calls; per-model usage needs the console session.
+type MistralUsageInfo struct {
+	HasLimits       bool                `json:"hasLimits"`
+	TokensLimit     float64             `json:"tokensLimit"`
+	TokensRemaining float64             `json:"tokensRemaining"`
+	TokensPerMinute float64             `json:"tokensPerMinute,omitempty"`
+	HasUsage        bool                `json:"hasUsage"`
+	TokensUsed      float64             `json:"tokensUsed"`
+	TotalCost       float64             `json:"totalCost"`
+	Currency        string              `json:"currency"`
+	Models          []MistralModelUsage `json:"models,omitempty"`
+	Period          string              `json:"period"`
+	CycleEnd        string              `json:"cycleEnd"`
+}
+
+func (m MistralUsageInfo) summary(locale string) string {
+	var text string
+	switch {
+	case m.HasLimits:
+		text = translate(locale, "summary.mistral_limit", formatCount(m.TokensUsed), formatCount(m.TokensLimit))
+	case m.HasUsage:
+		text = translate(locale, "summary.mistral_used", formatCount(m.TokensUsed))
+	default:
+		return translate(locale, "summary.mistral_no_data")
+	}
+	if m.TotalCost > 0 {
+		text += " " + translate(locale, "summary.mistral_cost", formatMoney(m.TotalCost, m.Currency, 2))
+	}
+	return text
+}
+
+func (m MistralUsageInfo) usagePercent() (float64, bool) {
+	if m.HasLimits && m.TokensLimit > 0 {
+		return m.TokensUsed / m.TokensLimit * 100, true
+	}
+	return 0, false
+}
+
+func (m MistralUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(m.CycleEnd)}}
+}
+
+// MistralModelUsage is one model's month-to-date consumption.
+type MistralModelUsage struct {
+	Model        string  `json:"model"`
+	InputTokens  float64 `json:"inputTokens"`
+	OutputTokens float64 `json:"outputTokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// mistralUsageEntry is one billing metric of a model, e.g. its input tokens.
+type mistralUsageEntry struct {
+	BillingMetric string    `json:"billing_metric"`
+	Value         flexFloat `json:"value" schema:"required"`
+	ValuePaid     flexFloat `json:"value_paid"`
+}
+
+// mistralUsageResponse is the console's /api/billing/v2/usage for a month. Models are keyed
+// "<alias>::<version>"; prices are per token and keyed by billing metric.
+type mistralUsageResponse struct {
+	Completion struct {
+		Models map[string]struct {
+			Input  []mistralUsageEntry `json:"input"`
+			Output []mistralUsageEntry `json:"output"`
+		} `json:"models"`
+	} `json:"completion" schema:"required"`
+	Currency       string `json:"currency"`
+	CurrencySymbol string `json:"currency_symbol"`
+	Prices         []struct {
+		BillingMetric string    `json:"billing_metric"`
+		BillingGroup  string    `json:"billing_group"`
+		Price         flexFloat `json:"price"`
+	} `json:"prices"`
+}
+
+func (p *Plugin) getMistralStatus(config *Configuration) ServiceStatus {
+	const id, name = "mistral", "Mistral"
+	if config.MistralApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := MistralUsageInfo{
+		// La Plateforme bills in euros
+		Currency: "EUR",
+		Period:   cyclePeriod(monthStart, monthEnd),
+		CycleEnd: monthEnd.Format(time.RFC3339),
+	}
+
+	// Listing models is free and carries the workspace's rate limit headers
+	resp, err := client.Do(newMistralRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	if limit, ok := headerFloat(resp.Header, "x-ratelimitbysize-limit-month"); ok {
+		info.HasLimits = true
+		info.TokensLimit = limit
+		info.TokensRemaining, _ = headerFloat(resp.Header, "x-ratelimitbysize-remaining-month")
+	}
+	info.TokensPerMinute, _ = headerFloat(resp.Header, "x-ratelimitbysize-limit-minute")
+
+	if config.MistralConsoleCookie != "" {
+		if err := p.fetchMistralUsage(client, config, now, &info); err != nil {
+			p.API.LogWarn("Failed to fetch Mistral usage", "error", err.Error())
+		}
+	}
+	if info.HasLimits && !info.HasUsage {
+		info.TokensUsed = info.TokensLimit - info.TokensRemaining
+	}
+
+	status := "ok"
+	if pct, ok := usagePercent(ServiceStatus{Data: info}); ok && pct > config.warningPercent(90) {
+		status = "warning"
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: status,
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// fetchMistralUsage adds this month's per-model consumption from the console's billing API.
+func (p *Plugin) fetchMistralUsage(client *http.Client, config *Configuration, now time.Time, info *MistralUsageInfo) error {
+	url := fmt.Sprintf("https://console.mistral.ai/api/billing/v2/usage?month=%d&year=%d", int(now.Month()), now.Year())
+	req, _ := http.NewRequest("GET", url, nil)
+	// The console authenticates with its session cookie, pasted as "name=value"
+	req.Header.Set("Cookie", strings.TrimSpace(config.MistralConsoleCookie))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var usage mistralUsageResponse
+	drift, err := decodeResponse(body, &usage)
+	if err != nil {
+		return err
+	}
+	if err := p.checkSchema("mistral", drift); err != nil {
+		return err
+	}
+
+	prices := map[string]float64{}
+	for _, price := range usage.Prices {
+		prices[price.BillingMetric] = float64(price.Price)
+	}
+	// Tokens are billed per metric; paid values exclude the free tier
+	cost := func(entries []mistralUsageEntry) (tokens, amount float64) {
+		for _, e := range entries {
+			tokens += float64(e.Value)
+			amount += float64(e.ValuePaid) * prices[e.BillingMetric]
+		}
+		return tokens, amount
+	}
+
+	info.HasUsage = true
+	if usage.Currency != "" {
+		info.Currency = strings.ToUpper(usage.Currency)
+	}
+	for key, m := range usage.Completion.Models {
+		model, _, _ := strings.Cut(key, "::")
+		in, inCost := cost(m.Input)
+		out, outCost := cost(m.Output)
+		if in == 0 && out == 0 {
+			continue
+		}
+		info.Models = append(info.Models, MistralModelUsage{Model: model, InputTokens: in, OutputTokens: out, Cost: inCost + outCost})
+		info.TokensUsed += in + out
+		info.TotalCost += inCost + outCost
+	}
+	sort.Slice(info.Models, func(i, j int) bool {
+		return info.Models[i].InputTokens+info.Models[i].OutputTokens > info.Models[j].InputTokens+info.Models[j].OutputTokens
+	})
+	return nil
+}
+
+func newMistralRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.mistral.ai/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.MistralApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// headerFloat parses a numeric response header.
+func headerFloat(h http.Header, name string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(h.Get(name)), 64)
+	return v, err == nil
+}
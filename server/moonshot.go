@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ===== Moonshot AI / Kimi (API key) =====
+
+// moonshotTierLimits are the rate limits of each tier, which Moonshot assigns by cumulative
+// recharge: concurrent requests, requests per minute and tokens per minute.
+var moonshotTierLimits = map[string][3]float64{
+	"Tier0": {1, 3, 500000},
+	"Tier1": {50, 200, 2000000},
+	"Tier2": {100, 500, 3000000},
+	"Tier3": {200, 5000, 3000000},
+	"Tier4": {400, 5000, 4000000},
+	"Tier5": {1000, 10000, 5000000},
+}
+
+// MoonshotBalanceInfo is the account's balance, split into cash and vouchers, and the rate
+// limits of its tier. The API doesn't report the tier, so it comes from the configuration.
+type MoonshotBalanceInfo struct {
+	Currency          string  `json:"currency"`
+	AvailableBalance  float64 `json:"availableBalance"`
+	CashBalance       float64 `json:"cashBalance"`
+	VoucherBalance    float64 `json:"voucherBalance"`
+	LowBalance        float64 `json:"lowBalance,omitempty"`
+	Tier              string  `json:"tier,omitempty"`
+	Concurrency       float64 `json:"concurrency,omitempty"`
+	RequestsPerMinute float64 `json:"requestsPerMinute,omitempty"`
+	TokensPerMinute   float64 `json:"tokensPerMinute,omitempty"`
+}
+
+func (m MoonshotBalanceInfo) summary(locale string) string {
+	text := translate(locale, "summary.moonshot", formatMoney(m.AvailableBalance, m.Currency, 2),
+		formatMoney(m.CashBalance, m.Currency, 2), formatMoney(m.VoucherBalance, m.Currency, 2))
+	if m.RequestsPerMinute > 0 {
+		text += ", " + translate(locale, "summary.moonshot_tier", m.Tier, formatCount(m.RequestsPerMinute), formatCount(m.Concurrency))
+	}
+	return text
+}
+
+func (m MoonshotBalanceInfo) compact(name, locale string) string {
+	return translate(locale, "compact.balance_left", name, formatMoney(m.AvailableBalance, m.Currency, 0))
+}
+
+// moonshotBalanceResponse is GET /v1/users/me/balance.
+type moonshotBalanceResponse struct {
+	Code flexFloat `json:"code"`
+	Data struct {
+		AvailableBalance flexFloat `json:"available_balance" schema:"required"`
+		VoucherBalance   flexFloat `json:"voucher_balance"`
+		CashBalance      flexFloat `json:"cash_balance"`
+	} `json:"data" schema:"required"`
+	Scode  string `json:"scode"`
+	Status bool   `json:"status"`
+}
+
+// moonshotPlatform returns the API host and billing currency: the international platform
+// bills in USD, the mainland China one in CNY.
+func (c *Configuration) moonshotPlatform() (host, currency string) {
+	if c.MoonshotPlatform == "china" {
+		return "api.moonshot.cn", "CNY"
+	}
+	return "api.moonshot.ai", "USD"
+}
+
+func (p *Plugin) getMoonshotStatus(config *Configuration) ServiceStatus {
+	const id, name = "moonshot", "Moonshot AI"
+	if config.MoonshotApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 10*time.Second)
+	resp, err := client.Do(newMoonshotRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	var raw moonshotBalanceResponse
+	drift, err := decodeResponse(body, &raw)
+	if err == nil && raw.Code != 0 {
+		err = fmt.Errorf("code %v: %s", raw.Code, raw.Scode)
+	}
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	_, currency := config.moonshotPlatform()
+	info := MoonshotBalanceInfo{
+		Currency:         currency,
+		AvailableBalance: float64(raw.Data.AvailableBalance),
+		CashBalance:      float64(raw.Data.CashBalance),
+		VoucherBalance:   float64(raw.Data.VoucherBalance),
+		Tier:             config.MoonshotTier,
+	}
+	if limits, ok := moonshotTierLimits[info.Tier]; ok {
+		info.Concurrency, info.RequestsPerMinute, info.TokensPerMinute = limits[0], limits[1], limits[2]
+	}
+	info.LowBalance = p.budgetIn(config, id, config.MoonshotLowBalance, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: moonshotStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// moonshotStatus is an error once the balance is used up, as Moonshot then refuses
+// inference calls, and a warning below the configured low balance.
+func moonshotStatus(info MoonshotBalanceInfo) string {
+	switch {
+	case info.AvailableBalance <= 0:
+		return "error"
+	case info.LowBalance > 0 && info.AvailableBalance < info.LowBalance:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newMoonshotRequest(config *Configuration) *http.Request {
+	host, _ := config.moonshotPlatform()
+	req, _ := http.NewRequest("GET", "https://"+host+"/v1/users/me/balance", nil)
+	req.Header.Set("Authorization", "Bearer "+config.MoonshotApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== NVIDIA NIM / build.nvidia.com (API key) =====
+
+// nvidiaCreditsKey holds the history of the configured credit balance.
+const nvidiaCreditsKey = "nvidia_credits"
+
+// nvidiaMaxSnapshots bounds the stored balance history.
+const nvidiaMaxSnapshots = 30
+
+// NvidiaInfo is the state of an API key on NVIDIA's hosted NIM endpoints and its credits.
+// build.nvidia.com has no credits API, so the balance comes from the configuration; the
+// consumption rate is worked out from how the configured balance has fallen between updates.
+type NvidiaInfo struct {
+	Models         int     `json:"models"`
+	HasCredits     bool    `json:"hasCredits"`
+	CreditsLeft    float64 `json:"creditsLeft"`
+	CreditsTotal   float64 `json:"creditsTotal,omitempty"`
+	CreditsUpdated string  `json:"creditsUpdated,omitempty"` // when the balance was last changed
+	CreditsPerDay  float64 `json:"creditsPerDay,omitempty"`
+	DaysLeft       float64 `json:"daysLeft,omitempty"`
+}
+
+// usagePercent is the share of the credit allotment consumed, if the allotment is known.
+func (i NvidiaInfo) usagePercent() (float64, bool) {
+	if !i.HasCredits || i.CreditsTotal <= 0 {
+		return 0, false
+	}
+	return (i.CreditsTotal - i.CreditsLeft) / i.CreditsTotal * 100, true
+}
+
+func (i NvidiaInfo) summary(locale string) string {
+	if !i.HasCredits {
+		return translate(locale, "summary.models_available", i.Models)
+	}
+	text := translate(locale, "summary.nvidia_credits", formatCount(i.CreditsLeft))
+	if i.CreditsTotal > 0 {
+		text = translate(locale, "summary.credits_remaining", formatCount(i.CreditsLeft), formatCount(i.CreditsTotal))
+	}
+	if i.CreditsPerDay > 0 {
+		text += ", " + translate(locale, "summary.nvidia_rate", formatCount(i.CreditsPerDay), i.DaysLeft)
+	}
+	return text
+}
+
+func (i NvidiaInfo) compact(name, locale string) string {
+	if i.HasCredits {
+		return translate(locale, "compact.credits_left", name, formatCount(i.CreditsLeft))
+	}
+	return name
+}
+
+// nvidiaSnapshot is a configured balance and when it was first seen.
+type nvidiaSnapshot struct {
+	At   string  `json:"at"`
+	Left float64 `json:"left"`
+}
+
+func (p *Plugin) getNvidiaStatus(config *Configuration) ServiceStatus {
+	const id, name = "nvidia", "NVIDIA NIM"
+	if config.NvidiaApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newNvidiaRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var models struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &models); err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	info := NvidiaInfo{Models: len(models.Data)}
+	if left, err := strconv.ParseFloat(strings.TrimSpace(config.NvidiaCreditsLeft), 64); err == nil {
+		info.HasCredits = true
+		info.CreditsLeft = max(left, 0)
+		if total, err := strconv.ParseFloat(strings.TrimSpace(config.NvidiaCreditsTotal), 64); err == nil && total > 0 {
+			info.CreditsTotal = total
+		}
+		p.nvidiaConsumption(&info, time.Now().UTC())
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: nvidiaStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// nvidiaConsumption records the configured balance when it changes and works out the daily
+// rate since the last top-up, i.e. over the trailing run of falling balances.
+func (p *Plugin) nvidiaConsumption(info *NvidiaInfo, now time.Time) {
+	var history []nvidiaSnapshot
+	if data, appErr := p.API.KVGet(p.scopedKey(nvidiaCreditsKey)); appErr == nil && data != nil {
+		json.Unmarshal(data, &history)
+	}
+	if len(history) == 0 || history[len(history)-1].Left != info.CreditsLeft {
+		history = append(history, nvidiaSnapshot{At: now.Format(time.RFC3339), Left: info.CreditsLeft})
+		if len(history) > nvidiaMaxSnapshots {
+			history = history[len(history)-nvidiaMaxSnapshots:]
+		}
+		data, _ := json.Marshal(history)
+		if appErr := p.API.KVSet(p.scopedKey(nvidiaCreditsKey), data); appErr != nil {
+			p.API.LogWarn("Failed to store NVIDIA credit history", "error", appErr.Error())
+		}
+	}
+
+	last := history[len(history)-1]
+	info.CreditsUpdated = last.At
+	first := len(history) - 1
+	for first > 0 && history[first-1].Left > history[first].Left {
+		first--
+	}
+	days := parseTime(last.At).Sub(parseTime(history[first].At)).Hours() / 24
+	if first == len(history)-1 || days <= 0 {
+		return
+	}
+	info.CreditsPerDay = (history[first].Left - last.Left) / days
+	if info.CreditsPerDay > 0 {
+		info.DaysLeft = info.CreditsLeft / info.CreditsPerDay
+	}
+}
+
+// nvidiaStatus is an error once the credits are used up, after which the hosted endpoints
+// refuse requests, and a warning past the warning threshold of the allotment or when the
+// credits would run out within a week at the current rate.
+func nvidiaStatus(info NvidiaInfo, config *Configuration) string {
+	if !info.HasCredits {
+		return "ok"
+	}
+	pct, ok := info.usagePercent()
+	switch {
+	case info.CreditsLeft <= 0:
+		return "error"
+	case ok && pct > config.warningPercent(90):
+		return "warning"
+	case info.DaysLeft > 0 && info.DaysLeft < 7:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newNvidiaRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://integrate.api.nvidia.com/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.NvidiaApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
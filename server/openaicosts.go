@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+)
+
+// ===== OpenAI costs by project and model =====
+
+// openAIModelsShown is how many models the card lists; the rest are summed up. Every
+// project is kept, since budgets can be set per project.
+const openAIModelsShown = 10
+
+// OpenAICostItem is this cycle's cost of one project or model.
+type OpenAICostItem struct {
+	ID      string  `json:"id"` // empty for costs without a project or line item
+	Name    string  `json:"name,omitempty"`
+	Cost    float64 `json:"cost"`
+	Percent float64 `json:"percent"` // of the cycle's cost
+}
+
+// openAIModel returns the model of a cost line item, e.g. "gpt-4o-2024-08-06" for
+// "gpt-4o-2024-08-06, input". Line items that aren't per model, like "web search tool
+// calls", are their own entry.
+func openAIModel(lineItem string) string {
+	model, _, _ := strings.Cut(lineItem, ", ")
+	return strings.TrimSpace(model)
+}
+
+// projectCosts sums the results by project, "" for costs not billed to one.
+func (r openAICostsResponse) projectCosts() map[string]float64 {
+	costs := map[string]float64{}
+	for _, bucket := range r.Data {
+		for _, result := range bucket.Results {
+			id := ""
+			if result.ProjectID != nil {
+				id = *result.ProjectID
+			}
+			costs[id] += float64(result.Amount.Value)
+		}
+	}
+	return costs
+}
+
+// modelCosts sums the results by the model of their line item.
+func (r openAICostsResponse) modelCosts() map[string]float64 {
+	costs := map[string]float64{}
+	for _, bucket := range r.Data {
+		for _, result := range bucket.Results {
+			id := ""
+			if result.LineItem != nil {
+				id = openAIModel(*result.LineItem)
+			}
+			costs[id] += float64(result.Amount.Value)
+		}
+	}
+	return costs
+}
+
+// openAICostItems lists the costs largest first, with their share of the total, up to
+// shown of them (0 for all), and returns how many there are in all.
+func openAICostItems(costs map[string]float64, shown int) ([]OpenAICostItem, int) {
+	var total float64
+	items := make([]OpenAICostItem, 0, len(costs))
+	for id, cost := range costs {
+		if cost == 0 {
+			continue
+		}
+		items = append(items, OpenAICostItem{ID: id, Cost: cost})
+		total += cost
+	}
+	for i := range items {
+		if total > 0 {
+			items[i].Percent = math.Round(items[i].Cost/total*1000) / 10
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Cost != items[j].Cost {
+			return items[i].Cost > items[j].Cost
+		}
+		return items[i].ID < items[j].ID
+	})
+	count := len(items)
+	if shown > 0 && len(items) > shown {
+		items = items[:shown]
+	}
+	return items, count
+}
+
+// parseOpenAIProjectNames parses the OpenAI Project Names, "proj_…=Name" entries
+// separated by commas.
+func parseOpenAIProjectNames(value string) map[string]string {
+	names := map[string]string{}
+	for _, item := range splitList(value) {
+		id, name, ok := strings.Cut(item, "=")
+		if ok && strings.TrimSpace(name) != "" {
+			names[strings.TrimSpace(id)] = strings.TrimSpace(name)
+		}
+	}
+	return names
+}
+
+// openAIProjectName is the name configured for a project, or else the one it has in
+// OpenAI, or "" when it can't be read.
+func (p *Plugin) openAIProjectName(client *http.Client, config *Configuration, org openAIOrg, id string) string {
+	if id == "" {
+		return ""
+	}
+	if name, ok := parseOpenAIProjectNames(config.OpenaiProjectNames)[id]; ok {
+		return name
+	}
+	cacheKey := "openai_project_" + id
+	if cached, ok := p.getCached(cacheKey); ok {
+		return cached.(string)
+	}
+
+	resp, err := client.Do(newOpenAIRequest(config, org, "https://api.openai.com/v1/organization/projects/"+neturl.PathEscape(id)))
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil || resp.StatusCode != 200 {
+		return ""
+	}
+	var project struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(body, &project) != nil {
+		return ""
+	}
+	p.setCache(cacheKey, project.Name)
+	return project.Name
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"time"
+)
+
+// ===== OpenAI usage by API key =====
+
+// openAIKeysShown is how many keys the card lists; the rest are summed up.
+const openAIKeysShown = 10
+
+// OpenAIKeyUsage is the completions usage of one API key this cycle. OpenAI only reports
+// tokens and requests per key, not cost.
+type OpenAIKeyUsage struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name,omitempty"`
+	ProjectID string  `json:"projectId,omitempty"`
+	Tokens    int64   `json:"tokens"`
+	Requests  int64   `json:"requests"`
+	Percent   float64 `json:"percent"`               // of the cycle's tokens
+	Today     int64   `json:"todayTokens,omitempty"` // tokens since midnight UTC, to spot a spike
+}
+
+// fetchOpenAIKeyUsage breaks the completions usage between start and end down by API
+// key, largest first. Like the usage by member it's an extra, so any failure leaves it out.
+func (p *Plugin) fetchOpenAIKeyUsage(client *http.Client, config *Configuration, org openAIOrg, start, end time.Time) ([]OpenAIKeyUsage, int) {
+	results, ok := p.fetchOpenAIUsage(client, config, org, start, end, "api_key_id", "project_id")
+	if !ok {
+		return nil, 0
+	}
+
+	today := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC).Unix()
+	byKey := map[string]*OpenAIKeyUsage{}
+	var total int64
+	for _, r := range results {
+		if r.APIKeyID == nil || *r.APIKeyID == "" {
+			continue
+		}
+		k := byKey[*r.APIKeyID]
+		if k == nil {
+			k = &OpenAIKeyUsage{ID: *r.APIKeyID}
+			if r.ProjectID != nil {
+				k.ProjectID = *r.ProjectID
+			}
+			byKey[*r.APIKeyID] = k
+		}
+		tokens := r.InputTokens + r.OutputTokens
+		k.Tokens += tokens
+		k.Requests += r.NumModelRequests
+		if r.day >= today {
+			k.Today += tokens
+		}
+		total += tokens
+	}
+
+	keys := make([]OpenAIKeyUsage, 0, len(byKey))
+	for _, k := range byKey {
+		if total > 0 {
+			k.Percent = math.Round(float64(k.Tokens)/float64(total)*1000) / 10
+		}
+		keys = append(keys, *k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Tokens != keys[j].Tokens {
+			return keys[i].Tokens > keys[j].Tokens
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	count := len(keys)
+	if len(keys) > openAIKeysShown {
+		keys = keys[:openAIKeysShown]
+	}
+	for i := range keys {
+		keys[i].Name = p.openAIKeyName(client, config, org, keys[i])
+	}
+	return keys, count
+}
+
+// openAIKeyName looks up the name a key was given in its project, or "" when the key
+// can't be read, e.g. because it was deleted.
+func (p *Plugin) openAIKeyName(client *http.Client, config *Configuration, org openAIOrg, key OpenAIKeyUsage) string {
+	if key.ProjectID == "" {
+		return ""
+	}
+	cacheKey := "openai_key_" + key.ID
+	if cached, ok := p.getCached(cacheKey); ok {
+		return cached.(string)
+	}
+
+	url := "https://api.openai.com/v1/organization/projects/" + neturl.PathEscape(key.ProjectID) + "/api_keys/" + neturl.PathEscape(key.ID)
+	resp, err := client.Do(newOpenAIRequest(config, org, url))
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return ""
+	}
+	var k struct {
+		Name string `json:"name"`
+	}
+	switch resp.StatusCode {
+	case 200:
+		if json.Unmarshal(body, &k) != nil {
+			return ""
+		}
+	case 404:
+		// Deleted keys stay in the usage, so remember that they have no name
+	default:
+		return ""
+	}
+	p.setCache(cacheKey, k.Name)
+	return k.Name
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== OpenAI usage by organization member =====
+
+// openAITopUsers is how many members the card and the weekly report name.
+const openAITopUsers = 5
+
+// OpenAIMemberUsage is the completions usage of an organization's members. OpenAI only
+// reports tokens and requests per member, not cost.
+type OpenAIMemberUsage struct {
+	ActiveUsers int   `json:"activeUsers"`
+	Tokens      int64 `json:"tokens"`
+	Requests    int64 `json:"requests"`
+	// Tokens of requests made with service account keys, which belong to no member
+	UnattributedTokens int64 `json:"unattributedTokens,omitempty"`
+	// Share of the members' tokens used by the top members
+	TopShare float64 `json:"topShare"`
+	// The members using the most tokens; only set when members may be named
+	TopUsers []OpenAIUserTokens `json:"topUsers,omitempty"`
+}
+
+// OpenAIUserTokens is one member's usage.
+type OpenAIUserTokens struct {
+	Name     string  `json:"name"`
+	Tokens   int64   `json:"tokens"`
+	Requests int64   `json:"requests"`
+	Percent  float64 `json:"percent"` // of the members' tokens
+}
+
+// openAIUserUsageMode returns "aggregate", "named", or "" when usage by member is off.
+func (c *Configuration) openAIUserUsageMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(c.OpenaiUserUsage)); mode {
+	case "aggregate", "named":
+		return mode
+	}
+	return ""
+}
+
+// openAIUsageResult is a group of /v1/organization/usage/completions: the usage of a
+// member, an API key or a project, depending on the grouping.
+type openAIUsageResult struct {
+	UserID           *string `json:"user_id"`
+	APIKeyID         *string `json:"api_key_id"`
+	ProjectID        *string `json:"project_id"`
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	NumModelRequests int64   `json:"num_model_requests"`
+	day              int64   // start of the daily bucket
+}
+
+// openAIUsageResponse is a page of /v1/organization/usage/completions.
+type openAIUsageResponse struct {
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+	Data     []struct {
+		StartTime int64               `json:"start_time"`
+		Results   []openAIUsageResult `json:"results"`
+	} `json:"data"`
+}
+
+// openAIUsagePages caps the pages read per fetch; a page holds up to 31 daily buckets.
+const openAIUsagePages = 3
+
+// newOpenAIRequest builds an authenticated request to the organization API.
+func newOpenAIRequest(config *Configuration, org openAIOrg, url string) *http.Request {
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Authorization", "Bearer "+config.OpenaiApiKey)
+	if org.ID != "" {
+		req.Header.Set("OpenAI-Organization", org.ID)
+	}
+	return req
+}
+
+// fetchOpenAIUsage reads the completions usage between start and end in daily buckets,
+// grouped by the given fields and filtered to the configured projects.
+func (p *Plugin) fetchOpenAIUsage(client *http.Client, config *Configuration, org openAIOrg, start, end time.Time, groupBy ...string) ([]openAIUsageResult, bool) {
+	var results []openAIUsageResult
+	page := ""
+	for i := 0; i < openAIUsagePages; i++ {
+		url := fmt.Sprintf("https://api.openai.com/v1/organization/usage/completions?start_time=%d&end_time=%d&bucket_width=1d&limit=31", start.Unix(), end.Unix())
+		for _, field := range groupBy {
+			url += "&group_by=" + field
+		}
+		for _, id := range splitList(config.OpenaiProjectIds) {
+			url += "&project_ids=" + neturl.QueryEscape(id)
+		}
+		if page != "" {
+			url += "&page=" + neturl.QueryEscape(page)
+		}
+		resp, err := client.Do(newOpenAIRequest(config, org, url))
+		if err != nil {
+			return nil, false
+		}
+		body, err := p.readResponse(resp)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != 200 {
+			return nil, false
+		}
+		var raw openAIUsageResponse
+		if json.Unmarshal(body, &raw) != nil {
+			return nil, false
+		}
+		for _, bucket := range raw.Data {
+			for _, r := range bucket.Results {
+				r.day = bucket.StartTime
+				results = append(results, r)
+			}
+		}
+		if !raw.HasMore || raw.NextPage == "" {
+			break
+		}
+		page = raw.NextPage
+	}
+	return results, true
+}
+
+// fetchOpenAIMemberUsage reads the completions usage between start and end grouped by
+// member. Usage by member is an extra, so any failure just leaves it out.
+func (p *Plugin) fetchOpenAIMemberUsage(client *http.Client, config *Configuration, org openAIOrg, start, end time.Time) *OpenAIMemberUsage {
+	mode := config.openAIUserUsageMode()
+	if mode == "" {
+		return nil
+	}
+	results, ok := p.fetchOpenAIUsage(client, config, org, start, end, "user_id")
+	if !ok {
+		return nil
+	}
+
+	type totals struct{ tokens, requests int64 }
+	users := map[string]*totals{}
+	usage := &OpenAIMemberUsage{}
+	for _, r := range results {
+		tokens := r.InputTokens + r.OutputTokens
+		usage.Requests += r.NumModelRequests
+		if r.UserID == nil || *r.UserID == "" {
+			usage.UnattributedTokens += tokens
+			continue
+		}
+		t := users[*r.UserID]
+		if t == nil {
+			t = &totals{}
+			users[*r.UserID] = t
+		}
+		t.tokens += tokens
+		t.requests += r.NumModelRequests
+		usage.Tokens += tokens
+	}
+
+	var top []OpenAIUserTokens
+	for id, t := range users {
+		if t.tokens == 0 && t.requests == 0 {
+			continue
+		}
+		usage.ActiveUsers++
+		top = append(top, OpenAIUserTokens{Name: id, Tokens: t.tokens, Requests: t.requests})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Tokens != top[j].Tokens {
+			return top[i].Tokens > top[j].Tokens
+		}
+		return top[i].Name < top[j].Name
+	})
+	if len(top) > openAITopUsers {
+		top = top[:openAITopUsers]
+	}
+	var topTokens int64
+	for i := range top {
+		topTokens += top[i].Tokens
+		if usage.Tokens > 0 {
+			top[i].Percent = math.Round(float64(top[i].Tokens)/float64(usage.Tokens)*1000) / 10
+		}
+	}
+	if usage.Tokens > 0 {
+		usage.TopShare = math.Round(float64(topTokens)/float64(usage.Tokens)*1000) / 10
+	}
+
+	if mode == "named" {
+		names := p.openAIUserNames(client, config, org)
+		for i := range top {
+			if name, ok := names[top[i].Name]; ok {
+				top[i].Name = name
+			}
+		}
+		usage.TopUsers = top
+	}
+	return usage
+}
+
+// openAIUserNames maps the organization's member IDs to their names, or their emails
+// when unnamed.
+func (p *Plugin) openAIUserNames(client *http.Client, config *Configuration, org openAIOrg) map[string]string {
+	key := "openai_users_" + org.ID
+	if cached, ok := p.getCached(key); ok {
+		return cached.(map[string]string)
+	}
+
+	names := map[string]string{}
+	after := ""
+	for i := 0; i < 10; i++ {
+		url := "https://api.openai.com/v1/organization/users?limit=100"
+		if after != "" {
+			url += "&after=" + neturl.QueryEscape(after)
+		}
+		resp, err := client.Do(newOpenAIRequest(config, org, url))
+		if err != nil {
+			return names
+		}
+		body, err := p.readResponse(resp)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != 200 {
+			return names
+		}
+		var page struct {
+			Data []struct {
+				ID    string `json:"id"`
+				Name  string `json:"name"`
+				Email string `json:"email"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if json.Unmarshal(body, &page) != nil {
+			return names
+		}
+		for _, u := range page.Data {
+			if u.Name != "" {
+				names[u.ID] = u.Name
+			} else if u.Email != "" {
+				names[u.ID] = u.Email
+			}
+		}
+		if !page.HasMore || page.LastID == "" {
+			break
+		}
+		after = page.LastID
+	}
+	p.setCache(key, names)
+	return names
+}
+
+// openAITopSpendersText renders the week's usage by member of each OpenAI organization
+// for the weekly cost report, or "" when usage by member is off or unavailable.
+func (p *Plugin) openAITopSpendersText(config *Configuration, locale string, now time.Time) string {
+	if !config.OpenaiEnabled || config.OpenaiApiKey == "" || config.openAIUserUsageMode() == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, org := range p.openAIOrgs(config) {
+		client := p.providerClient(org.statusID(), 15*time.Second)
+		usage := p.fetchOpenAIMemberUsage(client, config, org, now.AddDate(0, 0, -7), now)
+		if usage == nil || usage.ActiveUsers == 0 {
+			continue
+		}
+		b.WriteString("\n\n" + translate(locale, "openai_users.title", org.statusName()) + "\n")
+		b.WriteString(translate(locale, "openai_users.aggregate", usage.ActiveUsers, formatCount(float64(usage.Tokens)), formatCount(float64(usage.Requests))))
+		if len(usage.TopUsers) == 0 {
+			b.WriteString(" " + translate(locale, "openai_users.top_share", min(usage.ActiveUsers, openAITopUsers), usage.TopShare))
+		} else {
+			b.WriteString("\n\n" + translate(locale, "openai_users.table_header") + "\n|:--|--:|--:|--:|\n")
+			for _, u := range usage.TopUsers {
+				b.WriteString(fmt.Sprintf("| %s | %s | %s | %.1f%% |\n", u.Name, formatCount(float64(u.Tokens)), formatCount(float64(u.Requests)), u.Percent))
+			}
+		}
+		if usage.UnattributedTokens > 0 {
+			b.WriteString("\n" + translate(locale, "openai_users.unattributed", formatCount(float64(usage.UnattributedTokens))))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== OpenRouter (API key) =====
+
+// OpenRouterInfo is an OpenRouter account's prepaid credits and the API key's usage: the
+// spend of the key today, this week and this month (UTC), and its own credit limit, which
+// may reset daily, weekly or monthly. Amounts are in USD.
+type OpenRouterInfo struct {
+	Label        string  `json:"label,omitempty"`
+	IsFreeTier   bool    `json:"isFreeTier"`
+	KeyUsage     float64 `json:"keyUsage"` // the key's lifetime spend
+	UsageDaily   float64 `json:"usageDaily"`
+	UsageWeekly  float64 `json:"usageWeekly"`
+	UsageMonthly float64 `json:"usageMonthly"`
+	// The key's credit limit and what's left of it; nil for a key without a limit
+	KeyLimit          *float64 `json:"keyLimit,omitempty"`
+	KeyLimitRemaining *float64 `json:"keyLimitRemaining,omitempty"`
+	LimitReset        string   `json:"limitReset,omitempty"` // "daily", "weekly", "monthly", or "" for never
+	// Requests allowed per interval, e.g. 200 per "10s"; 0 when OpenRouter doesn't say
+	RateLimitRequests int    `json:"rateLimitRequests,omitempty"`
+	RateLimitInterval string `json:"rateLimitInterval,omitempty"`
+	// The account's credits, when the key may read them
+	HasCredits     bool    `json:"hasCredits"`
+	TotalCredits   float64 `json:"totalCredits"`
+	TotalUsage     float64 `json:"totalUsage"`
+	Balance        float64 `json:"balance"`
+	LowBalance     float64 `json:"lowBalance,omitempty"`
+	Currency       string  `json:"currency"`
+	Period         string  `json:"period"`
+	CycleEnd       string  `json:"cycleEnd"`
+	DaysUntilReset int     `json:"daysUntilReset"`
+}
+
+// keyLimitUsed is the key's spend against its limit, in the current reset period.
+func (o OpenRouterInfo) keyLimitUsed() (used, limit float64, ok bool) {
+	if o.KeyLimit == nil || *o.KeyLimit <= 0 || o.KeyLimitRemaining == nil {
+		return 0, 0, false
+	}
+	return max(*o.KeyLimit-*o.KeyLimitRemaining, 0), *o.KeyLimit, true
+}
+
+// limitResetAt is when the key's limit resets next: the next UTC midnight, Monday or
+// first of the month. It's the zero time for a limit that never resets.
+func (o OpenRouterInfo) limitResetAt(now time.Time) time.Time {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch o.LimitReset {
+	case "daily":
+		return today.AddDate(0, 0, 1)
+	case "weekly":
+		return today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+	case "monthly":
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Time{}
+}
+
+func (o OpenRouterInfo) summary(locale string) string {
+	var parts []string
+	if o.HasCredits {
+		parts = append(parts, translate(locale, "summary.credit_balance", formatMoney(o.Balance, o.Currency, 2)))
+	}
+	if used, limit, ok := o.keyLimitUsed(); ok {
+		parts = append(parts, translate(locale, "summary.openrouter_key_limit", formatMoney(used, o.Currency, 2), formatMoney(limit, o.Currency, 0)))
+	} else {
+		parts = append(parts, translate(locale, "summary.spent", formatMoney(o.UsageMonthly, o.Currency, 2), o.Period))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (o OpenRouterInfo) compact(name, locale string) string {
+	if used, limit, ok := o.keyLimitUsed(); ok {
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(used, o.Currency, 0), formatMoney(limit, o.Currency, 0))
+	}
+	if o.HasCredits {
+		return translate(locale, "compact.balance_left", name, formatMoney(o.Balance, o.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(o.UsageMonthly, o.Currency, 0))
+}
+
+func (o OpenRouterInfo) usagePercent() (float64, bool) {
+	if used, limit, ok := o.keyLimitUsed(); ok {
+		return used / limit * 100, true
+	}
+	return 0, false
+}
+
+func (o OpenRouterInfo) resets(now time.Time) []ResetInfo {
+	// A daily or weekly key limit resets before the month does
+	if _, _, ok := o.keyLimitUsed(); ok && o.LimitReset != "monthly" {
+		return []ResetInfo{{Kind: "budget_reset", At: o.limitResetAt(now)}}
+	}
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(o.CycleEnd)}}
+}
+
+// openRouterKeyResponse is GET /api/v1/auth/key.
+type openRouterKeyResponse struct {
+	Data struct {
+		Label              string     `json:"label"`
+		Usage              flexFloat  `json:"usage" schema:"required"`
+		UsageDaily         flexFloat  `json:"usage_daily"`
+		UsageWeekly        flexFloat  `json:"usage_weekly"`
+		UsageMonthly       flexFloat  `json:"usage_monthly"`
+		Limit              *flexFloat `json:"limit"`
+		LimitRemaining     *flexFloat `json:"limit_remaining"`
+		LimitReset         *string    `json:"limit_reset"`
+		IsFreeTier         bool       `json:"is_free_tier"`
+		IsProvisioningKey  bool       `json:"is_provisioning_key"`
+		IncludeByokInLimit bool       `json:"include_byok_in_limit"`
+		RateLimit          *struct {
+			Requests int    `json:"requests"`
+			Interval string `json:"interval"`
+		} `json:"rate_limit"`
+	} `json:"data" schema:"required"`
+}
+
+// openRouterCreditsResponse is GET /api/v1/credits.
+type openRouterCreditsResponse struct {
+	Data struct {
+		TotalCredits flexFloat `json:"total_credits" schema:"required"`
+		TotalUsage   flexFloat `json:"total_usage" schema:"required"`
+	} `json:"data" schema:"required"`
+}
+
+// openRouterProvider reads the key's usage and the account's credits with an API key.
+type openRouterProvider struct{}
+
+func (openRouterProvider) ID() string   { return "openrouter" }
+func (openRouterProvider) Name() string { return "OpenRouter" }
+
+func (openRouterProvider) Enabled(config *Configuration) bool {
+	return config.OpenrouterEnabled
+}
+
+func (openRouterProvider) EvaluateStatus(data interface{}, config *Configuration) string {
+	return openRouterStatus(data.(OpenRouterInfo), config)
+}
+
+func (o openRouterProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	if config.OpenrouterApiKey == "" {
+		return nil, errFetch("error.api_key_missing")
+	}
+
+	var key openRouterKeyResponse
+	client := p.providerClient(o.ID(), 15*time.Second)
+	if err := p.getJSON(o.ID(), client, newOpenRouterKeyRequest(config), &key); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	k := key.Data
+	info := OpenRouterInfo{
+		Label:          k.Label,
+		IsFreeTier:     k.IsFreeTier,
+		KeyUsage:       float64(k.Usage),
+		UsageDaily:     float64(k.UsageDaily),
+		UsageWeekly:    float64(k.UsageWeekly),
+		UsageMonthly:   float64(k.UsageMonthly),
+		Currency:       "USD",
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+	if k.Limit != nil {
+		info.KeyLimit = floatPtr(float64(*k.Limit))
+		if k.LimitRemaining != nil {
+			info.KeyLimitRemaining = floatPtr(float64(*k.LimitRemaining))
+		}
+		if k.LimitReset != nil {
+			info.LimitReset = *k.LimitReset
+		}
+	}
+	// A negative request count means no limit
+	if k.RateLimit != nil && k.RateLimit.Requests > 0 {
+		info.RateLimitRequests, info.RateLimitInterval = k.RateLimit.Requests, k.RateLimit.Interval
+	}
+
+	// The credits are only decoration when the key can't read them
+	var credits openRouterCreditsResponse
+	if err := p.getJSON(o.ID(), client, newOpenRouterCreditsRequest(config), &credits); err == nil {
+		info.HasCredits = true
+		info.TotalCredits = float64(credits.Data.TotalCredits)
+		info.TotalUsage = float64(credits.Data.TotalUsage)
+		info.Balance = info.TotalCredits - info.TotalUsage
+	}
+	info.LowBalance = p.budgetIn(config, o.ID(), config.OpenrouterLowBalance, info.Currency)
+	return info, nil
+}
+
+// openRouterStatus is an error once the credits or the key's limit are used up, when
+// OpenRouter rejects paid requests, and a warning below the low balance or above the
+// warning threshold of the key's limit.
+func openRouterStatus(info OpenRouterInfo, config *Configuration) string {
+	used, limit, hasLimit := info.keyLimitUsed()
+	switch {
+	case info.HasCredits && info.Balance <= 0:
+		return "error"
+	case hasLimit && used >= limit:
+		return "error"
+	case info.HasCredits && info.LowBalance > 0 && info.Balance < info.LowBalance:
+		return "warning"
+	case hasLimit && used/limit*100 > config.warningPercent(80):
+		return "warning"
+	}
+	return "ok"
+}
+
+func newOpenRouterKeyRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://openrouter.ai/api/v1/auth/key", nil)
+	setOpenRouterHeaders(req, config)
+	return req
+}
+
+func newOpenRouterCreditsRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://openrouter.ai/api/v1/credits", nil)
+	setOpenRouterHeaders(req, config)
+	return req
+}
+
+func setOpenRouterHeaders(req *http.Request, config *Configuration) {
+	req.Header.Set("Authorization", "Bearer "+config.OpenrouterApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+}
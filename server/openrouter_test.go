@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestOpenRouterKeyAndCredits(t *testing.T) {
+	config := &Configuration{OpenrouterEnabled: true, OpenrouterApiKey: "sk-or-test"}
+	p := newFixturePlugin(t, config, map[string]fixture{
+		"https://openrouter.ai/api/v1/auth/key": {200, "openrouter/key.json"},
+		"https://openrouter.ai/api/v1/credits":  {200, "openrouter/credits.json"},
+	})
+
+	s := p.fetchFromProvider(openRouterProvider{}, config)
+	if s.Error != "" {
+		t.Fatalf("error %q", s.Error)
+	}
+	info := s.Data.(OpenRouterInfo)
+	if info.KeyUsage != 31.25 || info.UsageDaily != 1.5 || info.UsageWeekly != 6.75 || info.UsageMonthly != 18.4 {
+		t.Errorf("usage = %v, %v, %v, %v", info.KeyUsage, info.UsageDaily, info.UsageWeekly, info.UsageMonthly)
+	}
+	if info.KeyLimit == nil || *info.KeyLimit != 50 || info.KeyLimitRemaining == nil || *info.KeyLimitRemaining != 5 || info.LimitReset != "monthly" {
+		t.Errorf("key limit = %v, %v, %q; want 50, 5, monthly", info.KeyLimit, info.KeyLimitRemaining, info.LimitReset)
+	}
+	// A negative request count means no rate limit
+	if info.RateLimitRequests != 0 {
+		t.Errorf("rate limit = %d per %q, want none", info.RateLimitRequests, info.RateLimitInterval)
+	}
+	if !info.HasCredits || info.Balance != 68.75 {
+		t.Errorf("credits = %v, balance %v; want 68.75", info.HasCredits, info.Balance)
+	}
+	// 45 of the key's 50 are used, above the default warning threshold
+	if s.Status != "warning" {
+		t.Errorf("status = %q, want warning", s.Status)
+	}
+}
+
+func TestOpenRouterWithoutCredits(t *testing.T) {
+	config := &Configuration{OpenrouterEnabled: true, OpenrouterApiKey: "sk-or-test"}
+	p := newFixturePlugin(t, config, map[string]fixture{
+		"https://openrouter.ai/api/v1/auth/key": {200, "openrouter/key.json"},
+		"https://openrouter.ai/api/v1/credits":  {403, "openrouter/credits.json"},
+	})
+
+	s := p.fetchFromProvider(openRouterProvider{}, config)
+	if s.Error != "" || s.Data.(OpenRouterInfo).HasCredits {
+		t.Errorf("error %q, data %+v; want the key's usage without credits", s.Error, s.Data)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===== Outbound proxy and TLS =====
+
+// outboundSettings are the settings the shared outbound transport is built from.
+type outboundSettings struct {
+	proxy      string
+	ca         string
+	skipVerify bool
+}
+
+// The transport is built once per distinct settings and shared by every client, so
+// connections are pooled across providers, instances and personal credentials.
+var (
+	outboundLock      sync.Mutex
+	outboundBuiltFrom outboundSettings
+	outboundBuilt     http.RoundTripper
+	outboundErr       error
+)
+
+func (c *Configuration) outboundSettings() outboundSettings {
+	return outboundSettings{
+		proxy:      strings.TrimSpace(c.OutboundProxyUrl),
+		ca:         strings.TrimSpace(c.OutboundCaCertificates),
+		skipVerify: c.OutboundSkipTlsVerify,
+	}
+}
+
+// buildOutboundTransport returns a transport for the settings: the default one, which
+// also honours HTTPS_PROXY and NO_PROXY, when none are set.
+func buildOutboundTransport(s outboundSettings) (http.RoundTripper, error) {
+	if s == (outboundSettings{}) {
+		return http.DefaultTransport, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if s.proxy != "" {
+		proxyURL, err := neturl.Parse(s.proxy)
+		if err != nil || proxyURL.Host == "" || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5") {
+			return nil, fmt.Errorf("outbound proxy %q is not an http, https or socks5 URL", s.proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.ca != "" {
+		pem := s.ca
+		if strings.HasPrefix(pem, "env:") || strings.HasPrefix(pem, "file:") {
+			resolved, err := resolveSecret(pem)
+			if err != nil {
+				return nil, fmt.Errorf("CA certificates: %v", err)
+			}
+			pem = resolved
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(pem)) {
+			return nil, fmt.Errorf("CA certificates: no PEM certificates found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	tlsConfig.InsecureSkipVerify = s.skipVerify
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// outboundTransport returns the shared transport for the configured proxy and TLS
+// settings. Invalid settings fail every request with the reason, rather than quietly
+// going around the proxy.
+func (c *Configuration) outboundTransport() http.RoundTripper {
+	s := c.outboundSettings()
+	outboundLock.Lock()
+	defer outboundLock.Unlock()
+	if outboundBuilt == nil && outboundErr == nil || s != outboundBuiltFrom {
+		outboundBuiltFrom = s
+		outboundBuilt, outboundErr = buildOutboundTransport(s)
+	}
+	if outboundErr != nil {
+		return failingTransport{outboundErr}
+	}
+	return outboundBuilt
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// outboundClient returns a client for integrations and other requests that aren't a
+// provider's, through the configured proxy.
+func (p *Plugin) outboundClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: p.getConfiguration().outboundTransport()}
+}
+
+// checkOutbound logs invalid outbound settings, and a warning while certificate checks
+// are off.
+func (p *Plugin) checkOutbound(config *Configuration) {
+	s := config.outboundSettings()
+	if _, err := buildOutboundTransport(s); err != nil {
+		p.API.LogError("Invalid outbound proxy settings, provider requests will fail", "error", err.Error())
+	}
+	if s.skipVerify {
+		p.API.LogWarn("TLS certificate verification is off for outbound requests; anyone on the network path can read and change them")
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Overall status =====
+
+// providerWeight is how much a provider counts towards the overall status.
+type providerWeight struct {
+	Weight   float64
+	Critical bool
+}
+
+// parseProviderWeights parses the Overall Status Weights setting: one
+// "<provider or card ID>=<weight>" per line, where the weight is a number or "critical".
+func parseProviderWeights(raw string) map[string]providerWeight {
+	weights := map[string]providerWeight{}
+	for _, line := range strings.Split(raw, "\n") {
+		id, value, ok := strings.Cut(line, "=")
+		id, value = strings.TrimSpace(id), strings.TrimSpace(value)
+		if !ok || id == "" {
+			continue
+		}
+		if strings.EqualFold(value, "critical") {
+			weights[id] = providerWeight{Weight: 1, Critical: true}
+		} else if w, err := strconv.ParseFloat(value, 64); err == nil && w >= 0 {
+			weights[id] = providerWeight{Weight: w}
+		}
+	}
+	return weights
+}
+
+// weightOf returns a card's weight; a provider ID covers all of its cards, and cards
+// without one weigh 1.
+func weightOf(weights map[string]providerWeight, id string) providerWeight {
+	if w, ok := weights[id]; ok {
+		return w
+	}
+	if w, ok := weights[strings.Split(id, ":")[0]]; ok {
+		return w
+	}
+	return providerWeight{Weight: 1}
+}
+
+// OverallStatus sums up all providers for the bot status and badges.
+type OverallStatus struct {
+	Status         string   `json:"status"`         // ok, warning, error, or none without weighted providers
+	ErrorPercent   float64  `json:"errorPercent"`   // share of the weight in error
+	WarningPercent float64  `json:"warningPercent"` // share of the weight in warning or error
+	Causes         []string `json:"causes"`         // providers that made the status worse than ok, heaviest first
+}
+
+// overallStatus weighs the enabled providers' statuses. It is an error when a critical
+// provider fails or the share of the weight in error reaches the error threshold, and a
+// warning likewise. With the default weights and thresholds that is the worst status.
+func overallStatus(services []ServiceStatus, config *Configuration) OverallStatus {
+	weights := parseProviderWeights(config.OverallStatusWeights)
+	errorAt := parsePercent(config.OverallErrorPercent, 0)
+	warningAt := parsePercent(config.OverallWarningPercent, 0)
+
+	type cause struct {
+		id     string
+		weight float64
+	}
+	var total, failing, warning float64
+	var criticalError, criticalWarning bool
+	var causes []cause
+	for _, s := range services {
+		w := weightOf(weights, s.ID)
+		if !s.Enabled || w.Weight == 0 {
+			continue
+		}
+		total += w.Weight
+		switch sev := statusSeverity(s.Status); {
+		case sev >= statusSeverity("error"):
+			failing += w.Weight
+			warning += w.Weight
+			criticalError = criticalError || w.Critical
+		case sev == statusSeverity("warning"):
+			warning += w.Weight
+			criticalWarning = criticalWarning || w.Critical
+		default:
+			continue
+		}
+		causes = append(causes, cause{s.ID, w.Weight})
+	}
+
+	overall := OverallStatus{Status: "none", Causes: []string{}}
+	if total == 0 {
+		return overall
+	}
+	overall.ErrorPercent = math.Round(1000*failing/total) / 10
+	overall.WarningPercent = math.Round(1000*warning/total) / 10
+	switch {
+	case criticalError || failing > 0 && overall.ErrorPercent >= errorAt:
+		overall.Status = "error"
+	case criticalWarning || warning > 0 && overall.WarningPercent >= warningAt:
+		overall.Status = "warning"
+	default:
+		overall.Status = "ok"
+	}
+	sort.SliceStable(causes, func(i, j int) bool { return causes[i].weight > causes[j].weight })
+	for _, c := range causes {
+		overall.Causes = append(overall.Causes, c.id)
+	}
+	return overall
+}
+
+// SummaryResponse is the response for GET /api/v1/summary.
+type SummaryResponse struct {
+	OverallStatus
+	Providers map[string]string `json:"providers"` // card ID → status
+	Runway    *Runway           `json:"runway,omitempty"`
+	Cards     []SummaryCard     `json:"cards"`
+}
+
+// sparklineHours is how far back the summary's sparklines go, one point per hour.
+const sparklineHours = 24
+
+// SummaryCard is a card's line in GET /api/v1/summary, for a channel header icon or a
+// small widget that doesn't need the full status.
+type SummaryCard struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Summary string   `json:"summary"` // one line, in the user's language
+	Percent *float64 `json:"percent,omitempty"`
+	// Percent of the limit at the end of each of the last hours, oldest first and the
+	// current hour last; null for hours nothing was recorded
+	Sparkline []*float64 `json:"sparkline,omitempty"`
+}
+
+// sparklines returns each card's percent of its limit per hour over the last
+// sparklineHours, from the hourly usage history.
+func (p *Plugin) sparklines(now time.Time) map[string][]*float64 {
+	current := now.UTC().Truncate(time.Hour)
+	first := current.Add(-(sparklineHours - 1) * time.Hour)
+	result := map[string][]*float64{}
+	for _, series := range p.usageHistory("", "hourly", first, now) {
+		line := make([]*float64, sparklineHours)
+		recorded := false
+		for _, point := range series.Points {
+			slot := int(time.Unix(point.Time, 0).Sub(first) / time.Hour)
+			if slot >= 0 && slot < sparklineHours && point.Percent != nil {
+				line[slot] = floatPtr(math.Round(*point.Percent*10) / 10)
+				recorded = true
+			}
+		}
+		if recorded {
+			result[series.Provider] = line
+		}
+	}
+	return result
+}
+
+// handleGetSummary returns the overall status, with an ETag and the same caching as the
+// compact status, for badges.
+func (p *Plugin) handleGetSummary(w http.ResponseWriter, r *http.Request) {
+	services := p.collectStatuses()
+	p.trackStatusChanges(services)
+
+	config := p.getConfiguration()
+	now := time.Now()
+	resp := SummaryResponse{
+		OverallStatus: overallStatus(services, config),
+		Providers:     map[string]string{},
+		Runway:        computeRunway(services, config, now),
+		Cards:         []SummaryCard{},
+	}
+	locale := p.getUserContext(r.Header.Get("Mattermost-User-Id")).Locale
+	localizeStatuses(services, locale)
+	var lines map[string][]*float64
+	if !config.DemoMode {
+		lines = p.sparklines(now)
+	}
+	for _, s := range services {
+		if !s.Enabled {
+			continue
+		}
+		resp.Providers[s.ID] = s.Status
+		card := SummaryCard{ID: s.ID, Name: s.Name, Status: s.Status, Summary: summarizeService(s, locale), Sparkline: lines[s.ID]}
+		if pct, ok := usagePercent(s); ok && (s.Error == "" || s.Stale) {
+			card.Percent = floatPtr(math.Round(pct*10) / 10)
+			// The current hour's point may not be recorded yet
+			if n := len(card.Sparkline); n > 0 && card.Sparkline[n-1] == nil {
+				card.Sparkline[n-1] = card.Percent
+			}
+		}
+		resp.Cards = append(resp.Cards, card)
+	}
+
+	body, _ := json.Marshal(resp)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(compactMaxAge))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Perplexity (API key) =====
+
+// perplexityTierThresholds is the cumulative credit purchased (USD) needed to reach each usage tier.
+var perplexityTierThresholds = []float64{0, 50, 250, 500, 1000, 5000}
+
+// PerplexityInfo is the account's usage tier and credit balance. Perplexity has no billing
+// API, so both come from the configuration; the API key itself is verified on every refresh.
+type PerplexityInfo struct {
+	Tier          int     `json:"tier"`
+	HasTier       bool    `json:"hasTier"`
+	NextTierSpend float64 `json:"nextTierSpend,omitempty"` // cumulative purchases needed for the next tier
+	CreditBalance float64 `json:"creditBalance"`
+	HasBalance    bool    `json:"hasBalance"`
+	LowBalance    float64 `json:"lowBalance,omitempty"`
+	Currency      string  `json:"currency"`
+}
+
+func (p PerplexityInfo) summary(locale string) string {
+	var parts []string
+	if p.HasTier {
+		parts = append(parts, translate(locale, "summary.perplexity_tier", p.Tier))
+	}
+	if p.HasBalance {
+		parts = append(parts, translate(locale, "summary.credit_balance", formatMoney(p.CreditBalance, p.Currency, 2)))
+	}
+	if len(parts) == 0 {
+		return translate(locale, "summary.perplexity_connected")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (p PerplexityInfo) compact(name, locale string) string {
+	if p.HasBalance {
+		return translate(locale, "compact.balance_left", name, formatMoney(p.CreditBalance, p.Currency, 0))
+	}
+	return name
+}
+
+// perplexityTier is the configured usage tier (0–5), or false when unset.
+func (c *Configuration) perplexityTier() (int, bool) {
+	tier, err := strconv.Atoi(strings.TrimSpace(c.PerplexityUsageTier))
+	if err != nil || tier < 0 || tier >= len(perplexityTierThresholds) {
+		return 0, false
+	}
+	return tier, true
+}
+
+func (p *Plugin) getPerplexityStatus(config *Configuration) ServiceStatus {
+	const id, name = "perplexity", "Perplexity"
+	if config.PerplexityApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	// Listing async requests is free and fails for revoked or invalid keys
+	client := p.providerClient(id, 10*time.Second)
+	resp, err := client.Do(newPerplexityRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	info := PerplexityInfo{Currency: baseCurrency}
+	if tier, ok := config.perplexityTier(); ok {
+		info.Tier, info.HasTier = tier, true
+		if tier+1 < len(perplexityTierThresholds) {
+			info.NextTierSpend = perplexityTierThresholds[tier+1]
+		}
+	}
+	if strings.TrimSpace(config.PerplexityCreditBalance) != "" {
+		info.CreditBalance = p.budgetIn(config, id, config.PerplexityCreditBalance, info.Currency)
+		info.HasBalance = true
+		info.LowBalance = p.budgetIn(config, id, config.PerplexityLowBalance, info.Currency)
+	}
+
+	status := "ok"
+	if info.HasBalance && info.LowBalance > 0 && info.CreditBalance < info.LowBalance {
+		status = "warning"
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: status,
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+func newPerplexityRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.perplexity.ai/async/chat/completions", nil)
+	req.Header.Set("Authorization", "Bearer "+config.PerplexityApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
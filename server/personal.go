@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ===== Personal credentials (per user) =====
+
+const personalCredentialsKeyPrefix = "personal_credentials_"
+
+// personalTypesExcluded can't use personal credentials: Codex saves renewed tokens to the
+// System Console fields, and Claude Code usage is pushed by each developer anyway.
+var personalTypesExcluded = map[string]bool{"codex": true, "claudecode": true}
+
+// personalCredentials are a user's own credentials per provider, with the provider's
+// System Console keys, e.g. {"claude": {"claudeaccesstoken": "...", ...}}.
+type personalCredentials map[string]map[string]string
+
+// personalPlugin fetches one provider with one user's credentials, with its own cache.
+type personalPlugin struct {
+	hash   string // of the credentials it was built with
+	plugin *Plugin
+}
+
+func personalCredentialsKey(userID string) string {
+	return personalCredentialsKeyPrefix + userID
+}
+
+func (p *Plugin) getPersonalCredentials(userID string) personalCredentials {
+	creds := personalCredentials{}
+	if data, appErr := p.API.KVGet(personalCredentialsKey(userID)); appErr == nil && data != nil {
+		json.Unmarshal(data, &creds)
+	}
+	return creds
+}
+
+func (p *Plugin) savePersonalCredentials(userID string, creds personalCredentials) error {
+	if len(creds) == 0 {
+		if appErr := p.API.KVDelete(personalCredentialsKey(userID)); appErr != nil {
+			return appErr
+		}
+		return nil
+	}
+	data, _ := json.Marshal(creds)
+	if appErr := p.API.KVSet(personalCredentialsKey(userID), data); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// personalSettings validates credentials given for a provider and returns them under the
+// provider's System Console keys. Only credential fields can be set, and only as values:
+// env: and file: references would read the server's secrets.
+func personalSettings(typ string, settings map[string]string) (map[string]string, error) {
+	step, ok := findSetupStep(typ)
+	if !ok || step.EnabledKey == "" || personalTypesExcluded[typ] {
+		return nil, fmt.Errorf("unsupported provider %q", typ)
+	}
+	prefix := strings.TrimSuffix(step.EnabledKey, "enabled")
+	known := configurationKeys()
+	result := map[string]string{}
+	for key, value := range settings {
+		key = strings.ToLower(key)
+		if !strings.HasPrefix(key, prefix) {
+			key = prefix + key
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case !known[key] || !isCredentialSetting(key):
+			return nil, fmt.Errorf("%q is not a credential setting of %s", key, typ)
+		case strings.HasPrefix(value, "env:") || strings.HasPrefix(value, "file:"):
+			return nil, fmt.Errorf("%q must be the credential itself", key)
+		case value != "":
+			result[key] = value
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no credentials given")
+	}
+	return result, nil
+}
+
+// personalConfiguration is the System Console configuration with the provider enabled and
+// its credentials replaced by the user's. Credentials the user didn't give are cleared
+// rather than inherited, so the user's account is never mixed with the organization's.
+func personalConfiguration(base *Configuration, typ string, settings map[string]string) (*Configuration, error) {
+	step, _ := findSetupStep(typ)
+	prefix := strings.TrimSuffix(step.EnabledKey, "enabled")
+	overlay := map[string]any{step.EnabledKey: true}
+	for key := range configurationKeys() {
+		if strings.HasPrefix(key, prefix) && isCredentialSetting(key) {
+			overlay[key] = ""
+		}
+	}
+	for key, value := range settings {
+		overlay[key] = value
+	}
+
+	config := *base
+	config.ProviderInstances = ""
+	data, _ := json.Marshal(overlay)
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid settings: %v", err)
+	}
+	return &config, nil
+}
+
+// newPersonalPlugin returns a plugin that fetches with a user's configuration. Its history
+// is stored under the user's own KV keys, see scopedKey.
+func (p *Plugin) newPersonalPlugin(userID string, config *Configuration) *Plugin {
+	return &Plugin{
+		MattermostPlugin: p.MattermostPlugin,
+		configuration:    config,
+		cache:            make(map[string]*CacheEntry),
+		botUserID:        p.botUserID,
+		bg:               p.bg,
+		defaultUA:        p.defaultUA,
+		latency:          p.latency,
+		fetchLog:         p.fetchLog,
+		tokenScope:       userID,
+	}
+}
+
+// personalPluginFor returns the plugin fetching the provider with the user's credentials,
+// reusing it, and its cache, while the credentials stay the same.
+func (p *Plugin) personalPluginFor(userID, typ string, settings map[string]string) (*Plugin, error) {
+	data, _ := json.Marshal(settings)
+	hash := credentialHash(string(data))
+	key := userID + "/" + typ
+
+	p.personalLock.Lock()
+	defer p.personalLock.Unlock()
+	if pp, ok := p.personal[key]; ok && pp.hash == hash {
+		return pp.plugin, nil
+	}
+	config, err := personalConfiguration(p.getConfiguration(), typ, settings)
+	if err != nil {
+		return nil, err
+	}
+	if p.personal == nil {
+		p.personal = map[string]*personalPlugin{}
+	}
+	pp := &personalPlugin{hash: hash, plugin: p.newPersonalPlugin(userID, config)}
+	p.personal[key] = pp
+	return pp.plugin, nil
+}
+
+// clearPersonalPlugins drops every user's plugin, to rebuild them from a new configuration.
+func (p *Plugin) clearPersonalPlugins() {
+	p.personalLock.Lock()
+	p.personal = nil
+	p.personalLock.Unlock()
+}
+
+// dropPersonalPlugin drops the user's plugin for a provider whose credentials changed.
+func (p *Plugin) dropPersonalPlugin(userID, typ string) {
+	p.personalLock.Lock()
+	delete(p.personal, userID+"/"+typ)
+	p.personalLock.Unlock()
+}
+
+// fetchPersonal fetches the provider with the user's credentials, marking the statuses
+// as the user's own.
+func (p *Plugin) fetchPersonal(prov provider) []ServiceStatus {
+	statuses := p.fetchProvider(prov, p.getConfiguration())
+	for i := range statuses {
+		statuses[i].Personal = true
+	}
+	return statuses
+}
+
+// withPersonalStatuses replaces the cards of the providers the user has credentials for
+// with the user's own. Provider instances keep their cards.
+func (p *Plugin) withPersonalStatuses(services []ServiceStatus, userID string) []ServiceStatus {
+	config := p.getConfiguration()
+	if !config.PersonalCredentialsEnabled || config.DemoMode {
+		return services
+	}
+	creds := p.getPersonalCredentials(userID)
+	if len(creds) == 0 {
+		return services
+	}
+
+	personal := map[string][]ServiceStatus{}
+	for typ, settings := range creds {
+		prov, ok := findProvider(typ)
+		if !ok || personalTypesExcluded[typ] {
+			continue
+		}
+		pp, err := p.personalPluginFor(userID, typ, settings)
+		if err != nil {
+			s := errorStatus(typ, prov.Name, "error.personal_credentials_invalid", err.Error())
+			s.Personal = true
+			personal[typ] = []ServiceStatus{s}
+			continue
+		}
+		personal[typ] = pp.fetchPersonal(prov)
+	}
+
+	instances := p.instanceStatusPrefixes()
+	var result []ServiceStatus
+	for _, s := range services {
+		typ := cacheKeyProvider(s.ID)
+		own, ok := personal[typ]
+		if !ok || isInstanceStatus(s.ID, instances) {
+			result = append(result, s)
+			continue
+		}
+		// The user's cards take the place of the provider's first card
+		if own != nil {
+			result = append(result, own...)
+			personal[typ] = nil
+		}
+	}
+	types := make([]string, 0, len(personal))
+	for typ, own := range personal {
+		if own != nil {
+			types = append(types, typ)
+		}
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		result = append(result, personal[typ]...)
+	}
+	return result
+}
+
+// instanceStatusPrefixes returns the card ID prefixes of the provider instances.
+func (p *Plugin) instanceStatusPrefixes() []string {
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+	var prefixes []string
+	for _, inst := range p.instances {
+		prefixes = append(prefixes, inst.Type+":"+inst.ID)
+	}
+	return prefixes
+}
+
+func isInstanceStatus(id string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if id == prefix || strings.HasPrefix(id, prefix+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePersonalCredentials lists, sets and removes the calling user's own credentials.
+// Values are never returned, only which settings are set.
+func (p *Plugin) handlePersonalCredentials(w http.ResponseWriter, r *http.Request, userID string) {
+	if !p.getConfiguration().PersonalCredentialsEnabled {
+		http.Error(w, `{"error": "disabled", "message": "Personal credentials are not enabled"}`, http.StatusForbidden)
+		return
+	}
+	creds := p.getPersonalCredentials(userID)
+
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			Provider string            `json:"provider"`
+			Settings map[string]string `json:"settings"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&body); err != nil {
+			http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+			return
+		}
+		typ := strings.ToLower(strings.TrimSpace(body.Provider))
+		settings, err := personalSettings(typ, body.Settings)
+		if err != nil {
+			personalError(w, "invalid_credentials", err.Error(), http.StatusBadRequest)
+			return
+		}
+		prov, _ := findProvider(typ)
+
+		// Check the credentials before saving them, with a plugin of their own
+		config, err := personalConfiguration(p.getConfiguration(), typ, settings)
+		if err != nil {
+			personalError(w, "invalid_credentials", err.Error(), http.StatusBadRequest)
+			return
+		}
+		statuses := p.newPersonalPlugin(userID, config).fetchPersonal(prov)
+		locale := p.getUserContext(userID).Locale
+		localizeStatuses(statuses, locale)
+		for _, s := range statuses {
+			if s.Error != "" {
+				personalError(w, "check_failed", s.Error, http.StatusBadRequest)
+				return
+			}
+		}
+
+		creds[typ] = settings
+		if err := p.savePersonalCredentials(userID, creds); err != nil {
+			personalError(w, "store_failed", err.Error(), http.StatusInternalServerError)
+			return
+		}
+		p.dropPersonalPlugin(userID, typ)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"provider": typ, "services": statuses})
+	case http.MethodDelete:
+		typ := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("provider")))
+		if _, ok := creds[typ]; !ok {
+			personalError(w, "not_found", "No personal credentials for this provider", http.StatusNotFound)
+			return
+		}
+		delete(creds, typ)
+		if err := p.savePersonalCredentials(userID, creds); err != nil {
+			personalError(w, "store_failed", err.Error(), http.StatusInternalServerError)
+			return
+		}
+		p.dropPersonalPlugin(userID, typ)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		list := map[string][]string{}
+		for typ, settings := range creds {
+			keys := make([]string, 0, len(settings))
+			for key := range settings {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			list[typ] = keys
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"providers": list})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func personalError(w http.ResponseWriter, code, message string, status int) {
+	msg, _ := json.Marshal(map[string]string{"error": code, "message": message})
+	http.Error(w, string(msg), status)
+}
@@ -2,13 +2,19 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
 )
 
@@ -22,13 +28,99 @@ type Plugin struct {
 	// Cache
 	cacheLock sync.RWMutex
 	cache     map[string]*CacheEntry
+	cacheTTL  time.Duration // zero for the default
 
+	// Failed providers and when to retry them
+	backoffLock sync.Mutex
+	backoff     map[string]*providerBackoff
+
+	// Last successful status per card, served while its provider fails
+	lastGoodLock sync.Mutex
+	lastGood     map[string]ServiceStatus
+
+	// Serializes renewals of the claude.ai token, since each one spends the refresh token
+	claudeTokenLock sync.Mutex
+
+	// Credential expiry announced by providers in their responses
+	expiryLock sync.Mutex
+	expiry     map[string]time.Time
+
+	// Last observed status per provider, used to detect transitions
+	stateLock sync.Mutex
+	states    map[string]*providerState
+
+	botUserID string
+	// User-Agent sent to providers unless one is configured
+	defaultUA string
+	// Last custom status set on the bot, to skip redundant updates
+	botStatus string
+
+	// Recent request outcomes per provider API
+	healthLock sync.Mutex
+	health     map[string]*providerHealth
+	// Request latencies not yet added to the stored history
+	latency *latencyRecorder
+	// Recent fetches and requests, for admins debugging a failure after the fact
+	fetchLog *fetchLog
+
+	// Serializes read-modify-write of the stored cost history
+	historyLock sync.Mutex
+
+	// Provider instances parsed from the configuration, each with its own cache
+	instancesLock sync.Mutex
+	instances     []*providerInstance
+	instancesErr  error
+
+	// Recent access decisions by user, so membership isn't looked up on every request
+	accessLock sync.Mutex
+	access     map[string]accessDecision
+
+	// Plugins fetching with users' personal credentials, by user and provider
+	personalLock sync.Mutex
+	personal     map[string]*personalPlugin
+	// Whose renewed tokens this plugin stores: empty for the organization's, else the user
+	// ID of personal credentials or "instance:<type>:<id>" for a provider instance
+	tokenScope string
+
+	// Whether a cache warm-up is running, and whether another is due after it
+	warming   atomic.Bool
+	warmAgain atomic.Bool
+	// Whether a request started gathering a fresh snapshot in the background
+	refreshing atomic.Bool
+
+	// Status changes waiting to be posted to the alert channel together
+	alertLock  sync.Mutex
+	alertBatch *alertBatch
+
+	// When reactions last refreshed each status post
+	reactionLock      sync.Mutex
+	reactionRefreshed map[string]time.Time
+
+	// Background jobs and goroutines, cancelled and waited for on deactivation
+	bg *background
+
+	// Unix time an allowed user last used the plugin, to slow down polling when idle.
+	// pollingIdle, lastIdlePoll, lastPoll and lastUsageSample are only used by the poller.
+	lastActivity    atomic.Int64
+	pollingIdle     bool
+	lastIdlePoll    time.Time
+	lastPoll        time.Time
+	lastUsageSample time.Time
+	// Fingerprints of the statuses last pushed to dashboards, by card; only used by the poller
+	pushed map[string]string
+
+	// Statuses as of the last background poll, served to requests
+	snapshot statusSnapshot
 }
 
 // Configuration holds the plugin settings from System Console.
 type Configuration struct {
 	AllowedUserIds     string `json:"alloweduserids"`
 	AllowedTeamIds     string `json:"allowedteamids"`
+	AllowedRoles       string `json:"allowedroles"`
+	AllowedChannelIds  string `json:"allowedchannelids"`
+	RestrictChanges    bool   `json:"restrictchanges"`
+	DemoMode           bool   `json:"demomode"`
 	AugmentEnabled     bool   `json:"augmentenabled"`
 	AugmentAccessToken string `json:"augmentaccesstoken"`
 	ZaiEnabled         bool   `json:"zaienabled"`
@@ -37,40 +129,475 @@ type Configuration struct {
 	OpenaiApiKey         string `json:"openaiapikey"`
 	OpenaiMonthlyBudget  string `json:"openaimonthlybudget"`
 	OpenaiCreditBalance  string `json:"openaicreditbalance"`
+	OpenaiProjectIds     string `json:"openaiprojectids"`
+	OpenaiProjectNames   string `json:"openaiprojectnames"`
+	OpenaiOrganizations  string `json:"openaiorganizations"`
+	OpenaiCycleAnchorDay string `json:"openaicycleanchorday"`
+	OpenaiBackfillMonths string `json:"openaibackfillmonths"`
+	OpenaiUserUsage      string `json:"openaiuserusage"`
+	OpenaiKeyBreakdown   bool   `json:"openaikeybreakdown"`
 	ClaudeEnabled      bool   `json:"claudeenabled"`
 	ClaudeAccessToken  string `json:"claudeaccesstoken"`
 	ClaudeRefreshToken string `json:"clauderefreshtoken"`
+	ClaudeSonnetWarn   string `json:"claudesonnetwarn"`
+	ClaudeOpusWarn     string `json:"claudeopuswarn"`
+	AnthropicEnabled       bool   `json:"anthropicenabled"`
+	AnthropicAdminKey      string `json:"anthropicadminkey"`
+	AnthropicMonthlyBudget string `json:"anthropicmonthlybudget"`
+	AnthropicUsageTier     string `json:"anthropicusagetier"`
+	CopilotEnabled         bool   `json:"copilotenabled"`
+	CopilotToken           string `json:"copilottoken"`
+	CopilotOrganization    string `json:"copilotorganization"`
+	CursorEnabled          bool   `json:"cursorenabled"`
+	CursorApiKey           string `json:"cursorapikey"`
+	CursorRequestsPerSeat  string `json:"cursorrequestsperseat"`
+	MistralEnabled         bool   `json:"mistralenabled"`
+	MistralApiKey          string `json:"mistralapikey"`
+	MistralConsoleCookie   string `json:"mistralconsolecookie"`
+	GroqEnabled            bool   `json:"groqenabled"`
+	GroqApiKey             string `json:"groqapikey"`
+	GroqModels             string `json:"groqmodels"`
+	DeepseekEnabled        bool   `json:"deepseekenabled"`
+	DeepseekApiKey         string `json:"deepseekapikey"`
+	DeepseekLowBalance     string `json:"deepseeklowbalance"`
+	PerplexityEnabled       bool   `json:"perplexityenabled"`
+	PerplexityApiKey        string `json:"perplexityapikey"`
+	PerplexityUsageTier     string `json:"perplexityusagetier"`
+	PerplexityCreditBalance string `json:"perplexitycreditbalance"`
+	PerplexityLowBalance    string `json:"perplexitylowbalance"`
+	TogetherEnabled         bool   `json:"togetherenabled"`
+	TogetherApiKey          string `json:"togetherapikey"`
+	TogetherCreditBalance   string `json:"togethercreditbalance"`
+	TogetherLowBalance      string `json:"togetherlowbalance"`
+	BedrockEnabled          bool   `json:"bedrockenabled"`
+	BedrockAccessKeyId      string `json:"bedrockaccesskeyid"`
+	BedrockSecretAccessKey  string `json:"bedrocksecretaccesskey"`
+	BedrockSessionToken     string `json:"bedrocksessiontoken"`
+	BedrockRegion           string `json:"bedrockregion"`
+	BedrockMonthlyBudget    string `json:"bedrockmonthlybudget"`
+	VertexEnabled           bool   `json:"vertexenabled"`
+	VertexServiceAccountKey string `json:"vertexserviceaccountkey"`
+	VertexProjects          string `json:"vertexprojects"`
+	VertexBillingTable      string `json:"vertexbillingtable"`
+	VertexMonthlyBudget     string `json:"vertexmonthlybudget"`
+	GeminiEnabled           bool   `json:"geminienabled"`
+	GeminiApiKey            string `json:"geminiapikey"`
+	GeminiServiceAccountKey string `json:"geminiserviceaccountkey"`
+	GeminiProject           string `json:"geminiproject"`
+	AzureOpenaiEnabled        bool   `json:"azureopenaienabled"`
+	AzureOpenaiTenantId       string `json:"azureopenaitenantid"`
+	AzureOpenaiClientId       string `json:"azureopenaiclientid"`
+	AzureOpenaiClientSecret   string `json:"azureopenaiclientsecret"`
+	AzureOpenaiSubscriptionId string `json:"azureopenaisubscriptionid"`
+	AzureOpenaiAccounts       string `json:"azureopenaiaccounts"`
+	XaiEnabled              bool   `json:"xaienabled"`
+	XaiApiKey               string `json:"xaiapikey"`
+	XaiManagementKey        string `json:"xaimanagementkey"`
+	XaiRateLimitTier        string `json:"xairatelimittier"`
+	XaiLowBalance           string `json:"xailowbalance"`
+	WindsurfEnabled         bool   `json:"windsurfenabled"`
+	WindsurfServiceKey      string `json:"windsurfservicekey"`
+	TabnineEnabled          bool   `json:"tabnineenabled"`
+	TabninePlan             string `json:"tabnineplan"`
+	TabnineSeatsPurchased   string `json:"tabnineseatspurchased"`
+	TabnineSeatsAssigned    string `json:"tabnineseatsassigned"`
+	TabnineRenewalDate      string `json:"tabninerenewaldate"`
+	JetbrainsEnabled        bool   `json:"jetbrainsenabled"`
+	JetbrainsCustomerCode   string `json:"jetbrainscustomercode"`
+	JetbrainsApiKey         string `json:"jetbrainsapikey"`
+	CerebrasEnabled         bool   `json:"cerebrasenabled"`
+	CerebrasApiKey          string `json:"cerebrasapikey"`
+	CerebrasModels          string `json:"cerebrasmodels"`
+	SambanovaEnabled        bool   `json:"sambanovaenabled"`
+	SambanovaApiKey         string `json:"sambanovaapikey"`
+	SambanovaModels         string `json:"sambanovamodels"`
+	SambanovaTier           string `json:"sambanovatier"`
+	SelfhostedEnabled       bool   `json:"selfhostedenabled"`
+	SelfhostedUrl           string `json:"selfhostedurl"`
+	SelfhostedServerType    string `json:"selfhostedservertype"`
+	SelfhostedApiKey        string `json:"selfhostedapikey"`
+	SelfhostedThroughput    bool   `json:"selfhostedthroughput"`
+	LitellmEnabled          bool   `json:"litellmenabled"`
+	LitellmUrl              string `json:"litellmurl"`
+	LitellmMasterKey        string `json:"litellmmasterkey"`
+	HeliconeEnabled         bool   `json:"heliconeenabled"`
+	HeliconeApiKey          string `json:"heliconeapikey"`
+	HeliconeUrl             string `json:"heliconeurl"`
+	HeliconeMonthlyBudget   string `json:"heliconemonthlybudget"`
+	GatewayEnabled          bool   `json:"gatewayenabled"`
+	GatewayName             string `json:"gatewayname"`
+	GatewayUrl              string `json:"gatewayurl"`
+	GatewayApiKey           string `json:"gatewayapikey"`
+	GatewayUsagePath        string `json:"gatewayusagepath"`
+	GatewayUsedField        string `json:"gatewayusedfield"`
+	GatewayLimitField       string `json:"gatewaylimitfield"`
+	GatewayUsageUnit        string `json:"gatewayusageunit"`
+	ElevenlabsEnabled       bool   `json:"elevenlabsenabled"`
+	ElevenlabsApiKey        string `json:"elevenlabsapikey"`
+	AssemblyaiEnabled       bool   `json:"assemblyaienabled"`
+	AssemblyaiApiKey        string `json:"assemblyaiapikey"`
+	AssemblyaiHourlyRate    string `json:"assemblyaihourlyrate"`
+	AssemblyaiCreditBalance string `json:"assemblyaicreditbalance"`
+	AssemblyaiLowBalance    string `json:"assemblyailowbalance"`
+	SupermavenEnabled       bool   `json:"supermavenenabled"`
+	SupermavenPlan          string `json:"supermavenplan"`
+	SupermavenPlanStatus    string `json:"supermavenplanstatus"`
+	SupermavenSeatsTotal    string `json:"supermavenseatstotal"`
+	SupermavenSeatsAssigned string `json:"supermavenseatsassigned"`
+	SupermavenRenewalDate   string `json:"supermavenrenewaldate"`
+	AmazonqEnabled          bool   `json:"amazonqenabled"`
+	AmazonqAccessKeyId      string `json:"amazonqaccesskeyid"`
+	AmazonqSecretAccessKey  string `json:"amazonqsecretaccesskey"`
+	AmazonqSessionToken     string `json:"amazonqsessiontoken"`
+	AmazonqRegion           string `json:"amazonqregion"`
+	AmazonqIdentityStoreId  string `json:"amazonqidentitystoreid"`
+	AmazonqGroupId          string `json:"amazonqgroupid"`
+	AmazonqAgenticLimit     string `json:"amazonqagenticlimit"`
+	MoonshotEnabled         bool   `json:"moonshotenabled"`
+	MoonshotApiKey          string `json:"moonshotapikey"`
+	MoonshotPlatform        string `json:"moonshotplatform"`
+	MoonshotTier            string `json:"moonshottier"`
+	MoonshotLowBalance      string `json:"moonshotlowbalance"`
+	DashscopeEnabled        bool   `json:"dashscopeenabled"`
+	DashscopeApiKey         string `json:"dashscopeapikey"`
+	DashscopeRegion         string `json:"dashscoperegion"`
+	DashscopeAccessKeyId    string `json:"dashscopeaccesskeyid"`
+	DashscopeAccessSecret   string `json:"dashscopeaccesssecret"`
+	DashscopeProductCode    string `json:"dashscopeproductcode"`
+	DashscopeMonthlyBudget  string `json:"dashscopemonthlybudget"`
+	Ai21Enabled             bool   `json:"ai21enabled"`
+	Ai21ApiKey              string `json:"ai21apikey"`
+	Ai21Plan                string `json:"ai21plan"`
+	Ai21TrialEnds           string `json:"ai21trialends"`
+	FalEnabled              bool   `json:"falenabled"`
+	FalApiKey               string `json:"falapikey"`
+	FalLowBalance           string `json:"fallowbalance"`
+	RunpodEnabled           bool   `json:"runpodenabled"`
+	RunpodApiKey            string `json:"runpodapikey"`
+	RunpodLowBalance        string `json:"runpodlowbalance"`
+	YandexEnabled           bool   `json:"yandexenabled"`
+	YandexServiceAccountKey string `json:"yandexserviceaccountkey"`
+	YandexBillingAccountId  string `json:"yandexbillingaccountid"`
+	YandexLowBalance        string `json:"yandexlowbalance"`
+	GigachatEnabled         bool   `json:"gigachatenabled"`
+	GigachatAuthKey         string `json:"gigachatauthkey"`
+	GigachatScope           string `json:"gigachatscope"`
+	GigachatLowTokens       string `json:"gigachatlowtokens"`
+	RekaEnabled             bool   `json:"rekaenabled"`
+	RekaApiKey              string `json:"rekaapikey"`
+	RekaCreditBalance       string `json:"rekacreditbalance"`
+	RekaLowBalance          string `json:"rekalowbalance"`
+	WatsonxEnabled          bool   `json:"watsonxenabled"`
+	WatsonxApiKey           string `json:"watsonxapikey"`
+	WatsonxAccountId        string `json:"watsonxaccountid"`
+	WatsonxUnitLimit        string `json:"watsonxunitlimit"`
+	WatsonxMonthlyBudget    string `json:"watsonxmonthlybudget"`
+	DatabricksEnabled       bool   `json:"databricksenabled"`
+	DatabricksHost          string `json:"databrickshost"`
+	DatabricksToken         string `json:"databrickstoken"`
+	DatabricksWarehouseId   string `json:"databrickswarehouseid"`
+	DatabricksMonthlyBudget string `json:"databricksmonthlybudget"`
+	NvidiaEnabled           bool   `json:"nvidiaenabled"`
+	NvidiaApiKey            string `json:"nvidiaapikey"`
+	NvidiaCreditsLeft       string `json:"nvidiacreditsleft"`
+	NvidiaCreditsTotal      string `json:"nvidiacreditstotal"`
+	VercelEnabled           bool   `json:"vercelenabled"`
+	VercelApiKey            string `json:"vercelapikey"`
+	VercelLowBalance        string `json:"vercellowbalance"`
+	OpenrouterEnabled       bool   `json:"openrouterenabled"`
+	OpenrouterApiKey        string `json:"openrouterapikey"`
+	OpenrouterLowBalance    string `json:"openrouterlowbalance"`
+	PortkeyEnabled          bool   `json:"portkeyenabled"`
+	PortkeyApiKey           string `json:"portkeyapikey"`
+	PortkeyWorkspaces       string `json:"portkeyworkspaces"`
+	PortkeyBudgets          string `json:"portkeybudgets"`
+	GithubModelsEnabled     bool   `json:"githubmodelsenabled"`
+	GithubModelsToken       string `json:"githubmodelstoken"`
+	GithubModelsOrg         string `json:"githubmodelsorg"`
+	GithubModelsPlan        string `json:"githubmodelsplan"`
+	GithubModelsModels      string `json:"githubmodelsmodels"`
+	CodexEnabled            bool   `json:"codexenabled"`
+	CodexAccessToken        string `json:"codexaccesstoken"`
+	CodexRefreshToken       string `json:"codexrefreshtoken"`
+	CodexAccountId          string `json:"codexaccountid"`
+	ChatgptEnabled          bool   `json:"chatgptenabled"`
+	ChatgptSessionToken     string `json:"chatgptsessiontoken"`
+	ChatgptAccountId        string `json:"chatgptaccountid"`
+	ClaudeCodeEnabled         bool   `json:"claudecodeenabled"`
+	ClaudeCodeDeveloperBudget string `json:"claudecodedeveloperbudget"`
+	UpstageEnabled          bool   `json:"upstageenabled"`
+	UpstageApiKey           string `json:"upstageapikey"`
+	UpstageCreditBalance    string `json:"upstagecreditbalance"`
+	UpstageLowBalance       string `json:"upstagelowbalance"`
+	AigatewayEnabled        bool   `json:"aigatewayenabled"`
+	AigatewayName           string `json:"aigatewayname"`
+	AigatewayMetricsUrl     string `json:"aigatewaymetricsurl"`
+	AigatewayToken          string `json:"aigatewaytoken"`
+	AigatewayLimits         string `json:"aigatewaylimits"`
+	CustomProvidersEnabled  bool   `json:"customprovidersenabled"`
+	CustomProviders         string `json:"customproviders"`
+	PluginProvidersEnabled  bool   `json:"pluginprovidersenabled"`
+	FederationEnabled       bool   `json:"federationenabled"`
+	FederationPeers         string `json:"federationpeers"`
+	CollectorsEnabled       bool   `json:"collectorsenabled"`
+	CollectorToken          string `json:"collectortoken"`
+	ProviderInstances       string `json:"providerinstances"`
+	ProviderHeaders         string `json:"providerheaders"`
+	OutboundProxyUrl        string `json:"outboundproxyurl"`
+	OutboundCaCertificates  string `json:"outboundcacertificates"`
+	OutboundSkipTlsVerify   bool   `json:"outboundskiptlsverify"`
+	PersonalCredentialsEnabled bool `json:"personalcredentialsenabled"`
+	DisplayUnits       string `json:"displayunits"`
+	ReportingCurrency  string `json:"reportingcurrency"`
+	ExchangeRates      string `json:"exchangerates"`
+	MaxResponseSizeKb  string `json:"maxresponsesizekb"`
+	StrictResponseChecks bool `json:"strictresponsechecks"`
+	WarningThreshold   string `json:"warningthreshold"`
+	ProviderWarningThresholds string `json:"providerwarningthresholds"`
+	CacheTtl           string `json:"cachettl"`
+	ProviderCacheTtls  string `json:"providercachettls"`
+	CircuitBreakerFailures        string `json:"circuitbreakerfailures"`
+	CircuitBreakerCooldownMinutes string `json:"circuitbreakercooldownminutes"`
+	ConfigManagerIds   string `json:"configmanagerids"`
+	OverallStatusWeights  string `json:"overallstatusweights"`
+	OverallErrorPercent   string `json:"overallerrorpercent"`
+	OverallWarningPercent string `json:"overallwarningpercent"`
+	AlertChannelId     string `json:"alertchannelid"`
+	AlertRecipients    string `json:"alertrecipients"`
+	AlertGroupingWindow string `json:"alertgroupingwindow"`
+	CostReportChannelId string `json:"costreportchannelid"`
+	CostAllocation      string `json:"costallocation"`
+	Budgets             string `json:"budgets"`
+	BoardsEnabled      bool   `json:"boardsenabled"`
+	BoardsBoardId      string `json:"boardsboardid"`
+	DigestSchedule     string `json:"digestschedule"`
+	DigestHour         string `json:"digesthour"`
+	DigestEmails       string `json:"digestemails"`
+	DigestWeekday      string `json:"digestweekday"`
+	DigestChannelId    string `json:"digestchannelid"`
+	DigestContents     string `json:"digestcontents"`
+	JiraEnabled        bool   `json:"jiraenabled"`
+	JiraUrl            string `json:"jiraurl"`
+	JiraUsername       string `json:"jirausername"`
+	JiraToken          string `json:"jiratoken"`
+	JiraProjectKey     string `json:"jiraprojectkey"`
+	JiraIssueType      string `json:"jiraissuetype"`
+	JiraCriticalMins   string `json:"jiracriticalmins"`
+	WebhookUrl         string `json:"webhookurl"`
+	WebhookSecret      string `json:"webhooksecret"`
+	WebhookTemplate    string `json:"webhooktemplate"`
+	WebhookEvents      string `json:"webhookevents"`
+	GrafanaUrl         string `json:"grafanaurl"`
+	GrafanaToken       string `json:"grafanatoken"`
+	GrafanaDashboardUid string `json:"grafanadashboarduid"`
+	CalendarToken      string `json:"calendartoken"`
+	MetricsToken       string `json:"metricstoken"`
+	HeaderChannelId    string `json:"headerchannelid"`
+	HeaderField        string `json:"headerfield"`
+	StatusPostChannelId string `json:"statuspostchannelid"`
+	StatusPostPin      bool   `json:"statuspostpin"`
+	MentionsEnabled    bool   `json:"mentionsenabled"`
+	RefreshEmoji       string `json:"refreshemoji"`
+	ProviderNames      string `json:"providernames"`
+	ProviderOrder      string `json:"providerorder"`
+	IdleAfterHours     string `json:"idleafterhours"`
+	PollIntervalMinutes string `json:"pollintervalminutes"`
+	UserAgent          string `json:"useragent"`
+	CredentialMaxAgeDays string `json:"credentialmaxagedays"`
+	CredentialExpirations string `json:"credentialexpirations"`
+	CredentialExpiryWarningDays string `json:"credentialexpirywarningdays"`
+
+	// Credential settings given as env: or file: references, by key
+	secretRefs map[string]secretReference
+	// The configuration document applied over the System Console settings
+	document *appliedDocument
 }
 
 // CacheEntry stores cached API response.
 type CacheEntry struct {
 	Data      interface{}
 	FetchedAt time.Time
+	TTL       time.Duration
 }
 
 // ServiceStatus represents the status of one AI service.
 type ServiceStatus struct {
-	ID       string      `json:"id"`
-	Name     string      `json:"name"`
-	Enabled  bool        `json:"enabled"`
-	Status   string      `json:"status"` // "ok", "warning", "error", "disabled"
-	Data     interface{} `json:"data,omitempty"`
-	Error    string      `json:"error,omitempty"`
-	CachedAt int64       `json:"cachedAt,omitempty"`
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Enabled  bool          `json:"enabled"`
+	Status   string        `json:"status"` // "ok", "warning", "error", "reauth", "unreachable", "disabled"
+	Data     interface{}   `json:"data,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	CachedAt int64         `json:"cachedAt,omitempty"`
+	Resets   []ResetView   `json:"resets,omitempty"`
+	Usage    *UsageMetrics `json:"usage,omitempty"`
+	// Usage projected to the end of the billing cycle, for services with one
+	Forecast *Forecast `json:"forecast,omitempty"`
+	// ID logged with a failed fetch's error, to find it in the server logs
+	RequestID string `json:"requestId,omitempty"`
+	// Unix time the provider's API key or token expires, when known
+	CredentialExpiresAt int64 `json:"credentialExpiresAt,omitempty"`
+	// The last successful status, shown because the latest fetch failed with StaleError
+	Stale      bool   `json:"stale,omitempty"`
+	StaleError string `json:"staleError,omitempty"`
+	AgeSeconds int64  `json:"ageSeconds,omitempty"` // how old the stale data is
+	// Set while the provider isn't called because its circuit breaker is open
+	Breaker *BreakerState `json:"breaker,omitempty"`
+	// Fetched with the requesting user's own credentials rather than the organization's
+	Personal bool `json:"personal,omitempty"`
+	// Seconds until the card is fetched again, when its result is cached or backing off
+	RefreshInSeconds int64 `json:"refreshInSeconds,omitempty"`
+
+	// Message ID and arguments of Error, so it can be localized per user
+	errorID   string
+	errorArgs []interface{}
+	// The same for StaleError
+	staleErrorID   string
+	staleErrorArgs []interface{}
+}
+
+// errorStatus returns an error status whose message can be localized per user.
+func errorStatus(id, name, msgID string, args ...interface{}) ServiceStatus {
+	return ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: "error",
+		Error: translate("en", msgID, args...), errorID: msgID, errorArgs: args,
+	}
+}
+
+// reauthStatus returns the status for a provider whose credentials expired or were
+// revoked. It is as severe as an error, but tells admins exactly what to do.
+func reauthStatus(id, name, msgID string) ServiceStatus {
+	s := errorStatus(id, name, msgID)
+	s.Status = "reauth"
+	return s
+}
+
+// disabledStatus returns the placeholder status for a provider that isn't enabled.
+func disabledStatus(id, name string) ServiceStatus {
+	return ServiceStatus{
+		ID: id, Name: name, Enabled: false, Status: "disabled",
+		Error: translate("en", "status.not_configured"), errorID: "status.not_configured",
+	}
 }
 
 // AllServicesResponse is the response for GET /api/v1/status.
 type AllServicesResponse struct {
 	Services []ServiceStatus `json:"services"`
+	Units    string          `json:"units"` // effective display units for the requesting user
+	Total    *CostTotal      `json:"totalCost,omitempty"`
+	Demo     bool            `json:"demo,omitempty"`
+	Budgets  []BudgetStatus  `json:"budgets,omitempty"`
 }
 
 // (no KV store needed — session key is in plugin config)
 
 func (p *Plugin) OnActivate() error {
 	p.cache = make(map[string]*CacheEntry)
+	p.states = make(map[string]*providerState)
+
+	botUserID, err := p.API.EnsureBotUser(&model.Bot{
+		Username:    "ailimits",
+		DisplayName: "AI Limits",
+		Description: "Created by the AI Limits Monitor plugin.",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ensure bot user: %w", err)
+	}
+	p.botUserID = botUserID
+
+	if err := p.registerCommands(); err != nil {
+		return fmt.Errorf("failed to register command: %w", err)
+	}
+
+	p.bg = newBackground()
+	p.markActive()
+	p.defaultUA = p.buildUserAgent()
+	p.latency = &latencyRecorder{}
+	p.fetchLog = &fetchLog{}
+	p.instancesLock.Lock()
+	for _, inst := range p.instances {
+		inst.plugin.bg = p.bg
+		inst.plugin.defaultUA = p.defaultUA
+		inst.plugin.latency = p.latency
+		inst.plugin.fetchLog = p.fetchLog
+	}
+	p.instancesLock.Unlock()
+
+	p.startJitteredJob(time.Minute, p.pollProviders)
+	p.startJob(time.Minute, p.runDigestJob)
+	p.startJob(time.Minute, p.runCostReportJob)
+	p.startJob(time.Minute, p.checkEscalations)
+	p.startJob(time.Hour, p.checkCredentialAges)
+	p.startJob(time.Hour, p.checkCredentialExpiry)
+	p.startJob(5*time.Minute, p.flushLatencies)
+	p.startJob(5*time.Minute, p.refreshSecrets)
+	p.startJob(time.Minute, p.checkConfigDocument)
+	p.startJob(time.Minute, p.renewClaudeTokens)
+	p.bg.Go(p, p.backfillOnActivate)
+	p.warmUpCache()
+
+	return nil
+}
+
+// OnDeactivate stops the background jobs, cancels provider requests in flight and waits for
+// them to return, so nothing calls the API once the plugin is gone.
+func (p *Plugin) OnDeactivate() error {
+	if p.bg == nil {
+		return nil
+	}
+	if !p.bg.stop(shutdownTimeout) {
+		p.API.LogWarn("Background work still running after deactivation", "timeout", shutdownTimeout.String())
+	}
+	p.flushLatencies()
 	return nil
 }
 
+// startJitteredJob is startJob for jobs that call provider APIs. The first run waits up to
+// one extra interval, at random, and each wait varies by up to a tenth, so servers restarted
+// together don't poll the providers in lockstep.
+func (p *Plugin) startJitteredJob(interval time.Duration, fn func()) {
+	p.bg.Go(p, func() {
+		timer := time.NewTimer(interval + time.Duration(rand.Int63n(int64(interval))))
+		defer timer.Stop()
+		for {
+			select {
+			case <-p.bg.done():
+				return
+			case <-timer.C:
+				p.runJob(fn)
+				timer.Reset(interval + time.Duration(rand.Int63n(int64(interval)/5)) - interval/10)
+			}
+		}
+	})
+}
+
+// runJob runs one iteration of a background job, surviving a panic.
+func (p *Plugin) runJob(fn func()) {
+	defer p.recoverJob()
+	fn()
+}
+
+// jitter shortens d by a random amount up to the given fraction of it.
+func jitter(d time.Duration, fraction float64) time.Duration {
+	return d - time.Duration(rand.Float64()*fraction*float64(d))
+}
+
+// startJob runs fn every interval in the background until the plugin is deactivated.
+func (p *Plugin) startJob(interval time.Duration, fn func()) {
+	p.bg.Go(p, func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.bg.done():
+				return
+			case <-ticker.C:
+				p.runJob(fn)
+			}
+		}
+	})
+}
+
 func (p *Plugin) getConfiguration() *Configuration {
 	p.configurationLock.RLock()
 	defer p.configurationLock.RUnlock()
@@ -86,63 +613,123 @@ func (p *Plugin) OnConfigurationChange() error {
 	if err := p.API.LoadPluginConfiguration(&configuration); err != nil {
 		return err
 	}
+	p.useConfiguration(&configuration)
+	return nil
+}
+
+// useConfiguration applies the configuration document, resolves the configuration's secret
+// references and makes it current.
+func (p *Plugin) useConfiguration(raw *Configuration) {
+	raw, document := p.applyConfigDocument(raw)
+	configuration, errs := resolveSecrets(raw)
+	for _, err := range errs {
+		p.API.LogWarn("Failed to resolve a secret reference", "error", err.Error())
+	}
+	configuration.document = document
 	p.configurationLock.Lock()
-	p.configuration = &configuration
+	previous := p.configuration
+	p.configuration = configuration
 	p.configurationLock.Unlock()
+	p.snapshot.clear()
 
-	// Clear cache on config change
-	p.cacheLock.Lock()
-	p.cache = make(map[string]*CacheEntry)
-	p.cacheLock.Unlock()
+	// Only refetch the providers whose settings changed
+	if previous == nil {
+		previous = &Configuration{}
+	}
+	p.applyConfigurationChange(previous, configuration)
 
-	return nil
-}
+	instances, err := p.parseProviderInstances(configuration)
+	if err != nil {
+		p.API.LogWarn("Invalid provider instances", "error", err.Error())
+	}
+	p.trackCredentialAges(configuration)
+	if _, err := parseProviderHeaders(configuration.ProviderHeaders); err != nil {
+		p.API.LogWarn("Invalid provider headers", "error", err.Error())
+	}
+	p.checkOutbound(configuration)
 
-// checkAccess returns true if user is allowed to access this plugin.
-func (p *Plugin) checkAccess(userID string) bool {
-	config := p.getConfiguration()
-	
-	// If no restrictions set, allow everyone
-	if config.AllowedUserIds == "" && config.AllowedTeamIds == "" {
-		return true
+	p.clearPersonalPlugins()
+	p.clearAccess()
+
+	p.instancesLock.Lock()
+	reuseInstancePlugins(p.instances, instances)
+	p.instances, p.instancesErr = instances, err
+	p.instancesLock.Unlock()
+
+	// Before activation there's nothing to warm up yet; OnActivate does it
+	if p.bg != nil {
+		p.warmUpCache()
 	}
-	
-	// Check allowed users
-	if config.AllowedUserIds != "" {
-		allowedUsers := strings.Split(config.AllowedUserIds, ",")
-		for _, id := range allowedUsers {
-			if strings.TrimSpace(id) == userID {
-				return true
-			}
-		}
+}
+
+// saveConfiguration persists config through the plugin settings, which triggers
+// OnConfigurationChange. Credentials read from a reference are saved as the reference.
+func (p *Plugin) saveConfiguration(config *Configuration) error {
+	if appErr := p.API.SavePluginConfig(config.withSecretReferences()); appErr != nil {
+		return appErr
 	}
-	
-	// Check allowed teams
-	if config.AllowedTeamIds != "" {
-		allowedTeams := strings.Split(config.AllowedTeamIds, ",")
-		for _, teamID := range allowedTeams {
-			teamID = strings.TrimSpace(teamID)
-			if teamID == "" {
-				continue
-			}
-			// Check if user is member of this team
-			_, err := p.API.GetTeamMember(teamID, userID)
-			if err == nil {
-				return true
-			}
-		}
+	return nil
+}
+
+// warningPercent returns the configured usage percentage at which providers
+// turn to warning, or the provider's own default when none is set.
+func (c *Configuration) warningPercent(def float64) float64 {
+	return parsePercent(c.WarningThreshold, def)
+}
+
+// parsePercent parses a threshold setting between 0 (exclusive) and 100, or returns def.
+func parsePercent(value string, def float64) float64 {
+	if t, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && t > 0 && t <= 100 {
+		return t
 	}
-	
-	return false
+	return def
 }
 
 func (p *Plugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+	// Reuse the server's request ID so plugin and server log lines match up
+	requestID := model.NewId()
+	if c != nil && c.RequestId != "" {
+		requestID = c.RequestId
+	}
+	w.Header().Set("X-Request-Id", requestID)
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		defer p.logRequest(rec, r, requestID, start)
+		w = rec
+	}
+	defer p.recoverRequest(w, r, requestID)
+
 	// Serve static assets from webapp/dist/
 	if !strings.HasPrefix(r.URL.Path, "/api/") {
 		p.serveStaticFile(w, r)
 		return
 	}
 
+	// Calendar clients can't send a Mattermost session; the feed checks its own token
+	if r.URL.Path == "/api/v1/calendar.ics" && r.Method == http.MethodGet {
+		p.handleCalendar(w, r)
+		return
+	}
+
+	// Prometheus scrapes with the metrics token rather than a session
+	if r.URL.Path == "/api/v1/metrics" && r.Method == http.MethodGet {
+		p.handleUsageMetrics(w, r)
+		return
+	}
+
+	// Collectors push from networks of their own, with a token rather than a session
+	if r.URL.Path == "/api/v1/collector/report" && r.Method == http.MethodPost {
+		p.handleCollectorReport(w, r)
+		return
+	}
+
+	// Other plugins manage their providers over the inter-plugin API, without a user session
+	if pluginID := r.Header.Get("Mattermost-Plugin-ID"); pluginID != "" && strings.HasPrefix(r.URL.Path, "/api/v1/providers/") {
+		p.handlePluginProviders(w, r, pluginID)
+		return
+	}
+
 	// Check user is logged in for API routes
 	userID := r.Header.Get("Mattermost-User-Id")
 	if userID == "" {
@@ -150,11 +737,47 @@ func (p *Plugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Req
 		return
 	}
 	
+	// Tuning managers may manage budgets too, like the per-provider budgets
+	if r.URL.Path == "/api/v1/admin/budgets" && (r.Method == http.MethodGet || r.Method == http.MethodPut) && p.canTune(userID) {
+		p.handleBudgets(w, r)
+		return
+	}
+
+	// Finance exports are for system admins, whatever the access settings
+	if r.URL.Path == "/api/v1/export" && r.Method == http.MethodGet {
+		if !p.isSystemAdmin(userID) {
+			http.Error(w, `{"error": "forbidden", "message": "System admin permission required"}`, http.StatusForbidden)
+			return
+		}
+		p.handleExport(w, r)
+		return
+	}
+
+	// System Console endpoints check for the system admin permission instead
+	if strings.HasPrefix(r.URL.Path, "/api/v1/admin/") {
+		p.serveAdminAPI(w, r, userID)
+		return
+	}
+
+	// Developers report their own Claude Code usage even without access to the dashboard
+	if r.URL.Path == "/api/v1/claudecode/usage" && r.Method == http.MethodPost {
+		p.handleClaudeCodeUsage(w, r, userID)
+		return
+	}
+
 	// Check access permissions
 	if !p.checkAccess(userID) {
 		http.Error(w, `{"error": "access_denied", "message": "You don't have permission to access this plugin"}`, http.StatusForbidden)
 		return
 	}
+	p.markActive()
+
+	// Refreshing and managing credentials may be left to managers
+	changing := r.Method != http.MethodGet && (strings.HasPrefix(r.URL.Path, "/api/v1/refresh") || r.URL.Path == "/api/v1/me/credentials")
+	if changing && !p.canChange(userID) {
+		http.Error(w, `{"error": "forbidden", "message": "Only managers may make changes"}`, http.StatusForbidden)
+		return
+	}
 
 	switch {
 	case r.URL.Path == "/api/v1/access" && r.Method == http.MethodGet:
@@ -163,13 +786,84 @@ func (p *Plugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Req
 		w.Write([]byte(`{"allowed": true}`))
 	case r.URL.Path == "/api/v1/status" && r.Method == http.MethodGet:
 		p.handleGetStatus(w, r)
+	case r.URL.Path == "/api/v1/status/compact" && r.Method == http.MethodGet:
+		p.handleGetCompactStatus(w, r)
+	case r.URL.Path == "/api/v1/summary" && r.Method == http.MethodGet:
+		p.handleGetSummary(w, r)
 	case r.URL.Path == "/api/v1/refresh" && r.Method == http.MethodPost:
 		p.handleRefresh(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/v1/status/") && r.Method == http.MethodGet:
+		p.handleGetProviderStatus(w, r, strings.TrimPrefix(r.URL.Path, "/api/v1/status/"))
+	case strings.HasPrefix(r.URL.Path, "/api/v1/refresh/") && r.Method == http.MethodPost:
+		p.handleRefreshProvider(w, r, strings.TrimPrefix(r.URL.Path, "/api/v1/refresh/"))
+	case r.URL.Path == "/api/v1/preferences" && r.Method == http.MethodGet:
+		p.handleGetPreferences(w, r, userID)
+	case r.URL.Path == "/api/v1/preferences" && r.Method == http.MethodPut:
+		p.handlePutPreferences(w, r, userID)
+	case r.URL.Path == "/api/v1/trends" && r.Method == http.MethodGet:
+		p.handleTrends(w, r)
+	case r.URL.Path == "/api/v1/history" && r.Method == http.MethodGet:
+		p.handleHistory(w, r)
+	case r.URL.Path == "/api/v1/timeline" && r.Method == http.MethodGet:
+		p.handleTimeline(w, r)
+	case r.URL.Path == "/api/v1/alerts/ack" && r.Method == http.MethodPost:
+		p.handleAlertAck(w, r, userID)
+	case r.URL.Path == "/api/v1/config" && r.Method == http.MethodGet:
+		p.handleGetConfig(w, r, userID)
+	case r.URL.Path == "/api/v1/config" && r.Method == http.MethodPatch:
+		p.handlePatchConfig(w, r, userID)
+	case r.URL.Path == "/api/v1/me/credentials":
+		p.handlePersonalCredentials(w, r, userID)
 	default:
 		http.NotFound(w, r)
 	}
 }
 
+// recoverRequest answers a request whose handler panicked with a 500 and logs the stack, so
+// one bad request doesn't take down the plugin for everyone.
+func (p *Plugin) recoverRequest(w http.ResponseWriter, r *http.Request, requestID string) {
+	if rec := recover(); rec != nil {
+		p.API.LogError("HTTP handler panicked", "request_id", requestID, "path", r.URL.Path, "method", r.Method,
+			"panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+		body, _ := json.Marshal(map[string]string{
+			"error": "internal_error", "message": "Internal error, see the server logs", "requestId": requestID,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(body)
+	}
+}
+
+// statusRecorder remembers the status code written, for the request log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequest logs every API call at debug level, and server errors as warnings, with the
+// request ID returned in the X-Request-Id header.
+func (p *Plugin) logRequest(w *statusRecorder, r *http.Request, requestID string, start time.Time) {
+	fields := []interface{}{"request_id", requestID, "method", r.Method, "path", r.URL.Path,
+		"status", w.status, "duration", time.Since(start).String()}
+	if w.status >= 500 {
+		p.API.LogWarn("API request failed", fields...)
+	} else {
+		p.API.LogDebug("API request", fields...)
+	}
+}
+
+// recoverJob logs a panic in a background job so the job keeps running.
+func (p *Plugin) recoverJob() {
+	if rec := recover(); rec != nil {
+		p.API.LogError("Background job panicked", "panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+	}
+}
+
 func (p *Plugin) serveStaticFile(w http.ResponseWriter, r *http.Request) {
 	bundlePath, err := p.API.GetBundlePath()
 	if err != nil {
@@ -182,59 +876,132 @@ func (p *Plugin) serveStaticFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	filePath := filepath.Join(bundlePath, "webapp", "dist", cleanPath)
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
 		// Also check assets/
 		filePath = filepath.Join(bundlePath, "assets", cleanPath)
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			http.NotFound(w, r)
-			return
-		}
+		info, err = os.Stat(filePath)
+	}
+	// Only regular files of a sane size; never directory listings
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxStaticFileSize {
+		http.NotFound(w, r)
+		return
 	}
 	http.ServeFile(w, r, filePath)
 }
 
+// handleGetStatus serves GET /api/v1/status. sort=severity puts the most constrained
+// services first and min_status leaves out the services below a status.
 func (p *Plugin) handleGetStatus(w http.ResponseWriter, r *http.Request) {
-	config := p.getConfiguration()
-	services := []ServiceStatus{}
-
-	if config.AugmentEnabled {
-		services = append(services, p.getAugmentStatus(config))
+	view, err := parseStatusView(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": "invalid_query", "message": "%s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	var services []ServiceStatus
+	if r.Header.Get(federationHeader) == "" {
+		services = p.collectStatuses()
 	} else {
-		services = append(services, ServiceStatus{ID: "augment", Name: "Augment Code", Enabled: false, Status: "disabled", Error: "Not configured. Enable in System Console → Plugins → AI Limits Monitor."})
+		services = p.gatherStatuses(false)
 	}
+	p.trackStatusChanges(services)
+	config := p.getConfiguration()
+	budgets := p.budgetStatuses(services, config)
+	p.trackBudgets(budgets)
+	// Personal cards are the user's own business, so they're added after tracking
+	services = p.withPersonalStatuses(services, r.Header.Get("Mattermost-User-Id"))
+	uc := p.getUserContext(r.Header.Get("Mattermost-User-Id"))
+	localizeStatuses(services, uc.Locale)
+	addResetViews(services, uc, time.Now())
+	addUsageMetrics(services)
+	setStaleAges(services, time.Now())
+	p.setRefreshTimes(services, time.Now())
 
-	if config.ZaiEnabled {
-		services = append(services, p.getZaiStatus(config))
-	} else {
-		services = append(services, ServiceStatus{ID: "zai", Name: "Z.AI", Enabled: false, Status: "disabled", Error: "Not configured. Enable in System Console → Plugins → AI Limits Monitor."})
+	// The total covers every service, including those filtered out
+	resp := AllServicesResponse{Units: uc.Units, Total: totalCost(services, config), Demo: config.DemoMode, Budgets: budgets}
+	resp.Services = view.apply(services)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// collectStatuses returns the current status of every known service: the background
+// poller's snapshot while it's recent, or else what the cache and providers return now.
+// A snapshot that's only a little old is served while a fresh one is gathered in the
+// background, so a slow provider doesn't hold up the request.
+func (p *Plugin) collectStatuses() []ServiceStatus {
+	now := time.Now()
+	config := p.getConfiguration()
+	if services, ok := p.snapshot.load(config.snapshotMaxAge(), now); ok {
+		return services
 	}
+	if services, ok := p.snapshot.load(p.snapshotStaleMaxAge(config), now); ok {
+		p.refreshSnapshot()
+		return services
+	}
+	services := p.gatherStatuses(true)
+	p.snapshot.store(services, now)
+	return services
+}
 
-	if config.OpenaiEnabled {
-		services = append(services, p.getOpenAIStatus(config))
-	} else {
-		services = append(services, ServiceStatus{ID: "openai", Name: "OpenAI", Enabled: false, Status: "disabled", Error: "Not configured. Enable in System Console → Plugins → AI Limits Monitor."})
+// refreshSnapshot gathers a fresh snapshot in the background, one at a time.
+func (p *Plugin) refreshSnapshot() {
+	if !p.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	if !p.bg.Go(p, func() {
+		defer p.refreshing.Store(false)
+		p.snapshot.store(p.gatherStatuses(true), time.Now())
+	}) {
+		p.refreshing.Store(false)
 	}
+}
 
-	if config.ClaudeEnabled {
-		services = append(services, p.getClaudeStatus(config))
-	} else {
-		services = append(services, ServiceStatus{ID: "claude", Name: "Claude (Anthropic)", Enabled: false, Status: "disabled", Error: "Not configured. Enable in System Console → Plugins → AI Limits Monitor."})
+// gatherStatuses is collectStatuses, leaving out the providers of federated peers when
+// answering a peer.
+func (p *Plugin) gatherStatuses(withPeers bool) []ServiceStatus {
+	config := p.getConfiguration()
+	if config.DemoMode {
+		services := demoStatuses(config, time.Now())
+		p.applyForecasts(services, config, time.Now())
+		return applyDisplaySettings(services, config)
 	}
 
-	resp := AllServicesResponse{Services: services}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	services := []ServiceStatus{}
+	instanceTypes := p.instanceTypes()
+	for _, provider := range providers {
+		if provider.ID == "federation" && !withPeers {
+			continue
+		}
+		if provider.Enabled(config) {
+			services = append(services, p.fetchProvider(provider, config.forProvider(provider.ID))...)
+		} else if !instanceTypes[provider.ID] {
+			services = append(services, disabledStatus(provider.ID, provider.Name))
+		}
+	}
+	services = append(services, p.getInstanceStatuses()...)
+	applyCredentialExpirations(services, config)
+	p.applyForecasts(services, config, time.Now())
+	return applyDisplaySettings(services, config)
 }
 
 func (p *Plugin) handleRefresh(w http.ResponseWriter, r *http.Request) {
 	p.cacheLock.Lock()
 	p.cache = make(map[string]*CacheEntry)
 	p.cacheLock.Unlock()
+	p.snapshot.clear()
+	p.clearBackoff()
+	p.clearInstanceCaches()
 
 	p.handleGetStatus(w, r)
 }
 
 func (p *Plugin) getCacheTTL() time.Duration {
+	if p.cacheTTL > 0 {
+		return p.cacheTTL
+	}
+	if ttl, ok := parseCacheTTL(p.getConfiguration().CacheTtl); ok {
+		return ttl
+	}
 	return 5 * time.Minute
 }
 
@@ -246,7 +1013,7 @@ func (p *Plugin) getCached(key string) (interface{}, bool) {
 	if !ok {
 		return nil, false
 	}
-	if time.Since(entry.FetchedAt) > p.getCacheTTL() {
+	if time.Since(entry.FetchedAt) > entry.TTL {
 		return nil, false
 	}
 	return entry.Data, true
@@ -256,12 +1023,19 @@ func (p *Plugin) setCache(key string, data interface{}) {
 	p.cacheLock.Lock()
 	defer p.cacheLock.Unlock()
 
+	// Entries expire up to a fifth early, at random, so providers fetched together
+	// drift apart and later polls refresh a few at a time rather than all at once
 	p.cache[key] = &CacheEntry{
 		Data:      data,
 		FetchedAt: time.Now(),
+		TTL:       jitter(p.cacheTTLFor(key), 0.2),
 	}
 }
 
 func main() {
+	// "collect" runs the binary as a standalone collector rather than as the plugin
+	if len(os.Args) > 1 && os.Args[1] == "collect" {
+		os.Exit(runCollector(os.Args[2:]))
+	}
 	plugin.ClientMain(&Plugin{})
 }
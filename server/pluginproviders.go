@@ -0,0 +1,433 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Providers registered by other plugins =====
+
+const (
+	pluginProvidersKey = "plugin_providers"
+	pluginProvidersMax = 50
+	// pluginProvidersAttempts bounds the retries of a registry update that raced another
+	pluginProvidersAttempts = 5
+	// Pushed reports older than this are flagged, since the reporting plugin may be gone
+	pluginReportStaleAfter = 24 * time.Hour
+)
+
+// pluginProvider is a provider another plugin registered through the inter-plugin API. It is
+// either pulled, by calling FetchPath on the owning plugin each refresh, or pushed, by the
+// plugin sending reports whenever its numbers change.
+type pluginProvider struct {
+	PluginID     string        `json:"pluginId"`
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	FetchPath    string        `json:"fetchPath,omitempty"`
+	RegisteredAt string        `json:"registeredAt"`
+	Report       *pluginReport `json:"report,omitempty"`
+	ReportedAt   string        `json:"reportedAt,omitempty"`
+}
+
+// pluginReport is the state of a plugin provider, as returned from its fetch path or pushed.
+// Status is derived from used and limit when the plugin doesn't set it.
+type pluginReport struct {
+	Status    string   `json:"status,omitempty"` // "ok", "warning" or "error"
+	Summary   string   `json:"summary,omitempty"`
+	Used      *float64 `json:"used,omitempty"`
+	Limit     *float64 `json:"limit,omitempty"`
+	Remaining *float64 `json:"remaining,omitempty"`
+	Unit      string   `json:"unit,omitempty"` // "requests", "tokens", "credits" or "cost"
+	Currency  string   `json:"currency,omitempty"`
+	ResetAt   string   `json:"resetAt,omitempty"`
+	Error     string   `json:"error,omitempty"` // shown as the card's error
+}
+
+// PluginProviderInfo is a plugin provider's latest report.
+type PluginProviderInfo struct {
+	PluginID  string   `json:"pluginId"`
+	Mode      string   `json:"mode"` // "pull" or "push"
+	Summary   string   `json:"summary,omitempty"`
+	Used      *float64 `json:"used,omitempty"`
+	Limit     *float64 `json:"limit,omitempty"`
+	Remaining *float64 `json:"remaining,omitempty"`
+	Unit      string   `json:"unit"`
+	Currency  string   `json:"currency,omitempty"`
+	ResetAt   string   `json:"resetAt,omitempty"`
+	UpdatedAt string   `json:"updatedAt"`
+	Stale     bool     `json:"stale,omitempty"`
+}
+
+// usagePercent is the share of the limit used, if both are known.
+func (i PluginProviderInfo) usagePercent() (float64, bool) {
+	used, ok := i.used()
+	if !ok || i.Limit == nil || *i.Limit <= 0 {
+		return 0, false
+	}
+	return used / *i.Limit * 100, true
+}
+
+// used is the amount used, or what the limit and remaining amount imply.
+func (i PluginProviderInfo) used() (float64, bool) {
+	switch {
+	case i.Used != nil:
+		return *i.Used, true
+	case i.Remaining != nil && i.Limit != nil:
+		return *i.Limit - *i.Remaining, true
+	}
+	return 0, false
+}
+
+func (i PluginProviderInfo) summary(locale string) string {
+	text := i.Summary
+	if text == "" {
+		used, hasUsed := i.used()
+		switch {
+		case i.Unit == "cost" && hasUsed && i.Limit != nil:
+			text = translate(locale, "summary.budget_used", formatMoney(used, i.Currency, 2), formatMoney(*i.Limit, i.Currency, 0))
+		case i.Unit == "cost" && i.Remaining != nil:
+			text = translate(locale, "summary.credit_balance", formatMoney(*i.Remaining, i.Currency, 2))
+		case i.Unit == "cost" && hasUsed:
+			text = translate(locale, "summary.spent_total", formatMoney(used, i.Currency, 2))
+		case hasUsed && i.Limit != nil:
+			text = translate(locale, "summary.custom", formatCount(*i.Limit-used), formatCount(*i.Limit), i.Unit)
+		case i.Remaining != nil:
+			text = translate(locale, "summary.custom_remaining", formatCount(*i.Remaining), i.Unit)
+		case hasUsed:
+			text = translate(locale, "summary.custom_used", formatCount(used), i.Unit)
+		}
+	}
+	if i.Stale {
+		text += " " + translate(locale, "summary.plugin_provider_stale", i.UpdatedAt[:min(len(i.UpdatedAt), 10)])
+	}
+	return strings.TrimSpace(text)
+}
+
+func (i PluginProviderInfo) compact(name, locale string) string {
+	switch pct, ok := i.usagePercent(); {
+	case ok:
+		return fmt.Sprintf("%s %.0f%%", name, pct)
+	case i.Remaining != nil && i.Unit == "cost":
+		return translate(locale, "compact.balance_left", name, formatMoney(*i.Remaining, i.Currency, 0))
+	case i.Remaining != nil:
+		return fmt.Sprintf("%s %s %s", name, formatCount(*i.Remaining), i.Unit)
+	default:
+		return name
+	}
+}
+
+func (i PluginProviderInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "quota_reset", At: parseTime(i.ResetAt)}}
+}
+
+func (r pluginProvider) statusID() string {
+	return "plugin:" + r.PluginID + ":" + r.ID
+}
+
+// handlePluginProviders serves the inter-plugin API. Mattermost sets Mattermost-Plugin-ID on
+// requests made with PluginHTTP and strips it from everything else, so it identifies the
+// calling plugin, and a plugin can only manage its own providers.
+func (p *Plugin) handlePluginProviders(w http.ResponseWriter, r *http.Request, pluginID string) {
+	if !p.getConfiguration().PluginProvidersEnabled {
+		http.Error(w, `{"error": "disabled", "message": "Providers from other plugins are not enabled"}`, http.StatusForbidden)
+		return
+	}
+	switch {
+	case r.URL.Path == "/api/v1/providers/register" && r.Method == http.MethodPost:
+		p.handleRegisterPluginProvider(w, r, pluginID)
+	case r.URL.Path == "/api/v1/providers/register" && r.Method == http.MethodDelete:
+		p.handleUnregisterPluginProvider(w, r, pluginID)
+	case r.URL.Path == "/api/v1/providers/report" && r.Method == http.MethodPost:
+		p.handlePluginProviderReport(w, r, pluginID)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *Plugin) handleRegisterPluginProvider(w http.ResponseWriter, r *http.Request, pluginID string) {
+	var body struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		FetchPath string `json:"fetchPath"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16*1024)).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	switch {
+	case !customIDPattern.MatchString(body.ID):
+		http.Error(w, `{"error": "invalid_id", "message": "id must be lowercase letters, digits, - or _"}`, http.StatusBadRequest)
+		return
+	case body.Name == "" || len(body.Name) > 100:
+		http.Error(w, `{"error": "invalid_name", "message": "name must be between 1 and 100 characters"}`, http.StatusBadRequest)
+		return
+	case body.FetchPath != "" && !strings.HasPrefix(body.FetchPath, "/"):
+		http.Error(w, `{"error": "invalid_fetch_path", "message": "fetchPath must start with /"}`, http.StatusBadRequest)
+		return
+	}
+
+	reg := pluginProvider{PluginID: pluginID, ID: body.ID, Name: body.Name, FetchPath: body.FetchPath}
+	err := p.updatePluginProviders(func(registry map[string]pluginProvider) error {
+		existing, known := registry[reg.statusID()]
+		if !known && len(registry) >= pluginProvidersMax {
+			return errTooManyPluginProviders
+		}
+		entry := reg
+		entry.RegisteredAt = time.Now().UTC().Format(time.RFC3339)
+		if known && entry.FetchPath == "" {
+			// Re-registering on activation keeps the last pushed report
+			entry.Report, entry.ReportedAt = existing.Report, existing.ReportedAt
+		}
+		registry[entry.statusID()] = entry
+		return nil
+	})
+	switch {
+	case errors.Is(err, errTooManyPluginProviders):
+		http.Error(w, `{"error": "too_many_providers", "message": "Too many providers registered by plugins"}`, http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, `{"error": "kv_error", "message": "Failed to save the registration"}`, http.StatusInternalServerError)
+		return
+	}
+	p.API.LogInfo("Plugin registered an AI limits provider", "plugin_id", pluginID, "provider", reg.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": reg.statusID()})
+}
+
+func (p *Plugin) handleUnregisterPluginProvider(w http.ResponseWriter, r *http.Request, pluginID string) {
+	id := pluginProvider{PluginID: pluginID, ID: r.URL.Query().Get("id")}.statusID()
+	err := p.updatePluginProviders(func(registry map[string]pluginProvider) error {
+		if _, ok := registry[id]; !ok {
+			return errPluginProviderNotFound
+		}
+		delete(registry, id)
+		return nil
+	})
+	switch {
+	case errors.Is(err, errPluginProviderNotFound):
+		http.Error(w, `{"error": "not_found", "message": "Provider not registered"}`, http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, `{"error": "kv_error", "message": "Failed to save the registration"}`, http.StatusInternalServerError)
+		return
+	}
+	p.cacheLock.Lock()
+	delete(p.cache, id)
+	p.cacheLock.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *Plugin) handlePluginProviderReport(w http.ResponseWriter, r *http.Request, pluginID string) {
+	var body struct {
+		ID string `json:"id"`
+		pluginReport
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16*1024)).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validatePluginReport(&body.pluginReport); err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "invalid_report", "message": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+
+	id := pluginProvider{PluginID: pluginID, ID: body.ID}.statusID()
+	err := p.updatePluginProviders(func(registry map[string]pluginProvider) error {
+		reg, ok := registry[id]
+		if !ok {
+			return errPluginProviderNotFound
+		}
+		reg.Report, reg.ReportedAt = &body.pluginReport, time.Now().UTC().Format(time.RFC3339)
+		registry[id] = reg
+		return nil
+	})
+	switch {
+	case errors.Is(err, errPluginProviderNotFound):
+		http.Error(w, `{"error": "not_found", "message": "Register the provider before reporting"}`, http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, `{"error": "kv_error", "message": "Failed to save the report"}`, http.StatusInternalServerError)
+		return
+	}
+	// Show the new report on the next refresh rather than after the cache expires
+	p.cacheLock.Lock()
+	delete(p.cache, id)
+	p.cacheLock.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validatePluginReport(r *pluginReport) error {
+	switch r.Status {
+	case "", "ok", "warning", "error":
+	default:
+		return fmt.Errorf("status must be ok, warning or error")
+	}
+	switch r.Unit {
+	case "", "requests", "tokens", "credits", "cost":
+	default:
+		return fmt.Errorf("unit must be requests, tokens, credits or cost")
+	}
+	if r.ResetAt != "" && parseTime(r.ResetAt).IsZero() {
+		return fmt.Errorf("resetAt must be an RFC 3339 time")
+	}
+	if len(r.Summary) > 200 || len(r.Error) > 200 {
+		return fmt.Errorf("summary and error must be at most 200 characters")
+	}
+	return nil
+}
+
+func (p *Plugin) getPluginProviders() map[string]pluginProvider {
+	registry := map[string]pluginProvider{}
+	if data, appErr := p.API.KVGet(pluginProvidersKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &registry)
+	}
+	return registry
+}
+
+var (
+	errPluginProviderNotFound = errors.New("provider not registered")
+	errTooManyPluginProviders = errors.New("too many providers registered by plugins")
+)
+
+// updatePluginProviders applies update to the registry and saves it. The registry is only
+// replaced if nobody changed it since it was read, and is otherwise read again, so
+// registrations and reports sent at the same time by other plugins, or to other servers of
+// the cluster, aren't lost.
+func (p *Plugin) updatePluginProviders(update func(registry map[string]pluginProvider) error) error {
+	for attempt := 0; attempt < pluginProvidersAttempts; attempt++ {
+		old, appErr := p.API.KVGet(pluginProvidersKey)
+		if appErr != nil {
+			return appErr
+		}
+		registry := map[string]pluginProvider{}
+		if old != nil {
+			json.Unmarshal(old, &registry)
+		}
+		if err := update(registry); err != nil {
+			return err
+		}
+		data, _ := json.Marshal(registry)
+		saved, appErr := p.API.KVCompareAndSet(pluginProvidersKey, old, data)
+		if appErr != nil {
+			return appErr
+		}
+		if saved {
+			return nil
+		}
+	}
+	return fmt.Errorf("the registry kept changing, gave up after %d attempts", pluginProvidersAttempts)
+}
+
+// getPluginProviderStatuses returns one card per registered provider, ordered by name.
+func (p *Plugin) getPluginProviderStatuses(config *Configuration) []ServiceStatus {
+	var regs []pluginProvider
+	for _, reg := range p.getPluginProviders() {
+		regs = append(regs, reg)
+	}
+	sort.Slice(regs, func(i, j int) bool { return regs[i].Name < regs[j].Name })
+
+	var statuses []ServiceStatus
+	for _, reg := range regs {
+		statuses = append(statuses, p.getPluginProviderStatus(config, reg))
+	}
+	return statuses
+}
+
+func (p *Plugin) getPluginProviderStatus(config *Configuration, reg pluginProvider) ServiceStatus {
+	id, name := reg.statusID(), reg.Name
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	info := PluginProviderInfo{PluginID: reg.PluginID, Mode: "push"}
+	report, updatedAt := reg.Report, reg.ReportedAt
+	if reg.FetchPath != "" {
+		info.Mode = "pull"
+		fetched, err := p.fetchPluginReport(reg)
+		if err != nil {
+			return errorStatus(id, name, "error.plugin_provider", reg.PluginID, err.Error())
+		}
+		report, updatedAt = fetched, time.Now().UTC().Format(time.RFC3339)
+	}
+	if report == nil {
+		return errorStatus(id, name, "error.plugin_provider_no_report", reg.PluginID)
+	}
+	if report.Error != "" {
+		return errorStatus(id, name, "error.plugin_provider", reg.PluginID, report.Error)
+	}
+
+	info.Summary, info.Used, info.Limit, info.Remaining = report.Summary, report.Used, report.Limit, report.Remaining
+	info.Unit, info.ResetAt, info.UpdatedAt = report.Unit, report.ResetAt, updatedAt
+	if info.Unit == "" {
+		info.Unit = "credits"
+	}
+	if info.Unit == "cost" {
+		info.Currency = strings.ToUpper(report.Currency)
+		if info.Currency == "" {
+			info.Currency = baseCurrency
+		}
+	}
+	info.Stale = info.Mode == "push" && time.Since(parseTime(updatedAt)) > pluginReportStaleAfter
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: pluginProviderStatus(info, report.Status, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// pluginProviderStatus uses the status the plugin reported, or the usage against the limit.
+// Stale pushed data is at least a warning.
+func pluginProviderStatus(info PluginProviderInfo, reported string, config *Configuration) string {
+	status := reported
+	if status == "" {
+		status = "ok"
+		if pct, ok := info.usagePercent(); ok {
+			if pct >= 100 {
+				status = "error"
+			} else if pct > config.warningPercent(80) {
+				status = "warning"
+			}
+		}
+	}
+	if info.Stale && status == "ok" {
+		status = "warning"
+	}
+	return status
+}
+
+// fetchPluginReport calls the owning plugin's fetch path over the inter-plugin API.
+func (p *Plugin) fetchPluginReport(reg pluginProvider) (*pluginReport, error) {
+	req, _ := http.NewRequest("GET", "/"+reg.PluginID+reg.FetchPath, nil)
+	req.Header.Set("Accept", "application/json")
+	resp := p.API.PluginHTTP(req)
+	if resp == nil {
+		return nil, fmt.Errorf("plugin unreachable")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var report pluginReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, err
+	}
+	if err := validatePluginReport(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===== Background refresh =====
+
+// defaultPollInterval is how often the background poller refreshes the providers unless
+// configured otherwise.
+const defaultPollInterval = time.Minute
+
+// pollInterval is the configured Background Refresh Interval, between 1 and 60 minutes.
+func (c *Configuration) pollInterval() time.Duration {
+	minutes, err := strconv.Atoi(strings.TrimSpace(c.PollIntervalMinutes))
+	if err != nil || minutes < 1 {
+		return defaultPollInterval
+	}
+	return time.Duration(min(minutes, 60)) * time.Minute
+}
+
+// statusSnapshot is every service's status as of the last background poll, which the
+// dashboard, commands and reports are served from without waiting for any provider.
+type statusSnapshot struct {
+	lock     sync.Mutex
+	services []ServiceStatus
+	at       time.Time
+}
+
+// store replaces the snapshot.
+func (s *statusSnapshot) store(services []ServiceStatus, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.services = append([]ServiceStatus(nil), services...)
+	s.at = now
+}
+
+// load returns a copy of the snapshot when it's younger than maxAge. The copy may be
+// changed, e.g. localized, without touching the snapshot.
+func (s *statusSnapshot) load(maxAge time.Duration, now time.Time) ([]ServiceStatus, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.services == nil || now.Sub(s.at) > maxAge {
+		return nil, false
+	}
+	return append([]ServiceStatus(nil), s.services...), true
+}
+
+// clear drops the snapshot, e.g. once the configuration changed.
+func (s *statusSnapshot) clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.services = nil
+}
+
+// snapshotMaxAge is how old a snapshot may be before requests fetch for themselves: two
+// poll intervals and the job's jitter, so one slow or skipped poll doesn't make them wait.
+// Idle polling lets it expire, so the first request after a quiet spell gets fresh data.
+func (c *Configuration) snapshotMaxAge() time.Duration {
+	return 2*c.pollInterval() + time.Minute
+}
+
+// snapshotStaleMaxAge is how old a snapshot may be and still be served while a fresh one
+// is gathered: a cache TTL past snapshotMaxAge. An older one, e.g. after a quiet spell
+// without polling, is waited for, so the first request after it gets fresh data.
+func (p *Plugin) snapshotStaleMaxAge(config *Configuration) time.Duration {
+	return config.snapshotMaxAge() + p.getCacheTTL()
+}
+
+// pollDue reports whether the configured interval has passed since the last poll. The
+// job runs every minute, so the interval is rounded to whole minutes.
+func (p *Plugin) pollDue(now time.Time) bool {
+	interval := p.getConfiguration().pollInterval()
+	// Leave slack for the job's jitter, which may run it a few seconds early
+	if !p.lastPoll.IsZero() && now.Sub(p.lastPoll) < interval-10*time.Second {
+		return false
+	}
+	p.lastPoll = now
+	return true
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Portkey AI gateway (admin API key) =====
+
+// portkeyMaxWorkspaces bounds the workspaces queried, two analytics requests each.
+const portkeyMaxWorkspaces = 10
+
+// PortkeyInfo is the month's requests and cost through the gateway, per workspace, with each
+// workspace's budget.
+type PortkeyInfo struct {
+	Workspaces     []PortkeyWorkspace `json:"workspaces"`
+	TotalRequests  float64            `json:"totalRequests"`
+	TotalCost      float64            `json:"totalCost"`
+	Currency       string             `json:"currency"`
+	Period         string             `json:"period"`
+	CycleEnd       string             `json:"cycleEnd"`
+	DaysUntilReset int                `json:"daysUntilReset"`
+	Partial        bool               `json:"partial,omitempty"` // more workspaces than are queried
+}
+
+// PortkeyWorkspace is one workspace's usage this month. Slug is empty for the API key's own
+// workspace when it can't list workspaces.
+type PortkeyWorkspace struct {
+	Slug     string  `json:"slug"`
+	Name     string  `json:"name"`
+	Requests float64 `json:"requests"`
+	Cost     float64 `json:"cost"`
+	Budget   float64 `json:"budget,omitempty"`
+}
+
+// portkeyWorkspacesResponse is GET /v1/admin/workspaces.
+type portkeyWorkspacesResponse struct {
+	Data []struct {
+		ID   string `json:"id" schema:"required"`
+		Slug string `json:"slug" schema:"required"`
+		Name string `json:"name"`
+	} `json:"data" schema:"required"`
+}
+
+// portkeyGraphResponse is GET /v1/analytics/graphs/{requests,cost}. Costs are in cents.
+type portkeyGraphResponse struct {
+	Summary struct {
+		Total flexFloat `json:"total" schema:"required"`
+	} `json:"summary" schema:"required"`
+}
+
+// portkeyBudgets parses the configured per-workspace budgets, e.g. "prod=500, research=200 EUR".
+func (p *Plugin) portkeyBudgets(config *Configuration) map[string]float64 {
+	budgets := map[string]float64{}
+	for _, item := range splitList(config.PortkeyBudgets) {
+		slug, amount, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		budgets[strings.TrimSpace(slug)] = p.budgetIn(config, "portkey", amount, baseCurrency)
+	}
+	return budgets
+}
+
+func (p *Plugin) getPortkeyStatus(config *Configuration) ServiceStatus {
+	const id, name = "portkey", "Portkey"
+	if config.PortkeyApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 30*time.Second)
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := PortkeyInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+
+	var drift schemaDrift
+	var workspaces []PortkeyWorkspace
+	if slugs := splitList(config.PortkeyWorkspaces); len(slugs) > 0 {
+		for _, slug := range slugs {
+			workspaces = append(workspaces, PortkeyWorkspace{Slug: slug, Name: slug})
+		}
+	} else {
+		// Workspace-scoped keys can't list workspaces and report on their own instead
+		body, err := p.portkeyGet(client, config, "/v1/admin/workspaces", nil)
+		if err == nil {
+			var list portkeyWorkspacesResponse
+			d, err := decodeResponse(body, &list)
+			if err != nil {
+				return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+			}
+			drift.merge(d)
+			for _, w := range list.Data {
+				workspaces = append(workspaces, PortkeyWorkspace{Slug: w.Slug, Name: w.Name})
+			}
+		}
+		if len(workspaces) == 0 {
+			workspaces = []PortkeyWorkspace{{}}
+		}
+	}
+	if len(workspaces) > portkeyMaxWorkspaces {
+		workspaces, info.Partial = workspaces[:portkeyMaxWorkspaces], true
+	}
+
+	budgets := p.portkeyBudgets(config)
+	for i := range workspaces {
+		w := &workspaces[i]
+		query := neturl.Values{
+			"time_of_generation_min": {monthStart.Format(time.RFC3339)},
+			"time_of_generation_max": {now.Format(time.RFC3339)},
+		}
+		if w.Slug != "" {
+			query.Set("workspace_slug", w.Slug)
+		}
+		for _, graph := range []string{"requests", "cost"} {
+			body, err := p.portkeyGet(client, config, "/v1/analytics/graphs/"+graph, query)
+			if err != nil {
+				return errorStatus(id, name, "error.api", err.Error())
+			}
+			var result portkeyGraphResponse
+			d, err := decodeResponse(body, &result)
+			if err != nil {
+				return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+			}
+			drift.merge(d)
+			if graph == "requests" {
+				w.Requests = float64(result.Summary.Total)
+			} else {
+				w.Cost = float64(result.Summary.Total) / 100
+			}
+		}
+		w.Budget = budgets[w.Slug]
+		info.TotalRequests += w.Requests
+		info.TotalCost += w.Cost
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Cost > workspaces[j].Cost })
+	info.Workspaces = workspaces
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: portkeyStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// portkeyStatus is the worst budget status of the workspaces.
+func portkeyStatus(info PortkeyInfo, config *Configuration) string {
+	status := "ok"
+	for _, w := range info.Workspaces {
+		switch budgetStatus(w.Cost, w.Budget, config) {
+		case "error":
+			return "error"
+		case "warning":
+			status = "warning"
+		}
+	}
+	return status
+}
+
+// mostConstrained returns the workspace closest to its budget, if any has one.
+func (i PortkeyInfo) mostConstrained() (PortkeyWorkspace, bool) {
+	var worst PortkeyWorkspace
+	found := false
+	for _, w := range i.Workspaces {
+		if w.Budget > 0 && (!found || w.Cost/w.Budget > worst.Cost/worst.Budget) {
+			worst, found = w, true
+		}
+	}
+	return worst, found
+}
+
+func (i PortkeyInfo) summary(locale string) string {
+	text := translate(locale, "summary.portkey", formatCount(i.TotalRequests), formatMoney(i.TotalCost, i.Currency, 2), i.Period)
+	if worst, ok := i.mostConstrained(); ok {
+		text += ", " + translate(locale, "summary.worst_spender", worst.Name, formatMoney(worst.Cost, i.Currency, 2), formatMoney(worst.Budget, i.Currency, 0))
+	}
+	return text
+}
+
+func (i PortkeyInfo) compact(name, locale string) string {
+	if worst, ok := i.mostConstrained(); ok {
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(worst.Cost, i.Currency, 0), formatMoney(worst.Budget, i.Currency, 0))
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(i.TotalCost, i.Currency, 0))
+}
+
+func (i PortkeyInfo) usagePercent() (float64, bool) {
+	if worst, ok := i.mostConstrained(); ok {
+		return worst.Cost / worst.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (i PortkeyInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(i.CycleEnd)}}
+}
+
+// portkeyGet sends a request and returns the body of a successful response.
+func (p *Plugin) portkeyGet(client *http.Client, config *Configuration, path string, query neturl.Values) ([]byte, error) {
+	resp, err := client.Do(newPortkeyRequest(config, path, query))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+func newPortkeyRequest(config *Configuration, path string, query neturl.Values) *http.Request {
+	url := "https://api.portkey.ai" + path
+	if len(query) > 0 {
+		url += "?" + query.Encode()
+	}
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("x-portkey-api-key", config.PortkeyApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ===== Per-user dashboard preferences =====
+
+// UserPreferences are per-user dashboard settings stored in the KV store,
+// so they follow the user across devices.
+type UserPreferences struct {
+	CardOrder       []string `json:"cardOrder"`
+	HiddenProviders []string `json:"hiddenProviders"`
+	RefreshInterval int      `json:"refreshInterval"` // seconds, 0 = default
+	Units           string   `json:"units"`           // "" (server default), "raw", "cost" or "percent"
+}
+
+const maxPreferenceItems = 100
+
+func preferencesKey(userID string) string {
+	return "prefs_" + userID
+}
+
+func (p *Plugin) getUserPreferences(userID string) UserPreferences {
+	prefs := UserPreferences{CardOrder: []string{}, HiddenProviders: []string{}}
+	data, appErr := p.API.KVGet(preferencesKey(userID))
+	if appErr != nil || data == nil {
+		return prefs
+	}
+	json.Unmarshal(data, &prefs)
+	return prefs
+}
+
+func (prefs *UserPreferences) validate() error {
+	if len(prefs.CardOrder) > maxPreferenceItems || len(prefs.HiddenProviders) > maxPreferenceItems {
+		return fmt.Errorf("too many providers listed")
+	}
+	if prefs.RefreshInterval != 0 && (prefs.RefreshInterval < 60 || prefs.RefreshInterval > 3600) {
+		return fmt.Errorf("refreshInterval must be between 60 and 3600 seconds")
+	}
+	if prefs.Units != "" && !validUnits(prefs.Units) {
+		return fmt.Errorf("units must be one of raw, cost, percent")
+	}
+	if prefs.CardOrder == nil {
+		prefs.CardOrder = []string{}
+	}
+	if prefs.HiddenProviders == nil {
+		prefs.HiddenProviders = []string{}
+	}
+	return nil
+}
+
+func (p *Plugin) handleGetPreferences(w http.ResponseWriter, r *http.Request, userID string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.getUserPreferences(userID))
+}
+
+func (p *Plugin) handlePutPreferences(w http.ResponseWriter, r *http.Request, userID string) {
+	var prefs UserPreferences
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&prefs); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	if err := prefs.validate(); err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "invalid_preferences", "message": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+
+	data, _ := json.Marshal(prefs)
+	if appErr := p.API.KVSet(preferencesKey(userID), data); appErr != nil {
+		http.Error(w, `{"error": "kv_error", "message": "Failed to save preferences"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Usage metrics for Prometheus =====
+
+// usageWindows are the utilization of a service's usage windows, in percent, for services
+// with more than one.
+func usageWindows(s ServiceStatus) map[string]float64 {
+	switch d := s.Data.(type) {
+	case ClaudeUsageInfo:
+		if !d.HasData {
+			return nil
+		}
+		windows := map[string]float64{"5h": d.Utilization5h, "7d": d.Utilization7d}
+		if d.SonnetUtil > 0 {
+			windows["7d_sonnet"] = d.SonnetUtil
+		}
+		if d.OpusUtil > 0 {
+			windows["7d_opus"] = d.OpusUtil
+		}
+		return windows
+	case CodexUsageInfo:
+		if !d.HasData {
+			return nil
+		}
+		return map[string]float64{"primary": d.PrimaryUsed, "weekly": d.WeeklyUsed}
+	}
+	return nil
+}
+
+// metricsToken is the token a scrape presents, as a bearer token or in the query.
+func metricsToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("token")
+}
+
+// handleUsageMetrics serves GET /api/v1/metrics: every enabled card's usage as Prometheus
+// gauges, for scrapers that authenticate with the Metrics Token rather than a session.
+func (p *Plugin) handleUsageMetrics(w http.ResponseWriter, r *http.Request) {
+	config := p.getConfiguration()
+	if config.MetricsToken == "" || subtle.ConstantTimeCompare([]byte(metricsToken(r)), []byte(config.MetricsToken)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	var services []ServiceStatus
+	for _, s := range p.collectStatuses() {
+		if s.Enabled {
+			services = append(services, s)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].ID < services[j].ID })
+	now := time.Now()
+
+	type sample struct {
+		labels string
+		value  float64
+	}
+	type metric struct {
+		name, help string
+		samples    []sample
+	}
+	metrics := []*metric{
+		{name: "ai_limits_up", help: "Whether the card's last fetch succeeded (1) or failed (0)."},
+		{name: "ai_limits_status", help: "Card status: 1 ok, 2 warning, 3 error, unreachable or reauthorization needed."},
+		{name: "ai_limits_utilization", help: "Share of the limit used, from 0 to 1; window is empty for the card's headline figure."},
+		{name: "ai_limits_used", help: "Usage in the card's own unit."},
+		{name: "ai_limits_limit", help: "Limit in the card's own unit."},
+		{name: "ai_limits_remaining", help: "What's left of the limit in the card's own unit."},
+		{name: "ai_limits_cost", help: "Spend in the billing cycle."},
+		{name: "ai_limits_cost_limit", help: "Budget for the billing cycle."},
+		{name: "ai_limits_reset_timestamp_seconds", help: "Unix time the card's window, quota or billing cycle resets next."},
+	}
+	up, status, utilization, used, limit, remaining, cost, costLimit, resets :=
+		metrics[0], metrics[1], metrics[2], metrics[3], metrics[4], metrics[5], metrics[6], metrics[7], metrics[8]
+
+	for _, s := range services {
+		labels := fmt.Sprintf("provider=%q,card=%q", cacheKeyProvider(s.ID), s.ID)
+		if s.Error != "" && !s.Stale {
+			up.samples = append(up.samples, sample{labels, 0})
+		} else {
+			up.samples = append(up.samples, sample{labels, 1})
+		}
+		status.samples = append(status.samples, sample{labels, float64(statusSeverity(s.Status))})
+		if s.Error != "" && !s.Stale {
+			continue
+		}
+
+		if m := computeUsage(s); m != nil {
+			if m.Percent != nil {
+				utilization.samples = append(utilization.samples, sample{labels + `,window=""`, *m.Percent / 100})
+			}
+			unit := labels + fmt.Sprintf(",unit=%q", m.Unit)
+			if m.Used != nil {
+				used.samples = append(used.samples, sample{unit, *m.Used})
+			}
+			if m.Limit != nil {
+				limit.samples = append(limit.samples, sample{unit, *m.Limit})
+				if m.Used != nil {
+					remaining.samples = append(remaining.samples, sample{unit, max(*m.Limit-*m.Used, 0)})
+				}
+			}
+			currency := labels + fmt.Sprintf(",currency=%q", m.Currency)
+			if m.Cost != nil {
+				cost.samples = append(cost.samples, sample{currency, *m.Cost})
+			}
+			if m.CostLimit != nil {
+				costLimit.samples = append(costLimit.samples, sample{currency, *m.CostLimit})
+			}
+		}
+
+		windows := usageWindows(s)
+		names := make([]string, 0, len(windows))
+		for name := range windows {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			utilization.samples = append(utilization.samples, sample{labels + fmt.Sprintf(",window=%q", name), windows[name] / 100})
+		}
+
+		for _, reset := range serviceResets(s, now) {
+			resets.samples = append(resets.samples, sample{labels + fmt.Sprintf(",kind=%q", reset.Kind), float64(reset.At.Unix())})
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, s := range m.samples {
+			fmt.Fprintf(w, "%s{%s} %g\n", m.name, s.labels, s.value)
+		}
+	}
+}
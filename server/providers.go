@@ -3,171 +3,913 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	neturl "net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// ===== Provider table =====
+
+// provider describes a monitored service. Fetch is only called when Enabled and
+// returns one status per configured instance (e.g. per OpenAI organization). Services
+// implementing Provider are listed with providerEntry, see registry.go.
+type provider struct {
+	ID      string
+	Name    string
+	Enabled func(config *Configuration) bool
+	Fetch   func(p *Plugin, config *Configuration) []ServiceStatus
+}
+
+// single adapts a provider that always has exactly one instance.
+func single(fetch func(p *Plugin, config *Configuration) ServiceStatus) func(p *Plugin, config *Configuration) []ServiceStatus {
+	return func(p *Plugin, config *Configuration) []ServiceStatus {
+		return []ServiceStatus{fetch(p, config)}
+	}
+}
+
+// providers lists the supported services in their default display order.
+var providers = []provider{
+	providerEntry(augmentProvider{}),
+	providerEntry(zaiProvider{}),
+	{
+		ID: "openai", Name: "OpenAI",
+		Enabled: func(c *Configuration) bool { return c.OpenaiEnabled },
+		Fetch:   (*Plugin).getOpenAIStatuses,
+	},
+	providerEntry(claudeProvider{}),
+	{
+		ID: "anthropic", Name: "Anthropic API",
+		Enabled: func(c *Configuration) bool { return c.AnthropicEnabled },
+		Fetch:   single((*Plugin).getAnthropicStatus),
+	},
+	{
+		ID: "copilot", Name: "GitHub Copilot",
+		Enabled: func(c *Configuration) bool { return c.CopilotEnabled },
+		Fetch:   single((*Plugin).getCopilotStatus),
+	},
+	{
+		ID: "cursor", Name: "Cursor",
+		Enabled: func(c *Configuration) bool { return c.CursorEnabled },
+		Fetch:   single((*Plugin).getCursorStatus),
+	},
+	{
+		ID: "mistral", Name: "Mistral",
+		Enabled: func(c *Configuration) bool { return c.MistralEnabled },
+		Fetch:   single((*Plugin).getMistralStatus),
+	},
+	{
+		ID: "groq", Name: "Groq",
+		Enabled: func(c *Configuration) bool { return c.GroqEnabled },
+		Fetch:   single((*Plugin).getGroqStatus),
+	},
+	{
+		ID: "deepseek", Name: "DeepSeek",
+		Enabled: func(c *Configuration) bool { return c.DeepseekEnabled },
+		Fetch:   single((*Plugin).getDeepSeekStatus),
+	},
+	{
+		ID: "perplexity", Name: "Perplexity",
+		Enabled: func(c *Configuration) bool { return c.PerplexityEnabled },
+		Fetch:   single((*Plugin).getPerplexityStatus),
+	},
+	{
+		ID: "together", Name: "Together AI",
+		Enabled: func(c *Configuration) bool { return c.TogetherEnabled },
+		Fetch:   single((*Plugin).getTogetherStatus),
+	},
+	{
+		ID: "bedrock", Name: "AWS Bedrock",
+		Enabled: func(c *Configuration) bool { return c.BedrockEnabled },
+		Fetch:   single((*Plugin).getBedrockStatus),
+	},
+	{
+		ID: "vertex", Name: "Google Vertex AI",
+		Enabled: func(c *Configuration) bool { return c.VertexEnabled },
+		Fetch:   single((*Plugin).getVertexStatus),
+	},
+	providerEntry(geminiProvider{}),
+	providerEntry(azureOpenAIProvider{}),
+	{
+		ID: "xai", Name: "xAI",
+		Enabled: func(c *Configuration) bool { return c.XaiEnabled },
+		Fetch:   single((*Plugin).getXaiStatus),
+	},
+	{
+		ID: "windsurf", Name: "Windsurf",
+		Enabled: func(c *Configuration) bool { return c.WindsurfEnabled },
+		Fetch:   single((*Plugin).getWindsurfStatus),
+	},
+	{
+		ID: "tabnine", Name: "Tabnine",
+		Enabled: func(c *Configuration) bool { return c.TabnineEnabled },
+		Fetch:   single((*Plugin).getTabnineStatus),
+	},
+	{
+		ID: "jetbrains", Name: "JetBrains AI",
+		Enabled: func(c *Configuration) bool { return c.JetbrainsEnabled },
+		Fetch:   single((*Plugin).getJetBrainsStatus),
+	},
+	{
+		ID: "cerebras", Name: "Cerebras",
+		Enabled: func(c *Configuration) bool { return c.CerebrasEnabled },
+		Fetch:   single((*Plugin).getCerebrasStatus),
+	},
+	{
+		ID: "sambanova", Name: "SambaNova",
+		Enabled: func(c *Configuration) bool { return c.SambanovaEnabled },
+		Fetch:   single((*Plugin).getSambaNovaStatus),
+	},
+	{
+		ID: "selfhosted", Name: "Self-hosted LLM",
+		Enabled: func(c *Configuration) bool { return c.SelfhostedEnabled },
+		Fetch:   single((*Plugin).getSelfHostedStatus),
+	},
+	{
+		ID: "litellm", Name: "LiteLLM",
+		Enabled: func(c *Configuration) bool { return c.LitellmEnabled },
+		Fetch:   single((*Plugin).getLiteLLMStatus),
+	},
+	{
+		ID: "helicone", Name: "Helicone",
+		Enabled: func(c *Configuration) bool { return c.HeliconeEnabled },
+		Fetch:   single((*Plugin).getHeliconeStatus),
+	},
+	{
+		ID: "gateway", Name: defaultGatewayName,
+		Enabled: func(c *Configuration) bool { return c.GatewayEnabled },
+		Fetch:   single((*Plugin).getGatewayStatus),
+	},
+	{
+		ID: "elevenlabs", Name: "ElevenLabs",
+		Enabled: func(c *Configuration) bool { return c.ElevenlabsEnabled },
+		Fetch:   single((*Plugin).getElevenLabsStatus),
+	},
+	{
+		ID: "assemblyai", Name: "AssemblyAI",
+		Enabled: func(c *Configuration) bool { return c.AssemblyaiEnabled },
+		Fetch:   single((*Plugin).getAssemblyAIStatus),
+	},
+	{
+		ID: "supermaven", Name: "Supermaven",
+		Enabled: func(c *Configuration) bool { return c.SupermavenEnabled },
+		Fetch:   single((*Plugin).getSupermavenStatus),
+	},
+	{
+		ID: "amazonq", Name: "Amazon Q Developer",
+		Enabled: func(c *Configuration) bool { return c.AmazonqEnabled },
+		Fetch:   single((*Plugin).getAmazonQStatus),
+	},
+	{
+		ID: "moonshot", Name: "Moonshot AI",
+		Enabled: func(c *Configuration) bool { return c.MoonshotEnabled },
+		Fetch:   single((*Plugin).getMoonshotStatus),
+	},
+	{
+		ID: "dashscope", Name: "Alibaba DashScope",
+		Enabled: func(c *Configuration) bool { return c.DashscopeEnabled },
+		Fetch:   single((*Plugin).getDashScopeStatus),
+	},
+	{
+		ID: "ai21", Name: "AI21 Labs",
+		Enabled: func(c *Configuration) bool { return c.Ai21Enabled },
+		Fetch:   single((*Plugin).getAI21Status),
+	},
+	{
+		ID: "fal", Name: "fal.ai",
+		Enabled: func(c *Configuration) bool { return c.FalEnabled },
+		Fetch:   single((*Plugin).getFalStatus),
+	},
+	{
+		ID: "runpod", Name: "RunPod",
+		Enabled: func(c *Configuration) bool { return c.RunpodEnabled },
+		Fetch:   single((*Plugin).getRunPodStatus),
+	},
+	{
+		ID: "yandex", Name: "Yandex Foundation Models",
+		Enabled: func(c *Configuration) bool { return c.YandexEnabled },
+		Fetch:   single((*Plugin).getYandexStatus),
+	},
+	{
+		ID: "gigachat", Name: "GigaChat",
+		Enabled: func(c *Configuration) bool { return c.GigachatEnabled },
+		Fetch:   single((*Plugin).getGigaChatStatus),
+	},
+	{
+		ID: "reka", Name: "Reka AI",
+		Enabled: func(c *Configuration) bool { return c.RekaEnabled },
+		Fetch:   single((*Plugin).getRekaStatus),
+	},
+	{
+		ID: "watsonx", Name: "IBM watsonx.ai",
+		Enabled: func(c *Configuration) bool { return c.WatsonxEnabled },
+		Fetch:   single((*Plugin).getWatsonxStatus),
+	},
+	{
+		ID: "databricks", Name: "Databricks Model Serving",
+		Enabled: func(c *Configuration) bool { return c.DatabricksEnabled },
+		Fetch:   single((*Plugin).getDatabricksStatus),
+	},
+	{
+		ID: "nvidia", Name: "NVIDIA NIM",
+		Enabled: func(c *Configuration) bool { return c.NvidiaEnabled },
+		Fetch:   single((*Plugin).getNvidiaStatus),
+	},
+	providerEntry(vercelGatewayProvider{}),
+	providerEntry(openRouterProvider{}),
+	{
+		ID: "portkey", Name: "Portkey",
+		Enabled: func(c *Configuration) bool { return c.PortkeyEnabled },
+		Fetch:   single((*Plugin).getPortkeyStatus),
+	},
+	{
+		ID: "githubmodels", Name: "GitHub Models",
+		Enabled: func(c *Configuration) bool { return c.GithubModelsEnabled },
+		Fetch:   single((*Plugin).getGithubModelsStatus),
+	},
+	{
+		ID: "codex", Name: "OpenAI Codex",
+		Enabled: func(c *Configuration) bool { return c.CodexEnabled },
+		Fetch:   single((*Plugin).getCodexStatus),
+	},
+	{
+		ID: "chatgpt", Name: "ChatGPT",
+		Enabled: func(c *Configuration) bool { return c.ChatgptEnabled },
+		Fetch:   single((*Plugin).getChatGPTStatus),
+	},
+	{
+		ID: "claudecode", Name: "Claude Code",
+		Enabled: func(c *Configuration) bool { return c.ClaudeCodeEnabled },
+		Fetch:   single((*Plugin).getClaudeCodeStatus),
+	},
+	{
+		ID: "upstage", Name: "Upstage Solar",
+		Enabled: func(c *Configuration) bool { return c.UpstageEnabled },
+		Fetch:   single((*Plugin).getUpstageStatus),
+	},
+	{
+		ID: "aigateway", Name: defaultAIGatewayName,
+		Enabled: func(c *Configuration) bool { return c.AigatewayEnabled },
+		Fetch:   (*Plugin).getAIGatewayStatuses,
+	},
+	{
+		ID: "custom", Name: "Custom providers",
+		Enabled: func(c *Configuration) bool { return c.CustomProvidersEnabled },
+		Fetch:   (*Plugin).getCustomStatuses,
+	},
+	{
+		ID: "plugin", Name: "Plugin providers",
+		Enabled: func(c *Configuration) bool { return c.PluginProvidersEnabled },
+		Fetch:   (*Plugin).getPluginProviderStatuses,
+	},
+	{
+		ID: "federation", Name: "Federated servers",
+		Enabled: func(c *Configuration) bool { return c.FederationEnabled && strings.TrimSpace(c.FederationPeers) != "" },
+		Fetch:   (*Plugin).getPeerStatuses,
+	},
+	{
+		ID: "collector", Name: "Remote collectors",
+		Enabled: func(c *Configuration) bool { return c.CollectorsEnabled },
+		Fetch:   (*Plugin).getCollectorStatuses,
+	},
+}
+
 // ===== Augment Code =====
 
 type AugmentCreditInfo struct {
-	PlanName       string  `json:"planName"`
-	UsageRemaining float64 `json:"usageRemaining"`
-	UsageTotal     float64 `json:"usageTotal"`
-	UsageUsed      float64 `json:"usageUsed"`
-	CycleEnd       string  `json:"cycleEnd"`
-	IsLow          bool    `json:"isLow"`
+	PlanName       string           `json:"planName"`
+	UsageRemaining float64          `json:"usageRemaining"`
+	UsageTotal     float64          `json:"usageTotal"`
+	UsageUsed      float64          `json:"usageUsed"`
+	CycleEnd       string           `json:"cycleEnd"`
+	IsLow          bool             `json:"isLow"`
+	IncludedUnits  float64          `json:"includedUnits,omitempty"` // the plan's allowance per cycle; UsageTotal when set
+	Team           *AugmentTeamPool `json:"team,omitempty"`
 }
 
-func (p *Plugin) getAugmentStatus(config *Configuration) ServiceStatus {
-	if config.AugmentAccessToken == "" {
-		return ServiceStatus{ID: "augment", Name: "Augment Code", Enabled: true, Status: "error", Error: "Access token not configured"}
+func (a AugmentCreditInfo) summary(locale string) string {
+	return translate(locale, "summary.augment", formatCount(a.UsageRemaining), formatCount(a.UsageTotal))
+}
+
+func (a AugmentCreditInfo) compact(name, locale string) string {
+	return translate(locale, "compact.augment_left", name, formatCount(a.UsageRemaining))
+}
+
+func (a AugmentCreditInfo) usagePercent() (float64, bool) {
+	if a.UsageTotal > 0 {
+		return a.UsageUsed / a.UsageTotal * 100, true
 	}
+	return 0, false
+}
+
+func (a AugmentCreditInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "billing_cycle", At: parseTime(a.CycleEnd)}}
+}
+
+// AugmentTeamPool is the shared credit pool of a team or organization account.
+type AugmentTeamPool struct {
+	Name           string             `json:"name,omitempty"`
+	UsageRemaining float64            `json:"usageRemaining"`
+	UsageTotal     float64            `json:"usageTotal"`
+	UsageUsed      float64            `json:"usageUsed"`
+	Seats          []AugmentSeatUsage `json:"seats,omitempty"` // heaviest users first
+}
+
+// AugmentSeatUsage is one member's consumption of the team pool this cycle.
+type AugmentSeatUsage struct {
+	User      string  `json:"user"`
+	UsageUsed float64 `json:"usageUsed"`
+}
+
+type augmentProvider struct{}
 
-	if cached, ok := p.getCached("augment"); ok {
-		return cached.(ServiceStatus)
+func (augmentProvider) ID() string   { return "augment" }
+func (augmentProvider) Name() string { return "Augment Code" }
+
+func (augmentProvider) Enabled(config *Configuration) bool {
+	return config.AugmentEnabled
+}
+
+// EvaluateStatus warns when Augment flags the balance as low, or above the warning
+// threshold of the plan's included credits or the team pool.
+func (augmentProvider) EvaluateStatus(data interface{}, config *Configuration) string {
+	info := data.(AugmentCreditInfo)
+	warn := config.warningPercent(90)
+	if info.IsLow || (info.IncludedUnits > 0 && info.UsageUsed/info.IncludedUnits*100 > warn) {
+		return "warning"
 	}
+	if team := info.Team; team != nil && team.UsageTotal > 0 && team.UsageUsed/team.UsageTotal*100 > warn {
+		return "warning"
+	}
+	return "ok"
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, _ := http.NewRequest("POST", "https://d2.api.augmentcode.com/get-credit-info", strings.NewReader("{}"))
-	req.Header.Set("Authorization", "Bearer "+config.AugmentAccessToken)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+func (a augmentProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	if config.AugmentAccessToken == "" {
+		return nil, errFetch("error.access_token_missing")
+	}
 
-	resp, err := client.Do(req)
+	client := p.providerClient(a.ID(), 10*time.Second)
+	statusCode, body, err := p.fetchAugmentCredits(client, config.AugmentAccessToken)
 	if err != nil {
-		return ServiceStatus{ID: "augment", Name: "Augment Code", Enabled: true, Status: "error", Error: fmt.Sprintf("API error: %v", err)}
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != 200 {
-		return ServiceStatus{ID: "augment", Name: "Augment Code", Enabled: true, Status: "error", Error: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))}
+	if augmentTokenExpired(statusCode, body) {
+		// A referenced token may have been renewed since it was read
+		renewed := renewedAugmentToken(config)
+		if renewed == "" {
+			return nil, errReauth("error.augment_reauth")
+		}
+		p.API.LogInfo("Augment rejected the access token, retrying with the renewed one")
+		if statusCode, body, err = p.fetchAugmentCredits(client, renewed); err != nil {
+			return nil, err
+		}
+		if augmentTokenExpired(statusCode, body) {
+			return nil, errReauth("error.augment_reauth")
+		}
 	}
-	var raw map[string]interface{}
-	if err := json.Unmarshal(body, &raw); err != nil {
-		return ServiceStatus{ID: "augment", Name: "Augment Code", Enabled: true, Status: "error", Error: fmt.Sprintf("Parse error: %v (body: %s)", err, string(body[:min(len(body), 200)]))}
+	if statusCode != 200 {
+		return nil, errFetch("error.http", statusCode, string(body[:min(len(body), 200)]))
+	}
+	var raw augmentCreditResponse
+	drift, err := decodeResponse(body, &raw)
+	if err != nil {
+		return nil, errFetch("error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(a.ID(), drift); err != nil {
+		return nil, errFetch("error.response_format", err.Error())
 	}
 
 	info := AugmentCreditInfo{
-		UsageRemaining: getFloat(raw, "usage_units_remaining"),
-		UsageTotal:     getFloat(raw, "usage_units_total"),
-		CycleEnd:       getString(raw, "current_billing_cycle_end_date_iso"),
+		PlanName:       raw.DisplayInfo.PlanDisplayName,
+		UsageRemaining: float64(raw.UsageUnitsRemaining),
+		UsageTotal:     float64(raw.UsageUnitsTotal),
+		CycleEnd:       raw.CycleEnd,
+		IsLow:          raw.IsCreditBalanceLow,
+		IncludedUnits:  float64(raw.IncludedUsageUnits),
 	}
 	info.UsageUsed = info.UsageTotal - info.UsageRemaining
+	if info.IncludedUnits > 0 {
+		info.UsageTotal = info.IncludedUnits
+		info.UsageUsed = info.IncludedUnits - info.UsageRemaining
+	}
+	info.Team = raw.teamPool()
+	return info, nil
+}
 
-	if display, ok := raw["display_info"].(map[string]interface{}); ok {
-		info.PlanName = getString(display, "plan_display_name")
+// fetchAugmentCredits sends get-credit-info with the token or session of setting.
+func (p *Plugin) fetchAugmentCredits(client *http.Client, setting string) (int, []byte, error) {
+	resp, err := client.Do(newAugmentCreditRequest(setting))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	return resp.StatusCode, body, err
+}
+
+// augmentTokenExpired reports whether Augment rejected the access token itself, as
+// opposed to failing for another reason. 403s only count when the body says so.
+func augmentTokenExpired(statusCode int, body []byte) bool {
+	if statusCode == http.StatusUnauthorized {
+		return true
 	}
-	if isLow, ok := raw["is_credit_balance_low"].(bool); ok {
-		info.IsLow = isLow
+	if statusCode == http.StatusForbidden {
+		text := strings.ToLower(string(body))
+		return strings.Contains(text, "expired") || strings.Contains(text, "invalid token") || strings.Contains(text, "unauthenticated")
 	}
+	return false
+}
 
-	included := getFloat(raw, "included_usage_units_per_billing_cycle")
-	if included > 0 {
-		info.UsageTotal = included
-		info.UsageUsed = included - info.UsageRemaining
+// augmentCreditResponse is the body of get-credit-info. Team, organization and tenant
+// pools are only present on team plans; accounts report at most one of them.
+type augmentCreditResponse struct {
+	UsageUnitsRemaining flexFloat `json:"usage_units_remaining" schema:"required"`
+	UsageUnitsTotal     flexFloat `json:"usage_units_total"`
+	IncludedUsageUnits  flexFloat `json:"included_usage_units_per_billing_cycle"`
+	CycleEnd            string    `json:"current_billing_cycle_end_date_iso"`
+	IsCreditBalanceLow  bool      `json:"is_credit_balance_low"`
+	DisplayInfo         struct {
+		PlanDisplayName string `json:"plan_display_name"`
+	} `json:"display_info"`
+	Team         *augmentPoolResponse `json:"team"`
+	Organization *augmentPoolResponse `json:"organization"`
+	Tenant       *augmentPoolResponse `json:"tenant"`
+}
+
+type augmentPoolResponse struct {
+	Name                string                `json:"name"`
+	UsageUnitsRemaining flexFloat             `json:"usage_units_remaining"`
+	UsageUnitsTotal     flexFloat             `json:"usage_units_total"`
+	IncludedUsageUnits  flexFloat             `json:"included_usage_units_per_billing_cycle"`
+	Members             []augmentSeatResponse `json:"members"`
+	Seats               []augmentSeatResponse `json:"seats"`
+}
+
+type augmentSeatResponse struct {
+	Email          string    `json:"email"`
+	Name           string    `json:"name"`
+	UsageUnitsUsed flexFloat `json:"usage_units_used"`
+}
+
+// teamPool returns the shared credit pool, or nil for personal accounts.
+func (r augmentCreditResponse) teamPool() *AugmentTeamPool {
+	pool := r.Team
+	if pool == nil {
+		pool = r.Organization
+	}
+	if pool == nil {
+		pool = r.Tenant
+	}
+	if pool == nil {
+		return nil
 	}
 
-	status := "ok"
-	if info.IsLow || (included > 0 && info.UsageRemaining/included < 0.1) {
-		status = "warning"
+	team := &AugmentTeamPool{
+		Name:           pool.Name,
+		UsageRemaining: float64(pool.UsageUnitsRemaining),
+		UsageTotal:     float64(pool.UsageUnitsTotal),
+	}
+	if pool.IncludedUsageUnits > 0 {
+		team.UsageTotal = float64(pool.IncludedUsageUnits)
 	}
+	team.UsageUsed = team.UsageTotal - team.UsageRemaining
 
-	result := ServiceStatus{
-		ID: "augment", Name: "Augment Code", Enabled: true, Status: status,
-		Data: info, CachedAt: time.Now().Unix(),
+	seats := pool.Members
+	if len(seats) == 0 {
+		seats = pool.Seats
 	}
-	p.setCache("augment", result)
-	return result
+	for _, seat := range seats {
+		user := seat.Email
+		if user == "" {
+			user = seat.Name
+		}
+		team.Seats = append(team.Seats, AugmentSeatUsage{User: user, UsageUsed: float64(seat.UsageUnitsUsed)})
+	}
+	sort.Slice(team.Seats, func(i, j int) bool { return team.Seats[i].UsageUsed > team.Seats[j].UsageUsed })
+
+	if team.UsageTotal == 0 && len(team.Seats) == 0 {
+		return nil
+	}
+	return team
 }
 
 // ===== Z.AI =====
 
 type ZaiQuotaInfo struct {
-	PlanName     string  `json:"planName"`
-	PlanStatus   string  `json:"planStatus"`
-	TokensUsed   float64 `json:"tokensUsed"`
-	TokensTotal  float64 `json:"tokensTotal"`
-	TokensRemain float64 `json:"tokensRemaining"`
-	NextReset    int64   `json:"nextReset"`
-	McpUsed      float64 `json:"mcpUsed"`
-	McpTotal     float64 `json:"mcpTotal"`
-	McpRemain    float64 `json:"mcpRemaining"`
+	PlanName      string        `json:"planName"`
+	PlanStatus    string        `json:"planStatus"`
+	TokensUsed    float64       `json:"tokensUsed"`
+	TokensTotal   float64       `json:"tokensTotal"`
+	TokensRemain  float64       `json:"tokensRemaining"`
+	NextReset     int64         `json:"nextReset"`
+	McpUsed       float64       `json:"mcpUsed"`
+	McpTotal      float64       `json:"mcpTotal"`
+	McpRemain     float64       `json:"mcpRemaining"`
+	PromptsUsed   float64       `json:"promptsUsed,omitempty"`
+	PromptsTotal  float64       `json:"promptsTotal,omitempty"`
+	PromptsRemain float64       `json:"promptsRemaining,omitempty"`
+	OtherLimits   []ZaiLimit    `json:"otherLimits,omitempty"`
+	Keys          []ZaiKeyQuota `json:"keys,omitempty"` // with more than one key, the pool's totals are above
 }
 
-func (p *Plugin) getZaiStatus(config *Configuration) ServiceStatus {
-	if config.ZaiApiKey == "" {
-		return ServiceStatus{ID: "zai", Name: "Z.AI", Enabled: true, Status: "error", Error: "API key not configured"}
+// ZaiKeyQuota is the plan and quotas of one key of the pool.
+type ZaiKeyQuota struct {
+	Name         string  `json:"name"`
+	PlanName     string  `json:"planName,omitempty"`
+	PlanStatus   string  `json:"planStatus,omitempty"`
+	TokensUsed    float64 `json:"tokensUsed"`
+	TokensTotal   float64 `json:"tokensTotal"`
+	TokensRemain  float64 `json:"tokensRemaining"`
+	McpUsed       float64 `json:"mcpUsed"`
+	McpTotal      float64 `json:"mcpTotal"`
+	PromptsUsed   float64 `json:"promptsUsed,omitempty"`
+	PromptsTotal  float64 `json:"promptsTotal,omitempty"`
+	PromptsRemain float64 `json:"promptsRemaining,omitempty"`
+	NextReset     int64   `json:"nextReset,omitempty"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// ZaiLimit is a quota of a type the plugin doesn't know specifically, shown generically.
+type ZaiLimit struct {
+	Type      string  `json:"type"`
+	Used      float64 `json:"used"`
+	Total     float64 `json:"total"`
+	Remaining float64 `json:"remaining"`
+	NextReset int64   `json:"nextReset,omitempty"`
+}
+
+// zaiSubscription is an item of the subscription list.
+type zaiSubscription struct {
+	ProductName string `json:"productName" schema:"required"`
+	Status      string `json:"status" schema:"required"`
+}
+
+// zaiLimitItem is an item of the quota limit list.
+type zaiLimitItem struct {
+	Type          string     `json:"type" schema:"required"`
+	CurrentValue  *flexFloat `json:"currentValue"` // used, despite the name of the next one
+	Usage         *flexFloat `json:"usage"`        // the limit
+	Remaining     flexFloat  `json:"remaining"`
+	NextResetTime flexFloat  `json:"nextResetTime"`
+}
+
+// missing lists the figures a known limit type lacks, which would otherwise show as zeros.
+// Only known types need them, so the check isn't a schema tag.
+func (lm zaiLimitItem) missing() []string {
+	if lm.Type != "TOKENS_LIMIT" && lm.Type != "TIME_LIMIT" && !zaiPromptLimit(lm.Type) {
+		return nil
+	}
+	var fields []string
+	if lm.CurrentValue == nil {
+		fields = append(fields, "currentValue")
 	}
+	if lm.Usage == nil {
+		fields = append(fields, "usage")
+	}
+	return fields
+}
 
-	if cached, ok := p.getCached("zai"); ok {
-		return cached.(ServiceStatus)
+// flexValue is a figure that may be absent, as zero.
+func flexValue(f *flexFloat) float64 {
+	if f == nil {
+		return 0
 	}
+	return float64(*f)
+}
+
+// zaiMaxPages bounds pagination in case the API keeps reporting more pages.
+const zaiMaxPages = 20
 
-	client := &http.Client{Timeout: 10 * time.Second}
+// zaiKey is one key of the Z.AI key pool.
+type zaiKey struct {
+	name string
+	key  string
+}
+
+// parseZaiKeys parses the Z.AI API Keys, one per line or separated by commas, each
+// optionally named as "name=key". Unnamed keys are named by their last characters.
+func parseZaiKeys(value string) []zaiKey {
+	var keys []zaiKey
+	for _, line := range strings.Split(value, "\n") {
+		for _, item := range splitList(line) {
+			name, key, ok := strings.Cut(item, "=")
+			if !ok {
+				name, key = "", item
+			}
+			name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if name == "" {
+				name = "…" + key[max(len(key)-4, 0):]
+			}
+			keys = append(keys, zaiKey{name: name, key: key})
+		}
+	}
+	return keys
+}
+
+// zaiPercent is how much of a limit is used, from what remains, as the card shows it.
+func zaiPercent(total, remaining float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return (total - remaining) / total * 100
+}
+
+// fetchZaiQuota reads the plan and quotas of one key, and how many items it decoded.
+func (p *Plugin) fetchZaiQuota(client *http.Client, config *Configuration, apiKey string) (ZaiQuotaInfo, schemaDrift, int, error) {
 	info := ZaiQuotaInfo{}
+	var drift schemaDrift
+	decoded := 0
 
-	req, _ := http.NewRequest("GET", "https://api.z.ai/api/biz/subscription/list", nil)
-	req.Header.Set("Authorization", "Bearer "+config.ZaiApiKey)
-	if resp, err := client.Do(req); err == nil {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
-		var raw map[string]interface{}
-		if json.Unmarshal(body, &raw) == nil {
-			if data, ok := raw["data"].([]interface{}); ok && len(data) > 0 {
-				if sub, ok := data[0].(map[string]interface{}); ok {
-					info.PlanName = getString(sub, "productName")
-					info.PlanStatus = getString(sub, "status")
-				}
+	subscriptions, err := zaiFetchAll(client, "https://api.z.ai/api/biz/subscription/list", apiKey, "", config.maxResponseBytes())
+	if err != nil {
+		return info, drift, 0, err
+	}
+	limits, err := zaiFetchAll(client, "https://api.z.ai/api/monitor/usage/quota/limit", apiKey, "limits", config.maxResponseBytes())
+	if err != nil {
+		return info, drift, 0, err
+	}
+
+	// Prefer the first active subscription; accounts can have expired ones listed first
+	for _, item := range subscriptions {
+		var sub zaiSubscription
+		d, err := decodeResponse(item, &sub)
+		if err != nil {
+			continue
+		}
+		drift.merge(d)
+		decoded++
+
+		active := strings.EqualFold(sub.Status, "VALID")
+		if info.PlanName == "" || active {
+			info.PlanName = sub.ProductName
+			info.PlanStatus = sub.Status
+		}
+		if active {
+			break
+		}
+	}
+
+	for _, item := range limits {
+		var lm zaiLimitItem
+		d, err := decodeResponse(item, &lm)
+		if err != nil {
+			continue
+		}
+		d.Missing = append(d.Missing, lm.missing()...)
+		drift.merge(d)
+		decoded++
+
+		switch {
+		case lm.Type == "TOKENS_LIMIT":
+			info.TokensUsed = flexValue(lm.CurrentValue)
+			info.TokensTotal = flexValue(lm.Usage)
+			info.TokensRemain = float64(lm.Remaining)
+			info.NextReset = int64(lm.NextResetTime)
+		case lm.Type == "TIME_LIMIT":
+			info.McpUsed = flexValue(lm.CurrentValue)
+			info.McpTotal = flexValue(lm.Usage)
+			info.McpRemain = float64(lm.Remaining)
+		case zaiPromptLimit(lm.Type):
+			info.PromptsUsed = flexValue(lm.CurrentValue)
+			info.PromptsTotal = flexValue(lm.Usage)
+			info.PromptsRemain = float64(lm.Remaining)
+			if info.NextReset == 0 {
+				info.NextReset = int64(lm.NextResetTime)
 			}
+		case lm.Type == "":
+		default:
+			info.OtherLimits = append(info.OtherLimits, ZaiLimit{
+				Type:      lm.Type,
+				Used:      flexValue(lm.CurrentValue),
+				Total:     flexValue(lm.Usage),
+				Remaining: float64(lm.Remaining),
+				NextReset: int64(lm.NextResetTime),
+			})
 		}
 	}
+	return info, drift, decoded, nil
+}
 
-	req2, _ := http.NewRequest("GET", "https://api.z.ai/api/monitor/usage/quota/limit", nil)
-	req2.Header.Set("Authorization", "Bearer "+config.ZaiApiKey)
-	if resp2, err := client.Do(req2); err == nil {
-		defer resp2.Body.Close()
-		body, _ := io.ReadAll(resp2.Body)
-		var raw map[string]interface{}
-		if json.Unmarshal(body, &raw) == nil {
-			if data, ok := raw["data"].(map[string]interface{}); ok {
-				if limits, ok := data["limits"].([]interface{}); ok {
-					for _, l := range limits {
-						lm, ok := l.(map[string]interface{})
-						if !ok {
-							continue
-						}
-						switch getString(lm, "type") {
-						case "TOKENS_LIMIT":
-							info.TokensUsed = getFloat(lm, "currentValue")
-							info.TokensTotal = getFloat(lm, "usage")
-							info.TokensRemain = getFloat(lm, "remaining")
-							info.NextReset = int64(getFloat(lm, "nextResetTime"))
-						case "TIME_LIMIT":
-							info.McpUsed = getFloat(lm, "currentValue")
-							info.McpTotal = getFloat(lm, "usage")
-							info.McpRemain = getFloat(lm, "remaining")
-						}
-					}
+// zaiPromptLimit reports whether a limit type counts prompts, e.g. "PROMPT_LIMIT" on
+// plans sold by prompts per window rather than tokens.
+func zaiPromptLimit(limitType string) bool {
+	return strings.HasPrefix(limitType, "PROMPT")
+}
+
+// addZaiQuota adds one key's quotas to the pool's: limits are summed by type, the plan is
+// the first active one, and the next reset is the earliest.
+func (info *ZaiQuotaInfo) addZaiQuota(k ZaiQuotaInfo) {
+	if info.PlanName == "" || (strings.EqualFold(k.PlanStatus, "VALID") && !strings.EqualFold(info.PlanStatus, "VALID")) {
+		info.PlanName, info.PlanStatus = k.PlanName, k.PlanStatus
+	}
+	info.TokensUsed += k.TokensUsed
+	info.TokensTotal += k.TokensTotal
+	info.TokensRemain += k.TokensRemain
+	info.McpUsed += k.McpUsed
+	info.McpTotal += k.McpTotal
+	info.McpRemain += k.McpRemain
+	info.PromptsUsed += k.PromptsUsed
+	info.PromptsTotal += k.PromptsTotal
+	info.PromptsRemain += k.PromptsRemain
+	if k.NextReset > 0 && (info.NextReset == 0 || k.NextReset < info.NextReset) {
+		info.NextReset = k.NextReset
+	}
+	for _, l := range k.OtherLimits {
+		merged := false
+		for i := range info.OtherLimits {
+			if o := &info.OtherLimits[i]; o.Type == l.Type {
+				o.Used += l.Used
+				o.Total += l.Total
+				o.Remaining += l.Remaining
+				if l.NextReset > 0 && (o.NextReset == 0 || l.NextReset < o.NextReset) {
+					o.NextReset = l.NextReset
 				}
+				merged = true
 			}
 		}
+		if !merged {
+			info.OtherLimits = append(info.OtherLimits, l)
+		}
 	}
+}
 
-	status := "ok"
-	if info.TokensTotal > 0 && info.TokensRemain/info.TokensTotal < 0.1 {
-		status = "warning"
+func (info ZaiQuotaInfo) summary(locale string) string {
+	return translate(locale, "summary.zai", formatCount(info.TokensUsed), formatCount(info.TokensTotal))
+}
+
+func (info ZaiQuotaInfo) usagePercent() (float64, bool) {
+	if info.TokensTotal > 0 {
+		return info.TokensUsed / info.TokensTotal * 100, true
+	}
+	return 0, false
+}
+
+func (info ZaiQuotaInfo) resets(time.Time) []ResetInfo {
+	if info.NextReset > 0 {
+		return []ResetInfo{{Kind: "zai_5h", At: time.UnixMilli(info.NextReset)}}
+	}
+	return nil
+}
+
+type zaiProvider struct{}
+
+func (zaiProvider) ID() string   { return "zai" }
+func (zaiProvider) Name() string { return "Z.AI" }
+
+func (zaiProvider) Enabled(config *Configuration) bool {
+	return config.ZaiEnabled
+}
+
+// EvaluateStatus warns above the warning threshold of the pool's quotas or of any one
+// key's, since a pooled total can hide one key that's run out, and for keys that failed.
+func (zaiProvider) EvaluateStatus(data interface{}, config *Configuration) string {
+	info := data.(ZaiQuotaInfo)
+	warn := config.warningPercent(90)
+	if zaiPercent(info.TokensTotal, info.TokensRemain) > warn || zaiPercent(info.PromptsTotal, info.PromptsRemain) > warn {
+		return "warning"
+	}
+	for _, l := range info.OtherLimits {
+		if l.Total > 0 && l.Used/l.Total*100 > warn {
+			return "warning"
+		}
+	}
+	for _, k := range info.Keys {
+		if k.Error != "" || zaiPercent(k.TokensTotal, k.TokensRemain) > warn || zaiPercent(k.PromptsTotal, k.PromptsRemain) > warn {
+			return "warning"
+		}
+	}
+	return "ok"
+}
+
+func (z zaiProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	keys := parseZaiKeys(config.ZaiApiKey)
+	if len(keys) == 0 {
+		return nil, errFetch("error.api_key_missing")
 	}
 
-	result := ServiceStatus{
-		ID: "zai", Name: "Z.AI", Enabled: true, Status: status,
-		Data: info, CachedAt: time.Now().Unix(),
+	client := p.providerClient(z.ID(), 10*time.Second)
+	info := ZaiQuotaInfo{}
+
+	var drift schemaDrift
+	decoded, failed := 0, 0
+	var firstErr error
+	for _, key := range keys {
+		k, d, n, err := p.fetchZaiQuota(client, config, key.key)
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			// A key that failed is listed with its error, and the rest of the pool still counts
+			if len(keys) > 1 {
+				info.Keys = append(info.Keys, ZaiKeyQuota{Name: key.name, Error: fetchErrorStatus(z.ID(), z.Name(), err).Error})
+			}
+			continue
+		}
+		drift.merge(d)
+		decoded += n
+		info.addZaiQuota(k)
+
+		if len(keys) > 1 {
+			kq := ZaiKeyQuota{
+				Name: key.name, PlanName: k.PlanName, PlanStatus: k.PlanStatus,
+				TokensUsed: k.TokensUsed, TokensTotal: k.TokensTotal, TokensRemain: k.TokensRemain,
+				McpUsed: k.McpUsed, McpTotal: k.McpTotal,
+				PromptsUsed: k.PromptsUsed, PromptsTotal: k.PromptsTotal, PromptsRemain: k.PromptsRemain,
+				NextReset: k.NextReset,
+			}
+			if n == 0 {
+				kq.Error = "No plan or quota data; check the key"
+			}
+			info.Keys = append(info.Keys, kq)
+		}
 	}
-	p.setCache("zai", result)
-	return result
+	if failed == len(keys) {
+		return nil, firstErr
+	}
+	// Empty lists say nothing about the format
+	if decoded > 0 {
+		if err := p.checkSchema(z.ID(), drift); err != nil {
+			return nil, errFetch("error.response_format", err.Error())
+		}
+	}
+	return info, nil
+}
+
+// zaiFetchAll collects the items of a Z.AI list endpoint across all pages. The API
+// returns either a plain array in "data", or a page object with the items under
+// listKey (or one of the usual list keys) and a total count. A failed, rejected or
+// oversized page fails the whole list, so a bad key doesn't show as an empty quota.
+func zaiFetchAll(client *http.Client, baseURL, apiKey, listKey string, maxBytes int64) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	pageSize := 0
+	for page := 1; page <= zaiMaxPages; page++ {
+		url := baseURL
+		if page > 1 {
+			url += fmt.Sprintf("?pageNum=%d&pageSize=%d", page, pageSize)
+		}
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := readBody(resp.Body, maxBytes)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, errFetch("error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+
+		var raw struct {
+			Data    json.RawMessage `json:"data"`
+			Success *bool           `json:"success"`
+			Msg     string          `json:"msg"`
+		}
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return nil, errFetch("error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		// Some errors, such as an invalid key, come back as HTTP 200 with success false
+		if raw.Success != nil && !*raw.Success {
+			return nil, errFetch("error.api", raw.Msg)
+		}
+
+		var list []json.RawMessage
+		total := 0
+		if json.Unmarshal(raw.Data, &list) != nil {
+			var pageObj map[string]json.RawMessage
+			if json.Unmarshal(raw.Data, &pageObj) != nil {
+				return items, nil
+			}
+			for _, key := range []string{listKey, "list", "rows", "records", "items"} {
+				if json.Unmarshal(pageObj[key], &list) == nil && list != nil {
+					break
+				}
+			}
+			var t flexFloat
+			if json.Unmarshal(pageObj["total"], &t) == nil {
+				total = int(t)
+			}
+		}
+		items = append(items, list...)
+		if page == 1 {
+			pageSize = len(list)
+		}
+
+		// Single-page responses don't report a total
+		if len(list) == 0 || total <= len(items) {
+			return items, nil
+		}
+	}
+	return items, nil
 }
 
 // ===== OpenAI =====
@@ -176,135 +918,513 @@ type OpenAIUsageInfo struct {
 	TotalCost     float64 `json:"totalCost"`
 	Budget        float64 `json:"budget,omitempty"`
 	CreditBalance float64 `json:"creditBalance,omitempty"`
+	CreditSource  string  `json:"creditSource,omitempty"` // "api" or "config"
+	// Credits left after this cycle's spend; nil when no balance is known
+	RemainingFunds *float64 `json:"remainingFunds,omitempty"`
+	Currency      string  `json:"currency"` // the currency OpenAI bills in, e.g. "USD"
 	Period        string  `json:"period"`
+	CycleEnd      string  `json:"cycleEnd"`
 	DaysUntilReset int    `json:"daysUntilReset"`
 	BucketCount   int     `json:"bucketCount"`
+	Projects      []string `json:"projects,omitempty"` // project filter, empty for the whole organization
+	// From the cost history: the previous cycle's total, and its spend up to the same point in the cycle
+	LastMonthCost   float64 `json:"lastMonthCost,omitempty"`
+	LastMonthToDate float64 `json:"lastMonthToDate,omitempty"`
+	// Usage by organization member this cycle, when enabled
+	Members *OpenAIMemberUsage `json:"members,omitempty"`
+	// This cycle's usage by API key, largest first, when enabled; KeyCount counts them all
+	Keys     []OpenAIKeyUsage `json:"keys,omitempty"`
+	KeyCount int              `json:"keyCount,omitempty"`
+	// This cycle's cost by project and by model, largest first; the counts count them all
+	ProjectCosts []OpenAICostItem `json:"projectCosts,omitempty"`
+	ProjectCount int              `json:"projectCount,omitempty"`
+	ModelCosts   []OpenAICostItem `json:"modelCosts,omitempty"`
+	ModelCount   int              `json:"modelCount,omitempty"`
 }
 
-func (p *Plugin) getOpenAIStatus(config *Configuration) ServiceStatus {
-	if config.OpenaiApiKey == "" {
-		return ServiceStatus{ID: "openai", Name: "OpenAI", Enabled: true, Status: "error", Error: "API key not configured"}
+func (d OpenAIUsageInfo) summary(locale string) string {
+	text := translate(locale, "summary.openai_spent", formatMoney(d.TotalCost, d.currency(), 2), d.Period)
+	if d.Budget > 0 {
+		text = translate(locale, "summary.openai_budget", formatMoney(d.TotalCost, d.currency(), 2), formatMoney(d.Budget, d.currency(), 0), d.Period)
+	}
+	if d.LastMonthToDate > 0 {
+		text += " " + translate(locale, "summary.openai_vs_last_month", (d.TotalCost/d.LastMonthToDate-1)*100)
 	}
+	if m := d.Members; m != nil && m.ActiveUsers > 0 {
+		if len(m.TopUsers) > 0 {
+			text += " · " + translate(locale, "summary.openai_top_user", m.TopUsers[0].Name, m.TopUsers[0].Percent)
+		} else {
+			text += " · " + translate(locale, "summary.openai_active_users", m.ActiveUsers)
+		}
+	}
+	return text
+}
 
-	if cached, ok := p.getCached("openai"); ok {
-		return cached.(ServiceStatus)
+func (d OpenAIUsageInfo) compact(name, locale string) string {
+	if d.Budget > 0 {
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(d.TotalCost, d.currency(), 0), formatMoney(d.Budget, d.currency(), 0))
 	}
+	return fmt.Sprintf("%s %s", name, formatMoney(d.TotalCost, d.currency(), 0))
+}
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	// Start of current month
-	now := time.Now().UTC()
-	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-	startTime := monthStart.Unix()
-	url := fmt.Sprintf("https://api.openai.com/v1/organization/costs?start_time=%d&end_time=%d&bucket_width=1d&limit=31", startTime, now.Unix())
+func (d OpenAIUsageInfo) usagePercent() (float64, bool) {
+	if d.Budget > 0 {
+		return d.TotalCost / d.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (d OpenAIUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(d.CycleEnd)}}
+}
+
+// openAIKeyType classifies a key by its prefix: "admin", "project", "service_account" or "user".
+// Only admin keys can read organization costs.
+func openAIKeyType(key string) string {
+	switch {
+	case strings.HasPrefix(key, "sk-admin-"):
+		return "admin"
+	case strings.HasPrefix(key, "sk-proj-"):
+		return "project"
+	case strings.HasPrefix(key, "sk-svcacct-"):
+		return "service_account"
+	}
+	return "user"
+}
+
+// openAIOrg is an organization whose costs are shown as a separate card.
+// The zero value is the key's default organization.
+type openAIOrg struct {
+	ID    string
+	Label string
+}
+
+func (o openAIOrg) statusID() string {
+	if o.ID == "" {
+		return "openai"
+	}
+	return "openai:" + o.ID
+}
+
+func (o openAIOrg) statusName() string {
+	switch {
+	case o.Label != "":
+		return "OpenAI (" + o.Label + ")"
+	case o.ID != "":
+		return "OpenAI (" + o.ID + ")"
+	}
+	return "OpenAI"
+}
+
+// getOpenAIStatuses returns one card per configured organization, or a single card
+// for the key's default organization when none are configured.
+func (p *Plugin) getOpenAIStatuses(config *Configuration) []ServiceStatus {
+	var statuses []ServiceStatus
+	for _, org := range p.openAIOrgs(config) {
+		statuses = append(statuses, p.fetchFromProvider(openAIProvider{org: org}, config))
+	}
+	return statuses
+}
+
+// openAIOrgs returns the organizations to track, or just the key's default one.
+func (p *Plugin) openAIOrgs(config *Configuration) []openAIOrg {
+	var orgs []openAIOrg
+	if strings.EqualFold(strings.TrimSpace(config.OpenaiOrganizations), "auto") {
+		orgs = p.discoverOpenAIOrgs(config)
+	} else {
+		orgs = parseOpenAIOrgs(config.OpenaiOrganizations)
+	}
+	if len(orgs) == 0 {
+		orgs = []openAIOrg{{}}
+	}
+	return orgs
+}
+
+// parseOpenAIOrgs parses "org-id" or "org-id=Label" entries separated by commas.
+func parseOpenAIOrgs(value string) []openAIOrg {
+	var orgs []openAIOrg
+	for _, item := range splitList(value) {
+		id, label, _ := strings.Cut(item, "=")
+		orgs = append(orgs, openAIOrg{ID: strings.TrimSpace(id), Label: strings.TrimSpace(label)})
+	}
+	return orgs
+}
+
+// discoverOpenAIOrgs lists the organizations the key's owner belongs to. Discovery
+// results are cached like provider data; failures fall back to the default organization.
+func (p *Plugin) discoverOpenAIOrgs(config *Configuration) []openAIOrg {
+	if cached, ok := p.getCached("openai_orgs"); ok {
+		return cached.([]openAIOrg)
+	}
+
+	client := p.providerClient("openai", 10*time.Second)
+	req, _ := http.NewRequest("GET", "https://api.openai.com/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer "+config.OpenaiApiKey)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil || resp.StatusCode != 200 {
+		return nil
+	}
+
+	var me struct {
+		Orgs struct {
+			Data []struct {
+				ID    string `json:"id"`
+				Title string `json:"title"`
+			} `json:"data"`
+		} `json:"orgs"`
+	}
+	if json.Unmarshal(body, &me) != nil {
+		return nil
+	}
+	var orgs []openAIOrg
+	for _, o := range me.Orgs.Data {
+		orgs = append(orgs, openAIOrg{ID: o.ID, Label: o.Title})
+	}
+	p.setCache("openai_orgs", orgs)
+	return orgs
+}
+
+// openAICostsResponse is a page of /v1/organization/costs.
+type openAICostsResponse struct {
+	Object   string `json:"object"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+	Data     []struct {
+		Object    string `json:"object"`
+		StartTime int64  `json:"start_time"`
+		EndTime   int64  `json:"end_time"`
+		Results   []struct {
+			Object string `json:"object"`
+			Amount struct {
+				Value    flexFloat `json:"value" schema:"required"`
+				Currency string    `json:"currency"`
+			} `json:"amount" schema:"required"`
+			LineItem  *string `json:"line_item"`
+			ProjectID *string `json:"project_id"`
+		} `json:"results"`
+	} `json:"data" schema:"required"`
+}
+
+// openAICreditGrantsResponse is the body of the billing credit grants endpoint.
+type openAICreditGrantsResponse struct {
+	Object             string    `json:"object"`
+	TotalGranted       flexFloat `json:"total_granted" schema:"required"`
+	TotalUsed          flexFloat `json:"total_used" schema:"required"`
+	TotalAvailable     flexFloat `json:"total_available" schema:"required"`
+	TotalPaidAvailable flexFloat `json:"total_paid_available"`
+	Grants             struct {
+		Object string `json:"object"`
+		Data   []struct {
+			Object      string    `json:"object"`
+			ID          string    `json:"id"`
+			GrantAmount flexFloat `json:"grant_amount"`
+			UsedAmount  flexFloat `json:"used_amount"`
+			EffectiveAt flexFloat `json:"effective_at"`
+			ExpiresAt   flexFloat `json:"expires_at"`
+		} `json:"data"`
+	} `json:"grants"`
+}
+
+// fetchOpenAICredits reads the organization's prepaid credit grants. Not every key may
+// read billing data, so any failure just means the balance isn't available.
+func (p *Plugin) fetchOpenAICredits(client *http.Client, config *Configuration, org openAIOrg) (openAICreditGrantsResponse, bool) {
+	var grants openAICreditGrantsResponse
+	req, _ := http.NewRequest("GET", "https://api.openai.com/dashboard/billing/credit_grants", nil)
+	req.Header.Set("Authorization", "Bearer "+config.OpenaiApiKey)
+	if org.ID != "" {
+		req.Header.Set("OpenAI-Organization", org.ID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return grants, false
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil || resp.StatusCode != 200 {
+		return grants, false
+	}
+
+	drift, err := decodeResponse(body, &grants)
+	if err != nil {
+		return grants, false
+	}
+	if err := p.checkSchema("openai_credits", drift); err != nil {
+		p.API.LogDebug("OpenAI credit grants are missing fields", "error", err.Error())
+		return grants, false
+	}
+	return grants, len(drift.Missing) == 0
+}
+
+// dailyCosts sums the results of each bucket by its UTC start date ("2006-01-02").
+func (r openAICostsResponse) dailyCosts() map[string]float64 {
+	days := map[string]float64{}
+	for _, bucket := range r.Data {
+		day := time.Unix(bucket.StartTime, 0).UTC().Format("2006-01-02")
+		for _, result := range bucket.Results {
+			days[day] += float64(result.Amount.Value)
+		}
+	}
+	return days
+}
+
+// currency is the billing currency of the costs, upper-cased, or the base currency when unreported.
+func (r openAICostsResponse) currency() string {
+	for _, bucket := range r.Data {
+		for _, result := range bucket.Results {
+			if result.Amount.Currency != "" {
+				return strings.ToUpper(result.Amount.Currency)
+			}
+		}
+	}
+	return baseCurrency
+}
+
+// newOpenAICostsRequest builds a request for daily cost buckets between start and end,
+// filtered to the configured projects. page continues a previous response's next_page.
+// Costs are grouped by project and line item, for the breakdowns.
+func newOpenAICostsRequest(config *Configuration, org openAIOrg, start, end time.Time, page string) *http.Request {
+	url := fmt.Sprintf("https://api.openai.com/v1/organization/costs?start_time=%d&end_time=%d&bucket_width=1d&limit=31&group_by=project_id&group_by=line_item", start.Unix(), end.Unix())
+	for _, id := range splitList(config.OpenaiProjectIds) {
+		url += "&project_ids=" + neturl.QueryEscape(id)
+	}
+	if page != "" {
+		url += "&page=" + neturl.QueryEscape(page)
+	}
 
 	req, _ := http.NewRequest("GET", url, nil)
 	req.Header.Set("Authorization", "Bearer "+config.OpenaiApiKey)
 	req.Header.Set("Content-Type", "application/json")
+	if org.ID != "" {
+		req.Header.Set("OpenAI-Organization", org.ID)
+	}
+	return req
+}
 
-	resp, err := client.Do(req)
+// openAIProvider is the costs card of one organization.
+type openAIProvider struct {
+	org openAIOrg
+}
+
+func (o openAIProvider) ID() string   { return o.org.statusID() }
+func (o openAIProvider) Name() string { return o.org.statusName() }
+
+func (openAIProvider) Enabled(config *Configuration) bool {
+	return config.OpenaiEnabled
+}
+
+// EvaluateStatus follows the budget, and is an error once prepaid credits run out since
+// the account stops working.
+func (openAIProvider) EvaluateStatus(data interface{}, config *Configuration) string {
+	info := data.(OpenAIUsageInfo)
+	if info.RemainingFunds != nil && *info.RemainingFunds <= 0 {
+		return "error"
+	}
+	return budgetStatus(info.TotalCost, info.Budget, config)
+}
+
+func (o openAIProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	id, org := o.ID(), o.org
+	if config.OpenaiApiKey == "" {
+		return nil, errFetch("error.api_key_missing")
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	now := time.Now().UTC()
+	cycleStart, cycleEnd := billingCycle(now, config.openAICycleAnchor())
+	projects := splitList(config.OpenaiProjectIds)
+
+	resp, err := client.Do(newOpenAICostsRequest(config, org, cycleStart, now, ""))
 	if err != nil {
-		return ServiceStatus{ID: "openai", Name: "OpenAI", Enabled: true, Status: "error", Error: fmt.Sprintf("API error: %v", err)}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	// Costs are only available to admin keys; say so instead of relaying the opaque upstream error
+	if keyType := openAIKeyType(config.OpenaiApiKey); (resp.StatusCode == 401 || resp.StatusCode == 403) && keyType != "admin" {
+		return nil, errFetch("error.openai_admin_key_required", keyType)
+	}
 	if resp.StatusCode != 200 {
-		var errResp map[string]interface{}
-		if json.Unmarshal(body, &errResp) == nil {
-			if errObj, ok := errResp["error"].(map[string]interface{}); ok {
-				return ServiceStatus{ID: "openai", Name: "OpenAI", Enabled: true, Status: "error",
-					Error: getString(errObj, "message")}
-			}
+		var errResp struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
 		}
-		return ServiceStatus{ID: "openai", Name: "OpenAI", Enabled: true, Status: "error",
-			Error: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))}
-	}
-
-	var raw map[string]interface{}
-	if err := json.Unmarshal(body, &raw); err != nil {
-		return ServiceStatus{ID: "openai", Name: "OpenAI", Enabled: true, Status: "error", Error: "Invalid JSON response"}
-	}
-
-	info := OpenAIUsageInfo{Period: monthStart.Format("Jan 2006")}
-
-	if data, ok := raw["data"].([]interface{}); ok {
-		info.BucketCount = len(data)
-		for _, d := range data {
-			if dm, ok := d.(map[string]interface{}); ok {
-				if results, ok := dm["results"].([]interface{}); ok {
-					for _, r := range results {
-						if rm, ok := r.(map[string]interface{}); ok {
-							if amountObj, ok := rm["amount"].(map[string]interface{}); ok {
-								valStr := getString(amountObj, "value")
-								if valStr != "" {
-									val, _ := strconv.ParseFloat(valStr, 64)
-									info.TotalCost += val
-								}
-							}
-						}
-					}
-				}
-			}
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			return nil, errFetch("error.api", errResp.Error.Message)
 		}
+		return nil, errFetch("error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
 	}
 
-	// Add budget and credit balance from config
-	if config.OpenaiMonthlyBudget != "" {
-		info.Budget, _ = strconv.ParseFloat(config.OpenaiMonthlyBudget, 64)
+	var raw openAICostsResponse
+	drift, err := decodeResponse(body, &raw)
+	if err != nil {
+		return nil, errFetch("error.invalid_json")
 	}
-	if config.OpenaiCreditBalance != "" {
-		info.CreditBalance, _ = strconv.ParseFloat(config.OpenaiCreditBalance, 64)
+	if err := p.checkSchema("openai", drift); err != nil {
+		return nil, errFetch("error.response_format", err.Error())
 	}
 
-	// Days until month reset
-	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
-	info.DaysUntilReset = int(nextMonth.Sub(now).Hours() / 24)
+	info := OpenAIUsageInfo{
+		Period:   cyclePeriod(cycleStart, cycleEnd),
+		CycleEnd: cycleEnd.Format(time.RFC3339),
+		Projects: projects,
+	}
+	info.BucketCount = len(raw.Data)
+	days := raw.dailyCosts()
+	for _, cost := range days {
+		info.TotalCost += cost
+	}
+	p.storeCostDays(id, days)
+	info.LastMonthCost, info.LastMonthToDate = p.previousCycleCosts(id, config.openAICycleAnchor(), cycleStart, now)
 
-	status := "ok"
-	if info.Budget > 0 && info.TotalCost/info.Budget > 0.8 {
-		status = "warning"
+	info.Currency = raw.currency()
+	info.ProjectCosts, info.ProjectCount = openAICostItems(raw.projectCosts(), 0)
+	for i := range info.ProjectCosts {
+		info.ProjectCosts[i].Name = p.openAIProjectName(client, config, org, info.ProjectCosts[i].ID)
 	}
-	if info.Budget > 0 && info.TotalCost >= info.Budget {
-		status = "error"
+	info.ModelCosts, info.ModelCount = openAICostItems(raw.modelCosts(), openAIModelsShown)
+
+	info.Budget = p.budgetIn(config, "openai", config.OpenaiMonthlyBudget, info.Currency)
+
+	// Prefer the live credit balance; the configured one is the balance at the start of the cycle
+	if grants, ok := p.fetchOpenAICredits(client, config, org); ok {
+		info.CreditBalance = float64(grants.TotalAvailable)
+		info.CreditSource = "api"
+		info.RemainingFunds = floatPtr(float64(grants.TotalGranted - grants.TotalUsed))
+	} else if config.OpenaiCreditBalance != "" {
+		info.CreditBalance, _ = strconv.ParseFloat(config.OpenaiCreditBalance, 64)
+		info.CreditSource = "config"
+		info.RemainingFunds = floatPtr(info.CreditBalance - info.TotalCost)
 	}
 
-	result := ServiceStatus{
-		ID: "openai", Name: "OpenAI", Enabled: true, Status: status,
-		Data: info, CachedAt: time.Now().Unix(),
+	info.DaysUntilReset = int(cycleEnd.Sub(now).Hours() / 24)
+	info.Members = p.fetchOpenAIMemberUsage(client, config, org, cycleStart, now)
+	if config.OpenaiKeyBreakdown {
+		info.Keys, info.KeyCount = p.fetchOpenAIKeyUsage(client, config, org, cycleStart, now)
 	}
-	p.setCache("openai", result)
-	return result
+	return info, nil
 }
 
 // ===== Claude (claude.ai usage via OAuth) =====
 
 type ClaudeUsageInfo struct {
-	Utilization5h float64 `json:"utilization5h"`
-	Reset5h       string  `json:"reset5h,omitempty"`
-	Utilization7d float64 `json:"utilization7d"`
-	Reset7d       string  `json:"reset7d,omitempty"`
-	SonnetUtil    float64 `json:"sonnetUtil,omitempty"`
-	OpusUtil      float64 `json:"opusUtil,omitempty"`
-	HasData       bool    `json:"hasData"`
+	Utilization5h float64  `json:"utilization5h"`
+	Reset5h       string   `json:"reset5h,omitempty"`
+	Utilization7d float64  `json:"utilization7d"`
+	Reset7d       string   `json:"reset7d,omitempty"`
+	SonnetUtil    float64  `json:"sonnetUtil,omitempty"`
+	OpusUtil      float64  `json:"opusUtil,omitempty"`
+	HasData       bool     `json:"hasData"`
+	Constrained   []string `json:"constrained,omitempty"` // windows driving the status: "5h", "7d", "sonnet", "opus"
 }
 
-func (p *Plugin) getClaudeStatus(config *Configuration) ServiceStatus {
-	if !config.ClaudeEnabled {
-		return ServiceStatus{ID: "claude", Name: "claude.ai", Enabled: false, Status: "disabled"}
+func (c ClaudeUsageInfo) summary(locale string) string {
+	if !c.HasData {
+		return translate(locale, "summary.claude_no_data")
+	}
+	text := translate(locale, "summary.claude", c.Utilization5h, c.Utilization7d)
+	if len(c.Constrained) > 0 {
+		var windows []string
+		for _, w := range c.Constrained {
+			windows = append(windows, translate(locale, "window.claude_"+w))
+		}
+		text = translate(locale, "summary.claude_constrained", text, strings.Join(windows, ", "))
 	}
+	return text
+}
 
-	if config.ClaudeAccessToken == "" {
-		return ServiceStatus{
-			ID: "claude", Name: "claude.ai", Enabled: true, Status: "error",
-			Error: "Access token not configured. Run 'claude' CLI on server, authorize, then copy tokens from ~/.claude/.credentials.json",
+func (c ClaudeUsageInfo) usagePercent() (float64, bool) {
+	if c.HasData {
+		return max(c.Utilization5h, c.Utilization7d, c.SonnetUtil, c.OpusUtil), true
+	}
+	return 0, false
+}
+
+func (c ClaudeUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "claude_5h", At: parseTime(c.Reset5h)}, {Kind: "claude_7d", At: parseTime(c.Reset7d)}}
+}
+
+// claudeStatus derives the status from all usage windows, each with its own
+// threshold, and records which windows are responsible for it.
+func claudeStatus(info *ClaudeUsageInfo, config *Configuration) string {
+	warn := config.warningPercent(80)
+	windows := []struct {
+		id   string
+		util float64
+		warn float64
+	}{
+		{"5h", info.Utilization5h, warn},
+		{"7d", info.Utilization7d, warn},
+		{"sonnet", info.SonnetUtil, parsePercent(config.ClaudeSonnetWarn, warn)},
+		{"opus", info.OpusUtil, parsePercent(config.ClaudeOpusWarn, warn)},
+	}
+
+	status := "ok"
+	info.Constrained = nil
+	for _, w := range windows {
+		windowStatus := "ok"
+		if w.util >= 100 {
+			windowStatus = "error"
+		} else if w.util > w.warn {
+			windowStatus = "warning"
+		}
+		switch {
+		case windowStatus == "ok":
+		case statusSeverity(windowStatus) > statusSeverity(status):
+			status = windowStatus
+			info.Constrained = []string{w.id}
+		case windowStatus == status:
+			info.Constrained = append(info.Constrained, w.id)
 		}
 	}
+	return status
+}
+
+// claudeUsageResponse is the body of /api/oauth/usage. Windows are null when the
+// plan doesn't have them.
+type claudeUsageResponse struct {
+	FiveHour       *claudeUsageWindow `json:"five_hour" schema:"required"`
+	SevenDay       *claudeUsageWindow `json:"seven_day" schema:"required"`
+	SevenDaySonnet *claudeUsageWindow `json:"seven_day_sonnet"`
+	SevenDayOpus   *claudeUsageWindow `json:"seven_day_opus"`
+}
+
+type claudeUsageWindow struct {
+	Utilization *flexFloat `json:"utilization"`
+	ResetsAt    string     `json:"resets_at"`
+}
+
+type claudeProvider struct{}
+
+func (claudeProvider) ID() string   { return "claude" }
+func (claudeProvider) Name() string { return "claude.ai" }
+
+func (claudeProvider) Enabled(config *Configuration) bool {
+	return config.ClaudeEnabled
+}
+
+func (claudeProvider) EvaluateStatus(data interface{}, config *Configuration) string {
+	info := data.(ClaudeUsageInfo)
+	return claudeStatus(&info, config)
+}
 
-	if cached, ok := p.getCached("claude"); ok {
-		return cached.(ServiceStatus)
+func (c claudeProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	config = p.claudeConfig(config)
+	if config.ClaudeAccessToken == "" {
+		return nil, errFetch("error.claude_token_missing")
 	}
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := p.providerClient(c.ID(), 15*time.Second)
 
 	req, _ := http.NewRequest("GET", "https://api.anthropic.com/api/oauth/usage", nil)
 	req.Header.Set("Authorization", "Bearer "+config.ClaudeAccessToken)
@@ -315,12 +1435,14 @@ func (p *Plugin) getClaudeStatus(config *Configuration) ServiceStatus {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return ServiceStatus{ID: "claude", Name: "claude.ai", Enabled: true, Status: "error",
-			Error: fmt.Sprintf("API error: %v", err)}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
 
 	// If auth error, try to refresh token
 	if (resp.StatusCode == 401 || resp.StatusCode == 403) && config.ClaudeRefreshToken != "" {
@@ -336,143 +1458,79 @@ func (p *Plugin) getClaudeStatus(config *Configuration) ServiceStatus {
 			resp2, err2 := client.Do(req2)
 			if err2 == nil {
 				defer resp2.Body.Close()
-				body, _ = io.ReadAll(resp2.Body)
+				if body, err = p.readResponse(resp2); err != nil {
+					return nil, err
+				}
 				resp = resp2
 			}
 		}
 	}
 
 	if resp.StatusCode != 200 {
-		return ServiceStatus{ID: "claude", Name: "claude.ai", Enabled: true, Status: "error",
-			Error: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))}
+		return nil, errFetch("error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
 	}
 
-	var raw map[string]interface{}
-	if err := json.Unmarshal(body, &raw); err != nil {
-		return ServiceStatus{ID: "claude", Name: "claude.ai", Enabled: true, Status: "error",
-			Error: "Invalid JSON from usage API"}
+	var raw claudeUsageResponse
+	drift, err := decodeResponse(body, &raw)
+	if err != nil {
+		return nil, errFetch("error.invalid_json")
 	}
-
-	info := ClaudeUsageInfo{HasData: false}
-
-	if fiveHour, ok := raw["five_hour"].(map[string]interface{}); ok {
-		if util, exists := fiveHour["utilization"]; exists {
-			info.Utilization5h = toFloat(util)
-			info.HasData = true
-		}
-		if resetAt, ok := fiveHour["resets_at"].(string); ok {
-			info.Reset5h = resetAt
-		}
+	if err := p.checkSchema(c.ID(), drift); err != nil {
+		return nil, errFetch("error.response_format", err.Error())
 	}
 
-	if sevenDay, ok := raw["seven_day"].(map[string]interface{}); ok {
-		if util, exists := sevenDay["utilization"]; exists {
-			info.Utilization7d = toFloat(util)
+	info := ClaudeUsageInfo{}
+	if w := raw.FiveHour; w != nil {
+		info.Reset5h = w.ResetsAt
+		if w.Utilization != nil {
+			info.Utilization5h = float64(*w.Utilization)
 			info.HasData = true
 		}
-		if resetAt, ok := sevenDay["resets_at"].(string); ok {
-			info.Reset7d = resetAt
-		}
 	}
-
-	if sonnet, ok := raw["seven_day_sonnet"].(map[string]interface{}); ok {
-		if util, exists := sonnet["utilization"]; exists {
-			info.SonnetUtil = toFloat(util)
-		}
-	}
-	if opus, ok := raw["seven_day_opus"].(map[string]interface{}); ok {
-		if util, exists := opus["utilization"]; exists {
-			info.OpusUtil = toFloat(util)
+	if w := raw.SevenDay; w != nil {
+		info.Reset7d = w.ResetsAt
+		if w.Utilization != nil {
+			info.Utilization7d = float64(*w.Utilization)
+			info.HasData = true
 		}
 	}
-
-	status := "ok"
-	if info.Utilization5h > 80 || info.Utilization7d > 80 {
-		status = "warning"
+	if w := raw.SevenDaySonnet; w != nil && w.Utilization != nil {
+		info.SonnetUtil = float64(*w.Utilization)
 	}
-	if info.Utilization5h >= 100 || info.Utilization7d >= 100 {
-		status = "error"
+	if w := raw.SevenDayOpus; w != nil && w.Utilization != nil {
+		info.OpusUtil = float64(*w.Utilization)
 	}
 
-	result := ServiceStatus{
-		ID: "claude", Name: "claude.ai", Enabled: true, Status: status,
-		Data: info, CachedAt: time.Now().Unix(),
-	}
-	p.setCache("claude", result)
-	return result
+	// Keep the windows behind the status with the data; EvaluateStatus derives the status
+	claudeStatus(&info, config)
+	return info, nil
 }
 
-// refreshClaudeToken uses refresh_token to get new access_token and saves it to config.
-func (p *Plugin) refreshClaudeToken(config *Configuration) (string, error) {
-	client := &http.Client{Timeout: 15 * time.Second}
-	formData := "grant_type=refresh_token&client_id=9d1c250a-e61b-44d9-88ed-5944d1962f5e&refresh_token=" + config.ClaudeRefreshToken
-
-	req, _ := http.NewRequest("POST", "https://platform.claude.com/v1/oauth/token", strings.NewReader(formData))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("refresh HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
-	}
-
-	var tokenResp map[string]interface{}
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return "", err
-	}
-
-	newToken := getString(tokenResp, "access_token")
-	if newToken == "" {
-		return "", fmt.Errorf("empty access_token")
-	}
-
-	// Update config with new token
-	config.ClaudeAccessToken = newToken
-	if rt := getString(tokenResp, "refresh_token"); rt != "" {
-		config.ClaudeRefreshToken = rt
-	}
-
-	// Save updated tokens to plugin config
-	cfgMap := map[string]interface{}{}
-	cfgBytes, _ := json.Marshal(config)
-	json.Unmarshal(cfgBytes, &cfgMap)
-	p.API.SavePluginConfig(cfgMap)
-
-	return newToken, nil
-}
+// ===== Helpers =====
 
-func toFloat(v interface{}) float64 {
-	switch n := v.(type) {
-	case float64:
-		return n
-	case json.Number:
-		f, _ := n.Float64()
-		return f
-	case int:
-		return float64(n)
+// budgetStatus is "error" once spend reaches the budget and "warning" above the
+// warning threshold (80% by default). Without a budget it's always "ok".
+func budgetStatus(spent, budget float64, config *Configuration) string {
+	switch {
+	case budget <= 0:
+		return "ok"
+	case spent >= budget:
+		return "error"
+	case spent/budget*100 > config.warningPercent(80):
+		return "warning"
 	}
-	return 0
+	return "ok"
 }
 
-// ===== Helpers =====
-
-func getFloat(m map[string]interface{}, key string) float64 {
-	if v, ok := m[key]; ok {
-		switch n := v.(type) {
-		case float64:
-			return n
-		case json.Number:
-			f, _ := n.Float64()
-			return f
+// splitList splits a comma-separated setting, dropping blanks.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
-	return 0
+	return items
 }
 
 func getString(m map[string]interface{}, key string) string {
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixture is a recorded response, read from testdata.
+type fixture struct {
+	status int
+	file   string
+}
+
+// fixtureTransport answers provider requests with recorded responses, by the request's URL
+// without the query. A route prefixed with an Authorization header value only answers
+// requests sent with it, so keys of a pool can get different responses. Requests without
+// a fixture get a 404.
+type fixtureTransport struct {
+	t      *testing.T
+	routes map[string]fixture
+}
+
+func (f *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.RawQuery = ""
+	route, ok := f.routes[req.Header.Get("Authorization")+" "+u.String()]
+	if !ok {
+		route, ok = f.routes[u.String()]
+	}
+	body := []byte(`{"error": "no fixture"}`)
+	if ok {
+		var err error
+		if body, err = os.ReadFile(filepath.Join("testdata", route.file)); err != nil {
+			f.t.Fatalf("reading fixture: %v", err)
+		}
+	} else {
+		route.status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: route.status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// newFixturePlugin returns a plugin with the configuration whose providers are answered
+// from the routes, with an in-memory KV store.
+func newFixturePlugin(t *testing.T, config *Configuration, routes map[string]fixture) *Plugin {
+	t.Helper()
+	previous := providerTransport
+	providerTransport = &fixtureTransport{t: t, routes: routes}
+	t.Cleanup(func() { providerTransport = previous })
+
+	p := &Plugin{
+		configuration: config,
+		cache:         make(map[string]*CacheEntry),
+		states:        make(map[string]*providerState),
+		bg:            newBackground(),
+		defaultUA:     "Mattermost-AI-Limits-Monitor-Test",
+	}
+	p.API = &collectorAPI{kv: map[string][]byte{}}
+	return p
+}
+
+const augmentCreditURL = "https://d2.api.augmentcode.com/get-credit-info"
+
+func TestAugmentCredits(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{AugmentEnabled: true, AugmentAccessToken: "token"}, map[string]fixture{
+		augmentCreditURL: {200, "augment/credit_info.json"},
+	})
+
+	s := p.fetchFromProvider(augmentProvider{}, p.getConfiguration())
+	if s.Status != "ok" || s.Error != "" {
+		t.Fatalf("status = %q, error %q; want ok", s.Status, s.Error)
+	}
+	info := s.Data.(AugmentCreditInfo)
+	// The included units are the cycle's total, and what's used is what's left of them
+	if info.PlanName != "Developer" || info.UsageTotal != 600 || info.UsageRemaining != 180 || info.UsageUsed != 420 {
+		t.Errorf("credits = %+v, want 420 of Developer's 600 used", info)
+	}
+	if info.CycleEnd != "2025-11-01T00:00:00Z" || info.Team != nil {
+		t.Errorf("cycle end = %q, team %+v; want a personal account", info.CycleEnd, info.Team)
+	}
+}
+
+func TestAugmentTeamPool(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{AugmentEnabled: true, AugmentAccessToken: "token"}, map[string]fixture{
+		augmentCreditURL: {200, "augment/credit_info_team.json"},
+	})
+
+	s := p.fetchFromProvider(augmentProvider{}, p.getConfiguration())
+	team := s.Data.(AugmentCreditInfo).Team
+	if team == nil || team.Name != "Platform" || team.UsageTotal != 3000 || team.UsageUsed != 2750 {
+		t.Fatalf("team = %+v, want 2750 of Platform's 3000 used", team)
+	}
+	// Seats go by email, or name without one, heaviest first
+	if len(team.Seats) != 2 || team.Seats[0] != (AugmentSeatUsage{User: "Bo", UsageUsed: 1850}) || team.Seats[1].User != "ana@example.com" {
+		t.Errorf("seats = %+v", team.Seats)
+	}
+	if s.Status != "warning" {
+		t.Errorf("status = %q, want warning for a pool over 90%% used", s.Status)
+	}
+}
+
+func TestAugmentExpiredToken(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{AugmentEnabled: true, AugmentAccessToken: "token"}, map[string]fixture{
+		augmentCreditURL: {403, "augment/token_expired.json"},
+	})
+
+	if s := p.fetchFromProvider(augmentProvider{}, p.getConfiguration()); s.Status != "reauth" {
+		t.Errorf("status = %q, error %q; want reauth", s.Status, s.Error)
+	}
+}
+
+const (
+	zaiSubscriptionURL = "https://api.z.ai/api/biz/subscription/list"
+	zaiQuotaLimitURL   = "https://api.z.ai/api/monitor/usage/quota/limit"
+)
+
+func TestZaiQuotaMapping(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{ZaiEnabled: true, ZaiApiKey: "abc.def"}, map[string]fixture{
+		zaiSubscriptionURL: {200, "zai/subscription_list.json"},
+		zaiQuotaLimitURL:   {200, "zai/quota_limit.json"},
+	})
+
+	s := p.fetchFromProvider(zaiProvider{}, p.getConfiguration())
+	if s.Status != "ok" || s.Error != "" {
+		t.Fatalf("status = %q, error %q; want ok", s.Status, s.Error)
+	}
+	info := s.Data.(ZaiQuotaInfo)
+	// currentValue is what's used and usage the limit, despite the names
+	want := ZaiQuotaInfo{
+		PlanName: "GLM Coding Pro", PlanStatus: "VALID",
+		TokensUsed: 123456789, TokensTotal: 800000000, TokensRemain: 676543211, NextReset: 1760601600000,
+		McpUsed: 37, McpTotal: 1000, McpRemain: 963,
+	}
+	if fmt.Sprint(info) != fmt.Sprint(want) {
+		t.Errorf("quota = %+v\nwant %+v", info, want)
+	}
+}
+
+func TestZaiQuotaMissingFigures(t *testing.T) {
+	config := &Configuration{ZaiEnabled: true, ZaiApiKey: "abc.def", StrictResponseChecks: true}
+	p := newFixturePlugin(t, config, map[string]fixture{
+		zaiSubscriptionURL: {200, "zai/subscription_list.json"},
+		zaiQuotaLimitURL:   {200, "zai/quota_limit_missing_usage.json"},
+	})
+
+	s := p.fetchFromProvider(zaiProvider{}, config)
+	if s.Status != "error" || !strings.Contains(s.Error, "currentValue") || !strings.Contains(s.Error, "usage") {
+		t.Errorf("status = %q, error %q; want a response format error naming the missing figures", s.Status, s.Error)
+	}
+}
+
+func TestZaiRejectedKey(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{ZaiEnabled: true, ZaiApiKey: "abc.def"}, map[string]fixture{
+		zaiSubscriptionURL: {200, "zai/invalid_key.json"},
+		zaiQuotaLimitURL:   {200, "zai/invalid_key.json"},
+	})
+
+	s := p.fetchFromProvider(zaiProvider{}, p.getConfiguration())
+	if s.Status != "error" || !strings.Contains(s.Error, "Authorization Token Invalid") {
+		t.Errorf("status = %q, error %q; want the API's error", s.Status, s.Error)
+	}
+}
+
+func TestZaiPoolWithRejectedKey(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{ZaiEnabled: true, ZaiApiKey: "research=good.key\nops=bad.key"}, map[string]fixture{
+		zaiSubscriptionURL:                     {200, "zai/subscription_list.json"},
+		zaiQuotaLimitURL:                       {200, "zai/quota_limit.json"},
+		"Bearer bad.key " + zaiSubscriptionURL: {401, "zai/invalid_key.json"},
+	})
+
+	s := p.fetchFromProvider(zaiProvider{}, p.getConfiguration())
+	if s.Status != "warning" {
+		t.Errorf("status = %q, want warning", s.Status)
+	}
+	info := s.Data.(ZaiQuotaInfo)
+	if info.TokensTotal != 800000000 || len(info.Keys) != 2 {
+		t.Fatalf("pool = %+v, want the good key's quota and both keys", info)
+	}
+	if info.Keys[0].Error != "" || !strings.Contains(info.Keys[1].Error, "HTTP 401") {
+		t.Errorf("keys = %+v, want only ops failed", info.Keys)
+	}
+}
+
+const (
+	openAICostsURL        = "https://api.openai.com/v1/organization/costs"
+	openAICreditGrantsURL = "https://api.openai.com/dashboard/billing/credit_grants"
+)
+
+func TestOpenAICosts(t *testing.T) {
+	config := &Configuration{OpenaiEnabled: true, OpenaiApiKey: "sk-admin-test", OpenaiMonthlyBudget: "100"}
+	p := newFixturePlugin(t, config, map[string]fixture{
+		openAICostsURL:        {200, "openai/costs.json"},
+		openAICreditGrantsURL: {200, "openai/credit_grants.json"},
+		"https://api.openai.com/v1/organization/projects/proj_research": {200, "openai/project.json"},
+	})
+
+	s := p.fetchFromProvider(openAIProvider{}, config)
+	if s.Status != "ok" || s.Error != "" {
+		t.Fatalf("status = %q, error %q; want ok", s.Status, s.Error)
+	}
+	info := s.Data.(OpenAIUsageInfo)
+	if info.TotalCost != 35 || info.Currency != "USD" || info.Budget != 100 || info.BucketCount != 2 {
+		t.Errorf("cost = %v %s of %v in %d buckets, want 35 USD of 100 in 2", info.TotalCost, info.Currency, info.Budget, info.BucketCount)
+	}
+	wantProjects := []OpenAICostItem{{ID: "proj_research", Name: "Research", Cost: 20, Percent: 57.1}, {ID: "", Cost: 15, Percent: 42.9}}
+	if fmt.Sprint(info.ProjectCosts) != fmt.Sprint(wantProjects) {
+		t.Errorf("projects = %+v\nwant %+v", info.ProjectCosts, wantProjects)
+	}
+	// Line items of one model add up
+	wantModels := []OpenAICostItem{{ID: "gpt-4o-2024-08-06", Cost: 20, Percent: 57.1}, {ID: "o3-mini", Cost: 15, Percent: 42.9}}
+	if fmt.Sprint(info.ModelCosts) != fmt.Sprint(wantModels) {
+		t.Errorf("models = %+v\nwant %+v", info.ModelCosts, wantModels)
+	}
+	if info.CreditSource != "api" || info.CreditBalance != 74.5 || info.RemainingFunds == nil || *info.RemainingFunds != 74.5 {
+		t.Errorf("credits = %v from %q, remaining %v; want 74.5 from the API", info.CreditBalance, info.CreditSource, info.RemainingFunds)
+	}
+}
+
+func TestOpenAICostsNeedAdminKey(t *testing.T) {
+	config := &Configuration{OpenaiEnabled: true, OpenaiApiKey: "sk-proj-test"}
+	p := newFixturePlugin(t, config, map[string]fixture{
+		openAICostsURL: {403, "openai/admin_key_required.json"},
+	})
+
+	if s := p.fetchFromProvider(openAIProvider{}, config); s.Status != "error" || s.errorID != "error.openai_admin_key_required" {
+		t.Errorf("status = %q, error %q; want the admin key hint", s.Status, s.Error)
+	}
+}
+
+func TestOpenAICreditGrants(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		available float64
+		ok        bool
+	}{
+		{"complete", "openai/credit_grants.json", 74.5, true},
+		{"missing totals", "openai/credit_grants_partial.json", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Configuration{OpenaiEnabled: true, OpenaiApiKey: "sk-admin-test", StrictResponseChecks: true}
+			p := newFixturePlugin(t, config, map[string]fixture{openAICreditGrantsURL: {200, tt.file}})
+
+			grants, ok := p.fetchOpenAICredits(p.providerClient("openai", 0), config, openAIOrg{})
+			if ok != tt.ok || float64(grants.TotalAvailable) != tt.available {
+				t.Errorf("available = %v, %v; want %v, %v", float64(grants.TotalAvailable), ok, tt.available, tt.ok)
+			}
+		})
+	}
+}
+
+const claudeUsageURL = "https://api.anthropic.com/api/oauth/usage"
+
+func TestClaudeUsage(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{ClaudeEnabled: true, ClaudeAccessToken: "access"}, map[string]fixture{
+		claudeUsageURL: {200, "claude/usage.json"},
+	})
+
+	s := p.fetchFromProvider(claudeProvider{}, p.getConfiguration())
+	info := s.Data.(ClaudeUsageInfo)
+	if !info.HasData || info.Utilization5h != 42 || info.Utilization7d != 85 || info.SonnetUtil != 12 || info.OpusUtil != 0 {
+		t.Errorf("usage = %+v", info)
+	}
+	if info.Reset5h != "2025-10-16T15:00:00+00:00" || info.Reset7d != "2025-10-20T09:00:00+00:00" {
+		t.Errorf("resets = %q, %q", info.Reset5h, info.Reset7d)
+	}
+	// Only the weekly window is over the 80% threshold
+	if s.Status != "warning" || fmt.Sprint(info.Constrained) != "[7d]" {
+		t.Errorf("status = %q constrained by %v, want warning by 7d", s.Status, info.Constrained)
+	}
+}
+
+func TestClaudeRenewsExpiredToken(t *testing.T) {
+	p := newFixturePlugin(t, &Configuration{ClaudeEnabled: true, ClaudeAccessToken: "expired", ClaudeRefreshToken: "refresh"}, map[string]fixture{
+		"Bearer expired " + claudeUsageURL: {401, "claude/unauthorized.json"},
+		claudeUsageURL:                     {200, "claude/usage.json"},
+		claudeOAuthTokenURL:                {200, "claude/token.json"},
+	})
+
+	s := p.fetchFromProvider(claudeProvider{}, p.getConfiguration())
+	if s.Error != "" || !s.Data.(ClaudeUsageInfo).HasData {
+		t.Fatalf("status = %q, error %q; want the usage read with the renewed token", s.Status, s.Error)
+	}
+	if tokens, ok := p.getClaudeOAuthTokens(); !ok || tokens.AccessToken != "sk-ant-oat01-renewed" || tokens.RefreshToken != "sk-ant-ort01-renewed" {
+		t.Errorf("stored tokens = %+v, want the renewed ones", tokens)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ===== One provider's status =====
+
+// handleGetProviderStatus serves GET /api/v1/status/{provider}: the cards of one provider,
+// its instances included, as GET /api/v1/status would show them.
+func (p *Plugin) handleGetProviderStatus(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := findProvider(id); !ok {
+		http.Error(w, `{"error": "unknown_provider", "message": "Unknown provider"}`, http.StatusNotFound)
+		return
+	}
+	var services []ServiceStatus
+	for _, s := range p.collectStatuses() {
+		if cacheKeyProvider(s.ID) == id {
+			services = append(services, s)
+		}
+	}
+	p.trackStatusChanges(services)
+	userID := r.Header.Get("Mattermost-User-Id")
+	var own []ServiceStatus
+	for _, s := range p.withPersonalStatuses(services, userID) {
+		if cacheKeyProvider(s.ID) == id {
+			own = append(own, s)
+		}
+	}
+	services = own
+	uc := p.getUserContext(userID)
+	localizeStatuses(services, uc.Locale)
+	addResetViews(services, uc, time.Now())
+	addUsageMetrics(services)
+	setStaleAges(services, time.Now())
+	p.setRefreshTimes(services, time.Now())
+
+	config := p.getConfiguration()
+	resp := AllServicesResponse{Units: uc.Units, Total: totalCost(services, config), Demo: config.DemoMode, Services: services}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleRefreshProvider serves POST /api/v1/refresh/{provider}: it drops the cached
+// results and error backoff of one provider and its instances, then returns its cards as
+// fetched now. Every other provider keeps its cache, and so its rate limits.
+func (p *Plugin) handleRefreshProvider(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := findProvider(id); !ok {
+		http.Error(w, `{"error": "unknown_provider", "message": "Unknown provider"}`, http.StatusNotFound)
+		return
+	}
+	p.invalidateProviders(map[string]bool{id: true}, nil)
+	p.instancesLock.Lock()
+	for _, inst := range p.instances {
+		if inst.Type == id {
+			inst.plugin.invalidateProviders(map[string]bool{id: true}, nil)
+		}
+	}
+	p.instancesLock.Unlock()
+	p.dropPersonalPlugin(r.Header.Get("Mattermost-User-Id"), id)
+	// The next snapshot is gathered from the cache, so only this provider is fetched again
+	p.snapshot.clear()
+
+	p.handleGetProviderStatus(w, r, id)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Live dashboard updates =====
+
+// statusUpdateEvent is the WebSocket event carrying changed statuses; the webapp receives it
+// as "custom_<plugin id>_status_update".
+const statusUpdateEvent = "status_update"
+
+// statusFingerprint tells statuses apart by everything but when they were fetched.
+func statusFingerprint(s ServiceStatus) string {
+	s.CachedAt, s.AgeSeconds, s.RefreshInSeconds = 0, 0, 0
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// publishStatusChanges pushes the statuses that changed since the last poll to the open
+// dashboards of everyone with access, so they update without polling. The first poll
+// after activation only takes note, since dashboards fetch for themselves when opened.
+// Like digests, the statuses are in the server's language, and resets in UTC.
+func (p *Plugin) publishStatusChanges(services []ServiceStatus) {
+	previous := p.pushed
+	p.pushed = make(map[string]string, len(services))
+	var changed []ServiceStatus
+	for _, s := range services {
+		fp := statusFingerprint(s)
+		p.pushed[s.ID] = fp
+		if previous != nil && previous[s.ID] != fp {
+			changed = append(changed, s)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	now := time.Now()
+	localizeStatuses(changed, p.serverLocale())
+	addResetViews(changed, p.getUserContext(""), now)
+	addUsageMetrics(changed)
+	setStaleAges(changed, now)
+	p.setRefreshTimes(changed, now)
+	data, err := json.Marshal(changed)
+	if err != nil {
+		p.API.LogWarn("Failed to encode the status update", "error", err.Error())
+		return
+	}
+	p.publishToDashboards(statusUpdateEvent, map[string]any{"services": string(data)})
+}
+
+// publishToDashboards sends a WebSocket event to the users allowed to see the dashboard:
+// everyone, or the allowed users and the members of the allowed teams and channels.
+// Events can't be addressed to a role, so with allowed roles the users recently allowed
+// access get them too.
+func (p *Plugin) publishToDashboards(event string, payload map[string]any) {
+	config := p.getConfiguration()
+	if !config.accessRestricted() {
+		p.API.PublishWebSocketEvent(event, payload, &model.WebsocketBroadcast{})
+		return
+	}
+	for _, id := range strings.Split(config.AllowedUserIds, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			p.API.PublishWebSocketEvent(event, payload, &model.WebsocketBroadcast{UserId: id})
+		}
+	}
+	for _, id := range strings.Split(config.AllowedTeamIds, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			p.API.PublishWebSocketEvent(event, payload, &model.WebsocketBroadcast{TeamId: id})
+		}
+	}
+	for _, id := range splitList(config.AllowedChannelIds) {
+		p.API.PublishWebSocketEvent(event, payload, &model.WebsocketBroadcast{ChannelId: id})
+	}
+	if config.AllowedRoles != "" {
+		for _, id := range p.allowedUsers() {
+			p.API.PublishWebSocketEvent(event, payload, &model.WebsocketBroadcast{UserId: id})
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// ===== Refresh by reaction =====
+
+// statusPostProp marks the bot's posts that show provider status, with the card IDs they
+// show or "all", so reacting to them can refresh those providers.
+const statusPostProp = "ailimits_status"
+
+// reactionRefreshCooldown is how often reactions may refresh the same post.
+const reactionRefreshCooldown = time.Minute
+
+// refreshEmoji is the reaction that refreshes a status post, without colons.
+func (c *Configuration) refreshEmoji() string {
+	return strings.Trim(strings.TrimSpace(c.RefreshEmoji), ":")
+}
+
+// ReactionHasBeenAdded refreshes the providers shown in a status post when someone reacts
+// to it with the refresh emoji, edits the post with the fresh figures and takes the
+// reaction back, so it can be used again.
+func (p *Plugin) ReactionHasBeenAdded(c *plugin.Context, reaction *model.Reaction) {
+	emoji := p.getConfiguration().refreshEmoji()
+	if emoji == "" || reaction.EmojiName != emoji || p.botUserID == "" || reaction.UserId == p.botUserID {
+		return
+	}
+	post, appErr := p.API.GetPost(reaction.PostId)
+	if appErr != nil || post.UserId != p.botUserID {
+		return
+	}
+	shown, _ := post.GetProp(statusPostProp).(string)
+	isStatusPost := p.isStatusPost(post.Id)
+	if shown == "" && !isStatusPost {
+		return
+	}
+	if !p.checkAccess(reaction.UserId) || !p.canChange(reaction.UserId) {
+		return
+	}
+	p.markActive()
+
+	defer func() {
+		if appErr := p.API.RemoveReaction(reaction); appErr != nil {
+			p.API.LogDebug("Failed to remove the refresh reaction", "post_id", post.Id, "error", appErr.Error())
+		}
+	}()
+	if !p.claimReactionRefresh(post.Id) {
+		return
+	}
+
+	if isStatusPost || shown == "all" {
+		p.cacheLock.Lock()
+		p.cache = make(map[string]*CacheEntry)
+		p.cacheLock.Unlock()
+		p.clearBackoff()
+		p.clearInstanceCaches()
+	} else {
+		p.invalidateCards(strings.Split(shown, ","))
+	}
+
+	if isStatusPost {
+		services := p.collectStatuses()
+		p.trackStatusChanges(services)
+		p.updateStatusPost(services)
+		return
+	}
+
+	// A mention reply, in the language of whoever asked
+	uc := p.getUserContext(reaction.UserId)
+	if root, appErr := p.API.GetPost(post.RootId); appErr == nil {
+		uc = p.getUserContext(root.UserId)
+	}
+	ids := map[string]bool{}
+	for _, id := range strings.Split(shown, ",") {
+		ids[id] = true
+	}
+	text := p.statusText(uc, func(s ServiceStatus) bool { return shown == "all" || ids[s.ID] })
+	if text == "" || text == post.Message {
+		return
+	}
+	post.Message = text
+	if _, appErr := p.API.UpdatePost(post); appErr != nil {
+		p.API.LogWarn("Failed to refresh a status post", "post_id", post.Id, "error", appErr.Error())
+	}
+}
+
+// claimReactionRefresh reports whether a reaction may refresh the post now, at most once
+// per cooldown.
+func (p *Plugin) claimReactionRefresh(postID string) bool {
+	p.reactionLock.Lock()
+	defer p.reactionLock.Unlock()
+	if p.reactionRefreshed == nil {
+		p.reactionRefreshed = map[string]time.Time{}
+	}
+	if time.Since(p.reactionRefreshed[postID]) < reactionRefreshCooldown {
+		return false
+	}
+	for id, at := range p.reactionRefreshed {
+		if time.Since(at) > reactionRefreshCooldown {
+			delete(p.reactionRefreshed, id)
+		}
+	}
+	p.reactionRefreshed[postID] = time.Now()
+	return true
+}
+
+// isStatusPost reports whether the post is the live status post.
+func (p *Plugin) isStatusPost(postID string) bool {
+	var ref statusPostRef
+	if data, appErr := p.API.KVGet(statusPostKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &ref)
+	}
+	return ref.PostID != "" && ref.PostID == postID
+}
+
+// invalidateCards drops the cached results of the providers and instances behind the cards.
+func (p *Plugin) invalidateCards(cardIDs []string) {
+	ids := map[string]bool{}
+	cards := map[string]bool{}
+	for _, id := range cardIDs {
+		ids[strings.Split(id, ":")[0]] = true
+		cards[id] = true
+	}
+	p.invalidateProviders(ids, nil)
+
+	p.instancesLock.Lock()
+	defer p.instancesLock.Unlock()
+	for _, inst := range p.instances {
+		prefix := inst.Type + ":" + inst.ID
+		matched := false
+		for card := range cards {
+			matched = matched || card == prefix || strings.HasPrefix(card, prefix+":")
+		}
+		if matched {
+			inst.plugin.cacheLock.Lock()
+			inst.plugin.cache = make(map[string]*CacheEntry)
+			inst.plugin.cacheLock.Unlock()
+			inst.plugin.clearBackoff()
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ===== Provider interface =====
+
+// Provider is a service with a single card. Fetch returns the card's data, e.g. a
+// VercelGatewayInfo, and EvaluateStatus turns that data into "ok", "warning" or "error".
+// It's listed in the provider table with providerEntry, which handles caching and error
+// cards, so a provider only talks to its API. Services with a card per instance, such as
+// OpenAI's organizations, fetch each instance with fetchFromProvider.
+type Provider interface {
+	ID() string
+	Name() string
+	Enabled(config *Configuration) bool
+	Fetch(p *Plugin, config *Configuration) (interface{}, error)
+	EvaluateStatus(data interface{}, config *Configuration) string
+}
+
+// fetchError is an error that Fetch returns to show a specific message on the card. Other
+// errors are shown as a failed API call.
+type fetchError struct {
+	msgID  string
+	args   []interface{}
+	reauth bool
+}
+
+func (e *fetchError) Error() string {
+	return translate("en", e.msgID, e.args...)
+}
+
+// errFetch returns a fetch error with the given message.
+func errFetch(msgID string, args ...interface{}) error {
+	return &fetchError{msgID: msgID, args: args}
+}
+
+// errReauth returns a fetch error for credentials that expired or were revoked.
+func errReauth(msgID string) error {
+	return &fetchError{msgID: msgID, reauth: true}
+}
+
+// providerEntry adapts a Provider to the provider table.
+func providerEntry(prov Provider) provider {
+	return provider{
+		ID: prov.ID(), Name: prov.Name(),
+		Enabled: prov.Enabled,
+		Fetch: func(p *Plugin, config *Configuration) []ServiceStatus {
+			return []ServiceStatus{p.fetchFromProvider(prov, config)}
+		},
+	}
+}
+
+// fetchFromProvider returns the provider's cached card, or fetches and caches it. Failed
+// fetches aren't cached, so the next refresh retries.
+func (p *Plugin) fetchFromProvider(prov Provider, config *Configuration) ServiceStatus {
+	id, name := prov.ID(), prov.Name()
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	data, err := prov.Fetch(p, config)
+	if err != nil {
+		return fetchErrorStatus(id, name, err)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: prov.EvaluateStatus(data, config),
+		Data: data, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// fetchErrorStatus returns the card for a failed fetch: the fetch error's own message, or a
+// failed API call.
+func fetchErrorStatus(id, name string, err error) ServiceStatus {
+	var fe *fetchError
+	switch {
+	case errors.As(err, &fe) && fe.reauth:
+		return reauthStatus(id, name, fe.msgID)
+	case errors.As(err, &fe):
+		return errorStatus(id, name, fe.msgID, fe.args...)
+	}
+	return errorStatus(id, name, "error.api", err.Error())
+}
+
+// getJSON sends a request and decodes the 200 response into v, checking it against v's
+// schema. Other responses come back as fetch errors with the usual messages.
+func (p *Plugin) getJSON(id string, client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return errFetch("error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	drift, err := decodeResponse(body, v)
+	if err != nil {
+		return errFetch("error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errFetch("error.response_format", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== Reka AI (API key) =====
+
+// RekaInfo is the account's credit balance. The Reka platform doesn't expose billing or usage
+// through its API, so the balance comes from the configuration; the key is verified on every
+// refresh.
+type RekaInfo struct {
+	CreditBalance float64 `json:"creditBalance"`
+	HasBalance    bool    `json:"hasBalance"`
+	LowBalance    float64 `json:"lowBalance,omitempty"`
+	Currency      string  `json:"currency"`
+	Models        int     `json:"models"`
+}
+
+func (r RekaInfo) summary(locale string) string {
+	if !r.HasBalance {
+		return translate(locale, "summary.models_available", r.Models)
+	}
+	return translate(locale, "summary.credit_balance", formatMoney(r.CreditBalance, r.Currency, 2))
+}
+
+func (r RekaInfo) compact(name, locale string) string {
+	if r.HasBalance {
+		return translate(locale, "compact.balance_left", name, formatMoney(r.CreditBalance, r.Currency, 0))
+	}
+	return name
+}
+
+func (p *Plugin) getRekaStatus(config *Configuration) ServiceStatus {
+	const id, name = "reka", "Reka AI"
+	if config.RekaApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newRekaRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	// The model list is a bare array on the native API and {"data": [...]} on the
+	// OpenAI-compatible one, so it isn't schema-checked
+	var models []json.RawMessage
+	if err := json.Unmarshal(body, &models); err != nil {
+		var wrapped struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		models = wrapped.Data
+	}
+
+	info := RekaInfo{Currency: baseCurrency, Models: len(models)}
+	if strings.TrimSpace(config.RekaCreditBalance) != "" {
+		info.CreditBalance = p.budgetIn(config, id, config.RekaCreditBalance, info.Currency)
+		info.HasBalance = true
+		info.LowBalance = p.budgetIn(config, id, config.RekaLowBalance, info.Currency)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: rekaStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// rekaStatus warns below the configured low balance.
+func rekaStatus(info RekaInfo) string {
+	if info.HasBalance && info.LowBalance > 0 && info.CreditBalance < info.LowBalance {
+		return "warning"
+	}
+	return "ok"
+}
+
+func newRekaRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.reka.ai/v1/models", nil)
+	req.Header.Set("X-Api-Key", config.RekaApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Retries =====
+
+const (
+	// retryAttempts is how often a request is sent at most, the first try included.
+	retryAttempts = 3
+	// retryBaseDelay is the wait before the first retry. It doubles with each retry.
+	retryBaseDelay = 500 * time.Millisecond
+	// retryMaxWait is the longest Retry-After waited for within a fetch. A provider that
+	// asks for longer is left alone until then, by the error backoff.
+	retryMaxWait = 10 * time.Second
+	// rateLimitMaxBackoff bounds how long a Retry-After keeps a provider's failed result.
+	rateLimitMaxBackoff = time.Hour
+)
+
+// transient reports whether a failed attempt may succeed if sent again: a network error,
+// rate limiting, or a server error that isn't about the request itself.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(max(seconds, 0) * float64(time.Second)), true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// retryDelay is the wait before retry n (0 for the first): the provider's Retry-After,
+// or an exponential delay with jitter, so cards refreshed together don't retry together.
+func retryDelay(n int, resp *http.Response, now time.Time) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header, now); ok {
+			return d
+		}
+	}
+	delay := retryBaseDelay << n
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)))
+}
+
+// sendWithRetries sends the request, retrying transient failures while the request's
+// deadline leaves time to. Every provider request only reads, so queries sent as POST
+// are retried too; a body that can't be sent again isn't.
+func (t *healthTransport) sendWithRetries(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attemptReq := req
+	for n := 0; ; n++ {
+		resp, err := t.attempt(attemptReq)
+		if n == retryAttempts-1 || ctx.Err() != nil || !transient(resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+
+		now := time.Now()
+		wait := retryDelay(n, resp, now)
+		if wait > retryMaxWait {
+			t.plugin.markRateLimited(t.provider, now.Add(wait))
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		attemptReq = req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+		t.plugin.recordRetry(t.provider)
+	}
+}
+
+// recordRetry counts a retried request in the provider's health.
+func (p *Plugin) recordRetry(provider string) {
+	p.healthLock.Lock()
+	defer p.healthLock.Unlock()
+	p.healthOf(provider).retries++
+}
+
+// markRateLimited remembers that the provider asked not to be called before until.
+func (p *Plugin) markRateLimited(provider string, until time.Time) {
+	p.healthLock.Lock()
+	defer p.healthLock.Unlock()
+	if h := p.healthOf(provider); until.After(h.rateLimitedUntil) {
+		h.rateLimitedUntil = until
+	}
+}
+
+// rateLimitedFor is how much longer any of the providers asked not to be called, capped
+// at rateLimitMaxBackoff.
+func (p *Plugin) rateLimitedFor(now time.Time, providers ...string) time.Duration {
+	p.healthLock.Lock()
+	defer p.healthLock.Unlock()
+	var longest time.Duration
+	for _, provider := range providers {
+		if h, ok := p.health[provider]; ok {
+			longest = max(longest, h.rateLimitedUntil.Sub(now))
+		}
+	}
+	return min(longest, rateLimitMaxBackoff)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== RunPod (API key) =====
+
+// runPodMyselfQuery reads the balance, the account-wide burn rate and the pods and serverless
+// endpoints that make it up.
+const runPodMyselfQuery = `query {
+  myself {
+    clientBalance
+    currentSpendPerHr
+    spendLimit
+    pods { id name desiredStatus costPerHr gpuCount machine { gpuDisplayName } }
+    endpoints { id name workersMin workersMax }
+  }
+}`
+
+// RunPodInfo is the account's credit balance and how fast running pods and serverless
+// workers are drawing it down.
+type RunPodInfo struct {
+	CreditBalance   float64     `json:"creditBalance"`
+	LowBalance      float64     `json:"lowBalance,omitempty"`
+	SpendPerHour    float64     `json:"spendPerHour"`
+	PodSpendPerHour float64     `json:"podSpendPerHour"`
+	SpendLimit      float64     `json:"spendLimit,omitempty"`
+	RunwayHours     float64     `json:"runwayHours,omitempty"` // 0 when nothing is running
+	RunningPods     int         `json:"runningPods"`
+	Endpoints       int         `json:"endpoints"`
+	MinWorkers      int         `json:"minWorkers"`
+	Pods            []RunPodPod `json:"pods,omitempty"`
+}
+
+func (r RunPodInfo) summary(locale string) string {
+	text := translate(locale, "summary.runpod", formatMoney(r.CreditBalance, "USD", 2), formatMoney(r.SpendPerHour, "USD", 2))
+	if r.RunwayHours > 0 {
+		text += ", " + translate(locale, "summary.runpod_runway", formatDuration(time.Duration(r.RunwayHours*float64(time.Hour)), locale))
+	}
+	return text
+}
+
+func (r RunPodInfo) compact(name, locale string) string {
+	return translate(locale, "compact.balance_left", name, formatMoney(r.CreditBalance, "USD", 0))
+}
+
+// RunPodPod is a running pod and its hourly cost.
+type RunPodPod struct {
+	Name      string  `json:"name"`
+	GPU       string  `json:"gpu,omitempty"`
+	GPUCount  int     `json:"gpuCount"`
+	CostPerHr float64 `json:"costPerHr"`
+}
+
+// runPodResponse is the GraphQL response to runPodMyselfQuery.
+type runPodResponse struct {
+	Data struct {
+		Myself struct {
+			ClientBalance     flexFloat `json:"clientBalance" schema:"required"`
+			CurrentSpendPerHr flexFloat `json:"currentSpendPerHr" schema:"required"`
+			SpendLimit        flexFloat `json:"spendLimit"`
+			Pods              []struct {
+				ID            string    `json:"id" schema:"required"`
+				Name          string    `json:"name"`
+				DesiredStatus string    `json:"desiredStatus" schema:"required"`
+				CostPerHr     flexFloat `json:"costPerHr" schema:"required"`
+				GPUCount      flexFloat `json:"gpuCount"`
+				Machine       struct {
+					GPUDisplayName string `json:"gpuDisplayName"`
+				} `json:"machine"`
+			} `json:"pods"`
+			Endpoints []struct {
+				ID         string    `json:"id" schema:"required"`
+				Name       string    `json:"name"`
+				WorkersMin flexFloat `json:"workersMin"`
+				WorkersMax flexFloat `json:"workersMax"`
+			} `json:"endpoints"`
+		} `json:"myself" schema:"required"`
+	} `json:"data" schema:"required"`
+}
+
+func (p *Plugin) getRunPodStatus(config *Configuration) ServiceStatus {
+	const id, name = "runpod", "RunPod"
+	if config.RunpodApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newRunPodRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	// GraphQL reports failures such as a rejected key with HTTP 200 and no data
+	var gqlErrors struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	json.Unmarshal(body, &gqlErrors)
+	if len(gqlErrors.Errors) > 0 {
+		return errorStatus(id, name, "error.api", gqlErrors.Errors[0].Message)
+	}
+	var data runPodResponse
+	drift, err := decodeResponse(body, &data)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	me := data.Data.Myself
+	info := RunPodInfo{
+		CreditBalance: float64(me.ClientBalance),
+		SpendPerHour:  float64(me.CurrentSpendPerHr),
+		SpendLimit:    float64(me.SpendLimit),
+		Endpoints:     len(me.Endpoints),
+	}
+	for _, pod := range me.Pods {
+		if !strings.EqualFold(pod.DesiredStatus, "RUNNING") {
+			continue
+		}
+		info.RunningPods++
+		info.PodSpendPerHour += float64(pod.CostPerHr)
+		podName := pod.Name
+		if podName == "" {
+			podName = pod.ID
+		}
+		info.Pods = append(info.Pods, RunPodPod{
+			Name: podName, GPU: pod.Machine.GPUDisplayName,
+			GPUCount: int(pod.GPUCount), CostPerHr: float64(pod.CostPerHr),
+		})
+	}
+	sort.Slice(info.Pods, func(i, j int) bool { return info.Pods[i].CostPerHr > info.Pods[j].CostPerHr })
+	for _, ep := range me.Endpoints {
+		info.MinWorkers += int(ep.WorkersMin)
+	}
+	if info.SpendPerHour > 0 && info.CreditBalance > 0 {
+		info.RunwayHours = info.CreditBalance / info.SpendPerHour
+	}
+	info.LowBalance = p.budgetIn(config, id, config.RunpodLowBalance, "USD")
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: runPodStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// runPodStatus is an error once the balance is used up, when RunPod stops pods and serverless
+// workers, and a warning below the configured low balance or when the current burn rate
+// would use the balance up within a day.
+func runPodStatus(info RunPodInfo) string {
+	switch {
+	case info.CreditBalance <= 0:
+		return "error"
+	case info.LowBalance > 0 && info.CreditBalance < info.LowBalance:
+		return "warning"
+	case info.RunwayHours > 0 && info.RunwayHours < 24:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newRunPodRequest(config *Configuration) *http.Request {
+	payload, _ := json.Marshal(map[string]string{"query": runPodMyselfQuery})
+	req, _ := http.NewRequest("POST", "https://api.runpod.io/graphql", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+config.RunpodApiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// ===== Runway =====
+
+// prepaidFunds is one prepaid provider's credits, in its billing currency.
+type prepaidFunds struct {
+	balance  float64 // as the provider reports it, or as configured
+	inFlight float64 // this cycle's spend not yet taken off the balance
+	spend    float64 // this cycle's spend, for the burn rate
+	perDay   float64 // the provider's own burn rate, when it reports one
+	days     float64 // days into the cycle the spend covers
+	currency string
+}
+
+// daysIntoCycle estimates how far into a monthly cycle we are from the days until it resets.
+func daysIntoCycle(daysUntilReset int, now time.Time) float64 {
+	monthDays := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return math.Max(float64(monthDays-daysUntilReset), 1)
+}
+
+// prepaidFundsOf returns the credits of a provider that's paid in advance.
+func prepaidFundsOf(s ServiceStatus, now time.Time) (prepaidFunds, bool) {
+	switch d := s.Data.(type) {
+	case OpenAIUsageInfo:
+		if d.RemainingFunds != nil {
+			return prepaidFunds{balance: d.CreditBalance, inFlight: d.CreditBalance - *d.RemainingFunds, spend: d.TotalCost,
+				days: daysIntoCycle(d.DaysUntilReset, now), currency: d.currency()}, true
+		}
+	case AssemblyAIUsageInfo:
+		// The balance is configured, so this cycle's transcripts aren't taken off it yet
+		if d.HasBalance {
+			return prepaidFunds{balance: d.CreditBalance, inFlight: d.TotalCost, spend: d.TotalCost,
+				days: daysIntoCycle(d.DaysUntilReset, now), currency: d.Currency}, true
+		}
+	case DeepSeekBalanceInfo:
+		return prepaidFunds{balance: d.TotalBalance, currency: d.Currency}, true
+	case FalInfo:
+		f := prepaidFunds{balance: d.CreditBalance, currency: d.Currency}
+		if d.HasUsage {
+			f.spend, f.days = d.MonthlySpend, daysIntoCycle(d.DaysUntilReset, now)
+		}
+		return f, true
+	case MoonshotBalanceInfo:
+		return prepaidFunds{balance: d.AvailableBalance, currency: d.Currency}, true
+	case PerplexityInfo:
+		if d.HasBalance {
+			return prepaidFunds{balance: d.CreditBalance, currency: d.Currency}, true
+		}
+	case TogetherInfo:
+		if d.HasBalance {
+			return prepaidFunds{balance: d.CreditBalance, currency: d.Currency}, true
+		}
+	case RekaInfo:
+		if d.HasBalance {
+			return prepaidFunds{balance: d.CreditBalance, currency: d.Currency}, true
+		}
+	case RunPodInfo:
+		return prepaidFunds{balance: d.CreditBalance, perDay: d.SpendPerHour * 24, currency: baseCurrency}, true
+	case UpstageInfo:
+		if d.HasBalance {
+			return prepaidFunds{balance: d.CreditBalance, spend: d.MonthlyUsage,
+				days: daysIntoCycle(d.DaysUntilReset, now), currency: d.Currency}, true
+		}
+	case VercelGatewayInfo:
+		return prepaidFunds{balance: d.Balance, spend: d.MonthlySpend, days: daysIntoCycle(d.DaysUntilReset, now), currency: d.Currency}, true
+	case OpenRouterInfo:
+		// The month's spend is the monitored key's, not the whole account's
+		if d.HasCredits {
+			return prepaidFunds{balance: d.Balance, spend: d.UsageMonthly, days: daysIntoCycle(d.DaysUntilReset, now), currency: d.Currency}, true
+		}
+	case XaiUsageInfo:
+		if d.HasBilling {
+			return prepaidFunds{balance: d.CreditBalance, spend: d.MonthlySpend,
+				days: daysIntoCycle(d.DaysUntilReset, now), currency: d.Currency}, true
+		}
+	case YandexInfo:
+		return prepaidFunds{balance: d.Balance, spend: d.MonthlySpend, days: daysIntoCycle(d.DaysUntilReset, now), currency: d.Currency}, true
+	}
+	return prepaidFunds{}, false
+}
+
+// Runway pools the prepaid providers' credits into one wallet: what's left once the
+// spend not yet taken off the balances is paid, and how many days that lasts at the
+// current burn rate. Amounts are in the reporting currency.
+type Runway struct {
+	Credits    float64  `json:"credits"`
+	InFlight   float64  `json:"inFlight"`
+	Available  float64  `json:"available"`
+	BurnPerDay float64  `json:"burnPerDay"`
+	Days       *float64 `json:"days,omitempty"` // nil while nothing is being spent
+	Currency   string   `json:"currency"`
+	Providers  []string `json:"providers"` // card IDs in the wallet
+	// Providers whose currency has no exchange rate and were left out
+	Unconverted []string `json:"unconverted,omitempty"`
+}
+
+// computeRunway returns the wallet of the enabled prepaid providers, or nil when there
+// are none. Providers that report a balance but no spend add credits but no burn.
+func computeRunway(services []ServiceStatus, config *Configuration, now time.Time) *Runway {
+	runway := &Runway{Currency: config.reportingCurrency(), Providers: []string{}}
+	for _, s := range services {
+		if !s.Enabled || s.Error != "" {
+			continue
+		}
+		funds, ok := prepaidFundsOf(s, now)
+		if !ok {
+			continue
+		}
+		if funds.currency == "" {
+			funds.currency = baseCurrency
+		}
+		convert := func(amount float64) float64 {
+			v, converted := config.convertMoney(amount, funds.currency, runway.Currency)
+			ok = ok && converted
+			return v
+		}
+		burn := funds.perDay
+		if burn == 0 && funds.days > 0 {
+			burn = funds.spend / funds.days
+		}
+		balance, inFlight, burn := convert(funds.balance), convert(math.Max(funds.inFlight, 0)), convert(burn)
+		if !ok {
+			runway.Unconverted = append(runway.Unconverted, s.Name)
+			continue
+		}
+		runway.Credits += balance
+		runway.InFlight += inFlight
+		runway.BurnPerDay += burn
+		runway.Providers = append(runway.Providers, s.ID)
+	}
+	if len(runway.Providers) == 0 && len(runway.Unconverted) == 0 {
+		return nil
+	}
+
+	runway.Available = math.Max(runway.Credits-runway.InFlight, 0)
+	if runway.BurnPerDay > 0 {
+		// Rounded, so the summary's ETag doesn't change with every fetch
+		days := math.Round(runway.Available/runway.BurnPerDay*10) / 10
+		runway.Days = &days
+	}
+	runway.Credits = math.Round(runway.Credits*100) / 100
+	runway.InFlight = math.Round(runway.InFlight*100) / 100
+	runway.Available = math.Round(runway.Available*100) / 100
+	runway.BurnPerDay = math.Round(runway.BurnPerDay*100) / 100
+	return runway
+}
+
+// runwayText renders the runway as "≈ N days of AI left", or "" when it's unknown.
+func runwayText(runway *Runway, locale string) string {
+	if runway == nil || runway.Days == nil {
+		return ""
+	}
+	if *runway.Days < 1 {
+		return translate(locale, "runway.under_a_day")
+	}
+	return translate(locale, "runway.days_left", int(*runway.Days))
+}
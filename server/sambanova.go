@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== SambaNova (SambaNova Cloud API key) =====
+
+const defaultSambaNovaModels = "Meta-Llama-3.3-70B-Instruct"
+
+// SambaNovaUsageInfo is the request limit headroom of the monitored models. SambaNova reports
+// limits only in inference response headers; the account tier isn't exposed and comes from
+// the configuration.
+type SambaNovaUsageInfo struct {
+	Tier   string                 `json:"tier,omitempty"`
+	Models []SambaNovaModelLimits `json:"models"`
+}
+
+// SambaNovaModelLimits is one model's per-minute and daily request limits.
+type SambaNovaModelLimits struct {
+	Model           string  `json:"model"`
+	MinuteLimit     float64 `json:"minuteLimit"`
+	MinuteRemaining float64 `json:"minuteRemaining"`
+	HasDaily        bool    `json:"hasDaily"`
+	DayLimit        float64 `json:"dayLimit"`
+	DayRemaining    float64 `json:"dayRemaining"`
+	DayReset        string  `json:"dayReset,omitempty"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// percent is the larger share of the daily and per-minute request limits consumed.
+func (m SambaNovaModelLimits) percent() float64 {
+	pct := 0.0
+	if m.MinuteLimit > 0 {
+		pct = (m.MinuteLimit - m.MinuteRemaining) / m.MinuteLimit * 100
+	}
+	if m.HasDaily && m.DayLimit > 0 {
+		pct = max(pct, (m.DayLimit-m.DayRemaining)/m.DayLimit*100)
+	}
+	return pct
+}
+
+// mostConstrained returns the reachable model with the least headroom, or false if none was reachable.
+func (d SambaNovaUsageInfo) mostConstrained() (SambaNovaModelLimits, bool) {
+	var worst SambaNovaModelLimits
+	found := false
+	for _, m := range d.Models {
+		if m.Error != "" {
+			continue
+		}
+		if !found || m.percent() > worst.percent() {
+			worst, found = m, true
+		}
+	}
+	return worst, found
+}
+
+func (d SambaNovaUsageInfo) summary(locale string) string {
+	m, ok := d.mostConstrained()
+	if !ok {
+		return ""
+	}
+	var text string
+	if m.HasDaily {
+		text = translate(locale, "summary.sambanova_daily", m.Model, formatCount(m.DayRemaining), formatCount(m.DayLimit), formatCount(m.MinuteLimit))
+	} else {
+		text = translate(locale, "summary.sambanova", m.Model, formatCount(m.MinuteRemaining), formatCount(m.MinuteLimit))
+	}
+	if d.Tier != "" {
+		text += ", " + translate(locale, "summary.sambanova_tier", d.Tier)
+	}
+	return text
+}
+
+func (d SambaNovaUsageInfo) usagePercent() (float64, bool) {
+	if m, ok := d.mostConstrained(); ok {
+		return m.percent(), true
+	}
+	return 0, false
+}
+
+func (d SambaNovaUsageInfo) resets(time.Time) []ResetInfo {
+	if m, ok := d.mostConstrained(); ok {
+		return []ResetInfo{{Kind: "daily_quota", At: parseTime(m.DayReset)}}
+	}
+	return nil
+}
+
+// sambaNovaModels is the configured list of models to monitor.
+func (c *Configuration) sambaNovaModels() []string {
+	models := splitList(c.SambanovaModels)
+	if len(models) == 0 {
+		return []string{defaultSambaNovaModels}
+	}
+	return models
+}
+
+func (p *Plugin) getSambaNovaStatus(config *Configuration) ServiceStatus {
+	const id, name = "sambanova", "SambaNova"
+	if config.SambanovaApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	now := time.Now().UTC()
+	info := SambaNovaUsageInfo{Tier: config.SambanovaTier}
+	failed := 0
+	for _, model := range config.sambaNovaModels() {
+		limits, err := p.fetchSambaNovaLimits(client, config, model, now)
+		if err != nil {
+			// An invalid key fails every model the same way
+			if errAuth, ok := err.(authError); ok {
+				return errorStatus(id, name, "error.http", errAuth.status, errAuth.message)
+			}
+			limits = SambaNovaModelLimits{Model: model, Error: err.Error()}
+			failed++
+		}
+		info.Models = append(info.Models, limits)
+	}
+	if failed == len(info.Models) {
+		return errorStatus(id, name, "error.api", info.Models[0].Error)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: sambaNovaStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// sambaNovaStatus is an error once a model's daily limit is used up.
+func sambaNovaStatus(info SambaNovaUsageInfo, config *Configuration) string {
+	status := "ok"
+	for _, m := range info.Models {
+		if m.Error != "" {
+			continue
+		}
+		if m.HasDaily && m.DayLimit > 0 && m.DayRemaining <= 0 {
+			return "error"
+		}
+		if m.percent() > config.warningPercent(90) {
+			status = "warning"
+		}
+	}
+	return status
+}
+
+// fetchSambaNovaLimits sends a one-token completion and reads the rate limit headers.
+// Rate limited responses carry the same headers, so they count as a reading too.
+func (p *Plugin) fetchSambaNovaLimits(client *http.Client, config *Configuration, model string, now time.Time) (SambaNovaModelLimits, error) {
+	resp, err := client.Do(newSambaNovaRequest(config, model))
+	if err != nil {
+		return SambaNovaModelLimits{}, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return SambaNovaModelLimits{}, err
+	}
+
+	if resp.StatusCode != 200 && resp.StatusCode != 429 {
+		var errResp struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		message := string(body[:min(len(body), 200)])
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			message = errResp.Error.Message
+		}
+		if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			return SambaNovaModelLimits{}, authError{status: resp.StatusCode, message: message}
+		}
+		return SambaNovaModelLimits{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, message)
+	}
+
+	limits := SambaNovaModelLimits{Model: model}
+	limits.MinuteLimit, _ = headerFloat(resp.Header, "x-ratelimit-limit-requests")
+	limits.MinuteRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-requests")
+	if limit, ok := headerFloat(resp.Header, "x-ratelimit-limit-requests-day"); ok {
+		limits.HasDaily = true
+		limits.DayLimit = limit
+		limits.DayRemaining, _ = headerFloat(resp.Header, "x-ratelimit-remaining-requests-day")
+		limits.DayReset = sambaNovaResetTime(resp.Header.Get("x-ratelimit-reset-requests-day"), now)
+	}
+	return limits, nil
+}
+
+// sambaNovaResetTime converts a reset header to an RFC 3339 timestamp. SambaNova sends
+// Unix timestamps; small values are treated as a delay in seconds.
+func sambaNovaResetTime(value string, now time.Time) string {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || v <= 0 {
+		return ""
+	}
+	if v > 1e9 {
+		return time.Unix(int64(v), 0).UTC().Format(time.RFC3339)
+	}
+	return now.Add(time.Duration(v * float64(time.Second))).Format(time.RFC3339)
+}
+
+func newSambaNovaRequest(config *Configuration, model string) *http.Request {
+	payload, _ := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "."}},
+		"max_tokens": 1,
+	})
+	req, _ := http.NewRequest("POST", "https://api.sambanova.ai/v1/chat/completions", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+config.SambanovaApiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
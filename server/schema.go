@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ===== Typed decoding and schema drift =====
+
+// schemaDrift lists how a provider response differs from the struct it's decoded into.
+// Paths use dots for objects and [] for array elements, e.g. "data[].results[].amount".
+type schemaDrift struct {
+	Unknown []string // fields in the response the plugin doesn't read
+	Missing []string // fields tagged schema:"required" that were absent or null
+}
+
+// merge adds the paths of other that aren't listed yet, keeping both lists sorted.
+// Used for endpoints whose items are decoded one at a time.
+func (d *schemaDrift) merge(other schemaDrift) {
+	d.Unknown = mergePaths(d.Unknown, other.Unknown)
+	d.Missing = mergePaths(d.Missing, other.Missing)
+}
+
+func mergePaths(into, from []string) []string {
+	for _, path := range from {
+		i := sort.SearchStrings(into, path)
+		if i < len(into) && into[i] == path {
+			continue
+		}
+		into = append(into, "")
+		copy(into[i+1:], into[i:])
+		into[i] = path
+	}
+	return into
+}
+
+// fingerprint identifies the shape of a response, so a notice is only raised when it changes.
+func (d schemaDrift) fingerprint() string {
+	return strings.Join(d.Unknown, ",") + "|" + strings.Join(d.Missing, ",")
+}
+
+// decodeResponse decodes body into v and reports unknown fields and missing required ones.
+// Decoding is lenient (unknown fields are ignored) so drift never breaks the dashboard on
+// its own; it only makes it visible.
+func decodeResponse(body []byte, v interface{}) (schemaDrift, error) {
+	if err := json.Unmarshal(body, v); err != nil {
+		return schemaDrift{}, err
+	}
+
+	var raw interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return schemaDrift{}, err
+	}
+
+	var drift schemaDrift
+	compareSchema(reflect.TypeOf(v), raw, "", &drift)
+	sort.Strings(drift.Unknown)
+	sort.Strings(drift.Missing)
+	return drift, nil
+}
+
+func compareSchema(t reflect.Type, raw interface{}, path string, drift *schemaDrift) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return
+		}
+		known := map[string]bool{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			known[name] = true
+
+			value, present := obj[name]
+			if (!present || value == nil) && field.Tag.Get("schema") == "required" {
+				drift.Missing = append(drift.Missing, joinPath(path, name))
+			}
+			if present {
+				compareSchema(field.Type, value, joinPath(path, name), drift)
+			}
+		}
+		for name := range obj {
+			if !known[name] {
+				drift.Unknown = append(drift.Unknown, joinPath(path, name))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := raw.([]interface{})
+		if !ok {
+			return
+		}
+		// Report each path once, however many elements share it
+		seen := map[string]bool{}
+		var elemDrift schemaDrift
+		for _, item := range list {
+			compareSchema(t.Elem(), item, path+"[]", &elemDrift)
+		}
+		for _, p := range elemDrift.Unknown {
+			if !seen["u"+p] {
+				seen["u"+p] = true
+				drift.Unknown = append(drift.Unknown, p)
+			}
+		}
+		for _, p := range elemDrift.Missing {
+			if !seen["m"+p] {
+				seen["m"+p] = true
+				drift.Missing = append(drift.Missing, p)
+			}
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// flexFloat accepts JSON numbers as well as numeric strings, which some providers use for money.
+type flexFloat float64
+
+func (f *flexFloat) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*f = flexFloat(v)
+	return nil
+}
+
+// checkSchema compares a provider's response shape with the last one seen and tells
+// admins when it changes. The first shape seen is recorded as the baseline; it only
+// raises a notice if required fields are already missing. With Strict Response Checks,
+// missing required fields are returned as an error, for the card to fail rather than
+// show zeros.
+func (p *Plugin) checkSchema(provider string, drift schemaDrift) error {
+	var strictErr error
+	if len(drift.Missing) > 0 && p.getConfiguration().StrictResponseChecks {
+		strictErr = fmt.Errorf("%s", strings.Join(drift.Missing, ", "))
+	}
+
+	key := "schema_" + provider
+	fingerprint := drift.fingerprint()
+
+	previous, appErr := p.API.KVGet(key)
+	if appErr != nil || string(previous) == fingerprint {
+		return strictErr
+	}
+	if appErr := p.API.KVSet(key, []byte(fingerprint)); appErr != nil {
+		p.API.LogWarn("Failed to store response schema", "provider", provider, "error", appErr.Error())
+		return strictErr
+	}
+	if previous == nil && len(drift.Missing) == 0 {
+		return strictErr
+	}
+
+	p.API.LogWarn("Provider response format changed", "provider", provider,
+		"unknown_fields", strings.Join(drift.Unknown, ","), "missing_fields", strings.Join(drift.Missing, ","))
+
+	locale := p.serverLocale()
+	message := translate(locale, "alert.schema_changed", provider)
+	if len(drift.Missing) > 0 {
+		message += "\n" + translate(locale, "alert.schema_missing", "`"+strings.Join(drift.Missing, "`, `")+"`")
+	}
+	if len(drift.Unknown) > 0 {
+		message += "\n" + translate(locale, "alert.schema_unknown", "`"+strings.Join(drift.Unknown, "`, `")+"`")
+	}
+	p.notifyAdmins(message)
+	return strictErr
+}
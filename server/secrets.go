@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ===== Secret references =====
+
+// maxSecretFileSize bounds a secret read from a file.
+const maxSecretFileSize = 64 * 1024
+
+// resolveSecret returns the value a credential setting refers to: "env:NAME" is read from
+// the server's environment and "file:/path" from a file, e.g. a mounted Kubernetes secret
+// or a Vault agent template. Other values are returned as they are.
+func resolveSecret(value string) (string, error) {
+	if name, ok := strings.CutPrefix(value, "env:"); ok {
+		v, set := os.LookupEnv(strings.TrimSpace(name))
+		if !set {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return strings.TrimSpace(v), nil
+	}
+	if path, ok := strings.CutPrefix(value, "file:"); ok {
+		f, err := os.Open(strings.TrimSpace(path))
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, maxSecretFileSize+1))
+		if err != nil {
+			return "", err
+		}
+		if len(data) > maxSecretFileSize {
+			return "", fmt.Errorf("%s is larger than %d KB", path, maxSecretFileSize/1024)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return value, nil
+}
+
+// isCredentialSetting reports whether a configuration key holds a single credential.
+func isCredentialSetting(key string) bool {
+	return credentialKeyPattern.MatchString(key) || strings.HasSuffix(key, "secret")
+}
+
+// secretReference is a credential setting given as a reference, with the value it
+// resolved to.
+type secretReference struct {
+	Ref   string
+	Value string
+}
+
+// resolveSecrets returns the configuration with the credential settings that are
+// references replaced by their values, remembering the references so they, rather than
+// the values, are saved back. A reference that can't be resolved leaves the setting empty.
+func resolveSecrets(config *Configuration) (*Configuration, []error) {
+	settings := configurationMap(config)
+	refs := map[string]secretReference{}
+	var errs []error
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ref, ok := settings[key].(string)
+		if !ok || !isCredentialSetting(key) || !strings.HasPrefix(ref, "env:") && !strings.HasPrefix(ref, "file:") {
+			continue
+		}
+		value, err := resolveSecret(ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", key, err))
+		}
+		settings[key] = value
+		refs[key] = secretReference{Ref: ref, Value: value}
+	}
+	if len(refs) == 0 {
+		return config, errs
+	}
+
+	resolved := &Configuration{}
+	data, _ := json.Marshal(settings)
+	json.Unmarshal(data, resolved)
+	resolved.secretRefs = refs
+	return resolved, errs
+}
+
+// withSecretReferences returns the configuration settings to save or export: credentials
+// that came from a reference get their reference back. A credential changed since, e.g.
+// renewed by the plugin or entered in the setup wizard, is saved as its new value.
+// Settings the configuration document set get their System Console values back.
+func (c *Configuration) withSecretReferences() map[string]interface{} {
+	settings := configurationMap(c)
+	for key, ref := range c.secretRefs {
+		if settings[key] == ref.Value {
+			settings[key] = ref.Ref
+		}
+	}
+	if c.document != nil {
+		for key, o := range c.document.overrides {
+			if settings[key] == o.value {
+				settings[key] = o.base
+			}
+		}
+	}
+	return settings
+}
+
+// refreshSecrets re-reads the referenced credentials, so a rotated secret file or a
+// restarted Vault agent takes effect without saving the configuration.
+func (p *Plugin) refreshSecrets() {
+	p.configurationLock.RLock()
+	current := p.configuration
+	p.configurationLock.RUnlock()
+	if current == nil || len(current.secretRefs) == 0 {
+		return
+	}
+
+	changed := false
+	for _, ref := range current.secretRefs {
+		if value, err := resolveSecret(ref.Ref); err == nil && value != ref.Value {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// Resolve from the references, not from the values they resolved to
+	raw := &Configuration{}
+	data, _ := json.Marshal(current.withSecretReferences())
+	json.Unmarshal(data, raw)
+	p.API.LogInfo("Referenced credentials changed, reloading the affected providers")
+	p.useConfiguration(raw)
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ===== Self-hosted LLM server (Ollama or OpenAI-compatible) =====
+
+const defaultSelfHostedURL = "http://localhost:11434"
+
+// SelfHostedInfo is the availability of an internal inference server. There is no quota
+// to run out of; being down or unable to generate is the limit worth showing.
+type SelfHostedInfo struct {
+	ServerType string                `json:"serverType"` // "ollama" or "openai"
+	Version    string                `json:"version,omitempty"`
+	LatencyMs  int64                 `json:"latencyMs"`
+	Models     []SelfHostedModel     `json:"models"`
+	Loaded     int                   `json:"loaded"`
+	Throughput *SelfHostedThroughput `json:"throughput,omitempty"`
+}
+
+func (s SelfHostedInfo) summary(locale string) string {
+	var text string
+	if s.ServerType == "openai" {
+		text = translate(locale, "summary.selfhosted_models", len(s.Models), s.LatencyMs)
+	} else {
+		text = translate(locale, "summary.selfhosted", s.Loaded, len(s.Models), s.LatencyMs)
+	}
+	if t := s.Throughput; t != nil {
+		if t.Error != "" {
+			text += ", " + translate(locale, "summary.selfhosted_generate_failed", t.Model)
+		} else {
+			text += ", " + translate(locale, "summary.selfhosted_throughput", t.TokensPerSecond, t.Model)
+		}
+	}
+	return text
+}
+
+func (s SelfHostedInfo) compact(name, locale string) string {
+	if t := s.Throughput; t != nil && t.Error == "" {
+		return fmt.Sprintf("%s %.0f tok/s", name, t.TokensPerSecond)
+	}
+	return name
+}
+
+// SelfHostedModel is one model the server can serve. Only Ollama reports sizes and which
+// models are loaded into memory.
+type SelfHostedModel struct {
+	Name      string  `json:"name"`
+	Size      float64 `json:"size,omitempty"` // bytes on disk
+	Loaded    bool    `json:"loaded"`
+	VRAM      float64 `json:"vram,omitempty"` // bytes of the loaded model in GPU memory
+	ExpiresAt string  `json:"expiresAt,omitempty"`
+}
+
+// SelfHostedThroughput is the generation speed of a short test completion.
+type SelfHostedThroughput struct {
+	Model                 string  `json:"model"`
+	TokensPerSecond       float64 `json:"tokensPerSecond"`
+	PromptTokensPerSecond float64 `json:"promptTokensPerSecond,omitempty"` // Ollama only
+	Error                 string  `json:"error,omitempty"`
+}
+
+// ollamaTagsResponse is GET /api/tags, the installed models.
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name string    `json:"name" schema:"required"`
+		Size flexFloat `json:"size"`
+	} `json:"models" schema:"required"`
+}
+
+// ollamaPsResponse is GET /api/ps, the models loaded into memory.
+type ollamaPsResponse struct {
+	Models []struct {
+		Name      string    `json:"name" schema:"required"`
+		Size      flexFloat `json:"size"`
+		SizeVRAM  flexFloat `json:"size_vram"`
+		ExpiresAt string    `json:"expires_at"`
+	} `json:"models" schema:"required"`
+}
+
+// ollamaGenerateResponse is a non-streaming POST /api/generate. Durations are in nanoseconds.
+type ollamaGenerateResponse struct {
+	EvalCount          flexFloat `json:"eval_count" schema:"required"`
+	EvalDuration       flexFloat `json:"eval_duration" schema:"required"`
+	PromptEvalCount    flexFloat `json:"prompt_eval_count"`
+	PromptEvalDuration flexFloat `json:"prompt_eval_duration"`
+}
+
+// openAIModelsResponse is GET /v1/models of an OpenAI-compatible server.
+type openAIModelsResponse struct {
+	Data []struct {
+		ID string `json:"id" schema:"required"`
+	} `json:"data" schema:"required"`
+}
+
+// selfHostedURL is the configured server base URL without a trailing slash or /v1 suffix.
+func (c *Configuration) selfHostedURL() string {
+	url := strings.TrimRight(strings.TrimSpace(c.SelfhostedUrl), "/")
+	if url == "" {
+		return defaultSelfHostedURL
+	}
+	return strings.TrimSuffix(url, "/v1")
+}
+
+// selfHostedType is "openai" for OpenAI-compatible servers such as vLLM or llama.cpp, and
+// "ollama" otherwise.
+func (c *Configuration) selfHostedType() string {
+	if c.SelfhostedServerType == "openai" {
+		return "openai"
+	}
+	return "ollama"
+}
+
+func (p *Plugin) getSelfHostedStatus(config *Configuration) ServiceStatus {
+	const id, name = "selfhosted", "Self-hosted LLM"
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	info := SelfHostedInfo{ServerType: config.selfHostedType()}
+	var drift schemaDrift
+	var err error
+	if info.ServerType == "openai" {
+		drift, err = p.fetchOpenAICompatibleModels(client, config, &info)
+	} else {
+		drift, err = p.fetchOllamaModels(client, config, &info)
+	}
+	if err != nil {
+		if errParse, ok := err.(parseError); ok {
+			return errorStatus(id, name, "error.parse", errParse.err.Error(), errParse.body)
+		}
+		return errorStatus(id, name, "error.selfhosted_unreachable", config.selfHostedURL(), err.Error())
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	if config.SelfhostedThroughput {
+		info.Throughput = p.measureSelfHostedThroughput(client, config, info)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: selfHostedStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// selfHostedStatus warns when the server answers but can't generate.
+func selfHostedStatus(info SelfHostedInfo) string {
+	if info.Throughput != nil && info.Throughput.Error != "" {
+		return "warning"
+	}
+	return "ok"
+}
+
+// parseError is a response that arrived but couldn't be decoded, as opposed to an unreachable server.
+type parseError struct {
+	err  error
+	body string
+}
+
+func (e parseError) Error() string {
+	return e.err.Error()
+}
+
+// selfHostedGet sends a request, timing it into latency, and returns the body of a successful response.
+func (p *Plugin) selfHostedGet(client *http.Client, req *http.Request, latency *int64) ([]byte, error) {
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if latency != nil {
+		*latency = time.Since(start).Milliseconds()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+// fetchOllamaModels reads the version, installed models and the models currently loaded.
+func (p *Plugin) fetchOllamaModels(client *http.Client, config *Configuration, info *SelfHostedInfo) (schemaDrift, error) {
+	body, err := p.selfHostedGet(client, newSelfHostedRequest(config, "GET", "/api/version", nil), &info.LatencyMs)
+	if err != nil {
+		return schemaDrift{}, err
+	}
+	var version struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(body, &version) == nil {
+		info.Version = version.Version
+	}
+
+	body, err = p.selfHostedGet(client, newSelfHostedRequest(config, "GET", "/api/tags", nil), nil)
+	if err != nil {
+		return schemaDrift{}, err
+	}
+	var tags ollamaTagsResponse
+	drift, err := decodeResponse(body, &tags)
+	if err != nil {
+		return drift, parseError{err: err, body: string(body[:min(len(body), 200)])}
+	}
+
+	body, err = p.selfHostedGet(client, newSelfHostedRequest(config, "GET", "/api/ps", nil), nil)
+	if err != nil {
+		return drift, err
+	}
+	var ps ollamaPsResponse
+	d, err := decodeResponse(body, &ps)
+	if err != nil {
+		return drift, parseError{err: err, body: string(body[:min(len(body), 200)])}
+	}
+	drift.merge(d)
+
+	models := map[string]*SelfHostedModel{}
+	for _, m := range tags.Models {
+		models[m.Name] = &SelfHostedModel{Name: m.Name, Size: float64(m.Size)}
+	}
+	for _, m := range ps.Models {
+		model, ok := models[m.Name]
+		if !ok {
+			model = &SelfHostedModel{Name: m.Name, Size: float64(m.Size)}
+			models[m.Name] = model
+		}
+		model.Loaded = true
+		model.VRAM = float64(m.SizeVRAM)
+		model.ExpiresAt = m.ExpiresAt
+		info.Loaded++
+	}
+	for _, m := range models {
+		info.Models = append(info.Models, *m)
+	}
+	sortSelfHostedModels(info.Models)
+	return drift, nil
+}
+
+// fetchOpenAICompatibleModels lists the served models. OpenAI-compatible servers don't
+// report a version or what is loaded, so every listed model counts as loaded.
+func (p *Plugin) fetchOpenAICompatibleModels(client *http.Client, config *Configuration, info *SelfHostedInfo) (schemaDrift, error) {
+	body, err := p.selfHostedGet(client, newSelfHostedRequest(config, "GET", "/v1/models", nil), &info.LatencyMs)
+	if err != nil {
+		return schemaDrift{}, err
+	}
+	var models openAIModelsResponse
+	drift, err := decodeResponse(body, &models)
+	if err != nil {
+		return drift, parseError{err: err, body: string(body[:min(len(body), 200)])}
+	}
+	for _, m := range models.Data {
+		info.Models = append(info.Models, SelfHostedModel{Name: m.ID, Loaded: true})
+	}
+	info.Loaded = len(info.Models)
+	sortSelfHostedModels(info.Models)
+	return drift, nil
+}
+
+// sortSelfHostedModels lists loaded models first, then by name.
+func sortSelfHostedModels(models []SelfHostedModel) {
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].Loaded != models[j].Loaded {
+			return models[i].Loaded
+		}
+		return models[i].Name < models[j].Name
+	})
+}
+
+// measureSelfHostedThroughput generates a few tokens with the first loaded model. Nothing
+// is measured while no model is loaded, so a refresh never makes the server load one.
+func (p *Plugin) measureSelfHostedThroughput(client *http.Client, config *Configuration, info SelfHostedInfo) *SelfHostedThroughput {
+	if len(info.Models) == 0 || !info.Models[0].Loaded {
+		return nil
+	}
+	result := &SelfHostedThroughput{Model: info.Models[0].Name}
+
+	if info.ServerType == "openai" {
+		payload, _ := json.Marshal(map[string]any{
+			"model":      result.Model,
+			"messages":   []map[string]string{{"role": "user", "content": "Count from one to ten."}},
+			"max_tokens": 32,
+		})
+		start := time.Now()
+		body, err := p.selfHostedGet(client, newSelfHostedRequest(config, "POST", "/v1/chat/completions", payload), nil)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		var completion struct {
+			Usage struct {
+				CompletionTokens flexFloat `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal(body, &completion); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		// Wall time includes prompt processing and the network, so this understates decoding speed
+		if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+			result.TokensPerSecond = float64(completion.Usage.CompletionTokens) / elapsed
+		}
+		return result
+	}
+
+	payload, _ := json.Marshal(map[string]any{
+		"model":   result.Model,
+		"prompt":  "Count from one to ten.",
+		"stream":  false,
+		"options": map[string]any{"num_predict": 32},
+	})
+	body, err := p.selfHostedGet(client, newSelfHostedRequest(config, "POST", "/api/generate", payload), nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	var gen ollamaGenerateResponse
+	if _, err := decodeResponse(body, &gen); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if gen.EvalDuration > 0 {
+		result.TokensPerSecond = float64(gen.EvalCount) / (float64(gen.EvalDuration) / 1e9)
+	}
+	if gen.PromptEvalDuration > 0 {
+		result.PromptTokensPerSecond = float64(gen.PromptEvalCount) / (float64(gen.PromptEvalDuration) / 1e9)
+	}
+	return result
+}
+
+// newSelfHostedRequest builds a request to the configured server. The API key is optional,
+// for servers behind an authenticating proxy.
+func newSelfHostedRequest(config *Configuration, method, path string, payload []byte) *http.Request {
+	req, _ := http.NewRequest(method, config.selfHostedURL()+path, bytes.NewReader(payload))
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if config.SelfhostedApiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+config.SelfhostedApiKey)
+	}
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
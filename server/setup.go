@@ -0,0 +1,875 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Guided setup =====
+
+const pluginURL = "/plugins/com.fambear.ai-limits-monitor"
+
+// setupStep describes one dialog of the setup wizard. Field names are the
+// Configuration JSON keys, so a submission maps straight onto the config.
+type setupStep struct {
+	ID         string
+	Name       string
+	EnabledKey string // config key switched on when the step is saved
+	Secret     string // config key of the credential that's validated
+}
+
+var setupSteps = []setupStep{
+	{ID: "augment", Name: "Augment Code", EnabledKey: "augmentenabled", Secret: "augmentaccesstoken"},
+	{ID: "zai", Name: "Z.AI", EnabledKey: "zaienabled", Secret: "zaiapikey"},
+	{ID: "openai", Name: "OpenAI", EnabledKey: "openaienabled", Secret: "openaiapikey"},
+	{ID: "claude", Name: "claude.ai", EnabledKey: "claudeenabled", Secret: "claudeaccesstoken"},
+	{ID: "anthropic", Name: "Anthropic API", EnabledKey: "anthropicenabled", Secret: "anthropicadminkey"},
+	{ID: "copilot", Name: "GitHub Copilot", EnabledKey: "copilotenabled", Secret: "copilottoken"},
+	{ID: "cursor", Name: "Cursor", EnabledKey: "cursorenabled", Secret: "cursorapikey"},
+	{ID: "mistral", Name: "Mistral", EnabledKey: "mistralenabled", Secret: "mistralapikey"},
+	{ID: "groq", Name: "Groq", EnabledKey: "groqenabled", Secret: "groqapikey"},
+	{ID: "deepseek", Name: "DeepSeek", EnabledKey: "deepseekenabled", Secret: "deepseekapikey"},
+	{ID: "perplexity", Name: "Perplexity", EnabledKey: "perplexityenabled", Secret: "perplexityapikey"},
+	{ID: "together", Name: "Together AI", EnabledKey: "togetherenabled", Secret: "togetherapikey"},
+	{ID: "bedrock", Name: "AWS Bedrock", EnabledKey: "bedrockenabled", Secret: "bedrocksecretaccesskey"},
+	{ID: "vertex", Name: "Google Vertex AI", EnabledKey: "vertexenabled", Secret: "vertexserviceaccountkey"},
+	{ID: "gemini", Name: "Google Gemini API", EnabledKey: "geminienabled", Secret: "geminiapikey"},
+	{ID: "azureopenai", Name: "Azure OpenAI", EnabledKey: "azureopenaienabled", Secret: "azureopenaiclientsecret"},
+	{ID: "xai", Name: "xAI", EnabledKey: "xaienabled", Secret: "xaiapikey"},
+	{ID: "windsurf", Name: "Windsurf", EnabledKey: "windsurfenabled", Secret: "windsurfservicekey"},
+	{ID: "tabnine", Name: "Tabnine", EnabledKey: "tabnineenabled"},
+	{ID: "jetbrains", Name: "JetBrains AI", EnabledKey: "jetbrainsenabled", Secret: "jetbrainsapikey"},
+	{ID: "cerebras", Name: "Cerebras", EnabledKey: "cerebrasenabled", Secret: "cerebrasapikey"},
+	{ID: "sambanova", Name: "SambaNova", EnabledKey: "sambanovaenabled", Secret: "sambanovaapikey"},
+	{ID: "selfhosted", Name: "Self-hosted LLM", EnabledKey: "selfhostedenabled", Secret: "selfhostedurl"},
+	{ID: "litellm", Name: "LiteLLM", EnabledKey: "litellmenabled", Secret: "litellmmasterkey"},
+	{ID: "helicone", Name: "Helicone", EnabledKey: "heliconeenabled", Secret: "heliconeapikey"},
+	{ID: "gateway", Name: defaultGatewayName, EnabledKey: "gatewayenabled", Secret: "gatewayurl"},
+	{ID: "elevenlabs", Name: "ElevenLabs", EnabledKey: "elevenlabsenabled", Secret: "elevenlabsapikey"},
+	{ID: "assemblyai", Name: "AssemblyAI", EnabledKey: "assemblyaienabled", Secret: "assemblyaiapikey"},
+	{ID: "supermaven", Name: "Supermaven", EnabledKey: "supermavenenabled"},
+	{ID: "amazonq", Name: "Amazon Q Developer", EnabledKey: "amazonqenabled", Secret: "amazonqsecretaccesskey"},
+	{ID: "moonshot", Name: "Moonshot AI", EnabledKey: "moonshotenabled", Secret: "moonshotapikey"},
+	{ID: "dashscope", Name: "Alibaba DashScope", EnabledKey: "dashscopeenabled", Secret: "dashscopeapikey"},
+	{ID: "ai21", Name: "AI21 Labs", EnabledKey: "ai21enabled", Secret: "ai21apikey"},
+	{ID: "fal", Name: "fal.ai", EnabledKey: "falenabled", Secret: "falapikey"},
+	{ID: "runpod", Name: "RunPod", EnabledKey: "runpodenabled", Secret: "runpodapikey"},
+	{ID: "yandex", Name: "Yandex Foundation Models", EnabledKey: "yandexenabled", Secret: "yandexserviceaccountkey"},
+	{ID: "gigachat", Name: "GigaChat", EnabledKey: "gigachatenabled", Secret: "gigachatauthkey"},
+	{ID: "reka", Name: "Reka AI", EnabledKey: "rekaenabled", Secret: "rekaapikey"},
+	{ID: "watsonx", Name: "IBM watsonx.ai", EnabledKey: "watsonxenabled", Secret: "watsonxapikey"},
+	{ID: "databricks", Name: "Databricks Model Serving", EnabledKey: "databricksenabled", Secret: "databrickstoken"},
+	{ID: "nvidia", Name: "NVIDIA NIM", EnabledKey: "nvidiaenabled", Secret: "nvidiaapikey"},
+	{ID: "vercel", Name: "Vercel AI Gateway", EnabledKey: "vercelenabled", Secret: "vercelapikey"},
+	{ID: "openrouter", Name: "OpenRouter", EnabledKey: "openrouterenabled", Secret: "openrouterapikey"},
+	{ID: "portkey", Name: "Portkey", EnabledKey: "portkeyenabled", Secret: "portkeyapikey"},
+	{ID: "githubmodels", Name: "GitHub Models", EnabledKey: "githubmodelsenabled", Secret: "githubmodelstoken"},
+	{ID: "codex", Name: "OpenAI Codex", EnabledKey: "codexenabled", Secret: "codexaccesstoken"},
+	{ID: "chatgpt", Name: "ChatGPT", EnabledKey: "chatgptenabled", Secret: "chatgptsessiontoken"},
+	{ID: "claudecode", Name: "Claude Code", EnabledKey: "claudecodeenabled"},
+	{ID: "upstage", Name: "Upstage Solar", EnabledKey: "upstageenabled", Secret: "upstageapikey"},
+	{ID: "aigateway", Name: defaultAIGatewayName, EnabledKey: "aigatewayenabled", Secret: "aigatewaymetricsurl"},
+	{ID: "alerts"},
+}
+
+func findSetupStep(id string) (setupStep, bool) {
+	for _, step := range setupSteps {
+		if step.ID == id {
+			return step, true
+		}
+	}
+	return setupStep{}, false
+}
+
+func (p *Plugin) isSystemAdmin(userID string) bool {
+	return p.API.HasPermissionTo(userID, model.PermissionManageSystem)
+}
+
+// executeSetupCommand handles `/ailimits setup [provider]`. With a provider it opens
+// that provider's dialog straight away; otherwise it offers a button per step.
+func (p *Plugin) executeSetupCommand(args *model.CommandArgs, uc userContext, stepID string) *model.CommandResponse {
+	if !p.isSystemAdmin(args.UserId) {
+		return ephemeralResponse(translate(uc.Locale, "setup.admin_only"))
+	}
+
+	if stepID != "" {
+		step, ok := findSetupStep(stepID)
+		if !ok {
+			return ephemeralResponse(translate(uc.Locale, "command.unknown"))
+		}
+		if err := p.openSetupDialog(args.TriggerId, step, uc.Locale); err != nil {
+			p.API.LogWarn("Failed to open setup dialog", "error", err.Error())
+		}
+		return &model.CommandResponse{}
+	}
+
+	var actions []*model.PostAction
+	for _, step := range setupSteps {
+		name := step.Name
+		if step.ID == "alerts" {
+			name = translate(uc.Locale, "setup.alerts_button")
+		}
+		actions = append(actions, &model.PostAction{
+			Id:   step.ID,
+			Name: name,
+			Type: model.PostActionTypeButton,
+			Integration: &model.PostActionIntegration{
+				URL:     pluginURL + "/api/v1/admin/setup/open",
+				Context: map[string]any{"step": step.ID},
+			},
+		})
+	}
+
+	post := &model.Post{
+		ChannelId: args.ChannelId,
+		UserId:    p.botUserID,
+		Message:   translate(uc.Locale, "setup.intro"),
+	}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{{Actions: actions}})
+	p.API.SendEphemeralPost(args.UserId, post)
+	return &model.CommandResponse{}
+}
+
+// handleSetupOpen opens the dialog for the button the admin clicked.
+func (p *Plugin) handleSetupOpen(w http.ResponseWriter, r *http.Request, userID string) {
+	var req model.PostActionIntegrationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+
+	stepID, _ := req.Context["step"].(string)
+	step, ok := findSetupStep(stepID)
+	if !ok {
+		http.Error(w, `{"error": "unknown_step", "message": "Unknown setup step"}`, http.StatusBadRequest)
+		return
+	}
+	if err := p.openSetupDialog(req.TriggerId, step, p.getUserContext(userID).Locale); err != nil {
+		p.API.LogWarn("Failed to open setup dialog", "error", err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.PostActionIntegrationResponse{})
+}
+
+func (p *Plugin) openSetupDialog(triggerID string, step setupStep, locale string) *model.AppError {
+	config := p.getConfiguration()
+
+	secret := func(name, labelID string, current string) *model.DialogElement {
+		el := &model.DialogElement{
+			DisplayName: translate(locale, labelID),
+			Name:        name,
+			Type:        "text",
+			SubType:     "password",
+		}
+		if current != "" {
+			el.Optional = true
+			el.HelpText = translate(locale, "setup.help_keep_secret")
+		}
+		return el
+	}
+	number := func(name, labelID, current string) *model.DialogElement {
+		return &model.DialogElement{
+			DisplayName: translate(locale, labelID),
+			Name:        name,
+			Type:        "text",
+			SubType:     "number",
+			Default:     current,
+			Optional:    true,
+		}
+	}
+	money := func(name, labelID, current string) *model.DialogElement {
+		return &model.DialogElement{
+			DisplayName: translate(locale, labelID),
+			Name:        name,
+			Type:        "text",
+			Default:     current,
+			Optional:    true,
+			HelpText:    translate(locale, "setup.help_money"),
+		}
+	}
+
+	title := translate(locale, "setup.dialog_title", step.Name)
+	var elements []model.DialogElement
+	switch step.ID {
+	case "augment":
+		elements = append(elements, *secret("augmentaccesstoken", "setup.field_token", config.AugmentAccessToken))
+	case "zai":
+		elements = append(elements, *secret("zaiapikey", "setup.field_api_key", config.ZaiApiKey))
+	case "openai":
+		elements = append(elements,
+			*secret("openaiapikey", "setup.field_api_key", config.OpenaiApiKey),
+			*money("openaimonthlybudget", "setup.field_budget", config.OpenaiMonthlyBudget),
+			*number("openaicreditbalance", "setup.field_credit_balance", config.OpenaiCreditBalance),
+		)
+	case "claude":
+		elements = append(elements,
+			*secret("claudeaccesstoken", "setup.field_token", config.ClaudeAccessToken),
+			*secret("clauderefreshtoken", "setup.field_refresh_token", config.ClaudeRefreshToken),
+		)
+		elements[1].Optional = true
+	case "anthropic":
+		elements = append(elements,
+			*secret("anthropicadminkey", "setup.field_api_key", config.AnthropicAdminKey),
+			*money("anthropicmonthlybudget", "setup.field_budget", config.AnthropicMonthlyBudget),
+		)
+	case "copilot":
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_github_org"),
+				Name:        "copilotorganization",
+				Type:        "text",
+				Default:     config.CopilotOrganization,
+			},
+			*secret("copilottoken", "setup.field_token", config.CopilotToken),
+		)
+	case "cursor":
+		elements = append(elements, *secret("cursorapikey", "setup.field_api_key", config.CursorApiKey))
+	case "mistral":
+		elements = append(elements, *secret("mistralapikey", "setup.field_api_key", config.MistralApiKey))
+	case "groq":
+		elements = append(elements, *secret("groqapikey", "setup.field_api_key", config.GroqApiKey))
+	case "deepseek":
+		elements = append(elements,
+			*secret("deepseekapikey", "setup.field_api_key", config.DeepseekApiKey),
+			*money("deepseeklowbalance", "setup.field_low_balance", config.DeepseekLowBalance),
+		)
+	case "perplexity":
+		elements = append(elements,
+			*secret("perplexityapikey", "setup.field_api_key", config.PerplexityApiKey),
+			*money("perplexitycreditbalance", "setup.field_credit_balance", config.PerplexityCreditBalance),
+		)
+	case "together":
+		elements = append(elements,
+			*secret("togetherapikey", "setup.field_api_key", config.TogetherApiKey),
+			*money("togethercreditbalance", "setup.field_credit_balance", config.TogetherCreditBalance),
+		)
+	case "bedrock":
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_aws_access_key"),
+				Name:        "bedrockaccesskeyid",
+				Type:        "text",
+				Default:     config.BedrockAccessKeyId,
+			},
+			*secret("bedrocksecretaccesskey", "setup.field_aws_secret_key", config.BedrockSecretAccessKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_aws_region"),
+				Name:        "bedrockregion",
+				Type:        "text",
+				Default:     config.bedrockRegion(),
+			},
+			*money("bedrockmonthlybudget", "setup.field_budget", config.BedrockMonthlyBudget),
+		)
+	case "vertex":
+		// The key is multi-line JSON, so it gets a textarea instead of a password field
+		key := secret("vertexserviceaccountkey", "setup.field_gcp_key", config.VertexServiceAccountKey)
+		key.Type, key.SubType = "textarea", ""
+		elements = append(elements,
+			*key,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_gcp_projects"),
+				Name:        "vertexprojects",
+				Type:        "text",
+				Default:     config.VertexProjects,
+				Optional:    true,
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_gcp_billing_table"),
+				Name:        "vertexbillingtable",
+				Type:        "text",
+				Default:     config.VertexBillingTable,
+				Optional:    true,
+			},
+			*money("vertexmonthlybudget", "setup.field_budget", config.VertexMonthlyBudget),
+		)
+	case "gemini":
+		// Either credential is enough: the key lists the models, the service account reads the quotas
+		apiKey := secret("geminiapikey", "setup.field_api_key", config.GeminiApiKey)
+		apiKey.Optional = true
+		account := secret("geminiserviceaccountkey", "setup.field_gcp_key", config.GeminiServiceAccountKey)
+		account.Type, account.SubType, account.Optional = "textarea", "", true
+		elements = append(elements,
+			*apiKey,
+			*account,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_gcp_project"),
+				Name:        "geminiproject",
+				Type:        "text",
+				Default:     config.GeminiProject,
+				Optional:    true,
+			},
+		)
+	case "azureopenai":
+		text := func(name, label, value string) model.DialogElement {
+			return model.DialogElement{DisplayName: translate(locale, label), Name: name, Type: "text", Default: value}
+		}
+		accounts := text("azureopenaiaccounts", "setup.field_azure_accounts", config.AzureOpenaiAccounts)
+		accounts.Optional = true
+		elements = append(elements,
+			text("azureopenaitenantid", "setup.field_azure_tenant", config.AzureOpenaiTenantId),
+			text("azureopenaiclientid", "setup.field_azure_client_id", config.AzureOpenaiClientId),
+			*secret("azureopenaiclientsecret", "setup.field_azure_client_secret", config.AzureOpenaiClientSecret),
+			text("azureopenaisubscriptionid", "setup.field_azure_subscription", config.AzureOpenaiSubscriptionId),
+			accounts,
+		)
+	case "xai":
+		// Billing needs the management key, but the API key alone is enough to monitor
+		management := secret("xaimanagementkey", "setup.field_management_key", config.XaiManagementKey)
+		management.Optional = true
+		elements = append(elements,
+			*secret("xaiapikey", "setup.field_api_key", config.XaiApiKey),
+			*management,
+			*money("xailowbalance", "setup.field_low_balance", config.XaiLowBalance),
+		)
+	case "windsurf":
+		elements = append(elements, *secret("windsurfservicekey", "setup.field_service_key", config.WindsurfServiceKey))
+	case "tabnine":
+		seats := number("tabnineseatspurchased", "setup.field_seats_purchased", config.TabnineSeatsPurchased)
+		seats.Optional = false
+		elements = append(elements,
+			*seats,
+			*number("tabnineseatsassigned", "setup.field_seats_assigned", config.TabnineSeatsAssigned),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_renewal_date"),
+				Name:        "tabninerenewaldate",
+				Type:        "text",
+				Default:     config.TabnineRenewalDate,
+				Placeholder: "YYYY-MM-DD",
+				Optional:    true,
+			},
+		)
+	case "jetbrains":
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_jetbrains_customer"),
+				Name:        "jetbrainscustomercode",
+				Type:        "text",
+				Default:     config.JetbrainsCustomerCode,
+			},
+			*secret("jetbrainsapikey", "setup.field_api_key", config.JetbrainsApiKey),
+		)
+	case "cerebras":
+		elements = append(elements, *secret("cerebrasapikey", "setup.field_api_key", config.CerebrasApiKey))
+	case "sambanova":
+		elements = append(elements, *secret("sambanovaapikey", "setup.field_api_key", config.SambanovaApiKey))
+	case "selfhosted":
+		// The key is only needed behind an authenticating proxy
+		apiKey := secret("selfhostedapikey", "setup.field_api_key", config.SelfhostedApiKey)
+		apiKey.Optional = true
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_server_url"),
+				Name:        "selfhostedurl",
+				Type:        "text",
+				Default:     config.selfHostedURL(),
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_server_type"),
+				Name:        "selfhostedservertype",
+				Type:        "select",
+				Default:     config.selfHostedType(),
+				Options: []*model.PostActionOptions{
+					{Text: "Ollama", Value: "ollama"},
+					{Text: translate(locale, "setup.server_type_openai"), Value: "openai"},
+				},
+			},
+			*apiKey,
+		)
+	case "litellm":
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_proxy_url"),
+				Name:        "litellmurl",
+				Type:        "text",
+				Default:     config.LitellmUrl,
+				Placeholder: "https://litellm.example.com",
+			},
+			*secret("litellmmasterkey", "setup.field_master_key", config.LitellmMasterKey),
+		)
+	case "helicone":
+		elements = append(elements,
+			*secret("heliconeapikey", "setup.field_api_key", config.HeliconeApiKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_helicone_url"),
+				Name:        "heliconeurl",
+				Type:        "text",
+				Default:     config.HeliconeUrl,
+				Placeholder: defaultHeliconeURL,
+				Optional:    true,
+			},
+			*money("heliconemonthlybudget", "setup.field_budget", config.HeliconeMonthlyBudget),
+		)
+	case "gateway":
+		// Usage fields are left to System Console; the dialog covers reachability and auth
+		apiKey := secret("gatewayapikey", "setup.field_api_key", config.GatewayApiKey)
+		apiKey.Optional = true
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_gateway_name"),
+				Name:        "gatewayname",
+				Type:        "text",
+				Default:     config.GatewayName,
+				Placeholder: defaultGatewayName,
+				Optional:    true,
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_server_url"),
+				Name:        "gatewayurl",
+				Type:        "text",
+				Default:     config.GatewayUrl,
+				Placeholder: "https://llm-gateway.example.com/v1",
+			},
+			*apiKey,
+		)
+	case "elevenlabs":
+		elements = append(elements, *secret("elevenlabsapikey", "setup.field_api_key", config.ElevenlabsApiKey))
+	case "assemblyai":
+		elements = append(elements,
+			*secret("assemblyaiapikey", "setup.field_api_key", config.AssemblyaiApiKey),
+			*money("assemblyaihourlyrate", "setup.field_hourly_rate", config.AssemblyaiHourlyRate),
+			*money("assemblyaicreditbalance", "setup.field_credit_balance", config.AssemblyaiCreditBalance),
+		)
+	case "supermaven":
+		seats := number("supermavenseatstotal", "setup.field_seats_purchased", config.SupermavenSeatsTotal)
+		seats.Optional = false
+		elements = append(elements,
+			*seats,
+			*number("supermavenseatsassigned", "setup.field_seats_assigned", config.SupermavenSeatsAssigned),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_renewal_date"),
+				Name:        "supermavenrenewaldate",
+				Type:        "text",
+				Default:     config.SupermavenRenewalDate,
+				Placeholder: "YYYY-MM-DD",
+				Optional:    true,
+			},
+		)
+	case "amazonq":
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_aws_access_key"),
+				Name:        "amazonqaccesskeyid",
+				Type:        "text",
+				Default:     config.AmazonqAccessKeyId,
+			},
+			*secret("amazonqsecretaccesskey", "setup.field_aws_secret_key", config.AmazonqSecretAccessKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_aws_region"),
+				Name:        "amazonqregion",
+				Type:        "text",
+				Default:     config.amazonQRegion(),
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_identity_store"),
+				Name:        "amazonqidentitystoreid",
+				Type:        "text",
+				Default:     config.AmazonqIdentityStoreId,
+				Placeholder: "d-1234567890",
+				Optional:    true,
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_subscription_group"),
+				Name:        "amazonqgroupid",
+				Type:        "text",
+				Default:     config.AmazonqGroupId,
+				Optional:    true,
+			},
+		)
+	case "moonshot":
+		platform := config.MoonshotPlatform
+		if platform != "china" {
+			platform = "global"
+		}
+		elements = append(elements,
+			*secret("moonshotapikey", "setup.field_api_key", config.MoonshotApiKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_platform"),
+				Name:        "moonshotplatform",
+				Type:        "select",
+				Default:     platform,
+				Options: []*model.PostActionOptions{
+					{Text: "platform.moonshot.ai", Value: "global"},
+					{Text: "platform.moonshot.cn", Value: "china"},
+				},
+			},
+			*money("moonshotlowbalance", "setup.field_low_balance", config.MoonshotLowBalance),
+		)
+	case "dashscope":
+		region := config.DashscopeRegion
+		if region != "china" {
+			region = "intl"
+		}
+		accessSecret := secret("dashscopeaccesssecret", "setup.field_access_key_secret", config.DashscopeAccessSecret)
+		accessSecret.Optional = true
+		elements = append(elements,
+			*secret("dashscopeapikey", "setup.field_api_key", config.DashscopeApiKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_platform"),
+				Name:        "dashscoperegion",
+				Type:        "select",
+				Default:     region,
+				Options: []*model.PostActionOptions{
+					{Text: "International (Singapore)", Value: "intl"},
+					{Text: "China (Beijing)", Value: "china"},
+				},
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_access_key_id"),
+				Name:        "dashscopeaccesskeyid",
+				Type:        "text",
+				Default:     config.DashscopeAccessKeyId,
+				Optional:    true,
+			},
+			*accessSecret,
+			*money("dashscopemonthlybudget", "setup.field_budget", config.DashscopeMonthlyBudget),
+		)
+	case "ai21":
+		elements = append(elements,
+			*secret("ai21apikey", "setup.field_api_key", config.Ai21ApiKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_trial_ends"),
+				Name:        "ai21trialends",
+				Type:        "text",
+				Default:     config.Ai21TrialEnds,
+				Placeholder: "YYYY-MM-DD",
+				Optional:    true,
+			},
+		)
+	case "fal":
+		elements = append(elements,
+			*secret("falapikey", "setup.field_api_key", config.FalApiKey),
+			*money("fallowbalance", "setup.field_low_balance", config.FalLowBalance),
+		)
+	case "runpod":
+		elements = append(elements,
+			*secret("runpodapikey", "setup.field_api_key", config.RunpodApiKey),
+			*money("runpodlowbalance", "setup.field_low_balance", config.RunpodLowBalance),
+		)
+	case "yandex":
+		key := secret("yandexserviceaccountkey", "setup.field_gcp_key", config.YandexServiceAccountKey)
+		key.Type, key.SubType = "textarea", ""
+		elements = append(elements,
+			*key,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_billing_account"),
+				Name:        "yandexbillingaccountid",
+				Type:        "text",
+				Default:     config.YandexBillingAccountId,
+			},
+			*money("yandexlowbalance", "setup.field_low_balance", config.YandexLowBalance),
+		)
+	case "gigachat":
+		elements = append(elements,
+			*secret("gigachatauthkey", "setup.field_auth_key", config.GigachatAuthKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_scope"),
+				Name:        "gigachatscope",
+				Type:        "select",
+				Default:     config.gigaChatScope(),
+				Options: []*model.PostActionOptions{
+					{Text: "GIGACHAT_API_PERS", Value: "GIGACHAT_API_PERS"},
+					{Text: "GIGACHAT_API_B2B", Value: "GIGACHAT_API_B2B"},
+					{Text: "GIGACHAT_API_CORP", Value: "GIGACHAT_API_CORP"},
+				},
+			},
+			*number("gigachatlowtokens", "setup.field_low_tokens", config.GigachatLowTokens),
+		)
+	case "reka":
+		elements = append(elements,
+			*secret("rekaapikey", "setup.field_api_key", config.RekaApiKey),
+			*money("rekacreditbalance", "setup.field_credit_balance", config.RekaCreditBalance),
+			*money("rekalowbalance", "setup.field_low_balance", config.RekaLowBalance),
+		)
+	case "watsonx":
+		elements = append(elements,
+			*secret("watsonxapikey", "setup.field_api_key", config.WatsonxApiKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_ibm_account"),
+				Name:        "watsonxaccountid",
+				Type:        "text",
+				Default:     config.WatsonxAccountId,
+			},
+			*number("watsonxunitlimit", "setup.field_resource_units", config.WatsonxUnitLimit),
+			*money("watsonxmonthlybudget", "setup.field_budget", config.WatsonxMonthlyBudget),
+		)
+	case "databricks":
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_workspace_url"),
+				Name:        "databrickshost",
+				Type:        "text",
+				SubType:     "url",
+				Default:     config.DatabricksHost,
+				Placeholder: "https://dbc-1234abcd-5678.cloud.databricks.com",
+			},
+			*secret("databrickstoken", "setup.field_token", config.DatabricksToken),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_warehouse"),
+				Name:        "databrickswarehouseid",
+				Type:        "text",
+				Default:     config.DatabricksWarehouseId,
+			},
+			*money("databricksmonthlybudget", "setup.field_budget", config.DatabricksMonthlyBudget),
+		)
+	case "nvidia":
+		elements = append(elements,
+			*secret("nvidiaapikey", "setup.field_api_key", config.NvidiaApiKey),
+			*number("nvidiacreditsleft", "setup.field_credits_left", config.NvidiaCreditsLeft),
+			*number("nvidiacreditstotal", "setup.field_credits_total", config.NvidiaCreditsTotal),
+		)
+	case "vercel":
+		elements = append(elements,
+			*secret("vercelapikey", "setup.field_api_key", config.VercelApiKey),
+			*money("vercellowbalance", "setup.field_low_balance", config.VercelLowBalance),
+		)
+	case "openrouter":
+		elements = append(elements,
+			*secret("openrouterapikey", "setup.field_api_key", config.OpenrouterApiKey),
+			*money("openrouterlowbalance", "setup.field_low_balance", config.OpenrouterLowBalance),
+		)
+	case "portkey":
+		elements = append(elements,
+			*secret("portkeyapikey", "setup.field_api_key", config.PortkeyApiKey),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_workspaces"),
+				Name:        "portkeyworkspaces",
+				Type:        "text",
+				Default:     config.PortkeyWorkspaces,
+				Placeholder: "production, research",
+				Optional:    true,
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_workspace_budgets"),
+				Name:        "portkeybudgets",
+				Type:        "text",
+				Default:     config.PortkeyBudgets,
+				Placeholder: "production=2000, research=500",
+				Optional:    true,
+			},
+		)
+	case "githubmodels":
+		elements = append(elements,
+			*secret("githubmodelstoken", "setup.field_token", config.GithubModelsToken),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_github_org"),
+				Name:        "githubmodelsorg",
+				Type:        "text",
+				Default:     config.GithubModelsOrg,
+				Optional:    true,
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_copilot_plan"),
+				Name:        "githubmodelsplan",
+				Type:        "select",
+				Default:     config.githubModelsPlan(),
+				Options: []*model.PostActionOptions{
+					{Text: "Copilot Free / Pro", Value: "free"},
+					{Text: "Copilot Business", Value: "business"},
+					{Text: "Copilot Enterprise", Value: "enterprise"},
+				},
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_models"),
+				Name:        "githubmodelsmodels",
+				Type:        "text",
+				Default:     config.GithubModelsModels,
+				Placeholder: defaultGithubModels,
+				Optional:    true,
+			},
+		)
+	case "codex":
+		elements = append(elements,
+			*secret("codexaccesstoken", "setup.field_token", config.CodexAccessToken),
+			*secret("codexrefreshtoken", "setup.field_refresh_token", config.CodexRefreshToken),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_chatgpt_account"),
+				Name:        "codexaccountid",
+				Type:        "text",
+				Default:     config.CodexAccountId,
+				Optional:    true,
+			},
+		)
+		elements[1].Optional = true
+	case "chatgpt":
+		elements = append(elements,
+			*secret("chatgptsessiontoken", "setup.field_session_token", config.ChatgptSessionToken),
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_chatgpt_account"),
+				Name:        "chatgptaccountid",
+				Type:        "text",
+				Default:     config.ChatgptAccountId,
+				Optional:    true,
+			},
+		)
+		// Without a session token the card uses the Codex login
+		elements[0].Optional = true
+	case "claudecode":
+		elements = append(elements, *money("claudecodedeveloperbudget", "setup.field_developer_budget", config.ClaudeCodeDeveloperBudget))
+	case "upstage":
+		elements = append(elements,
+			*secret("upstageapikey", "setup.field_api_key", config.UpstageApiKey),
+			*money("upstagecreditbalance", "setup.field_credit_balance", config.UpstageCreditBalance),
+			*money("upstagelowbalance", "setup.field_low_balance", config.UpstageLowBalance),
+		)
+	case "aigateway":
+		// Limits are left to System Console; the dialog covers the scrape
+		token := secret("aigatewaytoken", "setup.field_token", config.AigatewayToken)
+		token.Optional = true
+		elements = append(elements,
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_gateway_name"),
+				Name:        "aigatewayname",
+				Type:        "text",
+				Default:     config.AigatewayName,
+				Placeholder: defaultAIGatewayName,
+				Optional:    true,
+			},
+			model.DialogElement{
+				DisplayName: translate(locale, "setup.field_metrics_url"),
+				Name:        "aigatewaymetricsurl",
+				Type:        "text",
+				Default:     config.AigatewayMetricsUrl,
+				Placeholder: "http://kong-admin.internal:8001/metrics",
+			},
+			*token,
+		)
+	case "alerts":
+		title = translate(locale, "setup.alerts_title")
+		threshold := number("warningthreshold", "setup.field_threshold", config.WarningThreshold)
+		threshold.HelpText = translate(locale, "setup.help_threshold")
+		elements = append(elements, *threshold, model.DialogElement{
+			DisplayName: translate(locale, "setup.field_alert_channel"),
+			Name:        "alertchannelid",
+			Type:        "select",
+			DataSource:  "channels",
+			Default:     config.AlertChannelId,
+			Optional:    true,
+		})
+	}
+
+	return p.API.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       pluginURL + "/api/v1/admin/setup/submit",
+		Dialog: model.Dialog{
+			CallbackId:  step.ID,
+			Title:       title,
+			Elements:    elements,
+			SubmitLabel: translate(locale, "setup.submit"),
+		},
+	})
+}
+
+// handleSetupSubmit validates a dialog submission, checks the credentials against
+// the live API and saves the configuration only if everything passes.
+func (p *Plugin) handleSetupSubmit(w http.ResponseWriter, r *http.Request, userID string) {
+	var req model.SubmitDialogRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Cancelled {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	step, ok := findSetupStep(req.CallbackId)
+	if !ok {
+		http.Error(w, `{"error": "unknown_step", "message": "Unknown setup step"}`, http.StatusBadRequest)
+		return
+	}
+	locale := p.getUserContext(userID).Locale
+
+	config, errs := applySetupSubmission(p.getConfiguration(), step, req.Submission, locale)
+	if len(errs) == 0 && step.Secret != "" {
+		probes, _ := connectionProbes(step.ID, config)
+		if result := p.runConnectionTest(step.ID, probes, locale); !result.Success {
+			errs = map[string]string{step.Secret: result.Message}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(errs) > 0 {
+		json.NewEncoder(w).Encode(model.SubmitDialogResponse{Errors: errs})
+		return
+	}
+
+	if err := p.saveConfiguration(config); err != nil {
+		json.NewEncoder(w).Encode(model.SubmitDialogResponse{Error: err.Error()})
+		return
+	}
+
+	message := translate(locale, "setup.alerts_saved")
+	if step.Name != "" {
+		message = translate(locale, "setup.saved", step.Name)
+	}
+	p.API.SendEphemeralPost(userID, &model.Post{ChannelId: req.ChannelId, UserId: p.botUserID, Message: message})
+	w.Write([]byte("{}"))
+}
+
+// applySetupSubmission returns a copy of config with the submitted values applied,
+// or field errors keyed by element name.
+func applySetupSubmission(current *Configuration, step setupStep, submission map[string]any, locale string) (*Configuration, map[string]string) {
+	values := map[string]any{}
+	raw, _ := json.Marshal(current)
+	json.Unmarshal(raw, &values)
+
+	errs := map[string]string{}
+	for name, v := range submission {
+		value := strings.TrimSpace(fmtSubmission(v))
+		switch name {
+		case "openaimonthlybudget", "anthropicmonthlybudget", "deepseeklowbalance", "perplexitycreditbalance", "togethercreditbalance", "bedrockmonthlybudget", "vertexmonthlybudget", "xailowbalance", "heliconemonthlybudget", "assemblyaihourlyrate", "assemblyaicreditbalance", "moonshotlowbalance", "dashscopemonthlybudget", "fallowbalance", "runpodlowbalance", "yandexlowbalance", "rekacreditbalance", "rekalowbalance", "watsonxmonthlybudget", "databricksmonthlybudget", "vercellowbalance", "claudecodedeveloperbudget", "upstagecreditbalance", "upstagelowbalance", "openrouterlowbalance":
+			if _, _, err := parseMoney(value); value != "" && err != nil {
+				errs[name] = translate(locale, "setup.invalid_money")
+				continue
+			}
+		case "openaicreditbalance", "tabnineseatspurchased", "tabnineseatsassigned", "supermavenseatstotal", "supermavenseatsassigned", "gigachatlowtokens", "watsonxunitlimit", "nvidiacreditsleft", "nvidiacreditstotal":
+			if f, err := strconv.ParseFloat(value, 64); value != "" && (err != nil || f < 0) {
+				errs[name] = translate(locale, "setup.invalid_number")
+				continue
+			}
+		case "tabninerenewaldate", "supermavenrenewaldate", "ai21trialends":
+			if _, err := time.Parse("2006-01-02", value); value != "" && err != nil {
+				errs[name] = translate(locale, "setup.invalid_date")
+				continue
+			}
+		case "warningthreshold":
+			if f, err := strconv.ParseFloat(value, 64); value != "" && (err != nil || f < 1 || f > 100) {
+				errs[name] = translate(locale, "setup.invalid_threshold")
+				continue
+			}
+		case "augmentaccesstoken", "zaiapikey", "openaiapikey", "claudeaccesstoken", "clauderefreshtoken", "anthropicadminkey", "copilottoken", "cursorapikey", "mistralapikey", "groqapikey", "deepseekapikey", "perplexityapikey", "togetherapikey", "bedrocksecretaccesskey", "vertexserviceaccountkey", "xaiapikey", "xaimanagementkey", "windsurfservicekey", "jetbrainsapikey", "cerebrasapikey", "sambanovaapikey", "selfhostedapikey", "litellmmasterkey", "heliconeapikey", "gatewayapikey", "elevenlabsapikey", "assemblyaiapikey", "amazonqsecretaccesskey", "moonshotapikey", "dashscopeapikey", "dashscopeaccesssecret", "ai21apikey", "falapikey", "runpodapikey", "yandexserviceaccountkey", "gigachatauthkey", "rekaapikey", "watsonxapikey", "databrickstoken", "nvidiaapikey", "vercelapikey", "portkeyapikey", "githubmodelstoken", "codexaccesstoken", "codexrefreshtoken", "upstageapikey", "chatgptsessiontoken", "aigatewaytoken", "geminiapikey", "geminiserviceaccountkey", "openrouterapikey", "azureopenaiclientsecret":
+			// Empty secret fields keep the current value
+			if value == "" {
+				continue
+			}
+		}
+		values[name] = value
+	}
+	if step.EnabledKey != "" {
+		values[step.EnabledKey] = true
+	}
+
+	var config Configuration
+	raw, _ = json.Marshal(values)
+	json.Unmarshal(raw, &config)
+	return &config, errs
+}
+
+func fmtSubmission(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"time"
+)
+
+// ===== Last known good =====
+
+// lastGoodMaxAge is how long a card's last successful status stands in for failed
+// fetches. After that the error is shown, since the numbers may be far off by then.
+const lastGoodMaxAge = 6 * time.Hour
+
+// rememberGood keeps the successful statuses as their cards' last known good ones.
+func (p *Plugin) rememberGood(statuses []ServiceStatus) {
+	p.lastGoodLock.Lock()
+	defer p.lastGoodLock.Unlock()
+	for _, s := range statuses {
+		if !s.Enabled || s.Error != "" || s.Stale {
+			continue
+		}
+		if p.lastGood == nil {
+			p.lastGood = map[string]ServiceStatus{}
+		}
+		p.lastGood[s.ID] = s
+	}
+}
+
+// withLastGood replaces failed statuses with their card's last successful one, marked
+// stale and carrying the fetch error, so an upstream hiccup doesn't turn the card red.
+// Credentials that need renewing are shown as they are, since waiting won't fix them.
+func (p *Plugin) withLastGood(statuses []ServiceStatus, now time.Time) []ServiceStatus {
+	p.lastGoodLock.Lock()
+	defer p.lastGoodLock.Unlock()
+	result := make([]ServiceStatus, len(statuses))
+	for i, s := range statuses {
+		result[i] = s
+		good, ok := p.lastGood[s.ID]
+		if s.Error == "" || s.Status == "reauth" || !ok || now.Sub(time.Unix(good.CachedAt, 0)) > lastGoodMaxAge {
+			continue
+		}
+		good.Stale = true
+		good.StaleError, good.staleErrorID, good.staleErrorArgs = s.Error, s.errorID, s.errorArgs
+		good.RequestID = s.RequestID
+		result[i] = good
+	}
+	return result
+}
+
+// forgetGood drops the last known good statuses of the providers whose settings changed,
+// or of all of them when ids is nil, so a card never falls back to another account's data.
+func (p *Plugin) forgetGood(ids map[string]bool) {
+	p.lastGoodLock.Lock()
+	defer p.lastGoodLock.Unlock()
+	for key := range p.lastGood {
+		if ids == nil || ids[cacheKeyProvider(key)] {
+			delete(p.lastGood, key)
+		}
+	}
+}
+
+// setStaleAges sets how old each stale status' data is as of now.
+func setStaleAges(services []ServiceStatus, now time.Time) {
+	for i, s := range services {
+		if s.Stale && s.CachedAt > 0 {
+			services[i].AgeSeconds = int64(now.Sub(time.Unix(s.CachedAt, 0)).Seconds())
+		}
+	}
+}
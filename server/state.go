@@ -0,0 +1,73 @@
+package main
+
+import "time"
+
+// providerState is what the plugin remembers about a provider between status collections.
+type providerState struct {
+	Status     string
+	Since      time.Time
+	Last       ServiceStatus
+	Percent    float64
+	HasPercent bool
+
+	// Set once a Jira issue has been opened for the current incident
+	JiraFiled bool
+}
+
+// statusSeverity orders statuses so threshold crossings can be told apart from recoveries.
+func statusSeverity(status string) int {
+	switch status {
+	case "ok":
+		return 1
+	case "warning":
+		return 2
+	case "error", "reauth", "unreachable":
+		return 3
+	}
+	return 0
+}
+
+// trackStatusChanges remembers the last seen status per provider and dispatches
+// events for transitions. The first observation after activation only records
+// the status, so restarts don't re-fire.
+func (p *Plugin) trackStatusChanges(services []ServiceStatus) {
+	var events []StatusEvent
+	locale := p.serverLocale()
+
+	p.stateLock.Lock()
+	now := time.Now()
+	for _, s := range services {
+		pct, hasPct := usagePercent(s)
+
+		state, seen := p.states[s.ID]
+		if !seen {
+			p.states[s.ID] = &providerState{Status: s.Status, Since: now, Last: s, Percent: pct, HasPercent: hasPct}
+			continue
+		}
+
+		// Usage dropped sharply: the provider's window or billing cycle rolled over
+		if hasPct && state.HasPercent && state.Percent-pct >= 5 && pct < state.Percent/2 {
+			events = append(events, newStatusEvent(EventReset, s, state.Status, pct, now, locale))
+		}
+
+		state.Last = s
+		state.Percent, state.HasPercent = pct, hasPct
+		if state.Status == s.Status {
+			continue
+		}
+
+		prev := state.Status
+		state.Status = s.Status
+		state.Since = now
+		state.JiraFiled = false
+
+		events = append(events, newStatusEvent(EventStatusChange, s, prev, pct, now, locale))
+		if statusSeverity(s.Status) > statusSeverity(prev) && statusSeverity(s.Status) >= statusSeverity("warning") {
+			events = append(events, newStatusEvent(EventThreshold, s, prev, pct, now, locale))
+		}
+	}
+	p.stateLock.Unlock()
+
+	p.recordTransitions(events)
+	p.dispatchEvents(events)
+}
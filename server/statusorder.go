@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// ===== Status ordering and filtering =====
+
+// sortBySeverity orders services with the most constrained first: errors, then warnings,
+// then healthy services, and disabled ones last. Within a status, the service that
+// consumed more of its limit comes first; otherwise the configured order is kept.
+func sortBySeverity(services []ServiceStatus) {
+	type rank struct {
+		severity   int
+		percent    float64
+		hasPercent bool
+	}
+	ranks := make(map[string]rank, len(services))
+	for _, s := range services {
+		pct, ok := usagePercent(s)
+		ranks[s.ID] = rank{statusSeverity(s.Status), pct, ok}
+	}
+	sort.SliceStable(services, func(i, j int) bool {
+		a, b := ranks[services[i].ID], ranks[services[j].ID]
+		if a.severity != b.severity {
+			return a.severity > b.severity
+		}
+		if a.hasPercent != b.hasPercent {
+			return a.hasPercent
+		}
+		return a.percent > b.percent
+	})
+}
+
+// filterMinStatus keeps the services at least as severe as minStatus, e.g. only warnings
+// and errors for "warning".
+func filterMinStatus(services []ServiceStatus, minStatus string) []ServiceStatus {
+	threshold := statusSeverity(minStatus)
+	kept := []ServiceStatus{}
+	for _, s := range services {
+		if statusSeverity(s.Status) >= threshold {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// statusView is how a list of statuses is ordered and filtered.
+type statusView struct {
+	Sort      string // "severity", or "" for the configured order
+	MinStatus string // "ok", "warning" or "error", or "" for every service
+}
+
+// parseStatusView reads the sort and min_status query parameters.
+func parseStatusView(query url.Values) (statusView, error) {
+	view := statusView{Sort: query.Get("sort"), MinStatus: query.Get("min_status")}
+	if view.Sort != "" && view.Sort != "severity" {
+		return view, fmt.Errorf("sort must be severity")
+	}
+	if view.MinStatus != "" && statusSeverity(view.MinStatus) == 0 {
+		return view, fmt.Errorf("min_status must be ok, warning or error")
+	}
+	return view, nil
+}
+
+// apply orders and filters the services.
+func (v statusView) apply(services []ServiceStatus) []ServiceStatus {
+	if v.Sort == "severity" {
+		sortBySeverity(services)
+	}
+	if v.MinStatus != "" {
+		services = filterMinStatus(services, v.MinStatus)
+	}
+	return services
+}
+
+// digestView is the view of the digest's Digest Contents setting.
+func (c *Configuration) digestView() statusView {
+	switch c.DigestContents {
+	case "severity":
+		return statusView{Sort: "severity"}
+	case "issues":
+		return statusView{Sort: "severity", MinStatus: "warning"}
+	}
+	return statusView{}
+}
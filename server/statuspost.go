@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Live status post =====
+
+const statusPostKey = "status_post"
+
+// statusPostRef remembers which post the plugin maintains.
+type statusPostRef struct {
+	ChannelID string `json:"channelId"`
+	PostID    string `json:"postId"`
+	Table     string `json:"table"` // last rendered table, to skip edits when nothing changed
+}
+
+// updateStatusPost keeps a single bot post in the configured channel showing the
+// full dashboard. The post is created once and edited in place afterwards.
+func (p *Plugin) updateStatusPost(services []ServiceStatus) {
+	config := p.getConfiguration()
+	if config.StatusPostChannelId == "" || p.botUserID == "" {
+		return
+	}
+
+	now := time.Now()
+	locale := p.serverLocale()
+	table := renderStatusTable(services, now, locale)
+
+	var ref statusPostRef
+	if data, appErr := p.API.KVGet(statusPostKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &ref)
+	}
+
+	message := table + "\n" + translate(locale, "statuspost.updated", now.UTC().Format(time.RFC1123))
+
+	var post *model.Post
+	if ref.PostID != "" && ref.ChannelID == config.StatusPostChannelId {
+		if existing, appErr := p.API.GetPost(ref.PostID); appErr == nil && existing.DeleteAt == 0 {
+			post = existing
+		}
+	}
+
+	switch {
+	case post == nil:
+		created, appErr := p.API.CreatePost(&model.Post{
+			ChannelId: config.StatusPostChannelId,
+			UserId:    p.botUserID,
+			Message:   message,
+			IsPinned:  config.StatusPostPin,
+		})
+		if appErr != nil {
+			p.API.LogWarn("Failed to create status post", "channel_id", config.StatusPostChannelId, "error", appErr.Error())
+			return
+		}
+		post = created
+	case ref.Table == table && post.IsPinned == config.StatusPostPin:
+		return
+	default:
+		post.Message = message
+		post.IsPinned = config.StatusPostPin
+		if _, appErr := p.API.UpdatePost(post); appErr != nil {
+			p.API.LogWarn("Failed to update status post", "post_id", post.Id, "error", appErr.Error())
+			return
+		}
+	}
+
+	ref = statusPostRef{ChannelID: post.ChannelId, PostID: post.Id, Table: table}
+	data, _ := json.Marshal(ref)
+	if appErr := p.API.KVSet(statusPostKey, data); appErr != nil {
+		p.API.LogWarn("Failed to store status post reference", "error", appErr.Error())
+	}
+}
+
+// renderStatusTable renders enabled services as a Markdown table.
+func renderStatusTable(services []ServiceStatus, now time.Time, locale string) string {
+	var b strings.Builder
+	b.WriteString(translate(locale, "statuspost.title") + "\n\n")
+	b.WriteString(fmt.Sprintf("| | %s | %s | %s |\n", translate(locale, "statuspost.col_provider"),
+		translate(locale, "statuspost.col_usage"), translate(locale, "statuspost.col_reset")))
+	b.WriteString("|:-:|:--|:--|:--|\n")
+
+	localizeStatuses(services, locale)
+	count := 0
+	for _, s := range services {
+		if !s.Enabled {
+			continue
+		}
+		count++
+		reset := "—"
+		if resets := serviceResets(s, now); len(resets) > 0 {
+			reset = fmt.Sprintf("%s (%s)", formatDuration(resets[0].At.Sub(now), locale), resets[0].label(locale))
+		}
+		b.WriteString(fmt.Sprintf("| %s | **%s** | %s | %s |\n", statusEmoji(s.Status),
+			tableEscape(s.Name), tableEscape(summarizeService(s, locale)), reset))
+	}
+	if count == 0 {
+		return translate(locale, "command.no_services")
+	}
+	return b.String()
+}
+
+// tableEscape keeps text from breaking out of a Markdown table cell.
+func tableEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ===== Service summaries =====
+
+// summarizeService returns a one-line, human-readable summary of a service status.
+func summarizeService(s ServiceStatus, locale string) string {
+	if !s.Enabled {
+		return translate(locale, "summary.not_configured")
+	}
+	if s.Error != "" {
+		if s.errorID != "" {
+			return translate(locale, "summary.error", translate(locale, s.errorID, s.errorArgs...))
+		}
+		return translate(locale, "summary.error", s.Error)
+	}
+	if s.Stale {
+		fresh := s
+		fresh.Stale = false
+		age := formatDuration(time.Since(time.Unix(s.CachedAt, 0)), locale)
+		return summarizeService(fresh, locale) + " " + translate(locale, "summary.stale", age)
+	}
+
+	if d, ok := s.Data.(summarizer); ok {
+		if text := d.summary(locale); text != "" {
+			return text
+		}
+	}
+	return s.Status
+}
+
+// Each provider's data renders itself for the cards and one-line surfaces.
+// Only summary is required; without the others the service has no usage
+// percent or resets, and its compact label falls back to the name and percent.
+type (
+	summarizer interface{ summary(locale string) string }
+	compacter  interface{ compact(name, locale string) string }
+	usageMeter interface{ usagePercent() (float64, bool) }
+	resetter   interface{ resets(now time.Time) []ResetInfo }
+)
+
+// formatCount abbreviates large numbers the same way the webapp does (1.2K, 3.4M).
+func formatCount(n float64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.1fB", n/1_000_000_000)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", n/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fK", n/1_000)
+	}
+	return fmt.Sprintf("%.0f", n)
+}
+
+func statusColor(status string) string {
+	switch status {
+	case "ok":
+		return "#3db887"
+	case "warning":
+		return "#f5a623"
+	case "error", "reauth", "unreachable":
+		return "#d24b4e"
+	}
+	return "#8b8fa7"
+}
+
+// usagePercent returns how much of the provider's limit is consumed (0-100),
+// or false when the provider doesn't report a comparable limit.
+func usagePercent(s ServiceStatus) (float64, bool) {
+	if d, ok := s.Data.(usageMeter); ok {
+		return d.usagePercent()
+	}
+	return 0, false
+}
+
+// ResetInfo is an upcoming point in time when a provider's usage window or billing cycle resets.
+// Kind is a message ID suffix ("reset.<kind>") for the localized label.
+type ResetInfo struct {
+	Kind string    `json:"kind"`
+	At   time.Time `json:"at"`
+}
+
+// label returns the localized name of the reset, e.g. "7-day window".
+func (r ResetInfo) label(locale string) string {
+	return translate(locale, "reset."+r.Kind)
+}
+
+// serviceResets returns the known upcoming resets for a service, in chronological order.
+func serviceResets(s ServiceStatus, now time.Time) []ResetInfo {
+	var resets []ResetInfo
+	if d, ok := s.Data.(resetter); ok {
+		for _, r := range d.resets(now) {
+			if !r.At.IsZero() && r.At.After(now) {
+				resets = append(resets, r)
+			}
+		}
+	}
+
+	sort.Slice(resets, func(i, j int) bool { return resets[i].At.Before(resets[j].At) })
+	return resets
+}
+
+// parseTime parses an RFC 3339 timestamp, returning the zero time if it's empty or invalid.
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// compactSummary returns a very short label for one-line status surfaces, e.g. "claude.ai 82% 🔶".
+func compactSummary(s ServiceStatus, locale string) string {
+	text := s.Name
+	if d, ok := s.Data.(compacter); ok {
+		text = d.compact(s.Name, locale)
+	} else if pct, ok := usagePercent(s); ok {
+		text = fmt.Sprintf("%s %.0f%%", s.Name, pct)
+	}
+
+	switch s.Status {
+	case "warning":
+		text += " 🔶"
+	case "error":
+		text += " 🔴"
+	case "reauth":
+		text += " 🔑"
+	case "unreachable":
+		text += " ⛔"
+	}
+	return text
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Supermaven (configured seats) =====
+
+// SupermavenInfo is a Supermaven team plan's seats, subscription status and monthly renewal.
+// Supermaven has no public API for team administration or usage, so the figures come from
+// the team dashboard via the configuration.
+type SupermavenInfo struct {
+	Plan             string  `json:"plan"`
+	PlanStatus       string  `json:"planStatus,omitempty"` // "active", "trialing", "past_due" or "canceled"
+	SeatsPurchased   float64 `json:"seatsPurchased"`
+	SeatsAssigned    float64 `json:"seatsAssigned"`
+	RenewalDate      string  `json:"renewalDate,omitempty"`
+	DaysUntilRenewal int     `json:"daysUntilRenewal"`
+}
+
+func (s SupermavenInfo) summary(locale string) string {
+	text := translate(locale, "summary.seats", formatCount(s.SeatsAssigned), formatCount(s.SeatsPurchased))
+	if s.Plan != "" {
+		text = translate(locale, "summary.plan", s.Plan) + ", " + text
+	}
+	switch s.PlanStatus {
+	case "trialing", "past_due", "canceled":
+		text += ", " + translate(locale, "summary.plan_"+s.PlanStatus)
+	}
+	return text
+}
+
+func (s SupermavenInfo) compact(name, locale string) string {
+	return fmt.Sprintf("%s %s/%s", name, formatCount(s.SeatsAssigned), formatCount(s.SeatsPurchased))
+}
+
+func (s SupermavenInfo) usagePercent() (float64, bool) {
+	if s.SeatsPurchased > 0 {
+		return s.SeatsAssigned / s.SeatsPurchased * 100, true
+	}
+	return 0, false
+}
+
+func (s SupermavenInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "plan_renewal", At: parseTime(s.RenewalDate)}}
+}
+
+func (p *Plugin) getSupermavenStatus(config *Configuration) ServiceStatus {
+	const id, name = "supermaven", "Supermaven"
+	purchased, err := strconv.ParseFloat(strings.TrimSpace(config.SupermavenSeatsTotal), 64)
+	if err != nil || purchased <= 0 {
+		return errorStatus(id, name, "error.supermaven_seats_missing")
+	}
+	assigned, _ := strconv.ParseFloat(strings.TrimSpace(config.SupermavenSeatsAssigned), 64)
+
+	info := SupermavenInfo{
+		Plan: config.SupermavenPlan, PlanStatus: config.SupermavenPlanStatus,
+		SeatsPurchased: purchased, SeatsAssigned: assigned,
+	}
+	now := time.Now().UTC()
+	if renewal, err := time.Parse("2006-01-02", strings.TrimSpace(config.SupermavenRenewalDate)); err == nil {
+		// Team plans are billed monthly on the same day
+		for renewal.Before(now) {
+			renewal = renewal.AddDate(0, 1, 0)
+		}
+		info.RenewalDate = renewal.Format(time.RFC3339)
+		info.DaysUntilRenewal = int(renewal.Sub(now).Hours() / 24)
+	}
+
+	return ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: supermavenStatus(info, config),
+		Data: info, CachedAt: now.Unix(),
+	}
+}
+
+// supermavenStatus is an error once the subscription is canceled, and warns on a failed
+// payment or when nearly every seat is assigned.
+func supermavenStatus(info SupermavenInfo, config *Configuration) string {
+	switch {
+	case info.PlanStatus == "canceled" || info.SeatsAssigned > info.SeatsPurchased:
+		return "error"
+	case info.PlanStatus == "past_due" || info.SeatsAssigned/info.SeatsPurchased*100 > config.warningPercent(90):
+		return "warning"
+	}
+	return "ok"
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Tabnine (configured seats) =====
+
+// TabnineInfo is a Tabnine team's seat usage and plan renewal. Tabnine has no public team
+// administration API, so the figures come from the admin console via the configuration.
+type TabnineInfo struct {
+	Plan             string  `json:"plan"`
+	SeatsPurchased   float64 `json:"seatsPurchased"`
+	SeatsAssigned    float64 `json:"seatsAssigned"`
+	RenewalDate      string  `json:"renewalDate,omitempty"`
+	DaysUntilRenewal int     `json:"daysUntilRenewal"`
+}
+
+func (t TabnineInfo) summary(locale string) string {
+	text := translate(locale, "summary.tabnine", formatCount(t.SeatsAssigned), formatCount(t.SeatsPurchased))
+	if t.Plan != "" {
+		text = translate(locale, "summary.tabnine_plan", t.Plan) + ", " + text
+	}
+	return text
+}
+
+func (t TabnineInfo) compact(name, locale string) string {
+	return fmt.Sprintf("%s %s/%s", name, formatCount(t.SeatsAssigned), formatCount(t.SeatsPurchased))
+}
+
+func (t TabnineInfo) usagePercent() (float64, bool) {
+	if t.SeatsPurchased > 0 {
+		return t.SeatsAssigned / t.SeatsPurchased * 100, true
+	}
+	return 0, false
+}
+
+func (t TabnineInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "plan_renewal", At: parseTime(t.RenewalDate)}}
+}
+
+func (p *Plugin) getTabnineStatus(config *Configuration) ServiceStatus {
+	const id, name = "tabnine", "Tabnine"
+	purchased, err := strconv.ParseFloat(strings.TrimSpace(config.TabnineSeatsPurchased), 64)
+	if err != nil || purchased <= 0 {
+		return errorStatus(id, name, "error.tabnine_seats_missing")
+	}
+	assigned, _ := strconv.ParseFloat(strings.TrimSpace(config.TabnineSeatsAssigned), 64)
+
+	info := TabnineInfo{Plan: config.TabninePlan, SeatsPurchased: purchased, SeatsAssigned: assigned}
+	now := time.Now().UTC()
+	if renewal, err := time.Parse("2006-01-02", strings.TrimSpace(config.TabnineRenewalDate)); err == nil {
+		// Recurring plans renew on the same day every year
+		for renewal.Before(now) {
+			renewal = renewal.AddDate(1, 0, 0)
+		}
+		info.RenewalDate = renewal.Format(time.RFC3339)
+		info.DaysUntilRenewal = int(renewal.Sub(now).Hours() / 24)
+	}
+
+	return ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: tabnineStatus(info, config),
+		Data: info, CachedAt: now.Unix(),
+	}
+}
+
+// tabnineStatus warns when nearly every seat is assigned, so new users can't be added.
+func tabnineStatus(info TabnineInfo, config *Configuration) string {
+	switch {
+	case info.SeatsAssigned > info.SeatsPurchased:
+		return "error"
+	case info.SeatsAssigned/info.SeatsPurchased*100 > config.warningPercent(90):
+		return "warning"
+	}
+	return "ok"
+}
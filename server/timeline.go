@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Status change timeline =====
+
+// maxTimelineDays bounds how far back /api/v1/timeline reaches.
+const maxTimelineDays = 90
+
+// Transition is a recorded change of a provider's status, with the figure that caused it.
+type Transition struct {
+	Provider  string   `json:"provider"`
+	Name      string   `json:"name"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Timestamp int64    `json:"timestamp"`
+	Percent   *float64 `json:"percent,omitempty"` // usage when the status changed
+	Summary   string   `json:"summary,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func timelineMonthKey(month string) string {
+	return "timeline_" + month
+}
+
+func (p *Plugin) loadTimelineMonth(month string) []Transition {
+	var transitions []Transition
+	if data, appErr := p.API.KVGet(timelineMonthKey(month)); appErr == nil && data != nil {
+		json.Unmarshal(data, &transitions)
+	}
+	return transitions
+}
+
+// recordTransitions stores the status changes among events in the timeline.
+func (p *Plugin) recordTransitions(events []StatusEvent) {
+	byMonth := map[string][]Transition{}
+	for _, ev := range events {
+		if ev.Type != EventStatusChange {
+			continue
+		}
+		t := Transition{
+			Provider: ev.Provider, Name: ev.Name, From: ev.From, To: ev.To,
+			Timestamp: ev.Timestamp, Summary: ev.Summary, Error: ev.Service.Error,
+		}
+		if _, ok := usagePercent(ev.Service); ok {
+			pct := ev.Percent
+			t.Percent = &pct
+		}
+		month := time.Unix(ev.Timestamp, 0).UTC().Format("2006-01")
+		byMonth[month] = append(byMonth[month], t)
+	}
+	if len(byMonth) == 0 {
+		return
+	}
+
+	p.historyLock.Lock()
+	defer p.historyLock.Unlock()
+	for month, transitions := range byMonth {
+		data, _ := json.Marshal(append(p.loadTimelineMonth(month), transitions...))
+		if appErr := p.API.KVSet(timelineMonthKey(month), data); appErr != nil {
+			p.API.LogWarn("Failed to store status timeline", "month", month, "error", appErr.Error())
+		}
+	}
+}
+
+// handleTimeline serves GET /api/v1/timeline: the recorded status changes, newest first.
+// since and until are Unix times (the last 7 days by default), and provider filters by
+// provider or card ID.
+func (p *Plugin) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	until := time.Now()
+	since := until.AddDate(0, 0, -7)
+	for name, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf(`{"error": "invalid_%s", "message": "%s must be a Unix time"}`, name, name), http.StatusBadRequest)
+				return
+			}
+			*t = time.Unix(n, 0)
+		}
+	}
+	if until.Before(since) || until.Sub(since) > maxTimelineDays*24*time.Hour {
+		http.Error(w, fmt.Sprintf(`{"error": "invalid_range", "message": "the range must be at most %d days"}`, maxTimelineDays), http.StatusBadRequest)
+		return
+	}
+	provider := query.Get("provider")
+
+	transitions := []Transition{}
+	p.historyLock.Lock()
+	for month := since.UTC().Format("2006-01"); month <= until.UTC().Format("2006-01"); {
+		for _, t := range p.loadTimelineMonth(month) {
+			if t.Timestamp < since.Unix() || t.Timestamp > until.Unix() {
+				continue
+			}
+			if provider != "" && t.Provider != provider && !strings.HasPrefix(t.Provider, provider+":") {
+				continue
+			}
+			transitions = append(transitions, t)
+		}
+		next, _ := time.Parse("2006-01", month)
+		month = next.AddDate(0, 1, 0).Format("2006-01")
+	}
+	p.historyLock.Unlock()
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].Timestamp > transitions[j].Timestamp })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since":       since.Unix(),
+		"until":       until.Unix(),
+		"transitions": transitions,
+	})
+}
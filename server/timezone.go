@@ -0,0 +1,56 @@
+package main
+
+import (
+	"time"
+
+	// Embed the timezone database so user timezones resolve on any host
+	_ "time/tzdata"
+)
+
+// ===== Per-user reset times =====
+
+// ResetView is a reset time rendered for a specific user.
+type ResetView struct {
+	Kind      string `json:"kind"`
+	Label     string `json:"label"`
+	At        string `json:"at"`        // RFC 3339 in the user's timezone
+	Formatted string `json:"formatted"` // e.g. "Oct 16, 15:04 MSK"
+	Relative  string `json:"relative"`  // e.g. "3h 12m"
+}
+
+// addResetViews fills in each service's upcoming resets in the user's timezone and language.
+func addResetViews(services []ServiceStatus, uc userContext, now time.Time) {
+	for i, s := range services {
+		services[i].Resets = nil
+		for _, r := range serviceResets(s, now) {
+			services[i].Resets = append(services[i].Resets, newResetView(r, uc, now))
+		}
+	}
+}
+
+func newResetView(r ResetInfo, uc userContext, now time.Time) ResetView {
+	local := r.At.In(uc.Location)
+	return ResetView{
+		Kind:      r.Kind,
+		Label:     r.label(uc.Locale),
+		At:        local.Format(time.RFC3339),
+		Formatted: local.Format("Jan 2, 15:04 MST"),
+		Relative:  formatDuration(r.At.Sub(now), uc.Locale),
+	}
+}
+
+// formatDuration renders a duration the same way the webapp does ("2d 3h", "3h 12m", "45m").
+func formatDuration(d time.Duration, locale string) string {
+	if d <= 0 {
+		return translate(locale, "duration.now")
+	}
+	hours := int(d.Hours())
+	mins := int(d.Minutes()) % 60
+	switch {
+	case hours > 24:
+		return translate(locale, "duration.days", hours/24, hours%24)
+	case hours > 0:
+		return translate(locale, "duration.hours", hours, mins)
+	}
+	return translate(locale, "duration.minutes", mins)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== Together AI (API key) =====
+
+// TogetherInfo is the account's credit balance. Together AI doesn't expose billing through
+// its API, so the balance comes from the configuration; the key is verified on every refresh.
+type TogetherInfo struct {
+	CreditBalance float64 `json:"creditBalance"`
+	HasBalance    bool    `json:"hasBalance"`
+	LowBalance    float64 `json:"lowBalance,omitempty"`
+	Currency      string  `json:"currency"`
+	Models        int     `json:"models"` // available to the key, as a sanity check of its access
+}
+
+func (t TogetherInfo) summary(locale string) string {
+	if !t.HasBalance {
+		return translate(locale, "summary.together_models", t.Models)
+	}
+	return translate(locale, "summary.credit_balance", formatMoney(t.CreditBalance, t.Currency, 2))
+}
+
+func (t TogetherInfo) compact(name, locale string) string {
+	if t.HasBalance {
+		return translate(locale, "compact.balance_left", name, formatMoney(t.CreditBalance, t.Currency, 0))
+	}
+	return name
+}
+
+func (p *Plugin) getTogetherStatus(config *Configuration) ServiceStatus {
+	const id, name = "together", "Together AI"
+	if config.TogetherApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newTogetherRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+
+	// /v1/models returns a bare array. Its fields vary by model type, so it isn't schema-checked.
+	var models []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &models); err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	info := TogetherInfo{Currency: baseCurrency, Models: len(models)}
+	if strings.TrimSpace(config.TogetherCreditBalance) != "" {
+		info.CreditBalance = p.budgetIn(config, id, config.TogetherCreditBalance, info.Currency)
+		info.HasBalance = true
+		info.LowBalance = p.budgetIn(config, id, config.TogetherLowBalance, info.Currency)
+	}
+
+	status := "ok"
+	if info.HasBalance && info.LowBalance > 0 && info.CreditBalance < info.LowBalance {
+		status = "warning"
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: status,
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+func newTogetherRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.together.xyz/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.TogetherApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
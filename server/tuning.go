@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Tuning thresholds, TTLs and budgets =====
+
+const (
+	configAuditKey     = "config_audit"
+	maxConfigAuditSize = 100
+)
+
+// tunableKeyPattern matches the provider settings that can be tuned without System Console:
+// budgets, balances and limits, and warning thresholds.
+var tunableKeyPattern = regexp.MustCompile(`(budget|balance|lowtokens|creditsleft|creditstotal|limit|perseat|warn)$`)
+
+// parseProviderSettings parses a setting with one "<provider>=<value>" per line.
+func parseProviderSettings(raw string) map[string]string {
+	return parseProviderNames(raw)
+}
+
+// setProviderSetting sets or, with an empty value, removes a provider's line.
+func setProviderSetting(raw, id, value string) string {
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		key, _, _ := strings.Cut(line, "=")
+		if strings.TrimSpace(line) != "" && strings.TrimSpace(key) != id {
+			lines = append(lines, line)
+		}
+	}
+	if value != "" {
+		lines = append(lines, id+"="+value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseCacheTTL parses a cache TTL of at least a minute.
+func parseCacheTTL(value string) (time.Duration, bool) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	return d, err == nil && d >= time.Minute
+}
+
+// cacheTTLFor returns how long the result cached under key is kept: the provider's TTL,
+// else the instance's or the default one.
+func (p *Plugin) cacheTTLFor(key string) time.Duration {
+	if p.cacheTTL == 0 {
+		if ttl, ok := parseCacheTTL(parseProviderSettings(p.getConfiguration().ProviderCacheTtls)[cacheKeyProvider(key)]); ok {
+			return ttl
+		}
+	}
+	return p.getCacheTTL()
+}
+
+// setRefreshTimes sets how long until each card is fetched again: until its cached result
+// expires or, after a failure, until the error backoff ends.
+func (p *Plugin) setRefreshTimes(services []ServiceStatus, now time.Time) {
+	expires := map[string]time.Time{}
+	p.cacheLock.RLock()
+	for key, entry := range p.cache {
+		expires[key] = entry.FetchedAt.Add(entry.TTL)
+	}
+	p.cacheLock.RUnlock()
+	p.backoffLock.Lock()
+	for key, b := range p.backoff {
+		expires[key] = b.retryAt
+	}
+	p.backoffLock.Unlock()
+
+	for i, s := range services {
+		at, ok := expires[s.ID]
+		if !ok {
+			at, ok = expires[cacheKeyProvider(s.ID)]
+		}
+		if ok && at.After(now) {
+			services[i].RefreshInSeconds = int64(at.Sub(now).Seconds())
+		}
+	}
+}
+
+// forProvider returns the configuration a provider is fetched with: its own warning
+// threshold, if one is set, replaces the global one.
+func (c *Configuration) forProvider(id string) *Configuration {
+	threshold, ok := parseProviderSettings(c.ProviderWarningThresholds)[id]
+	if !ok {
+		return c
+	}
+	config := *c
+	config.WarningThreshold = threshold
+	return &config
+}
+
+// tunable is a setting that can be changed with /ailimits config or PATCH /api/v1/config.
+type tunable struct {
+	Name     string // as given, e.g. "claude.warn"
+	Key      string // configuration key
+	Provider string // for per-provider lines in Key
+	Kind     string // percent, duration or amount
+}
+
+// findTunable maps a tunable name to its setting:
+//
+//	warn, ttl                   the global warning threshold and cache TTL
+//	overall.error, overall.warn the overall status thresholds
+//	<provider>.warn, .ttl       a provider's own warning threshold and cache TTL
+//	<provider>.budget           a provider's monthly budget
+//	<provider>.<field>          a provider's budget, balance or limit field, e.g. deepseek.lowbalance
+func findTunable(name string) (tunable, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "warn":
+		return tunable{Name: name, Key: "warningthreshold", Kind: "percent"}, nil
+	case "ttl":
+		return tunable{Name: name, Key: "cachettl", Kind: "duration"}, nil
+	case "overall.error":
+		return tunable{Name: name, Key: "overallerrorpercent", Kind: "percent"}, nil
+	case "overall.warn":
+		return tunable{Name: name, Key: "overallwarningpercent", Kind: "percent"}, nil
+	}
+
+	id, field, ok := strings.Cut(name, ".")
+	if _, known := findProvider(id); !ok || !known {
+		return tunable{}, fmt.Errorf("unknown setting %q", name)
+	}
+	switch field {
+	case "warn":
+		return tunable{Name: name, Key: "providerwarningthresholds", Provider: id, Kind: "percent"}, nil
+	case "ttl":
+		return tunable{Name: name, Key: "providercachettls", Provider: id, Kind: "duration"}, nil
+	case "budget":
+		for _, suffix := range []string{"monthlybudget", "developerbudget"} {
+			if configurationKeys()[id+suffix] {
+				return tunable{Name: name, Key: id + suffix, Kind: "amount"}, nil
+			}
+		}
+	}
+	key := id + strings.ReplaceAll(field, ".", "")
+	if configurationKeys()[key] && tunableKeyPattern.MatchString(key) && providerForKey(key) == id {
+		kind := "amount"
+		if strings.HasSuffix(key, "warn") {
+			kind = "percent"
+		}
+		return tunable{Name: name, Key: key, Kind: kind}, nil
+	}
+	return tunable{}, fmt.Errorf("unknown setting %q", name)
+}
+
+// validate normalizes a value for the tunable; an empty value unsets it.
+func (t tunable) validate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	switch t.Kind {
+	case "percent":
+		if v, err := strconv.ParseFloat(value, 64); err != nil || v <= 0 || v > 100 {
+			return "", fmt.Errorf("%s must be a percentage above 0 and at most 100", t.Name)
+		}
+	case "duration":
+		if _, ok := parseCacheTTL(value); !ok {
+			return "", fmt.Errorf("%s must be a duration of at least 1m, like 10m", t.Name)
+		}
+	default:
+		if v, err := strconv.ParseFloat(value, 64); err != nil || v < 0 {
+			return "", fmt.Errorf("%s must be a number of at least 0", t.Name)
+		}
+	}
+	return value, nil
+}
+
+// current returns the tunable's value in the configuration settings.
+func (t tunable) current(settings map[string]interface{}) string {
+	raw, _ := settings[t.Key].(string)
+	if t.Provider != "" {
+		return parseProviderSettings(raw)[t.Provider]
+	}
+	return raw
+}
+
+func (t tunable) set(settings map[string]interface{}, value string) {
+	if t.Provider != "" {
+		raw, _ := settings[t.Key].(string)
+		value = setProviderSetting(raw, t.Provider, value)
+	}
+	settings[t.Key] = value
+}
+
+// configChange is a tuning change in the audit log.
+type configChange struct {
+	At      int64  `json:"at"`
+	UserID  string `json:"userId"`
+	Setting string `json:"setting"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+	Via     string `json:"via"` // command or api
+}
+
+// canTune reports whether the user may tune settings: system admins and the users listed
+// in Tuning Managers.
+func (p *Plugin) canTune(userID string) bool {
+	for _, id := range strings.Split(p.getConfiguration().ConfigManagerIds, ",") {
+		if strings.TrimSpace(id) == userID {
+			return true
+		}
+	}
+	return p.isSystemAdmin(userID)
+}
+
+// applyTuning validates and saves changes, by tunable name, and records them in the audit
+// log. Nothing is saved when any change is invalid.
+func (p *Plugin) applyTuning(userID, via string, changes map[string]string) ([]configChange, error) {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	settings := p.getConfiguration().withSecretReferences()
+	var applied []configChange
+	for _, name := range names {
+		t, err := findTunable(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := t.validate(changes[name])
+		if err != nil {
+			return nil, err
+		}
+		old := t.current(settings)
+		if old == value {
+			continue
+		}
+		t.set(settings, value)
+		applied = append(applied, configChange{At: time.Now().Unix(), UserID: userID, Setting: t.Name, Old: old, New: value, Via: via})
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	if appErr := p.API.SavePluginConfig(settings); appErr != nil {
+		return nil, appErr
+	}
+
+	p.historyLock.Lock()
+	defer p.historyLock.Unlock()
+	audit := p.configAudit()
+	for _, c := range applied {
+		p.API.LogInfo("Plugin setting changed", "user_id", c.UserID, "setting", c.Setting, "old", c.Old, "new", c.New, "via", c.Via)
+		audit = append(audit, c)
+	}
+	if len(audit) > maxConfigAuditSize {
+		audit = audit[len(audit)-maxConfigAuditSize:]
+	}
+	data, _ := json.Marshal(audit)
+	if appErr := p.API.KVSet(configAuditKey, data); appErr != nil {
+		p.API.LogWarn("Failed to store the settings audit log", "error", appErr.Error())
+	}
+	return applied, nil
+}
+
+func (p *Plugin) configAudit() []configChange {
+	audit := []configChange{}
+	if data, appErr := p.API.KVGet(configAuditKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &audit)
+	}
+	return audit
+}
+
+// tunedValues returns the tunable settings that are set, by tunable name.
+func (p *Plugin) tunedValues() map[string]string {
+	config := p.getConfiguration()
+	values := map[string]string{}
+	add := func(name, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			values[name] = value
+		}
+	}
+	add("warn", config.WarningThreshold)
+	add("ttl", config.CacheTtl)
+	add("overall.error", config.OverallErrorPercent)
+	add("overall.warn", config.OverallWarningPercent)
+	for id, v := range parseProviderSettings(config.ProviderWarningThresholds) {
+		add(id+".warn", v)
+	}
+	for id, v := range parseProviderSettings(config.ProviderCacheTtls) {
+		add(id+".ttl", v)
+	}
+	for key, value := range configurationMap(config) {
+		id := providerForKey(key)
+		if s, ok := value.(string); ok && id != "" && tunableKeyPattern.MatchString(key) {
+			add(id+"."+strings.TrimPrefix(key, id), s)
+		}
+	}
+	return values
+}
+
+// handleGetConfig serves GET /api/v1/config: the tuned settings and the recent changes.
+func (p *Plugin) handleGetConfig(w http.ResponseWriter, r *http.Request, userID string) {
+	if !p.canTune(userID) {
+		http.Error(w, `{"error": "forbidden", "message": "You may not change the plugin settings"}`, http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"settings": p.tunedValues(),
+		"audit":    p.configAudit(),
+	})
+}
+
+// handlePatchConfig serves PATCH /api/v1/config: a JSON object from tunable name to the
+// new value, or "" to unset it.
+func (p *Plugin) handlePatchConfig(w http.ResponseWriter, r *http.Request, userID string) {
+	if !p.canTune(userID) {
+		http.Error(w, `{"error": "forbidden", "message": "You may not change the plugin settings"}`, http.StatusForbidden)
+		return
+	}
+	var changes map[string]string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&changes); err != nil {
+		http.Error(w, `{"error": "invalid_json", "message": "Request body must be a JSON object of strings"}`, http.StatusBadRequest)
+		return
+	}
+	applied, err := p.applyTuning(userID, "api", changes)
+	if err != nil {
+		msg, _ := json.Marshal(map[string]string{"error": "invalid_setting", "message": err.Error()})
+		http.Error(w, string(msg), http.StatusBadRequest)
+		return
+	}
+	if applied == nil {
+		applied = []configChange{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"changed": applied})
+}
+
+// executeConfigCommand handles `/ailimits config [set <name> <value> | unset <name>]`.
+func (p *Plugin) executeConfigCommand(userID string, uc userContext, fields []string) string {
+	if !p.canTune(userID) {
+		return translate(uc.Locale, "config.not_allowed")
+	}
+	if len(fields) == 0 {
+		values := p.tunedValues()
+		if len(values) == 0 {
+			return translate(uc.Locale, "config.none")
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var b strings.Builder
+		b.WriteString(translate(uc.Locale, "config.header") + "\n")
+		for _, name := range names {
+			b.WriteString(fmt.Sprintf("- `%s` = `%s`\n", name, values[name]))
+		}
+		return b.String()
+	}
+
+	var name, value string
+	switch {
+	case fields[0] == "set" && len(fields) == 3:
+		name, value = fields[1], fields[2]
+	case fields[0] == "unset" && len(fields) == 2:
+		name = fields[1]
+	default:
+		return translate(uc.Locale, "config.usage")
+	}
+	applied, err := p.applyTuning(userID, "command", map[string]string{name: value})
+	switch {
+	case err != nil:
+		return translate(uc.Locale, "config.invalid", err.Error())
+	case len(applied) == 0:
+		return translate(uc.Locale, "config.unchanged", name)
+	case value == "":
+		return translate(uc.Locale, "config.unset", applied[0].Setting)
+	}
+	return translate(uc.Locale, "config.set", applied[0].Setting, applied[0].New)
+}
@@ -0,0 +1,326 @@
+package main
+
+// ===== Display units =====
+
+// Display unit choices. Raw leads with the provider's native unit (credits, tokens),
+// cost with dollars, and percent with the share of the limit consumed.
+const (
+	UnitsRaw     = "raw"
+	UnitsCost    = "cost"
+	UnitsPercent = "percent"
+)
+
+// UsageMetrics is a provider-independent view of a service's usage, with every
+// representation the server can compute. Fields that can't be derived are omitted.
+type UsageMetrics struct {
+	Unit      string   `json:"unit"` // "credits", "tokens", "requests", "seats", "characters", "cost" or "percent"
+	Used      *float64 `json:"used,omitempty"`
+	Limit     *float64 `json:"limit,omitempty"`
+	Cost      *float64 `json:"cost,omitempty"`
+	CostLimit *float64 `json:"costLimit,omitempty"`
+	Currency  string   `json:"currency,omitempty"` // of Cost and CostLimit
+	Percent   *float64 `json:"percent,omitempty"`
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// computeUsage normalizes the provider-specific data, or returns nil when there's nothing to show.
+func computeUsage(s ServiceStatus) *UsageMetrics {
+	var m *UsageMetrics
+	switch d := s.Data.(type) {
+	case AugmentCreditInfo:
+		m = &UsageMetrics{Unit: "credits", Used: floatPtr(d.UsageUsed), Limit: floatPtr(d.UsageTotal)}
+	case ZaiQuotaInfo:
+		m = &UsageMetrics{Unit: "tokens", Used: floatPtr(d.TokensUsed), Limit: floatPtr(d.TokensTotal)}
+	case BudgetStatus:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.Spent), Limit: floatPtr(d.Amount),
+			Cost: floatPtr(d.Spent), CostLimit: floatPtr(d.Amount), Currency: d.Currency}
+	case OpenAIUsageInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.currency()}
+		if d.Budget > 0 {
+			m.Limit = floatPtr(d.Budget)
+			m.CostLimit = floatPtr(d.Budget)
+		}
+	case AnthropicUsageInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		if limit := d.spendLimit(); limit > 0 {
+			m.Limit = floatPtr(limit)
+			m.CostLimit = floatPtr(limit)
+		}
+	case CopilotUsageInfo:
+		if d.HasPremiumData {
+			m = &UsageMetrics{Unit: "requests", Used: floatPtr(d.PremiumUsed), Limit: floatPtr(d.PremiumAllowance),
+				Cost: floatPtr(d.OverageCost), Currency: d.Currency}
+		}
+	case CursorUsageInfo:
+		m = &UsageMetrics{Unit: "requests", Used: floatPtr(d.RequestsUsed), Limit: floatPtr(d.RequestsTotal),
+			Cost: floatPtr(d.SpendCost), Currency: d.Currency}
+	case WindsurfUsageInfo:
+		m = &UsageMetrics{Unit: "credits", Used: floatPtr(d.CreditsUsed), Limit: floatPtr(d.CreditsTotal)}
+	case TabnineInfo:
+		m = &UsageMetrics{Unit: "seats", Used: floatPtr(d.SeatsAssigned), Limit: floatPtr(d.SeatsPurchased)}
+	case SupermavenInfo:
+		m = &UsageMetrics{Unit: "seats", Used: floatPtr(d.SeatsAssigned), Limit: floatPtr(d.SeatsPurchased)}
+	case AmazonQInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		if _, ok := d.agenticPercent(); ok {
+			m = &UsageMetrics{Unit: "requests", Used: floatPtr(d.AgenticRequests), Limit: floatPtr(d.Seats * d.AgenticLimit),
+				Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		}
+	case JetBrainsAIInfo:
+		m = &UsageMetrics{Unit: "seats", Used: floatPtr(d.SeatsAssigned), Limit: floatPtr(d.SeatsTotal)}
+	case MistralUsageInfo:
+		switch {
+		case d.HasLimits:
+			m = &UsageMetrics{Unit: "tokens", Used: floatPtr(d.TokensUsed), Limit: floatPtr(d.TokensLimit)}
+		case d.HasUsage:
+			m = &UsageMetrics{Unit: "tokens", Used: floatPtr(d.TokensUsed)}
+		}
+		if m != nil && d.HasUsage {
+			m.Cost = floatPtr(d.TotalCost)
+			m.Currency = d.Currency
+		}
+	case GroqUsageInfo:
+		if worst, ok := d.mostConstrained(); ok {
+			m = &UsageMetrics{Unit: "requests", Used: floatPtr(worst.RequestsLimit - worst.RequestsRemaining), Limit: floatPtr(worst.RequestsLimit)}
+		}
+	case CerebrasUsageInfo:
+		if worst, ok := d.mostConstrained(); ok {
+			if worst.HasDailyTokens {
+				m = &UsageMetrics{Unit: "tokens", Used: floatPtr(worst.TokensDayLimit - worst.TokensDayRemaining), Limit: floatPtr(worst.TokensDayLimit)}
+			} else {
+				m = &UsageMetrics{Unit: "requests", Used: floatPtr(worst.RequestsLimit - worst.RequestsRemaining), Limit: floatPtr(worst.RequestsLimit)}
+			}
+		}
+	case SambaNovaUsageInfo:
+		if worst, ok := d.mostConstrained(); ok {
+			if worst.HasDaily {
+				m = &UsageMetrics{Unit: "requests", Used: floatPtr(worst.DayLimit - worst.DayRemaining), Limit: floatPtr(worst.DayLimit)}
+			} else {
+				m = &UsageMetrics{Unit: "requests", Used: floatPtr(worst.MinuteLimit - worst.MinuteRemaining), Limit: floatPtr(worst.MinuteLimit)}
+			}
+		}
+	case LiteLLMBudgetInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalSpend), Cost: floatPtr(d.TotalSpend), Currency: d.Currency}
+		if d.TotalBudget > 0 {
+			m.Limit = floatPtr(d.TotalBudget)
+			m.CostLimit = floatPtr(d.TotalBudget)
+		}
+	case BedrockUsageInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		if d.Budget > 0 {
+			m.Limit = floatPtr(d.Budget)
+			m.CostLimit = floatPtr(d.Budget)
+		}
+	case HeliconeUsageInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		if d.Budget > 0 {
+			m.Limit = floatPtr(d.Budget)
+			m.CostLimit = floatPtr(d.Budget)
+		}
+	case ElevenLabsInfo:
+		m = &UsageMetrics{Unit: "characters", Used: floatPtr(d.CharactersUsed), Limit: floatPtr(d.CharactersLimit)}
+	case AssemblyAIUsageInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+	case GatewayInfo:
+		switch {
+		case d.HasUsage:
+			m = &UsageMetrics{Unit: d.Unit, Used: floatPtr(d.Used)}
+			if d.Limit > 0 {
+				m.Limit = floatPtr(d.Limit)
+			}
+			if d.Unit == "cost" {
+				m.Cost, m.CostLimit, m.Currency = m.Used, m.Limit, d.Currency
+			}
+		case d.HasRateLimits && d.RequestsLimit > 0:
+			m = &UsageMetrics{Unit: "requests", Used: floatPtr(d.RequestsLimit - d.RequestsRemaining), Limit: floatPtr(d.RequestsLimit)}
+		}
+	case VertexUsageInfo:
+		if d.HasCost {
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+			if d.Budget > 0 {
+				m.Limit = floatPtr(d.Budget)
+				m.CostLimit = floatPtr(d.Budget)
+			}
+		} else if _, ok := d.mostUtilized(); ok {
+			m = &UsageMetrics{Unit: "percent"}
+		}
+	case VercelGatewayInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.MonthlySpend), Cost: floatPtr(d.MonthlySpend), Currency: d.Currency}
+	case OpenRouterInfo:
+		if used, limit, ok := d.keyLimitUsed(); ok {
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(used), Limit: floatPtr(limit), Cost: floatPtr(used), CostLimit: floatPtr(limit), Currency: d.Currency}
+		} else {
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.UsageMonthly), Cost: floatPtr(d.UsageMonthly), Currency: d.Currency}
+		}
+	case PortkeyInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+	case PeerStatusInfo:
+		if d.usage != nil {
+			usage := *d.usage
+			m = &usage
+		}
+	case PluginProviderInfo:
+		if used, ok := d.used(); ok {
+			m = &UsageMetrics{Unit: d.Unit, Used: floatPtr(used), Limit: d.Limit}
+			if d.Unit == "cost" {
+				m.Cost, m.CostLimit, m.Currency = m.Used, m.Limit, d.Currency
+			}
+		}
+	case CustomProviderInfo:
+		if d.HasUsed || d.Total > 0 {
+			m = &UsageMetrics{Unit: d.Unit, Used: floatPtr(d.Used)}
+			if d.Total > 0 {
+				m.Limit = floatPtr(d.Total)
+			}
+			if d.Unit == "cost" {
+				m.Cost, m.CostLimit, m.Currency = m.Used, m.Limit, d.Currency
+			}
+		}
+	case UpstageInfo:
+		if d.HasBalance {
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.MonthlyUsage), Cost: floatPtr(d.MonthlyUsage), Currency: d.Currency}
+		}
+	case AIGatewayInfo:
+		switch {
+		case d.TokenLimit > 0:
+			m = &UsageMetrics{Unit: "tokens", Used: floatPtr(d.Tokens), Limit: floatPtr(d.TokenLimit)}
+			if d.HasCost {
+				m.Cost, m.Currency = floatPtr(d.Cost), d.Currency
+			}
+		case d.HasCost:
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.Cost), Cost: floatPtr(d.Cost), Currency: d.Currency}
+			if d.Budget > 0 {
+				m.Limit = floatPtr(d.Budget)
+				m.CostLimit = floatPtr(d.Budget)
+			}
+		default:
+			m = &UsageMetrics{Unit: "tokens", Used: floatPtr(d.Tokens)}
+		}
+	case ClaudeCodeInfo:
+		// The list-price cost isn't money spent, so it stays out of the cost total
+		m = &UsageMetrics{Unit: "tokens", Used: floatPtr(d.TotalTokens)}
+	case GithubModelsInfo:
+		if t, ok := d.mostConstrained(); ok && t.RequestsLimit > 0 {
+			m = &UsageMetrics{Unit: "requests", Used: floatPtr(t.RequestsLimit - t.RequestsRemaining), Limit: floatPtr(t.RequestsLimit)}
+		}
+	case GeminiInfo:
+		if peak, ok := d.peak(); ok {
+			m = &UsageMetrics{Unit: "requests", Used: floatPtr(peak.Usage), Limit: floatPtr(peak.Limit)}
+		}
+	case AzureOpenAIInfo:
+		if peak, ok := d.peak(); ok {
+			unit, used, limit := peak.binding()
+			m = &UsageMetrics{Unit: unit, Used: floatPtr(used), Limit: floatPtr(limit)}
+		}
+	case NvidiaInfo:
+		if _, ok := d.usagePercent(); ok {
+			m = &UsageMetrics{Unit: "credits", Used: floatPtr(d.CreditsTotal - d.CreditsLeft), Limit: floatPtr(d.CreditsTotal)}
+		}
+	case DatabricksInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		if d.Budget > 0 {
+			m.Limit = floatPtr(d.Budget)
+			m.CostLimit = floatPtr(d.Budget)
+		}
+	case WatsonxInfo:
+		m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		if d.ResourceUnitLimit > 0 {
+			// One resource unit is 1,000 tokens
+			m = &UsageMetrics{Unit: "tokens", Used: floatPtr(d.ResourceUnits * 1000), Limit: floatPtr(d.ResourceUnitLimit * 1000),
+				Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+		} else if d.Budget > 0 {
+			m.Limit = floatPtr(d.Budget)
+			m.CostLimit = floatPtr(d.Budget)
+		}
+	case FalInfo:
+		if d.HasUsage {
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.MonthlySpend), Cost: floatPtr(d.MonthlySpend), Currency: d.Currency}
+		}
+	case DashScopeInfo:
+		if d.HasBilling {
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.TotalCost), Cost: floatPtr(d.TotalCost), Currency: d.Currency}
+			if d.Budget > 0 {
+				m.Limit = floatPtr(d.Budget)
+				m.CostLimit = floatPtr(d.Budget)
+			}
+		}
+	case XaiUsageInfo:
+		if d.HasBilling {
+			m = &UsageMetrics{Unit: "cost", Used: floatPtr(d.MonthlySpend), Cost: floatPtr(d.MonthlySpend), Currency: d.Currency}
+			if d.SpendingLimit > 0 {
+				m.Limit = floatPtr(d.SpendingLimit)
+				m.CostLimit = floatPtr(d.SpendingLimit)
+			}
+		}
+	case ClaudeUsageInfo:
+		if d.HasData {
+			m = &UsageMetrics{Unit: "percent"}
+		}
+	case CodexUsageInfo:
+		if d.HasData {
+			m = &UsageMetrics{Unit: "percent"}
+		}
+	default:
+		return nil
+	}
+	if m == nil {
+		return nil
+	}
+	if pct, ok := usagePercent(s); ok {
+		m.Percent = floatPtr(pct)
+	}
+	return m
+}
+
+// addUsageMetrics fills in the normalized usage of each service.
+func addUsageMetrics(services []ServiceStatus) {
+	for i, s := range services {
+		services[i].Usage = computeUsage(s)
+	}
+}
+
+// validUnits reports whether units is a known display unit choice.
+func validUnits(units string) bool {
+	switch units {
+	case UnitsRaw, UnitsCost, UnitsPercent:
+		return true
+	}
+	return false
+}
+
+// defaultUnits returns the server-wide display unit setting.
+func (c *Configuration) defaultUnits() string {
+	if validUnits(c.DisplayUnits) {
+		return c.DisplayUnits
+	}
+	return UnitsRaw
+}
+
+// summarizeInUnits is summarizeService, leading with the requested unit when
+// the provider reports it and falling back to the native summary otherwise.
+func summarizeInUnits(s ServiceStatus, units, locale string) string {
+	if !s.Enabled || s.Error != "" {
+		return summarizeService(s, locale)
+	}
+
+	m := computeUsage(s)
+	if m != nil {
+		switch units {
+		case UnitsCost:
+			if m.Cost != nil && m.CostLimit != nil {
+				return translate(locale, "summary.cost_of", formatMoney(*m.Cost, m.Currency, 2), formatMoney(*m.CostLimit, m.Currency, 2))
+			}
+			if m.Cost != nil {
+				return translate(locale, "summary.cost", formatMoney(*m.Cost, m.Currency, 2))
+			}
+		case UnitsPercent:
+			if m.Percent != nil {
+				return translate(locale, "summary.percent", *m.Percent)
+			}
+		}
+	}
+	return summarizeService(s, locale)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ===== Upstage Solar (API key) =====
+
+// upstageBalanceKey holds the history of the configured credit balance.
+const upstageBalanceKey = "upstage_balance"
+
+// upstageMaxSnapshots bounds the stored balance history, enough for a couple of months of
+// daily updates.
+const upstageMaxSnapshots = 70
+
+// UpstageInfo is the state of an Upstage API key and the console's credit balance. The
+// Upstage console doesn't expose billing through the API, so the balance comes from the
+// configuration, and the month's usage is what the configured balance has fallen by this
+// month. Top-ups raise the balance and aren't counted.
+type UpstageInfo struct {
+	Models         int     `json:"models"`
+	HasBalance     bool    `json:"hasBalance"`
+	CreditBalance  float64 `json:"creditBalance"`
+	LowBalance     float64 `json:"lowBalance,omitempty"`
+	BalanceUpdated string  `json:"balanceUpdated,omitempty"` // when the balance was last changed
+	MonthlyUsage   float64 `json:"monthlyUsage"`
+	Currency       string  `json:"currency"`
+	Period         string  `json:"period"`
+	CycleEnd       string  `json:"cycleEnd"`
+	DaysUntilReset int     `json:"daysUntilReset"`
+}
+
+func (u UpstageInfo) summary(locale string) string {
+	if !u.HasBalance {
+		return translate(locale, "summary.models_available", u.Models)
+	}
+	return translate(locale, "summary.credit_balance", formatMoney(u.CreditBalance, u.Currency, 2)) + ", " +
+		translate(locale, "summary.spent", formatMoney(u.MonthlyUsage, u.Currency, 2), u.Period)
+}
+
+func (u UpstageInfo) compact(name, locale string) string {
+	if u.HasBalance {
+		return translate(locale, "compact.balance_left", name, formatMoney(u.CreditBalance, u.Currency, 0))
+	}
+	return name
+}
+
+func (u UpstageInfo) resets(time.Time) []ResetInfo {
+	if u.HasBalance {
+		return []ResetInfo{{Kind: "monthly_billing", At: parseTime(u.CycleEnd)}}
+	}
+	return nil
+}
+
+// upstageSnapshot is a configured balance and when it was first seen.
+type upstageSnapshot struct {
+	At      string  `json:"at"`
+	Balance float64 `json:"balance"`
+}
+
+func (p *Plugin) getUpstageStatus(config *Configuration) ServiceStatus {
+	const id, name = "upstage", "Upstage Solar"
+	if config.UpstageApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	resp, err := client.Do(newUpstageRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var models struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &models); err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := UpstageInfo{
+		Models:         len(models.Data),
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+	if strings.TrimSpace(config.UpstageCreditBalance) != "" {
+		info.HasBalance = true
+		info.CreditBalance = p.budgetIn(config, id, config.UpstageCreditBalance, info.Currency)
+		info.LowBalance = p.budgetIn(config, id, config.UpstageLowBalance, info.Currency)
+		p.upstageUsage(&info, monthStart, now)
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: upstageStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// upstageUsage records the configured balance when it changes and sums its falls since the
+// start of the month.
+func (p *Plugin) upstageUsage(info *UpstageInfo, monthStart, now time.Time) {
+	var history []upstageSnapshot
+	if data, appErr := p.API.KVGet(p.scopedKey(upstageBalanceKey)); appErr == nil && data != nil {
+		json.Unmarshal(data, &history)
+	}
+	if len(history) == 0 || history[len(history)-1].Balance != info.CreditBalance {
+		history = append(history, upstageSnapshot{At: now.Format(time.RFC3339), Balance: info.CreditBalance})
+		if len(history) > upstageMaxSnapshots {
+			history = history[len(history)-upstageMaxSnapshots:]
+		}
+		data, _ := json.Marshal(history)
+		if appErr := p.API.KVSet(p.scopedKey(upstageBalanceKey), data); appErr != nil {
+			p.API.LogWarn("Failed to store Upstage balance history", "error", appErr.Error())
+		}
+	}
+
+	info.BalanceUpdated = history[len(history)-1].At
+	for i := 1; i < len(history); i++ {
+		if parseTime(history[i].At).Before(monthStart) {
+			continue
+		}
+		if fall := history[i-1].Balance - history[i].Balance; fall > 0 {
+			info.MonthlyUsage += fall
+		}
+	}
+}
+
+// upstageStatus is an error once the credits are used up, after which the API refuses
+// requests, and a warning below the configured low balance.
+func upstageStatus(info UpstageInfo) string {
+	switch {
+	case !info.HasBalance:
+		return "ok"
+	case info.CreditBalance <= 0:
+		return "error"
+	case info.LowBalance > 0 && info.CreditBalance < info.LowBalance:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newUpstageRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.upstage.ai/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+config.UpstageApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
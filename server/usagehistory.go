@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Usage history =====
+
+const (
+	// usageSampleInterval is how often the poller records usage; later samples in an hour
+	// or day replace earlier ones, so a shorter interval only adds KV writes.
+	usageSampleInterval = 5 * time.Minute
+
+	// Hourly points are kept for a week and daily ones for a year and a month, so the
+	// same month of last year can be compared. The KV store expires them.
+	hourlyHistoryDays = 7
+	dailyHistoryDays  = 400
+)
+
+// UsagePoint is a service's usage at the end of an hour or day bucket.
+type UsagePoint struct {
+	Time    int64    `json:"time"` // start of the bucket, Unix time
+	Status  string   `json:"status"`
+	Used    *float64 `json:"used,omitempty"`
+	Limit   *float64 `json:"limit,omitempty"`
+	Cost    *float64 `json:"cost,omitempty"`
+	Percent *float64 `json:"percent,omitempty"`
+	Peak    *float64 `json:"peak,omitempty"` // highest percent in the bucket
+}
+
+// UsageSeries is a service's usage over time, oldest first.
+type UsageSeries struct {
+	Provider string       `json:"provider"` // card ID, e.g. "openai:org-abc"
+	Name     string       `json:"name"`
+	Unit     string       `json:"unit,omitempty"`
+	Currency string       `json:"currency,omitempty"`
+	Points   []UsagePoint `json:"points"`
+}
+
+// usageBucket is the stored points of every service in one bucket period: a day of
+// hourly points, or a month of daily ones.
+type usageBucket map[string]*UsageSeries
+
+func hourlyUsageKey(day string) string {
+	return "usage_h_" + day
+}
+
+func dailyUsageKey(month string) string {
+	return "usage_d_" + month
+}
+
+func (p *Plugin) loadUsageBucket(key string) usageBucket {
+	bucket := usageBucket{}
+	if data, appErr := p.API.KVGet(key); appErr == nil && data != nil {
+		json.Unmarshal(data, &bucket)
+	}
+	return bucket
+}
+
+// add records the point as the series' latest, replacing a point of the same bucket and
+// carrying its peak over.
+func (b usageBucket) add(s ServiceStatus, usage *UsageMetrics, point UsagePoint) {
+	series := b[s.ID]
+	if series == nil {
+		series = &UsageSeries{Provider: s.ID}
+		b[s.ID] = series
+	}
+	series.Name = s.Name
+	if usage != nil {
+		series.Unit, series.Currency = usage.Unit, usage.Currency
+	}
+	if n := len(series.Points); n > 0 && series.Points[n-1].Time == point.Time {
+		if prev := series.Points[n-1].Peak; prev != nil && (point.Peak == nil || *prev > *point.Peak) {
+			point.Peak = prev
+		}
+		series.Points[n-1] = point
+		return
+	}
+	series.Points = append(series.Points, point)
+}
+
+// usagePoint captures a service's usage for the bucket starting at start.
+func usagePoint(s ServiceStatus, usage *UsageMetrics, start time.Time) UsagePoint {
+	point := UsagePoint{Time: start.Unix(), Status: s.Status}
+	if usage != nil {
+		point.Used, point.Limit, point.Cost = usage.Used, usage.Limit, usage.Cost
+	}
+	if pct, ok := usagePercent(s); ok {
+		point.Percent = floatPtr(pct)
+		point.Peak = floatPtr(pct)
+	}
+	return point
+}
+
+// recordUsageHistory stores the services' usage in the hourly and daily buckets, at most
+// once per sample interval. Disabled services, failed fetches, even those showing their
+// last known good data, and demo data are left out, so a gap shows that nothing was known.
+func (p *Plugin) recordUsageHistory(services []ServiceStatus, now time.Time) {
+	if p.getConfiguration().DemoMode || now.Sub(p.lastUsageSample) < usageSampleInterval {
+		return
+	}
+	p.lastUsageSample = now
+
+	now = now.UTC()
+	hour := now.Truncate(time.Hour)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	p.historyLock.Lock()
+	defer p.historyLock.Unlock()
+	hourly := p.loadUsageBucket(hourlyUsageKey(day.Format("2006-01-02")))
+	daily := p.loadUsageBucket(dailyUsageKey(day.Format("2006-01")))
+	for _, s := range services {
+		if !s.Enabled || s.Error != "" || s.Stale {
+			continue
+		}
+		usage := computeUsage(s)
+		hourly.add(s, usage, usagePoint(s, usage, hour))
+		daily.add(s, usage, usagePoint(s, usage, day))
+	}
+
+	p.storeUsageBucket(hourlyUsageKey(day.Format("2006-01-02")), hourly, day.AddDate(0, 0, hourlyHistoryDays+1))
+	monthEnd := time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	p.storeUsageBucket(dailyUsageKey(day.Format("2006-01")), daily, monthEnd.AddDate(0, 0, dailyHistoryDays))
+}
+
+// storeUsageBucket saves a bucket until it's past the retention.
+func (p *Plugin) storeUsageBucket(key string, bucket usageBucket, expires time.Time) {
+	data, _ := json.Marshal(bucket)
+	if appErr := p.API.KVSetWithExpiry(key, data, int64(time.Until(expires)/time.Second)); appErr != nil {
+		p.API.LogWarn("Failed to store usage history", "key", key, "error", appErr.Error())
+	}
+}
+
+// usageHistory returns the series of the services matching provider, a provider or card
+// ID or "" for all, between since and now.
+func (p *Plugin) usageHistory(provider, resolution string, since, now time.Time) []UsageSeries {
+	var keys []string
+	if resolution == "hourly" {
+		for day := since.UTC().Truncate(24 * time.Hour); !day.After(now); day = day.AddDate(0, 0, 1) {
+			keys = append(keys, hourlyUsageKey(day.Format("2006-01-02")))
+		}
+	} else {
+		for month := time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(now); month = month.AddDate(0, 1, 0) {
+			keys = append(keys, dailyUsageKey(month.Format("2006-01")))
+		}
+	}
+
+	merged := map[string]*UsageSeries{}
+	p.historyLock.Lock()
+	for _, key := range keys {
+		for id, s := range p.loadUsageBucket(key) {
+			if provider != "" && id != provider && !strings.HasPrefix(id, provider+":") {
+				continue
+			}
+			series := merged[id]
+			if series == nil {
+				series = &UsageSeries{Provider: id, Points: []UsagePoint{}}
+				merged[id] = series
+			}
+			// The latest bucket has the current name and unit
+			series.Name, series.Unit, series.Currency = s.Name, s.Unit, s.Currency
+			for _, point := range s.Points {
+				if point.Time >= since.Unix() {
+					series.Points = append(series.Points, point)
+				}
+			}
+		}
+	}
+	p.historyLock.Unlock()
+
+	result := []UsageSeries{}
+	for _, series := range merged {
+		sort.Slice(series.Points, func(i, j int) bool { return series.Points[i].Time < series.Points[j].Time })
+		result = append(result, *series)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Provider < result[j].Provider })
+	return result
+}
+
+// handleHistory serves GET /api/v1/history: each service's recorded usage over the last
+// days (30 by default), daily or, for up to a week, hourly. provider filters by provider
+// or card ID.
+func (p *Plugin) handleHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	days := 30
+	if v := query.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > dailyHistoryDays {
+			http.Error(w, fmt.Sprintf(`{"error": "invalid_days", "message": "days must be between 1 and %d"}`, dailyHistoryDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	resolution := query.Get("resolution")
+	switch resolution {
+	case "":
+		resolution = "daily"
+	case "daily":
+	case "hourly":
+		if days > hourlyHistoryDays {
+			http.Error(w, fmt.Sprintf(`{"error": "invalid_resolution", "message": "hourly history covers at most %d days"}`, hourlyHistoryDays), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, `{"error": "invalid_resolution", "message": "resolution must be daily or hourly"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -days)
+	if resolution == "daily" {
+		// Whole days, so the first day's point is included
+		since = since.Truncate(24 * time.Hour)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since":      since.Unix(),
+		"until":      now.Unix(),
+		"resolution": resolution,
+		"series":     p.usageHistory(query.Get("provider"), resolution, since, now),
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ===== User-Agent =====
+
+const pluginID = "com.fambear.ai-limits-monitor"
+
+// defaultUserAgent identifies the plugin when its version and the server are unknown.
+const defaultUserAgent = "MattermostPlugin/1.0"
+
+// buildUserAgent returns the default User-Agent: the plugin name and version, and a hash of
+// the server's site URL so a provider or proxy can tell servers apart without learning
+// their address.
+func (p *Plugin) buildUserAgent() string {
+	version := "unknown"
+	if status, appErr := p.API.GetPluginStatus(pluginID); appErr == nil && status.Version != "" {
+		version = status.Version
+	}
+	ua := "Mattermost-AI-Limits-Monitor/" + version
+	if config := p.API.GetConfig(); config != nil && config.ServiceSettings.SiteURL != nil && *config.ServiceSettings.SiteURL != "" {
+		sum := sha256.Sum256([]byte(strings.TrimRight(*config.ServiceSettings.SiteURL, "/")))
+		ua += fmt.Sprintf(" (server %s)", hex.EncodeToString(sum[:])[:12])
+	}
+	return ua
+}
+
+// userAgent is the User-Agent sent to providers: the configured one, or the default.
+func (p *Plugin) userAgent() string {
+	if ua := strings.TrimSpace(p.getConfiguration().UserAgent); ua != "" {
+		return ua
+	}
+	if p.defaultUA != "" {
+		return p.defaultUA
+	}
+	return defaultUserAgent
+}
+
+// prepareProviderRequest returns a copy of a provider request with the User-Agent and the
+// provider's configured headers set, replacing those the provider client sets itself.
+func (p *Plugin) prepareProviderRequest(req *http.Request, provider string) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", p.userAgent())
+	for name, value := range p.getConfiguration().providerHeaders(provider) {
+		req.Header.Set(name, value)
+	}
+	return req
+}
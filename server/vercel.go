@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ===== Vercel AI Gateway (API key) =====
+
+// VercelGatewayInfo is the AI Gateway credit balance, which includes the team's free monthly
+// credit, and the spend routed through the gateway. The API only reports lifetime spend, so
+// the month's spend is measured from the first refresh of the month.
+type VercelGatewayInfo struct {
+	Balance        float64 `json:"balance"`
+	LowBalance     float64 `json:"lowBalance,omitempty"`
+	TotalUsed      float64 `json:"totalUsed"`
+	MonthlySpend   float64 `json:"monthlySpend"`
+	TrackedSince   string  `json:"trackedSince"`
+	Currency       string  `json:"currency"`
+	Period         string  `json:"period"`
+	CycleEnd       string  `json:"cycleEnd"`
+	DaysUntilReset int     `json:"daysUntilReset"`
+}
+
+func (v VercelGatewayInfo) summary(locale string) string {
+	return translate(locale, "summary.credit_balance", formatMoney(v.Balance, v.Currency, 2)) + ", " +
+		translate(locale, "summary.spent", formatMoney(v.MonthlySpend, v.Currency, 2), v.Period)
+}
+
+func (v VercelGatewayInfo) compact(name, locale string) string {
+	return translate(locale, "compact.balance_left", name, formatMoney(v.Balance, v.Currency, 0))
+}
+
+func (v VercelGatewayInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(v.CycleEnd)}}
+}
+
+// vercelCreditsResponse is GET /v1/credits.
+type vercelCreditsResponse struct {
+	Balance   flexFloat `json:"balance" schema:"required"`
+	TotalUsed flexFloat `json:"total_used" schema:"required"`
+}
+
+// vercelMonth is the lifetime spend at the first refresh of a month.
+type vercelMonth struct {
+	TotalUsed float64 `json:"totalUsed"`
+	FirstSeen string  `json:"firstSeen"`
+}
+
+func vercelMonthKey(month string) string {
+	return "vercel_" + month
+}
+
+// vercelGatewayProvider reads the gateway's credits with an API key.
+type vercelGatewayProvider struct{}
+
+func (vercelGatewayProvider) ID() string   { return "vercel" }
+func (vercelGatewayProvider) Name() string { return "Vercel AI Gateway" }
+
+func (vercelGatewayProvider) Enabled(config *Configuration) bool {
+	return config.VercelEnabled
+}
+
+func (vercelGatewayProvider) EvaluateStatus(data interface{}, _ *Configuration) string {
+	return vercelGatewayStatus(data.(VercelGatewayInfo))
+}
+
+func (v vercelGatewayProvider) Fetch(p *Plugin, config *Configuration) (interface{}, error) {
+	if config.VercelApiKey == "" {
+		return nil, errFetch("error.api_key_missing")
+	}
+
+	var credits vercelCreditsResponse
+	client := p.providerClient(v.ID(), 15*time.Second)
+	if err := p.getJSON(v.ID(), client, newVercelCreditsRequest(config), &credits); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := VercelGatewayInfo{
+		Balance:        float64(credits.Balance),
+		TotalUsed:      float64(credits.TotalUsed),
+		Currency:       "USD",
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+
+	monthKey := p.scopedKey(vercelMonthKey(monthStart.Format("2006-01")))
+	month := vercelMonth{TotalUsed: info.TotalUsed, FirstSeen: now.Format(time.RFC3339)}
+	if data, appErr := p.API.KVGet(monthKey); appErr == nil && data != nil {
+		json.Unmarshal(data, &month)
+	} else {
+		data, _ := json.Marshal(month)
+		if appErr := p.API.KVSet(monthKey, data); appErr != nil {
+			p.API.LogWarn("Failed to store Vercel AI Gateway spend", "month", monthKey, "error", appErr.Error())
+		}
+	}
+	info.MonthlySpend = max(info.TotalUsed-month.TotalUsed, 0)
+	info.TrackedSince = month.FirstSeen
+	info.LowBalance = p.budgetIn(config, v.ID(), config.VercelLowBalance, info.Currency)
+	return info, nil
+}
+
+// vercelGatewayStatus is an error once the credits are used up, when the gateway stops
+// routing paid requests, and a warning below the configured low balance.
+func vercelGatewayStatus(info VercelGatewayInfo) string {
+	switch {
+	case info.Balance <= 0:
+		return "error"
+	case info.LowBalance > 0 && info.Balance < info.LowBalance:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newVercelCreditsRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://ai-gateway.vercel.sh/v1/credits", nil)
+	req.Header.Set("Authorization", "Bearer "+config.VercelApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
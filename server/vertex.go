@@ -0,0 +1,633 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Google Vertex AI (service account) =====
+
+// vertexBillingTableRe matches a fully qualified BigQuery table, e.g.
+// "my-project.billing.gcp_billing_export_v1_012345_6789AB_CDEF01".
+var vertexBillingTableRe = regexp.MustCompile(`^[A-Za-z0-9_:-]+(\.[A-Za-z0-9_-]+){2}$`)
+
+// vertexServices are the billing export service descriptions that count as Vertex AI spend.
+var vertexServices = []string{"Vertex AI", "Gemini API"}
+
+// vertexScopes are the OAuth scopes requested for the service account.
+var vertexScopes = []string{
+	"https://www.googleapis.com/auth/bigquery.readonly",
+	"https://www.googleapis.com/auth/monitoring.read",
+}
+
+// VertexUsageInfo is the month-to-date Vertex AI spend from the BigQuery billing export
+// and the most utilized quotas of each monitored project.
+type VertexUsageInfo struct {
+	TotalCost      float64              `json:"totalCost"`
+	HasCost        bool                 `json:"hasCost"`
+	Budget         float64              `json:"budget,omitempty"`
+	Currency       string               `json:"currency"`
+	Period         string               `json:"period"`
+	CycleEnd       string               `json:"cycleEnd"`
+	DaysUntilReset int                  `json:"daysUntilReset"`
+	Projects       []VertexProjectUsage `json:"projects"`
+}
+
+// VertexProjectUsage is one project's spend and its peak quota utilization over the last
+// quarter hour.
+type VertexProjectUsage struct {
+	ProjectID    string  `json:"projectId"`
+	Cost         float64 `json:"cost"`
+	HasQuota     bool    `json:"hasQuota"`
+	QuotaMetric  string  `json:"quotaMetric,omitempty"`
+	Location     string  `json:"location,omitempty"`
+	QuotaUsage   float64 `json:"quotaUsage"`
+	QuotaLimit   float64 `json:"quotaLimit"`
+	QuotaPercent float64 `json:"quotaPercent"`
+	QuotaWindow  string  `json:"quotaWindow,omitempty"` // "day" for a daily quota, otherwise per minute
+	// The per-model request quotas closest to their limits, most utilized first
+	Models []VertexModelQuota `json:"models,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// VertexModelQuota is a per-model quota of Vertex AI or the Gemini API: the peak
+// per-minute usage over the last quarter hour, or the usage since the daily reset at
+// midnight Pacific time.
+type VertexModelQuota struct {
+	Metric   string  `json:"metric"` // the quota metric, as in the consumer quota labels
+	Model    string  `json:"model"`
+	Location string  `json:"location,omitempty"`
+	Window   string  `json:"window"` // "minute" or "day"
+	Usage    float64 `json:"usage"`
+	Limit    float64 `json:"limit"`
+	Percent  float64 `json:"percent"`
+}
+
+// mostUtilized is the project closest to a quota limit.
+func (v VertexUsageInfo) mostUtilized() (VertexProjectUsage, bool) {
+	var worst VertexProjectUsage
+	found := false
+	for _, p := range v.Projects {
+		if p.HasQuota && (!found || p.QuotaPercent > worst.QuotaPercent) {
+			worst, found = p, true
+		}
+	}
+	return worst, found
+}
+
+func (v VertexUsageInfo) summary(locale string) string {
+	var parts []string
+	if v.HasCost {
+		if v.Budget > 0 {
+			parts = append(parts, translate(locale, "summary.spent_budget", formatMoney(v.TotalCost, v.Currency, 2), formatMoney(v.Budget, v.Currency, 0), v.Period))
+		} else {
+			parts = append(parts, translate(locale, "summary.spent", formatMoney(v.TotalCost, v.Currency, 2), v.Period))
+		}
+	}
+	if worst, ok := v.mostUtilized(); ok && worst.QuotaMetric != "" {
+		parts = append(parts, translate(locale, "summary.vertex_quota", worst.QuotaPercent, worst.quotaName(), worst.ProjectID))
+	}
+	if len(parts) == 0 {
+		return translate(locale, "summary.vertex_no_data")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (v VertexUsageInfo) compact(name, locale string) string {
+	switch {
+	case v.HasCost && v.Budget > 0:
+		return fmt.Sprintf("%s %s/%s", name, formatMoney(v.TotalCost, v.Currency, 0), formatMoney(v.Budget, v.Currency, 0))
+	case v.HasCost:
+		return fmt.Sprintf("%s %s", name, formatMoney(v.TotalCost, v.Currency, 0))
+	}
+	if pct, ok := v.usagePercent(); ok {
+		return fmt.Sprintf("%s %.0f%%", name, pct)
+	}
+	return name
+}
+
+func (v VertexUsageInfo) usagePercent() (float64, bool) {
+	pct, ok := 0.0, false
+	if v.HasCost && v.Budget > 0 {
+		pct, ok = v.TotalCost/v.Budget*100, true
+	}
+	if worst, found := v.mostUtilized(); found {
+		pct, ok = max(pct, worst.QuotaPercent), true
+	}
+	return pct, ok
+}
+
+func (v VertexUsageInfo) resets(time.Time) []ResetInfo {
+	if v.HasCost {
+		return []ResetInfo{{Kind: "monthly_billing", At: parseTime(v.CycleEnd)}}
+	}
+	return nil
+}
+
+// quotaName names the project's peak quota: the model of a per-model quota, otherwise
+// the quota metric.
+func (u VertexProjectUsage) quotaName() string {
+	for _, m := range u.Models {
+		if m.Metric == u.QuotaMetric && m.Location == u.Location && m.Percent == u.QuotaPercent {
+			return m.Model
+		}
+	}
+	return strings.TrimPrefix(u.QuotaMetric, "aiplatform.googleapis.com/")
+}
+
+// vertexQueryResponse is BigQuery's jobs.query.
+type vertexQueryResponse struct {
+	Kind         string `json:"kind"`
+	JobComplete  bool   `json:"jobComplete" schema:"required"`
+	TotalRows    string `json:"totalRows"`
+	JobReference struct {
+		ProjectID string `json:"projectId"`
+		JobID     string `json:"jobId"`
+		Location  string `json:"location"`
+	} `json:"jobReference"`
+	Schema struct {
+		Fields []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+			Mode string `json:"mode"`
+		} `json:"fields"`
+	} `json:"schema"`
+	Rows []struct {
+		F []struct {
+			V *string `json:"v"` // scalars are strings, or null
+		} `json:"f" schema:"required"`
+	} `json:"rows"`
+	TotalBytesProcessed string `json:"totalBytesProcessed"`
+	CacheHit            bool   `json:"cacheHit"`
+}
+
+// vertexTimeSeriesResponse is Cloud Monitoring's projects.timeSeries.list.
+type vertexTimeSeriesResponse struct {
+	TimeSeries []struct {
+		Metric struct {
+			Type   string            `json:"type"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metric" schema:"required"`
+		Resource struct {
+			Type   string            `json:"type"`
+			Labels map[string]string `json:"labels"`
+		} `json:"resource"`
+		MetricKind string `json:"metricKind"`
+		ValueType  string `json:"valueType"`
+		Points     []struct {
+			Interval struct {
+				StartTime string `json:"startTime"`
+				EndTime   string `json:"endTime"`
+			} `json:"interval"`
+			Value struct {
+				Int64Value  flexFloat `json:"int64Value"`
+				DoubleValue flexFloat `json:"doubleValue"`
+			} `json:"value" schema:"required"`
+		} `json:"points" schema:"required"`
+	} `json:"timeSeries"`
+	NextPageToken string `json:"nextPageToken"`
+	Unit          string `json:"unit"`
+}
+
+// vertexProjects is the configured project list, or the service account's own project.
+func (c *Configuration) vertexProjects(sa gcpServiceAccount) []string {
+	if projects := splitList(c.VertexProjects); len(projects) > 0 {
+		return projects
+	}
+	if sa.ProjectID != "" {
+		return []string{sa.ProjectID}
+	}
+	return nil
+}
+
+func (p *Plugin) getVertexStatus(config *Configuration) ServiceStatus {
+	const id, name = "vertex", "Google Vertex AI"
+	if strings.TrimSpace(config.VertexServiceAccountKey) == "" {
+		return errorStatus(id, name, "error.gcp_key_missing")
+	}
+	sa, err := parseServiceAccount(config.VertexServiceAccountKey)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	projects := config.vertexProjects(sa)
+	if len(projects) == 0 {
+		return errorStatus(id, name, "error.gcp_projects_missing")
+	}
+	table := strings.Trim(strings.TrimSpace(config.VertexBillingTable), "`")
+	if table != "" && !vertexBillingTableRe.MatchString(table) {
+		return errorStatus(id, name, "error.gcp_billing_table_invalid", table)
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 30*time.Second)
+	token, err := p.gcpAccessToken(client, sa, vertexScopes...)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := VertexUsageInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+	var drift schemaDrift
+
+	costs := map[string]float64{}
+	if table != "" {
+		body, err := p.gcpCall(client, newVertexBillingRequest(token, table, projects, monthStart))
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		var result vertexQueryResponse
+		d, err := decodeResponse(body, &result)
+		if err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		drift.merge(d)
+		if !result.JobComplete {
+			return errorStatus(id, name, "error.api", "billing export query did not finish in time")
+		}
+		// Rows are (project, cost, currency)
+		for _, row := range result.Rows {
+			if len(row.F) < 3 {
+				continue
+			}
+			if row.F[0].V == nil || row.F[1].V == nil {
+				continue
+			}
+			cost, err := strconv.ParseFloat(*row.F[1].V, 64)
+			if err != nil {
+				return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+			}
+			costs[*row.F[0].V] += cost
+			info.TotalCost += cost
+			if currency := row.F[2].V; currency != nil && *currency != "" {
+				info.Currency = strings.ToUpper(*currency)
+			}
+		}
+		info.HasCost = true
+	}
+
+	// Quota metrics are optional per project: a missing monitoring.viewer role only hides its quota
+	for _, project := range projects {
+		usage := VertexProjectUsage{ProjectID: project, Cost: costs[project]}
+		d, err := p.vertexQuota(client, token, &usage, now)
+		if err != nil {
+			usage.Error = err.Error()
+		}
+		drift.merge(d)
+		if err == nil {
+			d, err = p.vertexModelQuotas(client, token, &usage, vertexModelQuotaMetrics, now)
+			if err != nil {
+				usage.Error = err.Error()
+			}
+			drift.merge(d)
+		}
+		info.Projects = append(info.Projects, usage)
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info.Budget = p.budgetIn(config, id, config.VertexMonthlyBudget, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: vertexStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// vertexStatus is the budget status, raised when a quota is close to or at its limit.
+func vertexStatus(info VertexUsageInfo, config *Configuration) string {
+	status := budgetStatus(info.TotalCost, info.Budget, config)
+	if worst, ok := info.mostUtilized(); ok && status == "ok" {
+		if worst.QuotaPercent >= 100 {
+			return "error"
+		}
+		if worst.QuotaPercent > config.warningPercent(80) {
+			return "warning"
+		}
+	}
+	return status
+}
+
+// vertexQuota fills in the project's most utilized per-minute quota: the peak of each
+// quota's summed per-minute usage over the last 15 minutes against its smallest per-minute limit.
+func (p *Plugin) vertexQuota(client *http.Client, token string, usage *VertexProjectUsage, now time.Time) (schemaDrift, error) {
+	var drift schemaDrift
+	key := func(labels map[string]string, resource map[string]string) string {
+		return labels["quota_metric"] + "|" + resource["location"]
+	}
+
+	limitBody, err := p.gcpCall(client, newVertexTimeSeriesRequest(token, usage.ProjectID, "serviceruntime.googleapis.com/quota/limit", now.Add(-24*time.Hour), now, nil))
+	if err != nil {
+		return drift, err
+	}
+	var limitSeries vertexTimeSeriesResponse
+	d, err := decodeResponse(limitBody, &limitSeries)
+	if err != nil {
+		return drift, err
+	}
+	drift.merge(d)
+	limits := map[string]float64{}
+	for _, ts := range limitSeries.TimeSeries {
+		if !strings.Contains(ts.Metric.Labels["limit_name"], "PerMinute") || len(ts.Points) == 0 {
+			continue
+		}
+		// Points are newest first; a negative limit means unlimited
+		v := ts.Points[0].Value
+		limit := float64(v.Int64Value) + float64(v.DoubleValue)
+		k := key(ts.Metric.Labels, ts.Resource.Labels)
+		if current, ok := limits[k]; limit > 0 && (!ok || limit < current) {
+			limits[k] = limit
+		}
+	}
+
+	usageBody, err := p.gcpCall(client, newVertexTimeSeriesRequest(token, usage.ProjectID, "serviceruntime.googleapis.com/quota/rate/net_usage", now.Add(-15*time.Minute), now, neturl.Values{
+		"aggregation.alignmentPeriod":    {"60s"},
+		"aggregation.perSeriesAligner":   {"ALIGN_SUM"},
+		"aggregation.crossSeriesReducer": {"REDUCE_SUM"},
+		"aggregation.groupByFields":      {"metric.label.quota_metric", "resource.label.location"},
+	}))
+	if err != nil {
+		return drift, err
+	}
+	var usageSeries vertexTimeSeriesResponse
+	d, err = decodeResponse(usageBody, &usageSeries)
+	if err != nil {
+		return drift, err
+	}
+	drift.merge(d)
+
+	for _, ts := range usageSeries.TimeSeries {
+		k := key(ts.Metric.Labels, ts.Resource.Labels)
+		limit, ok := limits[k]
+		if !ok {
+			continue
+		}
+		peak := 0.0
+		for _, pt := range ts.Points {
+			peak = max(peak, float64(pt.Value.Int64Value)+float64(pt.Value.DoubleValue))
+		}
+		if pct := peak / limit * 100; !usage.HasQuota || pct > usage.QuotaPercent {
+			usage.HasQuota = true
+			usage.QuotaMetric = ts.Metric.Labels["quota_metric"]
+			usage.Location = ts.Resource.Labels["location"]
+			usage.QuotaUsage, usage.QuotaLimit, usage.QuotaPercent = peak, limit, pct
+		}
+	}
+	// Quotas without recent usage still count as monitored
+	if !usage.HasQuota && len(limits) > 0 {
+		usage.HasQuota = true
+	}
+	return drift, nil
+}
+
+// modelQuotaMetric is a per-model quota metric and the label that holds the model.
+type modelQuotaMetric struct{ metric, modelLabel string }
+
+// vertexModelQuotaMetrics are the per-model request quotas of Vertex AI and the Gemini
+// API. Unlike the consumer quota metrics these are labelled with the model.
+var vertexModelQuotaMetrics = []modelQuotaMetric{
+	{"aiplatform.googleapis.com/quota/generate_content_requests_per_minute_per_project_per_base_model", "base_model"},
+	{"aiplatform.googleapis.com/quota/online_prediction_requests_per_base_model", "base_model"},
+	{"generativelanguage.googleapis.com/quota/generate_requests_per_model", "model"},
+	{"generativelanguage.googleapis.com/quota/generate_requests_per_model_per_day", "model"},
+}
+
+// vertexModelQuotasShown is how many per-model quotas a project lists.
+const vertexModelQuotasShown = 5
+
+// vertexQuotaDayStart is when Google's daily quotas were last reset: midnight Pacific time.
+func vertexQuotaDayStart(now time.Time) time.Time {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		loc = time.FixedZone("PST", -8*3600)
+	}
+	t := now.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// vertexModelQuotas fills in the project's per-model request quotas of the given metrics.
+// A quota more utilized than the project's peak per-minute quota becomes its peak. Metrics
+// of a service the project doesn't use are skipped; only when none can be read is it an error.
+func (p *Plugin) vertexModelQuotas(client *http.Client, token string, usage *VertexProjectUsage, metrics []modelQuotaMetric, now time.Time) (schemaDrift, error) {
+	var drift schemaDrift
+	list := func(metric string, start time.Time, aggregation neturl.Values) (vertexTimeSeriesResponse, error) {
+		var series vertexTimeSeriesResponse
+		body, err := p.gcpCall(client, newVertexModelQuotaRequest(token, usage.ProjectID, metric, start, now, aggregation))
+		if err != nil {
+			return series, err
+		}
+		d, err := decodeResponse(body, &series)
+		drift.merge(d)
+		return series, err
+	}
+
+	// Per-minute quotas take the busiest minute of the last quarter hour, daily ones the
+	// sum since the reset
+	sinceReset := max(int(now.Sub(vertexQuotaDayStart(now)).Seconds()), 60)
+	windowStart := map[string]time.Time{"minute": now.Add(-15 * time.Minute), "day": now.Add(-time.Duration(sinceReset) * time.Second)}
+	windowPeriod := map[string]string{"minute": "60s", "day": strconv.Itoa(sinceReset) + "s"}
+
+	var lastErr error
+	read := 0
+	for _, m := range metrics {
+		key := func(labels, resource map[string]string) string {
+			return labels[m.modelLabel] + "|" + resource["location"]
+		}
+		limitSeries, err := list(m.metric+"/limit", now.Add(-24*time.Hour), nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		read++
+		limits := map[string]VertexModelQuota{}
+		for _, ts := range limitSeries.TimeSeries {
+			window := ""
+			switch name := ts.Metric.Labels["limit_name"]; {
+			case strings.Contains(name, "PerMinute"):
+				window = "minute"
+			case strings.Contains(name, "PerDay"):
+				window = "day"
+			}
+			model := ts.Metric.Labels[m.modelLabel]
+			if window == "" || model == "" || len(ts.Points) == 0 {
+				continue
+			}
+			// Points are newest first; a negative limit means unlimited
+			v := ts.Points[0].Value
+			limit := float64(v.Int64Value) + float64(v.DoubleValue)
+			k := key(ts.Metric.Labels, ts.Resource.Labels)
+			if current, ok := limits[k]; limit > 0 && (!ok || limit < current.Limit) {
+				limits[k] = VertexModelQuota{Metric: strings.Replace(m.metric, "/quota/", "/", 1), Model: model, Location: ts.Resource.Labels["location"], Window: window, Limit: limit}
+			}
+		}
+
+		windows := map[string]bool{}
+		for _, q := range limits {
+			windows[q.Window] = true
+		}
+		used := map[string]float64{}
+		for window := range windows {
+			series, err := list(m.metric+"/usage", windowStart[window], neturl.Values{
+				"aggregation.alignmentPeriod":    {windowPeriod[window]},
+				"aggregation.perSeriesAligner":   {"ALIGN_SUM"},
+				"aggregation.crossSeriesReducer": {"REDUCE_SUM"},
+				"aggregation.groupByFields":      {"metric.label." + m.modelLabel, "resource.label.location"},
+			})
+			if err != nil {
+				return drift, err
+			}
+			for _, ts := range series.TimeSeries {
+				k := key(ts.Metric.Labels, ts.Resource.Labels)
+				if limits[k].Window != window {
+					continue
+				}
+				for _, pt := range ts.Points {
+					used[k] = max(used[k], float64(pt.Value.Int64Value)+float64(pt.Value.DoubleValue))
+				}
+			}
+		}
+
+		for k, q := range limits {
+			q.Usage = used[k]
+			q.Percent = q.Usage / q.Limit * 100
+			usage.Models = append(usage.Models, q)
+		}
+	}
+	if read == 0 {
+		return drift, lastErr
+	}
+	if len(usage.Models) == 0 {
+		return drift, nil
+	}
+
+	sort.Slice(usage.Models, func(i, j int) bool {
+		a, b := usage.Models[i], usage.Models[j]
+		if a.Percent != b.Percent {
+			return a.Percent > b.Percent
+		}
+		return a.Metric+a.Model+a.Location < b.Metric+b.Model+b.Location
+	})
+	if len(usage.Models) > vertexModelQuotasShown {
+		usage.Models = usage.Models[:vertexModelQuotasShown]
+	}
+	usage.HasQuota = true
+	if top := usage.Models[0]; top.Percent > usage.QuotaPercent {
+		usage.QuotaMetric, usage.Location, usage.QuotaWindow = top.Metric, top.Location, top.Window
+		usage.QuotaUsage, usage.QuotaLimit, usage.QuotaPercent = top.Usage, top.Limit, top.Percent
+	}
+	return drift, nil
+}
+
+// gcpCall sends an authorized request and returns the body of a successful response.
+func (p *Plugin) gcpCall(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		var errResp struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error.Message != "" {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+// newVertexBillingRequest sums this invoice month's Vertex AI cost, net of credits, per
+// project. The query job runs in the project that holds the billing export.
+func newVertexBillingRequest(token, table string, projects []string, monthStart time.Time) *http.Request {
+	query := fmt.Sprintf("SELECT project.id, SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)), ANY_VALUE(currency) "+
+		"FROM `%s` WHERE invoice.month = @month AND service.description IN UNNEST(@services) AND project.id IN UNNEST(@projects) "+
+		"GROUP BY 1", table)
+	stringArray := func(name string, values []string) map[string]any {
+		items := make([]map[string]string, len(values))
+		for i, v := range values {
+			items[i] = map[string]string{"value": v}
+		}
+		return map[string]any{
+			"name":           name,
+			"parameterType":  map[string]any{"type": "ARRAY", "arrayType": map[string]string{"type": "STRING"}},
+			"parameterValue": map[string]any{"arrayValues": items},
+		}
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"query":         query,
+		"useLegacySql":  false,
+		"timeoutMs":     20000,
+		"parameterMode": "NAMED",
+		"queryParameters": []map[string]any{
+			{"name": "month", "parameterType": map[string]string{"type": "STRING"}, "parameterValue": map[string]string{"value": monthStart.Format("200601")}},
+			stringArray("services", vertexServices),
+			stringArray("projects", projects),
+		},
+	})
+	billingProject := strings.SplitN(table, ".", 2)[0]
+	req, _ := http.NewRequest("POST", "https://bigquery.googleapis.com/bigquery/v2/projects/"+neturl.PathEscape(billingProject)+"/queries", bytes.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// newVertexTimeSeriesRequest lists a Vertex AI consumer quota metric of the project.
+func newVertexTimeSeriesRequest(token, project, metric string, start, end time.Time, aggregation neturl.Values) *http.Request {
+	query := neturl.Values{
+		"filter":             {fmt.Sprintf(`metric.type="%s" AND resource.type="consumer_quota" AND resource.label.service="aiplatform.googleapis.com"`, metric)},
+		"interval.startTime": {start.Format(time.RFC3339)},
+		"interval.endTime":   {end.Format(time.RFC3339)},
+	}
+	for k, v := range aggregation {
+		query[k] = v
+	}
+	req, _ := http.NewRequest("GET", "https://monitoring.googleapis.com/v3/projects/"+neturl.PathEscape(project)+"/timeSeries?"+query.Encode(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// newVertexModelQuotaRequest lists a per-model quota metric of the project.
+func newVertexModelQuotaRequest(token, project, metric string, start, end time.Time, aggregation neturl.Values) *http.Request {
+	query := neturl.Values{
+		"filter":             {fmt.Sprintf(`metric.type="%s"`, metric)},
+		"interval.startTime": {start.Format(time.RFC3339)},
+		"interval.endTime":   {end.Format(time.RFC3339)},
+	}
+	for k, v := range aggregation {
+		query[k] = v
+	}
+	req, _ := http.NewRequest("GET", "https://monitoring.googleapis.com/v3/projects/"+neturl.PathEscape(project)+"/timeSeries?"+query.Encode(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
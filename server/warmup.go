@@ -0,0 +1,92 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// ===== Cache warm-up =====
+
+// warmUpWorkers bounds the providers fetched at once during a warm-up.
+const warmUpWorkers = 4
+
+// warmUpStagger spaces out the start of each provider's fetch.
+const warmUpStagger = 250 * time.Millisecond
+
+// warmUpCache fetches every enabled provider in the background, a few at a time, so the
+// first dashboard view after a restart or a config save is served from the cache instead
+// of waiting for each provider in turn. A config change during a warm-up queues another.
+func (p *Plugin) warmUpCache() {
+	p.warmAgain.Store(true)
+	if !p.warming.CompareAndSwap(false, true) {
+		return
+	}
+	started := p.bg.Go(p, func() {
+		for {
+			for p.warmAgain.Swap(false) && !p.bg.stopping() {
+				p.warmUpProviders()
+			}
+			p.warming.Store(false)
+			// A request that arrived just as the loop ended still gets its warm-up
+			if !p.warmAgain.Load() || !p.warming.CompareAndSwap(false, true) {
+				return
+			}
+		}
+	})
+	if !started {
+		p.warming.Store(false)
+	}
+}
+
+func (p *Plugin) warmUpProviders() {
+	start := time.Now()
+	config := p.getConfiguration()
+	if config.DemoMode {
+		return
+	}
+
+	type job struct {
+		plugin *Plugin
+		prov   provider
+		config *Configuration
+	}
+	var jobs []job
+	for _, prov := range providers {
+		if prov.Enabled(config) {
+			jobs = append(jobs, job{p, prov, config})
+		}
+	}
+	p.instancesLock.Lock()
+	for _, inst := range p.instances {
+		if prov, ok := findProvider(inst.Type); ok && !inst.Disabled {
+			jobs = append(jobs, job{inst.plugin, prov, inst.plugin.getConfiguration()})
+		}
+	}
+	p.instancesLock.Unlock()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, warmUpWorkers)
+	for _, j := range jobs {
+		select {
+		case <-p.bg.done():
+			wg.Wait()
+			return
+		case <-time.After(warmUpStagger):
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		if !p.bg.Go(p, func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			j.plugin.fetchProvider(j.prov, j.config)
+		}) {
+			wg.Done()
+			break
+		}
+	}
+	wg.Wait()
+	if !p.bg.stopping() {
+		p.snapshot.store(p.gatherStatuses(true), time.Now())
+	}
+	p.API.LogDebug("Provider cache warmed up", "providers", len(jobs), "duration", time.Since(start).String())
+}
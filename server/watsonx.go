@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== IBM watsonx.ai (IBM Cloud API key) =====
+
+// watsonxResourceIDs are the catalog IDs of watsonx.ai Runtime, formerly Watson Machine
+// Learning, whose plans meter inference.
+var watsonxResourceIDs = []string{"51c53b72-918f-4869-b834-2d99eb28422a", "pm-20"}
+
+// watsonxPlanLimits are the monthly resource units included in each plan; pay-as-you-go
+// plans have none. One resource unit is 1,000 tokens.
+var watsonxPlanLimits = map[string]float64{"lite": 50}
+
+// WatsonxInfo is the month's watsonx.ai Runtime consumption from the account's usage report:
+// resource units (tokens) and capacity unit hours against the plan's allowance, and cost.
+type WatsonxInfo struct {
+	Plan              string          `json:"plan,omitempty"`
+	ResourceUnits     float64         `json:"resourceUnits"`
+	ResourceUnitLimit float64         `json:"resourceUnitLimit,omitempty"`
+	CapacityHours     float64         `json:"capacityHours"`
+	TotalCost         float64         `json:"totalCost"`
+	Budget            float64         `json:"budget,omitempty"`
+	Currency          string          `json:"currency"`
+	Period            string          `json:"period"`
+	CycleEnd          string          `json:"cycleEnd"`
+	DaysUntilReset    int             `json:"daysUntilReset"`
+	Metrics           []WatsonxMetric `json:"metrics,omitempty"`
+}
+
+// WatsonxMetric is one metered quantity of a plan, e.g. RESOURCE_UNITS.
+type WatsonxMetric struct {
+	Metric   string  `json:"metric"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Cost     float64 `json:"cost"`
+}
+
+// percent is the share of the plan's resource units consumed, if it has a limit.
+func (i WatsonxInfo) percent() (float64, bool) {
+	if i.ResourceUnitLimit <= 0 {
+		return 0, false
+	}
+	return i.ResourceUnits / i.ResourceUnitLimit * 100, true
+}
+
+func (i WatsonxInfo) summary(locale string) string {
+	if i.ResourceUnitLimit > 0 {
+		return translate(locale, "summary.watsonx_limit", formatCount(i.ResourceUnits), formatCount(i.ResourceUnitLimit), formatMoney(i.TotalCost, i.Currency, 2), i.Period)
+	}
+	text := translate(locale, "summary.watsonx", formatCount(i.ResourceUnits), formatMoney(i.TotalCost, i.Currency, 2), i.Period)
+	if i.Budget > 0 {
+		text += ", " + translate(locale, "summary.cost_of", formatMoney(i.TotalCost, i.Currency, 2), formatMoney(i.Budget, i.Currency, 0))
+	}
+	return text
+}
+
+func (i WatsonxInfo) compact(name, locale string) string {
+	if pct, ok := i.percent(); ok {
+		return fmt.Sprintf("%s %.0f%%", name, pct)
+	}
+	return fmt.Sprintf("%s %s", name, formatMoney(i.TotalCost, i.Currency, 0))
+}
+
+func (i WatsonxInfo) usagePercent() (float64, bool) {
+	if pct, ok := i.percent(); ok {
+		return pct, true
+	}
+	if i.Budget > 0 {
+		return i.TotalCost / i.Budget * 100, true
+	}
+	return 0, false
+}
+
+func (i WatsonxInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(i.CycleEnd)}}
+}
+
+// watsonxUsageResponse is GET /v4/accounts/{account_id}/usage/{billing_month}.
+type watsonxUsageResponse struct {
+	AccountID    string `json:"account_id" schema:"required"`
+	Month        string `json:"month"`
+	CurrencyCode string `json:"currency_code"`
+	Resources    []struct {
+		ResourceID   string `json:"resource_id" schema:"required"`
+		ResourceName string `json:"resource_name"`
+		Plans        []struct {
+			PlanID   string `json:"plan_id" schema:"required"`
+			PlanName string `json:"plan_name"`
+			Usage    []struct {
+				Metric     string    `json:"metric" schema:"required"`
+				MetricName string    `json:"metric_name"`
+				Quantity   flexFloat `json:"quantity" schema:"required"`
+				Unit       string    `json:"unit"`
+				UnitName   string    `json:"unit_name"`
+				Cost       flexFloat `json:"cost"`
+				RatedCost  flexFloat `json:"rated_cost"`
+			} `json:"usage" schema:"required"`
+		} `json:"plans" schema:"required"`
+	} `json:"resources" schema:"required"`
+}
+
+func (p *Plugin) getWatsonxStatus(config *Configuration) ServiceStatus {
+	const id, name = "watsonx", "IBM watsonx.ai"
+	if config.WatsonxApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+	account := strings.TrimSpace(config.WatsonxAccountId)
+	if account == "" {
+		return errorStatus(id, name, "error.ibm_account_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 30*time.Second)
+	token, err := p.ibmIAMToken(client, config)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	resp, err := client.Do(newWatsonxUsageRequest(token, account, monthStart))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var usage watsonxUsageResponse
+	drift, err := decodeResponse(body, &usage)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	info := WatsonxInfo{
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+	if usage.CurrencyCode != "" {
+		info.Currency = strings.ToUpper(usage.CurrencyCode)
+	}
+	for _, resource := range usage.Resources {
+		if !isWatsonxResource(resource.ResourceID) {
+			continue
+		}
+		for _, plan := range resource.Plans {
+			if info.Plan == "" {
+				info.Plan = plan.PlanName
+			}
+			for _, u := range plan.Usage {
+				metric := WatsonxMetric{
+					Metric: u.Metric, Name: u.MetricName, Quantity: float64(u.Quantity),
+					Unit: u.UnitName, Cost: float64(u.RatedCost),
+				}
+				if metric.Cost == 0 {
+					metric.Cost = float64(u.Cost)
+				}
+				if metric.Name == "" {
+					metric.Name = u.Metric
+				}
+				info.TotalCost += metric.Cost
+				switch upper := strings.ToUpper(u.Metric); {
+				case strings.Contains(upper, "RESOURCE_UNIT"):
+					info.ResourceUnits += metric.Quantity
+				case strings.Contains(upper, "CAPACITY_UNIT_HOUR"):
+					info.CapacityHours += metric.Quantity
+				}
+				info.Metrics = append(info.Metrics, metric)
+			}
+		}
+	}
+
+	info.ResourceUnitLimit = config.watsonxUnitLimit(info.Plan)
+	info.Budget = p.budgetIn(config, id, config.WatsonxMonthlyBudget, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: watsonxStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+func isWatsonxResource(resourceID string) bool {
+	for _, id := range watsonxResourceIDs {
+		if strings.EqualFold(resourceID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// watsonxUnitLimit is the configured monthly resource unit allowance, or the plan's own.
+func (c *Configuration) watsonxUnitLimit(plan string) float64 {
+	if limit, err := strconv.ParseFloat(strings.TrimSpace(c.WatsonxUnitLimit), 64); err == nil && limit > 0 {
+		return limit
+	}
+	return watsonxPlanLimits[strings.ToLower(strings.TrimSpace(plan))]
+}
+
+// watsonxStatus is an error once the plan's resource units are used up, after which Lite
+// instances stop serving until the month resets, and otherwise follows the budget.
+func watsonxStatus(info WatsonxInfo, config *Configuration) string {
+	if pct, ok := info.percent(); ok {
+		switch {
+		case pct >= 100:
+			return "error"
+		case pct > config.warningPercent(80):
+			return "warning"
+		}
+	}
+	return budgetStatus(info.TotalCost, info.Budget, config)
+}
+
+// ibmIAMToken exchanges the IBM Cloud API key for an IAM access token, which is valid for an hour.
+func (p *Plugin) ibmIAMToken(client *http.Client, config *Configuration) (string, error) {
+	resp, err := client.Do(newIBMTokenRequest(config))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		AccessToken  string `json:"access_token"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	if resp.StatusCode != 200 || token.AccessToken == "" {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, token.ErrorMessage)
+	}
+	return token.AccessToken, nil
+}
+
+func newIBMTokenRequest(config *Configuration) *http.Request {
+	form := neturl.Values{
+		"grant_type": {"urn:ibm:params:oauth:grant-type:apikey"},
+		"apikey":     {strings.TrimSpace(config.WatsonxApiKey)},
+	}
+	req, _ := http.NewRequest("POST", "https://iam.cloud.ibm.com/identity/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+func newWatsonxUsageRequest(token, account string, monthStart time.Time) *http.Request {
+	url := "https://billing.cloud.ibm.com/v4/accounts/" + neturl.PathEscape(account) + "/usage/" + monthStart.Format("2006-01")
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
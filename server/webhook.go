@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// ===== Templated outgoing webhook =====
+
+var webhookFuncs = template.FuncMap{
+	// json renders any value as a JSON literal, e.g. {{json .Summary}}
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// webhookTarget is one URL of the Outgoing Webhook URLs, with the payload it takes: the
+// event as JSON, or the templated payload, or a Slack-compatible {"text": ...} message.
+type webhookTarget struct {
+	url   string
+	slack bool
+}
+
+// webhookTargets parses the webhook URLs, one per line or comma-separated. A URL may be
+// preceded by its format, e.g. "slack https://hooks.slack.com/services/...".
+func webhookTargets(list string) []webhookTarget {
+	var targets []webhookTarget
+	for _, line := range strings.FieldsFunc(list, func(r rune) bool { return r == '\n' || r == ',' }) {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1:
+			targets = append(targets, webhookTarget{url: fields[0]})
+		case len(fields) == 2 && (fields[0] == "slack" || fields[0] == "json"):
+			targets = append(targets, webhookTarget{url: fields[1], slack: fields[0] == "slack"})
+		}
+	}
+	return targets
+}
+
+// sendWebhooks posts each subscribed event to every configured webhook URL.
+// Without a template the event itself is sent as JSON.
+func (p *Plugin) sendWebhooks(config *Configuration, events []StatusEvent) {
+	var tmpl *template.Template
+	if strings.TrimSpace(config.WebhookTemplate) != "" {
+		var err error
+		tmpl, err = template.New("webhook").Funcs(webhookFuncs).Parse(config.WebhookTemplate)
+		if err != nil {
+			p.API.LogWarn("Invalid webhook template", "error", err.Error())
+			return
+		}
+	}
+
+	client := p.outboundClient(10 * time.Second)
+	locale := p.serverLocale()
+	for _, ev := range events {
+		if !webhookSubscribed(config.WebhookEvents, ev.Type) {
+			continue
+		}
+		for i, target := range webhookTargets(config.WebhookUrl) {
+			payload, err := webhookPayload(target, tmpl, ev, locale)
+			if err == nil {
+				err = p.postWebhook(client, target.url, config.WebhookSecret, payload)
+			}
+			if err != nil {
+				// The URL may carry a token, as Slack's and Zapier's do, so only its host is logged
+				p.API.LogWarn("Failed to send webhook", "target", i+1, "host", target.host(), "provider", ev.Provider, "event", ev.Type, "error", webhookError(err))
+			}
+		}
+	}
+}
+
+// webhookPayload renders the event for the target: for Slack, a one-line message like
+// the Grafana annotations.
+func webhookPayload(target webhookTarget, tmpl *template.Template, ev StatusEvent, locale string) ([]byte, error) {
+	switch {
+	case target.slack:
+		text := translate(locale, "alert.transition", ev.Name, ev.From, ev.To, ev.Summary)
+		if ev.Type == EventReset {
+			text = translate(locale, "alert.reset", ev.Name, ev.Summary)
+		}
+		return json.Marshal(map[string]string{"text": statusEmoji(ev.To) + " " + text})
+	case tmpl != nil:
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, ev); err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(ev)
+}
+
+// webhookSubscribed reports whether eventType is in the comma-separated list (empty means all).
+func webhookSubscribed(list, eventType string) bool {
+	if strings.TrimSpace(list) == "" {
+		return true
+	}
+	for _, e := range strings.Split(list, ",") {
+		if strings.TrimSpace(e) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// signWebhook returns the signature of a webhook payload: the hex HMAC-SHA256, keyed with
+// the shared secret, of "<timestamp>.<nonce>.<body>". Receivers recompute it to check the
+// payload came from this server, and reject old timestamps or repeated nonces as replays.
+func signWebhook(secret, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + nonce + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// host is the target's host, which is safe to log unlike the whole URL.
+func (t webhookTarget) host() string {
+	if u, err := neturl.Parse(t.url); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+// webhookError is the error without the URL that net/http errors repeat.
+func webhookError(err error) string {
+	var urlErr *neturl.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Op + ": " + urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// postWebhook posts the payload, retrying network errors, rate limiting and server errors
+// until the plugin stops. Every attempt is signed with a fresh timestamp and nonce, so
+// receivers that reject repeated nonces accept the retry.
+func (p *Plugin) postWebhook(client *http.Client, url, secret string, payload []byte) error {
+	for n := 0; ; n++ {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", p.userAgent())
+		if secret != "" {
+			timestamp, nonce := strconv.FormatInt(time.Now().Unix(), 10), model.NewId()
+			req.Header.Set("X-AI-Limits-Timestamp", timestamp)
+			req.Header.Set("X-AI-Limits-Nonce", nonce)
+			req.Header.Set("X-AI-Limits-Signature", signWebhook(secret, timestamp, nonce, payload))
+		}
+
+		resp, err := client.Do(req)
+		if n < retryAttempts-1 && transient(resp, err) {
+			delay := retryDelay(n, resp, time.Now())
+			if resp != nil {
+				resp.Body.Close()
+			}
+			select {
+			case <-time.After(min(delay, retryMaxWait)):
+			case <-p.bg.done():
+				return errShuttingDown
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ===== Windsurf / Codeium (team, service key) =====
+
+// WindsurfUsageInfo is a Windsurf team's prompt credit pool and consumption in the current
+// billing cycle. The pool is the per-seat allowance times seats; purchased add-on credits
+// are drawn once it runs out.
+type WindsurfUsageInfo struct {
+	Seats          float64               `json:"seats"`
+	CreditsPerSeat float64               `json:"creditsPerSeat"`
+	CreditsTotal   float64               `json:"creditsTotal"`
+	CreditsUsed    float64               `json:"creditsUsed"`
+	CreditsRemain  float64               `json:"creditsRemain"`
+	AddOnAvailable float64               `json:"addOnAvailable"`
+	AddOnUsed      float64               `json:"addOnUsed"`
+	CycleStart     string                `json:"cycleStart"`
+	CycleEnd       string                `json:"cycleEnd"`
+	TopMembers     []WindsurfMemberUsage `json:"topMembers,omitempty"`
+}
+
+func (w WindsurfUsageInfo) summary(locale string) string {
+	text := translate(locale, "summary.windsurf", formatCount(w.CreditsRemain), formatCount(w.CreditsTotal), formatCount(w.Seats))
+	if w.AddOnAvailable > 0 {
+		text += " " + translate(locale, "summary.windsurf_addon", formatCount(w.AddOnAvailable))
+	}
+	return text
+}
+
+func (w WindsurfUsageInfo) compact(name, locale string) string {
+	return translate(locale, "compact.credits_left", name, formatCount(w.CreditsRemain))
+}
+
+func (w WindsurfUsageInfo) usagePercent() (float64, bool) {
+	if w.CreditsTotal > 0 {
+		return w.CreditsUsed / w.CreditsTotal * 100, true
+	}
+	return 0, false
+}
+
+func (w WindsurfUsageInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "billing_cycle", At: parseTime(w.CycleEnd)}}
+}
+
+// WindsurfMemberUsage is one seat's prompt credit consumption this cycle.
+type WindsurfMemberUsage struct {
+	Name    string  `json:"name"`
+	Credits float64 `json:"credits"`
+}
+
+// windsurfBalanceResponse is POST /api/v1/GetTeamCreditBalance.
+type windsurfBalanceResponse struct {
+	PromptCreditsPerSeat  flexFloat `json:"promptCreditsPerSeat" schema:"required"`
+	NumSeats              flexFloat `json:"numSeats" schema:"required"`
+	AddOnCreditsAvailable flexFloat `json:"addOnCreditsAvailable"`
+	AddOnCreditsUsed      flexFloat `json:"addOnCreditsUsed"`
+	BillingCycleStart     string    `json:"billingCycleStart" schema:"required"`
+	BillingCycleEnd       string    `json:"billingCycleEnd" schema:"required"`
+}
+
+// windsurfUsersResponse is POST /api/v1/UserPageAnalytics.
+type windsurfUsersResponse struct {
+	UserTableStats []struct {
+		Name              string    `json:"name"`
+		Email             string    `json:"email"`
+		LastUpdateTime    string    `json:"lastUpdateTime"`
+		APIKey            string    `json:"apiKey"`
+		ActiveDays        flexFloat `json:"activeDays"`
+		PromptCreditsUsed flexFloat `json:"promptCreditsUsed" schema:"required"`
+	} `json:"userTableStats" schema:"required"`
+}
+
+func (p *Plugin) getWindsurfStatus(config *Configuration) ServiceStatus {
+	const id, name = "windsurf", "Windsurf"
+	if config.WindsurfServiceKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	body, err := p.windsurfPost(client, newWindsurfRequest(config, "GetTeamCreditBalance", nil))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	var balance windsurfBalanceResponse
+	drift, err := decodeResponse(body, &balance)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+
+	info := WindsurfUsageInfo{
+		Seats:          float64(balance.NumSeats),
+		CreditsPerSeat: float64(balance.PromptCreditsPerSeat),
+		AddOnAvailable: float64(balance.AddOnCreditsAvailable),
+		AddOnUsed:      float64(balance.AddOnCreditsUsed),
+		CycleStart:     balance.BillingCycleStart,
+		CycleEnd:       balance.BillingCycleEnd,
+	}
+	info.CreditsTotal = info.Seats * info.CreditsPerSeat
+
+	// Per-seat consumption for the cycle; the team pool is the sum of it
+	start, end := parseTime(balance.BillingCycleStart), time.Now().UTC()
+	if start.IsZero() {
+		start, _ = billingCycle(end, 1)
+	}
+	body, err = p.windsurfPost(client, newWindsurfRequest(config, "UserPageAnalytics", map[string]any{
+		"start_timestamp": start.Format(time.RFC3339),
+		"end_timestamp":   end.Format(time.RFC3339),
+	}))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	var users windsurfUsersResponse
+	d, err := decodeResponse(body, &users)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	drift.merge(d)
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	var members []WindsurfMemberUsage
+	for _, u := range users.UserTableStats {
+		credits := float64(u.PromptCreditsUsed)
+		info.CreditsUsed += credits
+		memberName := u.Name
+		if memberName == "" {
+			memberName = u.Email
+		}
+		if credits > 0 {
+			members = append(members, WindsurfMemberUsage{Name: memberName, Credits: credits})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Credits > members[j].Credits })
+	info.TopMembers = members[:min(len(members), 5)]
+	info.CreditsRemain = max(info.CreditsTotal-info.CreditsUsed, 0)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: windsurfStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// windsurfStatus is an error once the seat pool and add-on credits are both used up.
+func windsurfStatus(info WindsurfUsageInfo, config *Configuration) string {
+	if info.CreditsTotal <= 0 {
+		return "ok"
+	}
+	switch {
+	case info.CreditsRemain <= 0 && info.AddOnAvailable <= 0:
+		return "error"
+	case info.CreditsUsed/info.CreditsTotal*100 > config.warningPercent(90):
+		return "warning"
+	}
+	return "ok"
+}
+
+// windsurfPost sends a request and returns the body of a successful response.
+func (p *Plugin) windsurfPost(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+// newWindsurfRequest builds a team API request. The service key is sent in the body.
+func newWindsurfRequest(config *Configuration, method string, params map[string]any) *http.Request {
+	payload := map[string]any{"service_key": config.WindsurfServiceKey}
+	for k, v := range params {
+		payload[k] = v
+	}
+	data, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", "https://server.codeium.com/api/v1/"+method, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== xAI (API key, optional management key) =====
+
+// XaiUsageInfo is the team's prepaid credit and this month's invoiced spend from the
+// management API. The rate-limit tier isn't exposed by the API and comes from the configuration.
+type XaiUsageInfo struct {
+	TeamID         string  `json:"teamId"`
+	KeyName        string  `json:"keyName"`
+	HasBilling     bool    `json:"hasBilling"`
+	CreditBalance  float64 `json:"creditBalance"`
+	MonthlySpend   float64 `json:"monthlySpend"`
+	SpendingLimit  float64 `json:"spendingLimit,omitempty"`
+	LowBalance     float64 `json:"lowBalance,omitempty"`
+	Currency       string  `json:"currency"`
+	Period         string  `json:"period"`
+	CycleEnd       string  `json:"cycleEnd"`
+	DaysUntilReset int     `json:"daysUntilReset"`
+	Tier           string  `json:"tier,omitempty"`
+}
+
+func (x XaiUsageInfo) summary(locale string) string {
+	var parts []string
+	if x.HasBilling {
+		if x.SpendingLimit > 0 {
+			parts = append(parts, translate(locale, "summary.xai_spend_limit", formatMoney(x.MonthlySpend, x.Currency, 2), formatMoney(x.SpendingLimit, x.Currency, 0), x.Period))
+		} else {
+			parts = append(parts, translate(locale, "summary.spent", formatMoney(x.MonthlySpend, x.Currency, 2), x.Period))
+		}
+		parts = append(parts, translate(locale, "summary.credit_balance", formatMoney(x.CreditBalance, x.Currency, 2)))
+	}
+	if x.Tier != "" {
+		parts = append(parts, translate(locale, "summary.xai_tier", x.Tier))
+	}
+	if len(parts) == 0 {
+		return translate(locale, "summary.connected")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (x XaiUsageInfo) compact(name, locale string) string {
+	if x.HasBilling {
+		return translate(locale, "compact.balance_left", name, formatMoney(x.CreditBalance, x.Currency, 0))
+	}
+	return name
+}
+
+func (x XaiUsageInfo) usagePercent() (float64, bool) {
+	if x.HasBilling && x.SpendingLimit > 0 {
+		return x.MonthlySpend / x.SpendingLimit * 100, true
+	}
+	return 0, false
+}
+
+func (x XaiUsageInfo) resets(time.Time) []ResetInfo {
+	if x.HasBilling {
+		return []ResetInfo{{Kind: "monthly_billing", At: parseTime(x.CycleEnd)}}
+	}
+	return nil
+}
+
+// xaiKeyResponse is /v1/api-key, the metadata of the key making the request.
+type xaiKeyResponse struct {
+	RedactedAPIKey string   `json:"redacted_api_key"`
+	UserID         string   `json:"user_id"`
+	Name           string   `json:"name"`
+	CreateTime     string   `json:"create_time"`
+	ModifyTime     string   `json:"modify_time"`
+	ModifiedBy     string   `json:"modified_by"`
+	TeamID         string   `json:"team_id" schema:"required"`
+	ACLs           []string `json:"acls"`
+	APIKeyID       string   `json:"api_key_id"`
+	TeamBlocked    bool     `json:"team_blocked"`
+	APIKeyBlocked  bool     `json:"api_key_blocked"`
+	APIKeyDisabled bool     `json:"api_key_disabled"`
+}
+
+// xaiAmount is a management API amount in USD cents.
+type xaiAmount struct {
+	Val flexFloat `json:"val" schema:"required"`
+}
+
+// xaiBalanceResponse is the team's prepaid credit ledger. The total is negative while
+// credit remains.
+type xaiBalanceResponse struct {
+	Changes []any     `json:"changes"`
+	Total   xaiAmount `json:"total" schema:"required"`
+}
+
+// xaiInvoiceResponse is the preview of the current month's postpaid invoice.
+type xaiInvoiceResponse struct {
+	CoreInvoice struct {
+		Lines           []any     `json:"lines"`
+		TotalWithCorr   xaiAmount `json:"totalWithCorr" schema:"required"`
+		AmountBeforeVat flexFloat `json:"amountBeforeVat"`
+		AmountAfterVat  flexFloat `json:"amountAfterVat"`
+	} `json:"coreInvoice" schema:"required"`
+	EffectiveSpendingLimit flexFloat `json:"effectiveSpendingLimit"`
+	BillingCycle           struct {
+		Year  int `json:"year"`
+		Month int `json:"month"`
+	} `json:"billingCycle"`
+}
+
+func (p *Plugin) getXaiStatus(config *Configuration) ServiceStatus {
+	const id, name = "xai", "xAI"
+	if config.XaiApiKey == "" {
+		return errorStatus(id, name, "error.api_key_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 15*time.Second)
+	body, err := p.xaiGet(client, newXaiKeyRequest(config))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	var key xaiKeyResponse
+	drift, err := decodeResponse(body, &key)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if key.TeamBlocked || key.APIKeyBlocked || key.APIKeyDisabled {
+		return errorStatus(id, name, "error.xai_key_blocked")
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := XaiUsageInfo{
+		TeamID:         key.TeamID,
+		KeyName:        key.Name,
+		Currency:       baseCurrency,
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+		Tier:           strings.TrimSpace(config.XaiRateLimitTier),
+	}
+
+	if config.XaiManagementKey != "" {
+		body, err := p.xaiGet(client, newXaiManagementRequest(config, key.TeamID, "prepaid/balance"))
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		var balance xaiBalanceResponse
+		d, err := decodeResponse(body, &balance)
+		if err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		drift.merge(d)
+
+		body, err = p.xaiGet(client, newXaiManagementRequest(config, key.TeamID, "postpaid/invoice/preview"))
+		if err != nil {
+			return errorStatus(id, name, "error.api", err.Error())
+		}
+		var invoice xaiInvoiceResponse
+		d, err = decodeResponse(body, &invoice)
+		if err != nil {
+			return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+		}
+		drift.merge(d)
+
+		info.HasBilling = true
+		info.CreditBalance = -float64(balance.Total.Val) / 100
+		info.MonthlySpend = float64(invoice.CoreInvoice.TotalWithCorr.Val) / 100
+		info.SpendingLimit = float64(invoice.EffectiveSpendingLimit) / 100
+		info.LowBalance = p.budgetIn(config, id, config.XaiLowBalance, info.Currency)
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: xaiStatus(info, config),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// xaiStatus is the spending limit status, with a warning once prepaid credit runs low.
+func xaiStatus(info XaiUsageInfo, config *Configuration) string {
+	if !info.HasBilling {
+		return "ok"
+	}
+	status := budgetStatus(info.MonthlySpend, info.SpendingLimit, config)
+	if status == "ok" && info.LowBalance > 0 && info.CreditBalance < info.LowBalance {
+		return "warning"
+	}
+	return status
+}
+
+// xaiGet sends a request and returns the body of a successful response.
+func (p *Plugin) xaiGet(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	return body, nil
+}
+
+func newXaiKeyRequest(config *Configuration) *http.Request {
+	req, _ := http.NewRequest("GET", "https://api.x.ai/v1/api-key", nil)
+	req.Header.Set("Authorization", "Bearer "+config.XaiApiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
+
+// newXaiManagementRequest builds a billing request for the team, e.g. path "prepaid/balance".
+func newXaiManagementRequest(config *Configuration, teamID, path string) *http.Request {
+	req, _ := http.NewRequest("GET", "https://management-api.x.ai/v1/billing/teams/"+neturl.PathEscape(teamID)+"/"+path, nil)
+	req.Header.Set("Authorization", "Bearer "+config.XaiManagementKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
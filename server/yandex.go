@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// ===== Yandex Cloud / Foundation Models (service account authorized key) =====
+
+// YandexInfo is the state of the billing account that pays for YandexGPT and the other
+// Foundation Models. The Billing API has no consumption endpoint, so the month's spend is
+// estimated from how far the balance has fallen since the first refresh of the month,
+// net of top-ups, and covers every service on the account.
+type YandexInfo struct {
+	AccountName    string  `json:"accountName"`
+	Active         bool    `json:"active"`
+	Balance        float64 `json:"balance"`
+	LowBalance     float64 `json:"lowBalance,omitempty"`
+	Currency       string  `json:"currency"`
+	MonthlySpend   float64 `json:"monthlySpend"`
+	TrackedSince   string  `json:"trackedSince"`
+	Period         string  `json:"period"`
+	CycleEnd       string  `json:"cycleEnd"`
+	DaysUntilReset int     `json:"daysUntilReset"`
+}
+
+func (y YandexInfo) summary(locale string) string {
+	if !y.Active {
+		return translate(locale, "summary.yandex_suspended", formatMoney(y.Balance, y.Currency, 2))
+	}
+	return translate(locale, "summary.yandex", formatMoney(y.Balance, y.Currency, 2), formatMoney(y.MonthlySpend, y.Currency, 2), y.Period)
+}
+
+func (y YandexInfo) compact(name, locale string) string {
+	return translate(locale, "compact.balance_left", name, formatMoney(y.Balance, y.Currency, 0))
+}
+
+func (y YandexInfo) resets(time.Time) []ResetInfo {
+	return []ResetInfo{{Kind: "monthly_billing", At: parseTime(y.CycleEnd)}}
+}
+
+// yandexBillingAccountResponse is GET /billing/v1/billingAccounts/{id}.
+type yandexBillingAccountResponse struct {
+	ID       string    `json:"id" schema:"required"`
+	Name     string    `json:"name"`
+	Currency string    `json:"currency" schema:"required"`
+	Active   bool      `json:"active" schema:"required"`
+	Balance  flexFloat `json:"balance" schema:"required"`
+}
+
+// yandexMonth is the balance history the month's spend is estimated from, stored per month.
+type yandexMonth struct {
+	Month        string  `json:"month"`
+	FirstBalance float64 `json:"firstBalance"`
+	FirstSeen    string  `json:"firstSeen"`
+	LastBalance  float64 `json:"lastBalance"`
+	TopUps       float64 `json:"topUps"`
+}
+
+func yandexMonthKey(account, month string) string {
+	return "yandex_" + account + "_" + month
+}
+
+func (p *Plugin) getYandexStatus(config *Configuration) ServiceStatus {
+	const id, name = "yandex", "Yandex Foundation Models"
+	if strings.TrimSpace(config.YandexServiceAccountKey) == "" {
+		return errorStatus(id, name, "error.yandex_key_missing")
+	}
+	key, err := parseYandexAuthorizedKey(config.YandexServiceAccountKey)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	account := strings.TrimSpace(config.YandexBillingAccountId)
+	if account == "" {
+		return errorStatus(id, name, "error.yandex_account_missing")
+	}
+
+	if cached, ok := p.getCached(id); ok {
+		return cached.(ServiceStatus)
+	}
+
+	client := p.providerClient(id, 20*time.Second)
+	token, err := p.yandexIAMToken(client, key)
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	resp, err := client.Do(newYandexBillingRequest(token, account))
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	body, err := p.readResponse(resp)
+	resp.Body.Close()
+	if err != nil {
+		return errorStatus(id, name, "error.api", err.Error())
+	}
+	if resp.StatusCode != 200 {
+		return errorStatus(id, name, "error.http", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	var billing yandexBillingAccountResponse
+	drift, err := decodeResponse(body, &billing)
+	if err != nil {
+		return errorStatus(id, name, "error.parse", err.Error(), string(body[:min(len(body), 200)]))
+	}
+	if err := p.checkSchema(id, drift); err != nil {
+		return errorStatus(id, name, "error.response_format", err.Error())
+	}
+
+	now := time.Now().UTC()
+	monthStart, monthEnd := billingCycle(now, 1)
+	info := YandexInfo{
+		AccountName:    billing.Name,
+		Active:         billing.Active,
+		Balance:        float64(billing.Balance),
+		Currency:       strings.ToUpper(billing.Currency),
+		Period:         cyclePeriod(monthStart, monthEnd),
+		CycleEnd:       monthEnd.Format(time.RFC3339),
+		DaysUntilReset: int(monthEnd.Sub(now).Hours() / 24),
+	}
+
+	month := p.trackYandexBalance(account, monthStart.Format("2006-01"), info.Balance, now)
+	info.MonthlySpend = max(month.FirstBalance+month.TopUps-info.Balance, 0)
+	info.TrackedSince = month.FirstSeen
+	info.LowBalance = p.budgetIn(config, id, config.YandexLowBalance, info.Currency)
+
+	result := ServiceStatus{
+		ID: id, Name: name, Enabled: true, Status: yandexStatus(info),
+		Data: info, CachedAt: time.Now().Unix(),
+	}
+	p.setCache(id, result)
+	return result
+}
+
+// trackYandexBalance records the balance in the month's history. A rise in the balance is
+// counted as a top-up so it doesn't hide the spend before it.
+func (p *Plugin) trackYandexBalance(account, monthKey string, balance float64, now time.Time) yandexMonth {
+	month := yandexMonth{Month: monthKey, FirstBalance: balance, FirstSeen: now.Format(time.RFC3339), LastBalance: balance}
+	if data, appErr := p.API.KVGet(yandexMonthKey(account, monthKey)); appErr == nil && data != nil {
+		json.Unmarshal(data, &month)
+	}
+	if balance > month.LastBalance {
+		month.TopUps += balance - month.LastBalance
+	}
+	month.LastBalance = balance
+
+	data, _ := json.Marshal(month)
+	if appErr := p.API.KVSet(yandexMonthKey(account, monthKey), data); appErr != nil {
+		p.API.LogWarn("Failed to store Yandex Cloud balance", "month", monthKey, "error", appErr.Error())
+	}
+	return month
+}
+
+// yandexStatus is an error when the billing account is suspended, which stops API calls,
+// and a warning below the configured low balance.
+func yandexStatus(info YandexInfo) string {
+	switch {
+	case !info.Active:
+		return "error"
+	case info.LowBalance > 0 && info.Balance < info.LowBalance:
+		return "warning"
+	}
+	return "ok"
+}
+
+func newYandexBillingRequest(token, account string) *http.Request {
+	req, _ := http.NewRequest("GET", "https://billing.api.cloud.yandex.net/billing/v1/billingAccounts/"+neturl.PathEscape(account), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req
+}
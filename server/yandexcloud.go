@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ===== Yandex Cloud service account authentication =====
+
+const yandexIAMTokenURL = "https://iam.api.cloud.yandex.net/iam/v1/tokens"
+
+// yandexAuthorizedKey is the part of a service account authorized key file (from
+// `yc iam key create`) needed to get IAM tokens.
+type yandexAuthorizedKey struct {
+	ID               string `json:"id"`
+	ServiceAccountID string `json:"service_account_id"`
+	PrivateKey       string `json:"private_key"`
+}
+
+func parseYandexAuthorizedKey(data string) (yandexAuthorizedKey, error) {
+	var key yandexAuthorizedKey
+	if err := json.Unmarshal([]byte(data), &key); err != nil {
+		return key, fmt.Errorf("invalid authorized key: %w", err)
+	}
+	if key.ID == "" || key.ServiceAccountID == "" || key.PrivateKey == "" {
+		return key, errors.New("authorized key is missing id, service_account_id or private_key")
+	}
+	return key, nil
+}
+
+// yandexIAMToken exchanges a signed JWT for an IAM token, which is valid for up to 12 hours.
+func (p *Plugin) yandexIAMToken(client *http.Client, key yandexAuthorizedKey) (string, error) {
+	req, err := newYandexTokenRequest(key, time.Now())
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := p.readResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var token struct {
+		IAMToken string `json:"iamToken"`
+		Message  string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body[:min(len(body), 200)]))
+	}
+	if resp.StatusCode != 200 || token.IAMToken == "" {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, token.Message)
+	}
+	return token.IAMToken, nil
+}
+
+// newYandexTokenRequest builds the IAM token request for the service account.
+func newYandexTokenRequest(key yandexAuthorizedKey, now time.Time) (*http.Request, error) {
+	jwt, err := key.signedJWT(now)
+	if err != nil {
+		return nil, err
+	}
+	payload, _ := json.Marshal(map[string]string{"jwt": jwt})
+	req, _ := http.NewRequest("POST", yandexIAMTokenURL, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MattermostPlugin/1.0")
+	return req, nil
+}
+
+// signedJWT returns a PS256-signed token request valid for an hour. The key file's
+// private_key has a "PLEASE DO NOT REMOVE THIS LINE!" preamble, which pem.Decode skips.
+func (key yandexAuthorizedKey) signedJWT(now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", errors.New("authorized key private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorized key private key: %w", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("authorized key private key is not an RSA key")
+	}
+
+	encode := func(v any) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := encode(map[string]string{"alg": "PS256", "typ": "JWT", "kid": key.ID}) + "." + encode(map[string]any{
+		"iss": key.ServiceAccountID,
+		"aud": yandexIAMTokenURL,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	})
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,110 @@
+import React, {useEffect, useState} from 'react';
+
+const PLUGIN_ID = 'com.fambear.ai-limits-monitor';
+const OAUTH_URL = `/plugins/${PLUGIN_ID}/api/v1/admin/claude/oauth`;
+
+interface OAuthStatus {
+    connected: boolean;
+    connectedBy?: string;
+    connectedAt?: number;
+    expiresAt?: number;
+}
+
+const request = async (path: string, method: string, body?: any): Promise<any> => {
+    const resp = await fetch(OAUTH_URL + path, {
+        method,
+        headers: {'X-Requested-With': 'XMLHttpRequest', 'Content-Type': 'application/json'},
+        body: body ? JSON.stringify(body) : undefined,
+    });
+    if (!resp.ok) {
+        const data = await resp.json().catch(() => null);
+        throw new Error(data?.message || `HTTP ${resp.status}`);
+    }
+    return resp.json();
+};
+
+// ClaudeOAuthSetting is a custom System Console setting that signs in to claude.ai: it
+// opens the authorization page and exchanges the code claude.ai shows for tokens the
+// plugin keeps and renews.
+const ClaudeOAuthSetting: React.FC<{helpText?: React.ReactNode}> = ({helpText}) => {
+    const [status, setStatus] = useState<OAuthStatus | null>(null);
+    const [started, setStarted] = useState(false);
+    const [code, setCode] = useState('');
+    const [busy, setBusy] = useState(false);
+    const [error, setError] = useState<string | null>(null);
+
+    useEffect(() => {
+        request('', 'GET').then(setStatus).catch((err) => setError(err.message));
+    }, []);
+
+    const run = async (action: () => Promise<void>) => {
+        setBusy(true);
+        setError(null);
+        try {
+            await action();
+        } catch (err: any) {
+            setError(err.message);
+        } finally {
+            setBusy(false);
+        }
+    };
+
+    const handleStart = (e: React.MouseEvent) => {
+        e.preventDefault();
+        run(async () => {
+            const {url} = await request('/start', 'POST');
+            window.open(url, '_blank', 'noopener');
+            setStarted(true);
+        });
+    };
+
+    const handleConnect = (e: React.MouseEvent) => {
+        e.preventDefault();
+        run(async () => {
+            setStatus(await request('/callback', 'POST', {code}));
+            setStarted(false);
+            setCode('');
+        });
+    };
+
+    const handleDisconnect = (e: React.MouseEvent) => {
+        e.preventDefault();
+        run(async () => setStatus(await request('', 'DELETE')));
+    };
+
+    return (
+        <div>
+            {status?.connected && (
+                <div style={{marginBottom: '8px', color: '#3db887'}}>
+                    {'✓'} Connected{status.connectedBy ? ` by @${status.connectedBy}` : ''}
+                    {status.connectedAt ? ` on ${new Date(status.connectedAt * 1000).toLocaleDateString()}` : ''}
+                </div>
+            )}
+            <button className='btn btn-tertiary' onClick={handleStart} disabled={busy}>
+                {status?.connected ? 'Reconnect claude.ai' : 'Sign in with claude.ai'}
+            </button>
+            {status?.connected && (
+                <button className='btn btn-tertiary' onClick={handleDisconnect} disabled={busy} style={{marginLeft: '8px'}}>
+                    {'Disconnect'}
+                </button>
+            )}
+            {started && (
+                <div style={{marginTop: '8px', display: 'flex', gap: '8px'}}>
+                    <input
+                        className='form-control'
+                        placeholder='Paste the code shown by claude.ai'
+                        value={code}
+                        onChange={(e) => setCode(e.target.value)}
+                    />
+                    <button className='btn btn-primary' onClick={handleConnect} disabled={busy || !code.trim()}>
+                        {'Connect'}
+                    </button>
+                </div>
+            )}
+            {error && <div style={{marginTop: '8px', color: '#d24b4e'}}>{error}</div>}
+            {helpText && <div className='help-text'>{helpText}</div>}
+        </div>
+    );
+};
+
+export default ClaudeOAuthSetting;
@@ -10,12 +10,97 @@ interface ServiceData {
     data?: any;
     error?: string;
     cachedAt?: number;
+    resets?: ResetView[];
+    usage?: UsageMetrics;
+    forecast?: Forecast;
+    requestId?: string;
+    credentialExpiresAt?: number;
+    stale?: boolean;
+    staleError?: string;
+    ageSeconds?: number;
+    refreshInSeconds?: number;
+    personal?: boolean;
+    breaker?: BreakerState;
+}
+
+interface BreakerState {
+    state: string;
+    failures: number;
+    openedAt: number;
+    retryAt: number;
+}
+
+interface UsageMetrics {
+    unit: string;
+    used?: number;
+    limit?: number;
+    cost?: number;
+    costLimit?: number;
+    currency?: string;
+    percent?: number;
+}
+
+interface Forecast {
+    unit: string;
+    currency?: string;
+    perDay: number;
+    projected: number;
+    limit?: number;
+    projectedPercent?: number;
+    daysUntilExhausted?: number;
+    exhaustsAt?: string;
+    cycleEnd: string;
+    basis: string;
+    overrun: boolean;
+}
+
+interface CostTotal {
+    amount: number;
+    currency: string;
+    unconverted?: string[];
+}
+
+interface ResetView {
+    kind: string;
+    label: string;
+    at: string;
+    formatted: string;
+    relative: string;
+}
+
+interface BudgetData {
+    id: string;
+    name: string;
+    period: string;
+    amount: number;
+    currency: string;
+    spent: number;
+    percent: number;
+    status: string;
+    sources: {id: string; name: string; amount: number}[];
+    unconverted?: string[];
 }
 
 interface StatusResponse {
     services: ServiceData[];
+    units: string;
+    totalCost?: CostTotal;
+    demo?: boolean;
+    budgets?: BudgetData[];
+}
+
+interface UserPreferences {
+    cardOrder: string[];
+    hiddenProviders: string[];
+    refreshInterval: number;
+    units: string;
 }
 
+// STATUS_UPDATE_EVENT is dispatched on window with the statuses the server pushed.
+export const STATUS_UPDATE_EVENT = 'ailimits:status_update';
+
+const DEFAULT_PREFERENCES: UserPreferences = {cardOrder: [], hiddenProviders: [], refreshInterval: 0, units: ''};
+
 const fetchStatus = async (): Promise<StatusResponse> => {
     const resp = await fetch(`/plugins/${PLUGIN_ID}/api/v1/status`, {
         headers: {'X-Requested-With': 'XMLHttpRequest'},
@@ -33,6 +118,47 @@ const refreshAll = async (): Promise<StatusResponse> => {
     return resp.json();
 };
 
+// refreshProvider refetches one provider's cards, leaving every other provider's cache be.
+const refreshProvider = async (provider: string): Promise<StatusResponse> => {
+    const resp = await fetch(`/plugins/${PLUGIN_ID}/api/v1/refresh/${encodeURIComponent(provider)}`, {
+        method: 'POST',
+        headers: {'X-Requested-With': 'XMLHttpRequest'},
+    });
+    if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
+    return resp.json();
+};
+
+// providerOf returns the provider of a card ID such as "openai" or "claude:work".
+const providerOf = (id: string): string => id.split(/[:_]/)[0];
+
+const fetchPreferences = async (): Promise<UserPreferences> => {
+    const resp = await fetch(`/plugins/${PLUGIN_ID}/api/v1/preferences`, {
+        headers: {'X-Requested-With': 'XMLHttpRequest'},
+    });
+    if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
+    return resp.json();
+};
+
+const savePreferences = async (prefs: UserPreferences): Promise<UserPreferences> => {
+    const resp = await fetch(`/plugins/${PLUGIN_ID}/api/v1/preferences`, {
+        method: 'PUT',
+        headers: {'X-Requested-With': 'XMLHttpRequest', 'Content-Type': 'application/json'},
+        body: JSON.stringify(prefs),
+    });
+    if (!resp.ok) throw new Error(`HTTP ${resp.status}`);
+    return resp.json();
+};
+
+const applyPreferences = (services: ServiceData[], prefs: UserPreferences): ServiceData[] => {
+    const rank = (id: string) => {
+        const i = prefs.cardOrder.indexOf(id);
+        return i === -1 ? prefs.cardOrder.length : i;
+    };
+    return services
+        .filter((s) => !prefs.hiddenProviders.includes(s.id))
+        .sort((a, b) => rank(a.id) - rank(b.id));
+};
+
 const formatNumber = (n: number): string => {
     if (n >= 1_000_000_000) return (n / 1_000_000_000).toFixed(1) + 'B';
     if (n >= 1_000_000) return (n / 1_000_000).toFixed(1) + 'M';
@@ -56,11 +182,32 @@ const formatTimeUntil = (input: number | string): string => {
     return `${mins}m`;
 };
 
+const CURRENCY_SYMBOLS: Record<string, string> = {USD: '$', EUR: '€', GBP: '£', CNY: '¥', JPY: '¥', INR: '₹', RUB: '₽'};
+
+const formatMoney = (amount: number, currency = 'USD', decimals = 2): string => {
+    const symbol = CURRENCY_SYMBOLS[currency.toUpperCase()];
+    return symbol ? `${symbol}${amount.toFixed(decimals)}` : `${amount.toFixed(decimals)} ${currency.toUpperCase()}`;
+};
+
+// Headline in the user's preferred units; null when the provider doesn't report them.
+const formatHeadline = (usage: UsageMetrics | undefined, units: string): string | null => {
+    if (!usage) return null;
+    if (units === 'cost' && usage.cost !== undefined) {
+        return usage.costLimit !== undefined ? `${formatMoney(usage.cost, usage.currency)} of ${formatMoney(usage.costLimit, usage.currency)}` : `${formatMoney(usage.cost, usage.currency)} spent`;
+    }
+    if (units === 'percent' && usage.percent !== undefined) {
+        return `${usage.percent.toFixed(0)}% used`;
+    }
+    return null;
+};
+
 const getStatusColor = (status: string): string => {
     switch (status) {
         case 'ok': return '#3db887';
         case 'warning': return '#f5a623';
-        case 'error': return '#d24b4e';
+        case 'error':
+        case 'reauth':
+        case 'unreachable': return '#d24b4e';
         default: return '#8b8fa7';
     }
 };
@@ -114,11 +261,29 @@ const AugmentCard: React.FC<{data: any}> = ({data}) => {
         <div>
             <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '4px'}}>{data.planName || 'Augment Code'}</div>
             <UsageBar used={data.usageUsed || 0} total={data.usageTotal || 0} label={`Credits: ${formatNumber(data.usageRemaining || 0)} remaining`} />
+            {data.team && (
+                <div style={{marginTop: '4px'}}>
+                    <UsageBar used={data.team.usageUsed || 0} total={data.team.usageTotal || 0} label={`${data.team.name || 'Team'} pool: ${formatNumber(data.team.usageRemaining || 0)} remaining`} />
+                    {(data.team.seats || []).slice(0, 5).map((seat: any) => (
+                        <div key={seat.user} style={{display: 'flex', fontSize: '11px', color: '#8b8fa7'}}>
+                            <span style={{flex: 1, overflow: 'hidden', textOverflow: 'ellipsis'}}>{seat.user}</span>
+                            <span>{formatNumber(seat.usageUsed || 0)}</span>
+                        </div>
+                    ))}
+                </div>
+            )}
             {data.cycleEnd && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Cycle ends: {new Date(data.cycleEnd).toLocaleDateString()}</div>}
         </div>
     );
 };
 
+// "SEARCH_LIMIT" -> "Search"
+const formatLimitType = (type: string): string => {
+    const words = type.replace(/_LIMIT$/, '').toLowerCase().split('_');
+    const label = words.join(' ');
+    return label.charAt(0).toUpperCase() + label.slice(1);
+};
+
 const ZaiCard: React.FC<{data: any}> = ({data}) => {
     if (!data) return null;
     return (
@@ -127,8 +292,42 @@ const ZaiCard: React.FC<{data: any}> = ({data}) => {
                 {data.planName || 'Z.AI'} {data.planStatus ? `(${data.planStatus})` : ''}
             </div>
             <UsageBar used={data.tokensUsed || 0} total={data.tokensTotal || 0} label="Tokens (5h window)" />
+            {data.promptsTotal > 0 && <UsageBar used={data.promptsUsed || 0} total={data.promptsTotal} label="Prompts (5h window)" />}
             <UsageBar used={data.mcpUsed || 0} total={data.mcpTotal || 0} label="MCP Tools" />
+            {(data.otherLimits || []).map((l: any) => (
+                <UsageBar key={l.type} used={l.used || 0} total={l.total || 0} label={formatLimitType(l.type)} />
+            ))}
             {data.nextReset > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Resets in: {formatTimeUntil(data.nextReset)}</div>}
+            {(data.keys || []).length > 0 && (
+                <div style={{marginTop: '6px'}}>
+                    <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '2px'}}>{`Keys (${data.keys.length})`}</div>
+                    {data.keys.map((k: any) => (
+                        <div key={k.name} style={{display: 'flex', gap: '6px', fontSize: '11px', color: k.error ? '#d24b4e' : '#8b8fa7'}}>
+                            <span style={{flex: 1, overflow: 'hidden', textOverflow: 'ellipsis'}}>{k.name}{k.planName ? ` · ${k.planName}` : ''}</span>
+                            <span>
+                                {k.error || (k.promptsTotal > 0 ?
+                                    `${formatNumber(k.promptsUsed || 0)} / ${formatNumber(k.promptsTotal)} prompts` :
+                                    `${formatNumber(k.tokensUsed || 0)} / ${formatNumber(k.tokensTotal || 0)} tokens`)}
+                            </span>
+                        </div>
+                    ))}
+                </div>
+            )}
+        </div>
+    );
+};
+
+const OpenAICostBreakdown: React.FC<{title: string; items?: any[]; count?: number; currency: string; unassigned: string}> = ({title, items, count, currency, unassigned}) => {
+    if (!items || items.length === 0) return null;
+    return (
+        <div>
+            <div style={{fontSize: '12px', color: '#8b8fa7', margin: '6px 0 2px'}}>{title}{count && count > Math.min(items.length, 10) ? ` (top ${Math.min(items.length, 10)} of ${count})` : ''}</div>
+            {items.slice(0, 10).map((item) => (
+                <div key={item.id} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span title={item.id}>{item.name || item.id || unassigned}</span>
+                    <span style={{color: '#8b8fa7'}}>{formatMoney(item.cost, currency)} · {item.percent.toFixed(0)}%</span>
+                </div>
+            ))}
         </div>
     );
 };
@@ -138,20 +337,24 @@ const OpenAICard: React.FC<{data: any}> = ({data}) => {
     const cost = data.totalCost || 0;
     const budget = data.budget || 0;
     const credit = data.creditBalance;
+    const currency = data.currency || 'USD';
     const days = data.daysUntilReset || 0;
     return (
         <div>
             {credit > 0 && (
                 <div style={{fontSize: '12px', marginBottom: '6px'}}>
-                    <span style={{color: '#8b8fa7'}}>Credit balance: </span>
-                    <span style={{fontWeight: 600}}>${credit.toFixed(2)}</span>
+                    <span style={{color: '#8b8fa7'}}>Credit balance{data.creditSource === 'config' ? ' (configured)' : ''}: </span>
+                    <span style={{fontWeight: 600}}>{formatMoney(credit, currency)}</span>
+                    {data.remainingFunds !== undefined && data.creditSource === 'config' && (
+                        <span style={{color: data.remainingFunds <= 0 ? '#d24b4e' : '#8b8fa7'}}> · {formatMoney(data.remainingFunds, currency)} left after this cycle</span>
+                    )}
                 </div>
             )}
             {budget > 0 ? (
                 <div>
                     <div style={{fontSize: '12px', marginBottom: '4px'}}>
                         <span style={{color: '#8b8fa7'}}>{data.period || 'Monthly'} budget: </span>
-                        <span style={{fontWeight: 600}}>${cost.toFixed(2)} / ${budget.toFixed(0)}</span>
+                        <span style={{fontWeight: 600}}>{formatMoney(cost, currency)} / {formatMoney(budget, currency, 0)}</span>
                     </div>
                     <div style={{height: '6px', borderRadius: '3px', backgroundColor: '#e8e8e8', overflow: 'hidden'}}>
                         <div style={{
@@ -162,8 +365,35 @@ const OpenAICard: React.FC<{data: any}> = ({data}) => {
                     </div>
                 </div>
             ) : (
-                <div style={{fontSize: '14px', fontWeight: 600}}>${cost.toFixed(2)}</div>
+                <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(cost, currency)}</div>
+            )}
+            {data.lastMonthCost > 0 && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                    Last month: {formatMoney(data.lastMonthCost, currency)}
+                    {data.lastMonthToDate > 0 && ` · ${cost >= data.lastMonthToDate ? '+' : ''}${((cost / data.lastMonthToDate - 1) * 100).toFixed(0)}% vs. same point`}
+                </div>
+            )}
+            {data.members?.activeUsers > 0 && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                    {data.members.activeUsers} active member{data.members.activeUsers !== 1 ? 's' : ''} · {formatNumber(data.members.tokens)} tokens
+                    {(data.members.topUsers || []).map((u: any) => (
+                        <div key={u.name}>{u.name}: {formatNumber(u.tokens)} tokens ({u.percent.toFixed(0)}%)</div>
+                    ))}
+                </div>
+            )}
+            {(data.keys || []).length > 0 && (
+                <div style={{fontSize: '12px', color: '#8b8fa7', margin: '6px 0 2px'}}>By API key{data.keyCount > data.keys.length ? ` (top ${data.keys.length} of ${data.keyCount})` : ''}</div>
             )}
+            {(data.keys || []).map((k: any) => (
+                <div key={k.id} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span title={k.id}>{k.name || k.id}</span>
+                    <span style={{color: '#8b8fa7'}}>
+                        {formatNumber(k.tokens)} tokens · {k.percent.toFixed(0)}%{k.todayTokens > 0 ? ` · ${formatNumber(k.todayTokens)} today` : ''}
+                    </span>
+                </div>
+            ))}
+            <OpenAICostBreakdown title='By project' items={data.projectCosts} count={data.projectCount} currency={currency} unassigned='No project' />
+            <OpenAICostBreakdown title='By model' items={data.modelCosts} count={data.modelCount} currency={currency} unassigned='Other' />
             <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
                 Resets in {days} day{days !== 1 ? 's' : ''}
             </div>
@@ -171,6 +401,13 @@ const OpenAICard: React.FC<{data: any}> = ({data}) => {
     );
 };
 
+const CLAUDE_WINDOW_LABELS: Record<string, string> = {
+    '5h': '5-hour window',
+    '7d': '7-day window',
+    sonnet: 'Sonnet (weekly)',
+    opus: 'Opus (weekly)',
+};
+
 const ClaudeCard: React.FC<{data: any}> = ({data}) => {
     if (!data || !data.hasData) {
         return <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · No usage data yet</div>;
@@ -178,6 +415,11 @@ const ClaudeCard: React.FC<{data: any}> = ({data}) => {
     return (
         <div>
             <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '4px'}}>claude.ai</div>
+            {data.constrained && data.constrained.length > 0 && (
+                <div style={{fontSize: '12px', color: '#d24b4e', marginBottom: '4px'}}>
+                    Limited by: {data.constrained.map((w: string) => CLAUDE_WINDOW_LABELS[w] || w).join(', ')}
+                </div>
+            )}
             {data.utilization5h !== undefined && (
                 <UtilizationBar utilization={data.utilization5h} label="5-hour window" resetAt={data.reset5h} />
             )}
@@ -194,120 +436,1750 @@ const ClaudeCard: React.FC<{data: any}> = ({data}) => {
     );
 };
 
-const ServiceCard: React.FC<{service: ServiceData}> = ({service}) => {
-    const statusColor = getStatusColor(service.status);
-
-    const renderData = () => {
-        if (service.error) {
-            return <div style={{fontSize: '12px', color: '#d24b4e'}}>{service.error}</div>;
-        }
-        switch (service.id) {
-            case 'augment': return <AugmentCard data={service.data} />;
-            case 'zai': return <ZaiCard data={service.data} />;
-            case 'openai': return <OpenAICard data={service.data} />;
-            case 'claude': return <ClaudeCard data={service.data} />;
-            default: return null;
-        }
-    };
+const AnthropicCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const cost = data.totalCost || 0;
+    const budget = data.budget || 0;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    const tierLimit = data.tierLimit || 0;
+    const workspaces: Array<{id?: string; name: string; cost: number}> = data.workspaces || [];
+    return (
+        <div>
+            {budget > 0 ? (
+                <UtilizationBar utilization={cost / budget * 100} label={`${data.period || 'Monthly'} budget: ${formatMoney(cost, currency)} / ${formatMoney(budget, currency, 0)}`} />
+            ) : (
+                <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(cost, currency)}</div>
+            )}
+            {tierLimit > 0 && (
+                <UtilizationBar utilization={cost / tierLimit * 100} label={`Tier ${data.tier} monthly limit: ${formatMoney(cost, currency)} / ${formatMoney(tierLimit, currency, 0)}`} />
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Tokens: {formatNumber(data.inputTokens || 0)} in · {formatNumber(data.outputTokens || 0)} out · {formatNumber(data.cacheReadTokens || 0)} cache reads
+            </div>
+            {workspaces.length > 1 && workspaces.slice(0, 5).map((w) => (
+                <div key={w.id || 'default'} style={{display: 'flex', justifyContent: 'space-between', fontSize: '11px', color: '#8b8fa7', marginTop: '2px'}}>
+                    <span>{w.name}</span>
+                    <span>{formatMoney(w.cost, currency)}</span>
+                </div>
+            ))}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
 
+const CopilotCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
     return (
-        <div style={{
-            padding: '12px', marginBottom: '8px', borderRadius: '8px',
-            backgroundColor: 'var(--center-channel-bg, #fff)',
-            border: '1px solid var(--center-channel-color-08, #e0e0e0)',
-        }}>
-            <div style={{display: 'flex', alignItems: 'center', gap: '8px', marginBottom: '8px'}}>
-                <div style={{width: '8px', height: '8px', borderRadius: '50%', backgroundColor: statusColor, flexShrink: 0}}/>
-                <span style={{fontWeight: 600, fontSize: '14px', flex: 1}}>{service.name}</span>
-                {service.cachedAt && service.cachedAt > 0 && (
-                    <span style={{fontSize: '10px', color: '#b0b0b0', flexShrink: 0}}>
-                        {new Date(service.cachedAt * 1000).toLocaleTimeString()}
-                    </span>
-                )}
+        <div>
+            <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '4px'}}>
+                {data.organization}{data.planType ? ` · ${data.planType.charAt(0).toUpperCase()}${data.planType.slice(1)}` : ''}
             </div>
-            {renderData()}
+            <UsageBar used={data.seatsActive || 0} total={data.seatsTotal || 0} label={`Seats active this cycle${data.seatsPending > 0 ? ` · ${data.seatsPending} pending` : ''}`} />
+            {(data.seatsInactive > 0 || data.seatCost > 0) && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginBottom: '4px'}}>
+                    {[
+                        data.seatCost > 0 ? `${formatMoney(data.seatCost, data.currency || 'USD', 0)}/month in seats` : '',
+                        data.seatsInactive > 0 ? `${data.seatsInactive} idle this cycle` : '',
+                        data.seatsPendingCancellation > 0 ? `${data.seatsPendingCancellation} ending` : '',
+                    ].filter(Boolean).join(' · ')}
+                </div>
+            )}
+            {data.hasPremiumData ? (
+                <UsageBar used={data.premiumUsed || 0} total={data.premiumAllowance || 0} label="Premium requests" />
+            ) : (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>Premium requests unavailable (token lacks billing access)</div>
+            )}
+            {data.overageCost > 0 && (
+                <div style={{fontSize: '12px', color: '#f5a623'}}>Overage: {formatMoney(data.overageCost, data.currency || 'USD')}</div>
+            )}
         </div>
     );
 };
 
-const RHSPanel: React.FC = () => {
-    const [services, setServices] = useState<ServiceData[]>([]);
-    const [loading, setLoading] = useState(true);
-    const [refreshing, setRefreshing] = useState(false);
-    const [error, setError] = useState<string | null>(null);
+const CursorCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            <UsageBar used={data.requestsUsed || 0} total={data.requestsTotal || 0} label={`Fast requests: ${formatNumber(data.requestsRemain || 0)} remaining`} />
+            <UsageBar used={data.seatsActive || 0} total={data.seatsTotal || 0} label="Seats active this cycle" />
+            {data.spendCost > 0 && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Usage-based spend: </span>
+                    <span style={{fontWeight: 600}}>{formatMoney(data.spendCost, data.currency || 'USD')}</span>
+                </div>
+            )}
+            {(data.topMembers || []).map((m: any) => (
+                <div key={m.name} style={{display: 'flex', fontSize: '11px', color: '#8b8fa7'}}>
+                    <span style={{flex: 1, overflow: 'hidden', textOverflow: 'ellipsis'}}>{m.name}</span>
+                    <span>{formatNumber(m.requests || 0)}{m.spend > 0 ? ` · ${formatMoney(m.spend, data.currency || 'USD')}` : ''}</span>
+                </div>
+            ))}
+            {data.cycleEnd && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Cycle ends: {new Date(data.cycleEnd).toLocaleDateString()}</div>}
+        </div>
+    );
+};
 
-    const loadData = useCallback(async () => {
-        try {
-            const data = await fetchStatus();
-            setServices(data.services);
-            setError(null);
-        } catch (e: any) {
-            setError(e.message);
-        } finally {
-            setLoading(false);
-        }
-    }, []);
+const MistralCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'EUR';
+    return (
+        <div>
+            {data.hasLimits && (
+                <UsageBar used={data.tokensUsed || 0} total={data.tokensLimit || 0} label={`Tokens (${data.period || 'this month'})`} />
+            )}
+            {!data.hasLimits && data.hasUsage && (
+                <div style={{fontSize: '14px', fontWeight: 600}}>{formatNumber(data.tokensUsed || 0)} tokens</div>
+            )}
+            {data.hasUsage && data.totalCost > 0 && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Month to date: </span>
+                    <span style={{fontWeight: 600}}>{formatMoney(data.totalCost, currency)}</span>
+                </div>
+            )}
+            {(data.models || []).slice(0, 5).map((m: any) => (
+                <div key={m.model} style={{display: 'flex', fontSize: '11px', color: '#8b8fa7'}}>
+                    <span style={{flex: 1, overflow: 'hidden', textOverflow: 'ellipsis'}}>{m.model}</span>
+                    <span>{formatNumber((m.inputTokens || 0) + (m.outputTokens || 0))}{m.cost > 0 ? ` · ${formatMoney(m.cost, currency)}` : ''}</span>
+                </div>
+            ))}
+            {!data.hasLimits && !data.hasUsage && (
+                <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · No limit or usage reported</div>
+            )}
+        </div>
+    );
+};
 
-    const handleRefresh = useCallback(async () => {
-        setRefreshing(true);
-        try {
-            const data = await refreshAll();
-            setServices(data.services);
-            setError(null);
-        } catch (e: any) {
-            setError(e.message);
-        } finally {
-            setRefreshing(false);
-        }
-    }, []);
+const GroqCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            {(data.models || []).map((m: any) => (
+                <div key={m.model} style={{marginBottom: '6px'}}>
+                    <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '2px'}}>{m.model}</div>
+                    {m.error ? (
+                        <div style={{fontSize: '11px', color: '#d24b4e'}}>{m.error}</div>
+                    ) : (
+                        <>
+                            <UsageBar used={(m.requestsLimit || 0) - (m.requestsRemaining || 0)} total={m.requestsLimit || 0} label={`Requests today${m.requestsReset ? ` · resets in ${formatTimeUntil(m.requestsReset)}` : ''}`} />
+                            <UsageBar used={(m.tokensLimit || 0) - (m.tokensRemaining || 0)} total={m.tokensLimit || 0} label="Tokens per minute" />
+                        </>
+                    )}
+                </div>
+            ))}
+        </div>
+    );
+};
 
-    useEffect(() => {
-        loadData();
-        const interval = setInterval(loadData, 5 * 60 * 1000);
-        return () => clearInterval(interval);
-    }, [loadData]);
+const DeepSeekCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const low = data.lowBalance || 0;
+    const balance = data.totalBalance || 0;
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Balance: </span>
+                <span style={{fontWeight: 600, color: balance <= 0 ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, currency)}</span>
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                Topped up: {formatMoney(data.toppedUpBalance || 0, currency)} · Granted: {formatMoney(data.grantedBalance || 0, currency)}
+            </div>
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatMoney(low, currency, 0)}</div>}
+            {!data.available && <div style={{fontSize: '12px', color: '#d24b4e'}}>API calls are suspended until the balance is topped up</div>}
+        </div>
+    );
+};
 
+const PerplexityCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
     return (
-        <div style={{
-            display: 'flex',
-            flexDirection: 'column',
-            height: 'calc(100vh - 63px)',
-            minHeight: 0,
-            backgroundColor: 'var(--center-channel-bg, #f8f9fa)',
-        }}>
-            <div style={{
-                display: 'flex',
-                justifyContent: 'space-between',
-                alignItems: 'center',
-                padding: '12px 16px',
-                borderBottom: '1px solid var(--center-channel-color-08, #e0e0e0)',
-                flexShrink: 0,
-            }}>
-                <h3 style={{margin: 0, fontSize: '16px', fontWeight: 600}}>AI Service Limits</h3>
-                <button onClick={handleRefresh} disabled={refreshing} style={{
-                    padding: '4px 12px', border: '1px solid var(--center-channel-color-16, #ccc)',
-                    borderRadius: '4px', backgroundColor: 'transparent',
-                    cursor: refreshing ? 'wait' : 'pointer', fontSize: '13px',
-                }}>
-                    {refreshing ? '⏳' : '🔄'} Refresh
-                </button>
+        <div>
+            {data.hasTier && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Usage tier: </span>
+                    <span style={{fontWeight: 600}}>{data.tier}</span>
+                    {data.nextTierSpend > 0 && <span style={{color: '#8b8fa7'}}> · next at {formatMoney(data.nextTierSpend, 'USD', 0)} purchased</span>}
+                </div>
+            )}
+            {data.hasBalance && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Credit balance (configured): </span>
+                    <span style={{fontWeight: 600}}>{formatMoney(data.creditBalance || 0, currency)}</span>
+                </div>
+            )}
+            {!data.hasTier && !data.hasBalance && <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · Set the tier and balance in System Console</div>}
+        </div>
+    );
+};
+
+const TogetherCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            {data.hasBalance ? (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Credit balance (configured): </span>
+                    <span style={{fontWeight: 600}}>{formatMoney(data.creditBalance || 0, data.currency || 'USD')}</span>
+                </div>
+            ) : (
+                <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · Set the balance in System Console</div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>{data.models || 0} models available</div>
+        </div>
+    );
+};
+
+const BedrockCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const cost = data.totalCost || 0;
+    const budget = data.budget || 0;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            {budget > 0 ? (
+                <UtilizationBar utilization={cost / budget * 100} label={`${data.period || 'Monthly'} budget: ${formatMoney(cost, currency)} / ${formatMoney(budget, currency, 0)}`} />
+            ) : (
+                <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(cost, currency)}</div>
+            )}
+            {data.hasMetrics && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                    {data.region}: {formatNumber(data.invocations || 0)} invocations · {formatNumber(data.inputTokens || 0)} in · {formatNumber(data.outputTokens || 0)} out
+                </div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
             </div>
-            <div style={{
-                padding: '16px',
-                flex: 1,
-                minHeight: 0,
-                overflowY: 'auto',
-                overflowX: 'hidden',
-            }}>
-                {loading && <div style={{textAlign: 'center', padding: '24px', color: '#8b8fa7'}}>Loading...</div>}
-                {error && (
+        </div>
+    );
+};
+
+const VertexCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const cost = data.totalCost || 0;
+    const budget = data.budget || 0;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    const projects: any[] = data.projects || [];
+    return (
+        <div>
+            {data.hasCost && (budget > 0 ? (
+                <UtilizationBar utilization={cost / budget * 100} label={`${data.period || 'Monthly'} budget: ${formatMoney(cost, currency)} / ${formatMoney(budget, currency, 0)}`} />
+            ) : (
+                <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(cost, currency)}</div>
+            ))}
+            {projects.map((p: any) => (
+                <div key={p.projectId} style={{marginTop: '6px'}}>
+                    {p.quotaMetric ? (
+                        <UtilizationBar utilization={p.quotaPercent || 0} label={`${p.projectId}: ${formatNumber(p.quotaUsage || 0)} / ${formatNumber(p.quotaLimit || 0)} per ${p.quotaWindow === 'day' ? 'day' : 'min'}`} />
+                    ) : (
+                        <div style={{fontSize: '12px'}}>{p.projectId}</div>
+                    )}
+                    <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                        {p.error ? p.error : [
+                            data.hasCost ? formatMoney(p.cost || 0, currency) : '',
+                            p.quotaMetric ? `${p.quotaMetric.replace('aiplatform.googleapis.com/', '')} (${p.location})` : (p.hasQuota ? 'No recent quota usage' : ''),
+                        ].filter(Boolean).join(' · ')}
+                    </div>
+                    {(p.models || []).map((m: any) => (
+                        <div key={`${m.metric}|${m.model}|${m.location}`} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                            <span>{m.model}{m.location ? ` (${m.location})` : ''}</span>
+                            <span style={{color: m.percent >= 100 ? '#d24b4e' : '#8b8fa7'}}>
+                                {formatNumber(m.usage)} / {formatNumber(m.limit)} per {m.window === 'day' ? 'day' : 'min'} · {m.percent.toFixed(0)}%
+                            </span>
+                        </div>
+                    ))}
+                </div>
+            ))}
+            {data.hasCost && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                    Resets in {days} day{days !== 1 ? 's' : ''}
+                </div>
+            )}
+        </div>
+    );
+};
+
+const GeminiCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const quotas: any[] = data.quotas || [];
+    const peak = quotas[0];
+    return (
+        <div>
+            {peak && (
+                <UtilizationBar utilization={peak.percent || 0} label={`${peak.model}: ${formatNumber(peak.usage || 0)} / ${formatNumber(peak.limit || 0)} per ${peak.window === 'day' ? 'day' : 'min'}`} />
+            )}
+            {quotas.slice(1).map((m: any) => (
+                <div key={`${m.metric}|${m.model}`} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span>{m.model}</span>
+                    <span style={{color: m.percent >= 100 ? '#d24b4e' : '#8b8fa7'}}>
+                        {formatNumber(m.usage)} / {formatNumber(m.limit)} per {m.window === 'day' ? 'day' : 'min'} · {m.percent.toFixed(0)}%
+                    </span>
+                </div>
+            ))}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                {[
+                    data.hasKey ? `${data.models || 0} models available` : '',
+                    data.project || '',
+                    data.hasQuota ? '' : (data.project ? 'No recent quota data' : 'Add a service account for quotas'),
+                ].filter(Boolean).join(' · ')}
+            </div>
+        </div>
+    );
+};
+
+const AzureOpenAICard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const deployments: any[] = data.deployments || [];
+    const binding = (d: any) => {
+        const tokens = d.tokensPerMinute > 0 ? (d.tokensUsed || 0) / d.tokensPerMinute : 0;
+        const requests = d.requestsPerMinute > 0 ? (d.requestsUsed || 0) / d.requestsPerMinute : 0;
+        return requests > tokens ? `${formatNumber(d.requestsUsed || 0)} / ${formatNumber(d.requestsPerMinute)} req/min` : `${formatNumber(d.tokensUsed || 0)} / ${formatNumber(d.tokensPerMinute || 0)} tokens/min`;
+    };
+    const peak = deployments.find((d) => d.tokensPerMinute > 0 || d.requestsPerMinute > 0);
+    return (
+        <div>
+            {peak && (
+                <UtilizationBar utilization={peak.percent || 0} label={`${peak.name}: ${binding(peak)}`} />
+            )}
+            {deployments.filter((d) => d !== peak).map((d: any) => (
+                <div key={`${d.account}|${d.name}`} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span>{d.name} · {d.model}</span>
+                    <span style={{color: d.percent >= 100 ? '#d24b4e' : '#8b8fa7'}}>
+                        {d.tokensPerMinute > 0 || d.requestsPerMinute > 0 ? `${binding(d)} · ${(d.percent || 0).toFixed(0)}%` : `${d.capacity} PTU`}
+                    </span>
+                </div>
+            ))}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                {deployments.length} deployment{deployments.length !== 1 ? 's' : ''} in {data.accounts || 0} resource{data.accounts !== 1 ? 's' : ''}{data.partial ? ' (first 10 read)' : ''} · busiest minute of the last 15
+            </div>
+        </div>
+    );
+};
+
+const XaiCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const spend = data.monthlySpend || 0;
+    const limit = data.spendingLimit || 0;
+    const balance = data.creditBalance || 0;
+    const low = data.lowBalance || 0;
+    return (
+        <div>
+            {data.hasBilling ? (
+                <>
+                    <div style={{fontSize: '14px', fontWeight: 600, color: low > 0 && balance < low ? '#f5a623' : undefined}}>
+                        {formatMoney(balance, currency)} credit
+                    </div>
+                    {limit > 0 ? (
+                        <UtilizationBar utilization={spend / limit * 100} label={`${data.period || 'Monthly'} spend: ${formatMoney(spend, currency)} / ${formatMoney(limit, currency, 0)}`} />
+                    ) : (
+                        <div style={{fontSize: '12px', marginTop: '4px'}}>{data.period}: {formatMoney(spend, currency)} spent</div>
+                    )}
+                </>
+            ) : (
+                <div style={{fontSize: '12px'}}>Connected{data.keyName ? ` · ${data.keyName}` : ''}</div>
+            )}
+            {data.tier && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>Rate limit: {data.tier}</div>
+            )}
+        </div>
+    );
+};
+
+const WindsurfCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            <UsageBar used={data.creditsUsed || 0} total={data.creditsTotal || 0} label={`Prompt credits: ${formatNumber(data.creditsRemain || 0)} remaining`} />
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Seats: </span>
+                <span style={{fontWeight: 600}}>{formatNumber(data.seats || 0)}</span>
+                <span style={{color: '#8b8fa7'}}> × {formatNumber(data.creditsPerSeat || 0)} credits</span>
+            </div>
+            {(data.addOnAvailable > 0 || data.addOnUsed > 0) && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Add-on credits: </span>
+                    <span style={{fontWeight: 600}}>{formatNumber(data.addOnAvailable || 0)}</span>
+                    <span style={{color: '#8b8fa7'}}> available, {formatNumber(data.addOnUsed || 0)} used</span>
+                </div>
+            )}
+            {(data.topMembers || []).map((m: any) => (
+                <div key={m.name} style={{display: 'flex', fontSize: '11px', color: '#8b8fa7'}}>
+                    <span style={{flex: 1, overflow: 'hidden', textOverflow: 'ellipsis'}}>{m.name}</span>
+                    <span>{formatNumber(m.credits || 0)}</span>
+                </div>
+            ))}
+            {data.cycleEnd && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Cycle ends: {new Date(data.cycleEnd).toLocaleDateString()}</div>}
+        </div>
+    );
+};
+
+const TabnineCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const days = data.daysUntilRenewal || 0;
+    return (
+        <div>
+            <UsageBar used={data.seatsAssigned || 0} total={data.seatsPurchased || 0} label={`Seats: ${formatNumber(data.seatsAssigned || 0)} of ${formatNumber(data.seatsPurchased || 0)} assigned`} />
+            {data.plan && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Plan: </span>
+                    <span style={{fontWeight: 600}}>{data.plan}</span>
+                </div>
+            )}
+            {data.renewalDate && (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                    Renews {new Date(data.renewalDate).toLocaleDateString()} (in {days} day{days !== 1 ? 's' : ''})
+                </div>
+            )}
+        </div>
+    );
+};
+
+const JetBrainsCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            {(data.products || []).map((p: any) => (
+                <UsageBar key={p.name} used={p.assigned || 0} total={p.total || 0} label={`${p.name}: ${formatNumber(p.assigned || 0)} of ${formatNumber(p.total || 0)} seats`} />
+            ))}
+            {data.creditsAllowance > 0 && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>AI credit quota: </span>
+                    <span style={{fontWeight: 600}}>{formatNumber(data.creditsAllowance)}</span>
+                    <span style={{color: '#8b8fa7'}}> per 30 days</span>
+                </div>
+            )}
+            {data.renewalDate && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Renews {new Date(data.renewalDate).toLocaleDateString()}</div>}
+        </div>
+    );
+};
+
+const CerebrasCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            {(data.models || []).map((m: any) => (
+                <div key={m.model} style={{marginBottom: '6px'}}>
+                    <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '2px'}}>{m.model}</div>
+                    {m.error ? (
+                        <div style={{fontSize: '11px', color: '#d24b4e'}}>{m.error}</div>
+                    ) : (
+                        <>
+                            {m.hasDailyTokens && (
+                                <UsageBar used={(m.tokensDayLimit || 0) - (m.tokensDayRemaining || 0)} total={m.tokensDayLimit || 0} label={`Tokens today${m.dayReset ? ` · resets in ${formatTimeUntil(m.dayReset)}` : ''}`} />
+                            )}
+                            <UsageBar used={(m.requestsLimit || 0) - (m.requestsRemaining || 0)} total={m.requestsLimit || 0} label={m.hasDailyTokens ? 'Requests today' : `Requests today${m.dayReset ? ` · resets in ${formatTimeUntil(m.dayReset)}` : ''}`} />
+                            <UsageBar used={(m.tokensMinuteLimit || 0) - (m.tokensMinuteRemaining || 0)} total={m.tokensMinuteLimit || 0} label="Tokens per minute" />
+                        </>
+                    )}
+                </div>
+            ))}
+        </div>
+    );
+};
+
+const SambaNovaCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            {(data.models || []).map((m: any) => (
+                <div key={m.model} style={{marginBottom: '6px'}}>
+                    <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '2px'}}>{m.model}</div>
+                    {m.error ? (
+                        <div style={{fontSize: '11px', color: '#d24b4e'}}>{m.error}</div>
+                    ) : (
+                        <>
+                            {m.hasDaily && (
+                                <UsageBar used={(m.dayLimit || 0) - (m.dayRemaining || 0)} total={m.dayLimit || 0} label={`Requests today${m.dayReset ? ` · resets in ${formatTimeUntil(m.dayReset)}` : ''}`} />
+                            )}
+                            <UsageBar used={(m.minuteLimit || 0) - (m.minuteRemaining || 0)} total={m.minuteLimit || 0} label="Requests per minute" />
+                        </>
+                    )}
+                </div>
+            ))}
+            {data.tier && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>Tier: {data.tier}</div>
+            )}
+        </div>
+    );
+};
+
+const SelfHostedCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const models: any[] = data.models || [];
+    const throughput = data.throughput;
+    return (
+        <div>
+            <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '4px'}}>
+                {data.serverType === 'openai' ? 'OpenAI-compatible' : `Ollama${data.version ? ` ${data.version}` : ''}`} · {data.latencyMs} ms
+            </div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                {data.serverType === 'openai' ? `${models.length} models served` : `${data.loaded} of ${models.length} models loaded`}
+            </div>
+            {models.filter((m) => m.loaded).slice(0, 5).map((m) => (
+                <div key={m.name} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span>{m.name}</span>
+                    <span style={{color: '#8b8fa7'}}>
+                        {m.vram ? `${(m.vram / 1e9).toFixed(1)} GB VRAM` : ''}
+                        {m.expiresAt ? ` · unloads in ${formatTimeUntil(m.expiresAt)}` : ''}
+                    </span>
+                </div>
+            ))}
+            {throughput && (throughput.error ? (
+                <div style={{fontSize: '11px', color: '#f5a623', marginTop: '4px'}}>Test generation on {throughput.model} failed: {throughput.error}</div>
+            ) : (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                    {throughput.tokensPerSecond.toFixed(1)} tok/s on {throughput.model}
+                    {throughput.promptTokensPerSecond ? ` · prompt ${throughput.promptTokensPerSecond.toFixed(0)} tok/s` : ''}
+                </div>
+            ))}
+        </div>
+    );
+};
+
+const LiteLLMCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const budgetRow = (b: any) => (
+        <div key={b.id} style={{marginBottom: '4px'}}>
+            {b.maxBudget ? (
+                <UtilizationBar utilization={b.spend / b.maxBudget * 100} label={`${b.name}: ${formatMoney(b.spend, currency)} / ${formatMoney(b.maxBudget, currency, 0)}${b.resetAt ? ` · resets in ${formatTimeUntil(b.resetAt)}` : ''}`} />
+            ) : (
+                <div style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span>{b.name}</span>
+                    <span style={{color: '#8b8fa7'}}>{formatMoney(b.spend, currency)} · no budget</span>
+                </div>
+            )}
+        </div>
+    );
+    return (
+        <div>
+            {data.totalBudget ? (
+                <UtilizationBar utilization={data.totalSpend / data.totalBudget * 100} label={`Proxy budget: ${formatMoney(data.totalSpend, currency)} / ${formatMoney(data.totalBudget, currency, 0)}`} />
+            ) : (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>Total spend: <span style={{fontWeight: 600}}>{formatMoney(data.totalSpend, currency)}</span></div>
+            )}
+            {(data.teams || []).length > 0 && (
+                <div style={{fontSize: '12px', color: '#8b8fa7', margin: '6px 0 2px'}}>Teams</div>
+            )}
+            {(data.teams || []).slice(0, 5).map(budgetRow)}
+            {(data.keys || []).length > 0 && (
+                <div style={{fontSize: '12px', color: '#8b8fa7', margin: '6px 0 2px'}}>Keys with budgets ({data.keysTotal} keys in total)</div>
+            )}
+            {(data.keys || []).slice(0, 5).map(budgetRow)}
+            {data.overBudget > 0 && (
+                <div style={{fontSize: '11px', color: '#d24b4e', marginTop: '4px'}}>{data.overBudget} over budget</div>
+            )}
+        </div>
+    );
+};
+
+const HeliconeCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const cost = data.totalCost || 0;
+    const budget = data.budget || 0;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            {budget > 0 ? (
+                <UtilizationBar utilization={cost / budget * 100} label={`${data.period || 'Monthly'} budget: ${formatMoney(cost, currency)} / ${formatMoney(budget, currency, 0)}`} />
+            ) : (
+                <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(cost, currency)}</div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                {formatNumber(data.requests || 0)} requests · {formatNumber(data.promptTokens || 0)} in · {formatNumber(data.completionTokens || 0)} out
+            </div>
+            {(data.topUsers || []).map((u: any) => (
+                <div key={u.user} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span>{u.user}</span>
+                    <span style={{color: '#8b8fa7'}}>{formatMoney(u.cost, currency)} · {formatNumber(u.requests)} req</span>
+                </div>
+            ))}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const GatewayCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    return (
+        <div>
+            <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '4px'}}>
+                Reachable · {data.models} models · {data.latencyMs} ms
+            </div>
+            {data.hasUsage && (data.limit > 0 ? (
+                data.unit === 'cost' ? (
+                    <UtilizationBar utilization={data.used / data.limit * 100} label={`Budget: ${formatMoney(data.used, currency)} / ${formatMoney(data.limit, currency, 0)}`} />
+                ) : (
+                    <UsageBar used={data.used} total={data.limit} label={`${data.unit.charAt(0).toUpperCase()}${data.unit.slice(1)} used`} />
+                )
+            ) : (
+                <div style={{fontSize: '14px', fontWeight: 600}}>
+                    {data.unit === 'cost' ? formatMoney(data.used, currency) : `${formatNumber(data.used)} ${data.unit}`}
+                </div>
+            ))}
+            {data.hasRateLimits && data.requestsLimit > 0 && (
+                <UsageBar used={data.requestsLimit - data.requestsRemaining} total={data.requestsLimit} label="Requests in rate limit window" />
+            )}
+            {data.hasRateLimits && data.tokensLimit > 0 && (
+                <UsageBar used={data.tokensLimit - data.tokensRemaining} total={data.tokensLimit} label="Tokens in rate limit window" />
+            )}
+            {data.usageError && (
+                <div style={{fontSize: '11px', color: '#f5a623', marginTop: '4px'}}>Usage endpoint: {data.usageError}</div>
+            )}
+        </div>
+    );
+};
+
+const ElevenLabsCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const used = data.charactersUsed || 0;
+    const limit = data.charactersLimit || 0;
+    return (
+        <div>
+            <UsageBar used={used} total={limit} label={`Characters (${data.tier})${data.nextReset ? ` · resets in ${formatTimeUntil(data.nextReset)}` : ''}`} />
+            {data.canExtend && used > limit && (
+                <div style={{fontSize: '11px', color: '#f5a623'}}>{formatNumber(used - limit)} characters over quota, billed as usage</div>
+            )}
+            {data.voicesLimit > 0 && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>Voices: {data.voicesUsed} / {data.voicesLimit}</div>
+            )}
+        </div>
+    );
+};
+
+const AssemblyAICard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    const low = data.hasBalance && data.lowBalance > 0 && data.creditBalance < data.lowBalance;
+    return (
+        <div>
+            <div style={{fontSize: '14px', fontWeight: 600}}>
+                {formatMoney(data.totalCost || 0, currency)}
+                <span style={{fontSize: '11px', fontWeight: 400, color: '#8b8fa7'}}> estimated, {data.period}</span>
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                {(data.hours || 0).toFixed(1)} audio hours · {formatNumber(data.transcripts || 0)} transcripts · {formatMoney(data.hourlyRate || 0, currency)}/h
+            </div>
+            {data.partial && (
+                <div style={{fontSize: '11px', color: '#f5a623'}}>Some durations are still being collected</div>
+            )}
+            {data.hasBalance && (
+                <div style={{fontSize: '12px', marginTop: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Credit balance (configured): </span>
+                    <span style={{fontWeight: 600, color: low ? '#f5a623' : undefined}}>{formatMoney(data.creditBalance || 0, currency)}</span>
+                </div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const SUPERMAVEN_STATUS_LABELS: Record<string, [string, string]> = {
+    trialing: ['Trial', '#8b8fa7'],
+    past_due: ['Payment past due', '#f5a623'],
+    canceled: ['Canceled', '#d24b4e'],
+};
+
+const SupermavenCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const days = data.daysUntilRenewal || 0;
+    const status = SUPERMAVEN_STATUS_LABELS[data.planStatus];
+    return (
+        <div>
+            <UsageBar used={data.seatsAssigned || 0} total={data.seatsPurchased || 0} label={`Seats: ${formatNumber(data.seatsAssigned || 0)} of ${formatNumber(data.seatsPurchased || 0)} assigned`} />
+            {(data.plan || status) && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    {data.plan && (
+                        <>
+                            <span style={{color: '#8b8fa7'}}>Plan: </span>
+                            <span style={{fontWeight: 600}}>{data.plan}</span>
+                        </>
+                    )}
+                    {status && <span style={{color: status[1]}}>{data.plan ? ' · ' : ''}{status[0]}</span>}
+                </div>
+            )}
+            {data.renewalDate && (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                    Renews {new Date(data.renewalDate).toLocaleDateString()} (in {days} day{days !== 1 ? 's' : ''})
+                </div>
+            )}
+        </div>
+    );
+};
+
+const AmazonQCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    const seats = data.seats || 0;
+    const agenticTotal = seats * (data.agenticLimit || 0);
+    return (
+        <div>
+            {data.hasMetrics && data.hasSeats && agenticTotal > 0 && (
+                <UsageBar used={data.agenticRequests || 0} total={agenticTotal} label={`Agentic requests (${formatNumber(data.agenticLimit)} per seat)`} />
+            )}
+            <div style={{fontSize: '14px', fontWeight: 600}}>
+                {formatMoney(data.totalCost || 0, currency)}
+                <span style={{fontSize: '11px', fontWeight: 400, color: '#8b8fa7'}}> {data.period}</span>
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                {data.hasSeats && <>{formatNumber(seats)} seat{seats !== 1 ? 's' : ''} · </>}
+                {data.hasMetrics ? `${formatNumber(data.requests || 0)} requests this month` : 'No CloudWatch metrics'} · {data.region}
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const MoonshotCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const low = data.lowBalance || 0;
+    const balance = data.availableBalance || 0;
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Balance: </span>
+                <span style={{fontWeight: 600, color: balance <= 0 ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, currency)}</span>
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                Cash: {formatMoney(data.cashBalance || 0, currency)} · Vouchers: {formatMoney(data.voucherBalance || 0, currency)}
+            </div>
+            {data.requestsPerMinute > 0 && (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                    {data.tier}: {formatNumber(data.requestsPerMinute)} RPM · {formatNumber(data.tokensPerMinute || 0)} TPM · {formatNumber(data.concurrency || 0)} concurrent
+                </div>
+            )}
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatMoney(low, currency, 0)}</div>}
+            {balance <= 0 && <div style={{fontSize: '12px', color: '#d24b4e'}}>API calls are refused until the balance is topped up</div>}
+        </div>
+    );
+};
+
+const DashScopeCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const cost = data.totalCost || 0;
+    const budget = data.budget || 0;
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            {data.hasBilling ? (
+                budget > 0 ? (
+                    <UtilizationBar utilization={cost / budget * 100} label={`${data.period || 'Monthly'} budget: ${formatMoney(cost, currency)} / ${formatMoney(budget, currency, 0)}`} />
+                ) : (
+                    <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(cost, currency)}</div>
+                )
+            ) : (
+                <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · {data.models || 0} models available</div>
+            )}
+            {(data.quotas || []).map((q: any) => (
+                <UsageBar key={q.name} used={(q.total || 0) - (q.remaining || 0)} total={q.total || 0} label={`${q.name}${q.unit ? ` (${q.unit})` : ''}`} />
+            ))}
+            {data.billingError && <div style={{fontSize: '11px', color: '#f5a623'}}>Billing unavailable: {data.billingError}</div>}
+            {data.hasBilling && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                    Resets in {days} day{days !== 1 ? 's' : ''}
+                </div>
+            )}
+        </div>
+    );
+};
+
+const AI21Card: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const days = data.daysLeft || 0;
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                {data.plan ? (
+                    <>
+                        <span style={{color: '#8b8fa7'}}>Plan: </span>
+                        <span style={{fontWeight: 600}}>{data.plan}</span>
+                    </>
+                ) : (
+                    <span style={{color: '#8b8fa7'}}>Connected</span>
+                )}
+            </div>
+            {data.trialEnds && (
+                <div style={{fontSize: '11px', color: days < 0 ? '#d24b4e' : days < 7 ? '#f5a623' : '#8b8fa7'}}>
+                    {days < 0 ? 'Trial credit expired' : `Trial credit expires ${new Date(data.trialEnds).toLocaleDateString()} (in ${days} day${days !== 1 ? 's' : ''})`}
+                </div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>Token usage isn't available through the AI21 API</div>
+        </div>
+    );
+};
+
+const FalCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const low = data.lowBalance || 0;
+    const balance = data.creditBalance || 0;
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Credits: </span>
+                <span style={{fontWeight: 600, color: balance <= 0 ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, currency)}</span>
+            </div>
+            {data.hasUsage && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>{data.period || 'This month'}: </span>
+                    <span style={{fontWeight: 600}}>{formatMoney(data.monthlySpend || 0, currency)}</span>
+                </div>
+            )}
+            {(data.topEndpoints || []).map((e: any) => (
+                <div key={e.endpoint} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span style={{color: '#8b8fa7', overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap'}}>{e.endpoint}</span>
+                    <span>{formatNumber(e.quantity || 0)}{e.unit ? ` ${e.unit}s` : ''} · {formatMoney(e.cost || 0, currency)}</span>
+                </div>
+            ))}
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatMoney(low, currency, 0)}</div>}
+            {balance <= 0 && <div style={{fontSize: '12px', color: '#d24b4e'}}>Requests are refused until credits are added</div>}
+            {data.hasUsage && (
+                <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                    Resets in {days} day{days !== 1 ? 's' : ''}
+                </div>
+            )}
+        </div>
+    );
+};
+
+const RunPodCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const low = data.lowBalance || 0;
+    const balance = data.creditBalance || 0;
+    const burn = data.spendPerHour || 0;
+    const runway = data.runwayHours || 0;
+    const serverless = Math.max(burn - (data.podSpendPerHour || 0), 0);
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Balance: </span>
+                <span style={{fontWeight: 600, color: balance <= 0 ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, 'USD')}</span>
+            </div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Burn rate: </span>
+                <span style={{fontWeight: 600}}>{formatMoney(burn, 'USD')}/hr</span>
+                {runway > 0 && <span style={{color: runway < 24 ? '#f5a623' : '#8b8fa7'}}> · lasts ~{runway >= 48 ? `${Math.floor(runway / 24)} days` : `${Math.floor(runway)} hours`}</span>}
+            </div>
+            {(data.pods || []).map((pod: any) => (
+                <div key={pod.name} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span style={{color: '#8b8fa7', overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap'}}>{pod.name}{pod.gpu ? ` · ${pod.gpuCount || 1}× ${pod.gpu}` : ''}</span>
+                    <span>{formatMoney(pod.costPerHr || 0, 'USD')}/hr</span>
+                </div>
+            ))}
+            {data.endpoints > 0 && (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                    Serverless: {data.endpoints} endpoint{data.endpoints !== 1 ? 's' : ''} · {data.minWorkers || 0} always-on worker{data.minWorkers !== 1 ? 's' : ''} · {formatMoney(serverless, 'USD')}/hr
+                </div>
+            )}
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatMoney(low, 'USD', 0)}</div>}
+            {balance <= 0 && <div style={{fontSize: '12px', color: '#d24b4e'}}>Pods and workers are stopped until the balance is topped up</div>}
+        </div>
+    );
+};
+
+const YandexCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'RUB';
+    const low = data.lowBalance || 0;
+    const balance = data.balance || 0;
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Balance: </span>
+                <span style={{fontWeight: 600, color: !data.active ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, currency)}</span>
+                {data.accountName && <span style={{color: '#8b8fa7'}}> · {data.accountName}</span>}
+            </div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>{data.period || 'This month'}: </span>
+                <span style={{fontWeight: 600}}>~{formatMoney(data.monthlySpend || 0, currency)}</span>
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                Estimated from the balance{data.trackedSince ? ` since ${new Date(data.trackedSince).toLocaleDateString()}` : ''}, all services on the account
+            </div>
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatMoney(low, currency, 0)}</div>}
+            {!data.active && <div style={{fontSize: '12px', color: '#d24b4e'}}>Billing account is suspended, so API calls are refused</div>}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const GigaChatCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const low = data.lowTokens || 0;
+    const packages = data.packages || [];
+    if (packages.length === 0) {
+        return <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · postpaid ({data.scope}), no token balance</div>;
+    }
+    return (
+        <div>
+            {packages.map((pkg: any) => (
+                <div key={pkg.usage} style={{fontSize: '12px', display: 'flex', justifyContent: 'space-between', marginBottom: '2px'}}>
+                    <span style={{color: '#8b8fa7'}}>{pkg.usage}</span>
+                    <span style={{fontWeight: 600, color: pkg.remaining <= 0 ? '#d24b4e' : low > 0 && pkg.remaining < low ? '#f5a623' : undefined}}>{formatNumber(pkg.remaining || 0)} tokens left</span>
+                </div>
+            ))}
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatNumber(low)} tokens</div>}
+        </div>
+    );
+};
+
+const RekaCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const low = data.lowBalance || 0;
+    const balance = data.creditBalance || 0;
+    return (
+        <div>
+            {data.hasBalance ? (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Credit balance (configured): </span>
+                    <span style={{fontWeight: 600, color: low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, data.currency || 'USD')}</span>
+                </div>
+            ) : (
+                <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · Set the balance in System Console</div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>{data.models || 0} models available</div>
+        </div>
+    );
+};
+
+const WatsonxCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const cost = data.totalCost || 0;
+    const budget = data.budget || 0;
+    const limit = data.resourceUnitLimit || 0;
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            {limit > 0 ? (
+                <UsageBar used={data.resourceUnits || 0} total={limit} label={`Resource units${data.plan ? ` (${data.plan})` : ''}`} />
+            ) : (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Resource units{data.plan ? ` (${data.plan})` : ''}: </span>
+                    <span style={{fontWeight: 600}}>{formatNumber(data.resourceUnits || 0)}</span>
+                    <span style={{color: '#8b8fa7'}}> · {formatNumber((data.resourceUnits || 0) * 1000)} tokens</span>
+                </div>
+            )}
+            {budget > 0 ? (
+                <UtilizationBar utilization={cost / budget * 100} label={`${data.period || 'Monthly'} budget: ${formatMoney(cost, currency)} / ${formatMoney(budget, currency, 0)}`} />
+            ) : (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>{data.period || 'This month'}: </span>
+                    <span style={{fontWeight: 600}}>{formatMoney(cost, currency)}</span>
+                </div>
+            )}
+            {(data.capacityHours || 0) > 0 && (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>{formatNumber(data.capacityHours)} capacity unit hours</div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const DatabricksCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const cost = data.totalCost || 0;
+    const budget = data.budget || 0;
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            {budget > 0 ? (
+                <UtilizationBar utilization={cost / budget * 100} label={`${data.period || 'Monthly'} budget: ${formatMoney(cost, currency)} / ${formatMoney(budget, currency, 0)}`} />
+            ) : (
+                <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(cost, currency)}</div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginBottom: '4px'}}>
+                Foundation Model APIs: {formatMoney(data.foundationCost || 0, currency)} · {formatNumber(data.dbus || 0)} DBUs at list price
+            </div>
+            {(data.topEndpoints || []).map((e: any) => (
+                <div key={e.name} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span style={{color: '#8b8fa7', overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap'}}>{e.name}</span>
+                    <span>{formatMoney(e.cost || 0, currency)}</span>
+                </div>
+            ))}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const NvidiaCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    if (!data.hasCredits) {
+        return (
+            <div>
+                <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · Set the credits in System Console</div>
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>{data.models || 0} models available</div>
+            </div>
+        );
+    }
+    const left = data.creditsLeft || 0;
+    const total = data.creditsTotal || 0;
+    const daysLeft = data.daysLeft || 0;
+    return (
+        <div>
+            {total > 0 ? (
+                <UsageBar used={total - left} total={total} label='Credits (configured)' />
+            ) : (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Credits left (configured): </span>
+                    <span style={{fontWeight: 600, color: left <= 0 ? '#d24b4e' : undefined}}>{formatNumber(left)}</span>
+                </div>
+            )}
+            {data.creditsPerDay > 0 && (
+                <div style={{fontSize: '11px', color: daysLeft > 0 && daysLeft < 7 ? '#f5a623' : '#8b8fa7'}}>
+                    ~{formatNumber(data.creditsPerDay)} credits/day · {Math.floor(daysLeft)} days left
+                </div>
+            )}
+            {data.creditsUpdated && (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>Balance updated {new Date(data.creditsUpdated).toLocaleDateString()}</div>
+            )}
+            {left <= 0 && <div style={{fontSize: '12px', color: '#d24b4e'}}>Hosted endpoints refuse requests once the credits are used up</div>}
+        </div>
+    );
+};
+
+const VercelGatewayCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const low = data.lowBalance || 0;
+    const balance = data.balance || 0;
+    const days = data.daysUntilReset || 0;
+    const tracked = data.trackedSince ? new Date(data.trackedSince) : null;
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Credits: </span>
+                <span style={{fontWeight: 600, color: balance <= 0 ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, currency)}</span>
+            </div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>{data.period || 'This month'}: </span>
+                <span style={{fontWeight: 600}}>{formatMoney(data.monthlySpend || 0, currency)}</span>
+                {tracked && tracked.getUTCDate() > 1 && <span style={{color: '#8b8fa7'}}> since {tracked.toLocaleDateString()}</span>}
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>{formatMoney(data.totalUsed || 0, currency)} used in total</div>
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatMoney(low, currency, 0)}</div>}
+            {balance <= 0 && <div style={{fontSize: '12px', color: '#d24b4e'}}>Paid requests are refused until credits are added</div>}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const OpenRouterCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const low = data.lowBalance || 0;
+    const balance = data.balance || 0;
+    const limit = data.keyLimit;
+    const used = limit !== undefined && data.keyLimitRemaining !== undefined ? Math.max(limit - data.keyLimitRemaining, 0) : undefined;
+    const resets: Record<string, string> = {daily: 'resets daily', weekly: 'resets weekly', monthly: 'resets monthly'};
+    return (
+        <div>
+            {data.hasCredits && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                    <span style={{color: '#8b8fa7'}}>Credits: </span>
+                    <span style={{fontWeight: 600, color: balance <= 0 ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, currency)}</span>
+                    <span style={{color: '#8b8fa7'}}> of {formatMoney(data.totalCredits || 0, currency, 0)} purchased</span>
+                </div>
+            )}
+            {used !== undefined && limit > 0 && (
+                <UtilizationBar utilization={used / limit * 100} label={`Key limit: ${formatMoney(used, currency)} / ${formatMoney(limit, currency, 0)}${data.limitReset ? ` · ${resets[data.limitReset] || data.limitReset}` : ''}`} />
+            )}
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>{data.label ? `${data.label}: ` : 'Key: '}</span>
+                {formatMoney(data.usageDaily || 0, currency)} today · {formatMoney(data.usageWeekly || 0, currency)} this week · {formatMoney(data.usageMonthly || 0, currency)} this month
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                {[
+                    data.rateLimitRequests ? `${formatNumber(data.rateLimitRequests)} requests per ${data.rateLimitInterval}` : '',
+                    data.isFreeTier ? 'Free tier' : '',
+                    low > 0 && data.hasCredits ? `Low balance alert below ${formatMoney(low, currency, 0)}` : '',
+                ].filter(Boolean).join(' · ')}
+            </div>
+            {data.hasCredits && balance <= 0 && <div style={{fontSize: '12px', color: '#d24b4e'}}>Paid requests are refused until credits are added</div>}
+        </div>
+    );
+};
+
+const PortkeyCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    return (
+        <div>
+            <div style={{fontSize: '14px', fontWeight: 600}}>{formatMoney(data.totalCost || 0, currency)}</div>
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginBottom: '4px'}}>
+                {formatNumber(data.totalRequests || 0)} requests {data.period ? `in ${data.period}` : 'this month'}
+            </div>
+            {(data.workspaces || []).map((w: any) => (
+                <div key={w.slug || 'default'} style={{marginBottom: '4px'}}>
+                    {w.budget ? (
+                        <UtilizationBar utilization={w.cost / w.budget * 100} label={`${w.name || w.slug}: ${formatMoney(w.cost, currency)} / ${formatMoney(w.budget, currency, 0)} · ${formatNumber(w.requests)} req`} />
+                    ) : (
+                        <div style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                            <span>{w.name || w.slug || 'This workspace'}</span>
+                            <span style={{color: '#8b8fa7'}}>{formatMoney(w.cost, currency)} · {formatNumber(w.requests)} req</span>
+                        </div>
+                    )}
+                </div>
+            ))}
+            {data.partial && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Showing the first 10 workspaces</div>}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const GithubModelsCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    return (
+        <div>
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginBottom: '4px'}}>
+                {data.organization ? `${data.organization} · ` : ''}Copilot {data.plan || 'free'} allowances
+            </div>
+            {(data.tiers || []).map((t: any) => (
+                <div key={t.model} style={{marginBottom: '6px'}}>
+                    <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '2px'}}>{t.model} · {t.tier} tier</div>
+                    {t.error ? (
+                        <div style={{fontSize: '11px', color: '#d24b4e'}}>{t.error}</div>
+                    ) : (
+                        <>
+                            {t.throttled ? (
+                                <div style={{fontSize: '11px', color: '#d24b4e'}}>Rate limited{t.requestsReset ? ` · resets in ${formatTimeUntil(t.requestsReset)}` : ''}</div>
+                            ) : t.requestsLimit > 0 && (
+                                <UsageBar used={t.requestsLimit - (t.requestsRemaining || 0)} total={t.requestsLimit} label={`Requests${t.requestsReset ? ` · resets in ${formatTimeUntil(t.requestsReset)}` : ''}`} />
+                            )}
+                            {t.requestsPerDay > 0 && (
+                                <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                                    {formatNumber(t.requestsPerDay)}/day · {formatNumber(t.requestsPerMinute)}/min · {formatNumber(t.tokensPerRequest)} tokens in · {t.concurrent} concurrent
+                                </div>
+                            )}
+                        </>
+                    )}
+                </div>
+            ))}
+        </div>
+    );
+};
+
+const CODEX_WINDOW_LABELS: Record<string, string> = {
+    primary: '5-hour window',
+    weekly: 'Weekly window',
+};
+
+const CodexCard: React.FC<{data: any}> = ({data}) => {
+    if (!data || !data.hasData) {
+        return <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · No usage data yet</div>;
+    }
+    return (
+        <div>
+            <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '4px'}}>ChatGPT {data.plan || 'plan'}</div>
+            {data.constrained && data.constrained.length > 0 && (
+                <div style={{fontSize: '12px', color: '#d24b4e', marginBottom: '4px'}}>
+                    Limited by: {data.constrained.map((w: string) => CODEX_WINDOW_LABELS[w] || w).join(', ')}
+                </div>
+            )}
+            <UtilizationBar utilization={data.primaryUsed || 0} label={CODEX_WINDOW_LABELS.primary} resetAt={data.primaryReset} />
+            <UtilizationBar utilization={data.weeklyUsed || 0} label={CODEX_WINDOW_LABELS.weekly} resetAt={data.weeklyReset} />
+            {data.credits !== undefined && (
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>Credits: {formatNumber(data.credits)}</div>
+            )}
+        </div>
+    );
+};
+
+const ChatGPTCard: React.FC<{data: any}> = ({data}) => {
+    if (!data || !data.hasData) {
+        return <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · No usage data yet</div>;
+    }
+    const capped: any[] = data.cappedModels || [];
+    const blocked: string[] = data.blockedFeatures || [];
+    return (
+        <div>
+            <div style={{fontSize: '12px', color: '#8b8fa7', marginBottom: '4px'}}>
+                ChatGPT {data.plan || 'plan'}{data.renewsAt ? ` · renews ${new Date(data.renewsAt).toLocaleDateString()}` : ''}
+            </div>
+            {capped.length === 0 && blocked.length === 0 && (
+                <div style={{fontSize: '12px', marginBottom: '4px'}}>No message caps reached</div>
+            )}
+            {capped.map((m: any) => (
+                <div key={m.model} style={{fontSize: '12px', color: '#d24b4e', marginBottom: '2px'}}>
+                    {m.model}: limit reached{m.resetsAfter ? ` · lifts in ${formatTimeUntil(m.resetsAfter)}` : ''}
+                </div>
+            ))}
+            {blocked.length > 0 && (
+                <div style={{fontSize: '12px', color: '#f5a623', marginBottom: '2px'}}>Blocked: {blocked.join(', ')}</div>
+            )}
+            {(data.features || []).map((f: any) => (
+                <div key={f.feature} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span>{f.feature.replace(/_/g, ' ')}</span>
+                    <span style={{color: f.remaining <= 0 ? '#d24b4e' : '#8b8fa7'}}>
+                        {f.remaining} left{f.resetAfter ? ` · resets in ${formatTimeUntil(f.resetAfter)}` : ''}
+                    </span>
+                </div>
+            ))}
+        </div>
+    );
+};
+
+const ClaudeCodeCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const budget = data.developerBudget || 0;
+    const days = data.daysUntilReset || 0;
+    if (!data.developers || data.developers.length === 0) {
+        return <div style={{fontSize: '12px', color: '#8b8fa7'}}>No usage reported yet · run the usage agent on developers' machines</div>;
+    }
+    return (
+        <div>
+            <div style={{fontSize: '14px', fontWeight: 600}}>{formatNumber(data.totalTokens || 0)} tokens</div>
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginBottom: '4px'}}>
+                {formatMoney(data.totalCost || 0, currency)} at API prices · {formatNumber(data.sessions || 0)} sessions {data.period ? `in ${data.period}` : 'this month'}
+            </div>
+            {data.developers.map((d: any) => (
+                <div key={d.userId} style={{marginBottom: '4px'}} title={`${d.hosts.join(', ')} · last report ${new Date(d.lastReport).toLocaleString()}`}>
+                    {budget > 0 ? (
+                        <UtilizationBar utilization={d.cost / budget * 100} label={`@${d.username}: ${formatMoney(d.cost, currency)} / ${formatMoney(budget, currency, 0)} · ${formatNumber(d.inputTokens + d.outputTokens + d.cacheTokens)} tok`} />
+                    ) : (
+                        <div style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                            <span>@{d.username}</span>
+                            <span style={{color: '#8b8fa7'}}>{formatMoney(d.cost, currency)} · {formatNumber(d.inputTokens + d.outputTokens + d.cacheTokens)} tok</span>
+                        </div>
+                    )}
+                    {d.stale && <div style={{fontSize: '11px', color: '#f5a623'}}>No report since {new Date(d.lastReport).toLocaleDateString()}</div>}
+                </div>
+            ))}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const UpstageCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const low = data.lowBalance || 0;
+    const balance = data.creditBalance || 0;
+    if (!data.hasBalance) {
+        return (
+            <div>
+                <div style={{fontSize: '12px', color: '#8b8fa7'}}>Connected · Set the balance in System Console</div>
+                <div style={{fontSize: '11px', color: '#8b8fa7'}}>{data.models || 0} models available</div>
+            </div>
+        );
+    }
+    return (
+        <div>
+            <div style={{fontSize: '12px', marginBottom: '4px'}}>
+                <span style={{color: '#8b8fa7'}}>Credit balance (configured): </span>
+                <span style={{fontWeight: 600, color: balance <= 0 ? '#d24b4e' : low > 0 && balance < low ? '#f5a623' : undefined}}>{formatMoney(balance, currency)}</span>
+            </div>
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                {formatMoney(data.monthlyUsage || 0, currency)} used {data.period ? `in ${data.period}` : 'this month'}
+                {data.balanceUpdated ? ` · balance updated ${new Date(data.balanceUpdated).toLocaleDateString()}` : ''}
+            </div>
+            {low > 0 && <div style={{fontSize: '11px', color: '#8b8fa7'}}>Low balance alert below {formatMoney(low, currency, 0)}</div>}
+        </div>
+    );
+};
+
+const AIGatewayCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const days = data.daysUntilReset || 0;
+    const tracked = data.trackedSince ? new Date(data.trackedSince) : null;
+    const models: any[] = data.models || [];
+    return (
+        <div>
+            {data.tokenLimit > 0 && (
+                <UtilizationBar utilization={data.tokens / data.tokenLimit * 100} label={`Tokens: ${formatNumber(data.tokens || 0)} / ${formatNumber(data.tokenLimit)}`} />
+            )}
+            {!data.tokenLimit && data.budget > 0 && (
+                <UtilizationBar utilization={data.cost / data.budget * 100} label={`Budget: ${formatMoney(data.cost || 0, currency)} / ${formatMoney(data.budget, currency, 0)}`} />
+            )}
+            {!data.tokenLimit && !data.budget && (
+                <div style={{fontSize: '14px', fontWeight: 600}}>{data.hasCost ? formatMoney(data.cost || 0, currency) : `${formatNumber(data.tokens || 0)} tokens`}</div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginBottom: '4px'}}>
+                {formatNumber(data.requests || 0)} requests · {formatNumber(data.inputTokens || 0)} in / {formatNumber(data.outputTokens || 0)} out {data.period ? `in ${data.period}` : 'this month'}
+                {tracked && tracked.getUTCDate() > 1 ? ` since ${tracked.toLocaleDateString()}` : ''}
+            </div>
+            {models.map((m: any) => (
+                <div key={m.model} style={{fontSize: '11px', display: 'flex', justifyContent: 'space-between'}}>
+                    <span>{m.model || 'Unknown model'}</span>
+                    <span style={{color: '#8b8fa7'}}>{formatNumber(m.tokens)} tok{data.hasCost ? ` · ${formatMoney(m.cost || 0, currency)}` : ''}</span>
+                </div>
+            ))}
+            {data.modelCount > models.length && <div style={{fontSize: '11px', color: '#8b8fa7'}}>+{data.modelCount - models.length} more models</div>}
+            <div style={{fontSize: '11px', color: '#8b8fa7', marginTop: '4px'}}>
+                Via {data.gateway === 'envoy' ? 'Envoy AI Gateway' : 'Kong AI Gateway'} · resets in {days} day{days !== 1 ? 's' : ''}
+            </div>
+        </div>
+    );
+};
+
+const CustomProviderCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const unit = data.unit || 'credits';
+    const amount = (value: number) => (unit === 'cost' ? formatMoney(value, currency) : `${formatNumber(value)} ${unit}`);
+    const reset = data.resetAt ? ` · resets in ${formatTimeUntil(data.resetAt)}` : '';
+    return (
+        <div>
+            {data.total > 0 ? (
+                unit === 'cost' ? (
+                    <UtilizationBar utilization={data.used / data.total * 100} label={`Budget: ${formatMoney(data.used, currency)} / ${formatMoney(data.total, currency, 0)}${reset}`} />
+                ) : (
+                    <UsageBar used={data.used} total={data.total} label={`${unit.charAt(0).toUpperCase()}${unit.slice(1)} used${reset}`} />
+                )
+            ) : (
+                <div style={{fontSize: '14px', fontWeight: 600}}>
+                    {data.hasRemaining ? `${amount(data.remaining)} left` : `${amount(data.used)} used`}
+                </div>
+            )}
+            <div style={{fontSize: '11px', color: '#8b8fa7'}}>
+                {[
+                    data.total > 0 && data.hasRemaining ? `${amount(data.remaining)} left` : '',
+                    data.total > 0 || !data.resetAt ? '' : `Resets in ${formatTimeUntil(data.resetAt)}`,
+                    `${data.latencyMs} ms`,
+                ].filter(Boolean).join(' · ')}
+            </div>
+        </div>
+    );
+};
+
+const PluginProviderCard: React.FC<{data: any}> = ({data}) => {
+    if (!data) return null;
+    const currency = data.currency || 'USD';
+    const unit = data.unit || 'credits';
+    const amount = (value: number) => (unit === 'cost' ? formatMoney(value, currency) : `${formatNumber(value)} ${unit}`);
+    const used = data.used ?? (data.limit != null && data.remaining != null ? data.limit - data.remaining : null);
+    return (
+        <div>
+            {used != null && data.limit > 0 ? (
+                <UsageBar used={used} total={data.limit} label={`${unit.charAt(0).toUpperCase()}${unit.slice(1)} used${data.resetAt ? ` · resets in ${formatTimeUntil(data.resetAt)}` : ''}`} />
+            ) : (used != null || data.remaining != null) && (
+                <div style={{fontSize: '14px', fontWeight: 600}}>
+                    {data.remaining != null ? `${amount(data.remaining)} left` : `${amount(used)} used`}
+                </div>
+            )}
+            {data.summary && <div style={{fontSize: '12px', marginTop: '4px'}}>{data.summary}</div>}
+            <div style={{fontSize: '11px', color: data.stale ? '#f5a623' : '#8b8fa7'}}>
+                From plugin {data.pluginId}{data.stale ? ` · last report ${new Date(data.updatedAt).toLocaleDateString()}` : ''}
+            </div>
+        </div>
+    );
+};
+
+const CredentialExpiry: React.FC<{expiresAt: number}> = ({expiresAt}) => {
+    const days = Math.floor((expiresAt - Date.now()) / 86400000);
+    const expired = expiresAt <= Date.now();
+    let color = '#8b8fa7';
+    if (expired) {
+        color = '#d24b4e';
+    } else if (days < 14) {
+        color = '#f5a623';
+    }
+    return (
+        <div style={{fontSize: '11px', color, marginTop: '6px'}}>
+            {expired ? `Key expired on ${new Date(expiresAt).toLocaleDateString()}` : `Key expires in ${formatTimeUntil(expiresAt)}`}
+        </div>
+    );
+};
+
+// Projection to the end of the billing cycle; amber when it's heading over the limit.
+const ForecastLine: React.FC<{forecast: Forecast}> = ({forecast}) => {
+    const amount = (n: number) => (forecast.unit === 'cost' ? formatMoney(n, forecast.currency, 0) : formatNumber(n));
+    let text = `On track for ${amount(forecast.projected)}`;
+    if (forecast.limit !== undefined) {
+        text += ` of ${amount(forecast.limit)}`;
+    }
+    text += ` by ${new Date(forecast.cycleEnd).toLocaleDateString()}`;
+    if (forecast.exhaustsAt) {
+        text += ` · runs out in ${formatTimeUntil(forecast.exhaustsAt)}`;
+    }
+    return (
+        <div style={{fontSize: '11px', color: forecast.overrun ? '#f5a623' : '#8b8fa7', marginTop: '6px'}}>
+            {text}
+        </div>
+    );
+};
+
+const ServiceCard: React.FC<{service: ServiceData; units: string; onHide?: () => void; onRefresh?: () => Promise<void>}> = ({service, units, onHide, onRefresh}) => {
+    const [refreshing, setRefreshing] = useState(false);
+    const statusColor = getStatusColor(service.status);
+    const headline = service.error ? null : formatHeadline(service.usage, units);
+
+    const renderData = () => {
+        if (service.error) {
+            return (
+                <div>
+                    <div style={{fontSize: '12px', color: '#d24b4e'}}>{service.error}</div>
+                    {service.requestId && <div style={{fontSize: '10px', color: '#8b8fa7', marginTop: '2px'}}>Ref: {service.requestId}</div>}
+                </div>
+            );
+        }
+        // Multi-instance providers use "<provider>:<instance>" IDs, e.g. "openai:org-abc"
+        switch (service.id.split(':')[0]) {
+            case 'augment': return <AugmentCard data={service.data} />;
+            case 'zai': return <ZaiCard data={service.data} />;
+            case 'openai': return <OpenAICard data={service.data} />;
+            case 'claude': return <ClaudeCard data={service.data} />;
+            case 'anthropic': return <AnthropicCard data={service.data} />;
+            case 'copilot': return <CopilotCard data={service.data} />;
+            case 'cursor': return <CursorCard data={service.data} />;
+            case 'mistral': return <MistralCard data={service.data} />;
+            case 'groq': return <GroqCard data={service.data} />;
+            case 'deepseek': return <DeepSeekCard data={service.data} />;
+            case 'perplexity': return <PerplexityCard data={service.data} />;
+            case 'together': return <TogetherCard data={service.data} />;
+            case 'bedrock': return <BedrockCard data={service.data} />;
+            case 'vertex': return <VertexCard data={service.data} />;
+            case 'gemini': return <GeminiCard data={service.data} />;
+            case 'azureopenai': return <AzureOpenAICard data={service.data} />;
+            case 'xai': return <XaiCard data={service.data} />;
+            case 'windsurf': return <WindsurfCard data={service.data} />;
+            case 'tabnine': return <TabnineCard data={service.data} />;
+            case 'jetbrains': return <JetBrainsCard data={service.data} />;
+            case 'cerebras': return <CerebrasCard data={service.data} />;
+            case 'sambanova': return <SambaNovaCard data={service.data} />;
+            case 'selfhosted': return <SelfHostedCard data={service.data} />;
+            case 'litellm': return <LiteLLMCard data={service.data} />;
+            case 'helicone': return <HeliconeCard data={service.data} />;
+            case 'gateway': return <GatewayCard data={service.data} />;
+            case 'elevenlabs': return <ElevenLabsCard data={service.data} />;
+            case 'assemblyai': return <AssemblyAICard data={service.data} />;
+            case 'supermaven': return <SupermavenCard data={service.data} />;
+            case 'amazonq': return <AmazonQCard data={service.data} />;
+            case 'moonshot': return <MoonshotCard data={service.data} />;
+            case 'dashscope': return <DashScopeCard data={service.data} />;
+            case 'ai21': return <AI21Card data={service.data} />;
+            case 'fal': return <FalCard data={service.data} />;
+            case 'runpod': return <RunPodCard data={service.data} />;
+            case 'yandex': return <YandexCard data={service.data} />;
+            case 'gigachat': return <GigaChatCard data={service.data} />;
+            case 'reka': return <RekaCard data={service.data} />;
+            case 'watsonx': return <WatsonxCard data={service.data} />;
+            case 'databricks': return <DatabricksCard data={service.data} />;
+            case 'nvidia': return <NvidiaCard data={service.data} />;
+            case 'vercel': return <VercelGatewayCard data={service.data} />;
+            case 'openrouter': return <OpenRouterCard data={service.data} />;
+            case 'portkey': return <PortkeyCard data={service.data} />;
+            case 'githubmodels': return <GithubModelsCard data={service.data} />;
+            case 'codex': return <CodexCard data={service.data} />;
+            case 'chatgpt': return <ChatGPTCard data={service.data} />;
+            case 'claudecode': return <ClaudeCodeCard data={service.data} />;
+            case 'upstage': return <UpstageCard data={service.data} />;
+            case 'aigateway': return <AIGatewayCard data={service.data} />;
+            case 'custom': return <CustomProviderCard data={service.data} />;
+            case 'plugin': return <PluginProviderCard data={service.data} />;
+            default: return null;
+        }
+    };
+
+    return (
+        <div style={{
+            padding: '12px', marginBottom: '8px', borderRadius: '8px',
+            backgroundColor: 'var(--center-channel-bg, #fff)',
+            border: '1px solid var(--center-channel-color-08, #e0e0e0)',
+        }}>
+            <div style={{display: 'flex', alignItems: 'center', gap: '8px', marginBottom: '8px'}}>
+                <div style={{width: '8px', height: '8px', borderRadius: '50%', backgroundColor: statusColor, flexShrink: 0}}/>
+                <span style={{fontWeight: 600, fontSize: '14px', flex: 1}}>{service.name}</span>
+                {service.personal && (
+                    <span
+                        title='Fetched with your own credentials'
+                        style={{fontSize: '10px', color: '#1c58d9', border: '1px solid #1c58d9', borderRadius: '4px', padding: '0 4px', flexShrink: 0}}
+                    >
+                        {'Personal'}
+                    </span>
+                )}
+                {service.cachedAt && service.cachedAt > 0 && (
+                    <span
+                        style={{fontSize: '10px', color: '#b0b0b0', flexShrink: 0}}
+                        title={service.refreshInSeconds ? `Next refresh in ${formatTimeUntil(Date.now() + service.refreshInSeconds * 1000)}` : undefined}
+                    >
+                        {new Date(service.cachedAt * 1000).toLocaleTimeString()}
+                    </span>
+                )}
+                {onRefresh && (
+                    <button
+                        onClick={() => {
+                            setRefreshing(true);
+                            onRefresh().finally(() => setRefreshing(false));
+                        }}
+                        disabled={refreshing}
+                        title="Refresh this provider"
+                        style={{
+                            border: 'none', background: 'transparent', cursor: refreshing ? 'wait' : 'pointer',
+                            color: '#b0b0b0', fontSize: '12px', padding: 0, flexShrink: 0,
+                        }}
+                    >
+                        {refreshing ? '⏳' : '↻'}
+                    </button>
+                )}
+                {onHide && (
+                    <button onClick={onHide} title="Hide this card" style={{
+                        border: 'none', background: 'transparent', cursor: 'pointer',
+                        color: '#b0b0b0', fontSize: '12px', padding: 0, flexShrink: 0,
+                    }}>
+                        {'✕'}
+                    </button>
+                )}
+            </div>
+            {headline && <div style={{fontSize: '18px', fontWeight: 600, marginBottom: '8px'}}>{headline}</div>}
+            {renderData()}
+            {service.stale && (
+                <div style={{fontSize: '11px', color: '#f5a623', marginTop: '6px'}} title={service.staleError}>
+                    {`Stale: from ${formatTimeUntil(Date.now() + (service.ageSeconds || 0) * 1000)} ago, the latest refresh failed`}
+                    {service.requestId && ` · Ref: ${service.requestId}`}
+                </div>
+            )}
+            {service.breaker && (
+                <div style={{fontSize: '11px', color: '#d24b4e', marginTop: '6px'}}>
+                    {service.breaker.state === 'open' ?
+                        `Paused after ${service.breaker.failures} failed refreshes, next try in ${formatTimeUntil(service.breaker.retryAt * 1000)}` :
+                        `Paused after ${service.breaker.failures} failed refreshes, trying again`}
+                </div>
+            )}
+            {!service.error && service.forecast && <ForecastLine forecast={service.forecast} />}
+            {service.credentialExpiresAt && <CredentialExpiry expiresAt={service.credentialExpiresAt * 1000} />}
+        </div>
+    );
+};
+
+// BudgetList shows each named budget's spend against its amount, colored by its status.
+const BudgetList: React.FC<{budgets: BudgetData[]}> = ({budgets}) => (
+    <div style={{marginBottom: '8px'}}>
+        {budgets.map((b) => (
+            <div
+                key={b.id}
+                style={{marginBottom: '6px'}}
+                title={b.sources.map((src) => `${src.name}: ${formatMoney(src.amount, b.currency)}`).join('\n')}
+            >
+                <div style={{fontSize: '12px', display: 'flex', justifyContent: 'space-between', marginBottom: '2px'}}>
+                    <span style={{fontWeight: 600}}>{b.name}</span>
+                    <span style={{color: '#8b8fa7'}}>{formatMoney(b.spent, b.currency)} / {formatMoney(b.amount, b.currency, 0)}</span>
+                </div>
+                <div style={{height: '6px', borderRadius: '3px', backgroundColor: '#e8e8e8', overflow: 'hidden'}}>
+                    <div style={{height: '100%', borderRadius: '3px', width: `${Math.min(b.percent, 100)}%`, backgroundColor: getStatusColor(b.status)}}/>
+                </div>
+                {b.unconverted && b.unconverted.length > 0 && (
+                    <div style={{fontSize: '11px', color: '#8b8fa7'}}>Excluding {b.unconverted.join(', ')}: no exchange rate</div>
+                )}
+            </div>
+        ))}
+    </div>
+);
+
+const RHSPanel: React.FC = () => {
+    const [services, setServices] = useState<ServiceData[]>([]);
+    const [loading, setLoading] = useState(true);
+    const [refreshing, setRefreshing] = useState(false);
+    const [error, setError] = useState<string | null>(null);
+    const [prefs, setPrefs] = useState<UserPreferences>(DEFAULT_PREFERENCES);
+    const [defaultUnits, setDefaultUnits] = useState('raw');
+    const [demo, setDemo] = useState(false);
+    const [totalCost, setTotalCost] = useState<CostTotal | undefined>();
+    const [budgets, setBudgets] = useState<BudgetData[]>([]);
+
+    const loadData = useCallback(async () => {
+        try {
+            const data = await fetchStatus();
+            setServices(data.services);
+            setDefaultUnits(data.units);
+            setDemo(Boolean(data.demo));
+            setTotalCost(data.totalCost);
+            setBudgets(data.budgets || []);
+            setError(null);
+        } catch (e: any) {
+            setError(e.message);
+        } finally {
+            setLoading(false);
+        }
+    }, []);
+
+    const handleRefresh = useCallback(async () => {
+        setRefreshing(true);
+        try {
+            const data = await refreshAll();
+            setServices(data.services);
+            setDefaultUnits(data.units);
+            setDemo(Boolean(data.demo));
+            setTotalCost(data.totalCost);
+            setBudgets(data.budgets || []);
+            setError(null);
+        } catch (e: any) {
+            setError(e.message);
+        } finally {
+            setRefreshing(false);
+        }
+    }, []);
+
+    // A card's refresh replaces the cards of its provider, instances included
+    const handleRefreshCard = useCallback(async (id: string) => {
+        const provider = providerOf(id);
+        try {
+            const data = await refreshProvider(provider);
+            const updated = new Map<string, ServiceData>(data.services.map((s) => [s.id, s]));
+            setServices((current) => current.map((s) => (providerOf(s.id) === provider ? updated.get(s.id) || s : s)));
+            setError(null);
+        } catch (e: any) {
+            setError(e.message);
+        }
+    }, []);
+
+    const updatePreferences = useCallback(async (next: UserPreferences) => {
+        setPrefs(next);
+        try {
+            setPrefs(await savePreferences(next));
+        } catch (e: any) {
+            setError(e.message);
+        }
+    }, []);
+
+    useEffect(() => {
+        fetchPreferences().then(setPrefs).catch(() => {
+            // Fall back to defaults; the dashboard works without preferences
+        });
+    }, []);
+
+    // Cards the server pushed after a background poll replace their old version. Personal
+    // cards are left alone, since pushes carry the organization's statuses.
+    useEffect(() => {
+        const handleUpdate = (e: Event) => {
+            const updated = new Map<string, ServiceData>(((e as CustomEvent).detail as ServiceData[]).map((s) => [s.id, s]));
+            setServices((current) => current.map((s) => (s.personal ? s : updated.get(s.id) || s)));
+        };
+        window.addEventListener(STATUS_UPDATE_EVENT, handleUpdate);
+        return () => window.removeEventListener(STATUS_UPDATE_EVENT, handleUpdate);
+    }, []);
+
+    useEffect(() => {
+        loadData();
+        const seconds = prefs.refreshInterval || 5 * 60;
+        const interval = setInterval(loadData, seconds * 1000);
+        return () => clearInterval(interval);
+    }, [loadData, prefs.refreshInterval]);
+
+    const hideService = (id: string) => updatePreferences({...prefs, hiddenProviders: [...prefs.hiddenProviders, id]});
+    const showAll = () => updatePreferences({...prefs, hiddenProviders: []});
+    const visibleServices = applyPreferences(services, prefs);
+    const units = prefs.units || defaultUnits;
+
+    return (
+        <div style={{
+            display: 'flex',
+            flexDirection: 'column',
+            height: 'calc(100vh - 63px)',
+            minHeight: 0,
+            backgroundColor: 'var(--center-channel-bg, #f8f9fa)',
+        }}>
+            <div style={{
+                display: 'flex',
+                justifyContent: 'space-between',
+                alignItems: 'center',
+                padding: '12px 16px',
+                borderBottom: '1px solid var(--center-channel-color-08, #e0e0e0)',
+                flexShrink: 0,
+            }}>
+                <h3 style={{margin: 0, fontSize: '16px', fontWeight: 600, flex: 1}}>AI Service Limits</h3>
+                <select
+                    value={prefs.units}
+                    onChange={(e) => updatePreferences({...prefs, units: e.target.value})}
+                    title="Display units"
+                    style={{marginRight: '8px', fontSize: '12px', padding: '2px 4px'}}
+                >
+                    <option value="">Default units</option>
+                    <option value="raw">Tokens / credits</option>
+                    <option value="cost">Cost</option>
+                    <option value="percent">Percent</option>
+                </select>
+                <button onClick={handleRefresh} disabled={refreshing} style={{
+                    padding: '4px 12px', border: '1px solid var(--center-channel-color-16, #ccc)',
+                    borderRadius: '4px', backgroundColor: 'transparent',
+                    cursor: refreshing ? 'wait' : 'pointer', fontSize: '13px',
+                }}>
+                    {refreshing ? '⏳' : '🔄'} Refresh
+                </button>
+            </div>
+            <div style={{
+                padding: '16px',
+                flex: 1,
+                minHeight: 0,
+                overflowY: 'auto',
+                overflowX: 'hidden',
+            }}>
+                {demo && (
+                    <div style={{padding: '8px 12px', backgroundColor: '#fff8e1', borderRadius: '8px', color: '#8a6d00', fontSize: '12px', marginBottom: '8px'}}>
+                        Demo mode: showing synthetic data, not real usage.
+                    </div>
+                )}
+                {loading && <div style={{textAlign: 'center', padding: '24px', color: '#8b8fa7'}}>Loading...</div>}
+                {!loading && units === 'cost' && totalCost && (
+                    <div style={{fontSize: '13px', marginBottom: '8px'}}>
+                        <span style={{color: '#8b8fa7'}}>Total spend: </span>
+                        <span style={{fontWeight: 600}}>{formatMoney(totalCost.amount, totalCost.currency)}</span>
+                        {totalCost.unconverted && totalCost.unconverted.length > 0 && (
+                            <span style={{color: '#8b8fa7'}}> (excluding {totalCost.unconverted.join(', ')}: no exchange rate)</span>
+                        )}
+                    </div>
+                )}
+                {!loading && budgets.length > 0 && <BudgetList budgets={budgets} />}
+                {error && (
                     <div style={{padding: '12px', backgroundColor: '#fef0f0', borderRadius: '8px', color: '#d24b4e', fontSize: '13px', marginBottom: '8px'}}>
                         Error: {error}
                     </div>
                 )}
-                {!loading && services.map((service) => (
-                    <ServiceCard key={service.id} service={service} />
+                {!loading && visibleServices.map((service) => (
+                    <ServiceCard
+                        key={service.id}
+                        service={service}
+                        units={units}
+                        onHide={() => hideService(service.id)}
+                        onRefresh={demo ? undefined : () => handleRefreshCard(service.id)}
+                    />
                 ))}
+                {!loading && prefs.hiddenProviders.length > 0 && (
+                    <div style={{textAlign: 'center', padding: '8px'}}>
+                        <button onClick={showAll} style={{
+                            border: 'none', background: 'transparent', cursor: 'pointer',
+                            color: '#8b8fa7', fontSize: '12px', textDecoration: 'underline',
+                        }}>
+                            Show {prefs.hiddenProviders.length} hidden
+                        </button>
+                    </div>
+                )}
             </div>
         </div>
     );
@@ -0,0 +1,148 @@
+import React, {useState} from 'react';
+
+const PLUGIN_ID = 'com.fambear.ai-limits-monitor';
+
+// Maps the System Console setting key to the provider it tests
+const PROVIDER_BY_SETTING: Record<string, string> = {
+    AugmentTestConnection: 'augment',
+    ZaiTestConnection: 'zai',
+    OpenaiTestConnection: 'openai',
+    ClaudeTestConnection: 'claude',
+    AnthropicTestConnection: 'anthropic',
+    CopilotTestConnection: 'copilot',
+    CursorTestConnection: 'cursor',
+    MistralTestConnection: 'mistral',
+    GroqTestConnection: 'groq',
+    DeepseekTestConnection: 'deepseek',
+    PerplexityTestConnection: 'perplexity',
+    TogetherTestConnection: 'together',
+    BedrockTestConnection: 'bedrock',
+    VertexTestConnection: 'vertex',
+    GeminiTestConnection: 'gemini',
+    AzureOpenaiTestConnection: 'azureopenai',
+    XaiTestConnection: 'xai',
+    WindsurfTestConnection: 'windsurf',
+    JetbrainsTestConnection: 'jetbrains',
+    CerebrasTestConnection: 'cerebras',
+    SambanovaTestConnection: 'sambanova',
+    SelfhostedTestConnection: 'selfhosted',
+    LitellmTestConnection: 'litellm',
+    HeliconeTestConnection: 'helicone',
+    GatewayTestConnection: 'gateway',
+    ElevenlabsTestConnection: 'elevenlabs',
+    AssemblyaiTestConnection: 'assemblyai',
+    AmazonqTestConnection: 'amazonq',
+    MoonshotTestConnection: 'moonshot',
+    DashscopeTestConnection: 'dashscope',
+    Ai21TestConnection: 'ai21',
+    FalTestConnection: 'fal',
+    RunpodTestConnection: 'runpod',
+    YandexTestConnection: 'yandex',
+    GigachatTestConnection: 'gigachat',
+    RekaTestConnection: 'reka',
+    WatsonxTestConnection: 'watsonx',
+    DatabricksTestConnection: 'databricks',
+    NvidiaTestConnection: 'nvidia',
+    VercelTestConnection: 'vercel',
+    OpenrouterTestConnection: 'openrouter',
+    PortkeyTestConnection: 'portkey',
+    GithubModelsTestConnection: 'githubmodels',
+    CodexTestConnection: 'codex',
+    ChatgptTestConnection: 'chatgpt',
+    UpstageTestConnection: 'upstage',
+    AigatewayTestConnection: 'aigateway',
+    CustomProvidersTestConnection: 'custom',
+};
+
+interface ProbeResult {
+    scope: string;
+    method: string;
+    url: string;
+    status?: number;
+    latencyMs: number;
+    requestId?: string;
+    fields?: string[];
+    error?: string;
+    body?: string;
+}
+
+interface TestResult {
+    provider: string;
+    success: boolean;
+    latencyMs: number;
+    message: string;
+    scopes: string[];
+    probes?: ProbeResult[];
+}
+
+const testConnection = async (provider: string): Promise<TestResult> => {
+    const resp = await fetch(`/plugins/${PLUGIN_ID}/api/v1/admin/test/${provider}`, {
+        method: 'POST',
+        headers: {'X-Requested-With': 'XMLHttpRequest'},
+    });
+    if (!resp.ok) {
+        const body = await resp.json().catch(() => null);
+        throw new Error(body?.message || `HTTP ${resp.status}`);
+    }
+    return resp.json();
+};
+
+// TestConnectionSetting is a custom System Console setting with a button that
+// checks the saved credentials for one provider against its live API.
+const TestConnectionSetting: React.FC<{id: string; helpText?: React.ReactNode}> = ({id, helpText}) => {
+    const provider = PROVIDER_BY_SETTING[id];
+    const [testing, setTesting] = useState(false);
+    const [result, setResult] = useState<TestResult | null>(null);
+    const [error, setError] = useState<string | null>(null);
+
+    const handleClick = async (e: React.MouseEvent) => {
+        e.preventDefault();
+        setTesting(true);
+        setResult(null);
+        setError(null);
+        try {
+            setResult(await testConnection(provider));
+        } catch (err: any) {
+            setError(err.message);
+        } finally {
+            setTesting(false);
+        }
+    };
+
+    return (
+        <div>
+            <button className='btn btn-tertiary' onClick={handleClick} disabled={testing || !provider}>
+                {testing ? 'Testing...' : 'Test connection'}
+            </button>
+            {result && (
+                <div style={{marginTop: '8px', color: result.success ? '#3db887' : '#d24b4e'}}>
+                    {result.success ? '✓' : '✕'} {result.message} ({result.latencyMs} ms)
+                </div>
+            )}
+            {result && result.probes && result.probes.length > 0 && (
+                <details style={{marginTop: '4px', fontSize: '12px'}}>
+                    <summary style={{cursor: 'pointer'}}>{'Details'}</summary>
+                    {result.probes.map((probe) => (
+                        <div key={probe.scope} style={{marginTop: '6px'}}>
+                            <div>
+                                <code>{probe.method} {probe.url}</code>
+                                {` → ${probe.status ? `HTTP ${probe.status}` : 'no response'} in ${probe.latencyMs} ms`}
+                            </div>
+                            {probe.requestId && <div>{'Request ID: '}<code>{probe.requestId}</code></div>}
+                            {probe.fields && probe.fields.length > 0 && <div>{'Fields: '}{probe.fields.join(', ')}</div>}
+                            {probe.error && <div style={{color: '#d24b4e'}}>{probe.error}</div>}
+                            {probe.body && (
+                                <pre style={{whiteSpace: 'pre-wrap', maxHeight: '160px', overflow: 'auto', margin: '4px 0 0'}}>{probe.body}</pre>
+                            )}
+                        </div>
+                    ))}
+                </details>
+            )}
+            {error && <div style={{marginTop: '8px', color: '#d24b4e'}}>{error}</div>}
+            {helpText && <div className='help-text'>{helpText}</div>}
+        </div>
+    );
+};
+
+export {PROVIDER_BY_SETTING};
+export default TestConnectionSetting;
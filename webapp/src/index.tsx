@@ -1,5 +1,7 @@
 import React from 'react';
-import RHSPanel from './components/rhs_panel';
+import RHSPanel, {STATUS_UPDATE_EVENT} from './components/rhs_panel';
+import TestConnectionSetting, {PROVIDER_BY_SETTING} from './components/test_connection';
+import ClaudeOAuthSetting from './components/claude_oauth';
 
 const PLUGIN_ID = 'com.fambear.ai-limits-monitor';
 
@@ -28,6 +30,24 @@ class PluginClass {
             null,
             'AI Limits Monitor',
         );
+
+        // Statuses the server pushes after a background poll, handed to the open panel
+        registry.registerWebSocketEventHandler(`custom_${PLUGIN_ID}_status_update`, (msg: any) => {
+            try {
+                const services = JSON.parse(msg.data.services);
+                window.dispatchEvent(new CustomEvent(STATUS_UPDATE_EVENT, {detail: services}));
+            } catch (e) {
+                // A malformed push is dropped; the next poll catches up
+            }
+        });
+
+        // "Test connection" buttons in System Console
+        if (registry.registerAdminConsoleCustomSetting) {
+            Object.keys(PROVIDER_BY_SETTING).forEach((key) => {
+                registry.registerAdminConsoleCustomSetting(key, TestConnectionSetting, {showTitle: true});
+            });
+            registry.registerAdminConsoleCustomSetting('ClaudeOauthConnect', ClaudeOAuthSetting, {showTitle: true});
+        }
     }
 
     uninitialize() {